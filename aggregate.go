@@ -0,0 +1,155 @@
+package iotmonitor
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+	"golang.org/x/net/context"
+)
+
+// Fleet aggregate groupings
+const (
+	GroupByNone       = ""
+	GroupByDevice     = "device"
+	GroupByOwner      = "owner"
+	GroupByDeviceType = "device_type"
+)
+
+// FleetQuery aggregates one metric across the selected devices between From
+// and To (Unix milliseconds). A zero To means now and a zero From means an
+// hour before To.
+type FleetQuery struct {
+	Filter  DeviceFilter `json:"filter"`
+	Metric  string       `json:"metric"`
+	From    int64        `json:"from"`
+	To      int64        `json:"to"`
+	GroupBy string       `json:"group_by,omitempty"`
+}
+
+// AggregateGroup holds the statistics for one group of devices.
+type AggregateGroup struct {
+	Key     string  `json:"key"`
+	Devices int     `json:"devices"` // devices that reported the metric
+	Min     float64 `json:"min"`
+	Max     float64 `json:"max"`
+	Avg     float64 `json:"avg"`
+	Sum     float64 `json:"sum"`
+	Count   int64   `json:"count"`
+}
+
+// FleetAggregate is the answer to a FleetQuery. Groups are sorted by key.
+// Rollup buckets that straddle From are counted whole, so at hourly or daily
+// resolution the window is widened to the bucket boundary.
+type FleetAggregate struct {
+	Metric     string           `json:"metric"`
+	From       int64            `json:"from"`
+	To         int64            `json:"to"`
+	Resolution string           `json:"resolution"`
+	Groups     []AggregateGroup `json:"groups"`
+}
+
+var errUnknownGroupBy = errors.New("unknown group_by")
+
+func aggregateKey(d Device, groupBy string) (string, error) {
+	switch groupBy {
+	case GroupByNone:
+		return "all", nil
+	case GroupByDevice:
+		return strconv.FormatUint(d.ID, 10), nil
+	case GroupByOwner:
+		return d.Owner, nil
+	case GroupByDeviceType:
+		return d.DeviceType, nil
+	}
+	return "", fmt.Errorf("%v %q", errUnknownGroupBy, groupBy)
+}
+
+// numeric reports whether any of the points summarise numeric values.
+func numeric(points []TelemetryPoint) bool {
+	for _, p := range points {
+		if p.Count > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (s monitorService) AggregateTelemetry(ctx context.Context, q FleetQuery) (FleetAggregate, error) {
+	now := makeTimestamp()
+	if q.To == 0 {
+		q.To = now
+	}
+	if q.From == 0 {
+		q.From = q.To - int64(time.Hour/time.Millisecond)
+	}
+	if q.From > q.To || q.Metric == "" {
+		return FleetAggregate{}, errInvalidRange
+	}
+	if _, err := aggregateKey(Device{}, q.GroupBy); err != nil {
+		return FleetAggregate{}, err
+	}
+
+	c, err := s.store.dial()
+	if err != nil {
+		return FleetAggregate{}, err
+	}
+	defer c.Close()
+	return aggregate(c, q, chooseResolution(s.retention, q.From, q.To, now))
+}
+
+// aggregate answers a validated query from the samples stored at resolution.
+func aggregate(c redis.Conn, q FleetQuery, resolution string) (FleetAggregate, error) {
+	devices, err := selectDevices(c, q.Filter)
+	if err != nil {
+		return FleetAggregate{}, err
+	}
+
+	agg := FleetAggregate{Metric: q.Metric, From: q.From, To: q.To, Resolution: resolution}
+	groups := make(map[string]*AggregateGroup)
+	for _, d := range devices {
+		var points []TelemetryPoint
+		if agg.Resolution == ResolutionRaw {
+			points, err = readRaw(c, d.ID, q.Metric, q.From, q.To)
+		} else {
+			points, err = readRollups(c, agg.Resolution, d.ID, q.Metric, q.From, q.To)
+		}
+		if err != nil {
+			return FleetAggregate{}, err
+		}
+		if !numeric(points) {
+			continue
+		}
+
+		key, _ := aggregateKey(d, q.GroupBy)
+		g, ok := groups[key]
+		if !ok {
+			g = &AggregateGroup{Key: key, Min: math.Inf(1), Max: math.Inf(-1)}
+			groups[key] = g
+		}
+		g.Devices++
+		for _, p := range points {
+			if p.Count == 0 {
+				continue
+			}
+			g.Min = math.Min(g.Min, p.Min)
+			g.Max = math.Max(g.Max, p.Max)
+			g.Sum += p.Avg * float64(p.Count)
+			g.Count += p.Count
+		}
+	}
+
+	agg.Groups = make([]AggregateGroup, 0, len(groups))
+	for _, g := range groups {
+		if g.Count > 0 {
+			g.Avg = g.Sum / float64(g.Count)
+		}
+		agg.Groups = append(agg.Groups, *g)
+	}
+	sort.Slice(agg.Groups, func(i, j int) bool { return agg.Groups[i].Key < agg.Groups[j].Key })
+	return agg, nil
+}
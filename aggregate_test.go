@@ -0,0 +1,112 @@
+package iotmonitor
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+// storeRaw stores a raw sample as recordTelemetry does, without the rollups.
+func storeRaw(t *testing.T, c redis.Conn, id uint64, metric string, ts int64, v TelemetryValue) {
+	stored, err := v.encodeStored()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Do("ZADD", rawKey(id, metric), ts, fmt.Sprintf("%d:%s", ts, stored))
+}
+
+// fleetDevices registers drones 1 and 4 and sensor 3 owned by ops and drone
+// 2 owned by lab, with temperatures between 1000 and 5000. Device 4 only
+// reported text.
+func fleetDevices(t *testing.T) redis.Conn {
+	c := newFakeRedis()
+	for _, d := range []deviceRecord{
+		{ID: 1, Name: "d1", Owner: "ops", DeviceType: "Drone"},
+		{ID: 2, Name: "d2", Owner: "lab", DeviceType: "Drone"},
+		{ID: 3, Name: "s3", Owner: "ops", DeviceType: "Sensor"},
+		{ID: 4, Name: "d4", Owner: "ops", DeviceType: "Drone"},
+	} {
+		c.Do("HMSET", redisArgs(fmt.Sprintf("device:%d", d.ID), &d)...)
+		c.Do("SADD", "devices", d.ID)
+	}
+	text := TelemetryValue{Type: TelemetryString, Text: "n/a"}
+	storeRaw(t, c, 1, "temp", 1000, DoubleValue(10, ""))
+	storeRaw(t, c, 1, "temp", 2000, DoubleValue(20, ""))
+	storeRaw(t, c, 1, "temp", 9000, DoubleValue(90, ""))
+	storeRaw(t, c, 2, "temp", 3000, DoubleValue(30, ""))
+	storeRaw(t, c, 3, "temp", 1500, DoubleValue(5, ""))
+	storeRaw(t, c, 3, "temp", 2500, text)
+	storeRaw(t, c, 4, "temp", 2000, text)
+	storeRaw(t, c, 4, "humidity", 2000, DoubleValue(60, ""))
+	return c
+}
+
+func TestAggregate(t *testing.T) {
+	c := fleetDevices(t)
+	tests := []struct {
+		groupBy string
+		filter  DeviceFilter
+		want    []AggregateGroup
+	}{
+		{GroupByNone, DeviceFilter{}, []AggregateGroup{
+			{Key: "all", Devices: 3, Min: 5, Max: 30, Avg: 16.25, Sum: 65, Count: 4},
+		}},
+		{GroupByDevice, DeviceFilter{}, []AggregateGroup{
+			{Key: "1", Devices: 1, Min: 10, Max: 20, Avg: 15, Sum: 30, Count: 2},
+			{Key: "2", Devices: 1, Min: 30, Max: 30, Avg: 30, Sum: 30, Count: 1},
+			{Key: "3", Devices: 1, Min: 5, Max: 5, Avg: 5, Sum: 5, Count: 1},
+		}},
+		{GroupByOwner, DeviceFilter{}, []AggregateGroup{
+			{Key: "lab", Devices: 1, Min: 30, Max: 30, Avg: 30, Sum: 30, Count: 1},
+			{Key: "ops", Devices: 2, Min: 5, Max: 20, Avg: 35.0 / 3, Sum: 35, Count: 3},
+		}},
+		{GroupByDeviceType, DeviceFilter{}, []AggregateGroup{
+			{Key: "Drone", Devices: 2, Min: 10, Max: 30, Avg: 20, Sum: 60, Count: 3},
+			{Key: "Sensor", Devices: 1, Min: 5, Max: 5, Avg: 5, Sum: 5, Count: 1},
+		}},
+		{GroupByNone, DeviceFilter{DeviceType: "Sensor"}, []AggregateGroup{
+			{Key: "all", Devices: 1, Min: 5, Max: 5, Avg: 5, Sum: 5, Count: 1},
+		}},
+		{GroupByNone, DeviceFilter{Owner: "nobody"}, []AggregateGroup{}},
+	}
+	for _, tt := range tests {
+		q := FleetQuery{Filter: tt.filter, Metric: "temp", From: 1000, To: 5000, GroupBy: tt.groupBy}
+		got, err := aggregate(c, q, ResolutionRaw)
+		if err != nil {
+			t.Fatalf("group by %q: %v", tt.groupBy, err)
+		}
+		if got.Metric != "temp" || got.From != 1000 || got.To != 5000 || got.Resolution != ResolutionRaw {
+			t.Errorf("group by %q: aggregate() = %+v, want the query echoed", tt.groupBy, got)
+		}
+		if !reflect.DeepEqual(got.Groups, tt.want) {
+			t.Errorf("group by %q, filter %+v: groups = %+v, want %+v", tt.groupBy, tt.filter, got.Groups, tt.want)
+		}
+	}
+}
+
+func TestAggregateRollups(t *testing.T) {
+	c := fleetDevices(t)
+	hour := int64(time.Hour / time.Millisecond)
+	for b, r := range map[int64]rollupRecord{
+		0:    {Min: 1, Max: 3, Sum: 8, Count: 4, Last: 3},
+		hour: {Min: 2, Max: 9, Sum: 12, Count: 2, Last: 9},
+	} {
+		c.Do("HMSET", redisArgs(rollupKey(ResolutionHour, 1, "temp", b), &r)...)
+		c.Do("ZADD", rollupIndexKey(ResolutionHour, 1, "temp"), b, b)
+	}
+	// A bucket compacted after the index was read is skipped.
+	c.Do("ZADD", rollupIndexKey(ResolutionHour, 1, "temp"), 2*hour, 2*hour)
+
+	q := FleetQuery{Metric: "temp", From: 1000, To: 3 * hour}
+	got, err := aggregate(c, q, ResolutionHour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []AggregateGroup{{Key: "all", Devices: 1, Min: 1, Max: 9, Avg: 20.0 / 6, Sum: 20, Count: 6}}
+	if got.Resolution != ResolutionHour || !reflect.DeepEqual(got.Groups, want) {
+		t.Errorf("aggregate() = %+v, want hourly groups %+v", got, want)
+	}
+}
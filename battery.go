@@ -0,0 +1,266 @@
+package iotmonitor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+	"github.com/go-kit/kit/metrics"
+	"golang.org/x/net/context"
+)
+
+// Battery history is kept per device in a sorted set scored by timestamp,
+// trimmed to batteryHistoryWindow and batteryHistoryCap samples.
+const (
+	batteryHistoryWindow = 7 * 24 * time.Hour
+	batteryHistoryCap    = 2000
+
+	// A discharge rate is only estimated from samples spanning at least this long.
+	minDischargeSpan = 5 * time.Minute
+)
+
+// BatterySample is a battery level reported at a point in time.
+type BatterySample struct {
+	Level     uint32 `json:"level"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// BatteryEstimate describes a device's battery and, while it is discharging,
+// how long it is predicted to last.
+type BatteryEstimate struct {
+	Level         uint32  `json:"level"`
+	Charging      bool    `json:"charging"`
+	DischargeRate float64 `json:"discharge_rate,omitempty"` // percent per hour
+	TimeToEmpty   int64   `json:"time_to_empty,omitempty"`  // seconds, 0 when unknown
+}
+
+func batteryKey(id uint64) string {
+	return fmt.Sprintf("battery:%d", id)
+}
+
+func recordBattery(c redis.Conn, id uint64, level uint32, ts int64) error {
+	key := batteryKey(id)
+	if _, err := c.Do("ZADD", key, ts, fmt.Sprintf("%d:%d", ts, level)); err != nil {
+		return err
+	}
+	cutoff := ts - int64(batteryHistoryWindow/time.Millisecond)
+	if _, err := c.Do("ZREMRANGEBYSCORE", key, "-inf", cutoff); err != nil {
+		return err
+	}
+	_, err := c.Do("ZREMRANGEBYRANK", key, 0, -batteryHistoryCap-1)
+	return err
+}
+
+// readBatteryHistory returns the samples reported since since, oldest first.
+func readBatteryHistory(c redis.Conn, id uint64, since int64) ([]BatterySample, error) {
+	members, err := redis.Strings(c.Do("ZRANGEBYSCORE", batteryKey(id), since, "+inf"))
+	if err != nil {
+		return nil, err
+	}
+	samples := make([]BatterySample, 0, len(members))
+	for _, m := range members {
+		parts := strings.SplitN(m, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		ts, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		level, err := strconv.ParseUint(parts[1], 10, 32)
+		if err != nil {
+			continue
+		}
+		samples = append(samples, BatterySample{Level: uint32(level), Timestamp: ts})
+	}
+	return samples, nil
+}
+
+// estimateBattery fits a least-squares line through the samples taken since
+// the battery was last charged and extrapolates it to zero from now.
+func estimateBattery(samples []BatterySample, now int64) (BatteryEstimate, bool) {
+	if len(samples) == 0 {
+		return BatteryEstimate{}, false
+	}
+	last := samples[len(samples)-1]
+	e := BatteryEstimate{Level: last.Level}
+
+	start := len(samples) - 1
+	for start > 0 && samples[start-1].Level >= samples[start].Level {
+		start--
+	}
+	if start == len(samples)-1 && start > 0 {
+		// The latest sample is higher than the one before it.
+		e.Charging = true
+		return e, true
+	}
+	run := samples[start:]
+	if time.Duration(last.Timestamp-run[0].Timestamp)*time.Millisecond < minDischargeSpan {
+		return e, true
+	}
+
+	var sumT, sumL, sumTT, sumTL float64
+	n := float64(len(run))
+	for _, s := range run {
+		t := float64(s.Timestamp-run[0].Timestamp) / float64(time.Hour/time.Millisecond)
+		l := float64(s.Level)
+		sumT += t
+		sumL += l
+		sumTT += t * t
+		sumTL += t * l
+	}
+	denom := n*sumTT - sumT*sumT
+	if denom == 0 {
+		return e, true
+	}
+	slope := (n*sumTL - sumT*sumL) / denom
+	if slope >= 0 {
+		return e, true
+	}
+	e.DischargeRate = -slope
+
+	hours := float64(last.Level)/e.DischargeRate - float64(now-last.Timestamp)/float64(time.Hour/time.Millisecond)
+	if hours < 0 {
+		hours = 0
+	}
+	e.TimeToEmpty = int64(hours * 3600)
+	if e.TimeToEmpty == 0 {
+		e.TimeToEmpty = 1 // distinguish "empty now" from "unknown"
+	}
+	return e, true
+}
+
+func loadBatteryEstimate(c redis.Conn, id uint64, now int64) (*BatteryEstimate, error) {
+	since := now - int64(batteryHistoryWindow/time.Millisecond)
+	samples, err := readBatteryHistory(c, id, since)
+	if err != nil {
+		return nil, err
+	}
+	e, ok := estimateBattery(samples, now)
+	if !ok {
+		return nil, nil
+	}
+	return &e, nil
+}
+
+// BatteryHistory returns the battery levels reported by a device since the
+// given timestamp, oldest first.
+func (s monitorService) BatteryHistory(ctx context.Context, id uint64, since int64) ([]BatterySample, error) {
+	c, err := s.store.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	if _, err := readDevice(c, id); err != nil {
+		return nil, err
+	}
+	return readBatteryHistory(c, id, since)
+}
+
+// BatteryMonitor periodically exports battery predictions as gauges and
+// raises an alert for devices predicted to run flat within LowRuntime.
+// Forget, if set, is called with the device label of each device a sweep no
+// longer has a prediction for, such as one that has been deleted, to remove
+// its series from the gauges.
+type BatteryMonitor struct {
+	LowRuntime  time.Duration
+	Interval    time.Duration
+	Level       metrics.Gauge // labelled by device
+	TimeToEmpty metrics.Gauge // labelled by device, seconds
+	Forget      func(device string)
+	Events      *EventBus
+	Store       StoreConfig
+
+	exported map[string]bool
+}
+
+// Run checks battery predictions every interval until ctx is cancelled.
+func (m *BatteryMonitor) Run(ctx context.Context) error {
+	ticker := time.NewTicker(m.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := m.sweep(); err != nil {
+				fmt.Println("Failed to check battery predictions")
+				fmt.Println(err)
+			}
+		}
+	}
+}
+
+func batteryAlertMember(deviceID uint64) string {
+	return fmt.Sprintf("battery:%d", deviceID)
+}
+
+// sweep updates the gauges and alerts. Series are only forgotten after a
+// complete sweep, so that one cut short doesn't drop the devices it missed.
+func (m *BatteryMonitor) sweep() (err error) {
+	c, err := m.Store.dial()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	ids, err := redis.Values(c.Do("SMEMBERS", "devices"))
+	if err != nil {
+		return err
+	}
+	now := makeTimestamp()
+	exported := make(map[string]bool, len(ids))
+	defer func() {
+		for device := range m.exported {
+			switch {
+			case err != nil:
+				exported[device] = true
+			case !exported[device] && m.Forget != nil:
+				m.Forget(device)
+			}
+		}
+		m.exported = exported
+	}()
+	for _, raw := range ids {
+		id, err := redis.Uint64(raw, nil)
+		if err != nil {
+			return err
+		}
+		e, err := loadBatteryEstimate(c, id, now)
+		if err != nil {
+			return err
+		}
+		if e == nil {
+			continue
+		}
+
+		device := strconv.FormatUint(id, 10)
+		exported[device] = true
+		if m.Level != nil {
+			m.Level.With("device", device).Set(float64(e.Level))
+		}
+		if m.TimeToEmpty != nil {
+			m.TimeToEmpty.With("device", device).Set(float64(e.TimeToEmpty))
+		}
+
+		remaining := time.Duration(e.TimeToEmpty) * time.Second
+		low := e.TimeToEmpty > 0 && remaining < m.LowRuntime
+		next := Alert{
+			Source:   AlertSourceBattery,
+			DeviceID: id,
+			Value:    float64(e.TimeToEmpty),
+			Message:  fmt.Sprintf("battery predicted to run out in %s", remaining.Round(time.Minute)),
+		}
+		a, err := transitionAlert(c, batteryAlertMember(id), next, low, now)
+		if err != nil {
+			return err
+		}
+		if a != nil {
+			m.Events.Publish(alertEvent(*a))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package iotmonitor
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// minutes returns a timestamp m minutes after an arbitrary start.
+func minutes(m float64) int64 {
+	return 1500000000000 + int64(m*float64(time.Minute/time.Millisecond))
+}
+
+func TestEstimateBattery(t *testing.T) {
+	tests := []struct {
+		name    string
+		samples []BatterySample
+		now     int64
+		ok      bool
+		want    BatteryEstimate
+	}{
+		{
+			name: "no samples",
+			ok:   false,
+		},
+		{
+			name:    "single sample",
+			samples: []BatterySample{{80, minutes(0)}},
+			now:     minutes(1),
+			ok:      true,
+			want:    BatteryEstimate{Level: 80},
+		},
+		{
+			name:    "charging",
+			samples: []BatterySample{{50, minutes(0)}, {40, minutes(10)}, {45, minutes(20)}},
+			now:     minutes(20),
+			ok:      true,
+			want:    BatteryEstimate{Level: 45, Charging: true},
+		},
+		{
+			name:    "too short to tell",
+			samples: []BatterySample{{50, minutes(0)}, {49, minutes(2)}},
+			now:     minutes(2),
+			ok:      true,
+			want:    BatteryEstimate{Level: 49},
+		},
+		{
+			name:    "steady discharge",
+			samples: []BatterySample{{60, minutes(0)}, {50, minutes(30)}, {40, minutes(60)}},
+			now:     minutes(60),
+			ok:      true,
+			// 20%/h from 40% leaves two hours.
+			want: BatteryEstimate{Level: 40, DischargeRate: 20, TimeToEmpty: 7200},
+		},
+		{
+			name:    "time since the last sample counts",
+			samples: []BatterySample{{60, minutes(0)}, {50, minutes(30)}, {40, minutes(60)}},
+			now:     minutes(90),
+			ok:      true,
+			want:    BatteryEstimate{Level: 40, DischargeRate: 20, TimeToEmpty: 5400},
+		},
+		{
+			name:    "only since the last charge",
+			samples: []BatterySample{{90, minutes(0)}, {30, minutes(30)}, {100, minutes(40)}, {90, minutes(70)}, {80, minutes(100)}},
+			now:     minutes(100),
+			ok:      true,
+			want:    BatteryEstimate{Level: 80, DischargeRate: 20, TimeToEmpty: 14400},
+		},
+		{
+			name:    "noisy discharge is fitted",
+			samples: []BatterySample{{60, minutes(0)}, {55, minutes(15)}, {50, minutes(30)}, {46, minutes(45)}, {40, minutes(60)}},
+			now:     minutes(60),
+			ok:      true,
+			// 40 / 19.6 hours.
+			want: BatteryEstimate{Level: 40, DischargeRate: 19.6, TimeToEmpty: 7346},
+		},
+		{
+			name:    "overdue",
+			samples: []BatterySample{{20, minutes(0)}, {10, minutes(30)}},
+			now:     minutes(120),
+			ok:      true,
+			want:    BatteryEstimate{Level: 10, DischargeRate: 20, TimeToEmpty: 1},
+		},
+		{
+			name:    "flat",
+			samples: []BatterySample{{70, minutes(0)}, {70, minutes(30)}},
+			now:     minutes(30),
+			ok:      true,
+			want:    BatteryEstimate{Level: 70},
+		},
+	}
+	for _, tt := range tests {
+		got, ok := estimateBattery(tt.samples, tt.now)
+		if ok != tt.ok {
+			t.Errorf("%s: ok = %v, want %v", tt.name, ok, tt.ok)
+			continue
+		}
+		if math.Abs(got.DischargeRate-tt.want.DischargeRate) > 1e-9 {
+			t.Errorf("%s: discharge rate = %g, want %g", tt.name, got.DischargeRate, tt.want.DischargeRate)
+		}
+		got.DischargeRate = tt.want.DischargeRate
+		if got != tt.want {
+			t.Errorf("%s: estimate = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
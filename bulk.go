@@ -0,0 +1,345 @@
+package iotmonitor
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/context"
+)
+
+// Bulk import and export formats
+const (
+	FormatCSV     = "csv"
+	FormatNDJSON  = "ndjson"
+	FormatParquet = "parquet"
+)
+
+// maxImportRows bounds a single import request.
+const maxImportRows = 50000
+
+// DeviceImport is one row of a bulk registration.
+type DeviceImport struct {
+	Name       string            `json:"name"`
+	Owner      string            `json:"owner"`
+	DeviceType string            `json:"device_type"`
+	GatewayID  uint64            `json:"gateway_id,omitempty"`
+	Labels     map[string]string `json:"labels,omitempty"`
+}
+
+// ImportResult reports the outcome of one import row. Rows are numbered
+// from 1. DeviceID is zero for dry runs and failed rows.
+type ImportResult struct {
+	Row      int    `json:"row"`
+	DeviceID uint64 `json:"device_id,omitempty"`
+	Err      string `json:"err,omitempty"`
+}
+
+// DeviceExport is a device and its last reported status, if any.
+type DeviceExport struct {
+	Device
+	Status *DeviceStatus `json:"status,omitempty"`
+}
+
+var errUnknownFormat = errors.New("unknown format")
+
+func (d DeviceImport) validate() error {
+	if strings.TrimSpace(d.Name) == "" {
+		return errors.New("name is required")
+	}
+	if strings.TrimSpace(d.DeviceType) == "" {
+		return errors.New("device_type is required")
+	}
+	return validateLabels(d.Labels)
+}
+
+// ImportDevices registers devices in bulk. Each row is validated and, unless
+// dryRun is set, registered; a bad row doesn't stop the rows after it.
+func (s monitorService) ImportDevices(ctx context.Context, devices []DeviceImport, dryRun bool) ([]ImportResult, error) {
+	if len(devices) > maxImportRows {
+		return nil, fmt.Errorf("import is limited to %d rows", maxImportRows)
+	}
+	c, err := s.store.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	results := make([]ImportResult, len(devices))
+	for i, d := range devices {
+		results[i].Row = i + 1
+		err := d.validate()
+		if err == nil && d.GatewayID != 0 {
+			if _, err = readDevice(c, d.GatewayID); err != nil {
+				err = fmt.Errorf("gateway %v", err)
+			}
+		}
+		if err == nil && !dryRun {
+			results[i].DeviceID, err = s.RegisterDevice(ctx, d.Name, d.Owner, d.DeviceType, d.GatewayID, d.Labels)
+		}
+		if err != nil {
+			results[i].Err = err.Error()
+		}
+	}
+	return results, nil
+}
+
+// ExportDevices returns the selected devices with their labels and last
+// status.
+func (s monitorService) ExportDevices(ctx context.Context, filter DeviceFilter) ([]DeviceExport, error) {
+	c, err := s.store.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	selected, err := selectDevices(c, filter)
+	if err != nil {
+		return nil, err
+	}
+	devices := make([]DeviceExport, 0, len(selected))
+	for _, d := range selected {
+		d, err := loadDevice(c, d.ID)
+		if err == errDeviceNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		e := DeviceExport{Device: d}
+		status, err := loadStatus(c, d.ID)
+		if err == nil {
+			e.Status = &status
+		} else if err != errNoStatus {
+			return nil, err
+		}
+		devices = append(devices, e)
+	}
+	sort.Slice(devices, func(i, j int) bool { return devices[i].ID < devices[j].ID })
+	return devices, nil
+}
+
+// formatLabels writes labels the way a label selector would match them:
+// "env=prod,site=plant-3".
+func formatLabels(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + "=" + labels[k]
+	}
+	return strings.Join(pairs, ",")
+}
+
+func parseLabels(s string) (map[string]string, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	labels := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid label %q, want key=value", pair)
+		}
+		labels[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	return labels, nil
+}
+
+// ReadDeviceImports parses a bulk registration file. CSV files need a header
+// row naming their columns: name, owner, device_type, gateway_id and labels,
+// where labels are written "key=value,key=value". NDJSON files hold one
+// DeviceImport object per line.
+func ReadDeviceImports(r io.Reader, format string) ([]DeviceImport, error) {
+	switch format {
+	case FormatCSV:
+		return readImportCSV(r)
+	case FormatNDJSON:
+		return readImportNDJSON(r)
+	}
+	return nil, fmt.Errorf("%v %q", errUnknownFormat, format)
+}
+
+func readImportCSV(r io.Reader) ([]DeviceImport, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading csv header: %v", err)
+	}
+	for _, col := range header {
+		switch col {
+		case "name", "owner", "device_type", "gateway_id", "labels":
+		default:
+			return nil, fmt.Errorf("unknown csv column %q", col)
+		}
+	}
+
+	var devices []DeviceImport
+	for row := 1; ; row++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return devices, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		var d DeviceImport
+		for i, col := range header {
+			v := rec[i]
+			switch col {
+			case "name":
+				d.Name = v
+			case "owner":
+				d.Owner = v
+			case "device_type":
+				d.DeviceType = v
+			case "gateway_id":
+				if v == "" {
+					continue
+				}
+				if d.GatewayID, err = strconv.ParseUint(v, 10, 64); err != nil {
+					return nil, fmt.Errorf("row %d: invalid gateway_id %q", row, v)
+				}
+			case "labels":
+				if d.Labels, err = parseLabels(v); err != nil {
+					return nil, fmt.Errorf("row %d: %v", row, err)
+				}
+			}
+		}
+		devices = append(devices, d)
+		if len(devices) > maxImportRows {
+			return nil, fmt.Errorf("import is limited to %d rows", maxImportRows)
+		}
+	}
+}
+
+func readImportNDJSON(r io.Reader) ([]DeviceImport, error) {
+	var devices []DeviceImport
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var d DeviceImport
+		if err := json.Unmarshal([]byte(line), &d); err != nil {
+			return nil, fmt.Errorf("line %d: %v", n, err)
+		}
+		devices = append(devices, d)
+		if len(devices) > maxImportRows {
+			return nil, fmt.Errorf("import is limited to %d rows", maxImportRows)
+		}
+	}
+	return devices, scanner.Err()
+}
+
+var exportColumns = []string{
+	"id", "name", "owner", "device_type", "gateway_id", "firmware_version", "labels",
+	"online", "last_seen", "latitude", "longitude", "altitude", "battery", "status_timestamp",
+}
+
+// WriteDeviceExport writes devices as CSV, NDJSON or Parquet. CSV and
+// Parquet files have one column per field in exportColumns; devices that
+// have never reported a status have zero status columns.
+func WriteDeviceExport(w io.Writer, format string, devices []DeviceExport) error {
+	switch format {
+	case FormatCSV:
+		return writeExportCSV(w, devices)
+	case FormatNDJSON:
+		enc := json.NewEncoder(w)
+		for _, d := range devices {
+			if err := enc.Encode(d); err != nil {
+				return err
+			}
+		}
+		return nil
+	case FormatParquet:
+		return writeExportParquet(w, devices)
+	}
+	return fmt.Errorf("%v %q", errUnknownFormat, format)
+}
+
+func (d DeviceExport) status() DeviceStatus {
+	if d.Status == nil {
+		return DeviceStatus{}
+	}
+	return *d.Status
+}
+
+func writeExportCSV(w io.Writer, devices []DeviceExport) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(exportColumns); err != nil {
+		return err
+	}
+	for _, d := range devices {
+		s := d.status()
+		err := cw.Write([]string{
+			strconv.FormatUint(d.ID, 10),
+			d.Name,
+			d.Owner,
+			d.DeviceType,
+			strconv.FormatUint(d.GatewayID, 10),
+			d.FirmwareVersion,
+			formatLabels(d.Labels),
+			strconv.FormatBool(d.Online),
+			strconv.FormatInt(d.LastSeen, 10),
+			strconv.FormatFloat(float64(s.Latitude), 'g', -1, 32),
+			strconv.FormatFloat(float64(s.Longitude), 'g', -1, 32),
+			strconv.FormatFloat(float64(s.Altitude), 'g', -1, 32),
+			strconv.FormatUint(uint64(s.Battery), 10),
+			strconv.FormatInt(s.Timestamp, 10),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeExportParquet(w io.Writer, devices []DeviceExport) error {
+	var t parquetTable
+	id := t.column("id", parquetInt64)
+	name := t.column("name", parquetByteArray)
+	owner := t.column("owner", parquetByteArray)
+	deviceType := t.column("device_type", parquetByteArray)
+	gateway := t.column("gateway_id", parquetInt64)
+	firmware := t.column("firmware_version", parquetByteArray)
+	labels := t.column("labels", parquetByteArray)
+	online := t.column("online", parquetBoolean)
+	lastSeen := t.column("last_seen", parquetInt64)
+	lat := t.column("latitude", parquetDouble)
+	long := t.column("longitude", parquetDouble)
+	alt := t.column("altitude", parquetDouble)
+	battery := t.column("battery", parquetInt64)
+	timestamp := t.column("status_timestamp", parquetInt64)
+
+	for _, d := range devices {
+		s := d.status()
+		id.appendInt64(int64(d.ID))
+		name.appendString(d.Name)
+		owner.appendString(d.Owner)
+		deviceType.appendString(d.DeviceType)
+		gateway.appendInt64(int64(d.GatewayID))
+		firmware.appendString(d.FirmwareVersion)
+		labels.appendString(formatLabels(d.Labels))
+		online.appendBool(d.Online)
+		lastSeen.appendInt64(d.LastSeen)
+		lat.appendDouble(float64(s.Latitude))
+		long.appendDouble(float64(s.Longitude))
+		alt.appendDouble(float64(s.Altitude))
+		battery.appendInt64(int64(s.Battery))
+		timestamp.appendInt64(s.Timestamp)
+	}
+	return t.writeTo(w)
+}
@@ -0,0 +1,256 @@
+// Package client connects to one or more monitord instances, over gRPC or
+// JSON/HTTP. The Client it
+// returns implements iotmonitor.Service, so callers use the monitor as they
+// would in-process and never see the transport:
+//
+//	c, err := client.NewGRPC([]string{"monitor-1:8081", "monitor-2:8081"},
+//		client.Token(os.Getenv("IOTMONITOR_TOKEN")),
+//		client.Timeout(5*time.Second),
+//		client.Retries(3, 100*time.Millisecond))
+//	if err != nil {
+//		return err
+//	}
+//	defer c.Close()
+//	id, err := c.RegisterDevice(ctx, "Drone Alpha 1", "kevin", "Drone", 0, nil)
+//
+// Calls are spread round-robin over the instances. A call that fails in
+// transport, as opposed to one the service refuses, is retried on the next
+// instance after an exponential backoff. Calls that create something, such
+// as RegisterDevice, are only retried if the failed attempt never reached
+// the service, so that a retry can't create it twice. WatchEvents follows the event
+// stream of one instance at a time.
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sync/atomic"
+	"time"
+
+	"github.com/autodidaddict/iotmonitor"
+	"github.com/go-kit/kit/endpoint"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Client is a monitor reached over the network.
+type Client struct {
+	next uint64 // first for 64-bit alignment
+	iotmonitor.Endpoints
+	watchers []watcher
+	close    func() error
+}
+
+// watcher follows the event stream of one instance.
+type watcher func(ctx context.Context, filter iotmonitor.EventFilter, handle func(iotmonitor.Event) error) error
+
+// WatchEvents calls handle with each event filter picks as it happens, until
+// ctx is done, handle fails or the stream breaks, and returns why. Streams
+// aren't retried or timed out: each call opens one on the next instance, and
+// a caller that wants to keep watching calls again after a backoff, missing
+// the events published in between.
+func (c *Client) WatchEvents(ctx context.Context, filter iotmonitor.EventFilter, handle func(iotmonitor.Event) error) error {
+	w := c.watchers[(atomic.AddUint64(&c.next, 1)-1)%uint64(len(c.watchers))]
+	return w(ctx, filter, handle)
+}
+
+// Close releases the client's connections.
+func (c *Client) Close() error {
+	return c.close()
+}
+
+// Option configures a Client.
+type Option func(*options)
+
+type options struct {
+	timeout    time.Duration
+	retries    int
+	backoff    time.Duration
+	maxBackoff time.Duration
+	token      string
+	tls        *tls.Config
+	dial       []grpc.DialOption
+	retryable  func(error) bool
+	unsent     func(error) bool
+}
+
+func defaultOptions() options {
+	return options{
+		timeout:    10 * time.Second,
+		backoff:    100 * time.Millisecond,
+		maxBackoff: 5 * time.Second,
+		retryable:  Retryable,
+		unsent:     Unsent,
+	}
+}
+
+// Timeout limits each call, retries included. The default is 10s; 0 leaves
+// calls bounded only by their context.
+func Timeout(d time.Duration) Option {
+	return func(o *options) { o.timeout = d }
+}
+
+// Retries retries a call that fails in transport up to n more times. The
+// first retry waits backoff, and each one after that twice as long as the
+// one before, up to 5s, with jitter.
+func Retries(n int, backoff time.Duration) Option {
+	return func(o *options) { o.retries, o.backoff = n, backoff }
+}
+
+// RetryIf replaces the test for which errors are worth retrying on calls
+// that are safe to repeat. The others are still only retried if Unsent.
+func RetryIf(retryable func(error) bool) Option {
+	return func(o *options) { o.retryable = retryable }
+}
+
+// Token sends token as a bearer token with every call.
+func Token(token string) Option {
+	return func(o *options) { o.token = token }
+}
+
+// TLS connects with TLS, configured by config. Without it connections are
+// plaintext.
+func TLS(config *tls.Config) Option {
+	return func(o *options) { o.tls = config }
+}
+
+// DialOptions adds options for dialing gRPC instances. NewHTTP ignores
+// them.
+func DialOptions(dial ...grpc.DialOption) Option {
+	return func(o *options) { o.dial = append(o.dial, dial...) }
+}
+
+// Retryable reports whether err is a transport failure that another
+// attempt, perhaps on another instance, could get past: an instance that
+// can't be reached or is overloaded. Calls are only retried while their own
+// deadline has yet to pass.
+func Retryable(err error) bool {
+	if _, ok := err.(net.Error); ok {
+		return true
+	}
+	if s, ok := err.(interface {
+		StatusCode() int
+	}); ok {
+		switch s.StatusCode() {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+	if s, ok := status.FromError(err); ok {
+		switch s.Code() {
+		case codes.Unavailable, codes.ResourceExhausted, codes.Aborted, codes.DeadlineExceeded:
+			return true
+		}
+	}
+	return false
+}
+
+// Unsent reports whether err shows that the request never reached the
+// service, so that even a call that isn't safe to repeat can be retried:
+// an HTTP instance couldn't be dialed, or an instance turned the call away
+// over its rate limit before running it. gRPC reports a failed dial as
+// Unavailable, just as it does a connection lost mid-call, so such calls to
+// a gRPC instance that is down aren't retried.
+func Unsent(err error) bool {
+	if u, ok := err.(*url.Error); ok {
+		err = u.Err
+	}
+	if op, ok := err.(*net.OpError); ok {
+		return op.Op == "dial"
+	}
+	if s, ok := err.(interface {
+		StatusCode() int
+	}); ok {
+		return s.StatusCode() == http.StatusTooManyRequests
+	}
+	if s, ok := status.FromError(err); ok {
+		return s.Code() == codes.ResourceExhausted
+	}
+	return false
+}
+
+// unrepeatable names the endpoints whose calls a repeat would apply twice,
+// registering a second device, queueing a second command and so on.
+var unrepeatable = map[string]bool{
+	"RegisterEndpoint":       true,
+	"TelemetryEndpoint":      true,
+	"RelayTelemetryEndpoint": true,
+	"CreateRuleEndpoint":     true,
+	"CreateWebhookEndpoint":  true,
+	"CreateGeofenceEndpoint": true,
+	"CreateGroupEndpoint":    true,
+	"EnqueueCommandEndpoint": true,
+	"UploadFirmwareEndpoint": true,
+	"CreateCampaignEndpoint": true,
+	"ImportDevicesEndpoint":  true,
+}
+
+var errNoInstances = errors.New("no monitor instances given")
+
+// balance combines the endpoints of each instance into one set that spreads
+// calls over them, retrying and timing them out as o says.
+func balance(instances []iotmonitor.Endpoints, o options) iotmonitor.Endpoints {
+	var balanced iotmonitor.Endpoints
+	out := reflect.ValueOf(&balanced).Elem()
+	for i := 0; i < out.NumField(); i++ {
+		each := make([]endpoint.Endpoint, len(instances))
+		for j, e := range instances {
+			each[j] = reflect.ValueOf(e).Field(i).Interface().(endpoint.Endpoint)
+		}
+		retryable := o.retryable
+		if unrepeatable[out.Type().Field(i).Name] {
+			retryable = o.unsent
+		}
+		out.Field(i).Set(reflect.ValueOf(o.roundRobin(each, retryable)))
+	}
+	return balanced
+}
+
+// roundRobin returns an endpoint that calls each of endpoints in turn,
+// retrying the errors retryable accepts.
+func (o options) roundRobin(endpoints []endpoint.Endpoint, retryable func(error) bool) endpoint.Endpoint {
+	var next uint64
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		if o.timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, o.timeout)
+			defer cancel()
+		}
+		for attempt := 0; ; attempt++ {
+			e := endpoints[(atomic.AddUint64(&next, 1)-1)%uint64(len(endpoints))]
+			resp, err := e(ctx, request)
+			if err == nil || attempt >= o.retries || !retryable(err) || ctx.Err() != nil {
+				return resp, err
+			}
+			select {
+			case <-ctx.Done():
+				return resp, err
+			case <-time.After(o.wait(attempt)):
+			}
+		}
+	}
+}
+
+// wait returns how long to back off before retry n+1: the backoff doubled n
+// times, capped, less up to half of it at random.
+func (o options) wait(n int) time.Duration {
+	d := o.backoff
+	for i := 0; i < n && d < o.maxBackoff; i++ {
+		d *= 2
+	}
+	if d > o.maxBackoff {
+		d = o.maxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d - time.Duration(rand.Int63n(int64(d)/2+1))
+}
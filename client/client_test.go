@@ -0,0 +1,192 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/autodidaddict/iotmonitor"
+	"github.com/go-kit/kit/endpoint"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// statusError is an HTTP error reply, as the HTTP client endpoints return.
+type statusError int
+
+func (e statusError) Error() string   { return http.StatusText(int(e)) }
+func (e statusError) StatusCode() int { return int(e) }
+
+var (
+	dialErr = &url.Error{Op: "Post", URL: "http://monitor/v1/devices", Err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}}
+	readErr = &url.Error{Op: "Post", URL: "http://monitor/v1/devices", Err: &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}}
+)
+
+func TestRetryableAndUnsent(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		retryable bool
+		unsent    bool
+	}{
+		{"dial", dialErr, true, true},
+		{"read", readErr, true, false},
+		{"too many requests", statusError(http.StatusTooManyRequests), true, true},
+		{"bad gateway", statusError(http.StatusBadGateway), true, false},
+		{"unavailable status", statusError(http.StatusServiceUnavailable), true, false},
+		{"not found", statusError(http.StatusNotFound), false, false},
+		{"gRPC resource exhausted", status.Error(codes.ResourceExhausted, "rate limited"), true, true},
+		{"gRPC unavailable", status.Error(codes.Unavailable, "connection refused"), true, false},
+		{"gRPC deadline", status.Error(codes.DeadlineExceeded, "deadline"), true, false},
+		{"gRPC invalid", status.Error(codes.InvalidArgument, "no name"), false, false},
+		{"service error", errors.New("device not found"), false, false},
+	}
+	for _, tt := range tests {
+		if got := Retryable(tt.err); got != tt.retryable {
+			t.Errorf("%s: Retryable() = %v, want %v", tt.name, got, tt.retryable)
+		}
+		if got := Unsent(tt.err); got != tt.unsent {
+			t.Errorf("%s: Unsent() = %v, want %v", tt.name, got, tt.unsent)
+		}
+	}
+}
+
+// stubInstance returns endpoints that all record which instance they were
+// called on and fail with the next of errs, then succeed.
+func stubInstance(id int, calls *[]int, errs *[]error) iotmonitor.Endpoints {
+	var e iotmonitor.Endpoints
+	stub := endpoint.Endpoint(func(ctx context.Context, request interface{}) (interface{}, error) {
+		*calls = append(*calls, id)
+		if len(*errs) == 0 {
+			return id, nil
+		}
+		err := (*errs)[0]
+		*errs = (*errs)[1:]
+		return nil, err
+	})
+	v := reflect.ValueOf(&e).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		v.Field(i).Set(reflect.ValueOf(stub))
+	}
+	return e
+}
+
+func TestBalanceRetries(t *testing.T) {
+	unavailable := statusError(http.StatusServiceUnavailable)
+	tests := []struct {
+		name      string
+		endpoint  func(iotmonitor.Endpoints) endpoint.Endpoint
+		errs      []error
+		wantCalls []int
+		wantErr   error
+	}{
+		{"success", func(e iotmonitor.Endpoints) endpoint.Endpoint { return e.GetDeviceEndpoint }, nil, []int{0}, nil},
+		{
+			"idempotent retried on the next instance",
+			func(e iotmonitor.Endpoints) endpoint.Endpoint { return e.GetDeviceEndpoint },
+			[]error{unavailable, readErr},
+			[]int{0, 1, 2},
+			nil,
+		},
+		{
+			"idempotent out of retries",
+			func(e iotmonitor.Endpoints) endpoint.Endpoint { return e.ListDevicesEndpoint },
+			[]error{unavailable, unavailable, unavailable, unavailable},
+			[]int{0, 1, 2},
+			unavailable,
+		},
+		{
+			"service error not retried",
+			func(e iotmonitor.Endpoints) endpoint.Endpoint { return e.GetDeviceEndpoint },
+			[]error{statusError(http.StatusNotFound)},
+			[]int{0},
+			statusError(http.StatusNotFound),
+		},
+		{
+			"register not retried once sent",
+			func(e iotmonitor.Endpoints) endpoint.Endpoint { return e.RegisterEndpoint },
+			[]error{readErr},
+			[]int{0},
+			readErr,
+		},
+		{
+			"enqueue not retried on unavailable",
+			func(e iotmonitor.Endpoints) endpoint.Endpoint { return e.EnqueueCommandEndpoint },
+			[]error{status.Error(codes.Unavailable, "transport is closing")},
+			[]int{0},
+			status.Error(codes.Unavailable, "transport is closing"),
+		},
+		{
+			"register retried when unsent",
+			func(e iotmonitor.Endpoints) endpoint.Endpoint { return e.RegisterEndpoint },
+			[]error{dialErr, statusError(http.StatusTooManyRequests)},
+			[]int{0, 1, 2},
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		var calls []int
+		errs := tt.errs
+		instances := []iotmonitor.Endpoints{stubInstance(0, &calls, &errs), stubInstance(1, &calls, &errs), stubInstance(2, &calls, &errs)}
+		o := defaultOptions()
+		o.retries, o.backoff = 2, 0
+		balanced := balance(instances, o)
+		_, err := tt.endpoint(balanced)(context.Background(), nil)
+		if !reflect.DeepEqual(err, tt.wantErr) {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.wantErr)
+		}
+		if !reflect.DeepEqual(calls, tt.wantCalls) {
+			t.Errorf("%s: called instances %v, want %v", tt.name, calls, tt.wantCalls)
+		}
+	}
+}
+
+func TestRoundRobinStopsAtDeadline(t *testing.T) {
+	calls := 0
+	e := func(ctx context.Context, request interface{}) (interface{}, error) {
+		calls++
+		return nil, statusError(http.StatusServiceUnavailable)
+	}
+	o := defaultOptions()
+	o.retries, o.backoff, o.timeout = 100, 20*time.Millisecond, 50*time.Millisecond
+	start := time.Now()
+	if _, err := o.roundRobin([]endpoint.Endpoint{e}, Retryable)(context.Background(), nil); err == nil {
+		t.Fatal("roundRobin() succeeded")
+	}
+	if calls > 5 || time.Since(start) > time.Second {
+		t.Errorf("%d calls in %v, want retries to stop at the timeout", calls, time.Since(start))
+	}
+}
+
+func TestWait(t *testing.T) {
+	o := defaultOptions()
+	o.backoff = 100 * time.Millisecond
+	tests := []struct {
+		n   int
+		max time.Duration
+	}{
+		{0, 100 * time.Millisecond},
+		{1, 200 * time.Millisecond},
+		{4, 1600 * time.Millisecond},
+		{10, 5 * time.Second},
+	}
+	for _, tt := range tests {
+		if d := o.wait(tt.n); d > tt.max || d < tt.max/2 {
+			t.Errorf("wait(%d) = %v, want between %v and %v", tt.n, d, tt.max/2, tt.max)
+		}
+	}
+}
+
+func TestUnrepeatableEndpointsExist(t *testing.T) {
+	fields := reflect.TypeOf(iotmonitor.Endpoints{})
+	for name := range unrepeatable {
+		if _, ok := fields.FieldByName(name); !ok {
+			t.Errorf("unrepeatable names %s, which Endpoints lacks", name)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package client
+
+import (
+	"context"
+
+	"github.com/autodidaddict/iotmonitor"
+	grpctransport "github.com/go-kit/kit/transport/grpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+)
+
+// NewGRPC returns a client for the monitord instances at the gRPC
+// addresses addrs. Connections are made lazily, so an instance that is down
+// fails only the calls sent to it, and those are retried elsewhere if
+// Retries allows.
+func NewGRPC(addrs []string, opts ...Option) (*Client, error) {
+	if len(addrs) == 0 {
+		return nil, errNoInstances
+	}
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	dial := []grpc.DialOption{grpc.WithInsecure()}
+	if o.tls != nil {
+		dial[0] = grpc.WithTransportCredentials(credentials.NewTLS(o.tls))
+	}
+	dial = append(dial, o.dial...)
+	var callOptions []grpctransport.ClientOption
+	if o.token != "" {
+		callOptions = append(callOptions, grpctransport.ClientBefore(grpctransport.SetRequestHeader("authorization", "Bearer "+o.token)))
+	}
+
+	conns := make([]*grpc.ClientConn, 0, len(addrs))
+	closeAll := func() error {
+		var first error
+		for _, conn := range conns {
+			if err := conn.Close(); err != nil && first == nil {
+				first = err
+			}
+		}
+		return first
+	}
+	instances := make([]iotmonitor.Endpoints, len(addrs))
+	watchers := make([]watcher, len(addrs))
+	for i, addr := range addrs {
+		conn, err := grpc.Dial(addr, dial...)
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		conns = append(conns, conn)
+		instances[i] = iotmonitor.NewGRPCClient(conn, callOptions...)
+		watchers[i] = func(ctx context.Context, filter iotmonitor.EventFilter, handle func(iotmonitor.Event) error) error {
+			if o.token != "" {
+				ctx = metadata.NewOutgoingContext(ctx, metadata.Pairs("authorization", "Bearer "+o.token))
+			}
+			return iotmonitor.WatchEventsGRPC(ctx, conn, filter, handle)
+		}
+	}
+	return &Client{Endpoints: balance(instances, o), watchers: watchers, close: closeAll}, nil
+}
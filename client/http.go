@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/autodidaddict/iotmonitor"
+	httptransport "github.com/go-kit/kit/transport/http"
+)
+
+// NewHTTP returns a client for the monitord instances whose JSON/HTTP APIs
+// are at the base URLs urls. It speaks HTTP/1.1, so it also gets through
+// proxies that don't pass HTTP/2.
+func NewHTTP(urls []string, opts ...Option) (*Client, error) {
+	if len(urls) == 0 {
+		return nil, errNoInstances
+	}
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	transport := &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		TLSClientConfig:     o.tls,
+		MaxIdleConnsPerHost: 16,
+	}
+	httpClient := &http.Client{Transport: transport}
+	options := []httptransport.ClientOption{httptransport.SetClient(httpClient)}
+	header := http.Header{}
+	if o.token != "" {
+		options = append(options, httptransport.ClientBefore(httptransport.SetRequestHeader("Authorization", "Bearer "+o.token)))
+		header.Set("Authorization", "Bearer "+o.token)
+	}
+
+	instances := make([]iotmonitor.Endpoints, len(urls))
+	watchers := make([]watcher, len(urls))
+	for i, u := range urls {
+		e, err := iotmonitor.NewHTTPClient(u, options...)
+		if err != nil {
+			return nil, err
+		}
+		instances[i] = e
+		u := u
+		watchers[i] = func(ctx context.Context, filter iotmonitor.EventFilter, handle func(iotmonitor.Event) error) error {
+			return iotmonitor.WatchEventsHTTP(ctx, httpClient, u, header, filter, handle)
+		}
+	}
+	closeIdle := func() error {
+		transport.CloseIdleConnections()
+		return nil
+	}
+	return &Client{Endpoints: balance(instances, o), watchers: watchers, close: closeIdle}, nil
+}
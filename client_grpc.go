@@ -0,0 +1,121 @@
+package iotmonitor
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/autodidaddict/iotmonitor/pb"
+	"github.com/go-kit/kit/endpoint"
+	grpctransport "github.com/go-kit/kit/transport/grpc"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+const grpcServiceName = "pb.Monitor"
+
+// NewGRPCClient returns endpoints that call the monitor over conn. The
+// options apply to every call, e.g. to set an authorization header.
+//
+// The deliveries a device picks up with its status updates have no RPC of
+// their own, so the endpoints for them fail; devices read them from the
+// status update reply or the WatchDesired and WatchCommands streams.
+func NewGRPCClient(conn *grpc.ClientConn, options ...grpctransport.ClientOption) Endpoints {
+	client := func(method string, enc grpctransport.EncodeRequestFunc, dec grpctransport.DecodeResponseFunc, reply interface{}) endpoint.Endpoint {
+		return grpctransport.NewClient(conn, grpcServiceName, method, enc, dec, reply, options...).Endpoint()
+	}
+
+	return Endpoints{
+		RegisterEndpoint:              client("RegisterDevice", EncodeGRPCRegisterRequest, DecodeGRPCRegisterResponse, pb.RegisterDeviceReply{}),
+		UpdateEndpoint:                client("UpdateDeviceStatus", EncodeGRPCUpdateRequest, DecodeGRPCUpdateResponse, pb.StatusUpdateReply{}),
+		TelemetryEndpoint:             client("SubmitTelemetry", EncodeGRPCTelemetryRequest, DecodeGRPCTelemetryResponse, pb.TelemetrySubmitReply{}),
+		CreateRuleEndpoint:            client("CreateRule", EncodeGRPCCreateRuleRequest, DecodeGRPCCreateRuleResponse, pb.CreateRuleReply{}),
+		GetRuleEndpoint:               client("GetRule", EncodeGRPCGetRuleRequest, DecodeGRPCGetRuleResponse, pb.GetRuleReply{}),
+		UpdateRuleEndpoint:            client("UpdateRule", EncodeGRPCUpdateRuleRequest, DecodeGRPCUpdateRuleResponse, pb.UpdateRuleReply{}),
+		DeleteRuleEndpoint:            client("DeleteRule", EncodeGRPCDeleteRuleRequest, DecodeGRPCDeleteRuleResponse, pb.DeleteRuleReply{}),
+		ListRulesEndpoint:             client("ListRules", EncodeGRPCListRulesRequest, DecodeGRPCListRulesResponse, pb.ListRulesReply{}),
+		ActiveAlertsEndpoint:          client("ActiveAlerts", EncodeGRPCActiveAlertsRequest, DecodeGRPCActiveAlertsResponse, pb.ActiveAlertsReply{}),
+		CreateWebhookEndpoint:         client("CreateWebhook", EncodeGRPCCreateWebhookRequest, DecodeGRPCCreateWebhookResponse, pb.CreateWebhookReply{}),
+		ListWebhooksEndpoint:          client("ListWebhooks", EncodeGRPCListWebhooksRequest, DecodeGRPCListWebhooksResponse, pb.ListWebhooksReply{}),
+		DeleteWebhookEndpoint:         client("DeleteWebhook", EncodeGRPCDeleteWebhookRequest, DecodeGRPCDeleteWebhookResponse, pb.DeleteWebhookReply{}),
+		DeadLettersEndpoint:           client("DeadLetters", EncodeGRPCDeadLettersRequest, DecodeGRPCDeadLettersResponse, pb.DeadLettersReply{}),
+		GetDeviceEndpoint:             client("GetDevice", EncodeGRPCGetDeviceRequest, DecodeGRPCGetDeviceResponse, pb.GetDeviceReply{}),
+		ListDevicesEndpoint:           client("ListDevices", EncodeGRPCListDevicesRequest, DecodeGRPCListDevicesResponse, pb.ListDevicesReply{}),
+		CreateGeofenceEndpoint:        client("CreateGeofence", EncodeGRPCCreateGeofenceRequest, DecodeGRPCCreateGeofenceResponse, pb.CreateGeofenceReply{}),
+		UpdateGeofenceEndpoint:        client("UpdateGeofence", EncodeGRPCUpdateGeofenceRequest, DecodeGRPCUpdateGeofenceResponse, pb.UpdateGeofenceReply{}),
+		DeleteGeofenceEndpoint:        client("DeleteGeofence", EncodeGRPCDeleteGeofenceRequest, DecodeGRPCDeleteGeofenceResponse, pb.DeleteGeofenceReply{}),
+		ListGeofencesEndpoint:         client("ListGeofences", EncodeGRPCListGeofencesRequest, DecodeGRPCListGeofencesResponse, pb.ListGeofencesReply{}),
+		NearbyDevicesEndpoint:         client("NearbyDevices", EncodeGRPCNearbyDevicesRequest, DecodeGRPCNearbyDevicesResponse, pb.NearbyDevicesReply{}),
+		DevicesInBoxEndpoint:          client("DevicesInBox", EncodeGRPCDevicesInBoxRequest, DecodeGRPCDevicesInBoxResponse, pb.DevicesInBoxReply{}),
+		GetStatusEndpoint:             client("GetStatus", EncodeGRPCGetStatusRequest, DecodeGRPCGetStatusResponse, pb.GetStatusReply{}),
+		BatteryHistoryEndpoint:        client("BatteryHistory", EncodeGRPCBatteryHistoryRequest, DecodeGRPCBatteryHistoryResponse, pb.BatteryHistoryReply{}),
+		QueryTelemetryEndpoint:        client("QueryTelemetry", EncodeGRPCQueryTelemetryRequest, DecodeGRPCQueryTelemetryResponse, pb.QueryTelemetryReply{}),
+		AggregateTelemetryEndpoint:    client("AggregateTelemetry", EncodeGRPCAggregateTelemetryRequest, DecodeGRPCAggregateTelemetryResponse, pb.AggregateTelemetryReply{}),
+		SetLabelsEndpoint:             client("SetLabels", EncodeGRPCSetLabelsRequest, DecodeGRPCSetLabelsResponse, pb.SetLabelsReply{}),
+		ListStatusesEndpoint:          client("ListStatuses", EncodeGRPCListStatusesRequest, DecodeGRPCListStatusesResponse, pb.ListStatusesReply{}),
+		CreateGroupEndpoint:           client("CreateGroup", EncodeGRPCCreateGroupRequest, DecodeGRPCCreateGroupResponse, pb.CreateGroupReply{}),
+		UpdateGroupEndpoint:           client("UpdateGroup", EncodeGRPCUpdateGroupRequest, DecodeGRPCUpdateGroupResponse, pb.UpdateGroupReply{}),
+		DeleteGroupEndpoint:           client("DeleteGroup", EncodeGRPCDeleteGroupRequest, DecodeGRPCDeleteGroupResponse, pb.DeleteGroupReply{}),
+		ListGroupsEndpoint:            client("ListGroups", EncodeGRPCListGroupsRequest, DecodeGRPCListGroupsResponse, pb.ListGroupsReply{}),
+		AddGroupMembersEndpoint:       client("AddGroupMembers", EncodeGRPCAddGroupMembersRequest, DecodeGRPCAddGroupMembersResponse, pb.AddGroupMembersReply{}),
+		RemoveGroupMembersEndpoint:    client("RemoveGroupMembers", EncodeGRPCRemoveGroupMembersRequest, DecodeGRPCRemoveGroupMembersResponse, pb.RemoveGroupMembersReply{}),
+		RelayStatusEndpoint:           client("RelayStatus", EncodeGRPCRelayStatusRequest, DecodeGRPCRelayStatusResponse, pb.RelayStatusReply{}),
+		RelayTelemetryEndpoint:        client("RelayTelemetry", EncodeGRPCRelayTelemetryRequest, DecodeGRPCRelayTelemetryResponse, pb.RelayTelemetryReply{}),
+		GetTopologyEndpoint:           client("GetTopology", EncodeGRPCGetTopologyRequest, DecodeGRPCGetTopologyResponse, pb.GetTopologyReply{}),
+		GetTwinEndpoint:               client("GetTwin", EncodeGRPCGetTwinRequest, DecodeGRPCGetTwinResponse, pb.GetTwinReply{}),
+		UpdateDesiredEndpoint:         client("UpdateDesired", EncodeGRPCUpdateDesiredRequest, DecodeGRPCUpdateDesiredResponse, pb.UpdateDesiredReply{}),
+		ReportStateEndpoint:           client("ReportState", EncodeGRPCReportStateRequest, DecodeGRPCReportStateResponse, pb.ReportStateReply{}),
+		EnqueueCommandEndpoint:        client("EnqueueCommand", EncodeGRPCEnqueueCommandRequest, DecodeGRPCEnqueueCommandResponse, pb.EnqueueCommandReply{}),
+		AckCommandEndpoint:            client("AckCommand", EncodeGRPCAckCommandRequest, DecodeGRPCAckCommandResponse, pb.AckCommandReply{}),
+		CommandHistoryEndpoint:        client("CommandHistory", EncodeGRPCCommandHistoryRequest, DecodeGRPCCommandHistoryResponse, pb.CommandHistoryReply{}),
+		UploadFirmwareEndpoint:        client("UploadFirmware", EncodeGRPCUploadFirmwareRequest, DecodeGRPCUploadFirmwareResponse, pb.UploadFirmwareReply{}),
+		ListFirmwareEndpoint:          client("ListFirmware", EncodeGRPCListFirmwareRequest, DecodeGRPCListFirmwareResponse, pb.ListFirmwareReply{}),
+		DownloadFirmwareEndpoint:      client("DownloadFirmware", EncodeGRPCDownloadFirmwareRequest, DecodeGRPCDownloadFirmwareResponse, pb.DownloadFirmwareReply{}),
+		CreateCampaignEndpoint:        client("CreateCampaign", EncodeGRPCCreateCampaignRequest, DecodeGRPCCreateCampaignResponse, pb.CreateCampaignReply{}),
+		GetCampaignEndpoint:           client("GetCampaign", EncodeGRPCGetCampaignRequest, DecodeGRPCGetCampaignResponse, pb.GetCampaignReply{}),
+		ListCampaignsEndpoint:         client("ListCampaigns", EncodeGRPCListCampaignsRequest, DecodeGRPCListCampaignsResponse, pb.ListCampaignsReply{}),
+		ControlCampaignEndpoint:       client("ControlCampaign", EncodeGRPCControlCampaignRequest, DecodeGRPCControlCampaignResponse, pb.ControlCampaignReply{}),
+		ReportFirmwareEndpoint:        client("ReportFirmware", EncodeGRPCReportFirmwareRequest, DecodeGRPCReportFirmwareResponse, pb.ReportFirmwareReply{}),
+		SetTelemetrySchemaEndpoint:    client("SetTelemetrySchema", EncodeGRPCSetTelemetrySchemaRequest, DecodeGRPCSetTelemetrySchemaResponse, pb.SetTelemetrySchemaReply{}),
+		GetTelemetrySchemaEndpoint:    client("GetTelemetrySchema", EncodeGRPCGetTelemetrySchemaRequest, DecodeGRPCGetTelemetrySchemaResponse, pb.GetTelemetrySchemaReply{}),
+		ListTelemetrySchemasEndpoint:  client("ListTelemetrySchemas", EncodeGRPCListTelemetrySchemasRequest, DecodeGRPCListTelemetrySchemasResponse, pb.ListTelemetrySchemasReply{}),
+		DeleteTelemetrySchemaEndpoint: client("DeleteTelemetrySchema", EncodeGRPCDeleteTelemetrySchemaRequest, DecodeGRPCDeleteTelemetrySchemaResponse, pb.DeleteTelemetrySchemaReply{}),
+		ImportDevicesEndpoint:         client("ImportDevices", EncodeGRPCImportDevicesRequest, DecodeGRPCImportDevicesResponse, pb.ImportDevicesReply{}),
+		ExportDevicesEndpoint:         client("ExportDevices", EncodeGRPCExportDevicesRequest, DecodeGRPCExportDevicesResponse, pb.ExportDevicesReply{}),
+		UpdateDeviceEndpoint:          client("UpdateDevice", EncodeGRPCUpdateDeviceRequest, DecodeGRPCUpdateDeviceResponse, pb.UpdateDeviceReply{}),
+		DeleteDeviceEndpoint:          client("DeleteDevice", EncodeGRPCDeleteDeviceRequest, DecodeGRPCDeleteDeviceResponse, pb.DeleteDeviceReply{}),
+
+		DeliverDesiredEndpoint:  notServed("DeliverDesired"),
+		DeliverCommandsEndpoint: notServed("DeliverCommands"),
+		OfferFirmwareEndpoint:   notServed("OfferFirmware"),
+	}
+}
+
+// notServed stands in for an endpoint the transports don't serve.
+func notServed(method string) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		return nil, fmt.Errorf("%s is only served with status updates", method)
+	}
+}
+
+// WatchEventsGRPC calls handle with each event filter picks from the monitor
+// at conn until ctx is done, handle fails or the stream ends, and returns
+// why. A stream the monitor ends as it shuts down fails with Unavailable.
+// The options apply to the call, e.g. to set an authorization header.
+func WatchEventsGRPC(ctx context.Context, conn *grpc.ClientConn, filter EventFilter, handle func(Event) error, options ...grpc.CallOption) error {
+	stream, err := pb.NewMonitorClient(conn).WatchEvents(ctx, &pb.WatchEventsRequest{Types: filter.Types, Filter: deviceFilterToPB(filter.Devices)}, options...)
+	if err != nil {
+		return err
+	}
+	for {
+		e, err := stream.Recv()
+		if err == io.EOF {
+			return errStreamEnded
+		}
+		if err != nil {
+			return err
+		}
+		if err := handle(eventFromPB(e)); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package iotmonitor
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/go-kit/kit/endpoint"
+	httptransport "github.com/go-kit/kit/transport/http"
+	"golang.org/x/net/context"
+)
+
+// NewHTTPClient returns endpoints that call the monitor's JSON/HTTP API at
+// instance, a base URL such as http://monitor:8080. The options apply to
+// every call, e.g. to set an authorization header or use a particular
+// http.Client.
+//
+// As with NewGRPCClient, the deliveries a device picks up with its status
+// updates have no route of their own, so the endpoints for them fail.
+func NewHTTPClient(instance string, options ...httptransport.ClientOption) (Endpoints, error) {
+	if !strings.Contains(instance, "://") {
+		instance = "http://" + instance
+	}
+	base, err := url.Parse(instance)
+	if err != nil {
+		return Endpoints{}, err
+	}
+	client := func(method string, enc httptransport.EncodeRequestFunc, dec httptransport.DecodeResponseFunc) endpoint.Endpoint {
+		return httptransport.NewClient(method, base, enc, dec, options...).Endpoint()
+	}
+
+	return Endpoints{
+		RegisterEndpoint:              client("POST", EncodeHTTPRegisterRequest, DecodeHTTPRegisterResponse),
+		UpdateEndpoint:                client("PUT", EncodeHTTPUpdateRequest, DecodeHTTPUpdateResponse),
+		TelemetryEndpoint:             client("PUT", EncodeHTTPTelemetryRequest, DecodeHTTPTelemetryResponse),
+		CreateRuleEndpoint:            client("POST", EncodeHTTPCreateRuleRequest, DecodeHTTPCreateRuleResponse),
+		GetRuleEndpoint:               client("GET", EncodeHTTPGetRuleRequest, DecodeHTTPGetRuleResponse),
+		UpdateRuleEndpoint:            client("PUT", EncodeHTTPUpdateRuleRequest, DecodeHTTPUpdateRuleResponse),
+		DeleteRuleEndpoint:            client("DELETE", EncodeHTTPDeleteRuleRequest, DecodeHTTPDeleteRuleResponse),
+		ListRulesEndpoint:             client("GET", EncodeHTTPListRulesRequest, DecodeHTTPListRulesResponse),
+		ActiveAlertsEndpoint:          client("GET", EncodeHTTPActiveAlertsRequest, DecodeHTTPActiveAlertsResponse),
+		CreateWebhookEndpoint:         client("POST", EncodeHTTPCreateWebhookRequest, DecodeHTTPCreateWebhookResponse),
+		ListWebhooksEndpoint:          client("GET", EncodeHTTPListWebhooksRequest, DecodeHTTPListWebhooksResponse),
+		DeleteWebhookEndpoint:         client("DELETE", EncodeHTTPDeleteWebhookRequest, DecodeHTTPDeleteWebhookResponse),
+		DeadLettersEndpoint:           client("GET", EncodeHTTPDeadLettersRequest, DecodeHTTPDeadLettersResponse),
+		GetDeviceEndpoint:             client("GET", EncodeHTTPGetDeviceRequest, DecodeHTTPGetDeviceResponse),
+		ListDevicesEndpoint:           client("GET", EncodeHTTPListDevicesRequest, DecodeHTTPListDevicesResponse),
+		CreateGeofenceEndpoint:        client("POST", EncodeHTTPCreateGeofenceRequest, DecodeHTTPCreateGeofenceResponse),
+		UpdateGeofenceEndpoint:        client("PUT", EncodeHTTPUpdateGeofenceRequest, DecodeHTTPUpdateGeofenceResponse),
+		DeleteGeofenceEndpoint:        client("DELETE", EncodeHTTPDeleteGeofenceRequest, DecodeHTTPDeleteGeofenceResponse),
+		ListGeofencesEndpoint:         client("GET", EncodeHTTPListGeofencesRequest, DecodeHTTPListGeofencesResponse),
+		NearbyDevicesEndpoint:         client("GET", EncodeHTTPNearbyDevicesRequest, DecodeHTTPNearbyDevicesResponse),
+		DevicesInBoxEndpoint:          client("GET", EncodeHTTPDevicesInBoxRequest, DecodeHTTPDevicesInBoxResponse),
+		GetStatusEndpoint:             client("GET", EncodeHTTPGetStatusRequest, DecodeHTTPGetStatusResponse),
+		BatteryHistoryEndpoint:        client("GET", EncodeHTTPBatteryHistoryRequest, DecodeHTTPBatteryHistoryResponse),
+		QueryTelemetryEndpoint:        client("GET", EncodeHTTPQueryTelemetryRequest, DecodeHTTPQueryTelemetryResponse),
+		AggregateTelemetryEndpoint:    client("GET", EncodeHTTPAggregateTelemetryRequest, DecodeHTTPAggregateTelemetryResponse),
+		SetLabelsEndpoint:             client("PUT", EncodeHTTPSetLabelsRequest, DecodeHTTPSetLabelsResponse),
+		ListStatusesEndpoint:          client("GET", EncodeHTTPListStatusesRequest, DecodeHTTPListStatusesResponse),
+		CreateGroupEndpoint:           client("POST", EncodeHTTPCreateGroupRequest, DecodeHTTPCreateGroupResponse),
+		UpdateGroupEndpoint:           client("PUT", EncodeHTTPUpdateGroupRequest, DecodeHTTPUpdateGroupResponse),
+		DeleteGroupEndpoint:           client("DELETE", EncodeHTTPDeleteGroupRequest, DecodeHTTPDeleteGroupResponse),
+		ListGroupsEndpoint:            client("GET", EncodeHTTPListGroupsRequest, DecodeHTTPListGroupsResponse),
+		AddGroupMembersEndpoint:       client("POST", EncodeHTTPAddGroupMembersRequest, DecodeHTTPAddGroupMembersResponse),
+		RemoveGroupMembersEndpoint:    client("DELETE", EncodeHTTPRemoveGroupMembersRequest, DecodeHTTPRemoveGroupMembersResponse),
+		RelayStatusEndpoint:           client("PUT", EncodeHTTPRelayStatusRequest, DecodeHTTPRelayStatusResponse),
+		RelayTelemetryEndpoint:        client("PUT", EncodeHTTPRelayTelemetryRequest, DecodeHTTPRelayTelemetryResponse),
+		GetTopologyEndpoint:           client("GET", EncodeHTTPGetTopologyRequest, DecodeHTTPGetTopologyResponse),
+		GetTwinEndpoint:               client("GET", EncodeHTTPGetTwinRequest, DecodeHTTPGetTwinResponse),
+		UpdateDesiredEndpoint:         client("PATCH", EncodeHTTPUpdateDesiredRequest, DecodeHTTPUpdateDesiredResponse),
+		ReportStateEndpoint:           client("PATCH", EncodeHTTPReportStateRequest, DecodeHTTPReportStateResponse),
+		EnqueueCommandEndpoint:        client("POST", EncodeHTTPEnqueueCommandRequest, DecodeHTTPEnqueueCommandResponse),
+		AckCommandEndpoint:            client("POST", EncodeHTTPAckCommandRequest, DecodeHTTPAckCommandResponse),
+		CommandHistoryEndpoint:        client("GET", EncodeHTTPCommandHistoryRequest, DecodeHTTPCommandHistoryResponse),
+		UploadFirmwareEndpoint:        client("POST", EncodeHTTPUploadFirmwareRequest, DecodeHTTPUploadFirmwareResponse),
+		ListFirmwareEndpoint:          client("GET", EncodeHTTPListFirmwareRequest, DecodeHTTPListFirmwareResponse),
+		DownloadFirmwareEndpoint:      client("GET", EncodeHTTPDownloadFirmwareRequest, DecodeHTTPDownloadFirmwareResponse),
+		CreateCampaignEndpoint:        client("POST", EncodeHTTPCreateCampaignRequest, DecodeHTTPCreateCampaignResponse),
+		GetCampaignEndpoint:           client("GET", EncodeHTTPGetCampaignRequest, DecodeHTTPGetCampaignResponse),
+		ListCampaignsEndpoint:         client("GET", EncodeHTTPListCampaignsRequest, DecodeHTTPListCampaignsResponse),
+		ControlCampaignEndpoint:       client("POST", EncodeHTTPControlCampaignRequest, DecodeHTTPControlCampaignResponse),
+		ReportFirmwareEndpoint:        client("PUT", EncodeHTTPReportFirmwareRequest, DecodeHTTPReportFirmwareResponse),
+		SetTelemetrySchemaEndpoint:    client("PUT", EncodeHTTPSetTelemetrySchemaRequest, DecodeHTTPSetTelemetrySchemaResponse),
+		GetTelemetrySchemaEndpoint:    client("GET", EncodeHTTPGetTelemetrySchemaRequest, DecodeHTTPGetTelemetrySchemaResponse),
+		ListTelemetrySchemasEndpoint:  client("GET", EncodeHTTPListTelemetrySchemasRequest, DecodeHTTPListTelemetrySchemasResponse),
+		DeleteTelemetrySchemaEndpoint: client("DELETE", EncodeHTTPDeleteTelemetrySchemaRequest, DecodeHTTPDeleteTelemetrySchemaResponse),
+		ImportDevicesEndpoint:         client("POST", EncodeHTTPImportDevicesRequest, DecodeHTTPImportDevicesResponse),
+		ExportDevicesEndpoint:         client("GET", EncodeHTTPExportDevicesRequest, DecodeHTTPExportDevicesResponse),
+		UpdateDeviceEndpoint:          client("PATCH", EncodeHTTPUpdateDeviceRequest, DecodeHTTPUpdateDeviceResponse),
+		DeleteDeviceEndpoint:          client("DELETE", EncodeHTTPDeleteDeviceRequest, DecodeHTTPDeleteDeviceResponse),
+
+		DeliverDesiredEndpoint:  notServed("DeliverDesired"),
+		DeliverCommandsEndpoint: notServed("DeliverCommands"),
+		OfferFirmwareEndpoint:   notServed("OfferFirmware"),
+	}, nil
+}
+
+// WatchEventsHTTP is WatchEventsGRPC over the JSON/HTTP API at instance. It
+// makes the request with client, adding header, e.g. for authorization.
+func WatchEventsHTTP(ctx context.Context, client *http.Client, instance string, header http.Header, filter EventFilter, handle func(Event) error) error {
+	if !strings.Contains(instance, "://") {
+		instance = "http://" + instance
+	}
+	req, err := http.NewRequest("GET", instance, nil)
+	if err != nil {
+		return err
+	}
+	routeHTTP(req, "/v1/events", eventFilterQuery(filter))
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 4096))
+		return httpStatusError{code: resp.StatusCode, msg: strings.TrimSpace(string(msg))}
+	}
+
+	lines := bufio.NewReader(resp.Body)
+	for {
+		b, err := lines.ReadBytes('\n')
+		if err == io.EOF && len(b) == 0 {
+			return errStreamEnded
+		}
+		if err != nil && err != io.EOF {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		var line watchEventsLine
+		if err := json.Unmarshal(b, &line); err != nil {
+			return err
+		}
+		if line.Err != "" {
+			return errors.New(line.Err)
+		}
+		if err := handle(line.event()); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/autodidaddict/iotmonitor"
+)
+
+// formatOf guesses a file's format from its extension.
+func formatOf(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ndjson", ".jsonl":
+		return iotmonitor.FormatNDJSON
+	case ".parquet":
+		return iotmonitor.FormatParquet
+	}
+	return iotmonitor.FormatCSV
+}
+
+func importDevices(e *env, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	format := fs.String("format", "", "file format, csv or ndjson (default: from the file extension)")
+	dryRun := fs.Bool("dry-run", false, "validate the rows without registering devices")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("import needs exactly one file, or - for stdin")
+	}
+
+	path := fs.Arg(0)
+	if *format == "" {
+		*format = formatOf(path)
+	}
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	devices, err := iotmonitor.ReadDeviceImports(r, *format)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := e.context()
+	defer cancel()
+	results, err := e.m.ImportDevices(ctx, devices, *dryRun)
+	if err != nil {
+		return err
+	}
+	t := &table{header: []string{"ROW", "RESULT"}}
+	var failed int
+	for _, res := range results {
+		switch {
+		case res.Err != "":
+			failed++
+			t.add(res.Row, res.Err)
+		case *dryRun:
+			t.add(res.Row, "ok")
+		default:
+			t.add(res.Row, fmt.Sprintf("registered device %d", res.DeviceID))
+		}
+	}
+	if err := e.out.print(results, t); err != nil {
+		return err
+	}
+	fmt.Fprintf(e.status, "%d rows, %d failed\n", len(results), failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+	return nil
+}
+
+func exportDevices(e *env, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	format := fs.String("format", "", "csv, ndjson or parquet (default: from the output file extension, else csv)")
+	out := fs.String("o", "-", "output file, - for stdout")
+	filter := filterFlags(fs)
+	fs.Parse(args)
+
+	if *format == "" {
+		*format = formatOf(*out)
+	}
+
+	ctx, cancel := e.context()
+	defer cancel()
+	devices, err := e.m.ExportDevices(ctx, *filter)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "-" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return iotmonitor.WriteDeviceExport(w, *format, devices)
+}
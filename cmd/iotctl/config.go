@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Transports
+const (
+	transportGRPC = "grpc"
+	transportHTTP = "http"
+)
+
+// config is where and how iotctl talks to the monitor. It is read from a
+// YAML file:
+//
+//	transport: http
+//	grpc_addr: monitor.example.com:8081
+//	http_url: https://monitor.example.com
+//	token: s3cr3t
+//	output: yaml
+//	timeout: 30s
+//
+// Flags override the file.
+type config struct {
+	Transport string
+	GRPCAddr  string
+	HTTPURL   string
+	Token     string
+	Output    string
+	Timeout   time.Duration
+}
+
+func defaultConfig() config {
+	return config{
+		Transport: transportGRPC,
+		GRPCAddr:  "localhost:8081",
+		HTTPURL:   "http://localhost:8080",
+		Output:    outputTable,
+		Timeout:   10 * time.Second,
+	}
+}
+
+// defaultConfigPath is $IOTCTL_CONFIG, or ~/.iotctl.yaml.
+func defaultConfigPath() string {
+	if path := os.Getenv("IOTCTL_CONFIG"); path != "" {
+		return path
+	}
+	return filepath.Join(os.Getenv("HOME"), ".iotctl.yaml")
+}
+
+// configFile is the settings file as YAML.
+type configFile struct {
+	Transport string `yaml:"transport"`
+	GRPCAddr  string `yaml:"grpc_addr"`
+	HTTPURL   string `yaml:"http_url"`
+	Token     string `yaml:"token"`
+	Output    string `yaml:"output"`
+	Timeout   string `yaml:"timeout"`
+}
+
+// loadConfig reads path over cfg. A missing file is only an error if it was
+// asked for by name.
+func loadConfig(cfg *config, path string, named bool) error {
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) && !named {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var f configFile
+	if err := yaml.UnmarshalStrict(b, &f); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	if err := cfg.apply(f); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	return nil
+}
+
+// apply sets what f sets.
+func (c *config) apply(f configFile) error {
+	set := func(dst *string, value string) {
+		if value != "" {
+			*dst = value
+		}
+	}
+	set(&c.Transport, f.Transport)
+	set(&c.GRPCAddr, f.GRPCAddr)
+	set(&c.HTTPURL, f.HTTPURL)
+	set(&c.Token, f.Token)
+	set(&c.Output, f.Output)
+	if f.Timeout != "" {
+		d, err := time.ParseDuration(f.Timeout)
+		if err != nil {
+			return fmt.Errorf("invalid timeout %q", f.Timeout)
+		}
+		c.Timeout = d
+	}
+	return nil
+}
+
+func (c config) validate() error {
+	switch c.Transport {
+	case transportGRPC, transportHTTP:
+	default:
+		return fmt.Errorf("unknown transport %q, want grpc or http", c.Transport)
+	}
+	return validOutput(c.Output)
+}
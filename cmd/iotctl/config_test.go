@@ -0,0 +1,68 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "iotctl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	defaults := defaultConfig()
+	tests := []struct {
+		name    string
+		file    string
+		want    config
+		wantErr bool
+	}{
+		{
+			"full",
+			"# monitor\ntransport: http\ngrpc_addr: monitor:8081\nhttp_url: \"https://monitor\"\ntoken: 's3cr3t: x'\noutput: yaml\ntimeout: 30s\n",
+			config{Transport: transportHTTP, GRPCAddr: "monitor:8081", HTTPURL: "https://monitor", Token: "s3cr3t: x", Output: outputYAML, Timeout: 30 * time.Second},
+			false,
+		},
+		{
+			"partial",
+			"token: abc\n",
+			config{Transport: defaults.Transport, GRPCAddr: defaults.GRPCAddr, HTTPURL: defaults.HTTPURL, Token: "abc", Output: defaults.Output, Timeout: defaults.Timeout},
+			false,
+		},
+		{"empty", "", defaults, false},
+		{"unknown setting", "colour: always\n", config{}, true},
+		{"bad timeout", "timeout: soon\n", config{}, true},
+		{"not a mapping", "- grpc\n", config{}, true},
+	}
+	for i, tt := range tests {
+		path := filepath.Join(dir, string(rune('a'+i))+".yaml")
+		if err := ioutil.WriteFile(path, []byte(tt.file), 0600); err != nil {
+			t.Fatal(err)
+		}
+		cfg := defaultConfig()
+		err := loadConfig(&cfg, path, true)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: loadConfig() = %v, want error %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && cfg != tt.want {
+			t.Errorf("%s: loadConfig() gave %+v, want %+v", tt.name, cfg, tt.want)
+		}
+	}
+}
+
+func TestLoadConfigMissing(t *testing.T) {
+	cfg := defaultConfig()
+	path := filepath.Join(os.TempDir(), "iotctl-missing.yaml")
+	if err := loadConfig(&cfg, path, false); err != nil || cfg != defaultConfig() {
+		t.Errorf("loadConfig() of a default path that doesn't exist = %v, %+v", err, cfg)
+	}
+	if err := loadConfig(&cfg, path, true); err == nil {
+		t.Error("loadConfig() accepted a named file that doesn't exist")
+	}
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/autodidaddict/iotmonitor"
+)
+
+func parseID(what, s string) (uint64, error) {
+	id, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s id %q", what, s)
+	}
+	return id, nil
+}
+
+// oneID parses the single device, rule or other ID a command takes.
+func oneID(fs *flag.FlagSet, what string) (uint64, error) {
+	if fs.NArg() != 1 {
+		return 0, fmt.Errorf("%s needs exactly one %s id", fs.Name(), what)
+	}
+	return parseID(what, fs.Arg(0))
+}
+
+// filterFlags adds the device filter flags to fs.
+func filterFlags(fs *flag.FlagSet) *iotmonitor.DeviceFilter {
+	var f iotmonitor.DeviceFilter
+	fs.StringVar(&f.DeviceType, "type", "", "only devices of this type")
+	fs.StringVar(&f.Owner, "owner", "", "only devices with this owner")
+	fs.StringVar(&f.Selector, "selector", "", "label selector, e.g. site=plant-3,env!=test")
+	fs.Uint64Var(&f.Group, "group", 0, "only devices in this group or its descendants")
+	fs.Var((*idList)(&f.DeviceIDs), "ids", "only these devices, comma separated")
+	return &f
+}
+
+// idList is a comma-separated list of IDs given as a flag.
+type idList []uint64
+
+func (l *idList) String() string {
+	ids := make([]string, len(*l))
+	for i, id := range *l {
+		ids[i] = strconv.FormatUint(id, 10)
+	}
+	return strings.Join(ids, ",")
+}
+
+func (l *idList) Set(s string) error {
+	for _, part := range strings.Split(s, ",") {
+		id, err := parseID("device", strings.TrimSpace(part))
+		if err != nil {
+			return err
+		}
+		*l = append(*l, id)
+	}
+	return nil
+}
+
+// labelFlag is a set of key=value labels given as a flag. It records
+// whether it was set at all, so an empty value can clear a device's labels.
+type labelFlag struct {
+	labels map[string]string
+	set    bool
+}
+
+func (l *labelFlag) String() string {
+	return formatLabels(l.labels)
+}
+
+func (l *labelFlag) Set(s string) error {
+	l.set = true
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	if l.labels == nil {
+		l.labels = make(map[string]string)
+	}
+	for _, pair := range strings.Split(s, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("invalid label %q, want key=value", pair)
+		}
+		l.labels[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	return nil
+}
+
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return "-"
+	}
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func deviceTable(devices ...iotmonitor.Device) *table {
+	t := &table{header: []string{"ID", "NAME", "TYPE", "OWNER", "GATEWAY", "ONLINE", "LAST SEEN", "BATTERY", "FIRMWARE", "LABELS"}}
+	for _, d := range devices {
+		gateway, battery, firmware := "-", "-", "-"
+		if d.GatewayID != 0 {
+			gateway = strconv.FormatUint(d.GatewayID, 10)
+		}
+		if d.Battery != nil {
+			battery = fmt.Sprintf("%d%%", d.Battery.Level)
+		}
+		if d.FirmwareVersion != "" {
+			firmware = d.FirmwareVersion
+		}
+		online := strconv.FormatBool(d.Online)
+		if d.Unreachable {
+			online = "unreachable"
+		}
+		t.add(d.ID, d.Name, d.DeviceType, d.Owner, gateway, online, formatTime(d.LastSeen), battery, firmware, formatLabels(d.Labels))
+	}
+	return t
+}
+
+func registerDevice(e *env, args []string) error {
+	fs := flag.NewFlagSet("register", flag.ExitOnError)
+	name := fs.String("name", "", "device name (required)")
+	owner := fs.String("owner", "", "device owner")
+	deviceType := fs.String("type", "Drone", "device type")
+	gateway := fs.Uint64("gateway", 0, "ID of the gateway the device reports through")
+	var labels labelFlag
+	fs.Var(&labels, "labels", "labels, e.g. env=prod,site=plant-3")
+	fs.Parse(args)
+	if *name == "" {
+		return fmt.Errorf("register needs a -name")
+	}
+
+	ctx, cancel := e.context()
+	defer cancel()
+	id, err := e.m.RegisterDevice(ctx, *name, *owner, *deviceType, *gateway, labels.labels)
+	if err != nil {
+		return err
+	}
+	d, err := e.m.GetDevice(ctx, id)
+	if err != nil {
+		return err
+	}
+	return e.out.print(d, deviceTable(d))
+}
+
+func listDevices(e *env, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	filter := filterFlags(fs)
+	fs.Parse(args)
+
+	ctx, cancel := e.context()
+	defer cancel()
+	devices, err := e.m.ListDevices(ctx, *filter)
+	if err != nil {
+		return err
+	}
+	return e.out.print(devices, deviceTable(devices...))
+}
+
+func getDevice(e *env, args []string) error {
+	fs := flag.NewFlagSet("get", flag.ExitOnError)
+	fs.Parse(args)
+	id, err := oneID(fs, "device")
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := e.context()
+	defer cancel()
+	d, err := e.m.GetDevice(ctx, id)
+	if err != nil {
+		return err
+	}
+	return e.out.print(d, deviceTable(d))
+}
+
+func updateDevice(e *env, args []string) error {
+	fs := flag.NewFlagSet("update", flag.ExitOnError)
+	name := fs.String("name", "", "new device name")
+	owner := fs.String("owner", "", "new device owner")
+	var labels labelFlag
+	fs.Var(&labels, "labels", `replace the device's labels, e.g. env=prod,site=plant-3; "" removes them all`)
+	fs.Parse(args)
+	id, err := oneID(fs, "device")
+	if err != nil {
+		return err
+	}
+	if *name == "" && *owner == "" && !labels.set {
+		return fmt.Errorf("update needs at least one of -name, -owner and -labels")
+	}
+
+	ctx, cancel := e.context()
+	defer cancel()
+	if *name != "" || *owner != "" {
+		if _, err := e.m.UpdateDevice(ctx, id, *name, *owner); err != nil {
+			return err
+		}
+	}
+	if labels.set {
+		if _, err := e.m.SetLabels(ctx, id, labels.labels); err != nil {
+			return err
+		}
+	}
+	d, err := e.m.GetDevice(ctx, id)
+	if err != nil {
+		return err
+	}
+	return e.out.print(d, deviceTable(d))
+}
+
+func deleteDevice(e *env, args []string) error {
+	fs := flag.NewFlagSet("delete", flag.ExitOnError)
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		return fmt.Errorf("delete needs at least one device id")
+	}
+	ids := make([]uint64, fs.NArg())
+	for i, arg := range fs.Args() {
+		id, err := parseID("device", arg)
+		if err != nil {
+			return err
+		}
+		ids[i] = id
+	}
+
+	ctx, cancel := e.context()
+	defer cancel()
+	for i, id := range ids {
+		if _, err := e.m.DeleteDevice(ctx, id); err != nil {
+			if i > 0 {
+				e.done(map[string][]uint64{"deleted": ids[:i]}, "deleted %d devices", i)
+			}
+			return fmt.Errorf("device %d: %v", id, err)
+		}
+	}
+	return e.done(map[string][]uint64{"deleted": ids}, "deleted %d devices", len(ids))
+}
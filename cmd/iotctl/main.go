@@ -0,0 +1,158 @@
+// Command iotctl manages an iotmonitor server from the command line.
+//
+//	iotctl register -name "Drone Alpha 1" -owner kevin -type Drone -labels site=plant-3
+//	iotctl list -type Sensor -selector env=prod
+//	iotctl -o yaml get 42
+//	iotctl telemetry send 42 temp=21.5@celsius door=true
+//	iotctl telemetry query -from 6h 42 temp
+//	iotctl -transport http watch -selector site=plant-3
+//	iotctl import [-format csv|ndjson] [-dry-run] devices.csv
+//	iotctl export [-format csv|ndjson|parquet] [-type Drone] [-selector env=prod] [-o devices.parquet]
+//
+// Settings are read from ~/.iotctl.yaml, or the file named by -config or
+// $IOTCTL_CONFIG; flags override them.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/autodidaddict/iotmonitor"
+	"github.com/autodidaddict/iotmonitor/client"
+)
+
+// env is what a command runs with.
+type env struct {
+	m       iotmonitor.Service
+	watch   func(context.Context, iotmonitor.EventFilter, func(iotmonitor.Event) error) error
+	out     *printer
+	status  io.Writer // messages for people, kept off stdout
+	timeout time.Duration
+}
+
+// context bounds a single call to the monitor.
+func (e *env) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), e.timeout)
+}
+
+// done reports a command that has no result to show beyond its success:
+// v in JSON and YAML output, the message otherwise.
+func (e *env) done(v interface{}, format string, args ...interface{}) error {
+	if e.out.format != outputTable {
+		return e.out.print(v, nil)
+	}
+	_, err := fmt.Fprintf(e.status, format+"\n", args...)
+	return err
+}
+
+type command struct {
+	run     func(e *env, args []string) error
+	summary string
+}
+
+var commands = map[string]command{
+	"register":  {registerDevice, "register a device"},
+	"list":      {listDevices, "list devices"},
+	"get":       {getDevice, "show a device"},
+	"update":    {updateDevice, "rename a device, or change its owner or labels"},
+	"delete":    {deleteDevice, "delete devices"},
+	"status":    {status, "get, list or send device status"},
+	"telemetry": {telemetry, "send or query telemetry"},
+	"watch":     {watch, "follow status reports and alerts"},
+	"alerts":    {alerts, "list firing alerts"},
+	"rules":     {rules, "list, get, create, update or delete alert rules"},
+	"import":    {importDevices, "register devices from a CSV or NDJSON file"},
+	"export":    {exportDevices, "write the device registry as CSV, NDJSON or Parquet"},
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: iotctl [flags] <command> [command flags]\n\ncommands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintln(os.Stderr, "\nflags:")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
+
+func main() {
+	cfg := defaultConfig()
+	configPath := flag.String("config", defaultConfigPath(), "settings file")
+	transport := flag.String("transport", "", "grpc or http (default from the settings file, else grpc)")
+	grpcAddr := flag.String("addr", "", "gRPC address of the monitor, or a comma-separated list to spread calls over (default localhost:8081)")
+	httpURL := flag.String("url", "", "base URL of the monitor's HTTP API, or a comma-separated list to spread calls over (default http://localhost:8080)")
+	token := flag.String("token", "", "bearer token sent with every call")
+	output := flag.String("o", "", "output format: table, json or yaml (default table)")
+	timeout := flag.Duration("timeout", 0, "time limit for each call (default 10s)")
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() < 1 {
+		usage()
+	}
+	cmd, ok := commands[flag.Arg(0)]
+	if !ok {
+		usage()
+	}
+
+	named := false
+	flag.Visit(func(f *flag.Flag) { named = named || f.Name == "config" })
+	if err := loadConfig(&cfg, *configPath, named || os.Getenv("IOTCTL_CONFIG") != ""); err != nil {
+		fatal(err)
+	}
+	override := func(dst *string, flagValue string) {
+		if flagValue != "" {
+			*dst = flagValue
+		}
+	}
+	override(&cfg.Transport, *transport)
+	override(&cfg.GRPCAddr, *grpcAddr)
+	override(&cfg.HTTPURL, *httpURL)
+	override(&cfg.Token, *token)
+	override(&cfg.Output, *output)
+	if *timeout > 0 {
+		cfg.Timeout = *timeout
+	}
+	if err := cfg.validate(); err != nil {
+		fatal(err)
+	}
+
+	e := &env{
+		out:     &printer{format: cfg.Output, w: os.Stdout},
+		status:  os.Stderr,
+		timeout: cfg.Timeout,
+	}
+	options := []client.Option{client.Token(cfg.Token), client.Timeout(cfg.Timeout), client.Retries(2, 200*time.Millisecond)}
+	var c *client.Client
+	var err error
+	if cfg.Transport == transportHTTP {
+		c, err = client.NewHTTP(strings.Split(cfg.HTTPURL, ","), options...)
+	} else {
+		c, err = client.NewGRPC(strings.Split(cfg.GRPCAddr, ","), options...)
+	}
+	if err != nil {
+		fatal(err)
+	}
+	defer c.Close()
+	e.m = c
+	e.watch = c.WatchEvents
+
+	if err := cmd.run(e, flag.Args()[1:]); err != nil {
+		fatal(err)
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "iotctl:", err)
+	os.Exit(1)
+}
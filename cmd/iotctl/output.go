@@ -0,0 +1,271 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Output formats
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+// table is how a result is shown in table output.
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+func (t *table) add(cols ...interface{}) {
+	row := make([]string, len(cols))
+	for i, c := range cols {
+		row[i] = fmt.Sprint(c)
+	}
+	t.rows = append(t.rows, row)
+}
+
+func (t *table) write(w io.Writer, header bool) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	if header {
+		fmt.Fprintln(tw, strings.Join(t.header, "\t"))
+	}
+	for _, row := range t.rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// printer writes command results in the chosen output format.
+type printer struct {
+	format string
+	w      io.Writer
+
+	// streamed is set once a streaming command has written its first
+	// result, so table headers and YAML separators are written once.
+	streamed bool
+}
+
+func validOutput(format string) error {
+	switch format {
+	case outputTable, outputJSON, outputYAML:
+		return nil
+	}
+	return fmt.Errorf("unknown output format %q, want table, json or yaml", format)
+}
+
+// print writes v as JSON or YAML, or t as a table.
+func (p *printer) print(v interface{}, t *table) error {
+	switch p.format {
+	case outputJSON:
+		enc := json.NewEncoder(p.w)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case outputYAML:
+		b, err := toYAML(v)
+		if err != nil {
+			return err
+		}
+		_, err = p.w.Write(b)
+		return err
+	}
+	return t.write(p.w, true)
+}
+
+// stream writes one result of a long-running command: a table row, a line
+// of JSON or a YAML document.
+func (p *printer) stream(v interface{}, t *table) error {
+	first := !p.streamed
+	p.streamed = true
+	switch p.format {
+	case outputJSON:
+		enc := json.NewEncoder(p.w)
+		enc.SetEscapeHTML(false)
+		return enc.Encode(v)
+	case outputYAML:
+		b, err := toYAML(v)
+		if err != nil {
+			return err
+		}
+		if !first {
+			fmt.Fprintln(p.w, "---")
+		}
+		_, err = p.w.Write(b)
+		return err
+	}
+	return t.write(p.w, first)
+}
+
+// formatTime shows a millisecond timestamp in local time.
+func formatTime(ms int64) string {
+	if ms == 0 {
+		return "-"
+	}
+	return time.Unix(0, ms*int64(time.Millisecond)).Format(time.RFC3339)
+}
+
+// toYAML writes v as YAML. It goes through v's JSON encoding, so fields are
+// named and ordered as they are in JSON.
+func toYAML(v interface{}) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	node, err := readNode(dec)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	writeYAML(&buf, node, 0)
+	return buf.Bytes(), nil
+}
+
+// yamlField is one key of a JSON object, in the order it was written.
+type yamlField struct {
+	key   string
+	value interface{}
+}
+
+// readNode reads the next JSON value as a scalar, a []interface{} or a
+// []yamlField.
+func readNode(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		fields := []yamlField{}
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := readNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, yamlField{key.(string), value})
+		}
+		_, err = dec.Token()
+		return fields, err
+	case json.Delim('['):
+		items := []interface{}{}
+		for dec.More() {
+			item, err := readNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		_, err = dec.Token()
+		return items, err
+	}
+	return tok, nil
+}
+
+func writeYAML(buf *bytes.Buffer, node interface{}, indent int) {
+	pad := strings.Repeat(" ", indent)
+	switch n := node.(type) {
+	case []yamlField:
+		if len(n) == 0 {
+			buf.WriteString(pad + "{}\n")
+		}
+		for _, f := range n {
+			buf.WriteString(pad + yamlScalar(f.key) + ":")
+			writeYAMLValue(buf, f.value, indent)
+		}
+	case []interface{}:
+		if len(n) == 0 {
+			buf.WriteString(pad + "[]\n")
+		}
+		for _, item := range n {
+			buf.WriteString(pad + "-")
+			if fields, ok := item.([]yamlField); ok && len(fields) > 0 {
+				// The first key goes on the dash's line, the rest line up
+				// beneath it.
+				var nested bytes.Buffer
+				writeYAML(&nested, fields, indent+2)
+				buf.WriteString(" ")
+				buf.Write(bytes.TrimLeft(nested.Bytes(), " "))
+				continue
+			}
+			writeYAMLValue(buf, item, indent)
+		}
+	default:
+		buf.WriteString(pad + yamlScalar(n) + "\n")
+	}
+}
+
+// writeYAMLValue writes the value that follows a key or a list dash.
+func writeYAMLValue(buf *bytes.Buffer, value interface{}, indent int) {
+	switch v := value.(type) {
+	case []yamlField:
+		if len(v) == 0 {
+			buf.WriteString(" {}\n")
+			return
+		}
+		buf.WriteString("\n")
+		writeYAML(buf, v, indent+2)
+	case []interface{}:
+		if len(v) == 0 {
+			buf.WriteString(" []\n")
+			return
+		}
+		buf.WriteString("\n")
+		writeYAML(buf, v, indent+2)
+	default:
+		buf.WriteString(" " + yamlScalar(v) + "\n")
+	}
+}
+
+func yamlScalar(v interface{}) string {
+	switch s := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(s)
+	case json.Number:
+		return s.String()
+	case string:
+		if yamlNeedsQuotes(s) {
+			return strconv.Quote(s)
+		}
+		return s
+	}
+	return fmt.Sprint(v)
+}
+
+// yamlNeedsQuotes reports whether a string would be read back as something
+// other than the same string if it were written plain.
+func yamlNeedsQuotes(s string) bool {
+	if s == "" || strings.TrimSpace(s) != s {
+		return true
+	}
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "null", "~":
+		return true
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return true
+	}
+	if strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") {
+		return true
+	}
+	for _, r := range s {
+		if r < ' ' || r == 0x7f {
+			return true
+		}
+	}
+	return strings.HasSuffix(s, ":") || strings.Contains(s, ": ") || strings.Contains(s, " #")
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/autodidaddict/iotmonitor"
+)
+
+func alertSource(a iotmonitor.Alert) string {
+	switch {
+	case a.Source == iotmonitor.AlertSourceBattery:
+		return "battery"
+	case a.GeofenceID != 0:
+		return fmt.Sprintf("geofence %d", a.GeofenceID)
+	case a.Source == iotmonitor.AlertSourceGeofence:
+		return "permitted zones"
+	}
+	return fmt.Sprintf("rule %d", a.RuleID)
+}
+
+func alertTable(alerts ...iotmonitor.Alert) *table {
+	t := &table{header: []string{"DEVICE", "SOURCE", "STATE", "VALUE", "FIRED", "MESSAGE"}}
+	for _, a := range alerts {
+		t.add(a.DeviceID, alertSource(a), a.State, a.Value, formatTime(a.FiredAt), a.Message)
+	}
+	return t
+}
+
+func alerts(e *env, args []string) error {
+	fs := flag.NewFlagSet("alerts", flag.ExitOnError)
+	filter := filterFlags(fs)
+	fs.Parse(args)
+
+	ctx, cancel := e.context()
+	defer cancel()
+	alerts, err := e.m.ActiveAlerts(ctx, *filter)
+	if err != nil {
+		return err
+	}
+	return e.out.print(alerts, alertTable(alerts...))
+}
+
+func ruleScope(r iotmonitor.Rule) string {
+	var scope []string
+	if r.DeviceID != 0 {
+		scope = append(scope, fmt.Sprintf("device %d", r.DeviceID))
+	}
+	if r.DeviceType != "" {
+		scope = append(scope, "type "+r.DeviceType)
+	}
+	if r.Owner != "" {
+		scope = append(scope, "owner "+r.Owner)
+	}
+	if len(scope) == 0 {
+		return "all devices"
+	}
+	return strings.Join(scope, ", ")
+}
+
+func ruleTable(rules ...iotmonitor.Rule) *table {
+	t := &table{header: []string{"ID", "NAME", "KIND", "CONDITION", "SCOPE"}}
+	for _, r := range rules {
+		condition := fmt.Sprintf("%s %s %v", r.Metric, r.Operator, r.Threshold)
+		if r.Kind == iotmonitor.RuleAbsence {
+			condition = fmt.Sprintf("no %s for %ds", r.Metric, r.Window)
+		}
+		t.add(r.ID, r.Name, r.Kind, condition, ruleScope(r))
+	}
+	return t
+}
+
+// ruleFlags adds a flag for each field of a rule to fs.
+func ruleFlags(fs *flag.FlagSet, r *iotmonitor.Rule) {
+	fs.StringVar(&r.Name, "name", "", "rule name")
+	fs.StringVar(&r.Kind, "kind", iotmonitor.RuleThreshold, "threshold, rate_of_change or absence")
+	fs.StringVar(&r.Metric, "metric", "", "metric the rule watches")
+	fs.StringVar(&r.Operator, "op", "", "comparison: >, >=, <, <=, == or !=")
+	fs.Float64Var(&r.Threshold, "threshold", 0, "value the metric is compared with")
+	fs.Int64Var(&r.Window, "window", 0, "seconds without data before an absence rule fires")
+	fs.Uint64Var(&r.DeviceID, "device", 0, "only this device")
+	fs.StringVar(&r.DeviceType, "type", "", "only devices of this type")
+	fs.StringVar(&r.Owner, "owner", "", "only devices with this owner")
+}
+
+func rules(e *env, args []string) error {
+	if len(args) == 0 {
+		args = []string{"list"}
+	}
+	fs := flag.NewFlagSet("rules "+args[0], flag.ExitOnError)
+	ctx, cancel := e.context()
+	defer cancel()
+
+	switch args[0] {
+	case "list":
+		fs.Parse(args[1:])
+		rules, err := e.m.ListRules(ctx)
+		if err != nil {
+			return err
+		}
+		return e.out.print(rules, ruleTable(rules...))
+
+	case "get":
+		fs.Parse(args[1:])
+		id, err := oneID(fs, "rule")
+		if err != nil {
+			return err
+		}
+		r, err := e.m.GetRule(ctx, id)
+		if err != nil {
+			return err
+		}
+		return e.out.print(r, ruleTable(r))
+
+	case "create":
+		var r iotmonitor.Rule
+		ruleFlags(fs, &r)
+		fs.Parse(args[1:])
+		id, err := e.m.CreateRule(ctx, r)
+		if err != nil {
+			return err
+		}
+		r.ID = id
+		return e.out.print(r, ruleTable(r))
+
+	case "update":
+		// Only the flags given change the rule.
+		var patch iotmonitor.Rule
+		ruleFlags(fs, &patch)
+		fs.Parse(args[1:])
+		id, err := oneID(fs, "rule")
+		if err != nil {
+			return err
+		}
+		r, err := e.m.GetRule(ctx, id)
+		if err != nil {
+			return err
+		}
+		fs.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "name":
+				r.Name = patch.Name
+			case "kind":
+				r.Kind = patch.Kind
+			case "metric":
+				r.Metric = patch.Metric
+			case "op":
+				r.Operator = patch.Operator
+			case "threshold":
+				r.Threshold = patch.Threshold
+			case "window":
+				r.Window = patch.Window
+			case "device":
+				r.DeviceID = patch.DeviceID
+			case "type":
+				r.DeviceType = patch.DeviceType
+			case "owner":
+				r.Owner = patch.Owner
+			}
+		})
+		if _, err := e.m.UpdateRule(ctx, r); err != nil {
+			return err
+		}
+		return e.out.print(r, ruleTable(r))
+
+	case "delete":
+		fs.Parse(args[1:])
+		id, err := oneID(fs, "rule")
+		if err != nil {
+			return err
+		}
+		if _, err := e.m.DeleteRule(ctx, id); err != nil {
+			return err
+		}
+		return e.done(map[string]uint64{"deleted": id}, "deleted rule %d", id)
+	}
+	return fmt.Errorf("unknown rules subcommand %q, want list, get, create, update or delete", args[0])
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/autodidaddict/iotmonitor"
+)
+
+func statusTable(statuses ...iotmonitor.DeviceStatus) *table {
+	t := &table{header: []string{"DEVICE", "TIME", "LATITUDE", "LONGITUDE", "ALTITUDE", "BATTERY", "SPEED", "HEADING"}}
+	for _, s := range statuses {
+		t.add(s.DeviceID, formatTime(s.Timestamp), s.Latitude, s.Longitude, s.Altitude,
+			fmt.Sprintf("%d%%", s.Battery), fmt.Sprintf("%.1f m/s", s.Speed), fmt.Sprintf("%.0f°", s.Heading))
+	}
+	return t
+}
+
+func status(e *env, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("status needs a subcommand: get, list or send")
+	}
+	switch args[0] {
+	case "get":
+		return getStatus(e, args[1:])
+	case "list":
+		return listStatuses(e, args[1:])
+	case "send":
+		return sendStatus(e, args[1:])
+	}
+	return fmt.Errorf("unknown status subcommand %q", args[0])
+}
+
+func getStatus(e *env, args []string) error {
+	fs := flag.NewFlagSet("status get", flag.ExitOnError)
+	fs.Parse(args)
+	id, err := oneID(fs, "device")
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := e.context()
+	defer cancel()
+	s, err := e.m.GetStatus(ctx, id)
+	if err != nil {
+		return err
+	}
+	return e.out.print(s, statusTable(s))
+}
+
+func listStatuses(e *env, args []string) error {
+	fs := flag.NewFlagSet("status list", flag.ExitOnError)
+	filter := filterFlags(fs)
+	fs.Parse(args)
+
+	ctx, cancel := e.context()
+	defer cancel()
+	statuses, err := e.m.ListStatuses(ctx, *filter)
+	if err != nil {
+		return err
+	}
+	return e.out.print(statuses, statusTable(statuses...))
+}
+
+func sendStatus(e *env, args []string) error {
+	fs := flag.NewFlagSet("status send", flag.ExitOnError)
+	lat := fs.Float64("lat", 0, "latitude")
+	long := fs.Float64("long", 0, "longitude")
+	alt := fs.Float64("alt", 0, "altitude in meters")
+	battery := fs.Uint("battery", 100, "battery remaining, in percent")
+	fs.Parse(args)
+	id, err := oneID(fs, "device")
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := e.context()
+	defer cancel()
+	ok, err := e.m.UpdateStatus(ctx, id, float32(*lat), float32(*long), float32(*alt), uint32(*battery))
+	if err != nil {
+		return err
+	}
+	return e.done(map[string]bool{"acknowledged": ok}, "status sent for device %d", id)
+}
+
+func telemetry(e *env, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("telemetry needs a subcommand: send or query")
+	}
+	switch args[0] {
+	case "send":
+		return sendTelemetry(e, args[1:])
+	case "query":
+		return queryTelemetry(e, args[1:])
+	}
+	return fmt.Errorf("unknown telemetry subcommand %q", args[0])
+}
+
+// parseReading parses a name=value reading. The value is read the way the
+// API reads a bare JSON value, so 21.5, true, [0.1,0.2] and "E42" are a
+// double, a bool, a vector and a string; anything else is a string. Ints
+// are written as typed values: count={"type":"int","value":21}. A unit may
+// follow the value after an @: temp=21.5@celsius.
+func parseReading(s string) (string, iotmonitor.TelemetryValue, error) {
+	var v iotmonitor.TelemetryValue
+	kv := strings.SplitN(s, "=", 2)
+	if len(kv) != 2 || kv[0] == "" {
+		return "", v, fmt.Errorf("invalid reading %q, want name=value", s)
+	}
+	value, unit := kv[1], ""
+	if i := strings.LastIndex(value, "@"); i >= 0 {
+		value, unit = value[:i], value[i+1:]
+	}
+	if err := json.Unmarshal([]byte(value), &v); err != nil {
+		v = iotmonitor.TelemetryValue{Type: iotmonitor.TelemetryString, Text: value}
+	}
+	v.Unit = unit
+	return kv[0], v, nil
+}
+
+func sendTelemetry(e *env, args []string) error {
+	fs := flag.NewFlagSet("telemetry send", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: iotctl telemetry send DEVICE name=value[@unit]...")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() < 2 {
+		return fmt.Errorf("telemetry send needs a device id and at least one name=value reading")
+	}
+	id, err := parseID("device", fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	readings := make(map[string]iotmonitor.TelemetryValue)
+	for _, arg := range fs.Args()[1:] {
+		name, v, err := parseReading(arg)
+		if err != nil {
+			return err
+		}
+		readings[name] = v
+	}
+
+	ctx, cancel := e.context()
+	defer cancel()
+	ok, violations, err := e.m.SubmitTelemetry(ctx, id, readings)
+	if err != nil && len(violations) == 0 {
+		return err
+	}
+	if len(violations) > 0 {
+		t := &table{header: []string{"METRIC", "REASON", "VALUE", "ACTION"}}
+		for _, v := range violations {
+			t.add(v.Metric, v.Reason, formatValue(v.Value), v.Action)
+		}
+		result := map[string]interface{}{"acknowledged": ok, "violations": violations}
+		if perr := e.out.print(result, t); perr != nil {
+			return perr
+		}
+		return err
+	}
+	return e.done(map[string]bool{"acknowledged": ok}, "%d readings sent for device %d", len(readings), id)
+}
+
+// parseTime reads a query bound: a duration before now such as 90m, an
+// RFC 3339 time, or milliseconds since the epoch.
+func parseTime(s string, now time.Time) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d).UnixNano() / int64(time.Millisecond), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.UnixNano() / int64(time.Millisecond), nil
+	}
+	if ms, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return ms, nil
+	}
+	return 0, fmt.Errorf("invalid time %q, want a duration such as 1h, an RFC 3339 time or epoch milliseconds", s)
+}
+
+func formatValue(v iotmonitor.TelemetryValue) string {
+	var s string
+	switch v.Type {
+	case iotmonitor.TelemetryInt:
+		s = strconv.FormatInt(v.Int, 10)
+	case iotmonitor.TelemetryBool:
+		s = strconv.FormatBool(v.Bool)
+	case iotmonitor.TelemetryString:
+		s = strconv.Quote(v.Text)
+	case iotmonitor.TelemetryVector:
+		parts := make([]string, len(v.Vector))
+		for i, f := range v.Vector {
+			parts[i] = strconv.FormatFloat(f, 'g', -1, 64)
+		}
+		s = "[" + strings.Join(parts, ",") + "]"
+	default:
+		s = strconv.FormatFloat(v.Double, 'g', -1, 64)
+	}
+	if v.Unit != "" {
+		s += " " + v.Unit
+	}
+	return s
+}
+
+func queryTelemetry(e *env, args []string) error {
+	fs := flag.NewFlagSet("telemetry query", flag.ExitOnError)
+	from := fs.String("from", "", "start of the range: a duration before now, an RFC 3339 time or epoch milliseconds (default 24h before -to)")
+	to := fs.String("to", "", "end of the range, in the same forms as -from (default now)")
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		return fmt.Errorf("telemetry query needs a device id and a metric")
+	}
+	id, err := parseID("device", fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	fromMS, err := parseTime(*from, now)
+	if err != nil {
+		return err
+	}
+	toMS, err := parseTime(*to, now)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := e.context()
+	defer cancel()
+	series, err := e.m.QueryTelemetry(ctx, id, fs.Arg(1), fromMS, toMS)
+	if err != nil {
+		return err
+	}
+
+	t := &table{header: []string{"TIME", "COUNT", "MIN", "MAX", "AVG", "LAST"}}
+	for _, p := range series.Points {
+		if p.Value != nil && p.Count == 0 {
+			t.add(formatTime(p.Timestamp), "-", "-", "-", "-", formatValue(*p.Value))
+			continue
+		}
+		t.add(formatTime(p.Timestamp), p.Count, p.Min, p.Max, p.Avg, p.Last)
+	}
+	return e.out.print(series, t)
+}
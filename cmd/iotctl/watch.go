@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/autodidaddict/iotmonitor"
+)
+
+// How long watch waits before reopening a broken stream: watchBackoff,
+// doubling up to watchMaxBackoff while the stream keeps breaking.
+const (
+	watchBackoff    = time.Second
+	watchMaxBackoff = 30 * time.Second
+)
+
+// watchEvent is one change seen by watch.
+type watchEvent struct {
+	Type     string                   `json:"type"`
+	DeviceID uint64                   `json:"device_id"`
+	Time     int64                    `json:"time"`
+	Status   *iotmonitor.DeviceStatus `json:"status,omitempty"`
+	Alert    *iotmonitor.Alert        `json:"alert,omitempty"`
+}
+
+func (w watchEvent) row() *table {
+	t := &table{header: []string{"TIME", "EVENT", "DEVICE", "DETAIL"}}
+	var detail string
+	switch {
+	case w.Status != nil:
+		s := w.Status
+		detail = fmt.Sprintf("%.5f,%.5f alt %.0fm battery %d%% speed %.1fm/s", s.Latitude, s.Longitude, s.Altitude, s.Battery, s.Speed)
+	case w.Alert != nil:
+		detail = fmt.Sprintf("%s: %s", alertSource(*w.Alert), w.Alert.Message)
+	}
+	t.add(formatTime(w.Time), w.Type, w.DeviceID, detail)
+	return t
+}
+
+// watchEventFrom decodes the status or alert an event carries.
+func watchEventFrom(e iotmonitor.Event) (watchEvent, error) {
+	w := watchEvent{Type: e.Type, DeviceID: e.DeviceID, Time: e.Timestamp}
+	b, err := json.Marshal(e.Data)
+	if err != nil {
+		return w, err
+	}
+	switch e.Type {
+	case iotmonitor.EventStatusUpdated:
+		w.Status = &iotmonitor.DeviceStatus{}
+		err = json.Unmarshal(b, w.Status)
+		w.Status.DeviceID = e.DeviceID
+	case iotmonitor.EventAlertFiring, iotmonitor.EventAlertResolved:
+		w.Alert = &iotmonitor.Alert{}
+		err = json.Unmarshal(b, w.Alert)
+	}
+	return w, err
+}
+
+// watch follows the monitor's event stream and prints each status report
+// and each alert that fires or resolves, until interrupted. A stream that
+// breaks is reopened, on the next instance if there are several, and
+// events published while it was down are missed.
+func watch(e *env, args []string) error {
+	fs := flag.NewFlagSet("watch", flag.ExitOnError)
+	filter := filterFlags(fs)
+	fs.Parse(args)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		cancel()
+	}()
+
+	f := iotmonitor.EventFilter{
+		Types:   []string{iotmonitor.EventStatusUpdated, iotmonitor.EventAlertFiring, iotmonitor.EventAlertResolved},
+		Devices: *filter,
+	}
+	var printErr error
+	show := func(ev iotmonitor.Event) error {
+		w, err := watchEventFrom(ev)
+		if err != nil {
+			return err
+		}
+		printErr = e.out.stream(w, w.row())
+		return printErr
+	}
+	backoff := watchBackoff
+	for {
+		opened := time.Now()
+		err := e.watch(ctx, f, show)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if printErr != nil {
+			return printErr
+		}
+		if time.Since(opened) > watchMaxBackoff {
+			backoff = watchBackoff
+		}
+		fmt.Fprintf(e.status, "iotctl: %v; reconnecting in %v\n", err, backoff)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > watchMaxBackoff {
+			backoff = watchMaxBackoff
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/autodidaddict/iotmonitor"
+)
+
+func TestWatchEventFrom(t *testing.T) {
+	tests := []struct {
+		event   iotmonitor.Event
+		want    watchEvent
+		wantErr bool
+	}{
+		{
+			iotmonitor.Event{Type: iotmonitor.EventStatusUpdated, DeviceID: 7, Timestamp: 1000, Data: json.RawMessage(`{"device_id":7,"latitude":51.5,"battery":80,"speed":3,"totals":{"distance":12}}`)},
+			watchEvent{Type: iotmonitor.EventStatusUpdated, DeviceID: 7, Time: 1000, Status: &iotmonitor.DeviceStatus{DeviceID: 7, Latitude: 51.5, Battery: 80, Speed: 3, Totals: iotmonitor.MotionTotals{Distance: 12}}},
+			false,
+		},
+		{
+			iotmonitor.Event{Type: iotmonitor.EventAlertFiring, DeviceID: 7, Timestamp: 1000, Data: json.RawMessage(`{"rule_id":2,"device_id":7,"state":"firing","message":"hot"}`)},
+			watchEvent{Type: iotmonitor.EventAlertFiring, DeviceID: 7, Time: 1000, Alert: &iotmonitor.Alert{RuleID: 2, DeviceID: 7, State: "firing", Message: "hot"}},
+			false,
+		},
+		{
+			iotmonitor.Event{Type: iotmonitor.EventAlertResolved, DeviceID: 7, Data: json.RawMessage(`[]`)},
+			watchEvent{},
+			true,
+		},
+	}
+	for _, tt := range tests {
+		got, err := watchEventFrom(tt.event)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("watchEventFrom(%s) error = %v, want error %v", tt.event.Type, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("watchEventFrom(%s) = %+v, want %+v", tt.event.Type, got, tt.want)
+		}
+	}
+}
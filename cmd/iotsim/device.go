@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/autodidaddict/iotmonitor"
+)
+
+// device registers a model with the monitor and reports on it until ctx is
+// done.
+type device struct {
+	target            target
+	stats             *stats
+	rng               *rand.Rand
+	timeout           time.Duration
+	statusInterval    time.Duration
+	telemetryInterval time.Duration
+	timescale         float64
+
+	name, deviceType string
+	labels           map[string]string
+
+	mu    sync.Mutex // guards model and acked, which command streams share
+	model model
+	id    uint64
+	acked map[uint64]bool
+	last  time.Time
+}
+
+// call runs f with a time limit and records how long it took. Calls aren't
+// tied to the run's context, so ones in flight when it ends still count.
+func (d *device) call(op string, f func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+	defer cancel()
+	start := time.Now()
+	err := f(ctx)
+	d.stats.record(op, time.Since(start), err)
+	return err
+}
+
+func (d *device) run(ctx context.Context) {
+	// Spread the fleet's reports over the first interval.
+	select {
+	case <-ctx.Done():
+		return
+	case <-time.After(time.Duration(d.rng.Int63n(int64(d.statusInterval)))):
+	}
+
+	err := d.call(opRegister, func(ctx context.Context) (err error) {
+		d.id, err = d.target.register(ctx, d.name, d.deviceType, d.labels)
+		return err
+	})
+	if err != nil {
+		return
+	}
+	d.acked = make(map[uint64]bool)
+	d.last = time.Now()
+
+	if w, ok := d.target.(commandWatcher); ok {
+		go func() {
+			// The stream ends with an error when ctx is done; only an
+			// earlier end is a failure.
+			if err := w.watchCommands(ctx, d.id, d.handle); err != nil && ctx.Err() == nil {
+				d.stats.record(opCommand, 0, err)
+			}
+		}()
+	}
+
+	statusTicker := time.NewTicker(d.statusInterval)
+	defer statusTicker.Stop()
+	var telemetryC <-chan time.Time
+	if _, ok := d.model.(telemeter); ok {
+		telemetryTicker := time.NewTicker(d.telemetryInterval)
+		defer telemetryTicker.Stop()
+		telemetryC = telemetryTicker.C
+	}
+
+	d.reportStatus()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-statusTicker.C:
+			d.reportStatus()
+		case <-telemetryC:
+			d.submitTelemetry()
+		}
+	}
+}
+
+// advance moves the model on to now. The caller holds d.mu.
+func (d *device) advance() {
+	now := time.Now()
+	d.model.step(now.Sub(d.last).Seconds() * d.timescale)
+	d.last = now
+}
+
+func (d *device) reportStatus() {
+	d.mu.Lock()
+	d.advance()
+	pos, alt, battery := d.model.status()
+	d.mu.Unlock()
+
+	var commands []iotmonitor.Command
+	d.call(opStatus, func(ctx context.Context) (err error) {
+		commands, err = d.target.status(ctx, d.id, float32(pos.lat), float32(pos.long), float32(alt), battery)
+		return err
+	})
+	for _, cmd := range commands {
+		d.handle(cmd)
+	}
+}
+
+func (d *device) submitTelemetry() {
+	d.mu.Lock()
+	d.advance()
+	values := d.model.(telemeter).telemetry()
+	d.mu.Unlock()
+
+	d.call(opTelemetry, func(ctx context.Context) error {
+		return d.target.telemetry(ctx, d.id, values)
+	})
+}
+
+// handle carries out a command and acknowledges it. How long the command
+// took to arrive is recorded as well.
+func (d *device) handle(cmd iotmonitor.Command) {
+	d.mu.Lock()
+	if d.acked[cmd.ID] {
+		d.mu.Unlock()
+		return
+	}
+	d.acked[cmd.ID] = true
+	result, ok := d.model.command(cmd.Name)
+	d.mu.Unlock()
+
+	if cmd.CreatedAt > 0 {
+		delay := time.Duration(time.Now().UnixNano()/int64(time.Millisecond)-cmd.CreatedAt) * time.Millisecond
+		d.stats.record(opCommand, delay, nil)
+	}
+	if !ok {
+		result = "unknown command " + cmd.Name
+	}
+	d.call(opAck, func(ctx context.Context) error {
+		return d.target.ack(ctx, d.id, cmd.ID, ok, result)
+	})
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"github.com/autodidaddict/iotmonitor"
+	"github.com/autodidaddict/iotmonitor/pb"
+	"google.golang.org/grpc"
+)
+
+// inProcess is a monitor served from this process on loopback listeners.
+type inProcess struct {
+	grpcAddr, httpURL string
+
+	grpc *grpc.Server
+	http *http.Server
+}
+
+// serveInProcess starts a monitor with the endpoints the simulator calls.
+// Its service keeps its state in the Redis at redisAddr as monitord's does.
+func serveInProcess(redisAddr string) (*inProcess, error) {
+	events := iotmonitor.NewEventBus()
+	srv, err := iotmonitor.NewService(events, iotmonitor.Config{Store: iotmonitor.StoreConfig{RedisAddr: redisAddr}})
+	if err != nil {
+		return nil, err
+	}
+	endpoints := iotmonitor.Endpoints{
+		RegisterEndpoint:        iotmonitor.MakeRegisterEndpoint(srv),
+		UpdateEndpoint:          iotmonitor.MakeUpdateEndpoint(srv),
+		TelemetryEndpoint:       iotmonitor.MakeTelemetryEndpoint(srv),
+		DeliverDesiredEndpoint:  iotmonitor.MakeDeliverDesiredEndpoint(srv),
+		DeliverCommandsEndpoint: iotmonitor.MakeDeliverCommandsEndpoint(srv),
+		AckCommandEndpoint:      iotmonitor.MakeAckCommandEndpoint(srv),
+	}
+	ctx := context.Background()
+
+	grpcListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	httpListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		grpcListener.Close()
+		return nil, err
+	}
+
+	p := &inProcess{
+		grpcAddr: grpcListener.Addr().String(),
+		httpURL:  "http://" + httpListener.Addr().String(),
+		grpc:     grpc.NewServer(),
+		http:     &http.Server{Handler: iotmonitor.NewHTTPServer(ctx, endpoints, events)},
+	}
+	pb.RegisterMonitorServer(p.grpc, iotmonitor.NewGRPCServer(ctx, endpoints, events))
+	go p.grpc.Serve(grpcListener)
+	go p.http.Serve(httpListener)
+	return p, nil
+}
+
+func (p *inProcess) close() {
+	p.grpc.Stop()
+	p.http.Close()
+}
@@ -0,0 +1,224 @@
+// Command iotsim simulates a fleet of devices against an iotmonitor server,
+// for demos and load tests. Drones fly between random waypoints around a
+// home base and return to recharge when their battery runs low; sensors sit
+// still and report sine-wave readings with noise and the odd spike. Any
+// commands queued for a simulated device are acknowledged.
+//
+//	iotsim -devices 200 -mix Drone=1,Sensor=3 -duration 5m
+//	iotsim -transport http -url http://monitor:8080 -telemetry-interval 500ms
+//	iotsim -transport stream -devices 1000 -conns 4
+//	iotsim -inprocess -devices 500 -duration 1m
+//
+// When it stops it prints the throughput and latency percentiles of each
+// kind of call. With -inprocess it serves the monitor itself, on loopback
+// listeners, so the numbers leave out the network; the service still needs
+// Redis, at -redis.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// Transports
+const (
+	transportGRPC   = "grpc"
+	transportHTTP   = "http"
+	transportStream = "stream"
+)
+
+func main() {
+	devices := flag.Int("devices", 10, "number of devices to simulate")
+	mix := flag.String("mix", "Drone=1,Sensor=1", "relative weights of the device types")
+	duration := flag.Duration("duration", 0, "how long to run (default until interrupted)")
+	statusInterval := flag.Duration("status-interval", time.Second, "how often each device reports its status")
+	telemetryInterval := flag.Duration("telemetry-interval", 5*time.Second, "how often each sensor submits telemetry")
+	timescale := flag.Float64("timescale", 1, "simulated seconds per real second, to fly and drain batteries faster")
+	spike := flag.Float64("spike", 0.01, "probability that a sensor reading is a spike")
+	base := flag.String("base", "51.4779,-0.0015", "latitude,longitude the fleet is spread around")
+	radius := flag.Float64("radius", 5000, "meters from -base that devices are placed and drones fly")
+	transport := flag.String("transport", transportGRPC, "grpc, http or stream (gRPC, with commands pushed over WatchCommands)")
+	grpcAddr := flag.String("addr", "localhost:8081", "gRPC address of the monitor")
+	httpURL := flag.String("url", "http://localhost:8080", "base URL of the monitor's HTTP API")
+	conns := flag.Int("conns", 1, "gRPC connections to spread the devices over")
+	timeout := flag.Duration("timeout", 10*time.Second, "time limit for each call")
+	inprocess := flag.Bool("inprocess", false, "serve the monitor in this process and simulate against it")
+	redisAddr := flag.String("redis", ":6379", "Redis address of the -inprocess monitor")
+	report := flag.Duration("report", 10*time.Second, "how often to print progress, 0 for never")
+	seed := flag.Int64("seed", 0, "random seed (default from the clock)")
+	flag.Parse()
+
+	weights, err := parseMix(*mix)
+	if err != nil {
+		fatal(err)
+	}
+	lat, long, err := parseBase(*base)
+	if err != nil {
+		fatal(err)
+	}
+	switch {
+	case *devices < 1:
+		fatal(fmt.Errorf("-devices must be at least 1"))
+	case *conns < 1:
+		fatal(fmt.Errorf("-conns must be at least 1"))
+	case *statusInterval <= 0 || *telemetryInterval <= 0:
+		fatal(fmt.Errorf("intervals must be positive"))
+	case *timescale <= 0:
+		fatal(fmt.Errorf("-timescale must be positive"))
+	}
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+
+	if *inprocess {
+		srv, err := serveInProcess(*redisAddr)
+		if err != nil {
+			fatal(err)
+		}
+		defer srv.close()
+		*grpcAddr, *httpURL = srv.grpcAddr, srv.httpURL
+	}
+
+	targets := make([]target, *conns)
+	switch *transport {
+	case transportHTTP:
+		client := &http.Client{Transport: &http.Transport{MaxIdleConnsPerHost: *devices}}
+		for i := range targets {
+			targets[i] = httpTarget{base: strings.TrimRight(*httpURL, "/"), client: client}
+		}
+	case transportGRPC, transportStream:
+		for i := range targets {
+			conn, err := grpc.Dial(*grpcAddr, grpc.WithInsecure())
+			if err != nil {
+				fatal(err)
+			}
+			defer conn.Close()
+			targets[i] = newGRPCTarget(conn, *transport == transportStream)
+		}
+	default:
+		fatal(fmt.Errorf("unknown transport %q, want grpc, http or stream", *transport))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if *duration > 0 {
+		time.AfterFunc(*duration, cancel)
+	}
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		cancel()
+	}()
+
+	stats := newStats()
+	start := time.Now()
+	if *report > 0 {
+		go stats.progress(ctx, os.Stderr, *report)
+	}
+
+	rng := rand.New(rand.NewSource(*seed))
+	run := strconv.FormatInt(start.Unix(), 10)
+	fmt.Fprintf(os.Stderr, "simulating %d devices over %s (run=%s, seed %d)\n", *devices, *transport, run, *seed)
+	var wg sync.WaitGroup
+	for i := 0; i < *devices; i++ {
+		d := &device{
+			target:            targets[i%len(targets)],
+			stats:             stats,
+			rng:               rand.New(rand.NewSource(rng.Int63())),
+			timeout:           *timeout,
+			statusInterval:    *statusInterval,
+			telemetryInterval: *telemetryInterval,
+			timescale:         *timescale,
+			labels:            map[string]string{"source": "iotsim", "run": run},
+		}
+		d.deviceType = pickType(d.rng, weights)
+		d.name = fmt.Sprintf("sim-%s-%s-%05d", strings.ToLower(d.deviceType), run, i+1)
+		d.model = newModel(d.deviceType, d.rng, point{lat, long}, *radius, *spike)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.run(ctx)
+		}()
+	}
+	wg.Wait()
+
+	stats.summary(os.Stdout, time.Since(start))
+}
+
+// parseMix parses device type weights such as Drone=1,Sensor=3.
+func parseMix(s string) (map[string]int, error) {
+	weights := make(map[string]int)
+	for _, pair := range strings.Split(s, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid -mix entry %q, want Type=weight", pair)
+		}
+		deviceType := strings.TrimSpace(kv[0])
+		if deviceType != deviceDrone && deviceType != deviceSensor {
+			return nil, fmt.Errorf("can't simulate devices of type %q", deviceType)
+		}
+		w, err := strconv.Atoi(strings.TrimSpace(kv[1]))
+		if err != nil || w < 0 {
+			return nil, fmt.Errorf("invalid -mix weight %q", kv[1])
+		}
+		weights[deviceType] = w
+	}
+	total := 0
+	for _, w := range weights {
+		total += w
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("-mix needs a positive weight")
+	}
+	return weights, nil
+}
+
+func pickType(rng *rand.Rand, weights map[string]int) string {
+	types := make([]string, 0, len(weights))
+	total := 0
+	for t, w := range weights {
+		types = append(types, t)
+		total += w
+	}
+	sort.Strings(types)
+	n := rng.Intn(total)
+	for _, t := range types {
+		if n < weights[t] {
+			return t
+		}
+		n -= weights[t]
+	}
+	return types[len(types)-1]
+}
+
+func parseBase(s string) (lat, long float64, err error) {
+	parts := strings.Split(s, ",")
+	if len(parts) == 2 {
+		lat, err = strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+		if err == nil {
+			long, err = strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		}
+		if err == nil && lat >= -90 && lat <= 90 && long >= -180 && long <= 180 {
+			return lat, long, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("invalid -base %q, want latitude,longitude", s)
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "iotsim:", err)
+	os.Exit(1)
+}
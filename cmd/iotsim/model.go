@@ -0,0 +1,245 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/autodidaddict/iotmonitor"
+)
+
+// Device types iotsim can simulate
+const (
+	deviceDrone  = "Drone"
+	deviceSensor = "Sensor"
+)
+
+const metersPerDegree = 111320
+
+// model is how a simulated device behaves. Times are simulated seconds.
+type model interface {
+	// step advances the model by dt seconds.
+	step(dt float64)
+	status() (pos point, alt float64, battery uint32)
+	// command carries out a command, reporting false if the device
+	// doesn't know it.
+	command(name string) (result string, ok bool)
+}
+
+// telemeter is a model that submits telemetry.
+type telemeter interface {
+	telemetry() map[string]iotmonitor.TelemetryValue
+}
+
+func newModel(deviceType string, rng *rand.Rand, base point, radius, spike float64) model {
+	home := randomPoint(rng, base, radius)
+	if deviceType == deviceDrone {
+		return newDrone(rng, home, radius)
+	}
+	return newSensor(rng, home, spike)
+}
+
+type point struct {
+	lat, long float64
+}
+
+// distance returns the meters between p and q, near enough for the few
+// kilometers a drone covers.
+func (p point) distance(q point) float64 {
+	dy := (q.lat - p.lat) * metersPerDegree
+	dx := (q.long - p.long) * metersPerDegree * math.Cos(p.lat*math.Pi/180)
+	return math.Hypot(dx, dy)
+}
+
+// toward returns the point meters along the line from p to q, or q if that
+// is closer.
+func (p point) toward(q point, meters float64) point {
+	d := p.distance(q)
+	if d <= meters {
+		return q
+	}
+	f := meters / d
+	return point{p.lat + (q.lat-p.lat)*f, p.long + (q.long-p.long)*f}
+}
+
+// randomPoint returns a point uniformly distributed within radius meters of
+// center.
+func randomPoint(rng *rand.Rand, center point, radius float64) point {
+	r := radius * math.Sqrt(rng.Float64())
+	theta := rng.Float64() * 2 * math.Pi
+	return point{
+		lat:  center.lat + r*math.Cos(theta)/metersPerDegree,
+		long: center.long + r*math.Sin(theta)/(metersPerDegree*math.Cos(center.lat*math.Pi/180)),
+	}
+}
+
+// Drone flight phases
+const (
+	droneFlying = iota
+	droneReturning
+	droneCharging
+)
+
+const (
+	droneClimbRate   = 3.0       // m/s
+	droneFlightTime  = 25 * 60.0 // seconds on a full battery
+	droneChargeTime  = 15 * 60.0 // seconds to charge from empty
+	droneReserve     = 10.0      // percent left on landing
+	droneHoverFactor = 0.6       // hovering draws this much of cruising power
+)
+
+// drone flies from waypoint to waypoint within range of its home, and goes
+// home to recharge when its battery gets down to what the trip back takes.
+type drone struct {
+	rng     *rand.Rand
+	home    point
+	radius  float64
+	phase   int
+	pos     point
+	target  point
+	alt     float64
+	cruise  float64 // m
+	speed   float64 // m/s
+	battery float64 // percent
+}
+
+func newDrone(rng *rand.Rand, home point, radius float64) *drone {
+	d := &drone{
+		rng:     rng,
+		home:    home,
+		radius:  radius / 2,
+		pos:     home,
+		cruise:  50 + rng.Float64()*70,
+		speed:   10 + rng.Float64()*5,
+		battery: 50 + rng.Float64()*50,
+	}
+	d.target = randomPoint(rng, home, d.radius)
+	return d
+}
+
+func (d *drone) drain(dt, factor float64) {
+	d.battery = math.Max(0, d.battery-factor*100/droneFlightTime*dt)
+}
+
+func (d *drone) step(dt float64) {
+	if d.phase == droneCharging {
+		d.battery += 100 / droneChargeTime * dt
+		if d.battery >= 100 {
+			d.battery = 100
+			d.phase = droneFlying
+			d.target = randomPoint(d.rng, d.home, d.radius)
+		}
+		return
+	}
+
+	if d.alt < d.cruise && d.phase == droneFlying {
+		d.alt = math.Min(d.cruise, d.alt+droneClimbRate*dt)
+		d.drain(dt, 1)
+		return
+	}
+
+	if d.phase == droneReturning && d.pos == d.target {
+		d.alt = math.Max(0, d.alt-droneClimbRate*dt)
+		d.drain(dt, droneHoverFactor)
+		if d.alt == 0 {
+			d.phase = droneCharging
+		}
+		return
+	}
+
+	d.pos = d.pos.toward(d.target, d.speed*dt)
+	d.drain(dt, 1)
+	if d.phase == droneFlying && d.pos == d.target {
+		d.target = randomPoint(d.rng, d.home, d.radius)
+	}
+	if d.phase == droneFlying {
+		trip := d.pos.distance(d.home)/d.speed + d.alt/droneClimbRate
+		if d.battery <= trip*100/droneFlightTime+droneReserve {
+			d.phase = droneReturning
+			d.target = d.home
+		}
+	}
+}
+
+func (d *drone) status() (point, float64, uint32) {
+	return d.pos, d.alt, uint32(math.Ceil(d.battery))
+}
+
+func (d *drone) command(name string) (string, bool) {
+	switch name {
+	case "return_home":
+		if d.phase == droneFlying {
+			d.phase = droneReturning
+			d.target = d.home
+		}
+		return "returning home", true
+	}
+	return "", false
+}
+
+// wave is a reading that follows a sine wave with noise.
+type wave struct {
+	name, unit string
+	base, amp  float64
+	period     float64 // seconds
+	phase      float64 // radians
+	noise      float64 // standard deviation
+	min, max   float64
+}
+
+// sensor sits still and reports a few readings. Its battery lasts a month.
+type sensor struct {
+	rng     *rand.Rand
+	pos     point
+	alt     float64
+	spike   float64
+	waves   []wave
+	t       float64
+	battery float64
+}
+
+const sensorBatteryLife = 30 * 24 * 3600.0
+
+func newSensor(rng *rand.Rand, pos point, spike float64) *sensor {
+	between := func(lo, hi float64) float64 { return lo + rng.Float64()*(hi-lo) }
+	phase := func() float64 { return rng.Float64() * 2 * math.Pi }
+	return &sensor{
+		rng:     rng,
+		pos:     pos,
+		alt:     between(0, 30),
+		spike:   spike,
+		battery: between(20, 100),
+		waves: []wave{
+			{"temperature", "celsius", between(18, 24), between(2, 5), between(600, 1800), phase(), 0.2, -40, 85},
+			{"humidity", "percent", between(40, 60), between(5, 15), between(1200, 3600), phase(), 1, 0, 100},
+			{"pressure", "hPa", between(1005, 1020), between(3, 8), between(3600, 10800), phase(), 0.3, 870, 1085},
+		},
+	}
+}
+
+func (s *sensor) step(dt float64) {
+	s.t += dt
+	s.battery = math.Max(0, s.battery-100/sensorBatteryLife*dt)
+}
+
+func (s *sensor) status() (point, float64, uint32) {
+	return s.pos, s.alt, uint32(math.Ceil(s.battery))
+}
+
+func (s *sensor) command(name string) (string, bool) {
+	return "", false
+}
+
+// telemetry returns the current readings, any of which may be a
+// spike of several times the wave's amplitude.
+func (s *sensor) telemetry() map[string]iotmonitor.TelemetryValue {
+	values := make(map[string]iotmonitor.TelemetryValue, len(s.waves))
+	for _, w := range s.waves {
+		v := w.base + w.amp*math.Sin(2*math.Pi*s.t/w.period+w.phase) + w.noise*s.rng.NormFloat64()
+		if s.rng.Float64() < s.spike {
+			v += w.amp * (3 + 3*s.rng.Float64()) * float64(1-2*s.rng.Intn(2))
+		}
+		v = math.Max(w.min, math.Min(w.max, v))
+		values[w.name] = iotmonitor.DoubleValue(math.Round(v*100)/100, w.unit)
+	}
+	return values
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// Operations the simulator measures. command is the time from a command
+// being queued to the device receiving it.
+const (
+	opRegister  = "register"
+	opStatus    = "status"
+	opTelemetry = "telemetry"
+	opCommand   = "command"
+	opAck       = "ack"
+)
+
+var ops = []string{opRegister, opStatus, opTelemetry, opCommand, opAck}
+
+type opStats struct {
+	latencies []time.Duration
+	errors    int
+	lastErr   error
+	reported  int // count at the last progress report
+}
+
+// stats collects the latency of every call.
+type stats struct {
+	mu  sync.Mutex
+	ops map[string]*opStats
+}
+
+func newStats() *stats {
+	s := &stats{ops: make(map[string]*opStats)}
+	for _, op := range ops {
+		s.ops[op] = &opStats{}
+	}
+	return s
+}
+
+func (s *stats) record(op string, d time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o := s.ops[op]
+	if err != nil {
+		o.errors++
+		o.lastErr = err
+		return
+	}
+	o.latencies = append(o.latencies, d)
+}
+
+// progress prints the rate of each operation every interval until ctx is
+// done.
+func (s *stats) progress(ctx context.Context, w io.Writer, interval time.Duration) {
+	start := time.Now()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		s.mu.Lock()
+		parts := []string{fmt.Sprintf("%6s", time.Since(start).Round(time.Second))}
+		errors := 0
+		for _, op := range ops {
+			o := s.ops[op]
+			n := len(o.latencies) - o.reported
+			o.reported = len(o.latencies)
+			errors += o.errors
+			if n > 0 {
+				parts = append(parts, fmt.Sprintf("%s %.1f/s", op, float64(n)/interval.Seconds()))
+			}
+		}
+		s.mu.Unlock()
+		parts = append(parts, fmt.Sprintf("errors %d", errors))
+		fmt.Fprintln(w, strings.Join(parts, "  "))
+	}
+}
+
+// summary writes the count, rate and latency percentiles of each operation
+// over elapsed, and the last error of any that failed.
+func (s *stats) summary(w io.Writer, elapsed time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "OP\tCOUNT\tERRORS\tRATE/s\tP50\tP90\tP99\tMAX")
+	for _, op := range ops {
+		o := s.ops[op]
+		if len(o.latencies) == 0 && o.errors == 0 {
+			continue
+		}
+		sort.Slice(o.latencies, func(i, j int) bool { return o.latencies[i] < o.latencies[j] })
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.1f\t%s\t%s\t%s\t%s\n", op, len(o.latencies), o.errors,
+			float64(len(o.latencies))/elapsed.Seconds(),
+			percentile(o.latencies, 50), percentile(o.latencies, 90), percentile(o.latencies, 99), percentile(o.latencies, 100))
+	}
+	tw.Flush()
+	for _, op := range ops {
+		if err := s.ops[op].lastErr; err != nil {
+			fmt.Fprintf(w, "%s: %d errors, the last: %v\n", op, s.ops[op].errors, err)
+		}
+	}
+}
+
+// percentile returns the p-th percentile of sorted latencies.
+func percentile(sorted []time.Duration, p int) string {
+	if len(sorted) == 0 {
+		return "-"
+	}
+	i := (len(sorted)*p+99)/100 - 1
+	if i < 0 {
+		i = 0
+	}
+	return sorted[i].Round(10 * time.Microsecond).String()
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	var ten []time.Duration
+	for i := 1; i <= 10; i++ {
+		ten = append(ten, time.Duration(i)*time.Millisecond)
+	}
+	tests := []struct {
+		name   string
+		sorted []time.Duration
+		p      int
+		want   string
+	}{
+		{"empty", nil, 50, "-"},
+		{"single", []time.Duration{3 * time.Millisecond}, 99, "3ms"},
+		{"p0", ten, 0, "1ms"},
+		{"p50", ten, 50, "5ms"},
+		{"p90", ten, 90, "9ms"},
+		{"p99", ten, 99, "10ms"},
+		{"max", ten, 100, "10ms"},
+		{"rounded", []time.Duration{1234567 * time.Nanosecond}, 50, "1.23ms"},
+	}
+	for _, tt := range tests {
+		if got := percentile(tt.sorted, tt.p); got != tt.want {
+			t.Errorf("%s: percentile() = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestStatsSummary(t *testing.T) {
+	s := newStats()
+	for _, ms := range []int{30, 10, 20} {
+		s.record(opStatus, time.Duration(ms)*time.Millisecond, nil)
+	}
+	s.record(opAck, 0, errors.New("first"))
+	s.record(opAck, 0, errors.New("timeout"))
+
+	var b bytes.Buffer
+	s.summary(&b, 2*time.Second)
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("summary has %d lines, want a header, status, ack and the ack error:\n%s", len(lines), b.String())
+	}
+	if got := strings.Fields(lines[1]); strings.Join(got, " ") != "status 3 0 1.5 20ms 30ms 30ms 30ms" {
+		t.Errorf("status row = %q", lines[1])
+	}
+	if got := strings.Fields(lines[2]); strings.Join(got, " ") != "ack 0 2 0.0 - - - -" {
+		t.Errorf("ack row = %q", lines[2])
+	}
+	if lines[3] != "ack: 2 errors, the last: timeout" {
+		t.Errorf("error line = %q", lines[3])
+	}
+}
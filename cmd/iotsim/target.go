@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/autodidaddict/iotmonitor"
+	"github.com/autodidaddict/iotmonitor/pb"
+	"google.golang.org/grpc"
+)
+
+// target is the monitor a device reports to.
+type target interface {
+	register(ctx context.Context, name, deviceType string, labels map[string]string) (uint64, error)
+	// status reports where a device is, returning any commands it is handed.
+	status(ctx context.Context, id uint64, lat, long, alt float32, battery uint32) ([]iotmonitor.Command, error)
+	telemetry(ctx context.Context, id uint64, values map[string]iotmonitor.TelemetryValue) error
+	ack(ctx context.Context, id, commandID uint64, success bool, result string) error
+}
+
+// commandWatcher is a target that pushes commands to devices as they are
+// queued, rather than handing them over with status replies.
+type commandWatcher interface {
+	// watchCommands calls handle with each command for the device until
+	// ctx is done or the stream fails.
+	watchCommands(ctx context.Context, id uint64, handle func(iotmonitor.Command)) error
+}
+
+// grpcTarget calls the generated gRPC client directly, so the simulator's
+// own overhead stays out of the numbers.
+type grpcTarget struct {
+	client pb.MonitorClient
+}
+
+// streamTarget is a grpcTarget that watches each device's commands.
+type streamTarget struct {
+	grpcTarget
+}
+
+func newGRPCTarget(conn *grpc.ClientConn, stream bool) target {
+	t := grpcTarget{client: pb.NewMonitorClient(conn)}
+	if stream {
+		return streamTarget{t}
+	}
+	return t
+}
+
+func replyError(msg string) error {
+	if msg == "" {
+		return nil
+	}
+	return fmt.Errorf("%s", msg)
+}
+
+func (t grpcTarget) register(ctx context.Context, name, deviceType string, labels map[string]string) (uint64, error) {
+	req := &pb.RegisterDeviceRequest{Name: name, Owner: "iotsim", Devicetype: pb.DeviceType_DRONE}
+	if deviceType == deviceSensor {
+		req.Devicetype = pb.DeviceType_SENSOR
+	}
+	for k, v := range labels {
+		req.Labels = append(req.Labels, &pb.Label{Key: k, Value: v})
+	}
+	reply, err := t.client.RegisterDevice(ctx, req)
+	if err != nil {
+		return 0, err
+	}
+	return reply.Deviceid, replyError(reply.Err)
+}
+
+func (t grpcTarget) status(ctx context.Context, id uint64, lat, long, alt float32, battery uint32) ([]iotmonitor.Command, error) {
+	reply, err := t.client.UpdateDeviceStatus(ctx, &pb.StatusUpdateRequest{
+		Deviceid:         id,
+		Location:         &pb.Location{Latitude: lat, Longitude: long, Altitude: alt},
+		Batteryremaining: battery,
+		Deliver:          true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := replyError(reply.Err); err != nil {
+		return nil, err
+	}
+	return commandsFromPB(reply.Commands), nil
+}
+
+func (t grpcTarget) telemetry(ctx context.Context, id uint64, values map[string]iotmonitor.TelemetryValue) error {
+	req := &pb.TelemetrySubmitRequest{Deviceid: id, Values: make(map[string]*pb.TelemetryValue, len(values))}
+	for name, v := range values {
+		req.Values[name] = &pb.TelemetryValue{Type: v.Type, Doublevalue: v.Double, Unit: v.Unit}
+	}
+	reply, err := t.client.SubmitTelemetry(ctx, req)
+	if err != nil {
+		return err
+	}
+	return replyError(reply.Err)
+}
+
+func (t grpcTarget) ack(ctx context.Context, id, commandID uint64, success bool, result string) error {
+	reply, err := t.client.AckCommand(ctx, &pb.AckCommandRequest{Deviceid: id, Commandid: commandID, Success: success, Result: result})
+	if err != nil {
+		return err
+	}
+	return replyError(reply.Err)
+}
+
+func (t streamTarget) watchCommands(ctx context.Context, id uint64, handle func(iotmonitor.Command)) error {
+	stream, err := t.client.WatchCommands(ctx, &pb.WatchCommandsRequest{Deviceid: id})
+	if err != nil {
+		return err
+	}
+	for {
+		p, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		handle(commandsFromPB([]*pb.Command{p})[0])
+	}
+}
+
+// commandsFromPB converts commands, keeping what the simulator needs.
+func commandsFromPB(in []*pb.Command) []iotmonitor.Command {
+	out := make([]iotmonitor.Command, len(in))
+	for i, p := range in {
+		out[i] = iotmonitor.Command{ID: p.Commandid, DeviceID: p.Deviceid, Name: p.Name, CreatedAt: p.Createdat}
+	}
+	return out
+}
+
+// httpTarget calls the monitor's HTTP API.
+type httpTarget struct {
+	base   string
+	client *http.Client
+}
+
+// do sends body as JSON and decodes the reply into reply, whose Err field
+// is returned as the error.
+func (t httpTarget) do(ctx context.Context, method, path string, body interface{}, reply interface{ err() error }) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(method, t.base+path, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(reply); err != nil {
+		return err
+	}
+	return reply.err()
+}
+
+// replyErr is the err field every reply carries.
+type replyErr struct {
+	Err string `json:"err"`
+}
+
+func (r *replyErr) err() error {
+	return replyError(r.Err)
+}
+
+func devicePath(id uint64, suffix string) string {
+	return "/v1/devices/" + strconv.FormatUint(id, 10) + suffix
+}
+
+func (t httpTarget) register(ctx context.Context, name, deviceType string, labels map[string]string) (uint64, error) {
+	body := map[string]interface{}{"name": name, "owner": "iotsim", "device_type": deviceType, "labels": labels}
+	var reply struct {
+		DeviceID uint64 `json:"device_id"`
+		replyErr
+	}
+	err := t.do(ctx, "POST", "/v1/devices", body, &reply)
+	return reply.DeviceID, err
+}
+
+func (t httpTarget) status(ctx context.Context, id uint64, lat, long, alt float32, battery uint32) ([]iotmonitor.Command, error) {
+	body := map[string]interface{}{
+		"location":          map[string]float32{"latitude": lat, "longitude": long, "altitude": alt},
+		"battery_remaining": battery,
+		"deliver":           true,
+	}
+	var reply struct {
+		Commands []iotmonitor.Command `json:"commands"`
+		replyErr
+	}
+	err := t.do(ctx, "PUT", devicePath(id, "/status"), body, &reply)
+	return reply.Commands, err
+}
+
+func (t httpTarget) telemetry(ctx context.Context, id uint64, values map[string]iotmonitor.TelemetryValue) error {
+	var reply replyErr
+	return t.do(ctx, "PUT", devicePath(id, "/telemetry"), map[string]interface{}{"values": values}, &reply)
+}
+
+func (t httpTarget) ack(ctx context.Context, id, commandID uint64, success bool, result string) error {
+	var reply replyErr
+	path := devicePath(id, "/commands/"+strconv.FormatUint(commandID, 10)+"/ack")
+	return t.do(ctx, "POST", path, map[string]interface{}{"success": success, "result": result}, &reply)
+}
@@ -0,0 +1,603 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/autodidaddict/iotmonitor"
+	"gopkg.in/yaml.v2"
+)
+
+// Log levels and formats
+const (
+	logInfo  = "info"
+	logDebug = "debug"
+
+	logLogfmt = "logfmt"
+	logJSON   = "json"
+)
+
+// config is how monitord runs. Each setting is read, lowest precedence
+// first, from its default, the config file, the environment and flags. The
+// file is YAML:
+//
+//	listen:
+//	  grpc: :8081
+//	  http: :8080
+//	store:
+//	  redis_addr: redis.internal:6379
+//	  redis_password: s3cr3t
+//	auth:
+//	  tokens:
+//	    - token-1
+//	    - token-2
+//	heartbeat:
+//	  offline_timeouts:
+//	    Drone: 30s
+//	    Sensor: 5m
+//
+// or, if its name ends in .toml, TOML:
+//
+//	[listen]
+//	grpc = ":8081"
+//	[auth]
+//	tokens = ["token-1", "token-2"]
+//	[heartbeat.offline_timeouts]
+//	Drone = "30s"
+//
+// The log settings apply to the transport and shutdown logs on stderr. The
+// service and its background workers print their errors to stdout as plain
+// lines whatever the level and format.
+//
+// Lists may also be given as comma-separated strings, and maps as
+// comma-separated key=value pairs, as they are in the environment.
+//
+// The environment variable for a setting is its key in upper case with
+// dots turned to underscores and IOTMONITOR_ in front, such as
+// IOTMONITOR_STORE_REDIS_ADDR, and its flag is the key itself, such as
+// -store.redis_addr.
+type config struct {
+	GRPCAddr  string
+	HTTPAddr  string
+	DebugAddr string
+
+	Store       iotmonitor.StoreConfig
+	FirmwareDir string
+
+	Tokens       []string
+	DeviceSecret string
+
+	TLSCert     string
+	TLSKey      string
+	TLSClientCA string
+
+	RawRetention       time.Duration
+	HourlyRetention    time.Duration
+	CompactionInterval time.Duration
+
+	RateLimit float64
+	RateBurst int
+
+	LogLevel  string
+	LogFormat string
+
+	ShutdownDelay   time.Duration
+	ShutdownTimeout time.Duration
+
+	HealthInterval time.Duration
+	HealthTimeout  time.Duration
+
+	HeartbeatInterval time.Duration
+	OfflineTimeout    time.Duration
+	OfflineTimeouts   map[string]time.Duration // by device type
+
+	AbsenceInterval time.Duration
+
+	BatteryInterval   time.Duration
+	LowBatteryRuntime time.Duration
+}
+
+func defaultConfig() config {
+	return config{
+		GRPCAddr:  ":8081",
+		HTTPAddr:  ":8080",
+		DebugAddr: ":8082",
+		Store: iotmonitor.StoreConfig{
+			Backend:     iotmonitor.StoreBackendRedis,
+			RedisAddr:   ":6379",
+			DialTimeout: 5 * time.Second,
+		},
+		FirmwareDir:        "firmware",
+		RawRetention:       48 * time.Hour,
+		HourlyRetention:    90 * 24 * time.Hour,
+		CompactionInterval: 10 * time.Minute,
+		RateBurst:          100,
+		LogLevel:           logInfo,
+		LogFormat:          logLogfmt,
+		ShutdownTimeout:    30 * time.Second,
+		HealthInterval:     5 * time.Second,
+		HealthTimeout:      2 * time.Second,
+		HeartbeatInterval:  10 * time.Second,
+		OfflineTimeout:     5 * time.Minute,
+		OfflineTimeouts: map[string]time.Duration{
+			"Drone":  30 * time.Second,
+			"Sensor": 5 * time.Minute,
+		},
+		AbsenceInterval:   30 * time.Second,
+		BatteryInterval:   time.Minute,
+		LowBatteryRuntime: 30 * time.Minute,
+	}
+}
+
+// setting is one key of the config.
+type setting struct {
+	key    string
+	usage  string
+	secret bool
+	get    func(c *config) string
+	set    func(c *config, value string) error
+}
+
+func stringSetting(key, usage string, field func(c *config) *string) setting {
+	return setting{
+		key:   key,
+		usage: usage,
+		get:   func(c *config) string { return *field(c) },
+		set:   func(c *config, value string) error { *field(c) = value; return nil },
+	}
+}
+
+func durationSetting(key, usage string, field func(c *config) *time.Duration) setting {
+	return setting{
+		key:   key,
+		usage: usage,
+		get:   func(c *config) string { return field(c).String() },
+		set: func(c *config, value string) error {
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("invalid duration %q", value)
+			}
+			*field(c) = d
+			return nil
+		},
+	}
+}
+
+// durationMapSetting is a set of durations by name, written as
+// name=duration pairs separated by commas, such as Drone=30s,Sensor=5m.
+func durationMapSetting(key, usage string, field func(c *config) *map[string]time.Duration) setting {
+	return setting{
+		key:   key,
+		usage: usage,
+		get: func(c *config) string {
+			m := *field(c)
+			names := make([]string, 0, len(m))
+			for name := range m {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			pairs := make([]string, len(names))
+			for i, name := range names {
+				pairs[i] = name + "=" + m[name].String()
+			}
+			return strings.Join(pairs, ",")
+		},
+		set: func(c *config, value string) error {
+			m := make(map[string]time.Duration)
+			for _, pair := range splitList(value) {
+				kv := strings.SplitN(pair, "=", 2)
+				if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+					return fmt.Errorf("invalid name=duration pair %q", pair)
+				}
+				d, err := time.ParseDuration(strings.TrimSpace(kv[1]))
+				if err != nil {
+					return fmt.Errorf("invalid duration %q", kv[1])
+				}
+				m[strings.TrimSpace(kv[0])] = d
+			}
+			*field(c) = m
+			return nil
+		},
+	}
+}
+
+func intSetting(key, usage string, field func(c *config) *int) setting {
+	return setting{
+		key:   key,
+		usage: usage,
+		get:   func(c *config) string { return strconv.Itoa(*field(c)) },
+		set: func(c *config, value string) error {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("invalid integer %q", value)
+			}
+			*field(c) = n
+			return nil
+		},
+	}
+}
+
+var settings = []setting{
+	stringSetting("listen.grpc", "gRPC listen address", func(c *config) *string { return &c.GRPCAddr }),
+	stringSetting("listen.http", "HTTP listen address", func(c *config) *string { return &c.HTTPAddr }),
+	stringSetting("listen.debug", "listen address for metrics, always plain HTTP", func(c *config) *string { return &c.DebugAddr }),
+
+	stringSetting("store.backend", "store backend; only redis is supported", func(c *config) *string { return &c.Store.Backend }),
+	stringSetting("store.redis_addr", "Redis address", func(c *config) *string { return &c.Store.RedisAddr }),
+	func() setting {
+		s := stringSetting("store.redis_password", "Redis password", func(c *config) *string { return &c.Store.RedisPassword })
+		s.secret = true
+		return s
+	}(),
+	intSetting("store.redis_db", "Redis database number", func(c *config) *int { return &c.Store.RedisDB }),
+	durationSetting("store.dial_timeout", "time limit for connecting to Redis", func(c *config) *time.Duration { return &c.Store.DialTimeout }),
+	stringSetting("store.firmware_dir", "directory firmware images are kept in", func(c *config) *string { return &c.FirmwareDir }),
+
+	{
+		key:    "auth.tokens",
+		usage:  "comma-separated bearer tokens callers must present; none to allow any caller",
+		secret: true,
+		get:    func(c *config) string { return strings.Join(c.Tokens, ",") },
+		set: func(c *config, value string) error {
+			c.Tokens = splitList(value)
+			return nil
+		},
+	},
+	func() setting {
+		s := stringSetting("auth.device_secret", "secret gateway device tokens are made from; none to refuse them", func(c *config) *string { return &c.DeviceSecret })
+		s.secret = true
+		return s
+	}(),
+
+	stringSetting("tls.cert_file", "certificate to serve gRPC and HTTP with TLS", func(c *config) *string { return &c.TLSCert }),
+	stringSetting("tls.key_file", "private key for tls.cert_file", func(c *config) *string { return &c.TLSKey }),
+	stringSetting("tls.client_ca_file", "CA certificates to require and verify client certificates with", func(c *config) *string { return &c.TLSClientCA }),
+
+	durationSetting("retention.raw", "how long raw telemetry samples are kept", func(c *config) *time.Duration { return &c.RawRetention }),
+	durationSetting("retention.hourly", "how long hourly telemetry rollups are kept", func(c *config) *time.Duration { return &c.HourlyRetention }),
+	durationSetting("retention.compaction_interval", "how often telemetry past its retention is dropped", func(c *config) *time.Duration { return &c.CompactionInterval }),
+
+	{
+		key:   "rate_limit.rps",
+		usage: "calls a second admitted over gRPC and HTTP together; 0 for no limit",
+		get:   func(c *config) string { return strconv.FormatFloat(c.RateLimit, 'g', -1, 64) },
+		set: func(c *config, value string) error {
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("invalid number %q", value)
+			}
+			c.RateLimit = f
+			return nil
+		},
+	},
+	intSetting("rate_limit.burst", "calls admitted at once above rate_limit.rps", func(c *config) *int { return &c.RateBurst }),
+
+	stringSetting("log.level", "info, or debug to log every call; transport logs only", func(c *config) *string { return &c.LogLevel }),
+	stringSetting("log.format", "logfmt or json, for the transport logs", func(c *config) *string { return &c.LogFormat }),
+
+	durationSetting("shutdown.delay", "how long to keep serving after failing readiness, for load balancers to notice", func(c *config) *time.Duration { return &c.ShutdownDelay }),
+	durationSetting("shutdown.timeout", "deadline for draining calls and flushing writes once serving stops", func(c *config) *time.Duration { return &c.ShutdownTimeout }),
+
+	durationSetting("health.interval", "how often readiness is checked for the gRPC health service", func(c *config) *time.Duration { return &c.HealthInterval }),
+	durationSetting("health.timeout", "time limit for each round of health checks", func(c *config) *time.Duration { return &c.HealthTimeout }),
+
+	durationSetting("heartbeat.interval", "how often devices are checked for missed heartbeats", func(c *config) *time.Duration { return &c.HeartbeatInterval }),
+	durationSetting("heartbeat.offline_timeout", "silence after which a device is offline, unless its type has its own", func(c *config) *time.Duration { return &c.OfflineTimeout }),
+	durationMapSetting("heartbeat.offline_timeouts", "offline timeouts by device type, such as Drone=30s,Sensor=5m", func(c *config) *map[string]time.Duration { return &c.OfflineTimeouts }),
+
+	durationSetting("rules.absence_interval", "how often absence-of-data rules are evaluated", func(c *config) *time.Duration { return &c.AbsenceInterval }),
+
+	durationSetting("battery.interval", "how often battery runtimes are predicted", func(c *config) *time.Duration { return &c.BatteryInterval }),
+	durationSetting("battery.low_runtime", "predicted runtime below which a low battery alert fires", func(c *config) *time.Duration { return &c.LowBatteryRuntime }),
+}
+
+func lookup(key string) (setting, bool) {
+	for _, s := range settings {
+		if s.key == key {
+			return s, true
+		}
+	}
+	return setting{}, false
+}
+
+func (c *config) set(key, value string) error {
+	s, ok := lookup(key)
+	if !ok {
+		return fmt.Errorf("unknown setting %q", key)
+	}
+	if err := s.set(c, value); err != nil {
+		return fmt.Errorf("%s: %v", key, err)
+	}
+	return nil
+}
+
+// defaultConfigPath is $IOTMONITOR_CONFIG, or /etc/iotmonitor/monitord.yaml.
+func defaultConfigPath() string {
+	if path := os.Getenv("IOTMONITOR_CONFIG"); path != "" {
+		return path
+	}
+	return "/etc/iotmonitor/monitord.yaml"
+}
+
+// loadConfig reads path over cfg. A missing file is only an error if it was
+// asked for by name.
+func loadConfig(cfg *config, path string, named bool) error {
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) && !named {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var file map[string]interface{}
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		_, err = toml.Decode(string(b), &file)
+	} else {
+		err = yaml.Unmarshal(b, &file)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	if err := cfg.setAll("", file); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	return nil
+}
+
+// setAll sets the settings under prefix in v, a decoded YAML or TOML value.
+// Sections nest as maps; a map given for a setting, such as
+// heartbeat.offline_timeouts, is set as its key=value pairs.
+func (c *config) setAll(prefix string, v interface{}) error {
+	if m, ok := stringMap(v); ok {
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		if _, ok := lookup(prefix); ok {
+			pairs := make([]string, len(keys))
+			for i, k := range keys {
+				pairs[i] = k + "=" + scalar(m[k])
+			}
+			return c.set(prefix, strings.Join(pairs, ","))
+		}
+		for _, k := range keys {
+			key := k
+			if prefix != "" {
+				key = prefix + "." + k
+			}
+			if err := c.setAll(key, m[k]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if prefix == "" {
+		return errors.New("want a mapping of sections")
+	}
+	if list, ok := v.([]interface{}); ok {
+		items := make([]string, len(list))
+		for i, item := range list {
+			items[i] = scalar(item)
+		}
+		return c.set(prefix, strings.Join(items, ","))
+	}
+	if v == nil {
+		if _, ok := lookup(prefix); !ok {
+			return nil // an empty section
+		}
+	}
+	return c.set(prefix, scalar(v))
+}
+
+// stringMap returns v as a map if it is one, with YAML's keys made strings.
+func stringMap(v interface{}) (map[string]interface{}, bool) {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		return m, true
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			out[fmt.Sprint(k)] = v
+		}
+		return out, true
+	}
+	return nil, false
+}
+
+// scalar is the string form settings are set from.
+func scalar(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// envName returns the environment variable that overrides key.
+func envName(key string) string {
+	return "IOTMONITOR_" + strings.ToUpper(strings.Replace(key, ".", "_", -1))
+}
+
+// loadEnv applies the settings set in the environment over cfg.
+func loadEnv(cfg *config) error {
+	for _, s := range settings {
+		if value, ok := os.LookupEnv(envName(s.key)); ok {
+			if err := cfg.set(s.key, value); err != nil {
+				return fmt.Errorf("%s: %v", envName(s.key), err)
+			}
+		}
+	}
+	return nil
+}
+
+// flagValue records a setting given as a flag, to be applied once the file
+// and environment have been read.
+type flagValue struct {
+	key string
+	set *[][2]string
+}
+
+func (f flagValue) String() string { return "" }
+
+func (f flagValue) Set(value string) error {
+	*f.set = append(*f.set, [2]string{f.key, value})
+	return nil
+}
+
+func (c config) validate() error {
+	for _, addr := range []struct{ key, value string }{
+		{"listen.grpc", c.GRPCAddr},
+		{"listen.http", c.HTTPAddr},
+		{"listen.debug", c.DebugAddr},
+		{"store.redis_addr", c.Store.RedisAddr},
+	} {
+		if _, _, err := net.SplitHostPort(addr.value); err != nil {
+			return fmt.Errorf("%s: invalid address %q", addr.key, addr.value)
+		}
+	}
+	if c.Store.Backend != iotmonitor.StoreBackendRedis {
+		return fmt.Errorf("store.backend: unknown backend %q, want %s", c.Store.Backend, iotmonitor.StoreBackendRedis)
+	}
+	if c.Store.RedisDB < 0 {
+		return errors.New("store.redis_db must not be negative")
+	}
+	if c.Store.DialTimeout < 0 {
+		return errors.New("store.dial_timeout must not be negative")
+	}
+	if c.FirmwareDir == "" {
+		return errors.New("store.firmware_dir must be set")
+	}
+	if (c.TLSCert == "") != (c.TLSKey == "") {
+		return errors.New("tls.cert_file and tls.key_file must be set together")
+	}
+	if c.TLSClientCA != "" && c.TLSCert == "" {
+		return errors.New("tls.client_ca_file needs tls.cert_file and tls.key_file")
+	}
+	if c.RawRetention <= 0 {
+		return errors.New("retention.raw must be positive")
+	}
+	if c.HourlyRetention < c.RawRetention {
+		return errors.New("retention.hourly must be at least retention.raw")
+	}
+	if c.CompactionInterval <= 0 {
+		return errors.New("retention.compaction_interval must be positive")
+	}
+	if c.RateLimit < 0 {
+		return errors.New("rate_limit.rps must not be negative")
+	}
+	if c.RateLimit > 0 && c.RateBurst < 1 {
+		return errors.New("rate_limit.burst must be at least 1")
+	}
+	switch c.LogLevel {
+	case logInfo, logDebug:
+	default:
+		return fmt.Errorf("log.level: unknown level %q, want info or debug", c.LogLevel)
+	}
+	switch c.LogFormat {
+	case logLogfmt, logJSON:
+	default:
+		return fmt.Errorf("log.format: unknown format %q, want logfmt or json", c.LogFormat)
+	}
+	if c.ShutdownDelay < 0 {
+		return errors.New("shutdown.delay must not be negative")
+	}
+	if c.ShutdownTimeout <= 0 {
+		return errors.New("shutdown.timeout must be positive")
+	}
+	if c.HealthInterval <= 0 || c.HealthTimeout <= 0 {
+		return errors.New("health.interval and health.timeout must be positive")
+	}
+	if c.HeartbeatInterval <= 0 || c.OfflineTimeout <= 0 {
+		return errors.New("heartbeat.interval and heartbeat.offline_timeout must be positive")
+	}
+	for deviceType, d := range c.OfflineTimeouts {
+		if d <= 0 {
+			return fmt.Errorf("heartbeat.offline_timeouts: %s must be positive", deviceType)
+		}
+	}
+	if c.AbsenceInterval <= 0 {
+		return errors.New("rules.absence_interval must be positive")
+	}
+	if c.BatteryInterval <= 0 || c.LowBatteryRuntime <= 0 {
+		return errors.New("battery.interval and battery.low_runtime must be positive")
+	}
+	_, err := c.tlsConfig()
+	return err
+}
+
+// tlsConfig returns the TLS configuration to serve with, or nil to serve
+// plaintext.
+func (c config) tlsConfig() (*tls.Config, error) {
+	if c.TLSCert == "" {
+		return nil, nil
+	}
+	cert, err := tls.LoadX509KeyPair(c.TLSCert, c.TLSKey)
+	if err != nil {
+		return nil, fmt.Errorf("tls: %v", err)
+	}
+	config := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	if c.TLSClientCA != "" {
+		pem, err := ioutil.ReadFile(c.TLSClientCA)
+		if err != nil {
+			return nil, fmt.Errorf("tls.client_ca_file: %v", err)
+		}
+		config.ClientCAs = x509.NewCertPool()
+		if !config.ClientCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("tls.client_ca_file: no certificates in %s", c.TLSClientCA)
+		}
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config, nil
+}
+
+// print writes cfg as a YAML config file, with secrets redacted.
+func (c config) print(w io.Writer) error {
+	var section string
+	for _, s := range settings {
+		dot := strings.Index(s.key, ".")
+		if s.key[:dot] != section {
+			section = s.key[:dot]
+			if _, err := fmt.Fprintf(w, "%s:\n", section); err != nil {
+				return err
+			}
+		}
+		value := s.get(&c)
+		switch {
+		case s.secret && value != "":
+			value = `"<redacted>"`
+		case value == "" || strings.ContainsAny(value, ":#'\"[],") || value != strings.TrimSpace(value):
+			value = strconv.Quote(value)
+		}
+		if _, err := fmt.Fprintf(w, "  %s: %s\n", s.key[dot+1:], value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestOfflineTimeoutsSetting(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    map[string]time.Duration
+		wantErr bool
+	}{
+		{"Drone=30s,Sensor=5m", map[string]time.Duration{"Drone": 30 * time.Second, "Sensor": 5 * time.Minute}, false},
+		{" Rover = 2m ", map[string]time.Duration{"Rover": 2 * time.Minute}, false},
+		{"", map[string]time.Duration{}, false},
+		{"Drone", nil, true},
+		{"=30s", nil, true},
+		{"Drone=soon", nil, true},
+	}
+	for _, tt := range tests {
+		cfg := defaultConfig()
+		err := cfg.set("heartbeat.offline_timeouts", tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("set(%q) = %v, want error %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if err == nil && !reflect.DeepEqual(cfg.OfflineTimeouts, tt.want) {
+			t.Errorf("set(%q) gave %v, want %v", tt.value, cfg.OfflineTimeouts, tt.want)
+		}
+	}
+}
+
+func TestOfflineTimeoutsRoundTrip(t *testing.T) {
+	cfg := defaultConfig()
+	s, _ := lookup("heartbeat.offline_timeouts")
+	value := s.get(&cfg)
+	if value != "Drone=30s,Sensor=5m0s" {
+		t.Errorf("get() = %q, want pairs sorted by type", value)
+	}
+	var back config
+	if err := s.set(&back, value); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(back.OfflineTimeouts, cfg.OfflineTimeouts) {
+		t.Errorf("round trip gave %v, want %v", back.OfflineTimeouts, cfg.OfflineTimeouts)
+	}
+}
+
+func TestValidateWorkerIntervals(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *config)
+	}{
+		{"heartbeat interval", func(c *config) { c.HeartbeatInterval = 0 }},
+		{"offline timeout", func(c *config) { c.OfflineTimeout = -time.Second }},
+		{"per-type offline timeout", func(c *config) { c.OfflineTimeouts = map[string]time.Duration{"Drone": 0} }},
+		{"absence interval", func(c *config) { c.AbsenceInterval = 0 }},
+		{"battery interval", func(c *config) { c.BatteryInterval = 0 }},
+		{"low battery runtime", func(c *config) { c.LowBatteryRuntime = 0 }},
+	}
+	if err := defaultConfig().validate(); err != nil {
+		t.Fatalf("default config: %v", err)
+	}
+	for _, tt := range tests {
+		cfg := defaultConfig()
+		tt.modify(&cfg)
+		if err := cfg.validate(); err == nil {
+			t.Errorf("%s: validate() = nil, want an error", tt.name)
+		}
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "monitord")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	want := defaultConfig()
+	want.GRPCAddr = ":9091"
+	want.Store.RedisAddr = "redis.internal:6379"
+	want.Tokens = []string{"token-1", "token-2"}
+	want.RateLimit, want.RateBurst = 2.5, 10
+	want.OfflineTimeouts = map[string]time.Duration{"Drone": 30 * time.Second, "Sensor": 5 * time.Minute}
+
+	tests := []struct {
+		name    string
+		file    string
+		want    *config
+		wantErr bool
+	}{
+		{
+			"yaml block list and map",
+			`# monitord
+listen:
+  grpc: ":9091"
+store:
+  redis_addr: redis.internal:6379 # the cache
+auth:
+  tokens:
+    - token-1
+    - "token-2"
+rate_limit:
+  rps: 2.5
+  burst: 10
+heartbeat:
+  offline_timeouts:
+    Drone: 30s
+    Sensor: 5m
+tls:
+`,
+			&want,
+			false,
+		},
+		{
+			"yaml flow list and pairs",
+			`listen: {grpc: ":9091"}
+store: {redis_addr: "redis.internal:6379"}
+auth: {tokens: [token-1, token-2]}
+rate_limit: {rps: 2.5, burst: 10}
+heartbeat: {offline_timeouts: "Drone=30s,Sensor=5m"}
+`,
+			&want,
+			false,
+		},
+		{
+			"toml",
+			`[listen]
+grpc = ":9091"
+[store]
+redis_addr = "redis.internal:6379"
+[auth]
+tokens = ["token-1", "token-2"]
+[rate_limit]
+rps = 2.5
+burst = 10
+[heartbeat.offline_timeouts]
+Drone = "30s"
+Sensor = "5m"
+`,
+			&want,
+			false,
+		},
+		{"unknown setting", "listen:\n  ftp: \":21\"\n", nil, true},
+		{"bad duration", "heartbeat:\n  offline_timeouts:\n    Drone: soon\n", nil, true},
+		{"not a mapping", "- listen\n", nil, true},
+		{"setting outside a section", "grpc: \":9091\"\n", nil, true},
+		{"malformed", "listen:\n  grpc: [\n", nil, true},
+	}
+	for i, tt := range tests {
+		ext := ".yaml"
+		if tt.name == "toml" {
+			ext = ".toml"
+		}
+		path := filepath.Join(dir, fmt.Sprintf("%d%s", i, ext))
+		if err := ioutil.WriteFile(path, []byte(tt.file), 0600); err != nil {
+			t.Fatal(err)
+		}
+		cfg := defaultConfig()
+		err := loadConfig(&cfg, path, true)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: loadConfig() = %v, want error %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if tt.want != nil && !reflect.DeepEqual(cfg, *tt.want) {
+			t.Errorf("%s: loadConfig() gave %+v, want %+v", tt.name, cfg, *tt.want)
+		}
+	}
+}
+
+func TestPrintedConfigLoads(t *testing.T) {
+	cfg := defaultConfig()
+	cfg.Tokens = []string{"token-1", "token-2"}
+	cfg.LogFormat = logJSON
+	var buf bytes.Buffer
+	if err := cfg.print(&buf); err != nil {
+		t.Fatal(err)
+	}
+	f, err := ioutil.TempFile("", "monitord")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.Write(buf.Bytes())
+	f.Close()
+
+	back := defaultConfig()
+	back.LogFormat = logLogfmt
+	if err := loadConfig(&back, f.Name(), true); err != nil {
+		t.Fatalf("loading\n%s: %v", buf.Bytes(), err)
+	}
+	cfg.Tokens = []string{"<redacted>"}
+	if !reflect.DeepEqual(back, cfg) {
+		t.Errorf("loaded %+v, want %+v", back, cfg)
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/autodidaddict/iotmonitor"
+	"golang.org/x/net/context"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// monitorService is the gRPC service name health is reported for, beside
+// the server as a whole ("").
+const monitorService = "pb.Monitor"
+
+// Health components
+const (
+	componentStore   = "store"
+	componentEvents  = "event_bus"
+	componentWorkers = "workers"
+	componentServer  = "server"
+)
+
+// health serves monitord's liveness at /healthz and readiness at /readyz,
+// and keeps the gRPC health service in step with readiness. It is alive
+// while its event bus and workers are up, and ready while the store is up
+// too and it isn't draining.
+type health struct {
+	checks  map[string]iotmonitor.HealthCheck
+	timeout time.Duration
+	grpc    *grpchealth.Server
+
+	mu       sync.Mutex
+	draining bool
+}
+
+func newHealth(store iotmonitor.StoreConfig, events *iotmonitor.EventBus, workers *workerStates, timeout time.Duration) *health {
+	return &health{
+		checks: map[string]iotmonitor.HealthCheck{
+			componentStore:   iotmonitor.StoreCheck(store),
+			componentEvents:  iotmonitor.EventBusCheck(events),
+			componentWorkers: workers.check,
+		},
+		timeout: timeout,
+		grpc:    grpchealth.NewServer(),
+	}
+}
+
+// check runs the checks for the named components.
+func (h *health) check(ctx context.Context, components ...string) iotmonitor.HealthReport {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+	checks := make(map[string]iotmonitor.HealthCheck, len(components))
+	for _, name := range components {
+		checks[name] = h.checks[name]
+	}
+	return iotmonitor.CheckHealth(ctx, checks)
+}
+
+// ready checks readiness.
+func (h *health) ready(ctx context.Context) iotmonitor.HealthReport {
+	report := h.check(ctx, componentStore, componentEvents, componentWorkers)
+	h.mu.Lock()
+	draining := h.draining
+	h.mu.Unlock()
+	if draining {
+		report.Components[componentServer] = iotmonitor.ComponentHealth{Status: iotmonitor.HealthDown, Error: "draining"}
+		report.Status = iotmonitor.HealthDown
+	}
+	return report
+}
+
+func (h *health) serveLive(w http.ResponseWriter, r *http.Request) {
+	writeHealth(w, h.check(r.Context(), componentEvents, componentWorkers))
+}
+
+func (h *health) serveReady(w http.ResponseWriter, r *http.Request) {
+	writeHealth(w, h.ready(r.Context()))
+}
+
+func writeHealth(w http.ResponseWriter, report iotmonitor.HealthReport) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if report.Status != iotmonitor.HealthUp {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(report)
+}
+
+// run sets the gRPC serving status from readiness every interval until ctx
+// is done.
+func (h *health) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		status := healthpb.HealthCheckResponse_NOT_SERVING
+		if h.ready(ctx).Status == iotmonitor.HealthUp {
+			status = healthpb.HealthCheckResponse_SERVING
+		}
+		h.mu.Lock()
+		if !h.draining {
+			h.setServing(status)
+		}
+		h.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (h *health) setServing(status healthpb.HealthCheckResponse_ServingStatus) {
+	h.grpc.SetServingStatus("", status)
+	h.grpc.SetServingStatus(monitorService, status)
+}
+
+// drain fails readiness from now on.
+func (h *health) drain() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.draining = true
+	h.setServing(healthpb.HealthCheckResponse_NOT_SERVING)
+}
+
+// workerStates tracks whether each background worker is running.
+type workerStates struct {
+	mu     sync.Mutex
+	states map[string]workerState
+}
+
+type workerState struct {
+	Running bool   `json:"running"`
+	Since   int64  `json:"since"`
+	Error   string `json:"error,omitempty"`
+}
+
+func newWorkerStates() *workerStates {
+	return &workerStates{states: make(map[string]workerState)}
+}
+
+func (w *workerStates) started(name string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.states[name] = workerState{Running: true, Since: time.Now().Unix()}
+}
+
+// stopped records that a worker has returned; err is nil or
+// context.Canceled if it was told to.
+func (w *workerStates) stopped(name string, err error) {
+	state := workerState{Since: time.Now().Unix()}
+	if err != nil && err != context.Canceled {
+		state.Error = err.Error()
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.states[name] = state
+}
+
+// check reports the workers down if any has stopped on its own.
+func (w *workerStates) check(ctx context.Context) iotmonitor.ComponentHealth {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	h := iotmonitor.ComponentHealth{Status: iotmonitor.HealthUp}
+	details := make(map[string]workerState, len(w.states))
+	for name, s := range w.states {
+		details[name] = s
+		if s.Error != "" {
+			h.Status = iotmonitor.HealthDown
+			h.Error = name + ": " + s.Error
+		}
+	}
+	h.Details = details
+	return h
+}
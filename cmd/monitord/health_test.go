@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/autodidaddict/iotmonitor"
+	"golang.org/x/net/context"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestWorkerStates(t *testing.T) {
+	tests := []struct {
+		name      string
+		run       func(w *workerStates)
+		want      string
+		wantError string
+	}{
+		{"running", func(w *workerStates) { w.started("sweeper") }, iotmonitor.HealthUp, ""},
+		{
+			"told to stop",
+			func(w *workerStates) {
+				w.started("sweeper")
+				w.stopped("sweeper", context.Canceled)
+			},
+			iotmonitor.HealthUp,
+			"",
+		},
+		{
+			"stopped on its own",
+			func(w *workerStates) {
+				w.started("sweeper")
+				w.started("compactor")
+				w.stopped("compactor", errors.New("redis: connection refused"))
+			},
+			iotmonitor.HealthDown,
+			"compactor: redis: connection refused",
+		},
+	}
+	for _, tt := range tests {
+		w := newWorkerStates()
+		tt.run(w)
+		h := w.check(context.Background())
+		if h.Status != tt.want || h.Error != tt.wantError {
+			t.Errorf("%s: check() = %s, %q, want %s, %q", tt.name, h.Status, h.Error, tt.want, tt.wantError)
+		}
+		if details := h.Details.(map[string]workerState); details["sweeper"].Running != (tt.name == "running" || tt.name == "stopped on its own") {
+			t.Errorf("%s: sweeper state %+v", tt.name, details["sweeper"])
+		}
+	}
+}
+
+// testHealth returns a health whose store check reports store.
+func testHealth(store string) *health {
+	h := newHealth(iotmonitor.StoreConfig{}, iotmonitor.NewEventBus(), newWorkerStates(), time.Second)
+	h.checks[componentStore] = func(ctx context.Context) iotmonitor.ComponentHealth {
+		return iotmonitor.ComponentHealth{Status: store}
+	}
+	return h
+}
+
+func serve(handler http.HandlerFunc) (int, iotmonitor.HealthReport) {
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest("GET", "/", nil))
+	var report iotmonitor.HealthReport
+	json.NewDecoder(rec.Body).Decode(&report)
+	return rec.Code, report
+}
+
+func TestHealthEndpoints(t *testing.T) {
+	tests := []struct {
+		name      string
+		store     string
+		drain     bool
+		wantLive  int
+		wantReady int
+	}{
+		{"up", iotmonitor.HealthUp, false, http.StatusOK, http.StatusOK},
+		{"store down", iotmonitor.HealthDown, false, http.StatusOK, http.StatusServiceUnavailable},
+		{"draining", iotmonitor.HealthUp, true, http.StatusOK, http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		h := testHealth(tt.store)
+		if tt.drain {
+			h.drain()
+		}
+		if code, report := serve(h.serveLive); code != tt.wantLive || report.Components[componentStore].Status != "" {
+			t.Errorf("%s: /healthz = %d %+v, want %d without the store", tt.name, code, report, tt.wantLive)
+		}
+		code, report := serve(h.serveReady)
+		if code != tt.wantReady {
+			t.Errorf("%s: /readyz = %d %+v, want %d", tt.name, code, report, tt.wantReady)
+		}
+		if _, ok := report.Components[componentServer]; ok != tt.drain {
+			t.Errorf("%s: /readyz reports the server as %+v", tt.name, report.Components[componentServer])
+		}
+	}
+}
+
+func TestHealthGRPCStatus(t *testing.T) {
+	servingStatus := func(h *health) healthpb.HealthCheckResponse_ServingStatus {
+		resp, err := h.grpc.Check(context.Background(), &healthpb.HealthCheckRequest{Service: monitorService})
+		if err != nil {
+			return healthpb.HealthCheckResponse_UNKNOWN // not set yet
+		}
+		return resp.Status
+	}
+
+	h := testHealth(iotmonitor.HealthUp)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		h.run(ctx, time.Millisecond)
+		close(done)
+	}()
+	for deadline := time.Now().Add(time.Second); servingStatus(h) != healthpb.HealthCheckResponse_SERVING; {
+		if time.Now().After(deadline) {
+			t.Fatal("gRPC health never reported SERVING")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	h.drain()
+	time.Sleep(10 * time.Millisecond)
+	if got := servingStatus(h); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Errorf("gRPC health = %v while draining, want NOT_SERVING", got)
+	}
+	cancel()
+	<-done
+}
@@ -1,14 +1,28 @@
+// Command monitord serves the iotmonitor API over gRPC and HTTP, with
+// metrics on a separate debug listener.
+//
+//	monitord -config /etc/iotmonitor/monitord.yaml
+//	IOTMONITOR_STORE_REDIS_ADDR=redis:6379 monitord -log.level debug
+//	monitord -print-config
+//	monitord -device-token 42
+//
+// See config for the settings and where they are read from.
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
+	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 
 	"golang.org/x/net/context"
 
@@ -18,32 +32,86 @@ import (
 
 	"github.com/autodidaddict/iotmonitor/pb"
 	"github.com/go-kit/kit/endpoint"
+	kitlog "github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/metrics"
 	"github.com/go-kit/kit/metrics/prometheus"
 	stdprometheus "github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
-const (
-	port = ":50051"
-)
+func main() {
+	cfg := defaultConfig()
+	configPath := flag.String("config", defaultConfigPath(), "config file, YAML or, if named *.toml, TOML")
+	printConfig := flag.Bool("print-config", false, "print the effective config, secrets redacted, and exit")
+	deviceToken := flag.Uint64("device-token", 0, "print the bearer token for the gateway with this device ID, and exit")
+	var overrides [][2]string
+	for _, s := range settings {
+		flag.Var(flagValue{key: s.key, set: &overrides}, s.key, fmt.Sprintf("%s (env %s, default %q)", s.usage, envName(s.key), s.get(&cfg)))
+	}
+	flag.Parse()
 
-var (
-	gRPCAddr  = ":8081"
-	httpAddr  = ":8080"
-	debugAddr = ":8082"
-)
+	named := false
+	flag.Visit(func(f *flag.Flag) { named = named || f.Name == "config" })
+	if err := loadConfig(&cfg, *configPath, named || os.Getenv("IOTMONITOR_CONFIG") != ""); err != nil {
+		fatal(err)
+	}
+	if err := loadEnv(&cfg); err != nil {
+		fatal(err)
+	}
+	for _, o := range overrides {
+		if err := cfg.set(o[0], o[1]); err != nil {
+			fatal(err)
+		}
+	}
+	if err := cfg.validate(); err != nil {
+		fatal(err)
+	}
+	if *printConfig {
+		if err := cfg.print(os.Stdout); err != nil {
+			fatal(err)
+		}
+		return
+	}
+	if *deviceToken != 0 {
+		if cfg.DeviceSecret == "" {
+			fatal(fmt.Errorf("-device-token needs auth.device_secret"))
+		}
+		fmt.Println(iotmonitor.DeviceToken(cfg.DeviceSecret, *deviceToken))
+		return
+	}
+	tlsConfig, err := cfg.tlsConfig()
+	if err != nil {
+		fatal(err)
+	}
+	retention := iotmonitor.Retention{Raw: cfg.RawRetention, Hourly: cfg.HourlyRetention}
+
+	var logger kitlog.Logger
+	{
+		if cfg.LogFormat == logJSON {
+			logger = kitlog.NewJSONLogger(kitlog.NewSyncWriter(os.Stderr))
+		} else {
+			logger = kitlog.NewLogfmtLogger(kitlog.NewSyncWriter(os.Stderr))
+		}
+		logger = kitlog.With(logger, "ts", kitlog.DefaultTimestampUTC)
+		log.SetFlags(0)
+		log.SetOutput(kitlog.NewStdlibAdapter(logger))
+	}
 
-func main() {
 	ctx := context.Background()
-	errChan := make(chan error)
-	go func() {
-		c := make(chan os.Signal, 1)
-		signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
-		errChan <- fmt.Errorf("%s", <-c)
-	}()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+
+	// The first server or worker to fail brings monitord down; once that,
+	// or a signal, has started the shutdown, later errors are dropped.
+	errChan := make(chan error, 1)
+	fail := func(err error) {
+		select {
+		case errChan <- err:
+		default:
+		}
+	}
 
-	var telemetryUpdates, statusUpdates, devicesRegistered metrics.Counter
+	var telemetryUpdates, statusUpdates, devicesRegistered, schemaViolations metrics.Counter
 	{
 		telemetryUpdates = prometheus.NewCounterFrom(stdprometheus.CounterOpts{
 			Namespace: "iotmonitor",
@@ -60,12 +128,55 @@ func main() {
 			Name:      "devices_registered",
 			Help:      "Total number of devices registered.",
 		}, []string{})
+		schemaViolations = prometheus.NewCounterFrom(stdprometheus.CounterOpts{
+			Namespace: "iotmonitor",
+			Name:      "telemetry_schema_violations",
+			Help:      "Total number of telemetry readings that violated their device type's schema.",
+		}, []string{"reason", "action"})
 	}
 
+	var devicesOnline metrics.Gauge
+	{
+		devicesOnline = prometheus.NewGaugeFrom(stdprometheus.GaugeOpts{
+			Namespace: "iotmonitor",
+			Name:      "devices_online",
+			Help:      "Number of devices currently reporting.",
+		}, []string{})
+	}
+
+	// The battery gauges are built from their vectors so that the series of
+	// devices that go away can be deleted.
+	var batteryLevel, batteryTimeToEmpty metrics.Gauge
+	var forgetBattery func(device string)
+	{
+		level := stdprometheus.NewGaugeVec(stdprometheus.GaugeOpts{
+			Namespace: "iotmonitor",
+			Name:      "battery_level",
+			Help:      "Last reported battery percentage.",
+		}, []string{"device"})
+		timeToEmpty := stdprometheus.NewGaugeVec(stdprometheus.GaugeOpts{
+			Namespace: "iotmonitor",
+			Name:      "battery_time_to_empty_seconds",
+			Help:      "Predicted time until the battery is empty, 0 when not discharging.",
+		}, []string{"device"})
+		stdprometheus.MustRegister(level, timeToEmpty)
+		batteryLevel = prometheus.NewGauge(level)
+		batteryTimeToEmpty = prometheus.NewGauge(timeToEmpty)
+		forgetBattery = func(device string) {
+			level.DeleteLabelValues(device)
+			timeToEmpty.DeleteLabelValues(device)
+		}
+	}
+
+	events := iotmonitor.NewEventBus()
+
 	var srv iotmonitor.Service
 	{
-		srv = iotmonitor.NewService()
-		srv = iotmonitor.ServiceInstrumentingMiddleware(telemetryUpdates, devicesRegistered, statusUpdates)(srv)
+		srv, err = iotmonitor.NewService(events, iotmonitor.Config{Store: cfg.Store, Retention: retention, FirmwareDir: cfg.FirmwareDir})
+		if err != nil {
+			fatal(err)
+		}
+		srv = iotmonitor.ServiceInstrumentingMiddleware(telemetryUpdates, devicesRegistered, statusUpdates, schemaViolations)(srv)
 	}
 
 	var duration metrics.Histogram
@@ -77,61 +188,313 @@ func main() {
 		}, []string{"method", "success"})
 	}
 
+	instrument := func(method string, e endpoint.Endpoint) endpoint.Endpoint {
+		e = iotmonitor.EndpointInstrumentingMiddleware(duration.With("method", method))(e)
+		if cfg.LogLevel == logDebug {
+			e = iotmonitor.EndpointLoggingMiddleware(kitlog.With(logger, "method", method))(e)
+		}
+		return e
+	}
+
 	var registerEndpoint endpoint.Endpoint
 	{
-		registerDuration := duration.With("method", "register")
 		registerEndpoint = iotmonitor.MakeRegisterEndpoint(srv)
-		registerEndpoint = iotmonitor.EndpointInstrumentingMiddleware(registerDuration)(registerEndpoint)
+		registerEndpoint = instrument("register", registerEndpoint)
 	}
 
 	var updateEndpoint endpoint.Endpoint
 	{
-		updateDuration := duration.With("method", "update")
 		updateEndpoint = iotmonitor.MakeUpdateEndpoint(srv)
-		updateEndpoint = iotmonitor.EndpointInstrumentingMiddleware(updateDuration)(updateEndpoint)
+		updateEndpoint = instrument("update", updateEndpoint)
 	}
 
 	var telemetryEndpoint endpoint.Endpoint
 	{
-		telemetryDuration := duration.With("method", "telemetry")
 		telemetryEndpoint = iotmonitor.MakeTelemetryEndpoint(srv)
-		telemetryEndpoint = iotmonitor.EndpointInstrumentingMiddleware(telemetryDuration)(telemetryEndpoint)
+		telemetryEndpoint = instrument("telemetry", telemetryEndpoint)
 	}
 
 	endpoints := iotmonitor.Endpoints{
 		UpdateEndpoint:    updateEndpoint,
 		TelemetryEndpoint: telemetryEndpoint,
 		RegisterEndpoint:  registerEndpoint,
+
+		CreateRuleEndpoint:   instrument("create_rule", iotmonitor.MakeCreateRuleEndpoint(srv)),
+		GetRuleEndpoint:      instrument("get_rule", iotmonitor.MakeGetRuleEndpoint(srv)),
+		UpdateRuleEndpoint:   instrument("update_rule", iotmonitor.MakeUpdateRuleEndpoint(srv)),
+		DeleteRuleEndpoint:   instrument("delete_rule", iotmonitor.MakeDeleteRuleEndpoint(srv)),
+		ListRulesEndpoint:    instrument("list_rules", iotmonitor.MakeListRulesEndpoint(srv)),
+		ActiveAlertsEndpoint: instrument("active_alerts", iotmonitor.MakeActiveAlertsEndpoint(srv)),
+
+		CreateWebhookEndpoint: instrument("create_webhook", iotmonitor.MakeCreateWebhookEndpoint(srv)),
+		ListWebhooksEndpoint:  instrument("list_webhooks", iotmonitor.MakeListWebhooksEndpoint(srv)),
+		DeleteWebhookEndpoint: instrument("delete_webhook", iotmonitor.MakeDeleteWebhookEndpoint(srv)),
+		DeadLettersEndpoint:   instrument("dead_letters", iotmonitor.MakeDeadLettersEndpoint(srv)),
+
+		GetDeviceEndpoint:   instrument("get_device", iotmonitor.MakeGetDeviceEndpoint(srv)),
+		ListDevicesEndpoint: instrument("list_devices", iotmonitor.MakeListDevicesEndpoint(srv)),
+
+		CreateGeofenceEndpoint: instrument("create_geofence", iotmonitor.MakeCreateGeofenceEndpoint(srv)),
+		UpdateGeofenceEndpoint: instrument("update_geofence", iotmonitor.MakeUpdateGeofenceEndpoint(srv)),
+		DeleteGeofenceEndpoint: instrument("delete_geofence", iotmonitor.MakeDeleteGeofenceEndpoint(srv)),
+		ListGeofencesEndpoint:  instrument("list_geofences", iotmonitor.MakeListGeofencesEndpoint(srv)),
+
+		NearbyDevicesEndpoint: instrument("nearby_devices", iotmonitor.MakeNearbyDevicesEndpoint(srv)),
+		DevicesInBoxEndpoint:  instrument("devices_in_box", iotmonitor.MakeDevicesInBoxEndpoint(srv)),
+
+		GetStatusEndpoint: instrument("get_status", iotmonitor.MakeGetStatusEndpoint(srv)),
+
+		BatteryHistoryEndpoint: instrument("battery_history", iotmonitor.MakeBatteryHistoryEndpoint(srv)),
+
+		QueryTelemetryEndpoint: instrument("query_telemetry", iotmonitor.MakeQueryTelemetryEndpoint(srv)),
+
+		AggregateTelemetryEndpoint: instrument("aggregate_telemetry", iotmonitor.MakeAggregateTelemetryEndpoint(srv)),
+
+		SetLabelsEndpoint: instrument("set_labels", iotmonitor.MakeSetLabelsEndpoint(srv)),
+
+		ListStatusesEndpoint:       instrument("list_statuses", iotmonitor.MakeListStatusesEndpoint(srv)),
+		CreateGroupEndpoint:        instrument("create_group", iotmonitor.MakeCreateGroupEndpoint(srv)),
+		UpdateGroupEndpoint:        instrument("update_group", iotmonitor.MakeUpdateGroupEndpoint(srv)),
+		DeleteGroupEndpoint:        instrument("delete_group", iotmonitor.MakeDeleteGroupEndpoint(srv)),
+		ListGroupsEndpoint:         instrument("list_groups", iotmonitor.MakeListGroupsEndpoint(srv)),
+		AddGroupMembersEndpoint:    instrument("add_group_members", iotmonitor.MakeAddGroupMembersEndpoint(srv)),
+		RemoveGroupMembersEndpoint: instrument("remove_group_members", iotmonitor.MakeRemoveGroupMembersEndpoint(srv)),
+
+		RelayStatusEndpoint:    instrument("relay_status", iotmonitor.MakeRelayStatusEndpoint(srv)),
+		RelayTelemetryEndpoint: instrument("relay_telemetry", iotmonitor.MakeRelayTelemetryEndpoint(srv)),
+		GetTopologyEndpoint:    instrument("get_topology", iotmonitor.MakeGetTopologyEndpoint(srv)),
+
+		GetTwinEndpoint:        instrument("get_twin", iotmonitor.MakeGetTwinEndpoint(srv)),
+		UpdateDesiredEndpoint:  instrument("update_desired", iotmonitor.MakeUpdateDesiredEndpoint(srv)),
+		ReportStateEndpoint:    instrument("report_state", iotmonitor.MakeReportStateEndpoint(srv)),
+		DeliverDesiredEndpoint: instrument("deliver_desired", iotmonitor.MakeDeliverDesiredEndpoint(srv)),
+
+		EnqueueCommandEndpoint:  instrument("enqueue_command", iotmonitor.MakeEnqueueCommandEndpoint(srv)),
+		AckCommandEndpoint:      instrument("ack_command", iotmonitor.MakeAckCommandEndpoint(srv)),
+		CommandHistoryEndpoint:  instrument("command_history", iotmonitor.MakeCommandHistoryEndpoint(srv)),
+		DeliverCommandsEndpoint: instrument("deliver_commands", iotmonitor.MakeDeliverCommandsEndpoint(srv)),
+
+		UploadFirmwareEndpoint:   instrument("upload_firmware", iotmonitor.MakeUploadFirmwareEndpoint(srv)),
+		ListFirmwareEndpoint:     instrument("list_firmware", iotmonitor.MakeListFirmwareEndpoint(srv)),
+		DownloadFirmwareEndpoint: instrument("download_firmware", iotmonitor.MakeDownloadFirmwareEndpoint(srv)),
+		CreateCampaignEndpoint:   instrument("create_campaign", iotmonitor.MakeCreateCampaignEndpoint(srv)),
+		GetCampaignEndpoint:      instrument("get_campaign", iotmonitor.MakeGetCampaignEndpoint(srv)),
+		ListCampaignsEndpoint:    instrument("list_campaigns", iotmonitor.MakeListCampaignsEndpoint(srv)),
+		ControlCampaignEndpoint:  instrument("control_campaign", iotmonitor.MakeControlCampaignEndpoint(srv)),
+
+		ReportFirmwareEndpoint: instrument("report_firmware", iotmonitor.MakeReportFirmwareEndpoint(srv)),
+		OfferFirmwareEndpoint:  instrument("offer_firmware", iotmonitor.MakeOfferFirmwareEndpoint(srv)),
+
+		SetTelemetrySchemaEndpoint:    instrument("set_telemetry_schema", iotmonitor.MakeSetTelemetrySchemaEndpoint(srv)),
+		GetTelemetrySchemaEndpoint:    instrument("get_telemetry_schema", iotmonitor.MakeGetTelemetrySchemaEndpoint(srv)),
+		ListTelemetrySchemasEndpoint:  instrument("list_telemetry_schemas", iotmonitor.MakeListTelemetrySchemasEndpoint(srv)),
+		DeleteTelemetrySchemaEndpoint: instrument("delete_telemetry_schema", iotmonitor.MakeDeleteTelemetrySchemaEndpoint(srv)),
+
+		ImportDevicesEndpoint: instrument("import_devices", iotmonitor.MakeImportDevicesEndpoint(srv)),
+		ExportDevicesEndpoint: instrument("export_devices", iotmonitor.MakeExportDevicesEndpoint(srv)),
+
+		UpdateDeviceEndpoint: instrument("update_device", iotmonitor.MakeUpdateDeviceEndpoint(srv)),
+		DeleteDeviceEndpoint: instrument("delete_device", iotmonitor.MakeDeleteDeviceEndpoint(srv)),
 	}
 
+	// Background workers stop when workersCtx is cancelled, the webhook
+	// dispatcher only once they have, so that it can flush the events they
+	// publish on the way out. Its deliveries under way carry on until
+	// deliveriesCtx is cancelled at the drain deadline.
+	workersCtx, stopWorkers := context.WithCancel(ctx)
+	dispatcherCtx, stopDispatcher := context.WithCancel(ctx)
+	deliveriesCtx, stopDeliveries := context.WithCancel(ctx)
+	var workers, dispatcher sync.WaitGroup
+	states := newWorkerStates()
+	run := func(wg *sync.WaitGroup, name string, f func() error) {
+		wg.Add(1)
+		states.started(name)
+		go func() {
+			defer wg.Done()
+			err := f()
+			states.stopped(name, err)
+			fail(err)
+		}()
+	}
+
+	// Absence-of-data rules
+	run(&workers, "absence_sweeper", func() error {
+		return iotmonitor.RunAbsenceSweeper(workersCtx, cfg.Store, events, cfg.AbsenceInterval)
+	})
+
+	// Device heartbeats
+	run(&workers, "heartbeat_monitor", func() error {
+		monitor := &iotmonitor.HeartbeatMonitor{
+			DefaultTimeout: cfg.OfflineTimeout,
+			Timeouts:       cfg.OfflineTimeouts,
+			Interval:       cfg.HeartbeatInterval,
+			Online:         devicesOnline,
+			Events:         events,
+			Store:          cfg.Store,
+		}
+		return monitor.Run(workersCtx)
+	})
+
+	// Battery predictions
+	run(&workers, "battery_monitor", func() error {
+		monitor := &iotmonitor.BatteryMonitor{
+			LowRuntime:  cfg.LowBatteryRuntime,
+			Interval:    cfg.BatteryInterval,
+			Level:       batteryLevel,
+			TimeToEmpty: batteryTimeToEmpty,
+			Forget:      forgetBattery,
+			Events:      events,
+			Store:       cfg.Store,
+		}
+		return monitor.Run(workersCtx)
+	})
+
+	// Telemetry retention
+	run(&workers, "rollup_compactor", func() error {
+		return iotmonitor.RunRollupCompactor(workersCtx, cfg.Store, retention, cfg.CompactionInterval)
+	})
+
+	// Webhook delivery
+	run(&dispatcher, "webhook_dispatcher", func() error {
+		return iotmonitor.NewWebhookDispatcher(cfg.Store).Run(dispatcherCtx, deliveriesCtx, events)
+	})
+
+	guard := iotmonitor.NewGuard(cfg.Tokens, cfg.DeviceSecret, cfg.RateLimit, cfg.RateBurst)
+	health := newHealth(cfg.Store, events, states, cfg.HealthTimeout)
+	healthCtx, stopHealth := context.WithCancel(ctx)
+	defer stopHealth()
+	go health.run(healthCtx, cfg.HealthInterval)
+
 	// Debug/Diagnostics Transport
-	go func() {
-		log.Println("Debug http:", debugAddr)
+	debugServer := &http.Server{Addr: cfg.DebugAddr}
+	{
 		m := http.NewServeMux()
 		m.Handle("/metrics", promhttp.Handler())
-		errChan <- http.ListenAndServe(debugAddr, m)
+		m.HandleFunc("/healthz", health.serveLive)
+		m.HandleFunc("/readyz", health.serveReady)
+		debugServer.Handler = m
+	}
+	go func() {
+		logger.Log("transport", "debug/http", "addr", cfg.DebugAddr)
+		fail(debugServer.ListenAndServe())
 	}()
 
+	// The transports' event and watch streams end when streamsCtx is
+	// cancelled at the drain deadline, as they would otherwise hold up a
+	// graceful stop indefinitely.
+	streamsCtx, stopStreams := context.WithCancel(ctx)
+
 	// HTTP Transport
+	httpServer := &http.Server{
+		Addr:      cfg.HTTPAddr,
+		Handler:   guard.HTTP(iotmonitor.NewHTTPServer(streamsCtx, endpoints, events)),
+		TLSConfig: tlsConfig,
+	}
 	go func() {
-		log.Println("http:", httpAddr)
-		handler := iotmonitor.NewHTTPServer(ctx, endpoints)
-		errChan <- http.ListenAndServe(httpAddr, handler)
+		logger.Log("transport", "http", "addr", cfg.HTTPAddr, "tls", tlsConfig != nil)
+		if tlsConfig != nil {
+			fail(httpServer.ListenAndServeTLS("", ""))
+			return
+		}
+		fail(httpServer.ListenAndServe())
 	}()
 
 	// gRPC Transport
+	var gRPCServer *grpc.Server
+	{
+		options := []grpc.ServerOption{
+			grpc.UnaryInterceptor(guard.UnaryInterceptor()),
+			grpc.StreamInterceptor(guard.StreamInterceptor()),
+		}
+		if tlsConfig != nil {
+			options = append(options, grpc.Creds(credentials.NewTLS(tlsConfig)))
+		}
+		gRPCServer = grpc.NewServer(options...)
+		pb.RegisterMonitorServer(gRPCServer, iotmonitor.NewGRPCServer(streamsCtx, endpoints, events))
+		healthpb.RegisterHealthServer(gRPCServer, health.grpc)
+	}
 	go func() {
-		listener, err := net.Listen("tcp", gRPCAddr)
+		listener, err := net.Listen("tcp", cfg.GRPCAddr)
 		if err != nil {
-			errChan <- err
+			fail(err)
 			return
 		}
-		log.Println("grpc:", gRPCAddr)
-		handler := iotmonitor.NewGRPCServer(ctx, endpoints)
-		gRPCServer := grpc.NewServer()
-		pb.RegisterMonitorServer(gRPCServer, handler)
-		errChan <- gRPCServer.Serve(listener)
+		logger.Log("transport", "grpc", "addr", cfg.GRPCAddr, "tls", tlsConfig != nil)
+		fail(gRPCServer.Serve(listener))
 	}()
 
-	log.Fatalln(<-errChan)
+	exitCode := 0
+	select {
+	case sig := <-signals:
+		logger.Log("msg", "shutting down", "signal", sig)
+	case err := <-errChan:
+		logger.Log("msg", "shutting down", "err", err)
+		exitCode = 1
+	}
+
+	// Fail readiness and give load balancers time to notice before
+	// refusing connections. Then drain: the transports stop accepting and
+	// wait for the calls and streams under way, the workers finish their
+	// current pass, and the dispatcher finishes the webhook deliveries it
+	// has started and dead-letters the rest. Streams and deliveries still
+	// going at the deadline are cut off. The dispatcher is only stopped once
+	// the transports have drained, so it and the debug server, which goes
+	// last so that metrics cover the drain, each get a grace period beyond
+	// the deadline.
+	health.drain()
+	time.Sleep(cfg.ShutdownDelay)
+	drainCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	go func() {
+		<-drainCtx.Done()
+		stopStreams()
+		stopDeliveries()
+	}()
+
+	var transports sync.WaitGroup
+	transports.Add(2)
+	httpShutdown := make(chan error, 1)
+	go func() {
+		defer transports.Done()
+		httpShutdown <- httpServer.Shutdown(drainCtx)
+	}()
+	go func() {
+		defer transports.Done()
+		if !stopGRPC(drainCtx, gRPCServer) {
+			logger.Log("transport", "grpc", "during", "shutdown", "err", "calls cut off at the deadline")
+		}
+	}()
+
+	stopWorkers()
+	if !wait(drainCtx, &workers) {
+		logger.Log("msg", "background workers still running at the deadline")
+		exitCode = 1
+	}
+	transports.Wait()
+	if err := <-httpShutdown; err != nil {
+		logger.Log("transport", "http", "during", "shutdown", "err", err)
+		exitCode = 1
+	}
+
+	stopDispatcher()
+	flushCtx, cancelFlush := graceContext(drainCtx)
+	defer cancelFlush()
+	if !wait(flushCtx, &dispatcher) {
+		logger.Log("msg", "webhook deliveries cut off at the deadline, dead-lettering them")
+		dispatcher.Wait()
+		exitCode = 1
+	}
+
+	debugCtx, cancelDebug := graceContext(context.Background())
+	defer cancelDebug()
+	if err := debugServer.Shutdown(debugCtx); err != nil {
+		logger.Log("transport", "debug/http", "during", "shutdown", "err", err)
+	}
+	logger.Log("msg", "stopped")
+	os.Exit(exitCode)
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "monitord:", err)
+	os.Exit(1)
 }
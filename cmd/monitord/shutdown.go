@@ -0,0 +1,56 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+// shutdownGrace is how long the steps of a shutdown that only start once
+// the drain is over may take beyond its deadline.
+const shutdownGrace = 5 * time.Second
+
+// graceContext returns a context that ends shutdownGrace after ctx's
+// deadline, or after now if that has passed or ctx has none.
+func graceContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if now := time.Now(); !ok || deadline.Before(now) {
+		deadline = now
+	}
+	return context.WithDeadline(context.Background(), deadline.Add(shutdownGrace))
+}
+
+// stopGRPC stops s gracefully, or abruptly if its calls haven't finished
+// when ctx is done, reporting whether they all did.
+func stopGRPC(ctx context.Context, s *grpc.Server) bool {
+	stopped := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return true
+	case <-ctx.Done():
+		s.Stop()
+		<-stopped
+		return false
+	}
+}
+
+// wait waits for wg until ctx is done, reporting whether wg finished.
+func wait(ctx context.Context, wg *sync.WaitGroup) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
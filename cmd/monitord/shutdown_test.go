@@ -0,0 +1,37 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+func TestGraceContext(t *testing.T) {
+	ahead := time.Now().Add(time.Minute)
+	live, cancelLive := context.WithDeadline(context.Background(), ahead)
+	defer cancelLive()
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Minute))
+	defer cancelExpired()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want time.Time
+	}{
+		{"before the deadline", live, ahead.Add(shutdownGrace)},
+		{"past the deadline", expired, time.Now().Add(shutdownGrace)},
+		{"no deadline", context.Background(), time.Now().Add(shutdownGrace)},
+	}
+	for _, tt := range tests {
+		ctx, cancel := graceContext(tt.ctx)
+		deadline, ok := ctx.Deadline()
+		if !ok || deadline.Sub(tt.want) > time.Second || tt.want.Sub(deadline) > time.Second {
+			t.Errorf("%s: deadline %s, want about %s", tt.name, deadline, tt.want)
+		}
+		if ctx.Err() != nil {
+			t.Errorf("%s: context already done: %v", tt.name, ctx.Err())
+		}
+		cancel()
+	}
+}
@@ -0,0 +1,340 @@
+package iotmonitor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+	"golang.org/x/net/context"
+)
+
+// Command states
+const (
+	CommandPending   = "pending"
+	CommandDelivered = "delivered"
+	CommandSucceeded = "succeeded"
+	CommandFailed    = "failed"
+	CommandExpired   = "expired"
+)
+
+const (
+	defaultCommandTTL = time.Hour
+	// commandHistoryLimit is how many commands we keep per device.
+	commandHistoryLimit = 500
+)
+
+// Command is an instruction queued for a device, such as "reboot" or
+// "return_to_base". Times are Unix milliseconds.
+type Command struct {
+	ID          uint64                 `json:"id"`
+	DeviceID    uint64                 `json:"device_id"`
+	Name        string                 `json:"name"`
+	Args        map[string]interface{} `json:"args,omitempty"`
+	Status      string                 `json:"status"`
+	CreatedAt   int64                  `json:"created_at"`
+	ExpiresAt   int64                  `json:"expires_at"`
+	DeliveredAt int64                  `json:"delivered_at,omitempty"`
+	CompletedAt int64                  `json:"completed_at,omitempty"`
+	Result      string                 `json:"result,omitempty"`
+}
+
+var (
+	errCommandNotFound = errors.New("command not found")
+	errCommandDone     = errors.New("command already completed")
+)
+
+type commandRecord struct {
+	ID          uint64 `redis:"id"`
+	DeviceID    uint64 `redis:"device_id"`
+	Name        string `redis:"name"`
+	Args        string `redis:"args"`
+	Status      string `redis:"status"`
+	CreatedAt   int64  `redis:"created_at"`
+	ExpiresAt   int64  `redis:"expires_at"`
+	DeliveredAt int64  `redis:"delivered_at"`
+	CompletedAt int64  `redis:"completed_at"`
+	Result      string `redis:"result"`
+}
+
+func commandKey(id uint64) string {
+	return fmt.Sprintf("command:%d", id)
+}
+
+// pendingCommandsKey is a zset of a device's undelivered commands, scored by
+// ID so they are delivered in the order they were queued.
+func pendingCommandsKey(deviceID uint64) string {
+	return fmt.Sprintf("commands:pending:%d", deviceID)
+}
+
+// commandHistoryKey is a zset of all of a device's commands, scored by ID.
+func commandHistoryKey(deviceID uint64) string {
+	return fmt.Sprintf("commands:%d", deviceID)
+}
+
+// deleteCommands drops every command queued for a device.
+func deleteCommands(c redis.Conn, deviceID uint64) error {
+	ids, err := redis.Int64s(c.Do("ZRANGE", commandHistoryKey(deviceID), 0, -1))
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if _, err := c.Do("DEL", commandKey(uint64(id))); err != nil {
+			return err
+		}
+	}
+	_, err = c.Do("DEL", commandHistoryKey(deviceID), pendingCommandsKey(deviceID))
+	return err
+}
+
+// readCommand loads a command, reporting pending commands past their TTL as
+// expired.
+func readCommand(c redis.Conn, id uint64, now int64) (Command, error) {
+	v, err := redis.Values(c.Do("HGETALL", commandKey(id)))
+	if err != nil {
+		return Command{}, err
+	}
+	if len(v) == 0 {
+		return Command{}, errCommandNotFound
+	}
+	var rec commandRecord
+	if err := redis.ScanStruct(v, &rec); err != nil {
+		return Command{}, err
+	}
+	cmd := Command{
+		ID:          rec.ID,
+		DeviceID:    rec.DeviceID,
+		Name:        rec.Name,
+		Status:      rec.Status,
+		CreatedAt:   rec.CreatedAt,
+		ExpiresAt:   rec.ExpiresAt,
+		DeliveredAt: rec.DeliveredAt,
+		CompletedAt: rec.CompletedAt,
+		Result:      rec.Result,
+	}
+	if rec.Args != "" {
+		if err := json.Unmarshal([]byte(rec.Args), &cmd.Args); err != nil {
+			return cmd, err
+		}
+	}
+	if cmd.Status == CommandPending && now > cmd.ExpiresAt {
+		cmd.Status = CommandExpired
+	}
+	return cmd, nil
+}
+
+// EnqueueCommand queues a command for a device. It expires if the device
+// hasn't fetched it within ttl seconds; zero means an hour.
+func (s monitorService) EnqueueCommand(ctx context.Context, id uint64, name string, args map[string]interface{}, ttl int64) (uint64, error) {
+	if strings.TrimSpace(name) == "" {
+		return 0, errors.New("command name is required")
+	}
+	if ttl < 0 {
+		return 0, errors.New("command ttl must not be negative")
+	}
+	lifetime := defaultCommandTTL
+	if ttl > 0 {
+		lifetime = time.Duration(ttl) * time.Second
+	}
+
+	c, err := s.store.dial()
+	if err != nil {
+		return 0, err
+	}
+	defer c.Close()
+
+	if _, err := readDevice(c, id); err != nil {
+		return 0, err
+	}
+	rec := commandRecord{DeviceID: id, Name: name, Status: CommandPending, CreatedAt: makeTimestamp()}
+	rec.ExpiresAt = rec.CreatedAt + int64(lifetime/time.Millisecond)
+	if len(args) > 0 {
+		b, err := json.Marshal(args)
+		if err != nil {
+			return 0, err
+		}
+		rec.Args = string(b)
+	}
+	rec.ID, err = redis.Uint64(c.Do("INCR", "id:commands"))
+	if err != nil {
+		return 0, err
+	}
+	if _, err := c.Do("HMSET", redis.Args{}.Add(commandKey(rec.ID)).AddFlat(&rec)...); err != nil {
+		return 0, err
+	}
+	if _, err := c.Do("ZADD", commandHistoryKey(id), rec.ID, rec.ID); err != nil {
+		return 0, err
+	}
+	if _, err := c.Do("ZADD", pendingCommandsKey(id), rec.ID, rec.ID); err != nil {
+		return 0, err
+	}
+	if err := trimCommandHistory(c, id); err != nil {
+		fmt.Printf("Failed to trim command history for device %d\n", id)
+		fmt.Println(err)
+	}
+
+	s.events.Publish(Event{Type: EventCommandQueued, DeviceID: id, Timestamp: rec.CreatedAt, Data: map[string]interface{}{"command_id": rec.ID, "name": name}})
+	return rec.ID, nil
+}
+
+// trimCommandHistory drops the oldest commands beyond commandHistoryLimit.
+func trimCommandHistory(c redis.Conn, deviceID uint64) error {
+	v, err := redis.Values(c.Do("ZRANGE", commandHistoryKey(deviceID), 0, -commandHistoryLimit-1))
+	if err != nil || len(v) == 0 {
+		return err
+	}
+	var ids []uint64
+	if err := redis.ScanSlice(v, &ids); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if _, err := c.Do("DEL", commandKey(id)); err != nil {
+			return err
+		}
+		if _, err := c.Do("ZREM", pendingCommandsKey(deviceID), id); err != nil {
+			return err
+		}
+	}
+	_, err = c.Do("ZREMRANGEBYRANK", commandHistoryKey(deviceID), 0, -commandHistoryLimit-1)
+	return err
+}
+
+// DeliverCommands hands a device its pending commands, oldest first, and
+// marks them delivered. Commands past their TTL are marked expired instead.
+func (s monitorService) DeliverCommands(ctx context.Context, id uint64) ([]Command, error) {
+	c, err := s.store.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	return claimCommands(c, id, makeTimestamp())
+}
+
+// claimCommands takes a device's pending commands off its queue, marking
+// them delivered or, past their TTL, expired. It returns those delivered,
+// including the ones claimed before any error.
+func claimCommands(c redis.Conn, id uint64, now int64) ([]Command, error) {
+	v, err := redis.Values(c.Do("ZRANGE", pendingCommandsKey(id), 0, -1))
+	if err != nil {
+		return nil, err
+	}
+	var ids []uint64
+	if err := redis.ScanSlice(v, &ids); err != nil {
+		return nil, err
+	}
+
+	var commands []Command
+	for _, cid := range ids {
+		// ZREM is our claim on the command: if another fetch got there
+		// first, it delivers it.
+		claimed, err := redis.Bool(c.Do("ZREM", pendingCommandsKey(id), cid))
+		if err != nil {
+			return commands, err
+		}
+		if !claimed {
+			continue
+		}
+		cmd, err := readCommand(c, cid, now)
+		if err == errCommandNotFound {
+			continue
+		}
+		if err != nil {
+			return commands, err
+		}
+		if cmd.Status == CommandExpired {
+			if _, err := c.Do("HSET", commandKey(cid), "status", CommandExpired); err != nil {
+				return commands, err
+			}
+			continue
+		}
+		cmd.Status, cmd.DeliveredAt = CommandDelivered, now
+		if _, err := c.Do("HMSET", commandKey(cid), "status", cmd.Status, "delivered_at", now); err != nil {
+			return commands, err
+		}
+		commands = append(commands, cmd)
+	}
+	return commands, nil
+}
+
+// AckCommand records the outcome a device reports for one of its commands.
+func (s monitorService) AckCommand(ctx context.Context, id, commandID uint64, success bool, result string) (bool, error) {
+	c, err := s.store.dial()
+	if err != nil {
+		return false, err
+	}
+	defer c.Close()
+
+	now := makeTimestamp()
+	cmd, err := ackCommand(c, id, commandID, success, result, now)
+	if err != nil {
+		return false, err
+	}
+	s.events.Publish(Event{Type: EventCommandCompleted, DeviceID: id, Timestamp: now, Data: cmd})
+	return true, nil
+}
+
+// ackCommand completes one of the device's commands and returns it.
+func ackCommand(c redis.Conn, id, commandID uint64, success bool, result string, now int64) (Command, error) {
+	cmd, err := readCommand(c, commandID, now)
+	if err != nil {
+		return Command{}, err
+	}
+	if cmd.DeviceID != id {
+		return Command{}, errCommandNotFound
+	}
+	switch cmd.Status {
+	case CommandSucceeded, CommandFailed, CommandExpired:
+		return Command{}, fmt.Errorf("%v: %s", errCommandDone, cmd.Status)
+	}
+
+	cmd.Status, cmd.CompletedAt, cmd.Result = CommandFailed, now, result
+	if success {
+		cmd.Status = CommandSucceeded
+	}
+	if _, err := c.Do("HMSET", commandKey(commandID), "status", cmd.Status, "completed_at", now, "result", result); err != nil {
+		return Command{}, err
+	}
+	if _, err := c.Do("ZREM", pendingCommandsKey(id), commandID); err != nil {
+		return Command{}, err
+	}
+	return cmd, nil
+}
+
+// CommandHistory returns up to limit of a device's commands, newest first.
+func (s monitorService) CommandHistory(ctx context.Context, id uint64, limit int) ([]Command, error) {
+	if limit <= 0 || limit > commandHistoryLimit {
+		limit = commandHistoryLimit
+	}
+	c, err := s.store.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	if _, err := readDevice(c, id); err != nil {
+		return nil, err
+	}
+	v, err := redis.Values(c.Do("ZREVRANGE", commandHistoryKey(id), 0, limit-1))
+	if err != nil {
+		return nil, err
+	}
+	var ids []uint64
+	if err := redis.ScanSlice(v, &ids); err != nil {
+		return nil, err
+	}
+	now := makeTimestamp()
+	commands := make([]Command, 0, len(ids))
+	for _, cid := range ids {
+		cmd, err := readCommand(c, cid, now)
+		if err == errCommandNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		commands = append(commands, cmd)
+	}
+	return commands, nil
+}
@@ -0,0 +1,168 @@
+package iotmonitor
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+// inboxService hands out one pending command, counting the deliveries.
+type inboxService struct {
+	Service
+	delivered int
+}
+
+func (s *inboxService) UpdateStatus(ctx context.Context, id uint64, lat, long, alt float32, battery uint32) (bool, error) {
+	return true, nil
+}
+
+func (s *inboxService) RelayStatus(ctx context.Context, gatewayID, id uint64, lat, long, alt float32, battery uint32) (bool, error) {
+	return true, nil
+}
+
+func (s *inboxService) DeliverDesired(ctx context.Context, id uint64) (*DesiredState, error) {
+	return nil, nil
+}
+
+func (s *inboxService) DeliverCommands(ctx context.Context, id uint64) ([]Command, error) {
+	s.delivered++
+	return []Command{{ID: 1, DeviceID: id, Name: "reboot", Status: CommandDelivered}}, nil
+}
+
+func (s *inboxService) OfferFirmware(ctx context.Context, id uint64) (*FirmwareOffer, error) {
+	return nil, nil
+}
+
+func TestUpdateEndpointDelivers(t *testing.T) {
+	for _, deliver := range []bool{false, true} {
+		srv := &inboxService{}
+		update := updateRequest{DeviceID: 7, Deliver: deliver}
+		resp, err := MakeUpdateEndpoint(srv)(context.Background(), update)
+		if err != nil {
+			t.Fatal(err)
+		}
+		relayed, err := MakeRelayStatusEndpoint(srv)(context.Background(), relayStatusRequest{GatewayID: 3, updateRequest: update})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, wantCommands := 0, 0
+		if deliver {
+			want, wantCommands = 2, 1
+		}
+		if srv.delivered != want {
+			t.Errorf("deliver %v: commands delivered %d times, want %d", deliver, srv.delivered, want)
+		}
+		if n := len(resp.(updateReply).Commands); n != wantCommands {
+			t.Errorf("deliver %v: update reply has %d commands, want %d", deliver, n, wantCommands)
+		}
+		if n := len(relayed.(relayStatusReply).Commands); n != wantCommands {
+			t.Errorf("deliver %v: relay reply has %d commands, want %d", deliver, n, wantCommands)
+		}
+	}
+}
+
+// queueCommand stores rec as EnqueueCommand does.
+func queueCommand(c redis.Conn, rec commandRecord) {
+	c.Do("HMSET", redisArgs(commandKey(rec.ID), &rec)...)
+	c.Do("ZADD", commandHistoryKey(rec.DeviceID), rec.ID, rec.ID)
+	c.Do("ZADD", pendingCommandsKey(rec.DeviceID), rec.ID, rec.ID)
+}
+
+func TestClaimCommands(t *testing.T) {
+	c := newFakeRedis()
+	queueCommand(c, commandRecord{ID: 1, DeviceID: 7, Name: "reboot", Status: CommandPending, CreatedAt: 1000, ExpiresAt: 5000})
+	queueCommand(c, commandRecord{ID: 2, DeviceID: 7, Name: "photo", Status: CommandPending, CreatedAt: 1000, ExpiresAt: 1500})
+	queueCommand(c, commandRecord{ID: 3, DeviceID: 7, Name: "goto", Args: `{"lat":51.5}`, Status: CommandPending, CreatedAt: 1100, ExpiresAt: 5000})
+	queueCommand(c, commandRecord{ID: 4, DeviceID: 8, Name: "reboot", Status: CommandPending, CreatedAt: 1000, ExpiresAt: 5000})
+
+	got, err := claimCommands(c, 7, 2000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Command{
+		{ID: 1, DeviceID: 7, Name: "reboot", Status: CommandDelivered, CreatedAt: 1000, ExpiresAt: 5000, DeliveredAt: 2000},
+		{ID: 3, DeviceID: 7, Name: "goto", Args: map[string]interface{}{"lat": 51.5}, Status: CommandDelivered, CreatedAt: 1100, ExpiresAt: 5000, DeliveredAt: 2000},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("claimCommands() = %+v, want %+v", got, want)
+	}
+	for id, status := range map[uint64]string{1: CommandDelivered, 2: CommandExpired, 3: CommandDelivered, 4: CommandPending} {
+		if cmd, err := readCommand(c, id, 2000); err != nil || cmd.Status != status {
+			t.Errorf("command %d = %+v, %v, want it %s", id, cmd, err, status)
+		}
+	}
+	if again, err := claimCommands(c, 7, 3000); err != nil || len(again) != 0 {
+		t.Errorf("second claimCommands() = %+v, %v, want nothing left", again, err)
+	}
+}
+
+func TestReadCommandExpiry(t *testing.T) {
+	c := newFakeRedis()
+	queueCommand(c, commandRecord{ID: 1, DeviceID: 7, Name: "reboot", Status: CommandPending, CreatedAt: 1000, ExpiresAt: 5000})
+	tests := []struct {
+		now  int64
+		want string
+	}{
+		{4999, CommandPending},
+		{5000, CommandPending},
+		{5001, CommandExpired},
+	}
+	for _, tt := range tests {
+		if cmd, err := readCommand(c, 1, tt.now); err != nil || cmd.Status != tt.want {
+			t.Errorf("readCommand() at %d = %s, %v, want %s", tt.now, cmd.Status, err, tt.want)
+		}
+	}
+	if _, err := readCommand(c, 2, 1000); err != errCommandNotFound {
+		t.Errorf("readCommand() of a missing command = %v, want %v", err, errCommandNotFound)
+	}
+}
+
+func TestAckCommand(t *testing.T) {
+	c := newFakeRedis()
+	queueCommand(c, commandRecord{ID: 1, DeviceID: 7, Name: "reboot", Status: CommandPending, CreatedAt: 1000, ExpiresAt: 5000})
+	queueCommand(c, commandRecord{ID: 2, DeviceID: 7, Name: "photo", Status: CommandPending, CreatedAt: 1000, ExpiresAt: 1500})
+	queueCommand(c, commandRecord{ID: 3, DeviceID: 7, Name: "goto", Status: CommandPending, CreatedAt: 1000, ExpiresAt: 5000})
+	if _, err := claimCommands(c, 7, 1200); err != nil {
+		t.Fatal(err)
+	}
+
+	// An undelivered command can be acknowledged too, which takes it off
+	// the queue.
+	queueCommand(c, commandRecord{ID: 4, DeviceID: 7, Name: "land", Status: CommandPending, CreatedAt: 1300, ExpiresAt: 5000})
+	tests := []struct {
+		name    string
+		device  uint64
+		command uint64
+		success bool
+		want    string
+		wantErr error
+	}{
+		{"success", 7, 1, true, CommandSucceeded, nil},
+		{"twice", 7, 1, false, "", errCommandDone},
+		{"failure", 7, 3, false, CommandFailed, nil},
+		{"other device", 8, 4, true, "", errCommandNotFound},
+		{"undelivered", 7, 4, true, CommandSucceeded, nil},
+		{"missing", 7, 5, true, "", errCommandNotFound},
+	}
+	for _, tt := range tests {
+		cmd, err := ackCommand(c, tt.device, tt.command, tt.success, "result", 2000)
+		if tt.wantErr != nil {
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr.Error()) {
+				t.Errorf("%s: ackCommand() = %v, want %v", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || cmd.Status != tt.want || cmd.CompletedAt != 2000 || cmd.Result != "result" {
+			t.Errorf("%s: ackCommand() = %+v, %v, want it %s", tt.name, cmd, err, tt.want)
+		}
+		if stored, _ := readCommand(c, tt.command, 2000); stored.Status != tt.want {
+			t.Errorf("%s: stored status %s, want %s", tt.name, stored.Status, tt.want)
+		}
+	}
+	if pending, _ := redis.Values(c.Do("ZRANGE", pendingCommandsKey(7), 0, -1)); len(pending) != 0 {
+		t.Errorf("pending commands = %v, want none", pending)
+	}
+}
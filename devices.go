@@ -0,0 +1,322 @@
+package iotmonitor
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/garyburd/redigo/redis"
+	"golang.org/x/net/context"
+)
+
+var errGatewayNotEmpty = errors.New("gateway still has child devices")
+
+// Device is a registered device along with its connectivity state.
+type Device struct {
+	ID              uint64 `json:"id"`
+	Name            string `json:"name"`
+	Owner           string `json:"owner"`
+	DeviceType      string `json:"device_type"`
+	GatewayID       uint64 `json:"gateway_id,omitempty"`
+	FirmwareVersion string `json:"firmware_version,omitempty"`
+
+	Online   bool  `json:"online"`
+	LastSeen int64 `json:"last_seen,omitempty"`
+	// Unreachable is set while the device's gateway is offline.
+	Unreachable bool `json:"unreachable,omitempty"`
+
+	Labels  map[string]string `json:"labels,omitempty"`
+	Battery *BatteryEstimate  `json:"battery,omitempty"`
+}
+
+// DeviceFilter selects devices for a query. Empty fields match every device.
+// Selector is a Kubernetes-style label selector, e.g. "site=plant-3,env!=test".
+type DeviceFilter struct {
+	DeviceType string   `json:"device_type,omitempty"`
+	Owner      string   `json:"owner,omitempty"`
+	DeviceIDs  []uint64 `json:"device_ids,omitempty"`
+	Selector   string   `json:"selector,omitempty"`
+	Group      uint64   `json:"group,omitempty"` // includes the group's descendants
+}
+
+func (f DeviceFilter) empty() bool {
+	return f.DeviceType == "" && f.Owner == "" && len(f.DeviceIDs) == 0 && f.Selector == "" && f.Group == 0
+}
+
+// deviceMatcher is a DeviceFilter with its selector parsed and its group
+// expanded to the devices beneath it.
+type deviceMatcher struct {
+	DeviceFilter
+	selector labelSelector
+	group    map[uint64]bool
+}
+
+func (f DeviceFilter) matcher(c redis.Conn) (deviceMatcher, error) {
+	m := deviceMatcher{DeviceFilter: f}
+	var err error
+	if m.selector, err = parseSelector(f.Selector); err != nil {
+		return m, err
+	}
+	if f.Group != 0 {
+		ids, err := devicesUnder(c, f.Group)
+		if err != nil {
+			return m, err
+		}
+		m.group = make(map[uint64]bool, len(ids))
+		for _, id := range ids {
+			m.group[id] = true
+		}
+	}
+	return m, nil
+}
+
+func (m deviceMatcher) matches(d Device) bool {
+	if m.DeviceType != "" && m.DeviceType != d.DeviceType {
+		return false
+	}
+	if m.Owner != "" && m.Owner != d.Owner {
+		return false
+	}
+	if !m.selector.matches(d.Labels) {
+		return false
+	}
+	if m.group != nil && !m.group[d.ID] {
+		return false
+	}
+	if len(m.DeviceIDs) == 0 {
+		return true
+	}
+	for _, id := range m.DeviceIDs {
+		if id == d.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// candidates returns the IDs of the devices that may match, using the label
+// indexes where the selector allows.
+func (m deviceMatcher) candidates(c redis.Conn) ([]uint64, error) {
+	if len(m.DeviceIDs) > 0 {
+		return m.DeviceIDs, nil
+	}
+	if m.group != nil {
+		ids := make([]uint64, 0, len(m.group))
+		for id := range m.group {
+			ids = append(ids, id)
+		}
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+		return ids, nil
+	}
+	ids, indexed, err := m.selector.candidates(c)
+	if indexed || err != nil {
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+		return ids, err
+	}
+	all, err := redis.Values(c.Do("SORT", "devices"))
+	if err != nil {
+		return nil, err
+	}
+	err = redis.ScanSlice(all, &ids)
+	return ids, err
+}
+
+func readDeviceSummary(c redis.Conn, id uint64) (Device, error) {
+	rec, err := readDevice(c, id)
+	if err != nil {
+		return Device{}, err
+	}
+	labels, err := readLabels(c, id)
+	return rec.device(labels), err
+}
+
+// selectDevices returns the registered devices the filter matches, without
+// their connectivity or battery state.
+func selectDevices(c redis.Conn, f DeviceFilter) ([]Device, error) {
+	m, err := f.matcher(c)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := m.candidates(c)
+	if err != nil {
+		return nil, err
+	}
+	var devices []Device
+	for _, id := range ids {
+		d, err := readDeviceSummary(c, id)
+		if err == errDeviceNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if m.matches(d) {
+			devices = append(devices, d)
+		}
+	}
+	return devices, nil
+}
+
+func loadDevice(c redis.Conn, id uint64) (Device, error) {
+	d, err := readDeviceSummary(c, id)
+	if err != nil {
+		return d, err
+	}
+	d.Online, d.LastSeen, err = readConnectivity(c, id)
+	if err != nil {
+		return d, err
+	}
+	d.Unreachable, err = redis.Bool(c.Do("SISMEMBER", unreachableKey, id))
+	if err != nil {
+		return d, err
+	}
+	d.Battery, err = loadBatteryEstimate(c, id, makeTimestamp())
+	return d, err
+}
+
+func (s monitorService) GetDevice(ctx context.Context, id uint64) (Device, error) {
+	c, err := s.store.dial()
+	if err != nil {
+		return Device{}, err
+	}
+	defer c.Close()
+	return loadDevice(c, id)
+}
+
+func (s monitorService) ListDevices(ctx context.Context, filter DeviceFilter) ([]Device, error) {
+	c, err := s.store.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	selected, err := selectDevices(c, filter)
+	if err != nil {
+		return nil, err
+	}
+	devices := make([]Device, 0, len(selected))
+	for _, d := range selected {
+		d, err := loadDevice(c, d.ID)
+		if err == errDeviceNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		devices = append(devices, d)
+	}
+	return devices, nil
+}
+
+// UpdateDevice renames a device or changes its owner. Empty fields are left
+// as they are; labels are changed with SetLabels.
+func (s monitorService) UpdateDevice(ctx context.Context, id uint64, name, owner string) (bool, error) {
+	c, err := s.store.dial()
+	if err != nil {
+		return false, err
+	}
+	defer c.Close()
+
+	d, err := readDeviceSummary(c, id)
+	if err != nil {
+		return false, err
+	}
+	if name = strings.TrimSpace(name); name != "" {
+		d.Name = name
+	}
+	if owner != "" {
+		d.Owner = owner
+	}
+	if _, err := c.Do("HMSET", fmt.Sprintf("device:%d", id), "name", d.Name, "owner", d.Owner); err != nil {
+		return false, err
+	}
+	s.events.Publish(Event{Type: EventDeviceUpdated, DeviceID: id, Data: d})
+	return true, nil
+}
+
+// DeleteDevice removes a device from the registry along with its state,
+// telemetry, commands and alerts. A gateway can only be deleted once it has
+// no child devices.
+func (s monitorService) DeleteDevice(ctx context.Context, id uint64) (bool, error) {
+	c, err := s.store.dial()
+	if err != nil {
+		return false, err
+	}
+	defer c.Close()
+
+	d, err := readDeviceSummary(c, id)
+	if err != nil {
+		return false, err
+	}
+	children, err := redis.Int(c.Do("SCARD", gatewayChildrenKey(id)))
+	if err != nil {
+		return false, err
+	}
+	if children > 0 {
+		return false, errGatewayNotEmpty
+	}
+	if d.GatewayID != 0 {
+		if _, err := c.Do("SREM", gatewayChildrenKey(d.GatewayID), id); err != nil {
+			return false, err
+		}
+	}
+
+	if err := writeLabels(c, id, nil); err != nil {
+		return false, err
+	}
+	groups, err := redis.Values(c.Do("SMEMBERS", "groups"))
+	if err != nil {
+		return false, err
+	}
+	var groupIDs []uint64
+	if err := redis.ScanSlice(groups, &groupIDs); err != nil {
+		return false, err
+	}
+	for _, g := range groupIDs {
+		if _, err := c.Do("SREM", groupDevicesKey(g), id); err != nil {
+			return false, err
+		}
+	}
+	for _, key := range []string{"devices", onlineKey, unreachableKey} {
+		if _, err := c.Do("SREM", key, id); err != nil {
+			return false, err
+		}
+	}
+	for _, key := range []string{lastSeenKey, locationsKey} {
+		if _, err := c.Do("ZREM", key, id); err != nil {
+			return false, err
+		}
+	}
+
+	if err := deleteCommands(c, id); err != nil {
+		return false, err
+	}
+	if err := deleteTelemetry(c, id); err != nil {
+		return false, err
+	}
+	alerts, err := clearDeviceAlerts(c, id, makeTimestamp())
+	for _, a := range alerts {
+		s.events.Publish(alertEvent(a))
+	}
+	if err != nil {
+		return false, err
+	}
+	_, err = c.Do("DEL",
+		fmt.Sprintf("device:%d", id),
+		fmt.Sprintf("status:%d", id),
+		fmt.Sprintf("telemetry:%d", id),
+		telemetryTimesKey(id),
+		gatewayChildrenKey(id),
+		motionKey(id),
+		batteryKey(id),
+		twinKey(id),
+		geofenceInsideKey(id),
+	)
+	if err != nil {
+		return false, err
+	}
+
+	s.events.Publish(Event{Type: EventDeviceDeleted, DeviceID: id, Data: d})
+	return true, nil
+}
@@ -1,12 +1,18 @@
 package iotmonitor
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
+	"net/url"
 
 	"strconv"
+	"strings"
 
 	"github.com/autodidaddict/iotmonitor/pb"
 	"github.com/gorilla/mux"
@@ -17,6 +23,9 @@ type registerRequest struct {
 	SerialNumber string `json:"serial_number"`
 	Owner        string `json:"owner"`
 	DeviceType   string `json:"device_type"`
+	GatewayID    uint64 `json:"gateway_id,omitempty"`
+
+	Labels map[string]string `json:"labels,omitempty"`
 }
 
 type registerReply struct {
@@ -25,10 +34,15 @@ type registerReply struct {
 	Err        string `json:"err,omitempty"`
 }
 
+// updateRequest is a status update. Deliver asks for the device's inbox in
+// the reply; what it hands over is delivered once, so only the device itself
+// sets it.
 type updateRequest struct {
-	DeviceID         uint64   `json:"device_id"`
-	Location         location `json:"location"`
-	BatteryRemaining uint32   `json:"battery_remaining"`
+	DeviceID         uint64          `json:"device_id"`
+	Location         location        `json:"location"`
+	BatteryRemaining uint32          `json:"battery_remaining"`
+	Firmware         *FirmwareReport `json:"firmware,omitempty"`
+	Deliver          bool            `json:"deliver,omitempty"`
 }
 
 type location struct {
@@ -38,152 +52,4494 @@ type location struct {
 }
 
 type updateReply struct {
-	Acknowledged bool   `json:"acknowledged"`
-	Err          string `json:"err,omitempty"`
+	Acknowledged bool `json:"acknowledged"`
+	deviceInbox
+	Err string `json:"err,omitempty"`
+}
+
+// deviceInbox is what the server hands a device in reply to a status update.
+type deviceInbox struct {
+	Desired  *DesiredState  `json:"desired,omitempty"`
+	Commands []Command      `json:"commands,omitempty"`
+	Firmware *FirmwareOffer `json:"firmware,omitempty"`
 }
 
+// telemetryRequest carries typed Values. Readings holds the plain float
+// readings sent by clients that predate typed values.
 type telemetryRequest struct {
-	DeviceID uint64             `json:"device_id"`
-	Readings map[string]float32 `json:"readings"`
+	DeviceID uint64                    `json:"device_id"`
+	Readings map[string]float32        `json:"readings,omitempty"`
+	Values   map[string]TelemetryValue `json:"values,omitempty"`
+}
+
+// values merges the float readings into the typed values. A metric sent
+// both ways keeps its typed value.
+func (r telemetryRequest) values() map[string]TelemetryValue {
+	if len(r.Readings) == 0 {
+		return r.Values
+	}
+	values := make(map[string]TelemetryValue, len(r.Readings)+len(r.Values))
+	for name, v := range r.Readings {
+		values[name] = legacyValue(v)
+	}
+	for name, v := range r.Values {
+		values[name] = v
+	}
+	return values
 }
 
 type telemetryReply struct {
+	Acknowledged bool              `json:"acknowledged"`
+	Violations   []SchemaViolation `json:"violations,omitempty"`
+	Err          string            `json:"err,omitempty"`
+}
+
+// relayStatusRequest is a status update a gateway submits for a child.
+type relayStatusRequest struct {
+	GatewayID uint64 `json:"gateway_id"`
+	updateRequest
+}
+
+type relayStatusReply struct {
+	Acknowledged bool `json:"acknowledged"`
+	deviceInbox
+	Err string `json:"err,omitempty"`
+}
+
+// relayTelemetryRequest is telemetry a gateway submits for a child.
+type relayTelemetryRequest struct {
+	GatewayID uint64 `json:"gateway_id"`
+	telemetryRequest
+}
+
+type relayTelemetryReply struct {
+	Acknowledged bool              `json:"acknowledged"`
+	Violations   []SchemaViolation `json:"violations,omitempty"`
+	Err          string            `json:"err,omitempty"`
+}
+
+type getTopologyRequest struct {
+	DeviceID uint64 `json:"device_id"`
+}
+
+type getTopologyReply struct {
+	Topology TopologyNode `json:"topology"`
+	Err      string       `json:"err,omitempty"`
+}
+
+type getDeviceRequest struct {
+	DeviceID uint64 `json:"device_id"`
+}
+
+type getDeviceReply struct {
+	Device Device `json:"device"`
+	Err    string `json:"err,omitempty"`
+}
+
+type getStatusRequest struct {
+	DeviceID uint64 `json:"device_id"`
+}
+
+type getStatusReply struct {
+	Status DeviceStatus `json:"status"`
+	Err    string       `json:"err,omitempty"`
+}
+
+type batteryHistoryRequest struct {
+	DeviceID uint64 `json:"device_id"`
+	Since    int64  `json:"since"`
+}
+
+type batteryHistoryReply struct {
+	Samples []BatterySample `json:"samples"`
+	Err     string          `json:"err,omitempty"`
+}
+
+type queryTelemetryRequest struct {
+	DeviceID uint64 `json:"device_id"`
+	Metric   string `json:"metric"`
+	From     int64  `json:"from"`
+	To       int64  `json:"to"`
+}
+
+type queryTelemetryReply struct {
+	Series TelemetrySeries `json:"series"`
+	Err    string          `json:"err,omitempty"`
+}
+
+type aggregateTelemetryRequest struct {
+	Query FleetQuery `json:"query"`
+}
+
+type aggregateTelemetryReply struct {
+	Aggregate FleetAggregate `json:"aggregate"`
+	Err       string         `json:"err,omitempty"`
+}
+
+type updateDeviceRequest struct {
+	DeviceID uint64 `json:"device_id"`
+	Name     string `json:"name,omitempty"`
+	Owner    string `json:"owner,omitempty"`
+}
+
+type updateDeviceReply struct {
 	Acknowledged bool   `json:"acknowledged"`
 	Err          string `json:"err,omitempty"`
 }
 
-var errBadRoute = errors.New("bad route")
+type deleteDeviceRequest struct {
+	DeviceID uint64 `json:"device_id"`
+}
 
-func decodeRegisterRequest(ctx context.Context, r *http.Request) (interface{}, error) {
-	var req registerRequest
-	err := json.NewDecoder(r.Body).Decode(&req)
-	if err != nil {
-		return nil, err
-	}
+type deleteDeviceReply struct {
+	Acknowledged bool   `json:"acknowledged"`
+	Err          string `json:"err,omitempty"`
+}
 
-	return req, nil
+type listDevicesRequest struct {
+	Filter DeviceFilter `json:"filter"`
 }
 
-func decodeUpdateRequest(ctx context.Context, r *http.Request) (interface{}, error) {
-	vars := mux.Vars(r)
-	id, ok := vars["id"]
-	if !ok {
-		return nil, errBadRoute
-	}
+type setLabelsRequest struct {
+	DeviceID uint64            `json:"device_id"`
+	Labels   map[string]string `json:"labels"`
+}
 
-	var req updateRequest
-	err := json.NewDecoder(r.Body).Decode(&req)
-	if err != nil {
-		return nil, err
-	}
-	realID, err := strconv.ParseUint(id, 10, 64)
-	if err != nil {
-		return nil, err
-	}
-	req.DeviceID = realID
-	return req, nil
+type setLabelsReply struct {
+	Acknowledged bool   `json:"acknowledged"`
+	Err          string `json:"err,omitempty"`
 }
 
-func decodeTelemetryRequest(ctx context.Context, r *http.Request) (interface{}, error) {
-	vars := mux.Vars(r)
-	id, ok := vars["id"]
-	if !ok {
-		return nil, errBadRoute
-	}
+type listDevicesReply struct {
+	Devices []Device `json:"devices"`
+	Err     string   `json:"err,omitempty"`
+}
 
-	var req telemetryRequest
-	err := json.NewDecoder(r.Body).Decode(&req)
-	if err != nil {
-		return nil, err
-	}
-	realID, err := strconv.ParseUint(id, 10, 64)
-	if err != nil {
-		return nil, err
-	}
-	req.DeviceID = realID
+type createRuleRequest struct {
+	Rule Rule `json:"rule"`
+}
 
-	return req, nil
+type createRuleReply struct {
+	RuleID uint64 `json:"rule_id"`
+	Err    string `json:"err,omitempty"`
 }
 
-func encodeResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
-	return json.NewEncoder(w).Encode(response)
+type getRuleRequest struct {
+	RuleID uint64 `json:"rule_id"`
 }
 
-// GRPC Encode -> to protobuf
-// GRPC Decode -> from protobuf
+type getRuleReply struct {
+	Rule Rule   `json:"rule"`
+	Err  string `json:"err,omitempty"`
+}
 
-func EncodeGRPCRegisterRequest(ctx context.Context, r interface{}) (interface{}, error) {
-	req := r.(registerRequest)
-	dt := pb.DeviceType_DRONE
-	if req.DeviceType == "Sensor" {
-		dt = pb.DeviceType_DRONE
-	}
-	return &pb.RegisterDeviceRequest{Devicetype: dt, Name: req.Name, Owner: req.Owner, Serialnumber: req.SerialNumber}, nil
+type updateRuleRequest struct {
+	Rule Rule `json:"rule"`
 }
 
-func DecodeGRPCRegisterRequest(ctx context.Context, r interface{}) (interface{}, error) {
-	req := r.(*pb.RegisterDeviceRequest)
-	dt := "Sensor"
-	if req.Devicetype == pb.DeviceType_DRONE {
-		dt = "Drone"
-	}
-	return registerRequest{DeviceType: dt, Name: req.Name, Owner: req.Owner, SerialNumber: req.Serialnumber}, nil
+type updateRuleReply struct {
+	Acknowledged bool   `json:"acknowledged"`
+	Err          string `json:"err,omitempty"`
 }
 
-func EncodeGRPCRegisterResponse(ctx context.Context, r interface{}) (interface{}, error) {
-	res := r.(registerReply)
-	return &pb.RegisterDeviceReply{Deviceid: res.DeviceID, Registered: res.Registered, Err: res.Err}, nil
+type deleteRuleRequest struct {
+	RuleID uint64 `json:"rule_id"`
 }
 
-func DecodeGRPCRegisterResponse(ctx context.Context, r interface{}) (interface{}, error) {
-	res := r.(*pb.RegisterDeviceReply)
-	return registerReply{DeviceID: res.Deviceid, Registered: res.Registered, Err: res.Err}, nil
+type deleteRuleReply struct {
+	Acknowledged bool   `json:"acknowledged"`
+	Err          string `json:"err,omitempty"`
 }
 
-func EncodeGRPCUpdateRequest(ctx context.Context, r interface{}) (interface{}, error) {
-	req := r.(updateRequest)
-	return &pb.StatusUpdateRequest{Batteryremaining: req.BatteryRemaining, Deviceid: req.DeviceID, Location: &pb.Location{
-		Altitude:  req.Location.Altitude,
-		Longitude: req.Location.Longitude,
-		Latitude:  req.Location.Latitude,
-	}}, nil
+type listRulesRequest struct{}
+
+type listRulesReply struct {
+	Rules []Rule `json:"rules"`
+	Err   string `json:"err,omitempty"`
 }
 
-func DecodeGRPCUpdateRequest(ctx context.Context, r interface{}) (interface{}, error) {
-	req := r.(*pb.StatusUpdateRequest)
-	return updateRequest{BatteryRemaining: req.Batteryremaining, DeviceID: req.Deviceid, Location: location{
-		Altitude:  req.Location.Altitude,
-		Longitude: req.Location.Longitude,
-		Latitude:  req.Location.Latitude,
-	}}, nil
+type activeAlertsRequest struct {
+	Filter DeviceFilter `json:"filter"`
 }
 
-func EncodeGRPCUpdateResponse(ctx context.Context, r interface{}) (interface{}, error) {
-	res := r.(updateReply)
-	return &pb.StatusUpdateReply{Acknowledged: res.Acknowledged, Err: res.Err}, nil
+type activeAlertsReply struct {
+	Alerts []Alert `json:"alerts"`
+	Err    string  `json:"err,omitempty"`
 }
 
-func DecodeGRPCUpdateResponse(ctx context.Context, r interface{}) (interface{}, error) {
-	res := r.(*pb.StatusUpdateReply)
-	return updateReply{Acknowledged: res.Acknowledged, Err: res.Err}, nil
+type createWebhookRequest struct {
+	Webhook Webhook `json:"webhook"`
 }
 
-func EncodeGRPCTelemetryRequest(ctx context.Context, r interface{}) (interface{}, error) {
-	req := r.(telemetryRequest)
-	return &pb.TelemetrySubmitRequest{Deviceid: req.DeviceID, Readings: req.Readings}, nil
+type createWebhookReply struct {
+	WebhookID uint64 `json:"webhook_id"`
+	Err       string `json:"err,omitempty"`
 }
 
-func DecodeGRPCTelemetryRequest(ctx context.Context, r interface{}) (interface{}, error) {
-	req := r.(*pb.TelemetrySubmitRequest)
-	return telemetryRequest{DeviceID: req.Deviceid, Readings: req.Readings}, nil
+type listWebhooksRequest struct{}
+
+type listWebhooksReply struct {
+	Webhooks []Webhook `json:"webhooks"`
+	Err      string    `json:"err,omitempty"`
 }
 
-func EncodeGRPCTelemetryResponse(ctx context.Context, r interface{}) (interface{}, error) {
-	res := r.(telemetryReply)
-	return &pb.TelemetrySubmitReply{Acknowledged: res.Acknowledged, Err: res.Err}, nil
+type deleteWebhookRequest struct {
+	WebhookID uint64 `json:"webhook_id"`
 }
 
-func DecodeGRPCTelemetryResponse(ctx context.Context, r interface{}) (interface{}, error) {
-	res := r.(*pb.TelemetrySubmitReply)
-	return telemetryReply{Acknowledged: res.Acknowledged, Err: res.Err}, nil
+type deleteWebhookReply struct {
+	Acknowledged bool   `json:"acknowledged"`
+	Err          string `json:"err,omitempty"`
+}
+
+type deadLettersRequest struct {
+	Limit int `json:"limit"`
+}
+
+type deadLettersReply struct {
+	DeadLetters []DeadLetter `json:"dead_letters"`
+	Err         string       `json:"err,omitempty"`
+}
+
+type createGeofenceRequest struct {
+	Geofence Geofence `json:"geofence"`
+}
+
+type createGeofenceReply struct {
+	GeofenceID uint64 `json:"geofence_id"`
+	Err        string `json:"err,omitempty"`
+}
+
+type updateGeofenceRequest struct {
+	Geofence Geofence `json:"geofence"`
+}
+
+type updateGeofenceReply struct {
+	Acknowledged bool   `json:"acknowledged"`
+	Err          string `json:"err,omitempty"`
+}
+
+type deleteGeofenceRequest struct {
+	GeofenceID uint64 `json:"geofence_id"`
+}
+
+type deleteGeofenceReply struct {
+	Acknowledged bool   `json:"acknowledged"`
+	Err          string `json:"err,omitempty"`
+}
+
+type listGeofencesRequest struct{}
+
+type listGeofencesReply struct {
+	Geofences []Geofence `json:"geofences"`
+	Err       string     `json:"err,omitempty"`
+}
+
+type nearbyDevicesRequest struct {
+	Latitude  float64      `json:"latitude"`
+	Longitude float64      `json:"longitude"`
+	Radius    float64      `json:"radius"`
+	Filter    DeviceFilter `json:"filter"`
+}
+
+type nearbyDevicesReply struct {
+	Devices []DeviceLocation `json:"devices"`
+	Err     string           `json:"err,omitempty"`
+}
+
+type devicesInBoxRequest struct {
+	Box    BoundingBox  `json:"box"`
+	Filter DeviceFilter `json:"filter"`
+}
+
+type devicesInBoxReply struct {
+	Devices []DeviceLocation `json:"devices"`
+	Err     string           `json:"err,omitempty"`
+}
+
+type listStatusesRequest struct {
+	Filter DeviceFilter `json:"filter"`
+}
+
+type listStatusesReply struct {
+	Statuses []DeviceStatus `json:"statuses"`
+	Err      string         `json:"err,omitempty"`
+}
+
+type createGroupRequest struct {
+	Group Group `json:"group"`
+}
+
+type createGroupReply struct {
+	GroupID uint64 `json:"group_id"`
+	Err     string `json:"err,omitempty"`
+}
+
+type updateGroupRequest struct {
+	Group Group `json:"group"`
+}
+
+type updateGroupReply struct {
+	Acknowledged bool   `json:"acknowledged"`
+	Err          string `json:"err,omitempty"`
+}
+
+type deleteGroupRequest struct {
+	GroupID uint64 `json:"group_id"`
+}
+
+type deleteGroupReply struct {
+	Acknowledged bool   `json:"acknowledged"`
+	Err          string `json:"err,omitempty"`
+}
+
+type listGroupsRequest struct{}
+
+type listGroupsReply struct {
+	Groups []Group `json:"groups"`
+	Err    string  `json:"err,omitempty"`
+}
+
+type addGroupMembersRequest struct {
+	GroupID   uint64   `json:"group_id"`
+	DeviceIDs []uint64 `json:"device_ids"`
+}
+
+type addGroupMembersReply struct {
+	Acknowledged bool   `json:"acknowledged"`
+	Err          string `json:"err,omitempty"`
+}
+
+type removeGroupMembersRequest struct {
+	GroupID   uint64   `json:"group_id"`
+	DeviceIDs []uint64 `json:"device_ids"`
+}
+
+type removeGroupMembersReply struct {
+	Acknowledged bool   `json:"acknowledged"`
+	Err          string `json:"err,omitempty"`
+}
+
+type getTwinRequest struct {
+	DeviceID uint64 `json:"device_id"`
+}
+
+type getTwinReply struct {
+	Twin Twin   `json:"twin"`
+	Err  string `json:"err,omitempty"`
+}
+
+type updateDesiredRequest struct {
+	DeviceID uint64       `json:"device_id"`
+	Patch    TwinDocument `json:"patch"`
+	Version  int64        `json:"version,omitempty"`
+}
+
+type updateDesiredReply struct {
+	Twin Twin   `json:"twin"`
+	Err  string `json:"err,omitempty"`
+}
+
+type reportStateRequest struct {
+	DeviceID uint64       `json:"device_id"`
+	Patch    TwinDocument `json:"patch"`
+}
+
+type reportStateReply struct {
+	Twin Twin   `json:"twin"`
+	Err  string `json:"err,omitempty"`
+}
+
+type deliverDesiredRequest struct {
+	DeviceID uint64 `json:"device_id"`
+}
+
+type deliverDesiredReply struct {
+	Desired *DesiredState `json:"desired,omitempty"`
+	Err     string        `json:"err,omitempty"`
+}
+
+type enqueueCommandRequest struct {
+	DeviceID uint64                 `json:"device_id"`
+	Name     string                 `json:"name"`
+	Args     map[string]interface{} `json:"args,omitempty"`
+	TTL      int64                  `json:"ttl,omitempty"` // seconds
+}
+
+type enqueueCommandReply struct {
+	CommandID uint64 `json:"command_id"`
+	Err       string `json:"err,omitempty"`
+}
+
+type deliverCommandsRequest struct {
+	DeviceID uint64 `json:"device_id"`
+}
+
+type deliverCommandsReply struct {
+	Commands []Command `json:"commands"`
+	Err      string    `json:"err,omitempty"`
+}
+
+type ackCommandRequest struct {
+	DeviceID  uint64 `json:"device_id"`
+	CommandID uint64 `json:"command_id"`
+	Success   bool   `json:"success"`
+	Result    string `json:"result,omitempty"`
+}
+
+type ackCommandReply struct {
+	Acknowledged bool   `json:"acknowledged"`
+	Err          string `json:"err,omitempty"`
+}
+
+type commandHistoryRequest struct {
+	DeviceID uint64 `json:"device_id"`
+	Limit    int    `json:"limit"`
+}
+
+type commandHistoryReply struct {
+	Commands []Command `json:"commands"`
+	Err      string    `json:"err,omitempty"`
+}
+
+type uploadFirmwareRequest struct {
+	DeviceType string `json:"device_type"`
+	Version    string `json:"version"`
+	Data       []byte `json:"data"`
+}
+
+type uploadFirmwareReply struct {
+	Artifact FirmwareArtifact `json:"artifact"`
+	Err      string           `json:"err,omitempty"`
+}
+
+type listFirmwareRequest struct{}
+
+type listFirmwareReply struct {
+	Artifacts []FirmwareArtifact `json:"artifacts"`
+	Err       string             `json:"err,omitempty"`
+}
+
+type downloadFirmwareRequest struct {
+	FirmwareID uint64 `json:"firmware_id"`
+}
+
+type downloadFirmwareReply struct {
+	Image FirmwareImage `json:"image"`
+	Err   string        `json:"err,omitempty"`
+}
+
+type createCampaignRequest struct {
+	Campaign Campaign `json:"campaign"`
+}
+
+type createCampaignReply struct {
+	CampaignID uint64 `json:"campaign_id"`
+	Err        string `json:"err,omitempty"`
+}
+
+type getCampaignRequest struct {
+	CampaignID uint64 `json:"campaign_id"`
+}
+
+type getCampaignReply struct {
+	Campaign Campaign `json:"campaign"`
+	Err      string   `json:"err,omitempty"`
+}
+
+type listCampaignsRequest struct{}
+
+type listCampaignsReply struct {
+	Campaigns []Campaign `json:"campaigns"`
+	Err       string     `json:"err,omitempty"`
+}
+
+type controlCampaignRequest struct {
+	CampaignID uint64 `json:"campaign_id"`
+	Action     string `json:"action"`
+}
+
+type controlCampaignReply struct {
+	Campaign Campaign `json:"campaign"`
+	Err      string   `json:"err,omitempty"`
+}
+
+type reportFirmwareRequest struct {
+	DeviceID uint64         `json:"device_id"`
+	Report   FirmwareReport `json:"report"`
+}
+
+type reportFirmwareReply struct {
+	Acknowledged bool   `json:"acknowledged"`
+	Err          string `json:"err,omitempty"`
+}
+
+type offerFirmwareRequest struct {
+	DeviceID uint64 `json:"device_id"`
+}
+
+type offerFirmwareReply struct {
+	Offer *FirmwareOffer `json:"offer,omitempty"`
+	Err   string         `json:"err,omitempty"`
+}
+
+type setTelemetrySchemaRequest struct {
+	Schema TelemetrySchema `json:"schema"`
+}
+
+type setTelemetrySchemaReply struct {
+	Acknowledged bool   `json:"acknowledged"`
+	Err          string `json:"err,omitempty"`
+}
+
+type getTelemetrySchemaRequest struct {
+	DeviceType string `json:"device_type"`
+}
+
+type getTelemetrySchemaReply struct {
+	Schema TelemetrySchema `json:"schema"`
+	Err    string          `json:"err,omitempty"`
+}
+
+type listTelemetrySchemasRequest struct{}
+
+type listTelemetrySchemasReply struct {
+	Schemas []TelemetrySchema `json:"schemas"`
+	Err     string            `json:"err,omitempty"`
+}
+
+type deleteTelemetrySchemaRequest struct {
+	DeviceType string `json:"device_type"`
+}
+
+type deleteTelemetrySchemaReply struct {
+	Acknowledged bool   `json:"acknowledged"`
+	Err          string `json:"err,omitempty"`
+}
+
+type importDevicesRequest struct {
+	Devices []DeviceImport `json:"devices"`
+	DryRun  bool           `json:"dry_run"`
+}
+
+type importDevicesReply struct {
+	Results []ImportResult `json:"results"`
+	Err     string         `json:"err,omitempty"`
+}
+
+// exportDevicesRequest carries the file format the HTTP transport should
+// write; the service itself doesn't use it.
+type exportDevicesRequest struct {
+	Filter DeviceFilter `json:"filter"`
+	Format string       `json:"format,omitempty"`
+}
+
+type exportDevicesReply struct {
+	Devices []DeviceExport `json:"devices"`
+	Format  string         `json:"-"`
+	Err     string         `json:"err,omitempty"`
+}
+
+var errBadRoute = errors.New("bad route")
+
+func routeID(r *http.Request) (uint64, error) {
+	id, ok := mux.Vars(r)["id"]
+	if !ok {
+		return 0, errBadRoute
+	}
+	return strconv.ParseUint(id, 10, 64)
+}
+
+func decodeRegisterRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req registerRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+func decodeUpdateRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	vars := mux.Vars(r)
+	id, ok := vars["id"]
+	if !ok {
+		return nil, errBadRoute
+	}
+
+	var req updateRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return nil, err
+	}
+	realID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	req.DeviceID = realID
+	return req, nil
+}
+
+func decodeTelemetryRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	vars := mux.Vars(r)
+	id, ok := vars["id"]
+	if !ok {
+		return nil, errBadRoute
+	}
+
+	var req telemetryRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return nil, err
+	}
+	realID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	req.DeviceID = realID
+
+	return req, nil
+}
+
+// relayRoute returns the gateway and child device IDs of a relay route.
+func relayRoute(r *http.Request) (gatewayID, deviceID uint64, err error) {
+	gatewayID, err = routeID(r)
+	if err != nil {
+		return
+	}
+	device, ok := mux.Vars(r)["device"]
+	if !ok {
+		return 0, 0, errBadRoute
+	}
+	deviceID, err = strconv.ParseUint(device, 10, 64)
+	return
+}
+
+func decodeRelayStatusRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	gatewayID, deviceID, err := relayRoute(r)
+	if err != nil {
+		return nil, err
+	}
+
+	req := relayStatusRequest{GatewayID: gatewayID}
+	err = json.NewDecoder(r.Body).Decode(&req.updateRequest)
+	if err != nil {
+		return nil, err
+	}
+	req.DeviceID = deviceID
+	return req, nil
+}
+
+func decodeRelayTelemetryRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	gatewayID, deviceID, err := relayRoute(r)
+	if err != nil {
+		return nil, err
+	}
+
+	req := relayTelemetryRequest{GatewayID: gatewayID}
+	err = json.NewDecoder(r.Body).Decode(&req.telemetryRequest)
+	if err != nil {
+		return nil, err
+	}
+	req.DeviceID = deviceID
+	return req, nil
+}
+
+func decodeGetTopologyRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := routeID(r)
+	if err != nil {
+		return nil, err
+	}
+	return getTopologyRequest{DeviceID: id}, nil
+}
+
+func decodeGetTwinRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := routeID(r)
+	if err != nil {
+		return nil, err
+	}
+	return getTwinRequest{DeviceID: id}, nil
+}
+
+// decodeUpdateDesiredRequest reads a JSON merge patch from the body. The
+// optional version query parameter makes the update conditional on the
+// desired document's current version.
+func decodeUpdateDesiredRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := routeID(r)
+	if err != nil {
+		return nil, err
+	}
+	req := updateDesiredRequest{DeviceID: id}
+	if version := r.URL.Query().Get("version"); version != "" {
+		if req.Version, err = strconv.ParseInt(version, 10, 64); err != nil {
+			return nil, err
+		}
+	}
+	err = json.NewDecoder(r.Body).Decode(&req.Patch)
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func decodeReportStateRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := routeID(r)
+	if err != nil {
+		return nil, err
+	}
+	req := reportStateRequest{DeviceID: id}
+	err = json.NewDecoder(r.Body).Decode(&req.Patch)
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func decodeEnqueueCommandRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := routeID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var req enqueueCommandRequest
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return nil, err
+	}
+	req.DeviceID = id
+	return req, nil
+}
+
+func decodeAckCommandRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := routeID(r)
+	if err != nil {
+		return nil, err
+	}
+	commandID, err := strconv.ParseUint(mux.Vars(r)["command"], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	var req ackCommandRequest
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return nil, err
+	}
+	req.DeviceID, req.CommandID = id, commandID
+	return req, nil
+}
+
+func decodeCommandHistoryRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := routeID(r)
+	if err != nil {
+		return nil, err
+	}
+	req := commandHistoryRequest{DeviceID: id}
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		if req.Limit, err = strconv.Atoi(limit); err != nil {
+			return nil, err
+		}
+	}
+	return req, nil
+}
+
+// decodeUploadFirmwareRequest reads the raw image from the body and the
+// device_type and version query parameters.
+func decodeUploadFirmwareRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	q := r.URL.Query()
+	req := uploadFirmwareRequest{DeviceType: q.Get("device_type"), Version: q.Get("version")}
+	data, err := ioutil.ReadAll(io.LimitReader(r.Body, maxFirmwareSize+1))
+	if err != nil {
+		return nil, err
+	}
+	req.Data = data
+	return req, nil
+}
+
+func decodeListFirmwareRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	return listFirmwareRequest{}, nil
+}
+
+func decodeDownloadFirmwareRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := routeID(r)
+	if err != nil {
+		return nil, err
+	}
+	return downloadFirmwareRequest{FirmwareID: id}, nil
+}
+
+// encodeFirmwareImage writes the image itself, with its checksum in a
+// header, rather than a JSON reply.
+func encodeFirmwareImage(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	res := response.(downloadFirmwareReply)
+	if res.Err != "" {
+		return encodeResponse(ctx, w, res)
+	}
+	a := res.Image.Artifact
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Length", strconv.FormatInt(a.Size, 10))
+	w.Header().Set("X-Firmware-Id", strconv.FormatUint(a.ID, 10))
+	w.Header().Set("X-Firmware-Device-Type", a.DeviceType)
+	w.Header().Set("X-Firmware-Version", a.Version)
+	w.Header().Set("X-Checksum-Sha256", a.Checksum)
+	_, err := w.Write(res.Image.Data)
+	return err
+}
+
+func decodeCreateCampaignRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req createCampaignRequest
+	err := json.NewDecoder(r.Body).Decode(&req.Campaign)
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func decodeGetCampaignRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := routeID(r)
+	if err != nil {
+		return nil, err
+	}
+	return getCampaignRequest{CampaignID: id}, nil
+}
+
+func decodeListCampaignsRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	return listCampaignsRequest{}, nil
+}
+
+func decodeControlCampaignRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := routeID(r)
+	if err != nil {
+		return nil, err
+	}
+	return controlCampaignRequest{CampaignID: id, Action: mux.Vars(r)["action"]}, nil
+}
+
+func decodeReportFirmwareRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := routeID(r)
+	if err != nil {
+		return nil, err
+	}
+	req := reportFirmwareRequest{DeviceID: id}
+	err = json.NewDecoder(r.Body).Decode(&req.Report)
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func decodeSetTelemetrySchemaRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	deviceType, ok := mux.Vars(r)["type"]
+	if !ok {
+		return nil, errBadRoute
+	}
+	var req setTelemetrySchemaRequest
+	err := json.NewDecoder(r.Body).Decode(&req.Schema)
+	if err != nil {
+		return nil, err
+	}
+	req.Schema.DeviceType = deviceType
+	return req, nil
+}
+
+func decodeGetTelemetrySchemaRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	deviceType, ok := mux.Vars(r)["type"]
+	if !ok {
+		return nil, errBadRoute
+	}
+	return getTelemetrySchemaRequest{DeviceType: deviceType}, nil
+}
+
+func decodeListTelemetrySchemasRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	return listTelemetrySchemasRequest{}, nil
+}
+
+func decodeDeleteTelemetrySchemaRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	deviceType, ok := mux.Vars(r)["type"]
+	if !ok {
+		return nil, errBadRoute
+	}
+	return deleteTelemetrySchemaRequest{DeviceType: deviceType}, nil
+}
+
+// importFormats maps the content types a bulk import may be sent as to
+// their formats.
+var importFormats = map[string]string{
+	"text/csv":             FormatCSV,
+	"application/x-ndjson": FormatNDJSON,
+}
+
+// decodeImportDevicesRequest reads a CSV or NDJSON file, chosen by the
+// format query parameter or the Content-Type, or else a JSON
+// importDevicesRequest. dry_run=true validates without registering.
+func decodeImportDevicesRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	q := r.URL.Query()
+	format := q.Get("format")
+	if format == "" {
+		format = importFormats[strings.Split(r.Header.Get("Content-Type"), ";")[0]]
+	}
+
+	var req importDevicesRequest
+	var err error
+	if format == "" {
+		err = json.NewDecoder(r.Body).Decode(&req)
+	} else {
+		req.Devices, err = ReadDeviceImports(r.Body, format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if dryRun := q.Get("dry_run"); dryRun != "" {
+		if req.DryRun, err = strconv.ParseBool(dryRun); err != nil {
+			return nil, fmt.Errorf("invalid dry_run %q", dryRun)
+		}
+	}
+	return req, nil
+}
+
+// decodeExportDevicesRequest reads the device filter and the format query
+// parameter: csv, ndjson or parquet. Without a format the devices are
+// returned as a JSON reply.
+func decodeExportDevicesRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	filter, err := queryFilter(r)
+	if err != nil {
+		return nil, err
+	}
+	req := exportDevicesRequest{Filter: filter, Format: r.URL.Query().Get("format")}
+	switch req.Format {
+	case "", FormatCSV, FormatNDJSON, FormatParquet:
+	default:
+		return nil, fmt.Errorf("%v %q", errUnknownFormat, req.Format)
+	}
+	return req, nil
+}
+
+var exportContentTypes = map[string]string{
+	FormatCSV:     "text/csv",
+	FormatNDJSON:  "application/x-ndjson",
+	FormatParquet: "application/vnd.apache.parquet",
+}
+
+// encodeDeviceExport writes the export as a file download in the requested
+// format.
+func encodeDeviceExport(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	res := response.(exportDevicesReply)
+	if res.Err != "" || res.Format == "" {
+		return encodeResponse(ctx, w, res)
+	}
+	w.Header().Set("Content-Type", exportContentTypes[res.Format])
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=devices.%s", res.Format))
+	return WriteDeviceExport(w, res.Format, res.Devices)
+}
+
+func decodeGetDeviceRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := routeID(r)
+	if err != nil {
+		return nil, err
+	}
+	return getDeviceRequest{DeviceID: id}, nil
+}
+
+func decodeUpdateDeviceRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := routeID(r)
+	if err != nil {
+		return nil, err
+	}
+	var req updateDeviceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+	req.DeviceID = id
+	return req, nil
+}
+
+func decodeDeleteDeviceRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := routeID(r)
+	if err != nil {
+		return nil, err
+	}
+	return deleteDeviceRequest{DeviceID: id}, nil
+}
+
+func decodeGetStatusRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := routeID(r)
+	if err != nil {
+		return nil, err
+	}
+	return getStatusRequest{DeviceID: id}, nil
+}
+
+func decodeBatteryHistoryRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := routeID(r)
+	if err != nil {
+		return nil, err
+	}
+	req := batteryHistoryRequest{DeviceID: id}
+	if since := r.URL.Query().Get("since"); since != "" {
+		req.Since, err = strconv.ParseInt(since, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return req, nil
+}
+
+func decodeQueryTelemetryRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := routeID(r)
+	if err != nil {
+		return nil, err
+	}
+	req := queryTelemetryRequest{DeviceID: id, Metric: mux.Vars(r)["metric"]}
+	q := r.URL.Query()
+	if from := q.Get("from"); from != "" {
+		if req.From, err = strconv.ParseInt(from, 10, 64); err != nil {
+			return nil, err
+		}
+	}
+	if to := q.Get("to"); to != "" {
+		if req.To, err = strconv.ParseInt(to, 10, 64); err != nil {
+			return nil, err
+		}
+	}
+	return req, nil
+}
+
+func decodeListDevicesRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	filter, err := queryFilter(r)
+	if err != nil {
+		return nil, err
+	}
+	return listDevicesRequest{Filter: filter}, nil
+}
+
+func decodeSetLabelsRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := routeID(r)
+	if err != nil {
+		return nil, err
+	}
+	req := setLabelsRequest{DeviceID: id}
+	err = json.NewDecoder(r.Body).Decode(&req.Labels)
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func decodeCreateRuleRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req createRuleRequest
+	err := json.NewDecoder(r.Body).Decode(&req.Rule)
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func decodeGetRuleRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := routeID(r)
+	if err != nil {
+		return nil, err
+	}
+	return getRuleRequest{RuleID: id}, nil
+}
+
+func decodeUpdateRuleRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := routeID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var req updateRuleRequest
+	err = json.NewDecoder(r.Body).Decode(&req.Rule)
+	if err != nil {
+		return nil, err
+	}
+	req.Rule.ID = id
+	return req, nil
+}
+
+func decodeDeleteRuleRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := routeID(r)
+	if err != nil {
+		return nil, err
+	}
+	return deleteRuleRequest{RuleID: id}, nil
+}
+
+func decodeListRulesRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	return listRulesRequest{}, nil
+}
+
+func decodeActiveAlertsRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req activeAlertsRequest
+	var err error
+	req.Filter, err = queryFilter(r)
+	if err != nil {
+		return nil, err
+	}
+	if device := r.URL.Query().Get("device"); device != "" {
+		id, err := strconv.ParseUint(device, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		req.Filter.DeviceIDs = append(req.Filter.DeviceIDs, id)
+	}
+	return req, nil
+}
+
+func decodeCreateWebhookRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req createWebhookRequest
+	err := json.NewDecoder(r.Body).Decode(&req.Webhook)
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func decodeListWebhooksRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	return listWebhooksRequest{}, nil
+}
+
+func decodeDeleteWebhookRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := routeID(r)
+	if err != nil {
+		return nil, err
+	}
+	return deleteWebhookRequest{WebhookID: id}, nil
+}
+
+func decodeDeadLettersRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req deadLettersRequest
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil {
+			return nil, err
+		}
+		req.Limit = n
+	}
+	return req, nil
+}
+
+func decodeCreateGeofenceRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req createGeofenceRequest
+	err := json.NewDecoder(r.Body).Decode(&req.Geofence)
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func decodeUpdateGeofenceRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := routeID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var req updateGeofenceRequest
+	err = json.NewDecoder(r.Body).Decode(&req.Geofence)
+	if err != nil {
+		return nil, err
+	}
+	req.Geofence.ID = id
+	return req, nil
+}
+
+func decodeDeleteGeofenceRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := routeID(r)
+	if err != nil {
+		return nil, err
+	}
+	return deleteGeofenceRequest{GeofenceID: id}, nil
+}
+
+func decodeListGeofencesRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	return listGeofencesRequest{}, nil
+}
+
+// queryFloats parses the named, required query parameters into dst.
+func queryFloats(r *http.Request, dst map[string]*float64) error {
+	q := r.URL.Query()
+	for name, f := range dst {
+		v := q.Get(name)
+		if v == "" {
+			return fmt.Errorf("missing query parameter %q", name)
+		}
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fmt.Errorf("invalid query parameter %q: %v", name, err)
+		}
+		*f = n
+	}
+	return nil
+}
+
+// queryFilter reads a device filter from the type, owner, ids (comma
+// separated), selector and group query parameters.
+func queryFilter(r *http.Request) (DeviceFilter, error) {
+	q := r.URL.Query()
+	f := DeviceFilter{DeviceType: q.Get("type"), Owner: q.Get("owner"), Selector: q.Get("selector")}
+	if ids := q.Get("ids"); ids != "" {
+		for _, s := range strings.Split(ids, ",") {
+			id, err := strconv.ParseUint(strings.TrimSpace(s), 10, 64)
+			if err != nil {
+				return f, fmt.Errorf("invalid device id %q", s)
+			}
+			f.DeviceIDs = append(f.DeviceIDs, id)
+		}
+	}
+	if group := q.Get("group"); group != "" {
+		id, err := strconv.ParseUint(group, 10, 64)
+		if err != nil {
+			return f, fmt.Errorf("invalid group id %q", group)
+		}
+		f.Group = id
+	}
+	return f, nil
+}
+
+// decodeEventFilter reads an event stream's filter from the query: the
+// device filter's parameters and a comma-separated list of event types.
+func decodeEventFilter(r *http.Request) (EventFilter, error) {
+	devices, err := queryFilter(r)
+	if err != nil {
+		return EventFilter{}, err
+	}
+	f := EventFilter{Devices: devices}
+	if events := r.URL.Query().Get("events"); events != "" {
+		f.Types = strings.Split(events, ",")
+	}
+	return f, nil
+}
+
+// watchEventsLine is a line of the /v1/events stream: an event, or the
+// error that ended the stream.
+type watchEventsLine struct {
+	ID        uint64          `json:"id,omitempty"`
+	Type      string          `json:"type,omitempty"`
+	DeviceID  uint64          `json:"device_id,omitempty"`
+	Timestamp int64           `json:"timestamp,omitempty"`
+	Data      json.RawMessage `json:"data,omitempty"`
+	Err       string          `json:"err,omitempty"`
+}
+
+func (l watchEventsLine) event() Event {
+	e := Event{ID: l.ID, Type: l.Type, DeviceID: l.DeviceID, Timestamp: l.Timestamp}
+	if len(l.Data) > 0 {
+		e.Data = l.Data
+	}
+	return e
+}
+
+func decodeNearbyDevicesRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req nearbyDevicesRequest
+	err := queryFloats(r, map[string]*float64{
+		"lat":    &req.Latitude,
+		"long":   &req.Longitude,
+		"radius": &req.Radius,
+	})
+	if err != nil {
+		return nil, err
+	}
+	req.Filter, err = queryFilter(r)
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func decodeDevicesInBoxRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req devicesInBoxRequest
+	err := queryFloats(r, map[string]*float64{
+		"min_lat":  &req.Box.MinLatitude,
+		"min_long": &req.Box.MinLongitude,
+		"max_lat":  &req.Box.MaxLatitude,
+		"max_long": &req.Box.MaxLongitude,
+	})
+	if err != nil {
+		return nil, err
+	}
+	req.Filter, err = queryFilter(r)
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func decodeListStatusesRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	filter, err := queryFilter(r)
+	if err != nil {
+		return nil, err
+	}
+	return listStatusesRequest{Filter: filter}, nil
+}
+
+func decodeCreateGroupRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req createGroupRequest
+	err := json.NewDecoder(r.Body).Decode(&req.Group)
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func decodeUpdateGroupRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := routeID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var req updateGroupRequest
+	err = json.NewDecoder(r.Body).Decode(&req.Group)
+	if err != nil {
+		return nil, err
+	}
+	req.Group.ID = id
+	return req, nil
+}
+
+func decodeDeleteGroupRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := routeID(r)
+	if err != nil {
+		return nil, err
+	}
+	return deleteGroupRequest{GroupID: id}, nil
+}
+
+func decodeListGroupsRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	return listGroupsRequest{}, nil
+}
+
+func decodeAddGroupMembersRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := routeID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var req addGroupMembersRequest
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return nil, err
+	}
+	req.GroupID = id
+	return req, nil
+}
+
+// decodeRemoveGroupMembersRequest reads the devices to remove from the ids
+// query parameter, since DELETE requests carry no body.
+func decodeRemoveGroupMembersRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	id, err := routeID(r)
+	if err != nil {
+		return nil, err
+	}
+	filter, err := queryFilter(r)
+	if err != nil {
+		return nil, err
+	}
+	return removeGroupMembersRequest{GroupID: id, DeviceIDs: filter.DeviceIDs}, nil
+}
+
+func decodeAggregateTelemetryRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req aggregateTelemetryRequest
+	var err error
+	q := r.URL.Query()
+	req.Query.Metric = q.Get("metric")
+	req.Query.GroupBy = q.Get("group_by")
+	if from := q.Get("from"); from != "" {
+		if req.Query.From, err = strconv.ParseInt(from, 10, 64); err != nil {
+			return nil, err
+		}
+	}
+	if to := q.Get("to"); to != "" {
+		if req.Query.To, err = strconv.ParseInt(to, 10, 64); err != nil {
+			return nil, err
+		}
+	}
+	req.Query.Filter, err = queryFilter(r)
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func encodeResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	return json.NewEncoder(w).Encode(response)
+}
+
+// HTTP client Encode -> to an HTTP request on the monitor's base URL
+// HTTP client Decode -> from the JSON reply
+
+// httpStatusError is a reply with a status other than 2xx, which the
+// monitor sends when it can't decode a request. Proxies in front of it send
+// them too, when an instance is down or overloaded.
+type httpStatusError struct {
+	code int
+	msg  string
+}
+
+func (e httpStatusError) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.code, http.StatusText(e.code), e.msg)
+}
+
+// StatusCode returns the reply's HTTP status.
+func (e httpStatusError) StatusCode() int {
+	return e.code
+}
+
+// routeHTTP points r, which targets the monitor's base URL, at path.
+func routeHTTP(r *http.Request, path string, query url.Values) {
+	r.URL.Path = strings.TrimRight(r.URL.Path, "/") + path
+	if len(query) > 0 {
+		r.URL.RawQuery = query.Encode()
+	}
+}
+
+// encodeHTTPJSON sends body as the JSON body of r.
+func encodeHTTPJSON(r *http.Request, body interface{}) error {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(body); err != nil {
+		return err
+	}
+	r.Header.Set("Content-Type", "application/json")
+	r.ContentLength = int64(buf.Len())
+	r.Body = ioutil.NopCloser(&buf)
+	return nil
+}
+
+func decodeHTTPReply(r *http.Response, reply interface{}) error {
+	if r.StatusCode/100 != 2 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(r.Body, 4096))
+		return httpStatusError{code: r.StatusCode, msg: strings.TrimSpace(string(msg))}
+	}
+	return json.NewDecoder(r.Body).Decode(reply)
+}
+
+func formatID(id uint64) string {
+	return strconv.FormatUint(id, 10)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// eventFilterQuery is the inverse of decodeEventFilter.
+func eventFilterQuery(f EventFilter) url.Values {
+	q := filterQuery(f.Devices)
+	if len(f.Types) > 0 {
+		q.Set("events", strings.Join(f.Types, ","))
+	}
+	return q
+}
+
+// filterQuery is the inverse of queryFilter.
+func filterQuery(f DeviceFilter) url.Values {
+	q := url.Values{}
+	if f.DeviceType != "" {
+		q.Set("type", f.DeviceType)
+	}
+	if f.Owner != "" {
+		q.Set("owner", f.Owner)
+	}
+	if f.Selector != "" {
+		q.Set("selector", f.Selector)
+	}
+	if len(f.DeviceIDs) > 0 {
+		ids := make([]string, len(f.DeviceIDs))
+		for i, id := range f.DeviceIDs {
+			ids[i] = formatID(id)
+		}
+		q.Set("ids", strings.Join(ids, ","))
+	}
+	if f.Group != 0 {
+		q.Set("group", formatID(f.Group))
+	}
+	return q
+}
+
+// EncodeHTTPUploadFirmwareRequest sends the image as the raw body, as
+// decodeUploadFirmwareRequest expects.
+func EncodeHTTPUploadFirmwareRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(uploadFirmwareRequest)
+	routeHTTP(r, "/v1/firmware", url.Values{"device_type": {req.DeviceType}, "version": {req.Version}})
+	r.Header.Set("Content-Type", "application/octet-stream")
+	r.ContentLength = int64(len(req.Data))
+	r.Body = ioutil.NopCloser(bytes.NewReader(req.Data))
+	return nil
+}
+
+func DecodeHTTPUploadFirmwareResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply uploadFirmwareReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+// DecodeHTTPDownloadFirmwareResponse reads the image written by
+// encodeFirmwareImage, or the JSON reply sent in its place on failure.
+func DecodeHTTPDownloadFirmwareResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply downloadFirmwareReply
+	if r.StatusCode/100 != 2 || r.Header.Get("Content-Type") != "application/octet-stream" {
+		err := decodeHTTPReply(r, &reply)
+		return reply, err
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(r.Body, maxFirmwareSize+1))
+	if err != nil {
+		return nil, err
+	}
+	a := &reply.Image.Artifact
+	a.ID, _ = strconv.ParseUint(r.Header.Get("X-Firmware-Id"), 10, 64)
+	a.DeviceType = r.Header.Get("X-Firmware-Device-Type")
+	a.Version = r.Header.Get("X-Firmware-Version")
+	a.Checksum = r.Header.Get("X-Checksum-Sha256")
+	a.Size = int64(len(data))
+	reply.Image.Data = data
+	return reply, nil
+}
+
+func EncodeHTTPRegisterRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(registerRequest)
+	routeHTTP(r, "/v1/devices", nil)
+	return encodeHTTPJSON(r, req)
+}
+
+func DecodeHTTPRegisterResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply registerReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPUpdateRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(updateRequest)
+	routeHTTP(r, "/v1/devices/"+formatID(req.DeviceID)+"/status", nil)
+	return encodeHTTPJSON(r, req)
+}
+
+func DecodeHTTPUpdateResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply updateReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPTelemetryRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(telemetryRequest)
+	routeHTTP(r, "/v1/devices/"+formatID(req.DeviceID)+"/telemetry", nil)
+	return encodeHTTPJSON(r, req)
+}
+
+func DecodeHTTPTelemetryResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply telemetryReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPCreateRuleRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(createRuleRequest)
+	routeHTTP(r, "/v1/rules", nil)
+	return encodeHTTPJSON(r, req.Rule)
+}
+
+func DecodeHTTPCreateRuleResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply createRuleReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPGetRuleRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(getRuleRequest)
+	routeHTTP(r, "/v1/rules/"+formatID(req.RuleID), nil)
+	return nil
+}
+
+func DecodeHTTPGetRuleResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply getRuleReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPUpdateRuleRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(updateRuleRequest)
+	routeHTTP(r, "/v1/rules/"+formatID(req.Rule.ID), nil)
+	return encodeHTTPJSON(r, req.Rule)
+}
+
+func DecodeHTTPUpdateRuleResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply updateRuleReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPDeleteRuleRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(deleteRuleRequest)
+	routeHTTP(r, "/v1/rules/"+formatID(req.RuleID), nil)
+	return nil
+}
+
+func DecodeHTTPDeleteRuleResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply deleteRuleReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPListRulesRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	routeHTTP(r, "/v1/rules", nil)
+	return nil
+}
+
+func DecodeHTTPListRulesResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply listRulesReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPActiveAlertsRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(activeAlertsRequest)
+	q := filterQuery(req.Filter)
+	routeHTTP(r, "/v1/alerts", q)
+	return nil
+}
+
+func DecodeHTTPActiveAlertsResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply activeAlertsReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPCreateWebhookRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(createWebhookRequest)
+	routeHTTP(r, "/v1/webhooks", nil)
+	return encodeHTTPJSON(r, req.Webhook)
+}
+
+func DecodeHTTPCreateWebhookResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply createWebhookReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPListWebhooksRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	routeHTTP(r, "/v1/webhooks", nil)
+	return nil
+}
+
+func DecodeHTTPListWebhooksResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply listWebhooksReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPDeleteWebhookRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(deleteWebhookRequest)
+	routeHTTP(r, "/v1/webhooks/"+formatID(req.WebhookID), nil)
+	return nil
+}
+
+func DecodeHTTPDeleteWebhookResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply deleteWebhookReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPDeadLettersRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(deadLettersRequest)
+	q := url.Values{}
+	if req.Limit != 0 {
+		q.Set("limit", strconv.Itoa(req.Limit))
+	}
+	routeHTTP(r, "/v1/webhooks/deadletters", q)
+	return nil
+}
+
+func DecodeHTTPDeadLettersResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply deadLettersReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPGetDeviceRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(getDeviceRequest)
+	routeHTTP(r, "/v1/devices/"+formatID(req.DeviceID), nil)
+	return nil
+}
+
+func DecodeHTTPGetDeviceResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply getDeviceReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPListDevicesRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(listDevicesRequest)
+	q := filterQuery(req.Filter)
+	routeHTTP(r, "/v1/devices", q)
+	return nil
+}
+
+func DecodeHTTPListDevicesResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply listDevicesReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPCreateGeofenceRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(createGeofenceRequest)
+	routeHTTP(r, "/v1/geofences", nil)
+	return encodeHTTPJSON(r, req.Geofence)
+}
+
+func DecodeHTTPCreateGeofenceResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply createGeofenceReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPUpdateGeofenceRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(updateGeofenceRequest)
+	routeHTTP(r, "/v1/geofences/"+formatID(req.Geofence.ID), nil)
+	return encodeHTTPJSON(r, req.Geofence)
+}
+
+func DecodeHTTPUpdateGeofenceResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply updateGeofenceReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPDeleteGeofenceRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(deleteGeofenceRequest)
+	routeHTTP(r, "/v1/geofences/"+formatID(req.GeofenceID), nil)
+	return nil
+}
+
+func DecodeHTTPDeleteGeofenceResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply deleteGeofenceReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPListGeofencesRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	routeHTTP(r, "/v1/geofences", nil)
+	return nil
+}
+
+func DecodeHTTPListGeofencesResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply listGeofencesReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPNearbyDevicesRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(nearbyDevicesRequest)
+	q := filterQuery(req.Filter)
+	q.Set("lat", formatFloat(req.Latitude))
+	q.Set("long", formatFloat(req.Longitude))
+	q.Set("radius", formatFloat(req.Radius))
+	routeHTTP(r, "/v1/locations/nearby", q)
+	return nil
+}
+
+func DecodeHTTPNearbyDevicesResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply nearbyDevicesReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPDevicesInBoxRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(devicesInBoxRequest)
+	q := filterQuery(req.Filter)
+	q.Set("min_lat", formatFloat(req.Box.MinLatitude))
+	q.Set("min_long", formatFloat(req.Box.MinLongitude))
+	q.Set("max_lat", formatFloat(req.Box.MaxLatitude))
+	q.Set("max_long", formatFloat(req.Box.MaxLongitude))
+	routeHTTP(r, "/v1/locations/within", q)
+	return nil
+}
+
+func DecodeHTTPDevicesInBoxResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply devicesInBoxReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPGetStatusRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(getStatusRequest)
+	routeHTTP(r, "/v1/devices/"+formatID(req.DeviceID)+"/status", nil)
+	return nil
+}
+
+func DecodeHTTPGetStatusResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply getStatusReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPBatteryHistoryRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(batteryHistoryRequest)
+	q := url.Values{}
+	if req.Since != 0 {
+		q.Set("since", strconv.FormatInt(req.Since, 10))
+	}
+	routeHTTP(r, "/v1/devices/"+formatID(req.DeviceID)+"/battery", q)
+	return nil
+}
+
+func DecodeHTTPBatteryHistoryResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply batteryHistoryReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPQueryTelemetryRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(queryTelemetryRequest)
+	q := url.Values{}
+	if req.From != 0 {
+		q.Set("from", strconv.FormatInt(req.From, 10))
+	}
+	if req.To != 0 {
+		q.Set("to", strconv.FormatInt(req.To, 10))
+	}
+	routeHTTP(r, "/v1/devices/"+formatID(req.DeviceID)+"/telemetry/"+req.Metric, q)
+	return nil
+}
+
+func DecodeHTTPQueryTelemetryResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply queryTelemetryReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPAggregateTelemetryRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(aggregateTelemetryRequest)
+	q := filterQuery(req.Query.Filter)
+	q.Set("metric", req.Query.Metric)
+	if req.Query.GroupBy != "" {
+		q.Set("group_by", req.Query.GroupBy)
+	}
+	if req.Query.From != 0 {
+		q.Set("from", strconv.FormatInt(req.Query.From, 10))
+	}
+	if req.Query.To != 0 {
+		q.Set("to", strconv.FormatInt(req.Query.To, 10))
+	}
+	routeHTTP(r, "/v1/telemetry/aggregate", q)
+	return nil
+}
+
+func DecodeHTTPAggregateTelemetryResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply aggregateTelemetryReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPSetLabelsRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(setLabelsRequest)
+	routeHTTP(r, "/v1/devices/"+formatID(req.DeviceID)+"/labels", nil)
+	return encodeHTTPJSON(r, req.Labels)
+}
+
+func DecodeHTTPSetLabelsResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply setLabelsReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPListStatusesRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(listStatusesRequest)
+	q := filterQuery(req.Filter)
+	routeHTTP(r, "/v1/statuses", q)
+	return nil
+}
+
+func DecodeHTTPListStatusesResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply listStatusesReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPCreateGroupRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(createGroupRequest)
+	routeHTTP(r, "/v1/groups", nil)
+	return encodeHTTPJSON(r, req.Group)
+}
+
+func DecodeHTTPCreateGroupResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply createGroupReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPUpdateGroupRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(updateGroupRequest)
+	routeHTTP(r, "/v1/groups/"+formatID(req.Group.ID), nil)
+	return encodeHTTPJSON(r, req.Group)
+}
+
+func DecodeHTTPUpdateGroupResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply updateGroupReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPDeleteGroupRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(deleteGroupRequest)
+	routeHTTP(r, "/v1/groups/"+formatID(req.GroupID), nil)
+	return nil
+}
+
+func DecodeHTTPDeleteGroupResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply deleteGroupReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPListGroupsRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	routeHTTP(r, "/v1/groups", nil)
+	return nil
+}
+
+func DecodeHTTPListGroupsResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply listGroupsReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPAddGroupMembersRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(addGroupMembersRequest)
+	routeHTTP(r, "/v1/groups/"+formatID(req.GroupID)+"/devices", nil)
+	return encodeHTTPJSON(r, req)
+}
+
+func DecodeHTTPAddGroupMembersResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply addGroupMembersReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPRemoveGroupMembersRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(removeGroupMembersRequest)
+	q := filterQuery(DeviceFilter{DeviceIDs: req.DeviceIDs})
+	routeHTTP(r, "/v1/groups/"+formatID(req.GroupID)+"/devices", q)
+	return nil
+}
+
+func DecodeHTTPRemoveGroupMembersResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply removeGroupMembersReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPRelayStatusRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(relayStatusRequest)
+	routeHTTP(r, "/v1/gateways/"+formatID(req.GatewayID)+"/devices/"+formatID(req.DeviceID)+"/status", nil)
+	return encodeHTTPJSON(r, req.updateRequest)
+}
+
+func DecodeHTTPRelayStatusResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply relayStatusReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPRelayTelemetryRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(relayTelemetryRequest)
+	routeHTTP(r, "/v1/gateways/"+formatID(req.GatewayID)+"/devices/"+formatID(req.DeviceID)+"/telemetry", nil)
+	return encodeHTTPJSON(r, req.telemetryRequest)
+}
+
+func DecodeHTTPRelayTelemetryResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply relayTelemetryReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPGetTopologyRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(getTopologyRequest)
+	routeHTTP(r, "/v1/devices/"+formatID(req.DeviceID)+"/topology", nil)
+	return nil
+}
+
+func DecodeHTTPGetTopologyResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply getTopologyReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPGetTwinRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(getTwinRequest)
+	routeHTTP(r, "/v1/devices/"+formatID(req.DeviceID)+"/twin", nil)
+	return nil
+}
+
+func DecodeHTTPGetTwinResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply getTwinReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPUpdateDesiredRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(updateDesiredRequest)
+	q := url.Values{}
+	if req.Version != 0 {
+		q.Set("version", strconv.FormatInt(req.Version, 10))
+	}
+	routeHTTP(r, "/v1/devices/"+formatID(req.DeviceID)+"/twin/desired", q)
+	return encodeHTTPJSON(r, req.Patch)
+}
+
+func DecodeHTTPUpdateDesiredResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply updateDesiredReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPReportStateRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(reportStateRequest)
+	routeHTTP(r, "/v1/devices/"+formatID(req.DeviceID)+"/twin/reported", nil)
+	return encodeHTTPJSON(r, req.Patch)
+}
+
+func DecodeHTTPReportStateResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply reportStateReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPEnqueueCommandRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(enqueueCommandRequest)
+	routeHTTP(r, "/v1/devices/"+formatID(req.DeviceID)+"/commands", nil)
+	return encodeHTTPJSON(r, req)
+}
+
+func DecodeHTTPEnqueueCommandResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply enqueueCommandReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPAckCommandRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(ackCommandRequest)
+	routeHTTP(r, "/v1/devices/"+formatID(req.DeviceID)+"/commands/"+formatID(req.CommandID)+"/ack", nil)
+	return encodeHTTPJSON(r, req)
+}
+
+func DecodeHTTPAckCommandResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply ackCommandReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPCommandHistoryRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(commandHistoryRequest)
+	q := url.Values{}
+	if req.Limit != 0 {
+		q.Set("limit", strconv.Itoa(req.Limit))
+	}
+	routeHTTP(r, "/v1/devices/"+formatID(req.DeviceID)+"/commands", q)
+	return nil
+}
+
+func DecodeHTTPCommandHistoryResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply commandHistoryReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPListFirmwareRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	routeHTTP(r, "/v1/firmware", nil)
+	return nil
+}
+
+func DecodeHTTPListFirmwareResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply listFirmwareReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPDownloadFirmwareRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(downloadFirmwareRequest)
+	routeHTTP(r, "/v1/firmware/"+formatID(req.FirmwareID)+"/image", nil)
+	return nil
+}
+
+func EncodeHTTPCreateCampaignRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(createCampaignRequest)
+	routeHTTP(r, "/v1/campaigns", nil)
+	return encodeHTTPJSON(r, req.Campaign)
+}
+
+func DecodeHTTPCreateCampaignResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply createCampaignReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPGetCampaignRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(getCampaignRequest)
+	routeHTTP(r, "/v1/campaigns/"+formatID(req.CampaignID), nil)
+	return nil
+}
+
+func DecodeHTTPGetCampaignResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply getCampaignReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPListCampaignsRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	routeHTTP(r, "/v1/campaigns", nil)
+	return nil
+}
+
+func DecodeHTTPListCampaignsResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply listCampaignsReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPControlCampaignRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(controlCampaignRequest)
+	routeHTTP(r, "/v1/campaigns/"+formatID(req.CampaignID)+"/"+req.Action, nil)
+	return nil
+}
+
+func DecodeHTTPControlCampaignResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply controlCampaignReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPReportFirmwareRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(reportFirmwareRequest)
+	routeHTTP(r, "/v1/devices/"+formatID(req.DeviceID)+"/firmware", nil)
+	return encodeHTTPJSON(r, req.Report)
+}
+
+func DecodeHTTPReportFirmwareResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply reportFirmwareReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPSetTelemetrySchemaRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(setTelemetrySchemaRequest)
+	routeHTTP(r, "/v1/schemas/"+req.Schema.DeviceType, nil)
+	return encodeHTTPJSON(r, req.Schema)
+}
+
+func DecodeHTTPSetTelemetrySchemaResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply setTelemetrySchemaReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPGetTelemetrySchemaRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(getTelemetrySchemaRequest)
+	routeHTTP(r, "/v1/schemas/"+req.DeviceType, nil)
+	return nil
+}
+
+func DecodeHTTPGetTelemetrySchemaResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply getTelemetrySchemaReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPListTelemetrySchemasRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	routeHTTP(r, "/v1/schemas", nil)
+	return nil
+}
+
+func DecodeHTTPListTelemetrySchemasResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply listTelemetrySchemasReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPDeleteTelemetrySchemaRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(deleteTelemetrySchemaRequest)
+	routeHTTP(r, "/v1/schemas/"+req.DeviceType, nil)
+	return nil
+}
+
+func DecodeHTTPDeleteTelemetrySchemaResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply deleteTelemetrySchemaReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPImportDevicesRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(importDevicesRequest)
+	routeHTTP(r, "/v1/registry/import", nil)
+	return encodeHTTPJSON(r, req)
+}
+
+func DecodeHTTPImportDevicesResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply importDevicesReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPExportDevicesRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(exportDevicesRequest)
+	q := filterQuery(req.Filter)
+	routeHTTP(r, "/v1/registry/export", q)
+	return nil
+}
+
+func DecodeHTTPExportDevicesResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply exportDevicesReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPUpdateDeviceRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(updateDeviceRequest)
+	routeHTTP(r, "/v1/devices/"+formatID(req.DeviceID), nil)
+	return encodeHTTPJSON(r, req)
+}
+
+func DecodeHTTPUpdateDeviceResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply updateDeviceReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+func EncodeHTTPDeleteDeviceRequest(ctx context.Context, r *http.Request, request interface{}) error {
+	req := request.(deleteDeviceRequest)
+	routeHTTP(r, "/v1/devices/"+formatID(req.DeviceID), nil)
+	return nil
+}
+
+func DecodeHTTPDeleteDeviceResponse(ctx context.Context, r *http.Response) (interface{}, error) {
+	var reply deleteDeviceReply
+	err := decodeHTTPReply(r, &reply)
+	return reply, err
+}
+
+// GRPC Encode -> to protobuf
+// GRPC Decode -> from protobuf
+
+func deviceTypeToPB(deviceType string) pb.DeviceType {
+	if deviceType == "Sensor" {
+		return pb.DeviceType_SENSOR
+	}
+	return pb.DeviceType_DRONE
+}
+
+func deviceTypeFromPB(dt pb.DeviceType) string {
+	if dt == pb.DeviceType_SENSOR {
+		return "Sensor"
+	}
+	return "Drone"
+}
+
+func EncodeGRPCRegisterRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(registerRequest)
+	return &pb.RegisterDeviceRequest{Devicetype: deviceTypeToPB(req.DeviceType), Name: req.Name, Owner: req.Owner, Serialnumber: req.SerialNumber, Gatewayid: req.GatewayID, Labels: labelsToPB(req.Labels)}, nil
+}
+
+func DecodeGRPCRegisterRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.RegisterDeviceRequest)
+	return registerRequest{DeviceType: deviceTypeFromPB(req.Devicetype), Name: req.Name, Owner: req.Owner, SerialNumber: req.Serialnumber, GatewayID: req.Gatewayid, Labels: labelsFromPB(req.Labels)}, nil
+}
+
+func EncodeGRPCRegisterResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(registerReply)
+	return &pb.RegisterDeviceReply{Deviceid: res.DeviceID, Registered: res.Registered, Err: res.Err}, nil
+}
+
+func DecodeGRPCRegisterResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.RegisterDeviceReply)
+	return registerReply{DeviceID: res.Deviceid, Registered: res.Registered, Err: res.Err}, nil
+}
+
+func EncodeGRPCUpdateRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(updateRequest)
+	return &pb.StatusUpdateRequest{Batteryremaining: req.BatteryRemaining, Deviceid: req.DeviceID, Location: &pb.Location{
+		Altitude:  req.Location.Altitude,
+		Longitude: req.Location.Longitude,
+		Latitude:  req.Location.Latitude,
+	}, Firmware: firmwareReportToPB(req.Firmware), Deliver: req.Deliver}, nil
+}
+
+func DecodeGRPCUpdateRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.StatusUpdateRequest)
+	return updateRequest{BatteryRemaining: req.Batteryremaining, DeviceID: req.Deviceid, Location: location{
+		Altitude:  req.Location.Altitude,
+		Longitude: req.Location.Longitude,
+		Latitude:  req.Location.Latitude,
+	}, Firmware: firmwareReportFromPB(req.Firmware), Deliver: req.Deliver}, nil
+}
+
+func EncodeGRPCUpdateResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(updateReply)
+	return &pb.StatusUpdateReply{
+		Acknowledged: res.Acknowledged,
+		Desired:      desiredStateToPB(res.Desired),
+		Commands:     commandsToPB(res.Commands),
+		Firmware:     firmwareOfferToPB(res.Firmware),
+		Err:          res.Err,
+	}, nil
+}
+
+func DecodeGRPCUpdateResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.StatusUpdateReply)
+	inbox, err := deviceInboxFromPB(res.Desired, res.Commands, res.Firmware)
+	if err != nil {
+		return nil, err
+	}
+	return updateReply{Acknowledged: res.Acknowledged, deviceInbox: inbox, Err: res.Err}, nil
+}
+
+func deviceInboxFromPB(desired *pb.DesiredState, commands []*pb.Command, firmware *pb.FirmwareOffer) (deviceInbox, error) {
+	var inbox deviceInbox
+	var err error
+	if inbox.Desired, err = desiredStateFromPB(desired); err != nil {
+		return inbox, err
+	}
+	if inbox.Commands, err = commandsFromPB(commands); err != nil {
+		return inbox, err
+	}
+	inbox.Firmware = firmwareOfferFromPB(firmware)
+	return inbox, nil
+}
+
+func EncodeGRPCTelemetryRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(telemetryRequest)
+	return &pb.TelemetrySubmitRequest{Deviceid: req.DeviceID, Readings: req.Readings, Values: telemetryValuesToPB(req.Values)}, nil
+}
+
+func DecodeGRPCTelemetryRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.TelemetrySubmitRequest)
+	return telemetryRequest{DeviceID: req.Deviceid, Readings: req.Readings, Values: telemetryValuesFromPB(req.Values)}, nil
+}
+
+func EncodeGRPCTelemetryResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(telemetryReply)
+	return &pb.TelemetrySubmitReply{Acknowledged: res.Acknowledged, Violations: violationsToPB(res.Violations), Err: res.Err}, nil
+}
+
+func DecodeGRPCTelemetryResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.TelemetrySubmitReply)
+	return telemetryReply{Acknowledged: res.Acknowledged, Violations: violationsFromPB(res.Violations), Err: res.Err}, nil
+}
+
+func deviceToPB(d Device) *pb.Device {
+	return &pb.Device{
+		Deviceid:    d.ID,
+		Name:        d.Name,
+		Owner:       d.Owner,
+		Devicetype:  deviceTypeToPB(d.DeviceType),
+		Gatewayid:   d.GatewayID,
+		Online:      d.Online,
+		Lastseen:    d.LastSeen,
+		Unreachable: d.Unreachable,
+		Firmware:    d.FirmwareVersion,
+		Battery:     batteryEstimateToPB(d.Battery),
+		Labels:      labelsToPB(d.Labels),
+	}
+}
+
+func deviceFromPB(d *pb.Device) Device {
+	if d == nil {
+		return Device{}
+	}
+	return Device{
+		ID:              d.Deviceid,
+		Name:            d.Name,
+		Owner:           d.Owner,
+		DeviceType:      deviceTypeFromPB(d.Devicetype),
+		GatewayID:       d.Gatewayid,
+		Online:          d.Online,
+		LastSeen:        d.Lastseen,
+		Unreachable:     d.Unreachable,
+		FirmwareVersion: d.Firmware,
+		Battery:         batteryEstimateFromPB(d.Battery),
+		Labels:          labelsFromPB(d.Labels),
+	}
+}
+
+func EncodeGRPCGetDeviceRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(getDeviceRequest)
+	return &pb.GetDeviceRequest{Deviceid: req.DeviceID}, nil
+}
+
+func DecodeGRPCGetDeviceRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.GetDeviceRequest)
+	return getDeviceRequest{DeviceID: req.Deviceid}, nil
+}
+
+func EncodeGRPCGetDeviceResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(getDeviceReply)
+	return &pb.GetDeviceReply{Device: deviceToPB(res.Device), Err: res.Err}, nil
+}
+
+func DecodeGRPCGetDeviceResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.GetDeviceReply)
+	return getDeviceReply{Device: deviceFromPB(res.Device), Err: res.Err}, nil
+}
+
+func EncodeGRPCUpdateDeviceRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(updateDeviceRequest)
+	return &pb.UpdateDeviceRequest{Deviceid: req.DeviceID, Name: req.Name, Owner: req.Owner}, nil
+}
+
+func DecodeGRPCUpdateDeviceRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.UpdateDeviceRequest)
+	return updateDeviceRequest{DeviceID: req.Deviceid, Name: req.Name, Owner: req.Owner}, nil
+}
+
+func EncodeGRPCUpdateDeviceResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(updateDeviceReply)
+	return &pb.UpdateDeviceReply{Acknowledged: res.Acknowledged, Err: res.Err}, nil
+}
+
+func DecodeGRPCUpdateDeviceResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.UpdateDeviceReply)
+	return updateDeviceReply{Acknowledged: res.Acknowledged, Err: res.Err}, nil
+}
+
+func EncodeGRPCDeleteDeviceRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(deleteDeviceRequest)
+	return &pb.DeleteDeviceRequest{Deviceid: req.DeviceID}, nil
+}
+
+func DecodeGRPCDeleteDeviceRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.DeleteDeviceRequest)
+	return deleteDeviceRequest{DeviceID: req.Deviceid}, nil
+}
+
+func EncodeGRPCDeleteDeviceResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(deleteDeviceReply)
+	return &pb.DeleteDeviceReply{Acknowledged: res.Acknowledged, Err: res.Err}, nil
+}
+
+func DecodeGRPCDeleteDeviceResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.DeleteDeviceReply)
+	return deleteDeviceReply{Acknowledged: res.Acknowledged, Err: res.Err}, nil
+}
+
+func EncodeGRPCListDevicesRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(listDevicesRequest)
+	return &pb.ListDevicesRequest{Filter: deviceFilterToPB(req.Filter)}, nil
+}
+
+func DecodeGRPCListDevicesRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.ListDevicesRequest)
+	return listDevicesRequest{Filter: deviceFilterFromPB(req.Filter)}, nil
+}
+
+func EncodeGRPCListDevicesResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(listDevicesReply)
+	devices := make([]*pb.Device, len(res.Devices))
+	for i, d := range res.Devices {
+		devices[i] = deviceToPB(d)
+	}
+	return &pb.ListDevicesReply{Devices: devices, Err: res.Err}, nil
+}
+
+func DecodeGRPCListDevicesResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.ListDevicesReply)
+	devices := make([]Device, len(res.Devices))
+	for i, d := range res.Devices {
+		devices[i] = deviceFromPB(d)
+	}
+	return listDevicesReply{Devices: devices, Err: res.Err}, nil
+}
+
+var ruleKinds = map[string]pb.RuleKind{
+	RuleThreshold:    pb.RuleKind_THRESHOLD,
+	RuleRateOfChange: pb.RuleKind_RATE_OF_CHANGE,
+	RuleAbsence:      pb.RuleKind_ABSENCE,
+}
+
+func ruleToPB(r Rule) *pb.Rule {
+	return &pb.Rule{
+		Ruleid:     r.ID,
+		Name:       r.Name,
+		Kind:       ruleKinds[r.Kind],
+		Metric:     r.Metric,
+		Operator:   r.Operator,
+		Threshold:  r.Threshold,
+		Window:     r.Window,
+		Deviceid:   r.DeviceID,
+		Devicetype: r.DeviceType,
+		Owner:      r.Owner,
+	}
+}
+
+func ruleFromPB(r *pb.Rule) Rule {
+	if r == nil {
+		return Rule{}
+	}
+	var kind string
+	for k, v := range ruleKinds {
+		if v == r.Kind {
+			kind = k
+		}
+	}
+	return Rule{
+		ID:         r.Ruleid,
+		Name:       r.Name,
+		Kind:       kind,
+		Metric:     r.Metric,
+		Operator:   r.Operator,
+		Threshold:  r.Threshold,
+		Window:     r.Window,
+		DeviceID:   r.Deviceid,
+		DeviceType: r.Devicetype,
+		Owner:      r.Owner,
+	}
+}
+
+func alertToPB(a Alert) *pb.Alert {
+	return &pb.Alert{
+		Source:     a.Source,
+		Ruleid:     a.RuleID,
+		Deviceid:   a.DeviceID,
+		State:      a.State,
+		Value:      a.Value,
+		Message:    a.Message,
+		Firedat:    a.FiredAt,
+		Resolvedat: a.ResolvedAt,
+		Geofenceid: a.GeofenceID,
+	}
+}
+
+func alertFromPB(a *pb.Alert) Alert {
+	return Alert{
+		Source:     a.Source,
+		RuleID:     a.Ruleid,
+		DeviceID:   a.Deviceid,
+		State:      a.State,
+		Value:      a.Value,
+		Message:    a.Message,
+		FiredAt:    a.Firedat,
+		ResolvedAt: a.Resolvedat,
+		GeofenceID: a.Geofenceid,
+	}
+}
+
+func EncodeGRPCCreateRuleRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(createRuleRequest)
+	return &pb.CreateRuleRequest{Rule: ruleToPB(req.Rule)}, nil
+}
+
+func DecodeGRPCCreateRuleRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.CreateRuleRequest)
+	return createRuleRequest{Rule: ruleFromPB(req.Rule)}, nil
+}
+
+func EncodeGRPCCreateRuleResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(createRuleReply)
+	return &pb.CreateRuleReply{Ruleid: res.RuleID, Err: res.Err}, nil
+}
+
+func DecodeGRPCCreateRuleResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.CreateRuleReply)
+	return createRuleReply{RuleID: res.Ruleid, Err: res.Err}, nil
+}
+
+func EncodeGRPCGetRuleRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(getRuleRequest)
+	return &pb.GetRuleRequest{Ruleid: req.RuleID}, nil
+}
+
+func DecodeGRPCGetRuleRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.GetRuleRequest)
+	return getRuleRequest{RuleID: req.Ruleid}, nil
+}
+
+func EncodeGRPCGetRuleResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(getRuleReply)
+	return &pb.GetRuleReply{Rule: ruleToPB(res.Rule), Err: res.Err}, nil
+}
+
+func DecodeGRPCGetRuleResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.GetRuleReply)
+	return getRuleReply{Rule: ruleFromPB(res.Rule), Err: res.Err}, nil
+}
+
+func EncodeGRPCUpdateRuleRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(updateRuleRequest)
+	return &pb.UpdateRuleRequest{Rule: ruleToPB(req.Rule)}, nil
+}
+
+func DecodeGRPCUpdateRuleRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.UpdateRuleRequest)
+	return updateRuleRequest{Rule: ruleFromPB(req.Rule)}, nil
+}
+
+func EncodeGRPCUpdateRuleResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(updateRuleReply)
+	return &pb.UpdateRuleReply{Acknowledged: res.Acknowledged, Err: res.Err}, nil
+}
+
+func DecodeGRPCUpdateRuleResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.UpdateRuleReply)
+	return updateRuleReply{Acknowledged: res.Acknowledged, Err: res.Err}, nil
+}
+
+func EncodeGRPCDeleteRuleRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(deleteRuleRequest)
+	return &pb.DeleteRuleRequest{Ruleid: req.RuleID}, nil
+}
+
+func DecodeGRPCDeleteRuleRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.DeleteRuleRequest)
+	return deleteRuleRequest{RuleID: req.Ruleid}, nil
+}
+
+func EncodeGRPCDeleteRuleResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(deleteRuleReply)
+	return &pb.DeleteRuleReply{Acknowledged: res.Acknowledged, Err: res.Err}, nil
+}
+
+func DecodeGRPCDeleteRuleResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.DeleteRuleReply)
+	return deleteRuleReply{Acknowledged: res.Acknowledged, Err: res.Err}, nil
+}
+
+func EncodeGRPCListRulesRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	return &pb.ListRulesRequest{}, nil
+}
+
+func DecodeGRPCListRulesRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	return listRulesRequest{}, nil
+}
+
+func EncodeGRPCListRulesResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(listRulesReply)
+	rules := make([]*pb.Rule, len(res.Rules))
+	for i, rule := range res.Rules {
+		rules[i] = ruleToPB(rule)
+	}
+	return &pb.ListRulesReply{Rules: rules, Err: res.Err}, nil
+}
+
+func DecodeGRPCListRulesResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.ListRulesReply)
+	rules := make([]Rule, len(res.Rules))
+	for i, rule := range res.Rules {
+		rules[i] = ruleFromPB(rule)
+	}
+	return listRulesReply{Rules: rules, Err: res.Err}, nil
+}
+
+func EncodeGRPCActiveAlertsRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(activeAlertsRequest)
+	return &pb.ActiveAlertsRequest{Filter: deviceFilterToPB(req.Filter)}, nil
+}
+
+func DecodeGRPCActiveAlertsRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.ActiveAlertsRequest)
+	filter := deviceFilterFromPB(req.Filter)
+	if req.Deviceid != 0 {
+		filter.DeviceIDs = append(filter.DeviceIDs, req.Deviceid)
+	}
+	return activeAlertsRequest{Filter: filter}, nil
+}
+
+func EncodeGRPCActiveAlertsResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(activeAlertsReply)
+	alerts := make([]*pb.Alert, len(res.Alerts))
+	for i, a := range res.Alerts {
+		alerts[i] = alertToPB(a)
+	}
+	return &pb.ActiveAlertsReply{Alerts: alerts, Err: res.Err}, nil
+}
+
+func DecodeGRPCActiveAlertsResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.ActiveAlertsReply)
+	alerts := make([]Alert, len(res.Alerts))
+	for i, a := range res.Alerts {
+		alerts[i] = alertFromPB(a)
+	}
+	return activeAlertsReply{Alerts: alerts, Err: res.Err}, nil
+}
+
+func eventToPB(e Event) *pb.Event {
+	data, _ := json.Marshal(e.Data)
+	return &pb.Event{Eventid: e.ID, Type: e.Type, Deviceid: e.DeviceID, Timestamp: e.Timestamp, Data: data}
+}
+
+func eventFromPB(e *pb.Event) Event {
+	if e == nil {
+		return Event{}
+	}
+	var data interface{}
+	if len(e.Data) > 0 {
+		data = json.RawMessage(e.Data)
+	}
+	return Event{ID: e.Eventid, Type: e.Type, DeviceID: e.Deviceid, Timestamp: e.Timestamp, Data: data}
+}
+
+func webhookToPB(w Webhook) *pb.Webhook {
+	return &pb.Webhook{Webhookid: w.ID, Url: w.URL, Secret: w.Secret, Events: w.Events}
+}
+
+func webhookFromPB(w *pb.Webhook) Webhook {
+	if w == nil {
+		return Webhook{}
+	}
+	return Webhook{ID: w.Webhookid, URL: w.Url, Secret: w.Secret, Events: w.Events}
+}
+
+func EncodeGRPCCreateWebhookRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(createWebhookRequest)
+	return &pb.CreateWebhookRequest{Webhook: webhookToPB(req.Webhook)}, nil
+}
+
+func DecodeGRPCCreateWebhookRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.CreateWebhookRequest)
+	return createWebhookRequest{Webhook: webhookFromPB(req.Webhook)}, nil
+}
+
+func EncodeGRPCCreateWebhookResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(createWebhookReply)
+	return &pb.CreateWebhookReply{Webhookid: res.WebhookID, Err: res.Err}, nil
+}
+
+func DecodeGRPCCreateWebhookResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.CreateWebhookReply)
+	return createWebhookReply{WebhookID: res.Webhookid, Err: res.Err}, nil
+}
+
+func EncodeGRPCListWebhooksRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	return &pb.ListWebhooksRequest{}, nil
+}
+
+func DecodeGRPCListWebhooksRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	return listWebhooksRequest{}, nil
+}
+
+func EncodeGRPCListWebhooksResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(listWebhooksReply)
+	hooks := make([]*pb.Webhook, len(res.Webhooks))
+	for i, w := range res.Webhooks {
+		hooks[i] = webhookToPB(w)
+	}
+	return &pb.ListWebhooksReply{Webhooks: hooks, Err: res.Err}, nil
+}
+
+func DecodeGRPCListWebhooksResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.ListWebhooksReply)
+	hooks := make([]Webhook, len(res.Webhooks))
+	for i, w := range res.Webhooks {
+		hooks[i] = webhookFromPB(w)
+	}
+	return listWebhooksReply{Webhooks: hooks, Err: res.Err}, nil
+}
+
+func EncodeGRPCDeleteWebhookRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(deleteWebhookRequest)
+	return &pb.DeleteWebhookRequest{Webhookid: req.WebhookID}, nil
+}
+
+func DecodeGRPCDeleteWebhookRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.DeleteWebhookRequest)
+	return deleteWebhookRequest{WebhookID: req.Webhookid}, nil
+}
+
+func EncodeGRPCDeleteWebhookResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(deleteWebhookReply)
+	return &pb.DeleteWebhookReply{Acknowledged: res.Acknowledged, Err: res.Err}, nil
+}
+
+func DecodeGRPCDeleteWebhookResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.DeleteWebhookReply)
+	return deleteWebhookReply{Acknowledged: res.Acknowledged, Err: res.Err}, nil
+}
+
+func EncodeGRPCDeadLettersRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(deadLettersRequest)
+	return &pb.DeadLettersRequest{Limit: int32(req.Limit)}, nil
+}
+
+func DecodeGRPCDeadLettersRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.DeadLettersRequest)
+	return deadLettersRequest{Limit: int(req.Limit)}, nil
+}
+
+func EncodeGRPCDeadLettersResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(deadLettersReply)
+	letters := make([]*pb.DeadLetter, len(res.DeadLetters))
+	for i, d := range res.DeadLetters {
+		letters[i] = &pb.DeadLetter{
+			Webhookid: d.WebhookID,
+			Url:       d.URL,
+			Event:     eventToPB(d.Event),
+			Error:     d.Error,
+			Attempts:  int32(d.Attempts),
+			Failedat:  d.FailedAt,
+		}
+	}
+	return &pb.DeadLettersReply{Deadletters: letters, Err: res.Err}, nil
+}
+
+func DecodeGRPCDeadLettersResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.DeadLettersReply)
+	letters := make([]DeadLetter, len(res.Deadletters))
+	for i, d := range res.Deadletters {
+		letters[i] = DeadLetter{
+			WebhookID: d.Webhookid,
+			URL:       d.Url,
+			Event:     eventFromPB(d.Event),
+			Error:     d.Error,
+			Attempts:  int(d.Attempts),
+			FailedAt:  d.Failedat,
+		}
+	}
+	return deadLettersReply{DeadLetters: letters, Err: res.Err}, nil
+}
+
+// Polygons travel over gRPC as GeoJSON text.
+func geofenceToPB(g Geofence) *pb.Geofence {
+	p := &pb.Geofence{
+		Geofenceid: g.ID,
+		Name:       g.Name,
+		Policy:     g.Policy,
+		Latitude:   g.Latitude,
+		Longitude:  g.Longitude,
+		Radius:     g.Radius,
+		Devices:    g.Devices,
+		Owners:     g.Owners,
+	}
+	if g.Polygon != nil {
+		b, _ := json.Marshal(g.Polygon)
+		p.Polygon = string(b)
+	}
+	return p
+}
+
+func geofenceFromPB(p *pb.Geofence) (Geofence, error) {
+	if p == nil {
+		return Geofence{}, nil
+	}
+	g := Geofence{
+		ID:        p.Geofenceid,
+		Name:      p.Name,
+		Policy:    p.Policy,
+		Latitude:  p.Latitude,
+		Longitude: p.Longitude,
+		Radius:    p.Radius,
+		Devices:   p.Devices,
+		Owners:    p.Owners,
+	}
+	if p.Polygon != "" {
+		g.Polygon = &GeoJSONPolygon{}
+		if err := json.Unmarshal([]byte(p.Polygon), g.Polygon); err != nil {
+			return g, err
+		}
+	}
+	return g, nil
+}
+
+func EncodeGRPCCreateGeofenceRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(createGeofenceRequest)
+	return &pb.CreateGeofenceRequest{Geofence: geofenceToPB(req.Geofence)}, nil
+}
+
+func DecodeGRPCCreateGeofenceRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.CreateGeofenceRequest)
+	g, err := geofenceFromPB(req.Geofence)
+	if err != nil {
+		return nil, err
+	}
+	return createGeofenceRequest{Geofence: g}, nil
+}
+
+func EncodeGRPCCreateGeofenceResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(createGeofenceReply)
+	return &pb.CreateGeofenceReply{Geofenceid: res.GeofenceID, Err: res.Err}, nil
+}
+
+func DecodeGRPCCreateGeofenceResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.CreateGeofenceReply)
+	return createGeofenceReply{GeofenceID: res.Geofenceid, Err: res.Err}, nil
+}
+
+func EncodeGRPCUpdateGeofenceRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(updateGeofenceRequest)
+	return &pb.UpdateGeofenceRequest{Geofence: geofenceToPB(req.Geofence)}, nil
+}
+
+func DecodeGRPCUpdateGeofenceRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.UpdateGeofenceRequest)
+	g, err := geofenceFromPB(req.Geofence)
+	if err != nil {
+		return nil, err
+	}
+	return updateGeofenceRequest{Geofence: g}, nil
+}
+
+func EncodeGRPCUpdateGeofenceResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(updateGeofenceReply)
+	return &pb.UpdateGeofenceReply{Acknowledged: res.Acknowledged, Err: res.Err}, nil
+}
+
+func DecodeGRPCUpdateGeofenceResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.UpdateGeofenceReply)
+	return updateGeofenceReply{Acknowledged: res.Acknowledged, Err: res.Err}, nil
+}
+
+func EncodeGRPCDeleteGeofenceRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(deleteGeofenceRequest)
+	return &pb.DeleteGeofenceRequest{Geofenceid: req.GeofenceID}, nil
+}
+
+func DecodeGRPCDeleteGeofenceRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.DeleteGeofenceRequest)
+	return deleteGeofenceRequest{GeofenceID: req.Geofenceid}, nil
+}
+
+func EncodeGRPCDeleteGeofenceResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(deleteGeofenceReply)
+	return &pb.DeleteGeofenceReply{Acknowledged: res.Acknowledged, Err: res.Err}, nil
+}
+
+func DecodeGRPCDeleteGeofenceResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.DeleteGeofenceReply)
+	return deleteGeofenceReply{Acknowledged: res.Acknowledged, Err: res.Err}, nil
+}
+
+func EncodeGRPCListGeofencesRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	return &pb.ListGeofencesRequest{}, nil
+}
+
+func DecodeGRPCListGeofencesRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	return listGeofencesRequest{}, nil
+}
+
+func EncodeGRPCListGeofencesResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(listGeofencesReply)
+	fences := make([]*pb.Geofence, len(res.Geofences))
+	for i, g := range res.Geofences {
+		fences[i] = geofenceToPB(g)
+	}
+	return &pb.ListGeofencesReply{Geofences: fences, Err: res.Err}, nil
+}
+
+func DecodeGRPCListGeofencesResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.ListGeofencesReply)
+	fences := make([]Geofence, len(res.Geofences))
+	for i, p := range res.Geofences {
+		g, err := geofenceFromPB(p)
+		if err != nil {
+			return nil, err
+		}
+		fences[i] = g
+	}
+	return listGeofencesReply{Geofences: fences, Err: res.Err}, nil
+}
+
+func deviceFilterToPB(f DeviceFilter) *pb.DeviceFilter {
+	return &pb.DeviceFilter{Devicetype: f.DeviceType, Owner: f.Owner, Deviceids: f.DeviceIDs, Selector: f.Selector, Group: f.Group}
+}
+
+func deviceFilterFromPB(f *pb.DeviceFilter) DeviceFilter {
+	if f == nil {
+		return DeviceFilter{}
+	}
+	return DeviceFilter{DeviceType: f.Devicetype, Owner: f.Owner, DeviceIDs: f.Deviceids, Selector: f.Selector, Group: f.Group}
+}
+
+func deviceLocationsToPB(locations []DeviceLocation) []*pb.DeviceLocation {
+	out := make([]*pb.DeviceLocation, len(locations))
+	for i, l := range locations {
+		out[i] = &pb.DeviceLocation{
+			Device:    deviceToPB(l.Device),
+			Latitude:  l.Latitude,
+			Longitude: l.Longitude,
+			Distance:  l.Distance,
+		}
+	}
+	return out
+}
+
+func deviceLocationsFromPB(locations []*pb.DeviceLocation) []DeviceLocation {
+	out := make([]DeviceLocation, len(locations))
+	for i, l := range locations {
+		out[i] = DeviceLocation{
+			Device:    deviceFromPB(l.Device),
+			Latitude:  l.Latitude,
+			Longitude: l.Longitude,
+			Distance:  l.Distance,
+		}
+	}
+	return out
+}
+
+func EncodeGRPCNearbyDevicesRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(nearbyDevicesRequest)
+	return &pb.NearbyDevicesRequest{
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+		Radius:    req.Radius,
+		Filter:    deviceFilterToPB(req.Filter),
+	}, nil
+}
+
+func DecodeGRPCNearbyDevicesRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.NearbyDevicesRequest)
+	return nearbyDevicesRequest{
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+		Radius:    req.Radius,
+		Filter:    deviceFilterFromPB(req.Filter),
+	}, nil
+}
+
+func EncodeGRPCNearbyDevicesResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(nearbyDevicesReply)
+	return &pb.NearbyDevicesReply{Devices: deviceLocationsToPB(res.Devices), Err: res.Err}, nil
+}
+
+func DecodeGRPCNearbyDevicesResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.NearbyDevicesReply)
+	return nearbyDevicesReply{Devices: deviceLocationsFromPB(res.Devices), Err: res.Err}, nil
+}
+
+func EncodeGRPCDevicesInBoxRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(devicesInBoxRequest)
+	return &pb.DevicesInBoxRequest{
+		Minlatitude:  req.Box.MinLatitude,
+		Minlongitude: req.Box.MinLongitude,
+		Maxlatitude:  req.Box.MaxLatitude,
+		Maxlongitude: req.Box.MaxLongitude,
+		Filter:       deviceFilterToPB(req.Filter),
+	}, nil
+}
+
+func DecodeGRPCDevicesInBoxRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.DevicesInBoxRequest)
+	return devicesInBoxRequest{
+		Box: BoundingBox{
+			MinLatitude:  req.Minlatitude,
+			MinLongitude: req.Minlongitude,
+			MaxLatitude:  req.Maxlatitude,
+			MaxLongitude: req.Maxlongitude,
+		},
+		Filter: deviceFilterFromPB(req.Filter),
+	}, nil
+}
+
+func EncodeGRPCDevicesInBoxResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(devicesInBoxReply)
+	return &pb.DevicesInBoxReply{Devices: deviceLocationsToPB(res.Devices), Err: res.Err}, nil
+}
+
+func DecodeGRPCDevicesInBoxResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.DevicesInBoxReply)
+	return devicesInBoxReply{Devices: deviceLocationsFromPB(res.Devices), Err: res.Err}, nil
+}
+
+func deviceStatusToPB(s DeviceStatus) *pb.DeviceStatus {
+	return &pb.DeviceStatus{
+		Deviceid:  s.DeviceID,
+		Location:  &pb.Location{Latitude: s.Latitude, Longitude: s.Longitude, Altitude: s.Altitude},
+		Battery:   s.Battery,
+		Timestamp: s.Timestamp,
+		Speed:     s.Speed,
+		Heading:   s.Heading,
+		Climbrate: s.ClimbRate,
+		Distance:  s.Distance,
+		Glitch:    s.Glitch,
+		Totals: &pb.MotionTotals{
+			Distance: s.Totals.Distance,
+			Maxspeed: s.Totals.MaxSpeed,
+			Glitches: s.Totals.Glitches,
+		},
+	}
+}
+
+func deviceStatusFromPB(s *pb.DeviceStatus) DeviceStatus {
+	if s == nil {
+		return DeviceStatus{}
+	}
+	status := DeviceStatus{
+		DeviceID:  s.Deviceid,
+		Battery:   s.Battery,
+		Timestamp: s.Timestamp,
+		Speed:     s.Speed,
+		Heading:   s.Heading,
+		ClimbRate: s.Climbrate,
+		Distance:  s.Distance,
+		Glitch:    s.Glitch,
+	}
+	if s.Location != nil {
+		status.Latitude = s.Location.Latitude
+		status.Longitude = s.Location.Longitude
+		status.Altitude = s.Location.Altitude
+	}
+	if s.Totals != nil {
+		status.Totals = MotionTotals{
+			Distance: s.Totals.Distance,
+			MaxSpeed: s.Totals.Maxspeed,
+			Glitches: s.Totals.Glitches,
+		}
+	}
+	return status
+}
+
+func EncodeGRPCGetStatusRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(getStatusRequest)
+	return &pb.GetStatusRequest{Deviceid: req.DeviceID}, nil
+}
+
+func DecodeGRPCGetStatusRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.GetStatusRequest)
+	return getStatusRequest{DeviceID: req.Deviceid}, nil
+}
+
+func EncodeGRPCGetStatusResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(getStatusReply)
+	return &pb.GetStatusReply{Status: deviceStatusToPB(res.Status), Err: res.Err}, nil
+}
+
+func DecodeGRPCGetStatusResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.GetStatusReply)
+	return getStatusReply{Status: deviceStatusFromPB(res.Status), Err: res.Err}, nil
+}
+
+func batteryEstimateToPB(e *BatteryEstimate) *pb.BatteryEstimate {
+	if e == nil {
+		return nil
+	}
+	return &pb.BatteryEstimate{
+		Level:         e.Level,
+		Charging:      e.Charging,
+		Dischargerate: e.DischargeRate,
+		Timetoempty:   e.TimeToEmpty,
+	}
+}
+
+func batteryEstimateFromPB(e *pb.BatteryEstimate) *BatteryEstimate {
+	if e == nil {
+		return nil
+	}
+	return &BatteryEstimate{
+		Level:         e.Level,
+		Charging:      e.Charging,
+		DischargeRate: e.Dischargerate,
+		TimeToEmpty:   e.Timetoempty,
+	}
+}
+
+func EncodeGRPCBatteryHistoryRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(batteryHistoryRequest)
+	return &pb.BatteryHistoryRequest{Deviceid: req.DeviceID, Since: req.Since}, nil
+}
+
+func DecodeGRPCBatteryHistoryRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.BatteryHistoryRequest)
+	return batteryHistoryRequest{DeviceID: req.Deviceid, Since: req.Since}, nil
+}
+
+func EncodeGRPCBatteryHistoryResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(batteryHistoryReply)
+	samples := make([]*pb.BatterySample, len(res.Samples))
+	for i, s := range res.Samples {
+		samples[i] = &pb.BatterySample{Level: s.Level, Timestamp: s.Timestamp}
+	}
+	return &pb.BatteryHistoryReply{Samples: samples, Err: res.Err}, nil
+}
+
+func DecodeGRPCBatteryHistoryResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.BatteryHistoryReply)
+	samples := make([]BatterySample, len(res.Samples))
+	for i, s := range res.Samples {
+		samples[i] = BatterySample{Level: s.Level, Timestamp: s.Timestamp}
+	}
+	return batteryHistoryReply{Samples: samples, Err: res.Err}, nil
+}
+
+func telemetrySeriesToPB(s TelemetrySeries) *pb.TelemetrySeries {
+	points := make([]*pb.TelemetryPoint, len(s.Points))
+	for i, p := range s.Points {
+		points[i] = &pb.TelemetryPoint{
+			Timestamp: p.Timestamp,
+			Min:       p.Min,
+			Max:       p.Max,
+			Avg:       p.Avg,
+			Last:      p.Last,
+			Count:     p.Count,
+		}
+		if p.Value != nil {
+			points[i].Value = telemetryValueToPB(*p.Value)
+		}
+	}
+	return &pb.TelemetrySeries{Deviceid: s.DeviceID, Metric: s.Metric, Resolution: s.Resolution, Points: points}
+}
+
+func telemetrySeriesFromPB(s *pb.TelemetrySeries) TelemetrySeries {
+	if s == nil {
+		return TelemetrySeries{}
+	}
+	points := make([]TelemetryPoint, len(s.Points))
+	for i, p := range s.Points {
+		points[i] = TelemetryPoint{
+			Timestamp: p.Timestamp,
+			Min:       p.Min,
+			Max:       p.Max,
+			Avg:       p.Avg,
+			Last:      p.Last,
+			Count:     p.Count,
+		}
+		if p.Value != nil {
+			v := telemetryValueFromPB(p.Value)
+			points[i].Value = &v
+		}
+	}
+	return TelemetrySeries{DeviceID: s.Deviceid, Metric: s.Metric, Resolution: s.Resolution, Points: points}
+}
+
+func EncodeGRPCQueryTelemetryRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(queryTelemetryRequest)
+	return &pb.QueryTelemetryRequest{Deviceid: req.DeviceID, Metric: req.Metric, From: req.From, To: req.To}, nil
+}
+
+func DecodeGRPCQueryTelemetryRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.QueryTelemetryRequest)
+	return queryTelemetryRequest{DeviceID: req.Deviceid, Metric: req.Metric, From: req.From, To: req.To}, nil
+}
+
+func EncodeGRPCQueryTelemetryResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(queryTelemetryReply)
+	return &pb.QueryTelemetryReply{Series: telemetrySeriesToPB(res.Series), Err: res.Err}, nil
+}
+
+func DecodeGRPCQueryTelemetryResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.QueryTelemetryReply)
+	return queryTelemetryReply{Series: telemetrySeriesFromPB(res.Series), Err: res.Err}, nil
+}
+
+func EncodeGRPCAggregateTelemetryRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(aggregateTelemetryRequest)
+	return &pb.AggregateTelemetryRequest{
+		Filter:  deviceFilterToPB(req.Query.Filter),
+		Metric:  req.Query.Metric,
+		From:    req.Query.From,
+		To:      req.Query.To,
+		Groupby: req.Query.GroupBy,
+	}, nil
+}
+
+func DecodeGRPCAggregateTelemetryRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.AggregateTelemetryRequest)
+	return aggregateTelemetryRequest{Query: FleetQuery{
+		Filter:  deviceFilterFromPB(req.Filter),
+		Metric:  req.Metric,
+		From:    req.From,
+		To:      req.To,
+		GroupBy: req.Groupby,
+	}}, nil
+}
+
+func EncodeGRPCAggregateTelemetryResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(aggregateTelemetryReply)
+	groups := make([]*pb.AggregateGroup, len(res.Aggregate.Groups))
+	for i, g := range res.Aggregate.Groups {
+		groups[i] = &pb.AggregateGroup{
+			Key:     g.Key,
+			Devices: int32(g.Devices),
+			Min:     g.Min,
+			Max:     g.Max,
+			Avg:     g.Avg,
+			Sum:     g.Sum,
+			Count:   g.Count,
+		}
+	}
+	return &pb.AggregateTelemetryReply{
+		Metric:     res.Aggregate.Metric,
+		From:       res.Aggregate.From,
+		To:         res.Aggregate.To,
+		Resolution: res.Aggregate.Resolution,
+		Groups:     groups,
+		Err:        res.Err,
+	}, nil
+}
+
+func DecodeGRPCAggregateTelemetryResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.AggregateTelemetryReply)
+	groups := make([]AggregateGroup, len(res.Groups))
+	for i, g := range res.Groups {
+		groups[i] = AggregateGroup{
+			Key:     g.Key,
+			Devices: int(g.Devices),
+			Min:     g.Min,
+			Max:     g.Max,
+			Avg:     g.Avg,
+			Sum:     g.Sum,
+			Count:   g.Count,
+		}
+	}
+	return aggregateTelemetryReply{
+		Aggregate: FleetAggregate{
+			Metric:     res.Metric,
+			From:       res.From,
+			To:         res.To,
+			Resolution: res.Resolution,
+			Groups:     groups,
+		},
+		Err: res.Err,
+	}, nil
+}
+
+func labelsToPB(labels map[string]string) []*pb.Label {
+	if len(labels) == 0 {
+		return nil
+	}
+	out := make([]*pb.Label, 0, len(labels))
+	for k, v := range labels {
+		out = append(out, &pb.Label{Key: k, Value: v})
+	}
+	return out
+}
+
+func labelsFromPB(labels []*pb.Label) map[string]string {
+	if len(labels) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(labels))
+	for _, l := range labels {
+		out[l.Key] = l.Value
+	}
+	return out
+}
+
+func EncodeGRPCSetLabelsRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(setLabelsRequest)
+	return &pb.SetLabelsRequest{Deviceid: req.DeviceID, Labels: labelsToPB(req.Labels)}, nil
+}
+
+func DecodeGRPCSetLabelsRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.SetLabelsRequest)
+	return setLabelsRequest{DeviceID: req.Deviceid, Labels: labelsFromPB(req.Labels)}, nil
+}
+
+func EncodeGRPCSetLabelsResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(setLabelsReply)
+	return &pb.SetLabelsReply{Acknowledged: res.Acknowledged, Err: res.Err}, nil
+}
+
+func DecodeGRPCSetLabelsResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.SetLabelsReply)
+	return setLabelsReply{Acknowledged: res.Acknowledged, Err: res.Err}, nil
+}
+
+func EncodeGRPCListStatusesRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(listStatusesRequest)
+	return &pb.ListStatusesRequest{Filter: deviceFilterToPB(req.Filter)}, nil
+}
+
+func DecodeGRPCListStatusesRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.ListStatusesRequest)
+	return listStatusesRequest{Filter: deviceFilterFromPB(req.Filter)}, nil
+}
+
+func EncodeGRPCListStatusesResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(listStatusesReply)
+	statuses := make([]*pb.DeviceStatus, len(res.Statuses))
+	for i, s := range res.Statuses {
+		statuses[i] = deviceStatusToPB(s)
+	}
+	return &pb.ListStatusesReply{Statuses: statuses, Err: res.Err}, nil
+}
+
+func DecodeGRPCListStatusesResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.ListStatusesReply)
+	statuses := make([]DeviceStatus, len(res.Statuses))
+	for i, s := range res.Statuses {
+		statuses[i] = deviceStatusFromPB(s)
+	}
+	return listStatusesReply{Statuses: statuses, Err: res.Err}, nil
+}
+
+func groupToPB(g Group) *pb.Group {
+	return &pb.Group{Groupid: g.ID, Name: g.Name, Parentid: g.ParentID}
+}
+
+func groupFromPB(g *pb.Group) Group {
+	if g == nil {
+		return Group{}
+	}
+	return Group{ID: g.Groupid, Name: g.Name, ParentID: g.Parentid}
+}
+
+func EncodeGRPCCreateGroupRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(createGroupRequest)
+	return &pb.CreateGroupRequest{Group: groupToPB(req.Group)}, nil
+}
+
+func DecodeGRPCCreateGroupRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.CreateGroupRequest)
+	return createGroupRequest{Group: groupFromPB(req.Group)}, nil
+}
+
+func EncodeGRPCCreateGroupResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(createGroupReply)
+	return &pb.CreateGroupReply{Groupid: res.GroupID, Err: res.Err}, nil
+}
+
+func DecodeGRPCCreateGroupResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.CreateGroupReply)
+	return createGroupReply{GroupID: res.Groupid, Err: res.Err}, nil
+}
+
+func EncodeGRPCUpdateGroupRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(updateGroupRequest)
+	return &pb.UpdateGroupRequest{Group: groupToPB(req.Group)}, nil
+}
+
+func DecodeGRPCUpdateGroupRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.UpdateGroupRequest)
+	return updateGroupRequest{Group: groupFromPB(req.Group)}, nil
+}
+
+func EncodeGRPCUpdateGroupResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(updateGroupReply)
+	return &pb.UpdateGroupReply{Acknowledged: res.Acknowledged, Err: res.Err}, nil
+}
+
+func DecodeGRPCUpdateGroupResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.UpdateGroupReply)
+	return updateGroupReply{Acknowledged: res.Acknowledged, Err: res.Err}, nil
+}
+
+func EncodeGRPCDeleteGroupRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(deleteGroupRequest)
+	return &pb.DeleteGroupRequest{Groupid: req.GroupID}, nil
+}
+
+func DecodeGRPCDeleteGroupRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.DeleteGroupRequest)
+	return deleteGroupRequest{GroupID: req.Groupid}, nil
+}
+
+func EncodeGRPCDeleteGroupResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(deleteGroupReply)
+	return &pb.DeleteGroupReply{Acknowledged: res.Acknowledged, Err: res.Err}, nil
+}
+
+func DecodeGRPCDeleteGroupResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.DeleteGroupReply)
+	return deleteGroupReply{Acknowledged: res.Acknowledged, Err: res.Err}, nil
+}
+
+func EncodeGRPCListGroupsRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	return &pb.ListGroupsRequest{}, nil
+}
+
+func DecodeGRPCListGroupsRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	return listGroupsRequest{}, nil
+}
+
+func EncodeGRPCListGroupsResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(listGroupsReply)
+	groups := make([]*pb.Group, len(res.Groups))
+	for i, g := range res.Groups {
+		groups[i] = groupToPB(g)
+	}
+	return &pb.ListGroupsReply{Groups: groups, Err: res.Err}, nil
+}
+
+func DecodeGRPCListGroupsResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.ListGroupsReply)
+	groups := make([]Group, len(res.Groups))
+	for i, g := range res.Groups {
+		groups[i] = groupFromPB(g)
+	}
+	return listGroupsReply{Groups: groups, Err: res.Err}, nil
+}
+
+func EncodeGRPCAddGroupMembersRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(addGroupMembersRequest)
+	return &pb.AddGroupMembersRequest{Groupid: req.GroupID, Deviceids: req.DeviceIDs}, nil
+}
+
+func DecodeGRPCAddGroupMembersRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.AddGroupMembersRequest)
+	return addGroupMembersRequest{GroupID: req.Groupid, DeviceIDs: req.Deviceids}, nil
+}
+
+func EncodeGRPCAddGroupMembersResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(addGroupMembersReply)
+	return &pb.AddGroupMembersReply{Acknowledged: res.Acknowledged, Err: res.Err}, nil
+}
+
+func DecodeGRPCAddGroupMembersResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.AddGroupMembersReply)
+	return addGroupMembersReply{Acknowledged: res.Acknowledged, Err: res.Err}, nil
+}
+
+func EncodeGRPCRemoveGroupMembersRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(removeGroupMembersRequest)
+	return &pb.RemoveGroupMembersRequest{Groupid: req.GroupID, Deviceids: req.DeviceIDs}, nil
+}
+
+func DecodeGRPCRemoveGroupMembersRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.RemoveGroupMembersRequest)
+	return removeGroupMembersRequest{GroupID: req.Groupid, DeviceIDs: req.Deviceids}, nil
+}
+
+func EncodeGRPCRemoveGroupMembersResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(removeGroupMembersReply)
+	return &pb.RemoveGroupMembersReply{Acknowledged: res.Acknowledged, Err: res.Err}, nil
+}
+
+func DecodeGRPCRemoveGroupMembersResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.RemoveGroupMembersReply)
+	return removeGroupMembersReply{Acknowledged: res.Acknowledged, Err: res.Err}, nil
+}
+
+func EncodeGRPCRelayStatusRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(relayStatusRequest)
+	return &pb.RelayStatusRequest{Gatewayid: req.GatewayID, Deviceid: req.DeviceID, Batteryremaining: req.BatteryRemaining, Location: &pb.Location{
+		Latitude:  req.Location.Latitude,
+		Longitude: req.Location.Longitude,
+		Altitude:  req.Location.Altitude,
+	}, Firmware: firmwareReportToPB(req.Firmware), Deliver: req.Deliver}, nil
+}
+
+func DecodeGRPCRelayStatusRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.RelayStatusRequest)
+	update := updateRequest{DeviceID: req.Deviceid, BatteryRemaining: req.Batteryremaining, Firmware: firmwareReportFromPB(req.Firmware), Deliver: req.Deliver}
+	if req.Location != nil {
+		update.Location = location{Latitude: req.Location.Latitude, Longitude: req.Location.Longitude, Altitude: req.Location.Altitude}
+	}
+	return relayStatusRequest{GatewayID: req.Gatewayid, updateRequest: update}, nil
+}
+
+func EncodeGRPCRelayStatusResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(relayStatusReply)
+	return &pb.RelayStatusReply{
+		Acknowledged: res.Acknowledged,
+		Desired:      desiredStateToPB(res.Desired),
+		Commands:     commandsToPB(res.Commands),
+		Firmware:     firmwareOfferToPB(res.Firmware),
+		Err:          res.Err,
+	}, nil
+}
+
+func DecodeGRPCRelayStatusResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.RelayStatusReply)
+	inbox, err := deviceInboxFromPB(res.Desired, res.Commands, res.Firmware)
+	if err != nil {
+		return nil, err
+	}
+	return relayStatusReply{Acknowledged: res.Acknowledged, deviceInbox: inbox, Err: res.Err}, nil
+}
+
+func EncodeGRPCRelayTelemetryRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(relayTelemetryRequest)
+	return &pb.RelayTelemetryRequest{Gatewayid: req.GatewayID, Deviceid: req.DeviceID, Readings: req.Readings, Values: telemetryValuesToPB(req.Values)}, nil
+}
+
+func DecodeGRPCRelayTelemetryRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.RelayTelemetryRequest)
+	return relayTelemetryRequest{GatewayID: req.Gatewayid, telemetryRequest: telemetryRequest{DeviceID: req.Deviceid, Readings: req.Readings, Values: telemetryValuesFromPB(req.Values)}}, nil
+}
+
+func EncodeGRPCRelayTelemetryResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(relayTelemetryReply)
+	return &pb.RelayTelemetryReply{Acknowledged: res.Acknowledged, Violations: violationsToPB(res.Violations), Err: res.Err}, nil
+}
+
+func DecodeGRPCRelayTelemetryResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.RelayTelemetryReply)
+	return relayTelemetryReply{Acknowledged: res.Acknowledged, Violations: violationsFromPB(res.Violations), Err: res.Err}, nil
+}
+
+func topologyToPB(n TopologyNode) *pb.TopologyNode {
+	out := &pb.TopologyNode{Device: deviceToPB(n.Device)}
+	for _, child := range n.Children {
+		out.Children = append(out.Children, topologyToPB(child))
+	}
+	return out
+}
+
+func topologyFromPB(n *pb.TopologyNode) TopologyNode {
+	if n == nil {
+		return TopologyNode{}
+	}
+	out := TopologyNode{Device: deviceFromPB(n.Device)}
+	for _, child := range n.Children {
+		out.Children = append(out.Children, topologyFromPB(child))
+	}
+	return out
+}
+
+func EncodeGRPCGetTopologyRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(getTopologyRequest)
+	return &pb.GetTopologyRequest{Deviceid: req.DeviceID}, nil
+}
+
+func DecodeGRPCGetTopologyRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.GetTopologyRequest)
+	return getTopologyRequest{DeviceID: req.Deviceid}, nil
+}
+
+func EncodeGRPCGetTopologyResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(getTopologyReply)
+	return &pb.GetTopologyReply{Topology: topologyToPB(res.Topology), Err: res.Err}, nil
+}
+
+func DecodeGRPCGetTopologyResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.GetTopologyReply)
+	return getTopologyReply{Topology: topologyFromPB(res.Topology), Err: res.Err}, nil
+}
+
+// Twin documents travel over gRPC as JSON text.
+func twinDocumentToPB(d TwinDocument) string {
+	if d == nil {
+		return ""
+	}
+	b, _ := json.Marshal(d)
+	return string(b)
+}
+
+func twinDocumentFromPB(s string) (TwinDocument, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var d TwinDocument
+	err := json.Unmarshal([]byte(s), &d)
+	return d, err
+}
+
+func twinToPB(t Twin) *pb.Twin {
+	return &pb.Twin{
+		Deviceid:         t.DeviceID,
+		Desired:          twinDocumentToPB(t.Desired),
+		Desiredversion:   t.DesiredVersion,
+		Reported:         twinDocumentToPB(t.Reported),
+		Reportedversion:  t.ReportedVersion,
+		Delta:            twinDocumentToPB(t.Delta),
+		Deliveredversion: t.DeliveredVersion,
+	}
+}
+
+func twinFromPB(p *pb.Twin) (Twin, error) {
+	if p == nil {
+		return Twin{}, nil
+	}
+	t := Twin{DeviceID: p.Deviceid, DesiredVersion: p.Desiredversion, ReportedVersion: p.Reportedversion, DeliveredVersion: p.Deliveredversion}
+	var err error
+	if t.Desired, err = twinDocumentFromPB(p.Desired); err != nil {
+		return t, err
+	}
+	if t.Reported, err = twinDocumentFromPB(p.Reported); err != nil {
+		return t, err
+	}
+	t.Delta, err = twinDocumentFromPB(p.Delta)
+	return t, err
+}
+
+func desiredStateToPB(d *DesiredState) *pb.DesiredState {
+	if d == nil {
+		return nil
+	}
+	return &pb.DesiredState{Version: d.Version, Desired: twinDocumentToPB(d.Desired), Delta: twinDocumentToPB(d.Delta)}
+}
+
+func desiredStateFromPB(p *pb.DesiredState) (*DesiredState, error) {
+	if p == nil {
+		return nil, nil
+	}
+	d := &DesiredState{Version: p.Version}
+	var err error
+	if d.Desired, err = twinDocumentFromPB(p.Desired); err != nil {
+		return nil, err
+	}
+	if d.Delta, err = twinDocumentFromPB(p.Delta); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func EncodeGRPCGetTwinRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(getTwinRequest)
+	return &pb.GetTwinRequest{Deviceid: req.DeviceID}, nil
+}
+
+func DecodeGRPCGetTwinRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.GetTwinRequest)
+	return getTwinRequest{DeviceID: req.Deviceid}, nil
+}
+
+func EncodeGRPCGetTwinResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(getTwinReply)
+	return &pb.GetTwinReply{Twin: twinToPB(res.Twin), Err: res.Err}, nil
+}
+
+func DecodeGRPCGetTwinResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.GetTwinReply)
+	t, err := twinFromPB(res.Twin)
+	if err != nil {
+		return nil, err
+	}
+	return getTwinReply{Twin: t, Err: res.Err}, nil
+}
+
+func EncodeGRPCUpdateDesiredRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(updateDesiredRequest)
+	return &pb.UpdateDesiredRequest{Deviceid: req.DeviceID, Patch: twinDocumentToPB(req.Patch), Version: req.Version}, nil
+}
+
+func DecodeGRPCUpdateDesiredRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.UpdateDesiredRequest)
+	patch, err := twinDocumentFromPB(req.Patch)
+	if err != nil {
+		return nil, err
+	}
+	return updateDesiredRequest{DeviceID: req.Deviceid, Patch: patch, Version: req.Version}, nil
+}
+
+func EncodeGRPCUpdateDesiredResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(updateDesiredReply)
+	return &pb.UpdateDesiredReply{Twin: twinToPB(res.Twin), Err: res.Err}, nil
+}
+
+func DecodeGRPCUpdateDesiredResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.UpdateDesiredReply)
+	t, err := twinFromPB(res.Twin)
+	if err != nil {
+		return nil, err
+	}
+	return updateDesiredReply{Twin: t, Err: res.Err}, nil
+}
+
+func EncodeGRPCReportStateRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(reportStateRequest)
+	return &pb.ReportStateRequest{Deviceid: req.DeviceID, Patch: twinDocumentToPB(req.Patch)}, nil
+}
+
+func DecodeGRPCReportStateRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.ReportStateRequest)
+	patch, err := twinDocumentFromPB(req.Patch)
+	if err != nil {
+		return nil, err
+	}
+	return reportStateRequest{DeviceID: req.Deviceid, Patch: patch}, nil
+}
+
+func EncodeGRPCReportStateResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(reportStateReply)
+	return &pb.ReportStateReply{Twin: twinToPB(res.Twin), Err: res.Err}, nil
+}
+
+func DecodeGRPCReportStateResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.ReportStateReply)
+	t, err := twinFromPB(res.Twin)
+	if err != nil {
+		return nil, err
+	}
+	return reportStateReply{Twin: t, Err: res.Err}, nil
+}
+
+// Command arguments travel over gRPC as JSON text.
+func commandToPB(c Command) *pb.Command {
+	p := &pb.Command{
+		Commandid:   c.ID,
+		Deviceid:    c.DeviceID,
+		Name:        c.Name,
+		Status:      c.Status,
+		Createdat:   c.CreatedAt,
+		Expiresat:   c.ExpiresAt,
+		Deliveredat: c.DeliveredAt,
+		Completedat: c.CompletedAt,
+		Result:      c.Result,
+	}
+	if len(c.Args) > 0 {
+		b, _ := json.Marshal(c.Args)
+		p.Args = string(b)
+	}
+	return p
+}
+
+func commandFromPB(p *pb.Command) (Command, error) {
+	c := Command{
+		ID:          p.Commandid,
+		DeviceID:    p.Deviceid,
+		Name:        p.Name,
+		Status:      p.Status,
+		CreatedAt:   p.Createdat,
+		ExpiresAt:   p.Expiresat,
+		DeliveredAt: p.Deliveredat,
+		CompletedAt: p.Completedat,
+		Result:      p.Result,
+	}
+	if p.Args != "" {
+		if err := json.Unmarshal([]byte(p.Args), &c.Args); err != nil {
+			return c, err
+		}
+	}
+	return c, nil
+}
+
+func commandsToPB(commands []Command) []*pb.Command {
+	out := make([]*pb.Command, len(commands))
+	for i, c := range commands {
+		out[i] = commandToPB(c)
+	}
+	return out
+}
+
+func commandsFromPB(commands []*pb.Command) ([]Command, error) {
+	if len(commands) == 0 {
+		return nil, nil
+	}
+	out := make([]Command, len(commands))
+	for i, p := range commands {
+		c, err := commandFromPB(p)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = c
+	}
+	return out, nil
+}
+
+func EncodeGRPCEnqueueCommandRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(enqueueCommandRequest)
+	p := &pb.EnqueueCommandRequest{Deviceid: req.DeviceID, Name: req.Name, Ttl: req.TTL}
+	if len(req.Args) > 0 {
+		b, err := json.Marshal(req.Args)
+		if err != nil {
+			return nil, err
+		}
+		p.Args = string(b)
+	}
+	return p, nil
+}
+
+func DecodeGRPCEnqueueCommandRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.EnqueueCommandRequest)
+	out := enqueueCommandRequest{DeviceID: req.Deviceid, Name: req.Name, TTL: req.Ttl}
+	if req.Args != "" {
+		if err := json.Unmarshal([]byte(req.Args), &out.Args); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func EncodeGRPCEnqueueCommandResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(enqueueCommandReply)
+	return &pb.EnqueueCommandReply{Commandid: res.CommandID, Err: res.Err}, nil
+}
+
+func DecodeGRPCEnqueueCommandResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.EnqueueCommandReply)
+	return enqueueCommandReply{CommandID: res.Commandid, Err: res.Err}, nil
+}
+
+func EncodeGRPCAckCommandRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(ackCommandRequest)
+	return &pb.AckCommandRequest{Deviceid: req.DeviceID, Commandid: req.CommandID, Success: req.Success, Result: req.Result}, nil
+}
+
+func DecodeGRPCAckCommandRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.AckCommandRequest)
+	return ackCommandRequest{DeviceID: req.Deviceid, CommandID: req.Commandid, Success: req.Success, Result: req.Result}, nil
+}
+
+func EncodeGRPCAckCommandResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(ackCommandReply)
+	return &pb.AckCommandReply{Acknowledged: res.Acknowledged, Err: res.Err}, nil
+}
+
+func DecodeGRPCAckCommandResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.AckCommandReply)
+	return ackCommandReply{Acknowledged: res.Acknowledged, Err: res.Err}, nil
+}
+
+func EncodeGRPCCommandHistoryRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(commandHistoryRequest)
+	return &pb.CommandHistoryRequest{Deviceid: req.DeviceID, Limit: int32(req.Limit)}, nil
+}
+
+func DecodeGRPCCommandHistoryRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.CommandHistoryRequest)
+	return commandHistoryRequest{DeviceID: req.Deviceid, Limit: int(req.Limit)}, nil
+}
+
+func EncodeGRPCCommandHistoryResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(commandHistoryReply)
+	return &pb.CommandHistoryReply{Commands: commandsToPB(res.Commands), Err: res.Err}, nil
+}
+
+func DecodeGRPCCommandHistoryResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.CommandHistoryReply)
+	commands, err := commandsFromPB(res.Commands)
+	if err != nil {
+		return nil, err
+	}
+	return commandHistoryReply{Commands: commands, Err: res.Err}, nil
+}
+
+func firmwareReportToPB(r *FirmwareReport) *pb.FirmwareReport {
+	if r == nil {
+		return nil
+	}
+	return &pb.FirmwareReport{Version: r.Version, Campaignid: r.CampaignID, State: r.State, Progress: int32(r.Progress), Error: r.Error}
+}
+
+func firmwareReportFromPB(r *pb.FirmwareReport) *FirmwareReport {
+	if r == nil {
+		return nil
+	}
+	return &FirmwareReport{Version: r.Version, CampaignID: r.Campaignid, State: r.State, Progress: int(r.Progress), Error: r.Error}
+}
+
+func firmwareOfferToPB(o *FirmwareOffer) *pb.FirmwareOffer {
+	if o == nil {
+		return nil
+	}
+	return &pb.FirmwareOffer{Campaignid: o.CampaignID, Firmwareid: o.FirmwareID, Version: o.Version, Checksum: o.Checksum, Size: o.Size}
+}
+
+func firmwareOfferFromPB(o *pb.FirmwareOffer) *FirmwareOffer {
+	if o == nil {
+		return nil
+	}
+	return &FirmwareOffer{CampaignID: o.Campaignid, FirmwareID: o.Firmwareid, Version: o.Version, Checksum: o.Checksum, Size: o.Size}
+}
+
+func firmwareArtifactToPB(a FirmwareArtifact) *pb.FirmwareArtifact {
+	return &pb.FirmwareArtifact{Firmwareid: a.ID, Devicetype: a.DeviceType, Version: a.Version, Checksum: a.Checksum, Size: a.Size, Createdat: a.CreatedAt}
+}
+
+func firmwareArtifactFromPB(a *pb.FirmwareArtifact) FirmwareArtifact {
+	if a == nil {
+		return FirmwareArtifact{}
+	}
+	return FirmwareArtifact{ID: a.Firmwareid, DeviceType: a.Devicetype, Version: a.Version, Checksum: a.Checksum, Size: a.Size, CreatedAt: a.Createdat}
+}
+
+func campaignToPB(c Campaign) *pb.Campaign {
+	p := &pb.Campaign{
+		Campaignid:       c.ID,
+		Name:             c.Name,
+		Firmwareid:       c.FirmwareID,
+		Devicetype:       c.DeviceType,
+		Version:          c.Version,
+		Selector:         c.Selector,
+		Stage:            int32(c.Stage),
+		Failurethreshold: c.FailureThreshold,
+		Minreports:       int32(c.MinReports),
+		Status:           c.Status,
+		Createdat:        c.CreatedAt,
+	}
+	for _, pct := range c.Stages {
+		p.Stages = append(p.Stages, int32(pct))
+	}
+	if s := c.Stats; s != nil {
+		p.Stats = &pb.CampaignStats{
+			Targeted:    int32(s.Targeted),
+			Offered:     int32(s.Offered),
+			Downloading: int32(s.Downloading),
+			Installing:  int32(s.Installing),
+			Installed:   int32(s.Installed),
+			Failed:      int32(s.Failed),
+			Failurerate: s.FailureRate,
+		}
+	}
+	return p
+}
+
+func campaignFromPB(p *pb.Campaign) Campaign {
+	if p == nil {
+		return Campaign{}
+	}
+	c := Campaign{
+		ID:               p.Campaignid,
+		Name:             p.Name,
+		FirmwareID:       p.Firmwareid,
+		DeviceType:       p.Devicetype,
+		Version:          p.Version,
+		Selector:         p.Selector,
+		Stage:            int(p.Stage),
+		FailureThreshold: p.Failurethreshold,
+		MinReports:       int(p.Minreports),
+		Status:           p.Status,
+		CreatedAt:        p.Createdat,
+	}
+	for _, pct := range p.Stages {
+		c.Stages = append(c.Stages, int(pct))
+	}
+	if s := p.Stats; s != nil {
+		c.Stats = &CampaignStats{
+			Targeted:    int(s.Targeted),
+			Offered:     int(s.Offered),
+			Downloading: int(s.Downloading),
+			Installing:  int(s.Installing),
+			Installed:   int(s.Installed),
+			Failed:      int(s.Failed),
+			FailureRate: s.Failurerate,
+		}
+	}
+	return c
+}
+
+func EncodeGRPCUploadFirmwareRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(uploadFirmwareRequest)
+	return &pb.UploadFirmwareRequest{Devicetype: req.DeviceType, Version: req.Version, Data: req.Data}, nil
+}
+
+func DecodeGRPCUploadFirmwareRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.UploadFirmwareRequest)
+	return uploadFirmwareRequest{DeviceType: req.Devicetype, Version: req.Version, Data: req.Data}, nil
+}
+
+func EncodeGRPCUploadFirmwareResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(uploadFirmwareReply)
+	return &pb.UploadFirmwareReply{Artifact: firmwareArtifactToPB(res.Artifact), Err: res.Err}, nil
+}
+
+func DecodeGRPCUploadFirmwareResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.UploadFirmwareReply)
+	return uploadFirmwareReply{Artifact: firmwareArtifactFromPB(res.Artifact), Err: res.Err}, nil
+}
+
+func EncodeGRPCListFirmwareRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	return &pb.ListFirmwareRequest{}, nil
+}
+
+func DecodeGRPCListFirmwareRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	return listFirmwareRequest{}, nil
+}
+
+func EncodeGRPCListFirmwareResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(listFirmwareReply)
+	artifacts := make([]*pb.FirmwareArtifact, len(res.Artifacts))
+	for i, a := range res.Artifacts {
+		artifacts[i] = firmwareArtifactToPB(a)
+	}
+	return &pb.ListFirmwareReply{Artifacts: artifacts, Err: res.Err}, nil
+}
+
+func DecodeGRPCListFirmwareResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.ListFirmwareReply)
+	artifacts := make([]FirmwareArtifact, len(res.Artifacts))
+	for i, a := range res.Artifacts {
+		artifacts[i] = firmwareArtifactFromPB(a)
+	}
+	return listFirmwareReply{Artifacts: artifacts, Err: res.Err}, nil
+}
+
+func EncodeGRPCDownloadFirmwareRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(downloadFirmwareRequest)
+	return &pb.DownloadFirmwareRequest{Firmwareid: req.FirmwareID}, nil
+}
+
+func DecodeGRPCDownloadFirmwareRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.DownloadFirmwareRequest)
+	return downloadFirmwareRequest{FirmwareID: req.Firmwareid}, nil
+}
+
+func EncodeGRPCDownloadFirmwareResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(downloadFirmwareReply)
+	return &pb.DownloadFirmwareReply{Artifact: firmwareArtifactToPB(res.Image.Artifact), Data: res.Image.Data, Err: res.Err}, nil
+}
+
+func DecodeGRPCDownloadFirmwareResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.DownloadFirmwareReply)
+	return downloadFirmwareReply{Image: FirmwareImage{Artifact: firmwareArtifactFromPB(res.Artifact), Data: res.Data}, Err: res.Err}, nil
+}
+
+func EncodeGRPCCreateCampaignRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(createCampaignRequest)
+	return &pb.CreateCampaignRequest{Campaign: campaignToPB(req.Campaign)}, nil
+}
+
+func DecodeGRPCCreateCampaignRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.CreateCampaignRequest)
+	return createCampaignRequest{Campaign: campaignFromPB(req.Campaign)}, nil
+}
+
+func EncodeGRPCCreateCampaignResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(createCampaignReply)
+	return &pb.CreateCampaignReply{Campaignid: res.CampaignID, Err: res.Err}, nil
+}
+
+func DecodeGRPCCreateCampaignResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.CreateCampaignReply)
+	return createCampaignReply{CampaignID: res.Campaignid, Err: res.Err}, nil
+}
+
+func EncodeGRPCGetCampaignRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(getCampaignRequest)
+	return &pb.GetCampaignRequest{Campaignid: req.CampaignID}, nil
+}
+
+func DecodeGRPCGetCampaignRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.GetCampaignRequest)
+	return getCampaignRequest{CampaignID: req.Campaignid}, nil
+}
+
+func EncodeGRPCGetCampaignResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(getCampaignReply)
+	return &pb.GetCampaignReply{Campaign: campaignToPB(res.Campaign), Err: res.Err}, nil
+}
+
+func DecodeGRPCGetCampaignResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.GetCampaignReply)
+	return getCampaignReply{Campaign: campaignFromPB(res.Campaign), Err: res.Err}, nil
+}
+
+func EncodeGRPCListCampaignsRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	return &pb.ListCampaignsRequest{}, nil
+}
+
+func DecodeGRPCListCampaignsRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	return listCampaignsRequest{}, nil
+}
+
+func EncodeGRPCListCampaignsResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(listCampaignsReply)
+	campaigns := make([]*pb.Campaign, len(res.Campaigns))
+	for i, c := range res.Campaigns {
+		campaigns[i] = campaignToPB(c)
+	}
+	return &pb.ListCampaignsReply{Campaigns: campaigns, Err: res.Err}, nil
+}
+
+func DecodeGRPCListCampaignsResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.ListCampaignsReply)
+	campaigns := make([]Campaign, len(res.Campaigns))
+	for i, c := range res.Campaigns {
+		campaigns[i] = campaignFromPB(c)
+	}
+	return listCampaignsReply{Campaigns: campaigns, Err: res.Err}, nil
+}
+
+func EncodeGRPCControlCampaignRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(controlCampaignRequest)
+	return &pb.ControlCampaignRequest{Campaignid: req.CampaignID, Action: req.Action}, nil
+}
+
+func DecodeGRPCControlCampaignRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.ControlCampaignRequest)
+	return controlCampaignRequest{CampaignID: req.Campaignid, Action: req.Action}, nil
+}
+
+func EncodeGRPCControlCampaignResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(controlCampaignReply)
+	return &pb.ControlCampaignReply{Campaign: campaignToPB(res.Campaign), Err: res.Err}, nil
+}
+
+func DecodeGRPCControlCampaignResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.ControlCampaignReply)
+	return controlCampaignReply{Campaign: campaignFromPB(res.Campaign), Err: res.Err}, nil
+}
+
+func EncodeGRPCReportFirmwareRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(reportFirmwareRequest)
+	return &pb.ReportFirmwareRequest{Deviceid: req.DeviceID, Report: firmwareReportToPB(&req.Report)}, nil
+}
+
+func DecodeGRPCReportFirmwareRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.ReportFirmwareRequest)
+	out := reportFirmwareRequest{DeviceID: req.Deviceid}
+	if report := firmwareReportFromPB(req.Report); report != nil {
+		out.Report = *report
+	}
+	return out, nil
+}
+
+func EncodeGRPCReportFirmwareResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(reportFirmwareReply)
+	return &pb.ReportFirmwareReply{Acknowledged: res.Acknowledged, Err: res.Err}, nil
+}
+
+func DecodeGRPCReportFirmwareResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.ReportFirmwareReply)
+	return reportFirmwareReply{Acknowledged: res.Acknowledged, Err: res.Err}, nil
+}
+
+func violationsToPB(violations []SchemaViolation) []*pb.SchemaViolation {
+	if len(violations) == 0 {
+		return nil
+	}
+	out := make([]*pb.SchemaViolation, len(violations))
+	for i, v := range violations {
+		out[i] = &pb.SchemaViolation{Metric: v.Metric, Reason: v.Reason, Value: telemetryValueToPB(v.Value), Action: v.Action}
+	}
+	return out
+}
+
+func violationsFromPB(violations []*pb.SchemaViolation) []SchemaViolation {
+	if len(violations) == 0 {
+		return nil
+	}
+	out := make([]SchemaViolation, len(violations))
+	for i, v := range violations {
+		out[i] = SchemaViolation{Metric: v.Metric, Reason: v.Reason, Value: telemetryValueFromPB(v.Value), Action: v.Action}
+	}
+	return out
+}
+
+func telemetrySchemaToPB(s TelemetrySchema) *pb.TelemetrySchema {
+	p := &pb.TelemetrySchema{Devicetype: s.DeviceType, Mode: s.Mode, Metrics: make(map[string]*pb.MetricSpec, len(s.Metrics))}
+	for name, m := range s.Metrics {
+		spec := &pb.MetricSpec{Unit: m.Unit}
+		if m.Min != nil {
+			spec.Hasmin, spec.Min = true, *m.Min
+		}
+		if m.Max != nil {
+			spec.Hasmax, spec.Max = true, *m.Max
+		}
+		p.Metrics[name] = spec
+	}
+	return p
+}
+
+func telemetrySchemaFromPB(p *pb.TelemetrySchema) TelemetrySchema {
+	if p == nil {
+		return TelemetrySchema{}
+	}
+	s := TelemetrySchema{DeviceType: p.Devicetype, Mode: p.Mode, Metrics: make(map[string]MetricSpec, len(p.Metrics))}
+	for name, spec := range p.Metrics {
+		if spec == nil {
+			continue
+		}
+		m := MetricSpec{Unit: spec.Unit}
+		if spec.Hasmin {
+			min := spec.Min
+			m.Min = &min
+		}
+		if spec.Hasmax {
+			max := spec.Max
+			m.Max = &max
+		}
+		s.Metrics[name] = m
+	}
+	return s
+}
+
+func EncodeGRPCSetTelemetrySchemaRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(setTelemetrySchemaRequest)
+	return &pb.SetTelemetrySchemaRequest{Schema: telemetrySchemaToPB(req.Schema)}, nil
+}
+
+func DecodeGRPCSetTelemetrySchemaRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.SetTelemetrySchemaRequest)
+	return setTelemetrySchemaRequest{Schema: telemetrySchemaFromPB(req.Schema)}, nil
+}
+
+func EncodeGRPCSetTelemetrySchemaResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(setTelemetrySchemaReply)
+	return &pb.SetTelemetrySchemaReply{Acknowledged: res.Acknowledged, Err: res.Err}, nil
+}
+
+func DecodeGRPCSetTelemetrySchemaResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.SetTelemetrySchemaReply)
+	return setTelemetrySchemaReply{Acknowledged: res.Acknowledged, Err: res.Err}, nil
+}
+
+func EncodeGRPCGetTelemetrySchemaRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(getTelemetrySchemaRequest)
+	return &pb.GetTelemetrySchemaRequest{Devicetype: req.DeviceType}, nil
+}
+
+func DecodeGRPCGetTelemetrySchemaRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.GetTelemetrySchemaRequest)
+	return getTelemetrySchemaRequest{DeviceType: req.Devicetype}, nil
+}
+
+func EncodeGRPCGetTelemetrySchemaResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(getTelemetrySchemaReply)
+	return &pb.GetTelemetrySchemaReply{Schema: telemetrySchemaToPB(res.Schema), Err: res.Err}, nil
+}
+
+func DecodeGRPCGetTelemetrySchemaResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.GetTelemetrySchemaReply)
+	return getTelemetrySchemaReply{Schema: telemetrySchemaFromPB(res.Schema), Err: res.Err}, nil
+}
+
+func EncodeGRPCListTelemetrySchemasRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	return &pb.ListTelemetrySchemasRequest{}, nil
+}
+
+func DecodeGRPCListTelemetrySchemasRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	return listTelemetrySchemasRequest{}, nil
+}
+
+func EncodeGRPCListTelemetrySchemasResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(listTelemetrySchemasReply)
+	schemas := make([]*pb.TelemetrySchema, len(res.Schemas))
+	for i, s := range res.Schemas {
+		schemas[i] = telemetrySchemaToPB(s)
+	}
+	return &pb.ListTelemetrySchemasReply{Schemas: schemas, Err: res.Err}, nil
+}
+
+func DecodeGRPCListTelemetrySchemasResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.ListTelemetrySchemasReply)
+	schemas := make([]TelemetrySchema, len(res.Schemas))
+	for i, s := range res.Schemas {
+		schemas[i] = telemetrySchemaFromPB(s)
+	}
+	return listTelemetrySchemasReply{Schemas: schemas, Err: res.Err}, nil
+}
+
+func EncodeGRPCDeleteTelemetrySchemaRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(deleteTelemetrySchemaRequest)
+	return &pb.DeleteTelemetrySchemaRequest{Devicetype: req.DeviceType}, nil
+}
+
+func DecodeGRPCDeleteTelemetrySchemaRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.DeleteTelemetrySchemaRequest)
+	return deleteTelemetrySchemaRequest{DeviceType: req.Devicetype}, nil
+}
+
+func EncodeGRPCDeleteTelemetrySchemaResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(deleteTelemetrySchemaReply)
+	return &pb.DeleteTelemetrySchemaReply{Acknowledged: res.Acknowledged, Err: res.Err}, nil
+}
+
+func DecodeGRPCDeleteTelemetrySchemaResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.DeleteTelemetrySchemaReply)
+	return deleteTelemetrySchemaReply{Acknowledged: res.Acknowledged, Err: res.Err}, nil
+}
+
+func telemetryValueToPB(v TelemetryValue) *pb.TelemetryValue {
+	return &pb.TelemetryValue{
+		Type:        v.Type,
+		Doublevalue: v.Double,
+		Intvalue:    v.Int,
+		Boolvalue:   v.Bool,
+		Stringvalue: v.Text,
+		Vectorvalue: v.Vector,
+		Unit:        v.Unit,
+	}
+}
+
+func telemetryValueFromPB(v *pb.TelemetryValue) TelemetryValue {
+	if v == nil {
+		return TelemetryValue{}
+	}
+	return TelemetryValue{
+		Type:   v.Type,
+		Double: v.Doublevalue,
+		Int:    v.Intvalue,
+		Bool:   v.Boolvalue,
+		Text:   v.Stringvalue,
+		Vector: v.Vectorvalue,
+		Unit:   v.Unit,
+	}
+}
+
+func telemetryValuesToPB(values map[string]TelemetryValue) map[string]*pb.TelemetryValue {
+	if len(values) == 0 {
+		return nil
+	}
+	out := make(map[string]*pb.TelemetryValue, len(values))
+	for name, v := range values {
+		out[name] = telemetryValueToPB(v)
+	}
+	return out
+}
+
+func telemetryValuesFromPB(values map[string]*pb.TelemetryValue) map[string]TelemetryValue {
+	if len(values) == 0 {
+		return nil
+	}
+	out := make(map[string]TelemetryValue, len(values))
+	for name, v := range values {
+		out[name] = telemetryValueFromPB(v)
+	}
+	return out
+}
+
+func deviceImportToPB(d DeviceImport) *pb.DeviceImport {
+	return &pb.DeviceImport{
+		Name:       d.Name,
+		Owner:      d.Owner,
+		Devicetype: d.DeviceType,
+		Gatewayid:  d.GatewayID,
+		Labels:     labelsToPB(d.Labels),
+	}
+}
+
+func deviceImportFromPB(d *pb.DeviceImport) DeviceImport {
+	if d == nil {
+		return DeviceImport{}
+	}
+	return DeviceImport{
+		Name:       d.Name,
+		Owner:      d.Owner,
+		DeviceType: d.Devicetype,
+		GatewayID:  d.Gatewayid,
+		Labels:     labelsFromPB(d.Labels),
+	}
+}
+
+func EncodeGRPCImportDevicesRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(importDevicesRequest)
+	devices := make([]*pb.DeviceImport, len(req.Devices))
+	for i, d := range req.Devices {
+		devices[i] = deviceImportToPB(d)
+	}
+	return &pb.ImportDevicesRequest{Devices: devices, Dryrun: req.DryRun}, nil
+}
+
+func DecodeGRPCImportDevicesRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.ImportDevicesRequest)
+	devices := make([]DeviceImport, len(req.Devices))
+	for i, d := range req.Devices {
+		devices[i] = deviceImportFromPB(d)
+	}
+	return importDevicesRequest{Devices: devices, DryRun: req.Dryrun}, nil
+}
+
+func EncodeGRPCImportDevicesResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(importDevicesReply)
+	results := make([]*pb.ImportResult, len(res.Results))
+	for i, ir := range res.Results {
+		results[i] = &pb.ImportResult{Row: int32(ir.Row), Deviceid: ir.DeviceID, Err: ir.Err}
+	}
+	return &pb.ImportDevicesReply{Results: results, Err: res.Err}, nil
+}
+
+func DecodeGRPCImportDevicesResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.ImportDevicesReply)
+	results := make([]ImportResult, len(res.Results))
+	for i, ir := range res.Results {
+		results[i] = ImportResult{Row: int(ir.Row), DeviceID: ir.Deviceid, Err: ir.Err}
+	}
+	return importDevicesReply{Results: results, Err: res.Err}, nil
+}
+
+func EncodeGRPCExportDevicesRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(exportDevicesRequest)
+	return &pb.ExportDevicesRequest{Filter: deviceFilterToPB(req.Filter)}, nil
+}
+
+func DecodeGRPCExportDevicesRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.ExportDevicesRequest)
+	return exportDevicesRequest{Filter: deviceFilterFromPB(req.Filter)}, nil
+}
+
+func EncodeGRPCExportDevicesResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(exportDevicesReply)
+	devices := make([]*pb.DeviceExport, len(res.Devices))
+	for i, d := range res.Devices {
+		devices[i] = &pb.DeviceExport{Device: deviceToPB(d.Device)}
+		if d.Status != nil {
+			devices[i].Status = deviceStatusToPB(*d.Status)
+		}
+	}
+	return &pb.ExportDevicesReply{Devices: devices, Err: res.Err}, nil
+}
+
+func DecodeGRPCExportDevicesResponse(ctx context.Context, r interface{}) (interface{}, error) {
+	res := r.(*pb.ExportDevicesReply)
+	devices := make([]DeviceExport, len(res.Devices))
+	for i, d := range res.Devices {
+		devices[i] = DeviceExport{Device: deviceFromPB(d.Device)}
+		if d.Status != nil {
+			s := deviceStatusFromPB(d.Status)
+			devices[i].Status = &s
+		}
+	}
+	return exportDevicesReply{Devices: devices, Err: res.Err}, nil
 }
@@ -4,16 +4,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"reflect"
 	"time"
 
 	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/metrics"
 )
 
 func MakeRegisterEndpoint(srv Service) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (interface{}, error) {
 		req := request.(registerRequest)
-		v, err := srv.RegisterDevice(ctx, req.Name, req.Owner, req.DeviceType)
+		v, err := srv.RegisterDevice(ctx, req.Name, req.Owner, req.DeviceType, req.GatewayID, req.Labels)
 		if err != nil {
 			return registerReply{DeviceID: 0, Registered: false, Err: err.Error()}, nil
 		}
@@ -29,75 +31,1555 @@ func MakeUpdateEndpoint(srv Service) endpoint.Endpoint {
 		if err != nil {
 			return updateReply{Acknowledged: false, Err: err.Error()}, nil
 		}
-		return updateReply{Acknowledged: v}, nil
+		if req.Firmware != nil {
+			if _, err := srv.ReportFirmware(ctx, req.DeviceID, *req.Firmware); err != nil {
+				return updateReply{Acknowledged: v, Err: err.Error()}, nil
+			}
+		}
+		reply := updateReply{Acknowledged: v}
+		if req.Deliver {
+			reply.deviceInbox = deliver(ctx, srv, req.DeviceID)
+		}
+		return reply, nil
+	}
+}
+
+// deliver collects what is waiting for a device in reply to a status update
+// that asked for it. The update has been recorded, so failures are logged
+// rather than returned. A desired state or firmware offer that couldn't be
+// read is offered again on the next update, but commands are claimed as
+// they are read: those claimed before a failure are in the inbox, and the
+// rest stay pending.
+func deliver(ctx context.Context, srv Service, id uint64) deviceInbox {
+	var inbox deviceInbox
+	var err error
+	if inbox.Desired, err = srv.DeliverDesired(ctx, id); err != nil {
+		fmt.Printf("Failed to deliver desired state to device %d: %v\n", id, err)
+	}
+	if inbox.Commands, err = srv.DeliverCommands(ctx, id); err != nil {
+		fmt.Printf("Failed to deliver commands to device %d: %v\n", id, err)
+	}
+	if inbox.Firmware, err = srv.OfferFirmware(ctx, id); err != nil {
+		fmt.Printf("Failed to offer firmware to device %d: %v\n", id, err)
+	}
+	return inbox
+}
+
+// MakeDeliverDesiredEndpoint and MakeDeliverCommandsEndpoint back the
+// WatchDesired and WatchCommands streams, which the go-kit gRPC transport
+// can't serve directly. They and MakeOfferFirmwareEndpoint are not routed
+// on their own: devices receive the same data in the replies to status
+// updates that set Deliver.
+func MakeDeliverDesiredEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(deliverDesiredRequest)
+		v, err := srv.DeliverDesired(ctx, req.DeviceID)
+		if err != nil {
+			return deliverDesiredReply{Err: err.Error()}, nil
+		}
+		return deliverDesiredReply{Desired: v}, nil
+	}
+}
+
+func MakeDeliverCommandsEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(deliverCommandsRequest)
+		v, err := srv.DeliverCommands(ctx, req.DeviceID)
+		if err != nil {
+			return deliverCommandsReply{Err: err.Error()}, nil
+		}
+		return deliverCommandsReply{Commands: v}, nil
+	}
+}
+
+func MakeOfferFirmwareEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(offerFirmwareRequest)
+		v, err := srv.OfferFirmware(ctx, req.DeviceID)
+		if err != nil {
+			return offerFirmwareReply{Err: err.Error()}, nil
+		}
+		return offerFirmwareReply{Offer: v}, nil
+	}
+}
+
+func MakeTelemetryEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(telemetryRequest)
+		v, violations, err := srv.SubmitTelemetry(ctx, req.DeviceID, req.values())
+		if err != nil {
+			return telemetryReply{Acknowledged: false, Violations: violations, Err: err.Error()}, nil
+		}
+		return telemetryReply{Acknowledged: v, Violations: violations}, nil
+	}
+}
+
+func MakeCreateRuleEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(createRuleRequest)
+		v, err := srv.CreateRule(ctx, req.Rule)
+		if err != nil {
+			return createRuleReply{RuleID: 0, Err: err.Error()}, nil
+		}
+		return createRuleReply{RuleID: v}, nil
+	}
+}
+
+func MakeGetRuleEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(getRuleRequest)
+		v, err := srv.GetRule(ctx, req.RuleID)
+		if err != nil {
+			return getRuleReply{Err: err.Error()}, nil
+		}
+		return getRuleReply{Rule: v}, nil
+	}
+}
+
+func MakeUpdateRuleEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(updateRuleRequest)
+		v, err := srv.UpdateRule(ctx, req.Rule)
+		if err != nil {
+			return updateRuleReply{Acknowledged: false, Err: err.Error()}, nil
+		}
+		return updateRuleReply{Acknowledged: v}, nil
+	}
+}
+
+func MakeDeleteRuleEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(deleteRuleRequest)
+		v, err := srv.DeleteRule(ctx, req.RuleID)
+		if err != nil {
+			return deleteRuleReply{Acknowledged: false, Err: err.Error()}, nil
+		}
+		return deleteRuleReply{Acknowledged: v}, nil
+	}
+}
+
+func MakeListRulesEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		v, err := srv.ListRules(ctx)
+		if err != nil {
+			return listRulesReply{Err: err.Error()}, nil
+		}
+		return listRulesReply{Rules: v}, nil
+	}
+}
+
+func MakeActiveAlertsEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(activeAlertsRequest)
+		v, err := srv.ActiveAlerts(ctx, req.Filter)
+		if err != nil {
+			return activeAlertsReply{Err: err.Error()}, nil
+		}
+		return activeAlertsReply{Alerts: v}, nil
+	}
+}
+
+func MakeCreateWebhookEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(createWebhookRequest)
+		v, err := srv.CreateWebhook(ctx, req.Webhook)
+		if err != nil {
+			return createWebhookReply{Err: err.Error()}, nil
+		}
+		return createWebhookReply{WebhookID: v}, nil
+	}
+}
+
+func MakeListWebhooksEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		v, err := srv.ListWebhooks(ctx)
+		if err != nil {
+			return listWebhooksReply{Err: err.Error()}, nil
+		}
+		return listWebhooksReply{Webhooks: v}, nil
+	}
+}
+
+func MakeDeleteWebhookEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(deleteWebhookRequest)
+		v, err := srv.DeleteWebhook(ctx, req.WebhookID)
+		if err != nil {
+			return deleteWebhookReply{Err: err.Error()}, nil
+		}
+		return deleteWebhookReply{Acknowledged: v}, nil
+	}
+}
+
+func MakeDeadLettersEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(deadLettersRequest)
+		v, err := srv.DeadLetters(ctx, req.Limit)
+		if err != nil {
+			return deadLettersReply{Err: err.Error()}, nil
+		}
+		return deadLettersReply{DeadLetters: v}, nil
+	}
+}
+
+func MakeGetDeviceEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(getDeviceRequest)
+		v, err := srv.GetDevice(ctx, req.DeviceID)
+		if err != nil {
+			return getDeviceReply{Err: err.Error()}, nil
+		}
+		return getDeviceReply{Device: v}, nil
+	}
+}
+
+func MakeListDevicesEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listDevicesRequest)
+		v, err := srv.ListDevices(ctx, req.Filter)
+		if err != nil {
+			return listDevicesReply{Err: err.Error()}, nil
+		}
+		return listDevicesReply{Devices: v}, nil
+	}
+}
+
+func MakeCreateGeofenceEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(createGeofenceRequest)
+		v, err := srv.CreateGeofence(ctx, req.Geofence)
+		if err != nil {
+			return createGeofenceReply{Err: err.Error()}, nil
+		}
+		return createGeofenceReply{GeofenceID: v}, nil
+	}
+}
+
+func MakeUpdateGeofenceEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(updateGeofenceRequest)
+		v, err := srv.UpdateGeofence(ctx, req.Geofence)
+		if err != nil {
+			return updateGeofenceReply{Err: err.Error()}, nil
+		}
+		return updateGeofenceReply{Acknowledged: v}, nil
+	}
+}
+
+func MakeDeleteGeofenceEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(deleteGeofenceRequest)
+		v, err := srv.DeleteGeofence(ctx, req.GeofenceID)
+		if err != nil {
+			return deleteGeofenceReply{Err: err.Error()}, nil
+		}
+		return deleteGeofenceReply{Acknowledged: v}, nil
+	}
+}
+
+func MakeListGeofencesEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		v, err := srv.ListGeofences(ctx)
+		if err != nil {
+			return listGeofencesReply{Err: err.Error()}, nil
+		}
+		return listGeofencesReply{Geofences: v}, nil
+	}
+}
+
+func MakeNearbyDevicesEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(nearbyDevicesRequest)
+		v, err := srv.NearbyDevices(ctx, req.Latitude, req.Longitude, req.Radius, req.Filter)
+		if err != nil {
+			return nearbyDevicesReply{Err: err.Error()}, nil
+		}
+		return nearbyDevicesReply{Devices: v}, nil
+	}
+}
+
+func MakeDevicesInBoxEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(devicesInBoxRequest)
+		v, err := srv.DevicesInBox(ctx, req.Box, req.Filter)
+		if err != nil {
+			return devicesInBoxReply{Err: err.Error()}, nil
+		}
+		return devicesInBoxReply{Devices: v}, nil
+	}
+}
+
+func MakeGetStatusEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(getStatusRequest)
+		v, err := srv.GetStatus(ctx, req.DeviceID)
+		if err != nil {
+			return getStatusReply{Err: err.Error()}, nil
+		}
+		return getStatusReply{Status: v}, nil
+	}
+}
+
+func MakeBatteryHistoryEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(batteryHistoryRequest)
+		v, err := srv.BatteryHistory(ctx, req.DeviceID, req.Since)
+		if err != nil {
+			return batteryHistoryReply{Err: err.Error()}, nil
+		}
+		return batteryHistoryReply{Samples: v}, nil
+	}
+}
+
+func MakeQueryTelemetryEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(queryTelemetryRequest)
+		v, err := srv.QueryTelemetry(ctx, req.DeviceID, req.Metric, req.From, req.To)
+		if err != nil {
+			return queryTelemetryReply{Err: err.Error()}, nil
+		}
+		return queryTelemetryReply{Series: v}, nil
+	}
+}
+
+func MakeAggregateTelemetryEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(aggregateTelemetryRequest)
+		v, err := srv.AggregateTelemetry(ctx, req.Query)
+		if err != nil {
+			return aggregateTelemetryReply{Err: err.Error()}, nil
+		}
+		return aggregateTelemetryReply{Aggregate: v}, nil
+	}
+}
+
+func MakeSetLabelsEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(setLabelsRequest)
+		v, err := srv.SetLabels(ctx, req.DeviceID, req.Labels)
+		if err != nil {
+			return setLabelsReply{Err: err.Error()}, nil
+		}
+		return setLabelsReply{Acknowledged: v}, nil
+	}
+}
+
+func MakeListStatusesEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(listStatusesRequest)
+		v, err := srv.ListStatuses(ctx, req.Filter)
+		if err != nil {
+			return listStatusesReply{Err: err.Error()}, nil
+		}
+		return listStatusesReply{Statuses: v}, nil
+	}
+}
+
+func MakeCreateGroupEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(createGroupRequest)
+		v, err := srv.CreateGroup(ctx, req.Group)
+		if err != nil {
+			return createGroupReply{Err: err.Error()}, nil
+		}
+		return createGroupReply{GroupID: v}, nil
+	}
+}
+
+func MakeUpdateGroupEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(updateGroupRequest)
+		v, err := srv.UpdateGroup(ctx, req.Group)
+		if err != nil {
+			return updateGroupReply{Err: err.Error()}, nil
+		}
+		return updateGroupReply{Acknowledged: v}, nil
+	}
+}
+
+func MakeDeleteGroupEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(deleteGroupRequest)
+		v, err := srv.DeleteGroup(ctx, req.GroupID)
+		if err != nil {
+			return deleteGroupReply{Err: err.Error()}, nil
+		}
+		return deleteGroupReply{Acknowledged: v}, nil
+	}
+}
+
+func MakeListGroupsEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		v, err := srv.ListGroups(ctx)
+		if err != nil {
+			return listGroupsReply{Err: err.Error()}, nil
+		}
+		return listGroupsReply{Groups: v}, nil
+	}
+}
+
+func MakeAddGroupMembersEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(addGroupMembersRequest)
+		v, err := srv.AddGroupMembers(ctx, req.GroupID, req.DeviceIDs)
+		if err != nil {
+			return addGroupMembersReply{Err: err.Error()}, nil
+		}
+		return addGroupMembersReply{Acknowledged: v}, nil
+	}
+}
+
+func MakeRemoveGroupMembersEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(removeGroupMembersRequest)
+		v, err := srv.RemoveGroupMembers(ctx, req.GroupID, req.DeviceIDs)
+		if err != nil {
+			return removeGroupMembersReply{Err: err.Error()}, nil
+		}
+		return removeGroupMembersReply{Acknowledged: v}, nil
+	}
+}
+
+func MakeRelayStatusEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(relayStatusRequest)
+		v, err := srv.RelayStatus(ctx, req.GatewayID, req.DeviceID, req.Location.Latitude, req.Location.Longitude, req.Location.Altitude, req.BatteryRemaining)
+		if err != nil {
+			return relayStatusReply{Err: err.Error()}, nil
+		}
+		if req.Firmware != nil {
+			if _, err := srv.ReportFirmware(ctx, req.DeviceID, *req.Firmware); err != nil {
+				return relayStatusReply{Acknowledged: v, Err: err.Error()}, nil
+			}
+		}
+		reply := relayStatusReply{Acknowledged: v}
+		if req.Deliver {
+			reply.deviceInbox = deliver(ctx, srv, req.DeviceID)
+		}
+		return reply, nil
+	}
+}
+
+func MakeRelayTelemetryEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(relayTelemetryRequest)
+		v, violations, err := srv.RelayTelemetry(ctx, req.GatewayID, req.DeviceID, req.values())
+		if err != nil {
+			return relayTelemetryReply{Violations: violations, Err: err.Error()}, nil
+		}
+		return relayTelemetryReply{Acknowledged: v, Violations: violations}, nil
+	}
+}
+
+func MakeGetTopologyEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(getTopologyRequest)
+		v, err := srv.GetTopology(ctx, req.DeviceID)
+		if err != nil {
+			return getTopologyReply{Err: err.Error()}, nil
+		}
+		return getTopologyReply{Topology: v}, nil
+	}
+}
+
+func MakeGetTwinEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(getTwinRequest)
+		v, err := srv.GetTwin(ctx, req.DeviceID)
+		if err != nil {
+			return getTwinReply{Err: err.Error()}, nil
+		}
+		return getTwinReply{Twin: v}, nil
+	}
+}
+
+func MakeUpdateDesiredEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(updateDesiredRequest)
+		v, err := srv.UpdateDesired(ctx, req.DeviceID, req.Patch, req.Version)
+		if err != nil {
+			return updateDesiredReply{Err: err.Error()}, nil
+		}
+		return updateDesiredReply{Twin: v}, nil
+	}
+}
+
+func MakeReportStateEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(reportStateRequest)
+		v, err := srv.ReportState(ctx, req.DeviceID, req.Patch)
+		if err != nil {
+			return reportStateReply{Err: err.Error()}, nil
+		}
+		return reportStateReply{Twin: v}, nil
+	}
+}
+
+func MakeEnqueueCommandEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(enqueueCommandRequest)
+		v, err := srv.EnqueueCommand(ctx, req.DeviceID, req.Name, req.Args, req.TTL)
+		if err != nil {
+			return enqueueCommandReply{Err: err.Error()}, nil
+		}
+		return enqueueCommandReply{CommandID: v}, nil
+	}
+}
+
+func MakeAckCommandEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(ackCommandRequest)
+		v, err := srv.AckCommand(ctx, req.DeviceID, req.CommandID, req.Success, req.Result)
+		if err != nil {
+			return ackCommandReply{Err: err.Error()}, nil
+		}
+		return ackCommandReply{Acknowledged: v}, nil
+	}
+}
+
+func MakeCommandHistoryEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(commandHistoryRequest)
+		v, err := srv.CommandHistory(ctx, req.DeviceID, req.Limit)
+		if err != nil {
+			return commandHistoryReply{Err: err.Error()}, nil
+		}
+		return commandHistoryReply{Commands: v}, nil
+	}
+}
+
+func MakeUploadFirmwareEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(uploadFirmwareRequest)
+		v, err := srv.UploadFirmware(ctx, req.DeviceType, req.Version, req.Data)
+		if err != nil {
+			return uploadFirmwareReply{Err: err.Error()}, nil
+		}
+		return uploadFirmwareReply{Artifact: v}, nil
+	}
+}
+
+func MakeListFirmwareEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		v, err := srv.ListFirmware(ctx)
+		if err != nil {
+			return listFirmwareReply{Err: err.Error()}, nil
+		}
+		return listFirmwareReply{Artifacts: v}, nil
+	}
+}
+
+func MakeDownloadFirmwareEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(downloadFirmwareRequest)
+		v, err := srv.DownloadFirmware(ctx, req.FirmwareID)
+		if err != nil {
+			return downloadFirmwareReply{Err: err.Error()}, nil
+		}
+		return downloadFirmwareReply{Image: v}, nil
+	}
+}
+
+func MakeCreateCampaignEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(createCampaignRequest)
+		v, err := srv.CreateCampaign(ctx, req.Campaign)
+		if err != nil {
+			return createCampaignReply{Err: err.Error()}, nil
+		}
+		return createCampaignReply{CampaignID: v}, nil
+	}
+}
+
+func MakeGetCampaignEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(getCampaignRequest)
+		v, err := srv.GetCampaign(ctx, req.CampaignID)
+		if err != nil {
+			return getCampaignReply{Err: err.Error()}, nil
+		}
+		return getCampaignReply{Campaign: v}, nil
+	}
+}
+
+func MakeListCampaignsEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		v, err := srv.ListCampaigns(ctx)
+		if err != nil {
+			return listCampaignsReply{Err: err.Error()}, nil
+		}
+		return listCampaignsReply{Campaigns: v}, nil
+	}
+}
+
+func MakeControlCampaignEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(controlCampaignRequest)
+		v, err := srv.ControlCampaign(ctx, req.CampaignID, req.Action)
+		if err != nil {
+			return controlCampaignReply{Err: err.Error()}, nil
+		}
+		return controlCampaignReply{Campaign: v}, nil
+	}
+}
+
+func MakeReportFirmwareEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(reportFirmwareRequest)
+		v, err := srv.ReportFirmware(ctx, req.DeviceID, req.Report)
+		if err != nil {
+			return reportFirmwareReply{Err: err.Error()}, nil
+		}
+		return reportFirmwareReply{Acknowledged: v}, nil
+	}
+}
+
+func MakeSetTelemetrySchemaEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(setTelemetrySchemaRequest)
+		v, err := srv.SetTelemetrySchema(ctx, req.Schema)
+		if err != nil {
+			return setTelemetrySchemaReply{Err: err.Error()}, nil
+		}
+		return setTelemetrySchemaReply{Acknowledged: v}, nil
+	}
+}
+
+func MakeGetTelemetrySchemaEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(getTelemetrySchemaRequest)
+		v, err := srv.GetTelemetrySchema(ctx, req.DeviceType)
+		if err != nil {
+			return getTelemetrySchemaReply{Err: err.Error()}, nil
+		}
+		return getTelemetrySchemaReply{Schema: v}, nil
+	}
+}
+
+func MakeListTelemetrySchemasEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		v, err := srv.ListTelemetrySchemas(ctx)
+		if err != nil {
+			return listTelemetrySchemasReply{Err: err.Error()}, nil
+		}
+		return listTelemetrySchemasReply{Schemas: v}, nil
+	}
+}
+
+func MakeDeleteTelemetrySchemaEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(deleteTelemetrySchemaRequest)
+		v, err := srv.DeleteTelemetrySchema(ctx, req.DeviceType)
+		if err != nil {
+			return deleteTelemetrySchemaReply{Err: err.Error()}, nil
+		}
+		return deleteTelemetrySchemaReply{Acknowledged: v}, nil
+	}
+}
+
+func MakeImportDevicesEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(importDevicesRequest)
+		v, err := srv.ImportDevices(ctx, req.Devices, req.DryRun)
+		if err != nil {
+			return importDevicesReply{Err: err.Error()}, nil
+		}
+		return importDevicesReply{Results: v}, nil
+	}
+}
+
+func MakeExportDevicesEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(exportDevicesRequest)
+		v, err := srv.ExportDevices(ctx, req.Filter)
+		if err != nil {
+			return exportDevicesReply{Err: err.Error()}, nil
+		}
+		return exportDevicesReply{Devices: v, Format: req.Format}, nil
+	}
+}
+
+func MakeUpdateDeviceEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(updateDeviceRequest)
+		v, err := srv.UpdateDevice(ctx, req.DeviceID, req.Name, req.Owner)
+		if err != nil {
+			return updateDeviceReply{Err: err.Error()}, nil
+		}
+		return updateDeviceReply{Acknowledged: v}, nil
+	}
+}
+
+func MakeDeleteDeviceEndpoint(srv Service) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.(deleteDeviceRequest)
+		v, err := srv.DeleteDevice(ctx, req.DeviceID)
+		if err != nil {
+			return deleteDeviceReply{Err: err.Error()}, nil
+		}
+		return deleteDeviceReply{Acknowledged: v}, nil
+	}
+}
+
+func EndpointInstrumentingMiddleware(duration metrics.Histogram) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+			defer func(begin time.Time) {
+				duration.With("success", fmt.Sprint(err == nil)).Observe(time.Since(begin).Seconds())
+			}(time.Now())
+			return next(ctx, request)
+		}
+	}
+}
+
+// EndpointLoggingMiddleware logs every call with how long it took and the
+// error it failed with, if any, whether the transport's or the service's.
+func EndpointLoggingMiddleware(logger log.Logger) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+			defer func(begin time.Time) {
+				failure := replyError(response)
+				if err != nil {
+					failure = err.Error()
+				}
+				logger.Log("took", time.Since(begin), "err", failure)
+			}(time.Now())
+			return next(ctx, request)
+		}
+	}
+}
+
+// replyError returns the Err a reply carries back to the caller.
+func replyError(response interface{}) string {
+	v := reflect.Indirect(reflect.ValueOf(response))
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+	if f := v.FieldByName("Err"); f.Kind() == reflect.String {
+		return f.String()
+	}
+	return ""
+}
+
+type Endpoints struct {
+	RegisterEndpoint  endpoint.Endpoint
+	UpdateEndpoint    endpoint.Endpoint
+	TelemetryEndpoint endpoint.Endpoint
+
+	CreateRuleEndpoint   endpoint.Endpoint
+	GetRuleEndpoint      endpoint.Endpoint
+	UpdateRuleEndpoint   endpoint.Endpoint
+	DeleteRuleEndpoint   endpoint.Endpoint
+	ListRulesEndpoint    endpoint.Endpoint
+	ActiveAlertsEndpoint endpoint.Endpoint
+
+	CreateWebhookEndpoint endpoint.Endpoint
+	ListWebhooksEndpoint  endpoint.Endpoint
+	DeleteWebhookEndpoint endpoint.Endpoint
+	DeadLettersEndpoint   endpoint.Endpoint
+
+	GetDeviceEndpoint   endpoint.Endpoint
+	ListDevicesEndpoint endpoint.Endpoint
+
+	CreateGeofenceEndpoint endpoint.Endpoint
+	UpdateGeofenceEndpoint endpoint.Endpoint
+	DeleteGeofenceEndpoint endpoint.Endpoint
+	ListGeofencesEndpoint  endpoint.Endpoint
+
+	NearbyDevicesEndpoint endpoint.Endpoint
+	DevicesInBoxEndpoint  endpoint.Endpoint
+
+	GetStatusEndpoint endpoint.Endpoint
+
+	BatteryHistoryEndpoint endpoint.Endpoint
+
+	QueryTelemetryEndpoint endpoint.Endpoint
+
+	AggregateTelemetryEndpoint endpoint.Endpoint
+
+	SetLabelsEndpoint endpoint.Endpoint
+
+	ListStatusesEndpoint       endpoint.Endpoint
+	CreateGroupEndpoint        endpoint.Endpoint
+	UpdateGroupEndpoint        endpoint.Endpoint
+	DeleteGroupEndpoint        endpoint.Endpoint
+	ListGroupsEndpoint         endpoint.Endpoint
+	AddGroupMembersEndpoint    endpoint.Endpoint
+	RemoveGroupMembersEndpoint endpoint.Endpoint
+
+	RelayStatusEndpoint    endpoint.Endpoint
+	RelayTelemetryEndpoint endpoint.Endpoint
+	GetTopologyEndpoint    endpoint.Endpoint
+
+	GetTwinEndpoint        endpoint.Endpoint
+	UpdateDesiredEndpoint  endpoint.Endpoint
+	ReportStateEndpoint    endpoint.Endpoint
+	DeliverDesiredEndpoint endpoint.Endpoint
+
+	EnqueueCommandEndpoint  endpoint.Endpoint
+	AckCommandEndpoint      endpoint.Endpoint
+	CommandHistoryEndpoint  endpoint.Endpoint
+	DeliverCommandsEndpoint endpoint.Endpoint
+
+	UploadFirmwareEndpoint   endpoint.Endpoint
+	ListFirmwareEndpoint     endpoint.Endpoint
+	DownloadFirmwareEndpoint endpoint.Endpoint
+	CreateCampaignEndpoint   endpoint.Endpoint
+	GetCampaignEndpoint      endpoint.Endpoint
+	ListCampaignsEndpoint    endpoint.Endpoint
+	ControlCampaignEndpoint  endpoint.Endpoint
+
+	ReportFirmwareEndpoint endpoint.Endpoint
+	OfferFirmwareEndpoint  endpoint.Endpoint
+
+	SetTelemetrySchemaEndpoint    endpoint.Endpoint
+	GetTelemetrySchemaEndpoint    endpoint.Endpoint
+	ListTelemetrySchemasEndpoint  endpoint.Endpoint
+	DeleteTelemetrySchemaEndpoint endpoint.Endpoint
+
+	ImportDevicesEndpoint endpoint.Endpoint
+	ExportDevicesEndpoint endpoint.Endpoint
+
+	UpdateDeviceEndpoint endpoint.Endpoint
+	DeleteDeviceEndpoint endpoint.Endpoint
+}
+
+func (e Endpoints) RegisterDevice(ctx context.Context, name, owner, deviceType string, gatewayID uint64, labels map[string]string) (id uint64, err error) {
+	req := registerRequest{DeviceType: deviceType, Name: name, Owner: owner, GatewayID: gatewayID, Labels: labels}
+	resp, err := e.RegisterEndpoint(ctx, req)
+	if err != nil {
+		return 0, err
+	}
+	registerResp := resp.(registerReply)
+	if registerResp.Err != "" {
+		return 0, errors.New(registerResp.Err)
+	}
+	return registerResp.DeviceID, nil
+}
+
+func (e Endpoints) UpdateStatus(ctx context.Context, deviceId uint64, lat, long, alt float32, battery uint32) (bool, error) {
+	req := updateRequest{BatteryRemaining: battery, DeviceID: deviceId, Location: location{
+		Latitude: lat, Longitude: long, Altitude: alt},
+	}
+	resp, err := e.UpdateEndpoint(ctx, req)
+	if err != nil {
+		return false, err
+	}
+	updateResp := resp.(updateReply)
+	if updateResp.Err != "" {
+		return false, errors.New(updateResp.Err)
+	}
+	return updateResp.Acknowledged, nil
+}
+
+func (e Endpoints) SubmitTelemetry(ctx context.Context, id uint64, readings map[string]TelemetryValue) (bool, []SchemaViolation, error) {
+	req := telemetryRequest{DeviceID: id, Values: readings}
+	resp, err := e.TelemetryEndpoint(ctx, req)
+	if err != nil {
+		return false, nil, err
+	}
+	telemetryResp := resp.(telemetryReply)
+	if telemetryResp.Err != "" {
+		return false, telemetryResp.Violations, errors.New(telemetryResp.Err)
+	}
+	return telemetryResp.Acknowledged, telemetryResp.Violations, nil
+}
+
+func (e Endpoints) CreateRule(ctx context.Context, rule Rule) (uint64, error) {
+	resp, err := e.CreateRuleEndpoint(ctx, createRuleRequest{Rule: rule})
+	if err != nil {
+		return 0, err
+	}
+	createResp := resp.(createRuleReply)
+	if createResp.Err != "" {
+		return 0, errors.New(createResp.Err)
+	}
+	return createResp.RuleID, nil
+}
+
+func (e Endpoints) GetRule(ctx context.Context, id uint64) (Rule, error) {
+	resp, err := e.GetRuleEndpoint(ctx, getRuleRequest{RuleID: id})
+	if err != nil {
+		return Rule{}, err
+	}
+	getResp := resp.(getRuleReply)
+	if getResp.Err != "" {
+		return Rule{}, errors.New(getResp.Err)
+	}
+	return getResp.Rule, nil
+}
+
+func (e Endpoints) UpdateRule(ctx context.Context, rule Rule) (bool, error) {
+	resp, err := e.UpdateRuleEndpoint(ctx, updateRuleRequest{Rule: rule})
+	if err != nil {
+		return false, err
+	}
+	updateResp := resp.(updateRuleReply)
+	if updateResp.Err != "" {
+		return false, errors.New(updateResp.Err)
+	}
+	return updateResp.Acknowledged, nil
+}
+
+func (e Endpoints) DeleteRule(ctx context.Context, id uint64) (bool, error) {
+	resp, err := e.DeleteRuleEndpoint(ctx, deleteRuleRequest{RuleID: id})
+	if err != nil {
+		return false, err
+	}
+	deleteResp := resp.(deleteRuleReply)
+	if deleteResp.Err != "" {
+		return false, errors.New(deleteResp.Err)
+	}
+	return deleteResp.Acknowledged, nil
+}
+
+func (e Endpoints) ListRules(ctx context.Context) ([]Rule, error) {
+	resp, err := e.ListRulesEndpoint(ctx, listRulesRequest{})
+	if err != nil {
+		return nil, err
+	}
+	listResp := resp.(listRulesReply)
+	if listResp.Err != "" {
+		return nil, errors.New(listResp.Err)
+	}
+	return listResp.Rules, nil
+}
+
+func (e Endpoints) ActiveAlerts(ctx context.Context, filter DeviceFilter) ([]Alert, error) {
+	resp, err := e.ActiveAlertsEndpoint(ctx, activeAlertsRequest{Filter: filter})
+	if err != nil {
+		return nil, err
+	}
+	alertsResp := resp.(activeAlertsReply)
+	if alertsResp.Err != "" {
+		return nil, errors.New(alertsResp.Err)
+	}
+	return alertsResp.Alerts, nil
+}
+
+func (e Endpoints) CreateWebhook(ctx context.Context, hook Webhook) (uint64, error) {
+	resp, err := e.CreateWebhookEndpoint(ctx, createWebhookRequest{Webhook: hook})
+	if err != nil {
+		return 0, err
+	}
+	createResp := resp.(createWebhookReply)
+	if createResp.Err != "" {
+		return 0, errors.New(createResp.Err)
+	}
+	return createResp.WebhookID, nil
+}
+
+func (e Endpoints) ListWebhooks(ctx context.Context) ([]Webhook, error) {
+	resp, err := e.ListWebhooksEndpoint(ctx, listWebhooksRequest{})
+	if err != nil {
+		return nil, err
+	}
+	listResp := resp.(listWebhooksReply)
+	if listResp.Err != "" {
+		return nil, errors.New(listResp.Err)
+	}
+	return listResp.Webhooks, nil
+}
+
+func (e Endpoints) DeleteWebhook(ctx context.Context, id uint64) (bool, error) {
+	resp, err := e.DeleteWebhookEndpoint(ctx, deleteWebhookRequest{WebhookID: id})
+	if err != nil {
+		return false, err
+	}
+	deleteResp := resp.(deleteWebhookReply)
+	if deleteResp.Err != "" {
+		return false, errors.New(deleteResp.Err)
+	}
+	return deleteResp.Acknowledged, nil
+}
+
+func (e Endpoints) DeadLetters(ctx context.Context, limit int) ([]DeadLetter, error) {
+	resp, err := e.DeadLettersEndpoint(ctx, deadLettersRequest{Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+	deadResp := resp.(deadLettersReply)
+	if deadResp.Err != "" {
+		return nil, errors.New(deadResp.Err)
+	}
+	return deadResp.DeadLetters, nil
+}
+
+func (e Endpoints) GetDevice(ctx context.Context, id uint64) (Device, error) {
+	resp, err := e.GetDeviceEndpoint(ctx, getDeviceRequest{DeviceID: id})
+	if err != nil {
+		return Device{}, err
+	}
+	getResp := resp.(getDeviceReply)
+	if getResp.Err != "" {
+		return Device{}, errors.New(getResp.Err)
+	}
+	return getResp.Device, nil
+}
+
+func (e Endpoints) ListDevices(ctx context.Context, filter DeviceFilter) ([]Device, error) {
+	resp, err := e.ListDevicesEndpoint(ctx, listDevicesRequest{Filter: filter})
+	if err != nil {
+		return nil, err
+	}
+	listResp := resp.(listDevicesReply)
+	if listResp.Err != "" {
+		return nil, errors.New(listResp.Err)
+	}
+	return listResp.Devices, nil
+}
+
+func (e Endpoints) CreateGeofence(ctx context.Context, geofence Geofence) (uint64, error) {
+	resp, err := e.CreateGeofenceEndpoint(ctx, createGeofenceRequest{Geofence: geofence})
+	if err != nil {
+		return 0, err
 	}
+	createResp := resp.(createGeofenceReply)
+	if createResp.Err != "" {
+		return 0, errors.New(createResp.Err)
+	}
+	return createResp.GeofenceID, nil
 }
 
-func MakeTelemetryEndpoint(srv Service) endpoint.Endpoint {
-	return func(ctx context.Context, request interface{}) (interface{}, error) {
-		req := request.(telemetryRequest)
-		v, err := srv.SubmitTelemetry(ctx, req.DeviceID, req.Readings)
-		if err != nil {
-			return telemetryReply{Acknowledged: false, Err: err.Error()}, nil
-		}
-		return telemetryReply{Acknowledged: v}, nil
+func (e Endpoints) UpdateGeofence(ctx context.Context, geofence Geofence) (bool, error) {
+	resp, err := e.UpdateGeofenceEndpoint(ctx, updateGeofenceRequest{Geofence: geofence})
+	if err != nil {
+		return false, err
 	}
+	updateResp := resp.(updateGeofenceReply)
+	if updateResp.Err != "" {
+		return false, errors.New(updateResp.Err)
+	}
+	return updateResp.Acknowledged, nil
 }
 
-func EndpointInstrumentingMiddleware(duration metrics.Histogram) endpoint.Middleware {
-	return func(next endpoint.Endpoint) endpoint.Endpoint {
-		return func(ctx context.Context, request interface{}) (response interface{}, err error) {
-			defer func(begin time.Time) {
-				duration.With("success", fmt.Sprint(err == nil)).Observe(time.Since(begin).Seconds())
-			}(time.Now())
-			return next(ctx, request)
-		}
+func (e Endpoints) DeleteGeofence(ctx context.Context, id uint64) (bool, error) {
+	resp, err := e.DeleteGeofenceEndpoint(ctx, deleteGeofenceRequest{GeofenceID: id})
+	if err != nil {
+		return false, err
 	}
+	deleteResp := resp.(deleteGeofenceReply)
+	if deleteResp.Err != "" {
+		return false, errors.New(deleteResp.Err)
+	}
+	return deleteResp.Acknowledged, nil
 }
 
-type Endpoints struct {
-	RegisterEndpoint  endpoint.Endpoint
-	UpdateEndpoint    endpoint.Endpoint
-	TelemetryEndpoint endpoint.Endpoint
+func (e Endpoints) ListGeofences(ctx context.Context) ([]Geofence, error) {
+	resp, err := e.ListGeofencesEndpoint(ctx, listGeofencesRequest{})
+	if err != nil {
+		return nil, err
+	}
+	listResp := resp.(listGeofencesReply)
+	if listResp.Err != "" {
+		return nil, errors.New(listResp.Err)
+	}
+	return listResp.Geofences, nil
 }
 
-func (e Endpoints) RegisterDevice(ctx context.Context, name, owner, deviceType string) (id uint64, err error) {
-	req := registerRequest{DeviceType: deviceType, Name: name, Owner: owner}
-	resp, err := e.RegisterEndpoint(ctx, req)
+func (e Endpoints) NearbyDevices(ctx context.Context, lat float64, long float64, radius float64, filter DeviceFilter) ([]DeviceLocation, error) {
+	resp, err := e.NearbyDevicesEndpoint(ctx, nearbyDevicesRequest{Latitude: lat, Longitude: long, Radius: radius, Filter: filter})
+	if err != nil {
+		return nil, err
+	}
+	nearbyResp := resp.(nearbyDevicesReply)
+	if nearbyResp.Err != "" {
+		return nil, errors.New(nearbyResp.Err)
+	}
+	return nearbyResp.Devices, nil
+}
+
+func (e Endpoints) DevicesInBox(ctx context.Context, box BoundingBox, filter DeviceFilter) ([]DeviceLocation, error) {
+	resp, err := e.DevicesInBoxEndpoint(ctx, devicesInBoxRequest{Box: box, Filter: filter})
+	if err != nil {
+		return nil, err
+	}
+	devicesResp := resp.(devicesInBoxReply)
+	if devicesResp.Err != "" {
+		return nil, errors.New(devicesResp.Err)
+	}
+	return devicesResp.Devices, nil
+}
+
+func (e Endpoints) GetStatus(ctx context.Context, id uint64) (DeviceStatus, error) {
+	resp, err := e.GetStatusEndpoint(ctx, getStatusRequest{DeviceID: id})
+	if err != nil {
+		return DeviceStatus{}, err
+	}
+	getResp := resp.(getStatusReply)
+	if getResp.Err != "" {
+		return DeviceStatus{}, errors.New(getResp.Err)
+	}
+	return getResp.Status, nil
+}
+
+func (e Endpoints) BatteryHistory(ctx context.Context, id uint64, since int64) ([]BatterySample, error) {
+	resp, err := e.BatteryHistoryEndpoint(ctx, batteryHistoryRequest{DeviceID: id, Since: since})
+	if err != nil {
+		return nil, err
+	}
+	batteryResp := resp.(batteryHistoryReply)
+	if batteryResp.Err != "" {
+		return nil, errors.New(batteryResp.Err)
+	}
+	return batteryResp.Samples, nil
+}
+
+func (e Endpoints) QueryTelemetry(ctx context.Context, id uint64, metric string, from int64, to int64) (TelemetrySeries, error) {
+	resp, err := e.QueryTelemetryEndpoint(ctx, queryTelemetryRequest{DeviceID: id, Metric: metric, From: from, To: to})
+	if err != nil {
+		return TelemetrySeries{}, err
+	}
+	queryResp := resp.(queryTelemetryReply)
+	if queryResp.Err != "" {
+		return TelemetrySeries{}, errors.New(queryResp.Err)
+	}
+	return queryResp.Series, nil
+}
+
+func (e Endpoints) AggregateTelemetry(ctx context.Context, query FleetQuery) (FleetAggregate, error) {
+	resp, err := e.AggregateTelemetryEndpoint(ctx, aggregateTelemetryRequest{Query: query})
+	if err != nil {
+		return FleetAggregate{}, err
+	}
+	aggregateResp := resp.(aggregateTelemetryReply)
+	if aggregateResp.Err != "" {
+		return FleetAggregate{}, errors.New(aggregateResp.Err)
+	}
+	return aggregateResp.Aggregate, nil
+}
+
+func (e Endpoints) SetLabels(ctx context.Context, id uint64, labels map[string]string) (bool, error) {
+	resp, err := e.SetLabelsEndpoint(ctx, setLabelsRequest{DeviceID: id, Labels: labels})
+	if err != nil {
+		return false, err
+	}
+	setResp := resp.(setLabelsReply)
+	if setResp.Err != "" {
+		return false, errors.New(setResp.Err)
+	}
+	return setResp.Acknowledged, nil
+}
+
+func (e Endpoints) ListStatuses(ctx context.Context, filter DeviceFilter) ([]DeviceStatus, error) {
+	resp, err := e.ListStatusesEndpoint(ctx, listStatusesRequest{Filter: filter})
+	if err != nil {
+		return nil, err
+	}
+	listResp := resp.(listStatusesReply)
+	if listResp.Err != "" {
+		return nil, errors.New(listResp.Err)
+	}
+	return listResp.Statuses, nil
+}
+
+func (e Endpoints) CreateGroup(ctx context.Context, group Group) (uint64, error) {
+	resp, err := e.CreateGroupEndpoint(ctx, createGroupRequest{Group: group})
 	if err != nil {
 		return 0, err
 	}
-	registerResp := resp.(registerReply)
-	if registerResp.Err != "" {
-		return 0, errors.New(registerResp.Err)
+	createResp := resp.(createGroupReply)
+	if createResp.Err != "" {
+		return 0, errors.New(createResp.Err)
 	}
-	return registerResp.DeviceID, nil
+	return createResp.GroupID, nil
 }
 
-func (e Endpoints) UpdateStatus(ctx context.Context, deviceId uint64, lat, long, alt float32, battery uint32) (bool, error) {
-	req := updateRequest{BatteryRemaining: battery, DeviceID: deviceId, Location: location{
+func (e Endpoints) UpdateGroup(ctx context.Context, group Group) (bool, error) {
+	resp, err := e.UpdateGroupEndpoint(ctx, updateGroupRequest{Group: group})
+	if err != nil {
+		return false, err
+	}
+	updateResp := resp.(updateGroupReply)
+	if updateResp.Err != "" {
+		return false, errors.New(updateResp.Err)
+	}
+	return updateResp.Acknowledged, nil
+}
+
+func (e Endpoints) DeleteGroup(ctx context.Context, id uint64) (bool, error) {
+	resp, err := e.DeleteGroupEndpoint(ctx, deleteGroupRequest{GroupID: id})
+	if err != nil {
+		return false, err
+	}
+	deleteResp := resp.(deleteGroupReply)
+	if deleteResp.Err != "" {
+		return false, errors.New(deleteResp.Err)
+	}
+	return deleteResp.Acknowledged, nil
+}
+
+func (e Endpoints) ListGroups(ctx context.Context) ([]Group, error) {
+	resp, err := e.ListGroupsEndpoint(ctx, listGroupsRequest{})
+	if err != nil {
+		return nil, err
+	}
+	listResp := resp.(listGroupsReply)
+	if listResp.Err != "" {
+		return nil, errors.New(listResp.Err)
+	}
+	return listResp.Groups, nil
+}
+
+func (e Endpoints) AddGroupMembers(ctx context.Context, groupID uint64, deviceIDs []uint64) (bool, error) {
+	resp, err := e.AddGroupMembersEndpoint(ctx, addGroupMembersRequest{GroupID: groupID, DeviceIDs: deviceIDs})
+	if err != nil {
+		return false, err
+	}
+	addResp := resp.(addGroupMembersReply)
+	if addResp.Err != "" {
+		return false, errors.New(addResp.Err)
+	}
+	return addResp.Acknowledged, nil
+}
+
+func (e Endpoints) RemoveGroupMembers(ctx context.Context, groupID uint64, deviceIDs []uint64) (bool, error) {
+	resp, err := e.RemoveGroupMembersEndpoint(ctx, removeGroupMembersRequest{GroupID: groupID, DeviceIDs: deviceIDs})
+	if err != nil {
+		return false, err
+	}
+	removeResp := resp.(removeGroupMembersReply)
+	if removeResp.Err != "" {
+		return false, errors.New(removeResp.Err)
+	}
+	return removeResp.Acknowledged, nil
+}
+
+func (e Endpoints) RelayStatus(ctx context.Context, gatewayID, id uint64, lat, long, alt float32, battery uint32) (bool, error) {
+	req := relayStatusRequest{GatewayID: gatewayID, updateRequest: updateRequest{BatteryRemaining: battery, DeviceID: id, Location: location{
 		Latitude: lat, Longitude: long, Altitude: alt},
+	}}
+	resp, err := e.RelayStatusEndpoint(ctx, req)
+	if err != nil {
+		return false, err
 	}
-	resp, err := e.UpdateEndpoint(ctx, req)
+	relayResp := resp.(relayStatusReply)
+	if relayResp.Err != "" {
+		return false, errors.New(relayResp.Err)
+	}
+	return relayResp.Acknowledged, nil
+}
+
+func (e Endpoints) RelayTelemetry(ctx context.Context, gatewayID, id uint64, readings map[string]TelemetryValue) (bool, []SchemaViolation, error) {
+	resp, err := e.RelayTelemetryEndpoint(ctx, relayTelemetryRequest{GatewayID: gatewayID, telemetryRequest: telemetryRequest{DeviceID: id, Values: readings}})
+	if err != nil {
+		return false, nil, err
+	}
+	relayResp := resp.(relayTelemetryReply)
+	if relayResp.Err != "" {
+		return false, relayResp.Violations, errors.New(relayResp.Err)
+	}
+	return relayResp.Acknowledged, relayResp.Violations, nil
+}
+
+func (e Endpoints) GetTopology(ctx context.Context, id uint64) (TopologyNode, error) {
+	resp, err := e.GetTopologyEndpoint(ctx, getTopologyRequest{DeviceID: id})
+	if err != nil {
+		return TopologyNode{}, err
+	}
+	getResp := resp.(getTopologyReply)
+	if getResp.Err != "" {
+		return TopologyNode{}, errors.New(getResp.Err)
+	}
+	return getResp.Topology, nil
+}
+
+func (e Endpoints) GetTwin(ctx context.Context, id uint64) (Twin, error) {
+	resp, err := e.GetTwinEndpoint(ctx, getTwinRequest{DeviceID: id})
+	if err != nil {
+		return Twin{}, err
+	}
+	getResp := resp.(getTwinReply)
+	if getResp.Err != "" {
+		return Twin{}, errors.New(getResp.Err)
+	}
+	return getResp.Twin, nil
+}
+
+func (e Endpoints) UpdateDesired(ctx context.Context, id uint64, patch TwinDocument, version int64) (Twin, error) {
+	resp, err := e.UpdateDesiredEndpoint(ctx, updateDesiredRequest{DeviceID: id, Patch: patch, Version: version})
+	if err != nil {
+		return Twin{}, err
+	}
+	updateResp := resp.(updateDesiredReply)
+	if updateResp.Err != "" {
+		return Twin{}, errors.New(updateResp.Err)
+	}
+	return updateResp.Twin, nil
+}
+
+func (e Endpoints) ReportState(ctx context.Context, id uint64, patch TwinDocument) (Twin, error) {
+	resp, err := e.ReportStateEndpoint(ctx, reportStateRequest{DeviceID: id, Patch: patch})
+	if err != nil {
+		return Twin{}, err
+	}
+	reportResp := resp.(reportStateReply)
+	if reportResp.Err != "" {
+		return Twin{}, errors.New(reportResp.Err)
+	}
+	return reportResp.Twin, nil
+}
+
+func (e Endpoints) DeliverDesired(ctx context.Context, id uint64) (*DesiredState, error) {
+	resp, err := e.DeliverDesiredEndpoint(ctx, deliverDesiredRequest{DeviceID: id})
+	if err != nil {
+		return nil, err
+	}
+	deliverResp := resp.(deliverDesiredReply)
+	if deliverResp.Err != "" {
+		return nil, errors.New(deliverResp.Err)
+	}
+	return deliverResp.Desired, nil
+}
+
+func (e Endpoints) EnqueueCommand(ctx context.Context, id uint64, name string, args map[string]interface{}, ttl int64) (uint64, error) {
+	resp, err := e.EnqueueCommandEndpoint(ctx, enqueueCommandRequest{DeviceID: id, Name: name, Args: args, TTL: ttl})
+	if err != nil {
+		return 0, err
+	}
+	enqueueResp := resp.(enqueueCommandReply)
+	if enqueueResp.Err != "" {
+		return 0, errors.New(enqueueResp.Err)
+	}
+	return enqueueResp.CommandID, nil
+}
+
+func (e Endpoints) AckCommand(ctx context.Context, id, commandID uint64, success bool, result string) (bool, error) {
+	resp, err := e.AckCommandEndpoint(ctx, ackCommandRequest{DeviceID: id, CommandID: commandID, Success: success, Result: result})
 	if err != nil {
 		return false, err
 	}
-	updateResp := resp.(updateReply)
+	ackResp := resp.(ackCommandReply)
+	if ackResp.Err != "" {
+		return false, errors.New(ackResp.Err)
+	}
+	return ackResp.Acknowledged, nil
+}
+
+func (e Endpoints) CommandHistory(ctx context.Context, id uint64, limit int) ([]Command, error) {
+	resp, err := e.CommandHistoryEndpoint(ctx, commandHistoryRequest{DeviceID: id, Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+	commandResp := resp.(commandHistoryReply)
+	if commandResp.Err != "" {
+		return nil, errors.New(commandResp.Err)
+	}
+	return commandResp.Commands, nil
+}
+
+func (e Endpoints) DeliverCommands(ctx context.Context, id uint64) ([]Command, error) {
+	resp, err := e.DeliverCommandsEndpoint(ctx, deliverCommandsRequest{DeviceID: id})
+	if err != nil {
+		return nil, err
+	}
+	deliverResp := resp.(deliverCommandsReply)
+	if deliverResp.Err != "" {
+		return nil, errors.New(deliverResp.Err)
+	}
+	return deliverResp.Commands, nil
+}
+
+func (e Endpoints) UploadFirmware(ctx context.Context, deviceType, version string, data []byte) (FirmwareArtifact, error) {
+	resp, err := e.UploadFirmwareEndpoint(ctx, uploadFirmwareRequest{DeviceType: deviceType, Version: version, Data: data})
+	if err != nil {
+		return FirmwareArtifact{}, err
+	}
+	uploadResp := resp.(uploadFirmwareReply)
+	if uploadResp.Err != "" {
+		return FirmwareArtifact{}, errors.New(uploadResp.Err)
+	}
+	return uploadResp.Artifact, nil
+}
+
+func (e Endpoints) ListFirmware(ctx context.Context) ([]FirmwareArtifact, error) {
+	resp, err := e.ListFirmwareEndpoint(ctx, listFirmwareRequest{})
+	if err != nil {
+		return nil, err
+	}
+	listResp := resp.(listFirmwareReply)
+	if listResp.Err != "" {
+		return nil, errors.New(listResp.Err)
+	}
+	return listResp.Artifacts, nil
+}
+
+func (e Endpoints) DownloadFirmware(ctx context.Context, id uint64) (FirmwareImage, error) {
+	resp, err := e.DownloadFirmwareEndpoint(ctx, downloadFirmwareRequest{FirmwareID: id})
+	if err != nil {
+		return FirmwareImage{}, err
+	}
+	downloadResp := resp.(downloadFirmwareReply)
+	if downloadResp.Err != "" {
+		return FirmwareImage{}, errors.New(downloadResp.Err)
+	}
+	return downloadResp.Image, nil
+}
+
+func (e Endpoints) CreateCampaign(ctx context.Context, campaign Campaign) (uint64, error) {
+	resp, err := e.CreateCampaignEndpoint(ctx, createCampaignRequest{Campaign: campaign})
+	if err != nil {
+		return 0, err
+	}
+	createResp := resp.(createCampaignReply)
+	if createResp.Err != "" {
+		return 0, errors.New(createResp.Err)
+	}
+	return createResp.CampaignID, nil
+}
+
+func (e Endpoints) GetCampaign(ctx context.Context, id uint64) (Campaign, error) {
+	resp, err := e.GetCampaignEndpoint(ctx, getCampaignRequest{CampaignID: id})
+	if err != nil {
+		return Campaign{}, err
+	}
+	getResp := resp.(getCampaignReply)
+	if getResp.Err != "" {
+		return Campaign{}, errors.New(getResp.Err)
+	}
+	return getResp.Campaign, nil
+}
+
+func (e Endpoints) ListCampaigns(ctx context.Context) ([]Campaign, error) {
+	resp, err := e.ListCampaignsEndpoint(ctx, listCampaignsRequest{})
+	if err != nil {
+		return nil, err
+	}
+	listResp := resp.(listCampaignsReply)
+	if listResp.Err != "" {
+		return nil, errors.New(listResp.Err)
+	}
+	return listResp.Campaigns, nil
+}
+
+func (e Endpoints) ControlCampaign(ctx context.Context, id uint64, action string) (Campaign, error) {
+	resp, err := e.ControlCampaignEndpoint(ctx, controlCampaignRequest{CampaignID: id, Action: action})
+	if err != nil {
+		return Campaign{}, err
+	}
+	controlResp := resp.(controlCampaignReply)
+	if controlResp.Err != "" {
+		return Campaign{}, errors.New(controlResp.Err)
+	}
+	return controlResp.Campaign, nil
+}
+
+func (e Endpoints) ReportFirmware(ctx context.Context, id uint64, report FirmwareReport) (bool, error) {
+	resp, err := e.ReportFirmwareEndpoint(ctx, reportFirmwareRequest{DeviceID: id, Report: report})
+	if err != nil {
+		return false, err
+	}
+	reportResp := resp.(reportFirmwareReply)
+	if reportResp.Err != "" {
+		return false, errors.New(reportResp.Err)
+	}
+	return reportResp.Acknowledged, nil
+}
+
+func (e Endpoints) OfferFirmware(ctx context.Context, id uint64) (*FirmwareOffer, error) {
+	resp, err := e.OfferFirmwareEndpoint(ctx, offerFirmwareRequest{DeviceID: id})
+	if err != nil {
+		return nil, err
+	}
+	offerResp := resp.(offerFirmwareReply)
+	if offerResp.Err != "" {
+		return nil, errors.New(offerResp.Err)
+	}
+	return offerResp.Offer, nil
+}
+
+func (e Endpoints) SetTelemetrySchema(ctx context.Context, schema TelemetrySchema) (bool, error) {
+	resp, err := e.SetTelemetrySchemaEndpoint(ctx, setTelemetrySchemaRequest{Schema: schema})
+	if err != nil {
+		return false, err
+	}
+	setResp := resp.(setTelemetrySchemaReply)
+	if setResp.Err != "" {
+		return false, errors.New(setResp.Err)
+	}
+	return setResp.Acknowledged, nil
+}
+
+func (e Endpoints) GetTelemetrySchema(ctx context.Context, deviceType string) (TelemetrySchema, error) {
+	resp, err := e.GetTelemetrySchemaEndpoint(ctx, getTelemetrySchemaRequest{DeviceType: deviceType})
+	if err != nil {
+		return TelemetrySchema{}, err
+	}
+	getResp := resp.(getTelemetrySchemaReply)
+	if getResp.Err != "" {
+		return TelemetrySchema{}, errors.New(getResp.Err)
+	}
+	return getResp.Schema, nil
+}
+
+func (e Endpoints) ListTelemetrySchemas(ctx context.Context) ([]TelemetrySchema, error) {
+	resp, err := e.ListTelemetrySchemasEndpoint(ctx, listTelemetrySchemasRequest{})
+	if err != nil {
+		return nil, err
+	}
+	listResp := resp.(listTelemetrySchemasReply)
+	if listResp.Err != "" {
+		return nil, errors.New(listResp.Err)
+	}
+	return listResp.Schemas, nil
+}
+
+func (e Endpoints) DeleteTelemetrySchema(ctx context.Context, deviceType string) (bool, error) {
+	resp, err := e.DeleteTelemetrySchemaEndpoint(ctx, deleteTelemetrySchemaRequest{DeviceType: deviceType})
+	if err != nil {
+		return false, err
+	}
+	deleteResp := resp.(deleteTelemetrySchemaReply)
+	if deleteResp.Err != "" {
+		return false, errors.New(deleteResp.Err)
+	}
+	return deleteResp.Acknowledged, nil
+}
+
+func (e Endpoints) ImportDevices(ctx context.Context, devices []DeviceImport, dryRun bool) ([]ImportResult, error) {
+	resp, err := e.ImportDevicesEndpoint(ctx, importDevicesRequest{Devices: devices, DryRun: dryRun})
+	if err != nil {
+		return nil, err
+	}
+	importResp := resp.(importDevicesReply)
+	if importResp.Err != "" {
+		return nil, errors.New(importResp.Err)
+	}
+	return importResp.Results, nil
+}
+
+func (e Endpoints) ExportDevices(ctx context.Context, filter DeviceFilter) ([]DeviceExport, error) {
+	resp, err := e.ExportDevicesEndpoint(ctx, exportDevicesRequest{Filter: filter})
+	if err != nil {
+		return nil, err
+	}
+	exportResp := resp.(exportDevicesReply)
+	if exportResp.Err != "" {
+		return nil, errors.New(exportResp.Err)
+	}
+	return exportResp.Devices, nil
+}
+
+func (e Endpoints) UpdateDevice(ctx context.Context, id uint64, name string, owner string) (bool, error) {
+	resp, err := e.UpdateDeviceEndpoint(ctx, updateDeviceRequest{DeviceID: id, Name: name, Owner: owner})
+	if err != nil {
+		return false, err
+	}
+	updateResp := resp.(updateDeviceReply)
 	if updateResp.Err != "" {
 		return false, errors.New(updateResp.Err)
 	}
 	return updateResp.Acknowledged, nil
 }
 
-func (e Endpoints) SubmitTelemetry(ctx context.Context, id uint64, readings map[string]float32) (bool, error) {
-	req := telemetryRequest{DeviceID: id, Readings: readings}
-	resp, err := e.TelemetryEndpoint(ctx, req)
+func (e Endpoints) DeleteDevice(ctx context.Context, id uint64) (bool, error) {
+	resp, err := e.DeleteDeviceEndpoint(ctx, deleteDeviceRequest{DeviceID: id})
 	if err != nil {
 		return false, err
 	}
-	telemetryResp := resp.(telemetryReply)
-	if telemetryResp.Err != "" {
-		return false, errors.New(telemetryResp.Err)
+	deleteResp := resp.(deleteDeviceReply)
+	if deleteResp.Err != "" {
+		return false, errors.New(deleteResp.Err)
 	}
-	return telemetryResp.Acknowledged, nil
+	return deleteResp.Acknowledged, nil
 }
@@ -0,0 +1,214 @@
+package iotmonitor
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Event types published by the service write path.
+const (
+	EventDeviceRegistered   = "device.registered"
+	EventDeviceUpdated      = "device.updated"
+	EventDeviceDeleted      = "device.deleted"
+	EventDeviceOnline       = "device.online"
+	EventDeviceOffline      = "device.offline"
+	EventDeviceUnreachable  = "device.unreachable"
+	EventDeviceReachable    = "device.reachable"
+	EventStatusUpdated      = "status.updated"
+	EventTelemetrySubmitted = "telemetry.submitted"
+	EventAlertFiring        = "alert.firing"
+	EventAlertResolved      = "alert.resolved"
+	EventGeofenceEntered    = "geofence.entered"
+	EventGeofenceExited     = "geofence.exited"
+	EventTwinDesiredUpdated = "twin.desired.updated"
+	EventTwinReported       = "twin.reported"
+	EventCommandQueued      = "command.queued"
+	EventCommandCompleted   = "command.completed"
+	EventFirmwareProgress   = "firmware.progress"
+	EventCampaignPaused     = "campaign.paused"
+)
+
+// Event is a notification that something happened to a device.
+type Event struct {
+	ID        uint64      `json:"id"`
+	Type      string      `json:"type"`
+	DeviceID  uint64      `json:"device_id"`
+	Timestamp int64       `json:"timestamp"`
+	Data      interface{} `json:"data,omitempty"`
+}
+
+// EventBus fans events out to in-process subscribers. Publishing never
+// blocks: a subscriber that falls behind its buffer misses events, unless it
+// subscribed unbounded.
+type EventBus struct {
+	mu      sync.RWMutex
+	subs    map[int]chan Event
+	queues  map[int]*eventQueue
+	nextID  int
+	seq     uint64
+	dropped uint64
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{subs: make(map[int]chan Event), queues: make(map[int]*eventQueue)}
+}
+
+// Publish stamps the event with a sequence number and delivers it to every
+// subscriber. A nil bus discards events.
+func (b *EventBus) Publish(e Event) {
+	if b == nil {
+		return
+	}
+	e.ID = atomic.AddUint64(&b.seq, 1)
+	if e.Timestamp == 0 {
+		e.Timestamp = makeTimestamp()
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, ch := range b.subs {
+		select {
+		case ch <- e:
+		default:
+			atomic.AddUint64(&b.dropped, 1)
+		}
+	}
+	for _, q := range b.queues {
+		q.push(e)
+	}
+}
+
+// EventBusStats counts a bus's subscribers and the events it has handled.
+type EventBusStats struct {
+	Subscribers int    `json:"subscribers"`
+	Published   uint64 `json:"published"`
+	Dropped     uint64 `json:"dropped"` // deliveries missed by subscribers that fell behind
+	Queued      int    `json:"queued"`  // events waiting for unbounded subscribers
+}
+
+// Stats returns the bus's current counts.
+func (b *EventBus) Stats() EventBusStats {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	queued := 0
+	for _, q := range b.queues {
+		queued += q.len()
+	}
+	return EventBusStats{
+		Subscribers: len(b.subs) + len(b.queues),
+		Published:   atomic.LoadUint64(&b.seq),
+		Dropped:     atomic.LoadUint64(&b.dropped),
+		Queued:      queued,
+	}
+}
+
+// Subscribe returns a channel of events and a function that cancels the
+// subscription and closes the channel.
+func (b *EventBus) Subscribe(buffer int) (<-chan Event, func()) {
+	ch := make(chan Event, buffer)
+	b.mu.Lock()
+	id := b.nextID
+	b.nextID++
+	b.subs[id] = ch
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, id)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// SubscribeUnbounded is like Subscribe, but queues events for as long as the
+// subscriber takes rather than miss any. Cancelling it closes the channel
+// once the events already queued have been received, so the subscriber must
+// keep receiving until then.
+func (b *EventBus) SubscribeUnbounded() (<-chan Event, func()) {
+	q := &eventQueue{ready: make(chan struct{}, 1)}
+	ch := make(chan Event)
+	b.mu.Lock()
+	id := b.nextID
+	b.nextID++
+	b.queues[id] = q
+	b.mu.Unlock()
+	go q.forward(ch)
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.queues, id)
+			b.mu.Unlock()
+			q.close()
+		})
+	}
+}
+
+// eventQueue is an unbounded FIFO of events for one subscriber.
+type eventQueue struct {
+	mu     sync.Mutex
+	events []Event
+	closed bool
+	ready  chan struct{}
+}
+
+func (q *eventQueue) push(e Event) {
+	q.mu.Lock()
+	q.events = append(q.events, e)
+	q.mu.Unlock()
+	q.wake()
+}
+
+func (q *eventQueue) close() {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+	q.wake()
+}
+
+func (q *eventQueue) wake() {
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+func (q *eventQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.events)
+}
+
+// forward sends the queued events to ch in order, closing it once the queue
+// is closed and empty.
+func (q *eventQueue) forward(ch chan<- Event) {
+	for {
+		q.mu.Lock()
+		if len(q.events) == 0 {
+			closed := q.closed
+			q.mu.Unlock()
+			if closed {
+				close(ch)
+				return
+			}
+			<-q.ready
+			continue
+		}
+		e := q.events[0]
+		q.events[0] = Event{}
+		q.events = q.events[1:]
+		q.mu.Unlock()
+		ch <- e
+	}
+}
+
+func alertEvent(a Alert) Event {
+	t := EventAlertResolved
+	if a.State == AlertFiring {
+		t = EventAlertFiring
+	}
+	return Event{Type: t, DeviceID: a.DeviceID, Data: a}
+}
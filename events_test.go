@@ -0,0 +1,62 @@
+package iotmonitor
+
+import "testing"
+
+func TestSubscribeDropsWhenFull(t *testing.T) {
+	bus := NewEventBus()
+	events, cancel := bus.Subscribe(2)
+	defer cancel()
+	for i := 0; i < 5; i++ {
+		bus.Publish(Event{Type: EventStatusUpdated})
+	}
+	if got := len(events); got != 2 {
+		t.Errorf("buffered %d events, want 2", got)
+	}
+	if got := bus.Stats().Dropped; got != 3 {
+		t.Errorf("Dropped = %d, want 3", got)
+	}
+}
+
+func TestSubscribeUnboundedKeepsEverything(t *testing.T) {
+	const n = 5000
+	bus := NewEventBus()
+	events, cancel := bus.SubscribeUnbounded()
+	for i := 0; i < n; i++ {
+		bus.Publish(Event{Type: EventStatusUpdated, DeviceID: uint64(i)})
+	}
+	cancel()
+	bus.Publish(Event{Type: EventStatusUpdated, DeviceID: n})
+
+	var got uint64
+	for e := range events {
+		if e.DeviceID != got {
+			t.Fatalf("event %d has device %d, want events in order", got, e.DeviceID)
+		}
+		got++
+	}
+	if got != n {
+		t.Errorf("received %d events, want %d", got, n)
+	}
+	stats := bus.Stats()
+	if stats.Dropped != 0 || stats.Subscribers != 0 || stats.Queued != 0 {
+		t.Errorf("Stats() = %+v, want nothing dropped, subscribed or queued", stats)
+	}
+}
+
+func TestPublishStampsEvents(t *testing.T) {
+	bus := NewEventBus()
+	events, cancel := bus.Subscribe(2)
+	defer cancel()
+	bus.Publish(Event{Type: EventDeviceOnline})
+	bus.Publish(Event{Type: EventDeviceOffline, Timestamp: 42})
+	first, second := <-events, <-events
+	if first.ID != 1 || second.ID != 2 {
+		t.Errorf("IDs = %d, %d, want 1, 2", first.ID, second.ID)
+	}
+	if first.Timestamp == 0 {
+		t.Error("unstamped event got no timestamp")
+	}
+	if second.Timestamp != 42 {
+		t.Errorf("Timestamp = %d, want the published 42", second.Timestamp)
+	}
+}
@@ -0,0 +1,526 @@
+package iotmonitor
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+// fakeRedis is an in-memory redis.Conn implementing the commands the
+// package's Redis helpers use, for testing them without a server. abortExec
+// makes that many EXECs fail as if a watched key had changed.
+type fakeRedis struct {
+	strings map[string]string
+	hashes  map[string]map[string]string
+	sets    map[string]map[string]bool
+	zsets   map[string]map[string]float64
+	lists   map[string][]string
+	// geo holds the [long, lat] of geo set members, which are zset
+	// members as in Redis.
+	geo map[string]map[string][2]float64
+
+	multi     bool
+	queued    [][]string
+	pending   []interface{}
+	abortExec int
+}
+
+func newFakeRedis() *fakeRedis {
+	return &fakeRedis{
+		strings: make(map[string]string),
+		hashes:  make(map[string]map[string]string),
+		sets:    make(map[string]map[string]bool),
+		zsets:   make(map[string]map[string]float64),
+		lists:   make(map[string][]string),
+		geo:     make(map[string]map[string][2]float64),
+	}
+}
+
+func (f *fakeRedis) Close() error { return nil }
+func (f *fakeRedis) Err() error   { return nil }
+func (f *fakeRedis) Flush() error { return nil }
+
+func (f *fakeRedis) Send(cmd string, args ...interface{}) error {
+	if f.multi {
+		f.queued = append(f.queued, f.command(cmd, args))
+		return nil
+	}
+	if strings.EqualFold(cmd, "MULTI") {
+		f.multi = true
+		return nil
+	}
+	reply, err := f.Do(cmd, args...)
+	if err != nil {
+		reply = redis.Error(err.Error())
+	}
+	f.pending = append(f.pending, reply)
+	return nil
+}
+
+func (f *fakeRedis) Receive() (interface{}, error) {
+	if len(f.pending) == 0 {
+		return nil, errors.New("fakeRedis: nothing to receive")
+	}
+	reply := f.pending[0]
+	f.pending = f.pending[1:]
+	if err, ok := reply.(redis.Error); ok {
+		return nil, err
+	}
+	return reply, nil
+}
+
+func (f *fakeRedis) command(cmd string, args []interface{}) []string {
+	words := []string{strings.ToUpper(cmd)}
+	for _, a := range args {
+		switch v := a.(type) {
+		case []byte:
+			words = append(words, string(v))
+		case bool:
+			if v {
+				words = append(words, "1")
+			} else {
+				words = append(words, "0")
+			}
+		case float32:
+			words = append(words, strconv.FormatFloat(float64(v), 'g', -1, 32))
+		case float64:
+			words = append(words, strconv.FormatFloat(v, 'g', -1, 64))
+		default:
+			words = append(words, fmt.Sprint(v))
+		}
+	}
+	return words
+}
+
+func (f *fakeRedis) Do(cmd string, args ...interface{}) (interface{}, error) {
+	if cmd == "" {
+		return nil, nil
+	}
+	words := f.command(cmd, args)
+	switch words[0] {
+	case "MULTI":
+		f.multi = true
+		return "OK", nil
+	case "EXEC":
+		queued := f.queued
+		f.multi, f.queued = false, nil
+		if f.abortExec > 0 {
+			f.abortExec--
+			return nil, nil
+		}
+		replies := make([]interface{}, len(queued))
+		for i, q := range queued {
+			reply, err := f.exec(q)
+			if err != nil {
+				reply = redis.Error(err.Error())
+			}
+			replies[i] = reply
+		}
+		return replies, nil
+	case "DISCARD":
+		f.multi, f.queued = false, nil
+		return "OK", nil
+	}
+	if f.multi {
+		f.queued = append(f.queued, words)
+		return "QUEUED", nil
+	}
+	return f.exec(words)
+}
+
+func (f *fakeRedis) hash(key string) map[string]string {
+	h, ok := f.hashes[key]
+	if !ok {
+		h = make(map[string]string)
+		f.hashes[key] = h
+	}
+	return h
+}
+
+func (f *fakeRedis) set(key string) map[string]bool {
+	s, ok := f.sets[key]
+	if !ok {
+		s = make(map[string]bool)
+		f.sets[key] = s
+	}
+	return s
+}
+
+func (f *fakeRedis) exists(key string) bool {
+	_, s := f.strings[key]
+	_, h := f.hashes[key]
+	_, st := f.sets[key]
+	_, z := f.zsets[key]
+	_, l := f.lists[key]
+	return s || h || st || z || l
+}
+
+func (f *fakeRedis) tidy(key string) {
+	if h, ok := f.hashes[key]; ok && len(h) == 0 {
+		delete(f.hashes, key)
+	}
+	if s, ok := f.sets[key]; ok && len(s) == 0 {
+		delete(f.sets, key)
+	}
+	if z, ok := f.zsets[key]; ok && len(z) == 0 {
+		delete(f.zsets, key)
+	}
+	if l, ok := f.lists[key]; ok && len(l) == 0 {
+		delete(f.lists, key)
+	}
+}
+
+func bulk(values []string) []interface{} {
+	out := make([]interface{}, len(values))
+	for i, v := range values {
+		out[i] = []byte(v)
+	}
+	return out
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (f *fakeRedis) exec(w []string) (interface{}, error) {
+	cmd, args := w[0], w[1:]
+	switch cmd {
+	case "PING":
+		return "PONG", nil
+	case "WATCH", "UNWATCH":
+		return "OK", nil
+	case "GET":
+		v, ok := f.strings[args[0]]
+		if !ok {
+			return nil, nil
+		}
+		return []byte(v), nil
+	case "SET":
+		f.strings[args[0]] = args[1]
+		return "OK", nil
+	case "INCR", "INCRBY":
+		by := int64(1)
+		if cmd == "INCRBY" {
+			by, _ = strconv.ParseInt(args[1], 10, 64)
+		}
+		n, _ := strconv.ParseInt(f.strings[args[0]], 10, 64)
+		n += by
+		f.strings[args[0]] = strconv.FormatInt(n, 10)
+		return n, nil
+	case "EXISTS":
+		var n int64
+		for _, k := range args {
+			if f.exists(k) {
+				n++
+			}
+		}
+		return n, nil
+	case "DEL":
+		var n int64
+		for _, k := range args {
+			if f.exists(k) {
+				n++
+			}
+			delete(f.strings, k)
+			delete(f.hashes, k)
+			delete(f.sets, k)
+			delete(f.zsets, k)
+			delete(f.lists, k)
+		}
+		return n, nil
+
+	case "HSET", "HMSET":
+		h := f.hash(args[0])
+		var n int64
+		for i := 1; i+1 < len(args); i += 2 {
+			if _, ok := h[args[i]]; !ok {
+				n++
+			}
+			h[args[i]] = args[i+1]
+		}
+		if cmd == "HMSET" {
+			return "OK", nil
+		}
+		return n, nil
+	case "HSETNX":
+		h := f.hash(args[0])
+		if _, ok := h[args[1]]; ok {
+			return int64(0), nil
+		}
+		h[args[1]] = args[2]
+		return int64(1), nil
+	case "HGET":
+		v, ok := f.hashes[args[0]][args[1]]
+		if !ok {
+			return nil, nil
+		}
+		return []byte(v), nil
+	case "HGETALL":
+		h := f.hashes[args[0]]
+		fields := make(map[string]bool, len(h))
+		for k := range h {
+			fields[k] = true
+		}
+		var out []string
+		for _, k := range sortedKeys(fields) {
+			out = append(out, k, h[k])
+		}
+		return bulk(out), nil
+	case "HMGET":
+		out := make([]interface{}, len(args)-1)
+		for i, field := range args[1:] {
+			if v, ok := f.hashes[args[0]][field]; ok {
+				out[i] = []byte(v)
+			}
+		}
+		return out, nil
+	case "HVALS":
+		h := f.hashes[args[0]]
+		fields := make(map[string]bool, len(h))
+		for k := range h {
+			fields[k] = true
+		}
+		var out []string
+		for _, k := range sortedKeys(fields) {
+			out = append(out, h[k])
+		}
+		return bulk(out), nil
+	case "HEXISTS":
+		if _, ok := f.hashes[args[0]][args[1]]; ok {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	case "HDEL":
+		var n int64
+		for _, field := range args[1:] {
+			if _, ok := f.hashes[args[0]][field]; ok {
+				delete(f.hashes[args[0]], field)
+				n++
+			}
+		}
+		f.tidy(args[0])
+		return n, nil
+	case "HINCRBY":
+		h := f.hash(args[0])
+		n, _ := strconv.ParseInt(h[args[1]], 10, 64)
+		by, _ := strconv.ParseInt(args[2], 10, 64)
+		n += by
+		h[args[1]] = strconv.FormatInt(n, 10)
+		return n, nil
+
+	case "SADD":
+		s := f.set(args[0])
+		var n int64
+		for _, m := range args[1:] {
+			if !s[m] {
+				s[m] = true
+				n++
+			}
+		}
+		return n, nil
+	case "SREM":
+		var n int64
+		for _, m := range args[1:] {
+			if f.sets[args[0]][m] {
+				delete(f.sets[args[0]], m)
+				n++
+			}
+		}
+		f.tidy(args[0])
+		return n, nil
+	case "SMEMBERS":
+		return bulk(sortedKeys(f.sets[args[0]])), nil
+	case "SISMEMBER":
+		if f.sets[args[0]][args[1]] {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	case "SCARD":
+		return int64(len(f.sets[args[0]])), nil
+	case "SORT":
+		members := sortedKeys(f.sets[args[0]])
+		sort.SliceStable(members, func(i, j int) bool {
+			a, _ := strconv.ParseFloat(members[i], 64)
+			b, _ := strconv.ParseFloat(members[j], 64)
+			return a < b
+		})
+		return bulk(members), nil
+
+	case "ZADD":
+		z, ok := f.zsets[args[0]]
+		if !ok {
+			z = make(map[string]float64)
+			f.zsets[args[0]] = z
+		}
+		var n int64
+		for i := 1; i+1 < len(args); i += 2 {
+			score, err := strconv.ParseFloat(args[i], 64)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := z[args[i+1]]; !ok {
+				n++
+			}
+			z[args[i+1]] = score
+		}
+		return n, nil
+	case "ZREM":
+		var n int64
+		for _, m := range args[1:] {
+			if _, ok := f.zsets[args[0]][m]; ok {
+				delete(f.zsets[args[0]], m)
+				n++
+			}
+		}
+		f.tidy(args[0])
+		return n, nil
+	case "ZSCORE":
+		score, ok := f.zsets[args[0]][args[1]]
+		if !ok {
+			return nil, nil
+		}
+		return []byte(strconv.FormatFloat(score, 'g', -1, 64)), nil
+	case "GEOADD":
+		z, ok := f.zsets[args[0]]
+		if !ok {
+			z = make(map[string]float64)
+			f.zsets[args[0]] = z
+		}
+		g, ok := f.geo[args[0]]
+		if !ok {
+			g = make(map[string][2]float64)
+			f.geo[args[0]] = g
+		}
+		var n int64
+		for i := 1; i+2 < len(args); i += 3 {
+			long, _ := strconv.ParseFloat(args[i], 64)
+			lat, _ := strconv.ParseFloat(args[i+1], 64)
+			if _, ok := z[args[i+2]]; !ok {
+				n++
+			}
+			z[args[i+2]] = 0
+			g[args[i+2]] = [2]float64{long, lat}
+		}
+		return n, nil
+	case "GEORADIUS":
+		// Only the "m WITHDIST WITHCOORD ASC" form the package uses.
+		long, _ := strconv.ParseFloat(args[1], 64)
+		lat, _ := strconv.ParseFloat(args[2], 64)
+		radius, _ := strconv.ParseFloat(args[3], 64)
+		type hit struct {
+			member string
+			dist   float64
+			pos    [2]float64
+		}
+		var hits []hit
+		for m := range f.zsets[args[0]] {
+			pos := f.geo[args[0]][m]
+			if d := haversine(lat, long, pos[1], pos[0]); d <= radius {
+				hits = append(hits, hit{m, d, pos})
+			}
+		}
+		sort.Slice(hits, func(i, j int) bool { return hits[i].dist < hits[j].dist })
+		out := make([]interface{}, len(hits))
+		for i, h := range hits {
+			out[i] = []interface{}{
+				[]byte(h.member),
+				[]byte(strconv.FormatFloat(h.dist, 'f', 4, 64)),
+				bulk([]string{strconv.FormatFloat(h.pos[0], 'g', -1, 64), strconv.FormatFloat(h.pos[1], 'g', -1, 64)}),
+			}
+		}
+		return out, nil
+	case "ZRANGE", "ZRANGEBYSCORE":
+		z := f.zsets[args[0]]
+		members := make([]string, 0, len(z))
+		for m := range z {
+			members = append(members, m)
+		}
+		sort.Slice(members, func(i, j int) bool {
+			if z[members[i]] != z[members[j]] {
+				return z[members[i]] < z[members[j]]
+			}
+			return members[i] < members[j]
+		})
+		if cmd == "ZRANGE" {
+			start, _ := strconv.Atoi(args[1])
+			stop, _ := strconv.Atoi(args[2])
+			return bulk(sliceRange(members, start, stop)), nil
+		}
+		lo, hi := parseScore(args[1]), parseScore(args[2])
+		var out []string
+		for _, m := range members {
+			if z[m] >= lo && z[m] <= hi {
+				out = append(out, m)
+			}
+		}
+		return bulk(out), nil
+
+	case "LPUSH":
+		for _, v := range args[1:] {
+			f.lists[args[0]] = append([]string{v}, f.lists[args[0]]...)
+		}
+		return int64(len(f.lists[args[0]])), nil
+	case "RPUSH":
+		f.lists[args[0]] = append(f.lists[args[0]], args[1:]...)
+		return int64(len(f.lists[args[0]])), nil
+	case "LRANGE":
+		start, _ := strconv.Atoi(args[1])
+		stop, _ := strconv.Atoi(args[2])
+		return bulk(sliceRange(f.lists[args[0]], start, stop)), nil
+	case "LTRIM":
+		start, _ := strconv.Atoi(args[1])
+		stop, _ := strconv.Atoi(args[2])
+		f.lists[args[0]] = sliceRange(f.lists[args[0]], start, stop)
+		f.tidy(args[0])
+		return "OK", nil
+	case "LLEN":
+		return int64(len(f.lists[args[0]])), nil
+	}
+	return nil, fmt.Errorf("fakeRedis: unsupported command %s", cmd)
+}
+
+func parseScore(s string) float64 {
+	switch s {
+	case "-inf":
+		return -1e308
+	case "+inf", "inf":
+		return 1e308
+	}
+	f, _ := strconv.ParseFloat(s, 64)
+	return f
+}
+
+// sliceRange applies Redis's inclusive, negative-from-the-end indexes.
+func sliceRange(values []string, start, stop int) []string {
+	n := len(values)
+	if start < 0 {
+		start += n
+	}
+	if stop < 0 {
+		stop += n
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= n {
+		stop = n - 1
+	}
+	if start > stop {
+		return nil
+	}
+	return append([]string(nil), values[start:stop+1]...)
+}
+
+// redisArgs flattens a struct into the arguments of an HMSET of key.
+func redisArgs(key string, v interface{}) []interface{} {
+	return redis.Args{}.Add(key).AddFlat(v)
+}
@@ -0,0 +1,699 @@
+package iotmonitor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/garyburd/redigo/redis"
+	"golang.org/x/net/context"
+)
+
+// Firmware states a device reports while applying an update
+const (
+	FirmwareDownloading = "downloading"
+	FirmwareInstalling  = "installing"
+	FirmwareInstalled   = "installed"
+	FirmwareFailed      = "failed"
+)
+
+// Campaign states
+const (
+	CampaignActive = "active"
+	CampaignPaused = "paused"
+)
+
+// Campaign actions
+const (
+	CampaignPause   = "pause"
+	CampaignResume  = "resume"
+	CampaignAdvance = "advance"
+)
+
+const (
+	maxFirmwareSize = 64 << 20
+
+	firmwareKey         = "firmware"
+	firmwareVersionsKey = "firmware:versions"
+	campaignsKey        = "campaigns"
+
+	firmwareRetries = 3
+
+	defaultFailureThreshold = 0.2
+	defaultMinReports       = 5
+)
+
+// FirmwareArtifact describes a firmware image stored on the monitor's disk.
+type FirmwareArtifact struct {
+	ID         uint64 `json:"id"`
+	DeviceType string `json:"device_type"`
+	Version    string `json:"version"`
+	Checksum   string `json:"checksum"` // hex SHA-256 of the image
+	Size       int64  `json:"size"`
+	CreatedAt  int64  `json:"created_at"`
+}
+
+// FirmwareImage is an artifact along with its contents.
+type FirmwareImage struct {
+	Artifact FirmwareArtifact `json:"artifact"`
+	Data     []byte           `json:"-"`
+}
+
+// Campaign rolls a firmware artifact out to the devices of its device type,
+// optionally narrowed by a label selector. Firmware is built for one device
+// type, so every campaign targets the type of its artifact: DeviceType may be
+// left empty when creating one, and is refused if it names another type. A
+// selector can't widen a campaign to other types. Stages lists the cumulative
+// percentage of targeted devices offered the update at each step, e.g.
+// [5, 25, 100]; the campaign starts at the first stage and is advanced by
+// an operator. It pauses itself once more than FailureThreshold of at least
+// MinReports finished installs have failed.
+type Campaign struct {
+	ID               uint64         `json:"id"`
+	Name             string         `json:"name"`
+	FirmwareID       uint64         `json:"firmware_id"`
+	DeviceType       string         `json:"device_type"`
+	Version          string         `json:"version"`
+	Selector         string         `json:"selector,omitempty"`
+	Stages           []int          `json:"stages"`
+	Stage            int            `json:"stage"`
+	FailureThreshold float64        `json:"failure_threshold"`
+	MinReports       int            `json:"min_reports"`
+	Status           string         `json:"status"`
+	CreatedAt        int64          `json:"created_at"`
+	Stats            *CampaignStats `json:"stats,omitempty"`
+}
+
+// CampaignStats counts a campaign's devices by progress.
+type CampaignStats struct {
+	Targeted    int     `json:"targeted"`
+	Offered     int     `json:"offered"` // targeted devices within the current stage
+	Downloading int     `json:"downloading"`
+	Installing  int     `json:"installing"`
+	Installed   int     `json:"installed"`
+	Failed      int     `json:"failed"`
+	FailureRate float64 `json:"failure_rate"`
+}
+
+// FirmwareReport is the firmware state a device sends with a status update.
+// Version is the firmware the device is running; CampaignID and State report
+// progress on an update it was offered.
+type FirmwareReport struct {
+	Version    string `json:"version,omitempty"`
+	CampaignID uint64 `json:"campaign_id,omitempty"`
+	State      string `json:"state,omitempty"`
+	Progress   int    `json:"progress,omitempty"` // percent
+	Error      string `json:"error,omitempty"`
+}
+
+// FirmwareOffer tells a device about an update it should install.
+type FirmwareOffer struct {
+	CampaignID uint64 `json:"campaign_id"`
+	FirmwareID uint64 `json:"firmware_id"`
+	Version    string `json:"version"`
+	Checksum   string `json:"checksum"`
+	Size       int64  `json:"size"`
+}
+
+var (
+	errFirmwareNotFound = errors.New("firmware not found")
+	errFirmwareCorrupt  = errors.New("firmware image does not match its checksum")
+	errCampaignNotFound = errors.New("campaign not found")
+	errCampaignInvalid  = errors.New("invalid campaign")
+
+	errFirmwareConcurrentWrite = errors.New("firmware versions were modified concurrently, retry")
+	errCampaignConcurrentWrite = errors.New("campaign was modified concurrently, retry")
+)
+
+// firmwareStore keeps firmware images as files in a directory.
+type firmwareStore struct {
+	dir string
+}
+
+func (f firmwareStore) path(id uint64) string {
+	return filepath.Join(f.dir, fmt.Sprintf("%d.bin", id))
+}
+
+func campaignDevicesKey(id uint64) string {
+	return fmt.Sprintf("campaign:%d:devices", id)
+}
+
+// campaignsByTypeKey indexes the campaigns for a device type.
+func campaignsByTypeKey(deviceType string) string {
+	return fmt.Sprintf("campaigns:type:%s", deviceType)
+}
+
+func readArtifact(c redis.Conn, id uint64) (FirmwareArtifact, error) {
+	var a FirmwareArtifact
+	b, err := redis.Bytes(c.Do("HGET", firmwareKey, id))
+	if err == redis.ErrNil {
+		return a, errFirmwareNotFound
+	}
+	if err != nil {
+		return a, err
+	}
+	err = json.Unmarshal(b, &a)
+	return a, err
+}
+
+// UploadFirmware stores a firmware image for a device type. Versions are
+// unique per device type. The image is written before the artifact is
+// recorded, so an artifact never refers to a missing image, and is removed
+// again if the artifact can't be recorded.
+func (s monitorService) UploadFirmware(ctx context.Context, deviceType, version string, data []byte) (FirmwareArtifact, error) {
+	if strings.TrimSpace(deviceType) == "" || strings.TrimSpace(version) == "" {
+		return FirmwareArtifact{}, errors.New("firmware device type and version are required")
+	}
+	if len(data) == 0 || len(data) > maxFirmwareSize {
+		return FirmwareArtifact{}, fmt.Errorf("firmware image must be between 1 and %d bytes", maxFirmwareSize)
+	}
+
+	c, err := s.store.dial()
+	if err != nil {
+		return FirmwareArtifact{}, err
+	}
+	defer c.Close()
+
+	sum := sha256.Sum256(data)
+	a := FirmwareArtifact{DeviceType: deviceType, Version: version, Checksum: hex.EncodeToString(sum[:]), Size: int64(len(data)), CreatedAt: makeTimestamp()}
+	a.ID, err = redis.Uint64(c.Do("INCR", "id:firmware"))
+	if err != nil {
+		return FirmwareArtifact{}, err
+	}
+
+	if err := os.MkdirAll(s.firmware.dir, 0755); err != nil {
+		return FirmwareArtifact{}, err
+	}
+	path := s.firmware.path(a.ID)
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		os.Remove(path)
+		return FirmwareArtifact{}, err
+	}
+	if err := addArtifact(c, a); err != nil {
+		os.Remove(path)
+		return FirmwareArtifact{}, err
+	}
+	return a, nil
+}
+
+// addArtifact records an artifact and reserves its version for its device
+// type in one transaction, failing if the version is already taken.
+func addArtifact(c redis.Conn, a FirmwareArtifact) error {
+	b, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	version := a.DeviceType + "/" + a.Version
+	for i := 0; i < firmwareRetries; i++ {
+		if _, err := c.Do("WATCH", firmwareVersionsKey); err != nil {
+			return err
+		}
+		taken, err := redis.Bool(c.Do("HEXISTS", firmwareVersionsKey, version))
+		if err != nil {
+			c.Do("UNWATCH")
+			return err
+		}
+		if taken {
+			c.Do("UNWATCH")
+			return fmt.Errorf("firmware %s already exists for %s", a.Version, a.DeviceType)
+		}
+
+		c.Send("MULTI")
+		c.Send("HSET", firmwareVersionsKey, version, a.ID)
+		c.Send("HSET", firmwareKey, a.ID, b)
+		reply, err := c.Do("EXEC")
+		if err != nil {
+			return err
+		}
+		if reply != nil {
+			return nil
+		}
+	}
+	return errFirmwareConcurrentWrite
+}
+
+func (s monitorService) ListFirmware(ctx context.Context) ([]FirmwareArtifact, error) {
+	c, err := s.store.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	raw, err := redis.ByteSlices(c.Do("HVALS", firmwareKey))
+	if err != nil {
+		return nil, err
+	}
+	artifacts := make([]FirmwareArtifact, 0, len(raw))
+	for _, b := range raw {
+		var a FirmwareArtifact
+		if err := json.Unmarshal(b, &a); err != nil {
+			return nil, err
+		}
+		artifacts = append(artifacts, a)
+	}
+	sort.Slice(artifacts, func(i, j int) bool { return artifacts[i].ID < artifacts[j].ID })
+	return artifacts, nil
+}
+
+// DownloadFirmware reads a firmware image, verifying it against its
+// checksum.
+func (s monitorService) DownloadFirmware(ctx context.Context, id uint64) (FirmwareImage, error) {
+	c, err := s.store.dial()
+	if err != nil {
+		return FirmwareImage{}, err
+	}
+	defer c.Close()
+
+	a, err := readArtifact(c, id)
+	if err != nil {
+		return FirmwareImage{}, err
+	}
+	data, err := ioutil.ReadFile(s.firmware.path(id))
+	if err != nil {
+		return FirmwareImage{}, err
+	}
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != a.Checksum {
+		return FirmwareImage{}, errFirmwareCorrupt
+	}
+	return FirmwareImage{Artifact: a, Data: data}, nil
+}
+
+func readCampaign(c redis.Conn, id uint64) (Campaign, error) {
+	var cp Campaign
+	b, err := redis.Bytes(c.Do("HGET", campaignsKey, id))
+	if err == redis.ErrNil {
+		return cp, errCampaignNotFound
+	}
+	if err != nil {
+		return cp, err
+	}
+	err = json.Unmarshal(b, &cp)
+	return cp, err
+}
+
+func readCampaigns(c redis.Conn) ([]Campaign, error) {
+	raw, err := redis.ByteSlices(c.Do("HVALS", campaignsKey))
+	if err != nil {
+		return nil, err
+	}
+	campaigns := make([]Campaign, 0, len(raw))
+	for _, b := range raw {
+		var cp Campaign
+		if err := json.Unmarshal(b, &cp); err != nil {
+			return nil, err
+		}
+		campaigns = append(campaigns, cp)
+	}
+	sort.Slice(campaigns, func(i, j int) bool { return campaigns[i].ID < campaigns[j].ID })
+	return campaigns, nil
+}
+
+func writeCampaign(c redis.Conn, cp Campaign) error {
+	cp.Stats = nil
+	b, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	_, err = c.Do("HSET", campaignsKey, cp.ID, b)
+	return err
+}
+
+// addCampaign writes a new campaign and indexes it by its device type.
+func addCampaign(c redis.Conn, cp Campaign) error {
+	c.Send("MULTI")
+	if err := writeCampaign(c, cp); err != nil {
+		c.Do("DISCARD")
+		return err
+	}
+	c.Send("SADD", campaignsByTypeKey(cp.DeviceType), cp.ID)
+	_, err := c.Do("EXEC")
+	return err
+}
+
+// readCampaignsFor returns the campaigns for a device type.
+func readCampaignsFor(c redis.Conn, deviceType string) ([]Campaign, error) {
+	ids, err := redis.Values(c.Do("SORT", campaignsByTypeKey(deviceType)))
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+	raw, err := redis.ByteSlices(c.Do("HMGET", redis.Args{}.Add(campaignsKey).Add(ids...)...))
+	if err != nil {
+		return nil, err
+	}
+	campaigns := make([]Campaign, 0, len(raw))
+	for _, b := range raw {
+		if b == nil {
+			continue
+		}
+		var cp Campaign
+		if err := json.Unmarshal(b, &cp); err != nil {
+			return nil, err
+		}
+		campaigns = append(campaigns, cp)
+	}
+	return campaigns, nil
+}
+
+func (cp *Campaign) validate() error {
+	if strings.TrimSpace(cp.Name) == "" {
+		return fmt.Errorf("%v: name is required", errCampaignInvalid)
+	}
+	if len(cp.Stages) == 0 {
+		cp.Stages = []int{100}
+	}
+	for i, pct := range cp.Stages {
+		if pct < 1 || pct > 100 || (i > 0 && pct <= cp.Stages[i-1]) {
+			return fmt.Errorf("%v: stages must be increasing percentages between 1 and 100", errCampaignInvalid)
+		}
+	}
+	if cp.FailureThreshold == 0 {
+		cp.FailureThreshold = defaultFailureThreshold
+	}
+	if cp.FailureThreshold < 0 || cp.FailureThreshold > 1 {
+		return fmt.Errorf("%v: failure threshold must be between 0 and 1", errCampaignInvalid)
+	}
+	if cp.MinReports <= 0 {
+		cp.MinReports = defaultMinReports
+	}
+	_, err := parseSelector(cp.Selector)
+	return err
+}
+
+// target points the campaign at artifact a and its device type.
+func (cp *Campaign) target(a FirmwareArtifact) error {
+	if cp.DeviceType != "" && cp.DeviceType != a.DeviceType {
+		return fmt.Errorf("%v: firmware %d is for device type %s, not %s", errCampaignInvalid, a.ID, a.DeviceType, cp.DeviceType)
+	}
+	cp.DeviceType, cp.Version = a.DeviceType, a.Version
+	return nil
+}
+
+// bucket deterministically places a device in [0, 100) for a campaign, so a
+// device offered the update at one stage stays offered at the next.
+func (cp Campaign) bucket(deviceID uint64) int {
+	h := fnv.New32a()
+	fmt.Fprintf(h, "%d:%d", cp.ID, deviceID)
+	return int(h.Sum32() % 100)
+}
+
+func (cp Campaign) offered(deviceID uint64) bool {
+	return cp.bucket(deviceID) < cp.Stages[cp.Stage]
+}
+
+func (s monitorService) CreateCampaign(ctx context.Context, campaign Campaign) (uint64, error) {
+	if err := campaign.validate(); err != nil {
+		return 0, err
+	}
+	c, err := s.store.dial()
+	if err != nil {
+		return 0, err
+	}
+	defer c.Close()
+
+	a, err := readArtifact(c, campaign.FirmwareID)
+	if err != nil {
+		return 0, err
+	}
+	if err := campaign.target(a); err != nil {
+		return 0, err
+	}
+	campaign.Stage, campaign.Status, campaign.CreatedAt = 0, CampaignActive, makeTimestamp()
+	campaign.ID, err = redis.Uint64(c.Do("INCR", "id:campaigns"))
+	if err != nil {
+		return 0, err
+	}
+	if err := addCampaign(c, campaign); err != nil {
+		return 0, err
+	}
+	return campaign.ID, nil
+}
+
+// campaignStats counts the campaign's targeted devices and the progress they
+// have reported.
+func campaignStats(c redis.Conn, cp Campaign) (*CampaignStats, error) {
+	devices, err := selectDevices(c, DeviceFilter{DeviceType: cp.DeviceType, Selector: cp.Selector})
+	if err != nil {
+		return nil, err
+	}
+	stats := &CampaignStats{Targeted: len(devices)}
+	for _, d := range devices {
+		if cp.offered(d.ID) {
+			stats.Offered++
+		}
+	}
+	progress, err := readCampaignProgress(c, cp.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range progress {
+		switch r.State {
+		case FirmwareDownloading:
+			stats.Downloading++
+		case FirmwareInstalling:
+			stats.Installing++
+		case FirmwareInstalled:
+			stats.Installed++
+		case FirmwareFailed:
+			stats.Failed++
+		}
+	}
+	if done := stats.Installed + stats.Failed; done > 0 {
+		stats.FailureRate = float64(stats.Failed) / float64(done)
+	}
+	return stats, nil
+}
+
+func readCampaignProgress(c redis.Conn, id uint64) (map[string]FirmwareReport, error) {
+	raw, err := redis.StringMap(c.Do("HGETALL", campaignDevicesKey(id)))
+	if err != nil {
+		return nil, err
+	}
+	progress := make(map[string]FirmwareReport, len(raw))
+	for device, v := range raw {
+		var r FirmwareReport
+		if err := json.Unmarshal([]byte(v), &r); err != nil {
+			return nil, err
+		}
+		progress[device] = r
+	}
+	return progress, nil
+}
+
+func (s monitorService) GetCampaign(ctx context.Context, id uint64) (Campaign, error) {
+	c, err := s.store.dial()
+	if err != nil {
+		return Campaign{}, err
+	}
+	defer c.Close()
+
+	cp, err := readCampaign(c, id)
+	if err != nil {
+		return cp, err
+	}
+	cp.Stats, err = campaignStats(c, cp)
+	return cp, err
+}
+
+func (s monitorService) ListCampaigns(ctx context.Context) ([]Campaign, error) {
+	c, err := s.store.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	campaigns, err := readCampaigns(c)
+	if err != nil {
+		return nil, err
+	}
+	for i := range campaigns {
+		if campaigns[i].Stats, err = campaignStats(c, campaigns[i]); err != nil {
+			return nil, err
+		}
+	}
+	return campaigns, nil
+}
+
+// ControlCampaign pauses, resumes or advances a campaign to its next stage.
+func (s monitorService) ControlCampaign(ctx context.Context, id uint64, action string) (Campaign, error) {
+	c, err := s.store.dial()
+	if err != nil {
+		return Campaign{}, err
+	}
+	defer c.Close()
+
+	cp, err := readCampaign(c, id)
+	if err != nil {
+		return cp, err
+	}
+	switch action {
+	case CampaignPause:
+		cp.Status = CampaignPaused
+	case CampaignResume:
+		cp.Status = CampaignActive
+	case CampaignAdvance:
+		if cp.Stage == len(cp.Stages)-1 {
+			return cp, errors.New("campaign is already at its final stage")
+		}
+		cp.Stage++
+	default:
+		return cp, fmt.Errorf("unknown campaign action %q", action)
+	}
+	if err := writeCampaign(c, cp); err != nil {
+		return cp, err
+	}
+	cp.Stats, err = campaignStats(c, cp)
+	return cp, err
+}
+
+// ReportFirmware records the firmware version a device runs and its progress
+// on a campaign's update, pausing the campaign if too many installs fail.
+func (s monitorService) ReportFirmware(ctx context.Context, id uint64, report FirmwareReport) (bool, error) {
+	switch report.State {
+	case "", FirmwareDownloading, FirmwareInstalling, FirmwareInstalled, FirmwareFailed:
+	default:
+		return false, fmt.Errorf("unknown firmware state %q", report.State)
+	}
+	if report.State != "" && report.CampaignID == 0 {
+		return false, errors.New("firmware state requires a campaign_id")
+	}
+
+	c, err := s.store.dial()
+	if err != nil {
+		return false, err
+	}
+	defer c.Close()
+
+	if _, err := readDevice(c, id); err != nil {
+		return false, err
+	}
+	if report.Version != "" {
+		if _, err := c.Do("HSET", fmt.Sprintf("device:%d", id), "firmware_version", report.Version); err != nil {
+			return false, err
+		}
+	}
+	if report.State == "" {
+		return true, nil
+	}
+
+	cp, err := readCampaign(c, report.CampaignID)
+	if err != nil {
+		return false, err
+	}
+	b, err := json.Marshal(report)
+	if err != nil {
+		return false, err
+	}
+	if _, err := c.Do("HSET", campaignDevicesKey(cp.ID), id, b); err != nil {
+		return false, err
+	}
+	s.events.Publish(Event{Type: EventFirmwareProgress, DeviceID: id, Data: report})
+
+	if report.State != FirmwareFailed || cp.Status != CampaignActive {
+		return true, nil
+	}
+	paused, err := pauseIfFailing(c, cp.ID)
+	if err != nil {
+		return false, err
+	}
+	if paused != nil {
+		fmt.Printf("Paused campaign %d: failure rate %.2f exceeds %.2f\n", paused.ID, paused.Stats.FailureRate, paused.FailureThreshold)
+		s.events.Publish(Event{Type: EventCampaignPaused, DeviceID: id, Data: map[string]interface{}{"campaign_id": paused.ID, "failure_rate": paused.Stats.FailureRate}})
+	}
+	return true, nil
+}
+
+// pauseIfFailing pauses an active campaign once too many of its finished
+// installs have failed, returning it with its stats if it did. The campaign
+// is watched while it is checked, so that the pause can't undo a concurrent
+// change to it.
+func pauseIfFailing(c redis.Conn, id uint64) (*Campaign, error) {
+	for i := 0; i < firmwareRetries; i++ {
+		if _, err := c.Do("WATCH", campaignsKey); err != nil {
+			return nil, err
+		}
+		cp, err := readCampaign(c, id)
+		if err != nil {
+			c.Do("UNWATCH")
+			return nil, err
+		}
+		if cp.Status != CampaignActive {
+			c.Do("UNWATCH")
+			return nil, nil
+		}
+		stats, err := campaignStats(c, cp)
+		if err != nil {
+			c.Do("UNWATCH")
+			return nil, err
+		}
+		if stats.Installed+stats.Failed < cp.MinReports || stats.FailureRate <= cp.FailureThreshold {
+			c.Do("UNWATCH")
+			return nil, nil
+		}
+
+		cp.Status = CampaignPaused
+		c.Send("MULTI")
+		if err := writeCampaign(c, cp); err != nil {
+			c.Do("DISCARD")
+			return nil, err
+		}
+		reply, err := c.Do("EXEC")
+		if err != nil {
+			return nil, err
+		}
+		if reply != nil {
+			cp.Stats = stats
+			return &cp, nil
+		}
+	}
+	return nil, errCampaignConcurrentWrite
+}
+
+// OfferFirmware returns the update a device should install, if an active
+// campaign has reached it and it hasn't started on the update yet.
+func (s monitorService) OfferFirmware(ctx context.Context, id uint64) (*FirmwareOffer, error) {
+	c, err := s.store.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	d, err := readDeviceSummary(c, id)
+	if err != nil {
+		return nil, err
+	}
+	campaigns, err := readCampaignsFor(c, d.DeviceType)
+	if err != nil {
+		return nil, err
+	}
+	for _, cp := range campaigns {
+		if cp.Status != CampaignActive || cp.Version == d.FirmwareVersion || !cp.offered(id) {
+			continue
+		}
+		sel, err := parseSelector(cp.Selector)
+		if err != nil || !sel.matches(d.Labels) {
+			continue
+		}
+		started, err := redis.Bool(c.Do("HEXISTS", campaignDevicesKey(cp.ID), id))
+		if err != nil {
+			return nil, err
+		}
+		if started {
+			continue
+		}
+		a, err := readArtifact(c, cp.FirmwareID)
+		if err != nil {
+			return nil, err
+		}
+		return &FirmwareOffer{CampaignID: cp.ID, FirmwareID: a.ID, Version: a.Version, Checksum: a.Checksum, Size: a.Size}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,217 @@
+package iotmonitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+func TestCampaignValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cp      Campaign
+		wantErr bool
+	}{
+		{"defaults", Campaign{Name: "roll"}, false},
+		{"stages", Campaign{Name: "roll", Stages: []int{5, 25, 100}}, false},
+		{"no name", Campaign{Name: " "}, true},
+		{"decreasing stages", Campaign{Name: "roll", Stages: []int{25, 5}}, true},
+		{"repeated stage", Campaign{Name: "roll", Stages: []int{5, 5}}, true},
+		{"stage over 100", Campaign{Name: "roll", Stages: []int{101}}, true},
+		{"negative threshold", Campaign{Name: "roll", FailureThreshold: -0.1}, true},
+		{"threshold over 1", Campaign{Name: "roll", FailureThreshold: 1.5}, true},
+		{"bad selector", Campaign{Name: "roll", Selector: "tier in ()"}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.cp.validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: validate() = %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+
+	cp := Campaign{Name: "roll"}
+	cp.validate()
+	if !reflect.DeepEqual(cp.Stages, []int{100}) || cp.FailureThreshold != defaultFailureThreshold || cp.MinReports != defaultMinReports {
+		t.Errorf("defaults: stages %v, threshold %g, min reports %d", cp.Stages, cp.FailureThreshold, cp.MinReports)
+	}
+}
+
+func TestCampaignTarget(t *testing.T) {
+	a := FirmwareArtifact{ID: 4, DeviceType: "Drone", Version: "2.1.0"}
+	tests := []struct {
+		deviceType string
+		wantErr    bool
+	}{
+		{"", false},
+		{"Drone", false},
+		{"Sensor", true},
+	}
+	for _, tt := range tests {
+		cp := Campaign{Name: "roll", DeviceType: tt.deviceType, Selector: "site=plant-3"}
+		err := cp.target(a)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("target() with device type %q = %v, want error %v", tt.deviceType, err, tt.wantErr)
+			continue
+		}
+		if err == nil && (cp.DeviceType != "Drone" || cp.Version != "2.1.0") {
+			t.Errorf("target() with device type %q gave %s %s, want the firmware's", tt.deviceType, cp.DeviceType, cp.Version)
+		}
+	}
+}
+
+func TestCampaignOffered(t *testing.T) {
+	cp := Campaign{ID: 3, Stages: []int{10, 50, 100}}
+	counts := make([]int, len(cp.Stages))
+	for id := uint64(1); id <= 1000; id++ {
+		was := false
+		for stage := range cp.Stages {
+			cp.Stage = stage
+			now := cp.offered(id)
+			if was && !now {
+				t.Fatalf("device %d offered at stage %d but not %d", id, stage-1, stage)
+			}
+			if now {
+				counts[stage]++
+			}
+			was = now
+		}
+	}
+	if counts[2] != 1000 {
+		t.Errorf("%d of 1000 devices offered at 100%%", counts[2])
+	}
+	if counts[0] < 50 || counts[0] > 150 || counts[1] < 400 || counts[1] > 600 {
+		t.Errorf("offered %v of 1000 devices at stages %v", counts, cp.Stages)
+	}
+}
+
+func TestAddArtifact(t *testing.T) {
+	a := FirmwareArtifact{ID: 1, DeviceType: "Drone", Version: "1.0"}
+	tests := []struct {
+		name      string
+		artifact  FirmwareArtifact
+		abortExec int
+		wantErr   bool
+	}{
+		{"new", a, 0, false},
+		{"taken version", FirmwareArtifact{ID: 2, DeviceType: "Drone", Version: "1.0"}, 0, true},
+		{"other device type", FirmwareArtifact{ID: 3, DeviceType: "Sensor", Version: "1.0"}, 0, false},
+		{"concurrent upload", FirmwareArtifact{ID: 4, DeviceType: "Drone", Version: "1.1"}, 1, false},
+		{"contended", FirmwareArtifact{ID: 5, DeviceType: "Drone", Version: "1.2"}, firmwareRetries, true},
+	}
+	c := newFakeRedis()
+	for _, tt := range tests {
+		c.abortExec = tt.abortExec
+		err := addArtifact(c, tt.artifact)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: addArtifact() = %v, want error %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		_, readErr := readArtifact(c, tt.artifact.ID)
+		if tt.wantErr != (readErr == errFirmwareNotFound) {
+			t.Errorf("%s: readArtifact() = %v after addArtifact() = %v", tt.name, readErr, err)
+		}
+	}
+	versions, _ := redis.StringMap(c.Do("HGETALL", firmwareVersionsKey))
+	want := map[string]string{"Drone/1.0": "1", "Sensor/1.0": "3", "Drone/1.1": "4"}
+	if !reflect.DeepEqual(versions, want) {
+		t.Errorf("versions = %v, want %v", versions, want)
+	}
+}
+
+func TestReadCampaignsFor(t *testing.T) {
+	c := newFakeRedis()
+	for _, cp := range []Campaign{
+		{ID: 1, Name: "a", DeviceType: "Drone"},
+		{ID: 2, Name: "b", DeviceType: "Sensor"},
+		{ID: 10, Name: "c", DeviceType: "Drone"},
+	} {
+		if err := addCampaign(c, cp); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		deviceType string
+		want       []uint64
+	}{
+		{"Drone", []uint64{1, 10}},
+		{"Sensor", []uint64{2}},
+		{"Camera", nil},
+	}
+	for _, tt := range tests {
+		campaigns, err := readCampaignsFor(c, tt.deviceType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []uint64
+		for _, cp := range campaigns {
+			got = append(got, cp.ID)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("readCampaignsFor(%q) = %v, want %v", tt.deviceType, got, tt.want)
+		}
+	}
+}
+
+// campaignFixture stores a campaign over devices 1 to 10 with the given
+// install reports.
+func campaignFixture(t *testing.T, cp Campaign, reports map[uint64]string) *fakeRedis {
+	c := newFakeRedis()
+	for id := uint64(1); id <= 10; id++ {
+		d := deviceRecord{ID: id, DeviceType: cp.DeviceType}
+		c.Do("HMSET", redisArgs(fmt.Sprintf("device:%d", id), &d)...)
+		c.Do("SADD", "devices", id)
+	}
+	if err := addCampaign(c, cp); err != nil {
+		t.Fatal(err)
+	}
+	for id, state := range reports {
+		b, _ := json.Marshal(FirmwareReport{CampaignID: cp.ID, State: state})
+		c.Do("HSET", campaignDevicesKey(cp.ID), id, b)
+	}
+	return c
+}
+
+func TestPauseIfFailing(t *testing.T) {
+	failing := map[uint64]string{1: FirmwareFailed, 2: FirmwareFailed, 3: FirmwareInstalled, 4: FirmwareDownloading}
+	healthy := map[uint64]string{1: FirmwareFailed, 2: FirmwareInstalled, 3: FirmwareInstalled, 4: FirmwareInstalled, 5: FirmwareInstalled, 6: FirmwareInstalled}
+	active := Campaign{ID: 1, Name: "roll", DeviceType: "Drone", Stages: []int{100}, FailureThreshold: 0.5, MinReports: 3, Status: CampaignActive}
+	paused := active
+	paused.Status = CampaignPaused
+	tests := []struct {
+		name      string
+		cp        Campaign
+		reports   map[uint64]string
+		abortExec int
+		want      bool
+		wantErr   bool
+	}{
+		{"failing", active, failing, 0, true, false},
+		{"healthy", active, healthy, 0, false, false},
+		{"too few reports", active, map[uint64]string{1: FirmwareFailed, 2: FirmwareFailed}, 0, false, false},
+		{"already paused", paused, failing, 0, false, false},
+		{"concurrent change", active, failing, 1, true, false},
+		{"contended", active, failing, firmwareRetries, false, true},
+	}
+	for _, tt := range tests {
+		c := campaignFixture(t, tt.cp, tt.reports)
+		c.abortExec = tt.abortExec
+		got, err := pauseIfFailing(c, tt.cp.ID)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: pauseIfFailing() error = %v, want error %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if (got != nil) != tt.want {
+			t.Errorf("%s: pauseIfFailing() = %+v, want paused %v", tt.name, got, tt.want)
+			continue
+		}
+		stored, _ := readCampaign(c, tt.cp.ID)
+		if tt.want && (stored.Status != CampaignPaused || got.Stats.FailureRate != 2.0/3) {
+			t.Errorf("%s: stored status %q, failure rate %g", tt.name, stored.Status, got.Stats.FailureRate)
+		}
+		if !tt.want && stored.Status != tt.cp.Status {
+			t.Errorf("%s: stored status %q, want %q", tt.name, stored.Status, tt.cp.Status)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package iotmonitor
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/garyburd/redigo/redis"
+	"golang.org/x/net/context"
+)
+
+// Devices behind a gateway are listed in the set gateway:<id>:children. A
+// device whose gateway, or any gateway above it, is offline sits in
+// devices:unreachable until the gateway reports again.
+
+const unreachableKey = "devices:unreachable"
+
+var errNotChild = errors.New("device is not a child of this gateway")
+
+// TopologyNode is a device and the devices that reach us through it.
+type TopologyNode struct {
+	Device   Device         `json:"device"`
+	Children []TopologyNode `json:"children,omitempty"`
+}
+
+func gatewayChildrenKey(id uint64) string {
+	return fmt.Sprintf("gateway:%d:children", id)
+}
+
+func readChildren(c redis.Conn, id uint64) ([]uint64, error) {
+	v, err := redis.Values(c.Do("SORT", gatewayChildrenKey(id)))
+	if err != nil {
+		return nil, err
+	}
+	var ids []uint64
+	err = redis.ScanSlice(v, &ids)
+	return ids, err
+}
+
+// setReachable marks the devices behind a gateway reachable or unreachable
+// and publishes an event for each one that changed. Reachability only
+// cascades through child gateways that are themselves online.
+func setReachable(c redis.Conn, events *EventBus, gatewayID uint64, reachable bool, ts int64) error {
+	children, err := readChildren(c, gatewayID)
+	if err != nil {
+		return err
+	}
+	for _, id := range children {
+		var changed bool
+		if reachable {
+			changed, err = redis.Bool(c.Do("SREM", unreachableKey, id))
+		} else {
+			changed, err = redis.Bool(c.Do("SADD", unreachableKey, id))
+		}
+		if err != nil {
+			return err
+		}
+		if changed {
+			e := Event{Type: EventDeviceUnreachable, DeviceID: id, Timestamp: ts, Data: map[string]uint64{"gateway_id": gatewayID}}
+			if reachable {
+				e.Type = EventDeviceReachable
+			}
+			events.Publish(e)
+		}
+		if reachable {
+			online, err := redis.Bool(c.Do("SISMEMBER", onlineKey, id))
+			if err != nil {
+				return err
+			}
+			if !online {
+				continue
+			}
+		}
+		if err := setReachable(c, events, id, reachable, ts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkGateway verifies that the device is registered behind the gateway.
+func checkGateway(c redis.Conn, gatewayID, deviceID uint64) error {
+	if _, err := readDevice(c, gatewayID); err != nil {
+		return fmt.Errorf("gateway %v", err)
+	}
+	d, err := readDevice(c, deviceID)
+	if err != nil {
+		return err
+	}
+	if d.Gateway != gatewayID {
+		return errNotChild
+	}
+	return nil
+}
+
+// relay authorizes a submission a gateway makes for one of its children and
+// records a heartbeat for the gateway itself.
+func (s monitorService) relay(ctx context.Context, gatewayID, deviceID uint64) error {
+	if err := checkCaller(ctx, gatewayID); err != nil {
+		return err
+	}
+	c, err := s.store.dial()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if err := checkGateway(c, gatewayID, deviceID); err != nil {
+		return err
+	}
+	s.seen(c, gatewayID, makeTimestamp())
+	return nil
+}
+
+// RelayStatus records a status update that a gateway submits on behalf of
+// one of its children.
+func (s monitorService) RelayStatus(ctx context.Context, gatewayID, id uint64, lat, long, alt float32, battery uint32) (bool, error) {
+	if err := s.relay(ctx, gatewayID, id); err != nil {
+		return false, err
+	}
+	return s.UpdateStatus(ctx, id, lat, long, alt, battery)
+}
+
+// RelayTelemetry records telemetry that a gateway submits on behalf of one of
+// its children.
+func (s monitorService) RelayTelemetry(ctx context.Context, gatewayID, id uint64, readings map[string]TelemetryValue) (bool, []SchemaViolation, error) {
+	if err := s.relay(ctx, gatewayID, id); err != nil {
+		return false, nil, err
+	}
+	return s.SubmitTelemetry(ctx, id, readings)
+}
+
+func loadTopology(c redis.Conn, id uint64) (TopologyNode, error) {
+	d, err := loadDevice(c, id)
+	if err != nil {
+		return TopologyNode{}, err
+	}
+	node := TopologyNode{Device: d}
+	children, err := readChildren(c, id)
+	if err != nil {
+		return node, err
+	}
+	for _, child := range children {
+		n, err := loadTopology(c, child)
+		if err == errDeviceNotFound {
+			continue
+		}
+		if err != nil {
+			return node, err
+		}
+		node.Children = append(node.Children, n)
+	}
+	return node, nil
+}
+
+// GetTopology returns the tree of devices that reach us through the given
+// device.
+func (s monitorService) GetTopology(ctx context.Context, id uint64) (TopologyNode, error) {
+	c, err := s.store.dial()
+	if err != nil {
+		return TopologyNode{}, err
+	}
+	defer c.Close()
+	return loadTopology(c, id)
+}
@@ -0,0 +1,109 @@
+package iotmonitor
+
+import (
+	"errors"
+	"math"
+)
+
+const (
+	earthRadius     = 6371000.0                   // meters
+	metersPerDegree = earthRadius * math.Pi / 180 // along a meridian
+)
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+// haversine returns the great-circle distance in meters between two points.
+func haversine(lat1, long1, lat2, long2 float64) float64 {
+	dLat := radians(lat2 - lat1)
+	dLong := radians(long2 - long1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(radians(lat1))*math.Cos(radians(lat2))*math.Sin(dLong/2)*math.Sin(dLong/2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// GeoJSONPolygon is a GeoJSON Polygon geometry. Positions are [longitude,
+// latitude]; the first ring is the exterior and any others are holes.
+type GeoJSONPolygon struct {
+	Type        string         `json:"type"`
+	Coordinates [][][2]float64 `json:"coordinates"`
+}
+
+func (p *GeoJSONPolygon) contains(lat, long float64) bool {
+	if len(p.Coordinates) == 0 || !ringContains(p.Coordinates[0], lat, long) {
+		return false
+	}
+	for _, hole := range p.Coordinates[1:] {
+		if ringContains(hole, lat, long) {
+			return false
+		}
+	}
+	return true
+}
+
+// ringContains is a planar ray-casting test, which is accurate enough for
+// fences that don't span the antimeridian or a pole.
+func ringContains(ring [][2]float64, lat, long float64) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		xi, yi := ring[i][0], ring[i][1]
+		xj, yj := ring[j][0], ring[j][1]
+		if (yi > lat) != (yj > lat) && long < (xj-xi)*(lat-yi)/(yj-yi)+xi {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// BoundingBox is a latitude/longitude box in degrees.
+type BoundingBox struct {
+	MinLatitude  float64 `json:"min_latitude"`
+	MinLongitude float64 `json:"min_longitude"`
+	MaxLatitude  float64 `json:"max_latitude"`
+	MaxLongitude float64 `json:"max_longitude"`
+}
+
+func (b BoundingBox) validate() error {
+	if b.MinLatitude > b.MaxLatitude || b.MinLongitude > b.MaxLongitude {
+		return errors.New("invalid bounding box: minimum exceeds maximum")
+	}
+	if b.MinLatitude < -90 || b.MaxLatitude > 90 || b.MinLongitude < -180 || b.MaxLongitude > 180 {
+		return errors.New("invalid bounding box: out of range")
+	}
+	return nil
+}
+
+func (b BoundingBox) contains(lat, long float64) bool {
+	return lat >= b.MinLatitude && lat <= b.MaxLatitude && long >= b.MinLongitude && long <= b.MaxLongitude
+}
+
+func (b BoundingBox) center() (lat, long float64) {
+	return (b.MinLatitude + b.MaxLatitude) / 2, (b.MinLongitude + b.MaxLongitude) / 2
+}
+
+// circleBounds approximates the box enclosing a circle of radius meters.
+func circleBounds(lat, long, radius float64) BoundingBox {
+	dLat := radius / metersPerDegree
+	dLong := 180.0
+	if c := math.Cos(radians(lat)); c > 1e-6 {
+		dLong = math.Min(180, radius/(metersPerDegree*c))
+	}
+	return BoundingBox{
+		MinLatitude:  math.Max(-90, lat-dLat),
+		MaxLatitude:  math.Min(90, lat+dLat),
+		MinLongitude: math.Max(-180, long-dLong),
+		MaxLongitude: math.Min(180, long+dLong),
+	}
+}
+
+func ringBounds(ring [][2]float64) BoundingBox {
+	b := BoundingBox{MinLatitude: 90, MaxLatitude: -90, MinLongitude: 180, MaxLongitude: -180}
+	for _, pos := range ring {
+		b.MinLongitude = math.Min(b.MinLongitude, pos[0])
+		b.MaxLongitude = math.Max(b.MaxLongitude, pos[0])
+		b.MinLatitude = math.Min(b.MinLatitude, pos[1])
+		b.MaxLatitude = math.Max(b.MaxLatitude, pos[1])
+	}
+	return b
+}
@@ -0,0 +1,93 @@
+package iotmonitor
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHaversine(t *testing.T) {
+	tests := []struct {
+		name                     string
+		lat1, long1, lat2, long2 float64
+		want, tolerance          float64
+	}{
+		{"same point", 51.5, -0.12, 51.5, -0.12, 0, 1e-9},
+		{"one degree of latitude", 0, 0, 1, 0, 111195, 1},
+		{"one degree of longitude at 60N", 60, 0, 60, 1, 55597, 1},
+		{"London to Paris", 51.5074, -0.1278, 48.8566, 2.3522, 343556, 500},
+		{"antipodes", 0, 0, 0, 180, math.Pi * earthRadius, 1},
+	}
+	for _, tt := range tests {
+		got := haversine(tt.lat1, tt.long1, tt.lat2, tt.long2)
+		if math.Abs(got-tt.want) > tt.tolerance {
+			t.Errorf("%s: haversine() = %.1f, want %.1f ± %g", tt.name, got, tt.want, tt.tolerance)
+		}
+	}
+}
+
+func TestPolygonContains(t *testing.T) {
+	// A 10° square with a 2° hole in the middle, as [long, lat] positions.
+	p := &GeoJSONPolygon{
+		Type: "Polygon",
+		Coordinates: [][][2]float64{
+			{{0, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}},
+			{{4, 4}, {6, 4}, {6, 6}, {4, 6}, {4, 4}},
+		},
+	}
+	tests := []struct {
+		name      string
+		lat, long float64
+		want      bool
+	}{
+		{"inside", 2, 2, true},
+		{"in the hole", 5, 5, false},
+		{"outside", 11, 5, false},
+		{"latitude and longitude not swapped", 2, 8, true},
+		{"west of the square", 5, -1, false},
+	}
+	for _, tt := range tests {
+		if got := p.contains(tt.lat, tt.long); got != tt.want {
+			t.Errorf("%s: contains(%g, %g) = %v, want %v", tt.name, tt.lat, tt.long, got, tt.want)
+		}
+	}
+}
+
+func TestCircleBounds(t *testing.T) {
+	tests := []struct {
+		name              string
+		lat, long, radius float64
+	}{
+		{"equator", 0, 0, 1000},
+		{"mid latitude", 45, 90, 50000},
+		{"near the dateline", -30, 179.99, 5000},
+	}
+	for _, tt := range tests {
+		b := circleBounds(tt.lat, tt.long, tt.radius)
+		if err := b.validate(); err != nil {
+			t.Errorf("%s: invalid bounds %+v: %v", tt.name, b, err)
+			continue
+		}
+		// Points just inside the circle due north, south, east and west
+		// must be inside the bounds.
+		d := tt.radius * 0.999
+		dLat := d / earthRadius * 180 / math.Pi
+		dLong := dLat / math.Cos(radians(tt.lat))
+		for _, p := range [][2]float64{
+			{tt.lat + dLat, tt.long},
+			{tt.lat - dLat, tt.long},
+			{tt.lat, math.Min(180, tt.long+dLong)},
+			{tt.lat, tt.long - dLong},
+		} {
+			if !b.contains(p[0], p[1]) {
+				t.Errorf("%s: bounds %+v miss %v", tt.name, b, p)
+			}
+		}
+	}
+}
+
+func TestCircleBoundsAtPole(t *testing.T) {
+	b := circleBounds(90, 0, 1000)
+	if b.MinLongitude != -180 || b.MaxLongitude != 180 || b.MaxLatitude != 90 {
+		t.Errorf("circleBounds at the pole = %+v, want every longitude up to 90N", b)
+	}
+}
@@ -0,0 +1,578 @@
+package iotmonitor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/garyburd/redigo/redis"
+	"golang.org/x/net/context"
+)
+
+// Geofence policies
+const (
+	GeofenceInside  = "inside"  // permitted zone: devices must stay inside one
+	GeofenceOutside = "outside" // keep-out zone: devices must not enter
+)
+
+// Geofence is either a circle (Radius meters around Latitude/Longitude) or a
+// GeoJSON polygon. It applies to the listed devices and owners, or to every
+// device when neither list is set.
+type Geofence struct {
+	ID        uint64          `json:"id"`
+	Name      string          `json:"name"`
+	Policy    string          `json:"policy"`
+	Latitude  float64         `json:"latitude,omitempty"`
+	Longitude float64         `json:"longitude,omitempty"`
+	Radius    float64         `json:"radius,omitempty"`
+	Polygon   *GeoJSONPolygon `json:"polygon,omitempty"`
+	Devices   []uint64        `json:"devices,omitempty"`
+	Owners    []string        `json:"owners,omitempty"`
+}
+
+var (
+	errGeofenceNotFound = errors.New("geofence not found")
+	errGeofenceInvalid  = errors.New("invalid geofence")
+)
+
+const (
+	geofencesKey        = "geofences"
+	geofencesVersionKey = "geofences:version"
+)
+
+func (g *Geofence) validate() error {
+	if strings.TrimSpace(g.Name) == "" {
+		return fmt.Errorf("%v: name is required", errGeofenceInvalid)
+	}
+	switch g.Policy {
+	case "":
+		g.Policy = GeofenceInside
+	case GeofenceInside, GeofenceOutside:
+	default:
+		return fmt.Errorf("%v: unknown policy %q", errGeofenceInvalid, g.Policy)
+	}
+	if (g.Polygon == nil) == (g.Radius <= 0) {
+		return fmt.Errorf("%v: exactly one of radius or polygon is required", errGeofenceInvalid)
+	}
+	if g.Polygon != nil {
+		if g.Polygon.Type != "Polygon" {
+			return fmt.Errorf("%v: unsupported GeoJSON type %q", errGeofenceInvalid, g.Polygon.Type)
+		}
+		for _, ring := range g.Polygon.Coordinates {
+			if len(ring) < 3 {
+				return fmt.Errorf("%v: polygon rings need at least 3 positions", errGeofenceInvalid)
+			}
+		}
+		if len(g.Polygon.Coordinates) == 0 {
+			return fmt.Errorf("%v: polygon has no rings", errGeofenceInvalid)
+		}
+		return nil
+	}
+	if math.Abs(g.Latitude) > 90 || math.Abs(g.Longitude) > 180 {
+		return fmt.Errorf("%v: center is out of range", errGeofenceInvalid)
+	}
+	return nil
+}
+
+func (g *Geofence) bounds() BoundingBox {
+	if g.Polygon != nil {
+		return ringBounds(g.Polygon.Coordinates[0])
+	}
+	return circleBounds(g.Latitude, g.Longitude, g.Radius)
+}
+
+func (g *Geofence) contains(lat, long float64) bool {
+	if g.Polygon != nil {
+		return g.Polygon.contains(lat, long)
+	}
+	return haversine(g.Latitude, g.Longitude, lat, long) <= g.Radius
+}
+
+func (g *Geofence) global() bool {
+	return len(g.Devices) == 0 && len(g.Owners) == 0
+}
+
+func (g *Geofence) appliesTo(d deviceRecord) bool {
+	if g.global() {
+		return true
+	}
+	for _, id := range g.Devices {
+		if id == d.ID {
+			return true
+		}
+	}
+	for _, owner := range g.Owners {
+		if owner == d.Owner {
+			return true
+		}
+	}
+	return false
+}
+
+// Spatial index tuning: fences are bucketed into a grid of cellSize degree
+// cells; fences covering more than maxCells cells are checked on every query.
+const (
+	cellSize = 0.25
+	maxCells = 256
+)
+
+type cell struct {
+	lat, long int
+}
+
+func cellOf(lat, long float64) cell {
+	return cell{int(math.Floor(lat / cellSize)), int(math.Floor(long / cellSize))}
+}
+
+// geofenceIndex is an in-memory grid index over the fences stored in Redis.
+// It reloads itself whenever the stored version changes, so every monitord
+// instance converges on the same fences.
+type geofenceIndex struct {
+	mu       sync.RWMutex
+	version  int64
+	loaded   bool
+	fences   map[uint64]*Geofence
+	cells    map[cell][]*Geofence
+	large    []*Geofence
+	byDevice map[uint64][]*Geofence
+	byOwner  map[string][]*Geofence
+	global   []*Geofence
+}
+
+func newGeofenceIndex() *geofenceIndex {
+	return &geofenceIndex{}
+}
+
+func (x *geofenceIndex) refresh(c redis.Conn) error {
+	version, err := redis.Int64(c.Do("GET", geofencesVersionKey))
+	if err != nil && err != redis.ErrNil {
+		return err
+	}
+	x.mu.RLock()
+	current := x.loaded && x.version == version
+	x.mu.RUnlock()
+	if current {
+		return nil
+	}
+
+	fences, err := readGeofences(c)
+	if err != nil {
+		return err
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.build(fences)
+	x.version = version
+	x.loaded = true
+	return nil
+}
+
+func (x *geofenceIndex) build(fences []Geofence) {
+	x.fences = make(map[uint64]*Geofence, len(fences))
+	x.cells = make(map[cell][]*Geofence)
+	x.large = nil
+	x.byDevice = make(map[uint64][]*Geofence)
+	x.byOwner = make(map[string][]*Geofence)
+	x.global = nil
+
+	for i := range fences {
+		g := &fences[i]
+		x.fences[g.ID] = g
+		if g.global() {
+			x.global = append(x.global, g)
+		}
+		for _, id := range g.Devices {
+			x.byDevice[id] = append(x.byDevice[id], g)
+		}
+		for _, owner := range g.Owners {
+			x.byOwner[owner] = append(x.byOwner[owner], g)
+		}
+
+		b := g.bounds()
+		lo, hi := cellOf(b.MinLatitude, b.MinLongitude), cellOf(b.MaxLatitude, b.MaxLongitude)
+		if (hi.lat-lo.lat+1)*(hi.long-lo.long+1) > maxCells {
+			x.large = append(x.large, g)
+			continue
+		}
+		for la := lo.lat; la <= hi.lat; la++ {
+			for lo2 := lo.long; lo2 <= hi.long; lo2++ {
+				k := cell{la, lo2}
+				x.cells[k] = append(x.cells[k], g)
+			}
+		}
+	}
+}
+
+// applicable returns the fences assigned to the device.
+func (x *geofenceIndex) applicable(d deviceRecord) map[uint64]*Geofence {
+	out := make(map[uint64]*Geofence)
+	for _, g := range x.global {
+		out[g.ID] = g
+	}
+	for _, g := range x.byDevice[d.ID] {
+		out[g.ID] = g
+	}
+	for _, g := range x.byOwner[d.Owner] {
+		out[g.ID] = g
+	}
+	return out
+}
+
+// containing returns the IDs of the fences in candidates that contain the point.
+func (x *geofenceIndex) containing(lat, long float64, candidates map[uint64]*Geofence) map[uint64]bool {
+	inside := make(map[uint64]bool)
+	check := func(fences []*Geofence) {
+		for _, g := range fences {
+			if _, ok := candidates[g.ID]; ok && g.contains(lat, long) {
+				inside[g.ID] = true
+			}
+		}
+	}
+	check(x.cells[cellOf(lat, long)])
+	check(x.large)
+	return inside
+}
+
+// outsideZones reports whether a device has permitted zones among the
+// applicable fences but is inside none of them. Devices with permitted zones
+// must be inside at least one of them.
+func outsideZones(applicable map[uint64]*Geofence, inside map[uint64]bool) bool {
+	var zoned bool
+	for id, g := range applicable {
+		if g.Policy == GeofenceInside {
+			if inside[id] {
+				return false
+			}
+			zoned = true
+		}
+	}
+	return zoned
+}
+
+func geofenceInsideKey(deviceID uint64) string {
+	return fmt.Sprintf("geofences:inside:%d", deviceID)
+}
+
+// zoneAlertMember identifies the "outside every permitted zone" alert.
+func zoneAlertMember(deviceID uint64) string {
+	return fmt.Sprintf("fence:0:%d", deviceID)
+}
+
+func fenceAlertMember(fenceID, deviceID uint64) string {
+	return fmt.Sprintf("fence:%d:%d", fenceID, deviceID)
+}
+
+// evaluate compares the device's new position with the fences it was inside
+// and returns the resulting enter/exit events and alert transitions.
+func (x *geofenceIndex) evaluate(c redis.Conn, device deviceRecord, lat, long float64, now int64) ([]Event, []Alert, error) {
+	if err := x.refresh(c); err != nil {
+		return nil, nil, err
+	}
+	prev, err := redis.Values(c.Do("SMEMBERS", geofenceInsideKey(device.ID)))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	x.mu.RLock()
+	applicable := x.applicable(device)
+	inside := x.containing(lat, long, applicable)
+	x.mu.RUnlock()
+
+	var events []Event
+	var alerts []Alert
+	transition := func(member string, next Alert, firing bool) error {
+		a, err := transitionAlert(c, member, next, firing, now)
+		if a != nil {
+			alerts = append(alerts, *a)
+		}
+		return err
+	}
+	data := func(g *Geofence) map[string]interface{} {
+		return map[string]interface{}{"geofence_id": g.ID, "name": g.Name, "latitude": lat, "longitude": long}
+	}
+
+	was := make(map[uint64]bool, len(prev))
+	for _, raw := range prev {
+		id, err := redis.Uint64(raw, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		was[id] = true
+		if inside[id] {
+			continue
+		}
+		if _, err := c.Do("SREM", geofenceInsideKey(device.ID), id); err != nil {
+			return nil, nil, err
+		}
+		g, ok := applicable[id]
+		if !ok {
+			continue // fence deleted or reassigned
+		}
+		events = append(events, Event{Type: EventGeofenceExited, DeviceID: device.ID, Timestamp: now, Data: data(g)})
+		if g.Policy == GeofenceOutside {
+			if err := transition(fenceAlertMember(id, device.ID), Alert{Source: AlertSourceGeofence, GeofenceID: id, DeviceID: device.ID}, false); err != nil {
+				return events, alerts, err
+			}
+		}
+	}
+
+	for id := range inside {
+		if was[id] {
+			continue
+		}
+		if _, err := c.Do("SADD", geofenceInsideKey(device.ID), id); err != nil {
+			return events, alerts, err
+		}
+		g := applicable[id]
+		events = append(events, Event{Type: EventGeofenceEntered, DeviceID: device.ID, Timestamp: now, Data: data(g)})
+		if g.Policy == GeofenceOutside {
+			next := Alert{Source: AlertSourceGeofence, GeofenceID: id, DeviceID: device.ID, Message: fmt.Sprintf("entered keep-out zone %s", g.Name)}
+			if err := transition(fenceAlertMember(id, device.ID), next, true); err != nil {
+				return events, alerts, err
+			}
+		}
+	}
+
+	next := Alert{Source: AlertSourceGeofence, DeviceID: device.ID, Message: "outside all permitted zones"}
+	if err := transition(zoneAlertMember(device.ID), next, outsideZones(applicable, inside)); err != nil {
+		return events, alerts, err
+	}
+	return events, alerts, nil
+}
+
+func readGeofences(c redis.Conn) ([]Geofence, error) {
+	raw, err := redis.ByteSlices(c.Do("HVALS", geofencesKey))
+	if err != nil {
+		return nil, err
+	}
+	fences := make([]Geofence, 0, len(raw))
+	for _, b := range raw {
+		var g Geofence
+		if err := json.Unmarshal(b, &g); err != nil {
+			return nil, err
+		}
+		fences = append(fences, g)
+	}
+	return fences, nil
+}
+
+func writeGeofence(c redis.Conn, g Geofence) error {
+	b, err := json.Marshal(g)
+	if err != nil {
+		return err
+	}
+	if _, err := c.Do("HSET", geofencesKey, g.ID, b); err != nil {
+		return err
+	}
+	_, err = c.Do("INCR", geofencesVersionKey)
+	return err
+}
+
+func (s monitorService) CreateGeofence(ctx context.Context, fence Geofence) (uint64, error) {
+	if err := fence.validate(); err != nil {
+		return 0, err
+	}
+	c, err := s.store.dial()
+	if err != nil {
+		return 0, err
+	}
+	defer c.Close()
+
+	fence.ID, err = redis.Uint64(c.Do("INCR", "id:geofences"))
+	if err != nil {
+		return 0, err
+	}
+	if err := writeGeofence(c, fence); err != nil {
+		return 0, err
+	}
+	return fence.ID, nil
+}
+
+// UpdateGeofence replaces a fence, resolving its keep-out alerts for devices
+// it no longer applies to or whose last position is no longer inside it, and
+// the zone alerts the change leaves unsupported.
+func (s monitorService) UpdateGeofence(ctx context.Context, fence Geofence) (bool, error) {
+	if err := fence.validate(); err != nil {
+		return false, err
+	}
+	c, err := s.store.dial()
+	if err != nil {
+		return false, err
+	}
+	defer c.Close()
+
+	exists, err := redis.Bool(c.Do("HEXISTS", geofencesKey, fence.ID))
+	if err != nil {
+		return false, err
+	}
+	if !exists {
+		return false, errGeofenceNotFound
+	}
+	if err := writeGeofence(c, fence); err != nil {
+		return false, err
+	}
+
+	now := makeTimestamp()
+	alerts, err := resolveKeepOut(c, fence, now)
+	if err == nil {
+		var zone []Alert
+		zone, err = s.fences.resolveZoneAlerts(c, now)
+		alerts = append(alerts, zone...)
+	}
+	for _, a := range alerts {
+		s.events.Publish(alertEvent(a))
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// resolveKeepOut resolves the fence's firing keep-out alerts that its
+// current policy, assignment and shape no longer support, taking the fence
+// out of the device's inside set too. It returns the alerts it resolved.
+func resolveKeepOut(c redis.Conn, g Geofence, now int64) ([]Alert, error) {
+	active, err := redis.Strings(c.Do("SMEMBERS", "alerts:active"))
+	if err != nil {
+		return nil, err
+	}
+	prefix := fmt.Sprintf("fence:%d:", g.ID)
+	var resolved []Alert
+	for _, m := range active {
+		if !strings.HasPrefix(m, prefix) {
+			continue
+		}
+		deviceID, err := strconv.ParseUint(strings.TrimPrefix(m, prefix), 10, 64)
+		if err != nil {
+			continue
+		}
+		device, err := readDevice(c, deviceID)
+		if err == errDeviceNotFound {
+			continue
+		}
+		if err != nil {
+			return resolved, err
+		}
+		status, err := readStatus(c, deviceID)
+		if err != nil {
+			return resolved, err
+		}
+		inside := status.Timestamp != 0 && g.contains(float64(status.Latitude), float64(status.Longitude))
+		if g.Policy == GeofenceOutside && g.appliesTo(device) && inside {
+			continue
+		}
+		if !inside || !g.appliesTo(device) {
+			if _, err := c.Do("SREM", geofenceInsideKey(deviceID), g.ID); err != nil {
+				return resolved, err
+			}
+		}
+		a, err := transitionAlert(c, m, Alert{Source: AlertSourceGeofence, GeofenceID: g.ID, DeviceID: deviceID}, false, now)
+		if err != nil {
+			return resolved, err
+		}
+		if a != nil {
+			resolved = append(resolved, *a)
+		}
+	}
+	return resolved, nil
+}
+
+// resolveZoneAlerts resolves the firing zone alerts that the current fences
+// no longer support, judged on each device's last position. A change that
+// takes a device out of its permitted zones fires the alert on the device's
+// next report instead. It returns the alerts it resolved.
+func (x *geofenceIndex) resolveZoneAlerts(c redis.Conn, now int64) ([]Alert, error) {
+	if err := x.refresh(c); err != nil {
+		return nil, err
+	}
+	active, err := redis.Strings(c.Do("SMEMBERS", "alerts:active"))
+	if err != nil {
+		return nil, err
+	}
+	const prefix = "fence:0:"
+	var resolved []Alert
+	for _, m := range active {
+		if !strings.HasPrefix(m, prefix) {
+			continue
+		}
+		deviceID, err := strconv.ParseUint(strings.TrimPrefix(m, prefix), 10, 64)
+		if err != nil {
+			continue
+		}
+		device, err := readDevice(c, deviceID)
+		if err == errDeviceNotFound {
+			continue
+		}
+		if err != nil {
+			return resolved, err
+		}
+		status, err := readStatus(c, deviceID)
+		if err != nil {
+			return resolved, err
+		}
+
+		x.mu.RLock()
+		applicable := x.applicable(device)
+		inside := x.containing(float64(status.Latitude), float64(status.Longitude), applicable)
+		x.mu.RUnlock()
+		if outsideZones(applicable, inside) {
+			continue
+		}
+		a, err := transitionAlert(c, m, Alert{Source: AlertSourceGeofence, DeviceID: deviceID}, false, now)
+		if err != nil {
+			return resolved, err
+		}
+		if a != nil {
+			resolved = append(resolved, *a)
+		}
+	}
+	return resolved, nil
+}
+
+// DeleteGeofence removes a fence, resolving its keep-out alerts and the zone
+// alerts it leaves unsupported.
+func (s monitorService) DeleteGeofence(ctx context.Context, id uint64) (bool, error) {
+	c, err := s.store.dial()
+	if err != nil {
+		return false, err
+	}
+	defer c.Close()
+
+	n, err := redis.Int(c.Do("HDEL", geofencesKey, id))
+	if err != nil {
+		return false, err
+	}
+	if n == 0 {
+		return false, errGeofenceNotFound
+	}
+	if _, err := c.Do("INCR", geofencesVersionKey); err != nil {
+		return false, err
+	}
+	now := makeTimestamp()
+	alerts, err := clearAlerts(c, fmt.Sprintf("fence:%d:", id), now)
+	if err == nil {
+		var zone []Alert
+		zone, err = s.fences.resolveZoneAlerts(c, now)
+		alerts = append(alerts, zone...)
+	}
+	for _, a := range alerts {
+		s.events.Publish(alertEvent(a))
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s monitorService) ListGeofences(ctx context.Context) ([]Geofence, error) {
+	c, err := s.store.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	return readGeofences(c)
+}
@@ -0,0 +1,209 @@
+package iotmonitor
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+func TestGeofenceValidate(t *testing.T) {
+	square := &GeoJSONPolygon{Type: "Polygon", Coordinates: [][][2]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}}
+	tests := []struct {
+		name       string
+		fence      Geofence
+		wantErr    bool
+		wantPolicy string
+	}{
+		{"circle defaults to inside", Geofence{Name: "yard", Latitude: 1, Longitude: 2, Radius: 100}, false, GeofenceInside},
+		{"keep-out polygon", Geofence{Name: "airport", Policy: GeofenceOutside, Polygon: square}, false, GeofenceOutside},
+		{"no name", Geofence{Radius: 100}, true, ""},
+		{"unknown policy", Geofence{Name: "x", Policy: "near", Radius: 100}, true, ""},
+		{"circle and polygon", Geofence{Name: "x", Radius: 100, Polygon: square}, true, ""},
+		{"neither", Geofence{Name: "x"}, true, ""},
+		{"center out of range", Geofence{Name: "x", Latitude: 91, Radius: 100}, true, ""},
+		{"not a polygon", Geofence{Name: "x", Polygon: &GeoJSONPolygon{Type: "Point"}}, true, ""},
+		{"short ring", Geofence{Name: "x", Polygon: &GeoJSONPolygon{Type: "Polygon", Coordinates: [][][2]float64{{{0, 0}, {1, 1}}}}}, true, ""},
+	}
+	for _, tt := range tests {
+		g := tt.fence
+		err := g.validate()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: validate() = %v, want error %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err == nil && g.Policy != tt.wantPolicy {
+			t.Errorf("%s: policy = %q, want %q", tt.name, g.Policy, tt.wantPolicy)
+		}
+	}
+}
+
+func TestGeofenceAppliesTo(t *testing.T) {
+	d := deviceRecord{ID: 3, Owner: "ops"}
+	tests := []struct {
+		name  string
+		fence Geofence
+		want  bool
+	}{
+		{"global", Geofence{}, true},
+		{"assigned device", Geofence{Devices: []uint64{1, 3}}, true},
+		{"assigned owner", Geofence{Owners: []string{"ops"}}, true},
+		{"other devices", Geofence{Devices: []uint64{1, 2}}, false},
+		{"other owners", Geofence{Devices: []uint64{1}, Owners: []string{"lab"}}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.fence.appliesTo(d); got != tt.want {
+			t.Errorf("%s: appliesTo() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGeofenceIndexApplicableAgreesWithAppliesTo(t *testing.T) {
+	fences := []Geofence{
+		{ID: 1, Name: "global", Radius: 100},
+		{ID: 2, Name: "device", Radius: 100, Devices: []uint64{3}},
+		{ID: 3, Name: "owner", Radius: 100, Owners: []string{"ops"}},
+		{ID: 4, Name: "other", Radius: 100, Devices: []uint64{4}, Owners: []string{"lab"}},
+	}
+	x := newGeofenceIndex()
+	x.build(fences)
+	for _, d := range []deviceRecord{{ID: 3, Owner: "lab"}, {ID: 5, Owner: "ops"}, {ID: 6}} {
+		applicable := x.applicable(d)
+		for _, g := range fences {
+			_, got := applicable[g.ID]
+			if want := g.appliesTo(d); got != want {
+				t.Errorf("device %d, fence %s: applicable = %v, appliesTo = %v", d.ID, g.Name, got, want)
+			}
+		}
+	}
+}
+
+func TestGeofenceIndexContaining(t *testing.T) {
+	fences := []Geofence{
+		{ID: 1, Name: "small", Latitude: 10, Longitude: 10, Radius: 1000},
+		{ID: 2, Name: "large", Latitude: 0, Longitude: 0, Radius: 3000000},
+		{ID: 3, Name: "elsewhere", Latitude: -40, Longitude: 100, Radius: 1000},
+	}
+	x := newGeofenceIndex()
+	x.build(fences)
+	all := x.applicable(deviceRecord{ID: 1})
+	inside := x.containing(10, 10.001, all)
+	if !inside[1] || !inside[2] || inside[3] || len(inside) != 2 {
+		t.Errorf("containing() = %v, want fences 1 and 2", inside)
+	}
+	if len(x.large) != 1 || x.large[0].ID != 2 {
+		t.Errorf("large fences = %v, want only fence 2", x.large)
+	}
+}
+
+func TestResolveKeepOut(t *testing.T) {
+	c := newFakeRedis()
+	// Device 1 is still inside the fence, device 2 has left it and device
+	// 3 is inside but owned by someone else.
+	for _, d := range []struct {
+		device deviceRecord
+		lat    float32
+	}{
+		{deviceRecord{ID: 1, Owner: "ops"}, 0},
+		{deviceRecord{ID: 2, Owner: "ops"}, 1},
+		{deviceRecord{ID: 3, Owner: "lab"}, 0},
+	} {
+		c.Do("HMSET", redisArgs(fmt.Sprintf("device:%d", d.device.ID), &d.device)...)
+		s := statusRecord{Latitude: d.lat, Timestamp: 1000}
+		c.Do("HMSET", redisArgs(fmt.Sprintf("status:%d", d.device.ID), &s)...)
+		c.Do("SADD", geofenceInsideKey(d.device.ID), 7)
+		next := Alert{Source: AlertSourceGeofence, GeofenceID: 7, DeviceID: d.device.ID, Message: "entered keep-out zone airport"}
+		if _, err := transitionAlert(c, fenceAlertMember(7, d.device.ID), next, true, 1000); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	g := Geofence{ID: 7, Name: "airport", Policy: GeofenceOutside, Radius: 1000, Owners: []string{"ops"}}
+	resolved, err := resolveKeepOut(c, g, 2000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[uint64]bool)
+	for _, a := range resolved {
+		got[a.DeviceID] = true
+		if a.State != AlertResolved || a.Message != "entered keep-out zone airport" {
+			t.Errorf("resolved %+v, want it resolved with its message", a)
+		}
+	}
+	if len(got) != 2 || !got[2] || !got[3] {
+		t.Errorf("resolved alerts for %v, want devices 2 and 3", got)
+	}
+	for id, want := range map[uint64]bool{1: true, 2: false, 3: false} {
+		if inside, _ := redis.Bool(c.Do("SISMEMBER", geofenceInsideKey(id), 7)); inside != want {
+			t.Errorf("device %d inside = %v, want %v", id, inside, want)
+		}
+	}
+
+	// Turning the fence into a permitted zone resolves the rest.
+	g.Policy = GeofenceInside
+	resolved, err = resolveKeepOut(c, g, 3000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resolved) != 1 || resolved[0].DeviceID != 1 {
+		t.Errorf("resolved %+v, want device 1's alert", resolved)
+	}
+	if inside, _ := redis.Bool(c.Do("SISMEMBER", geofenceInsideKey(1), 7)); !inside {
+		t.Error("device 1 was taken out of a fence it is still inside")
+	}
+}
+
+func TestResolveZoneAlerts(t *testing.T) {
+	c := newFakeRedis()
+	// Device 1 is outside the yard, its only permitted zone; device 2 is
+	// outside the depot too.
+	fences := []Geofence{
+		{ID: 1, Name: "yard", Policy: GeofenceInside, Radius: 1000, Devices: []uint64{1, 2}},
+		{ID: 2, Name: "depot", Policy: GeofenceInside, Latitude: 5, Radius: 1000, Devices: []uint64{2}},
+	}
+	for _, g := range fences {
+		if err := writeGeofence(c, g); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, id := range []uint64{1, 2} {
+		d := deviceRecord{ID: id}
+		c.Do("HMSET", redisArgs(fmt.Sprintf("device:%d", id), &d)...)
+		s := statusRecord{Latitude: 1, Timestamp: 1000}
+		c.Do("HMSET", redisArgs(fmt.Sprintf("status:%d", id), &s)...)
+		next := Alert{Source: AlertSourceGeofence, DeviceID: id, Message: "outside all permitted zones"}
+		if _, err := transitionAlert(c, zoneAlertMember(id), next, true, 1000); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	x := newGeofenceIndex()
+	if resolved, err := x.resolveZoneAlerts(c, 1500); err != nil || len(resolved) != 0 {
+		t.Fatalf("resolveZoneAlerts() = %+v, %v with the fences unchanged, want none", resolved, err)
+	}
+
+	// Without the yard, device 1 has no permitted zone left.
+	c.Do("HDEL", geofencesKey, 1)
+	c.Do("INCR", geofencesVersionKey)
+	resolved, err := x.resolveZoneAlerts(c, 2000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resolved) != 1 || resolved[0].DeviceID != 1 || resolved[0].State != AlertResolved ||
+		resolved[0].ResolvedAt != 2000 || resolved[0].Message != "outside all permitted zones" {
+		t.Fatalf("resolved %+v, want device 1's zone alert", resolved)
+	}
+
+	// Moving the depot over device 2 puts it back in a permitted zone.
+	fences[1].Latitude = 1
+	if err := writeGeofence(c, fences[1]); err != nil {
+		t.Fatal(err)
+	}
+	resolved, err = x.resolveZoneAlerts(c, 3000)
+	if err != nil || len(resolved) != 1 || resolved[0].DeviceID != 2 {
+		t.Errorf("resolveZoneAlerts() = %+v, %v, want device 2's zone alert", resolved, err)
+	}
+	if active, _ := redis.Strings(c.Do("SMEMBERS", "alerts:active")); len(active) != 0 {
+		t.Errorf("active alerts = %v, want none", active)
+	}
+}
@@ -0,0 +1,315 @@
+package iotmonitor
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/garyburd/redigo/redis"
+	"golang.org/x/net/context"
+)
+
+// Group is a named node in the fleet hierarchy, such as a site, building or
+// floor. Root groups have no parent. Queries filtered by a group include the
+// devices of every group beneath it.
+type Group struct {
+	ID       uint64 `json:"id" redis:"id"`
+	Name     string `json:"name" redis:"name"`
+	ParentID uint64 `json:"parent_id,omitempty" redis:"parent_id"`
+}
+
+var (
+	errGroupNotFound = errors.New("group not found")
+	errGroupNotEmpty = errors.New("group has child groups")
+	errGroupCycle    = errors.New("group cannot be moved beneath itself")
+)
+
+func groupKey(id uint64) string {
+	return fmt.Sprintf("group:%d", id)
+}
+
+func groupChildrenKey(id uint64) string {
+	return fmt.Sprintf("group:%d:children", id)
+}
+
+func groupDevicesKey(id uint64) string {
+	return fmt.Sprintf("group:%d:devices", id)
+}
+
+func readGroup(c redis.Conn, id uint64) (Group, error) {
+	var g Group
+	v, err := redis.Values(c.Do("HGETALL", groupKey(id)))
+	if err != nil {
+		return g, err
+	}
+	if len(v) == 0 {
+		return g, errGroupNotFound
+	}
+	err = redis.ScanStruct(v, &g)
+	return g, err
+}
+
+// descendants returns the group and every group beneath it.
+func descendants(c redis.Conn, id uint64) ([]uint64, error) {
+	groups := []uint64{id}
+	for i := 0; i < len(groups); i++ {
+		children, err := redis.Values(c.Do("SMEMBERS", groupChildrenKey(groups[i])))
+		if err != nil {
+			return nil, err
+		}
+		var ids []uint64
+		if err := redis.ScanSlice(children, &ids); err != nil {
+			return nil, err
+		}
+		groups = append(groups, ids...)
+	}
+	return groups, nil
+}
+
+// devicesUnder returns the IDs of the devices in the group or any group
+// beneath it.
+func devicesUnder(c redis.Conn, id uint64) ([]uint64, error) {
+	if _, err := readGroup(c, id); err != nil {
+		return nil, err
+	}
+	groups, err := descendants(c, id)
+	if err != nil {
+		return nil, err
+	}
+	args := redis.Args{}
+	for _, g := range groups {
+		args = args.Add(groupDevicesKey(g))
+	}
+	v, err := redis.Values(c.Do("SUNION", args...))
+	if err != nil {
+		return nil, err
+	}
+	var ids []uint64
+	err = redis.ScanSlice(v, &ids)
+	return ids, err
+}
+
+func (g *Group) validate(c redis.Conn) error {
+	if strings.TrimSpace(g.Name) == "" {
+		return errors.New("group name is required")
+	}
+	if g.ParentID == 0 {
+		return nil
+	}
+	if _, err := readGroup(c, g.ParentID); err != nil {
+		if err == errGroupNotFound {
+			return fmt.Errorf("parent %v", err)
+		}
+		return err
+	}
+	if g.ID == 0 {
+		return nil
+	}
+	below, err := descendants(c, g.ID)
+	if err != nil {
+		return err
+	}
+	for _, id := range below {
+		if id == g.ParentID {
+			return errGroupCycle
+		}
+	}
+	return nil
+}
+
+func (s monitorService) CreateGroup(ctx context.Context, group Group) (uint64, error) {
+	c, err := s.store.dial()
+	if err != nil {
+		return 0, err
+	}
+	defer c.Close()
+
+	group.ID = 0
+	if err := group.validate(c); err != nil {
+		return 0, err
+	}
+	group.ID, err = redis.Uint64(c.Do("INCR", "id:groups"))
+	if err != nil {
+		return 0, err
+	}
+	if _, err := c.Do("HMSET", redis.Args{}.Add(groupKey(group.ID)).AddFlat(&group)...); err != nil {
+		return 0, err
+	}
+	if _, err := c.Do("SADD", "groups", group.ID); err != nil {
+		return 0, err
+	}
+	if group.ParentID != 0 {
+		if _, err := c.Do("SADD", groupChildrenKey(group.ParentID), group.ID); err != nil {
+			return 0, err
+		}
+	}
+	return group.ID, nil
+}
+
+// UpdateGroup renames a group or moves it, with its subtree, beneath a new
+// parent.
+func (s monitorService) UpdateGroup(ctx context.Context, group Group) (bool, error) {
+	c, err := s.store.dial()
+	if err != nil {
+		return false, err
+	}
+	defer c.Close()
+
+	old, err := readGroup(c, group.ID)
+	if err != nil {
+		return false, err
+	}
+	if err := group.validate(c); err != nil {
+		return false, err
+	}
+	if _, err := c.Do("HMSET", redis.Args{}.Add(groupKey(group.ID)).AddFlat(&group)...); err != nil {
+		return false, err
+	}
+	if old.ParentID == group.ParentID {
+		return true, nil
+	}
+	if old.ParentID != 0 {
+		if _, err := c.Do("SREM", groupChildrenKey(old.ParentID), group.ID); err != nil {
+			return false, err
+		}
+	}
+	if group.ParentID != 0 {
+		if _, err := c.Do("SADD", groupChildrenKey(group.ParentID), group.ID); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// DeleteGroup removes an empty group. Its devices stay registered; groups
+// with child groups must have them moved or deleted first.
+func (s monitorService) DeleteGroup(ctx context.Context, id uint64) (bool, error) {
+	c, err := s.store.dial()
+	if err != nil {
+		return false, err
+	}
+	defer c.Close()
+
+	g, err := readGroup(c, id)
+	if err != nil {
+		return false, err
+	}
+	children, err := redis.Int(c.Do("SCARD", groupChildrenKey(id)))
+	if err != nil {
+		return false, err
+	}
+	if children > 0 {
+		return false, errGroupNotEmpty
+	}
+	if g.ParentID != 0 {
+		if _, err := c.Do("SREM", groupChildrenKey(g.ParentID), id); err != nil {
+			return false, err
+		}
+	}
+	if _, err := c.Do("DEL", groupKey(id), groupChildrenKey(id), groupDevicesKey(id)); err != nil {
+		return false, err
+	}
+	if _, err := c.Do("SREM", "groups", id); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s monitorService) ListGroups(ctx context.Context) ([]Group, error) {
+	c, err := s.store.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	v, err := redis.Values(c.Do("SORT", "groups"))
+	if err != nil {
+		return nil, err
+	}
+	var ids []uint64
+	if err := redis.ScanSlice(v, &ids); err != nil {
+		return nil, err
+	}
+	groups := make([]Group, 0, len(ids))
+	for _, id := range ids {
+		g, err := readGroup(c, id)
+		if err == errGroupNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, g)
+	}
+	return groups, nil
+}
+
+func (s monitorService) AddGroupMembers(ctx context.Context, groupID uint64, deviceIDs []uint64) (bool, error) {
+	c, err := s.store.dial()
+	if err != nil {
+		return false, err
+	}
+	defer c.Close()
+
+	if _, err := readGroup(c, groupID); err != nil {
+		return false, err
+	}
+	for _, id := range deviceIDs {
+		if _, err := readDevice(c, id); err != nil {
+			return false, fmt.Errorf("device %d: %v", id, err)
+		}
+	}
+	if len(deviceIDs) == 0 {
+		return true, nil
+	}
+	if _, err := c.Do("SADD", redis.Args{}.Add(groupDevicesKey(groupID)).AddFlat(deviceIDs)...); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s monitorService) RemoveGroupMembers(ctx context.Context, groupID uint64, deviceIDs []uint64) (bool, error) {
+	c, err := s.store.dial()
+	if err != nil {
+		return false, err
+	}
+	defer c.Close()
+
+	if _, err := readGroup(c, groupID); err != nil {
+		return false, err
+	}
+	if len(deviceIDs) == 0 {
+		return true, nil
+	}
+	if _, err := c.Do("SREM", redis.Args{}.Add(groupDevicesKey(groupID)).AddFlat(deviceIDs)...); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ListStatuses returns the latest status of every selected device that has
+// reported one, e.g. all devices under a site when filtering by group.
+func (s monitorService) ListStatuses(ctx context.Context, filter DeviceFilter) ([]DeviceStatus, error) {
+	c, err := s.store.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	devices, err := selectDevices(c, filter)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]DeviceStatus, 0, len(devices))
+	for _, d := range devices {
+		s, err := loadStatus(c, d.ID)
+		if err == errNoStatus {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses, nil
+}
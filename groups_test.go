@@ -0,0 +1,71 @@
+package iotmonitor
+
+import "testing"
+
+// fleetGroups stores site 1 > building 2 > floor 3, and a separate site 4.
+func fleetGroups() *fakeRedis {
+	c := newFakeRedis()
+	for _, g := range []Group{
+		{ID: 1, Name: "site"},
+		{ID: 2, Name: "building", ParentID: 1},
+		{ID: 3, Name: "floor", ParentID: 2},
+		{ID: 4, Name: "other site"},
+	} {
+		c.Do("HMSET", redisArgs(groupKey(g.ID), &g)...)
+		if g.ParentID != 0 {
+			c.Do("SADD", groupChildrenKey(g.ParentID), g.ID)
+		}
+	}
+	return c
+}
+
+func TestGroupValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		group   Group
+		wantErr error
+	}{
+		{"new root", Group{Name: "new"}, nil},
+		{"new child", Group{Name: "new", ParentID: 3}, nil},
+		{"move beneath another site", Group{ID: 2, Name: "building", ParentID: 4}, nil},
+		{"no name", Group{Name: " "}, nil},
+		{"missing parent", Group{Name: "new", ParentID: 9}, nil},
+		{"beneath itself", Group{ID: 2, Name: "building", ParentID: 2}, errGroupCycle},
+		{"beneath its child", Group{ID: 2, Name: "building", ParentID: 3}, errGroupCycle},
+		{"beneath its grandchild", Group{ID: 1, Name: "site", ParentID: 3}, errGroupCycle},
+	}
+	wantAnyErr := map[string]bool{"no name": true, "missing parent": true}
+	for _, tt := range tests {
+		err := tt.group.validate(fleetGroups())
+		switch {
+		case tt.wantErr != nil && err != tt.wantErr:
+			t.Errorf("%s: validate() = %v, want %v", tt.name, err, tt.wantErr)
+		case tt.wantErr == nil && wantAnyErr[tt.name] && err == nil:
+			t.Errorf("%s: validate() = nil, want an error", tt.name)
+		case tt.wantErr == nil && !wantAnyErr[tt.name] && err != nil:
+			t.Errorf("%s: validate() = %v, want nil", tt.name, err)
+		}
+	}
+}
+
+func TestDescendants(t *testing.T) {
+	c := fleetGroups()
+	tests := []struct {
+		id   uint64
+		want int
+	}{
+		{1, 3},
+		{2, 2},
+		{3, 1},
+		{4, 1},
+	}
+	for _, tt := range tests {
+		got, err := descendants(c, tt.id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != tt.want || got[0] != tt.id {
+			t.Errorf("descendants(%d) = %v, want %d groups starting with itself", tt.id, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package iotmonitor
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/autodidaddict/iotmonitor/pb"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Guard admits calls to the HTTP and gRPC transports: each must carry one
+// of its bearer tokens, and together they are held to its rate limit. The
+// gRPC health service is exempt, so that orchestrators can probe without
+// credentials.
+//
+// Gateways may instead carry their own device token, made by DeviceToken
+// from the guard's device secret. That only admits them to report their own
+// status and telemetry and to relay for their children: a report is refused
+// unless it is for the device the token was made for, and a relay unless
+// the gateway named in it is.
+type Guard struct {
+	tokens       [][]byte
+	deviceSecret []byte
+	bucket       *tokenBucket
+}
+
+// NewGuard returns a Guard that accepts any of tokens, or every call if
+// there are neither tokens nor a device secret, and lets through rate calls
+// a second with bursts of up to burst, or any number if rate is 0. Device
+// tokens are only accepted if deviceSecret is set.
+func NewGuard(tokens []string, deviceSecret string, rate float64, burst int) *Guard {
+	g := &Guard{}
+	for _, t := range tokens {
+		g.tokens = append(g.tokens, []byte(t))
+	}
+	if deviceSecret != "" {
+		g.deviceSecret = []byte(deviceSecret)
+	}
+	if rate > 0 {
+		g.bucket = newTokenBucket(rate, burst)
+	}
+	return g
+}
+
+const deviceTokenPrefix = "dev."
+
+// DeviceToken returns the bearer token that identifies device id to a
+// Guard with the given device secret.
+func DeviceToken(secret string, id uint64) string {
+	return deviceTokenPrefix + strconv.FormatUint(id, 10) + "." + deviceMAC([]byte(secret), id)
+}
+
+func deviceMAC(secret []byte, id uint64) string {
+	mac := hmac.New(sha256.New, secret)
+	fmt.Fprintf(mac, "device:%d", id)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// caller is who a call was admitted as: an operator, or the device a
+// device token was made for.
+type caller struct {
+	device uint64
+}
+
+type callerKey struct{}
+
+func withCaller(ctx context.Context, c caller) context.Context {
+	return context.WithValue(ctx, callerKey{}, c)
+}
+
+var errGatewayIdentity = errors.New("caller is not this gateway")
+
+// checkCaller refuses a call made with a device token on behalf of another
+// gateway. Calls that didn't pass a Guard, or that were admitted as an
+// operator, may name any gateway.
+func checkCaller(ctx context.Context, gatewayID uint64) error {
+	c, ok := ctx.Value(callerKey{}).(caller)
+	if ok && c.device != 0 && c.device != gatewayID {
+		return errGatewayIdentity
+	}
+	return nil
+}
+
+// authenticate returns who header, an Authorization value, identifies.
+func (g *Guard) authenticate(header string) (caller, bool) {
+	if len(g.tokens) == 0 && g.deviceSecret == nil {
+		return caller{}, true
+	}
+	const prefix = "Bearer "
+	if len(header) < len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return caller{}, false
+	}
+	presented := header[len(prefix):]
+	ok := false
+	for _, t := range g.tokens {
+		if subtle.ConstantTimeCompare([]byte(presented), t) == 1 {
+			ok = true
+		}
+	}
+	if ok {
+		return caller{}, true
+	}
+	if g.deviceSecret == nil || !strings.HasPrefix(presented, deviceTokenPrefix) {
+		return caller{}, false
+	}
+	parts := strings.SplitN(presented[len(deviceTokenPrefix):], ".", 2)
+	if len(parts) != 2 {
+		return caller{}, false
+	}
+	id, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil || id == 0 {
+		return caller{}, false
+	}
+	if subtle.ConstantTimeCompare([]byte(parts[1]), []byte(deviceMAC(g.deviceSecret, id))) != 1 {
+		return caller{}, false
+	}
+	return caller{device: id}, true
+}
+
+// relayPath matches the HTTP relay routes a device token admits to.
+var relayPath = regexp.MustCompile(`^/v1/gateways/[0-9]+/devices/[0-9]+/(status|telemetry)$`)
+
+// reportPath matches the HTTP routes a device token admits to for the
+// device it was made for.
+var reportPath = regexp.MustCompile(`^/v1/devices/([0-9]+)/(status|telemetry)$`)
+
+// relayMethods are the gRPC relay methods a device token admits to.
+var relayMethods = map[string]bool{
+	"/pb.Monitor/RelayStatus":    true,
+	"/pb.Monitor/RelayTelemetry": true,
+}
+
+// reportMethods are the gRPC methods a device token admits to for the device
+// it was made for, with the device each request is for.
+var reportMethods = map[string]func(req interface{}) uint64{
+	"/pb.Monitor/UpdateDeviceStatus": func(req interface{}) uint64 {
+		r, _ := req.(*pb.StatusUpdateRequest)
+		return r.GetDeviceid()
+	},
+	"/pb.Monitor/SubmitTelemetry": func(req interface{}) uint64 {
+		r, _ := req.(*pb.TelemetrySubmitRequest)
+		return r.GetDeviceid()
+	},
+}
+
+// mayCall reports whether a device token admits who to an HTTP route.
+func (who caller) mayCall(method, path string) bool {
+	if method != "PUT" {
+		return false
+	}
+	if relayPath.MatchString(path) {
+		return true
+	}
+	m := reportPath.FindStringSubmatch(path)
+	return m != nil && m[1] == strconv.FormatUint(who.device, 10)
+}
+
+// mayInvoke reports whether a device token admits who to a gRPC method
+// called with req.
+func (who caller) mayInvoke(fullMethod string, req interface{}) bool {
+	if relayMethods[fullMethod] {
+		return true
+	}
+	device, ok := reportMethods[fullMethod]
+	return ok && device(req) == who.device
+}
+
+func (g *Guard) allow() bool {
+	return g.bucket == nil || g.bucket.take(time.Now())
+}
+
+// HTTP wraps next, refusing unauthorized calls with 401, device tokens
+// outside their own report routes and the relay routes with 403 and calls
+// over the limit with 429.
+func (g *Guard) HTTP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		who, ok := g.authenticate(r.Header.Get("Authorization"))
+		if !ok {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "missing or invalid bearer token", http.StatusUnauthorized)
+			return
+		}
+		if who.device != 0 && !who.mayCall(r.Method, r.URL.Path) {
+			http.Error(w, "device tokens may only report for their device or relay", http.StatusForbidden)
+			return
+		}
+		if !g.allow() {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(withCaller(r.Context(), who)))
+	})
+}
+
+// admit returns ctx with the caller's identity, or an error refusing the
+// call. req is nil for streams.
+func (g *Guard) admit(ctx context.Context, fullMethod string, req interface{}) (context.Context, error) {
+	var header string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md["authorization"]; len(v) > 0 {
+			header = v[0]
+		}
+	}
+	who, ok := g.authenticate(header)
+	if !ok {
+		return ctx, status.Error(codes.Unauthenticated, "missing or invalid bearer token")
+	}
+	if who.device != 0 && !who.mayInvoke(fullMethod, req) {
+		return ctx, status.Error(codes.PermissionDenied, "device tokens may only report for their device or relay")
+	}
+	if !g.allow() {
+		return ctx, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+	return withCaller(ctx, who), nil
+}
+
+// UnaryInterceptor guards unary gRPC calls, refusing them with
+// Unauthenticated, PermissionDenied or ResourceExhausted.
+func (g *Guard) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !exempt(info.FullMethod) {
+			var err error
+			if ctx, err = g.admit(ctx, info.FullMethod, req); err != nil {
+				return nil, err
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor guards the opening of gRPC streams. No stream admits
+// device tokens.
+func (g *Guard) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !exempt(info.FullMethod) {
+			if _, err := g.admit(ss.Context(), info.FullMethod, nil); err != nil {
+				return err
+			}
+		}
+		return handler(srv, ss)
+	}
+}
+
+// exempt reports whether a gRPC method is let through unguarded.
+func exempt(fullMethod string) bool {
+	return strings.HasPrefix(fullMethod, "/grpc.health.v1.Health/")
+}
+
+// tokenBucket holds up to burst tokens and refills at rate a second.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst)}
+}
+
+// take takes a token if there is one.
+func (b *tokenBucket) take(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.last.IsZero() {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
@@ -0,0 +1,173 @@
+package iotmonitor
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/autodidaddict/iotmonitor/pb"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestGuardAuthenticate(t *testing.T) {
+	g := NewGuard([]string{"op"}, "s3cret", 0, 0)
+	tests := []struct {
+		name   string
+		header string
+		device uint64
+		ok     bool
+	}{
+		{"operator", "Bearer op", 0, true},
+		{"lowercase scheme", "bearer op", 0, true},
+		{"device", "Bearer " + DeviceToken("s3cret", 42), 42, true},
+		{"other secret", "Bearer " + DeviceToken("other", 42), 0, false},
+		{"forged id", "Bearer dev.43." + DeviceToken("s3cret", 42)[len("dev.42."):], 0, false},
+		{"device zero", "Bearer " + DeviceToken("s3cret", 0), 0, false},
+		{"malformed device", "Bearer dev.42", 0, false},
+		{"unknown", "Bearer nope", 0, false},
+		{"no scheme", "op", 0, false},
+		{"missing", "", 0, false},
+	}
+	for _, tt := range tests {
+		who, ok := g.authenticate(tt.header)
+		if ok != tt.ok || who.device != tt.device {
+			t.Errorf("%s: authenticate() = %+v, %v, want device %d, %v", tt.name, who, ok, tt.device, tt.ok)
+		}
+	}
+}
+
+func TestGuardOpen(t *testing.T) {
+	if _, ok := NewGuard(nil, "", 0, 0).authenticate(""); !ok {
+		t.Error("guard without tokens or device secret refused a call")
+	}
+	if _, ok := NewGuard(nil, "s3cret", 0, 0).authenticate(""); ok {
+		t.Error("guard with only a device secret admitted a call without a token")
+	}
+	if _, ok := NewGuard(nil, "", 0, 0).authenticate("Bearer " + DeviceToken("", 42)); !ok {
+		t.Error("open guard refused a call with a token")
+	}
+}
+
+func TestGuardHTTP(t *testing.T) {
+	g := NewGuard([]string{"op"}, "s3cret", 0, 0)
+	device := "Bearer " + DeviceToken("s3cret", 42)
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		header string
+		code   int
+		caller uint64
+	}{
+		{"operator", "GET", "/v1/devices", "Bearer op", http.StatusOK, 0},
+		{"device relays status", "PUT", "/v1/gateways/42/devices/7/status", device, http.StatusOK, 42},
+		{"device relays telemetry", "PUT", "/v1/gateways/42/devices/7/telemetry", device, http.StatusOK, 42},
+		{"device reports status", "PUT", "/v1/devices/42/status", device, http.StatusOK, 42},
+		{"device reports telemetry", "PUT", "/v1/devices/42/telemetry", device, http.StatusOK, 42},
+		{"device reports for another", "PUT", "/v1/devices/7/status", device, http.StatusForbidden, 0},
+		{"device reads own status", "GET", "/v1/devices/42/status", device, http.StatusForbidden, 0},
+		{"device reads", "GET", "/v1/devices", device, http.StatusForbidden, 0},
+		{"device reads relay route", "GET", "/v1/gateways/42/devices/7/status", device, http.StatusForbidden, 0},
+		{"device under relay route", "PUT", "/v1/gateways/42/devices/7/status/x", device, http.StatusForbidden, 0},
+		{"no token", "GET", "/v1/devices", "", http.StatusUnauthorized, 0},
+	}
+	for _, tt := range tests {
+		var got caller
+		h := g.HTTP(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got, _ = r.Context().Value(callerKey{}).(caller)
+		}))
+		r := httptest.NewRequest(tt.method, tt.path, nil)
+		if tt.header != "" {
+			r.Header.Set("Authorization", tt.header)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != tt.code {
+			t.Errorf("%s: status %d, want %d", tt.name, w.Code, tt.code)
+		}
+		if got.device != tt.caller {
+			t.Errorf("%s: caller device %d, want %d", tt.name, got.device, tt.caller)
+		}
+	}
+}
+
+func TestGuardAdmit(t *testing.T) {
+	g := NewGuard([]string{"op"}, "s3cret", 0, 0)
+	device := "Bearer " + DeviceToken("s3cret", 42)
+	tests := []struct {
+		name   string
+		method string
+		req    interface{}
+		header string
+		code   codes.Code
+	}{
+		{"operator", "/pb.Monitor/ListDevices", &pb.ListDevicesRequest{}, "Bearer op", codes.OK},
+		{"device relays status", "/pb.Monitor/RelayStatus", &pb.RelayStatusRequest{}, device, codes.OK},
+		{"device relays telemetry", "/pb.Monitor/RelayTelemetry", &pb.RelayTelemetryRequest{}, device, codes.OK},
+		{"device reports status", "/pb.Monitor/UpdateDeviceStatus", &pb.StatusUpdateRequest{Deviceid: 42}, device, codes.OK},
+		{"device submits telemetry", "/pb.Monitor/SubmitTelemetry", &pb.TelemetrySubmitRequest{Deviceid: 42}, device, codes.OK},
+		{"device submits for another", "/pb.Monitor/SubmitTelemetry", &pb.TelemetrySubmitRequest{Deviceid: 7}, device, codes.PermissionDenied},
+		{"device opens a stream", "/pb.Monitor/SubmitTelemetry", nil, device, codes.PermissionDenied},
+		{"device reads", "/pb.Monitor/ListDevices", &pb.ListDevicesRequest{}, device, codes.PermissionDenied},
+		{"no token", "/pb.Monitor/ListDevices", &pb.ListDevicesRequest{}, "", codes.Unauthenticated},
+	}
+	for _, tt := range tests {
+		ctx := context.Background()
+		if tt.header != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", tt.header))
+		}
+		_, err := g.admit(ctx, tt.method, tt.req)
+		if status.Code(err) != tt.code {
+			t.Errorf("%s: admit() = %v, want %s", tt.name, err, tt.code)
+		}
+	}
+}
+
+func TestCheckCaller(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		gateway uint64
+		wantErr bool
+	}{
+		{"unguarded", context.Background(), 42, false},
+		{"operator", withCaller(context.Background(), caller{}), 42, false},
+		{"same gateway", withCaller(context.Background(), caller{device: 42}), 42, false},
+		{"other gateway", withCaller(context.Background(), caller{device: 42}), 43, true},
+	}
+	for _, tt := range tests {
+		if err := checkCaller(tt.ctx, tt.gateway); (err != nil) != tt.wantErr {
+			t.Errorf("%s: checkCaller() = %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	b := newTokenBucket(2, 3)
+	start := time.Unix(1000, 0)
+	tests := []struct {
+		after time.Duration
+		want  bool
+	}{
+		{0, true},
+		{0, true},
+		{0, true},
+		{0, false},
+		{250 * time.Millisecond, false},
+		{500 * time.Millisecond, true},
+		{500 * time.Millisecond, false},
+		{time.Hour, true},
+		{time.Hour, true},
+		{time.Hour, true},
+		{time.Hour, false},
+	}
+	for i, tt := range tests {
+		if got := b.take(start.Add(tt.after)); got != tt.want {
+			t.Errorf("take %d at +%s = %v, want %v", i, tt.after, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package iotmonitor
+
+import (
+	"sync"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+	"golang.org/x/net/context"
+)
+
+// Component health
+const (
+	HealthUp   = "up"
+	HealthDown = "down"
+)
+
+// ComponentHealth is the state of one part of the monitor.
+type ComponentHealth struct {
+	Status  string      `json:"status"`
+	Latency float64     `json:"latency_ms,omitempty"`
+	Error   string      `json:"error,omitempty"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// HealthCheck reports a component's health, giving up when ctx is done.
+type HealthCheck func(ctx context.Context) ComponentHealth
+
+// HealthReport is the health of each component checked.
+type HealthReport struct {
+	Status     string                     `json:"status"`
+	Components map[string]ComponentHealth `json:"components"`
+}
+
+// Up reports whether each of the named components is up, or every
+// component if none are named.
+func (r HealthReport) Up(components ...string) bool {
+	if len(components) == 0 {
+		for _, c := range r.Components {
+			if c.Status != HealthUp {
+				return false
+			}
+		}
+		return true
+	}
+	for _, name := range components {
+		if r.Components[name].Status != HealthUp {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckHealth runs checks concurrently. Its Status is up if every
+// component is.
+func CheckHealth(ctx context.Context, checks map[string]HealthCheck) HealthReport {
+	report := HealthReport{Components: make(map[string]ComponentHealth, len(checks))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check HealthCheck) {
+			defer wg.Done()
+			h := check(ctx)
+			mu.Lock()
+			report.Components[name] = h
+			mu.Unlock()
+		}(name, check)
+	}
+	wg.Wait()
+	report.Status = HealthDown
+	if report.Up() {
+		report.Status = HealthUp
+	}
+	return report
+}
+
+// StoreCheck returns a check that pings the store's Redis, reporting how
+// long the PING took.
+func StoreCheck(store StoreConfig) HealthCheck {
+	return func(ctx context.Context) ComponentHealth {
+		timeout := 5 * time.Second
+		if deadline, ok := ctx.Deadline(); ok {
+			timeout = time.Until(deadline)
+		}
+		details := map[string]interface{}{"backend": store.Backend, "addr": store.RedisAddr}
+		if timeout <= 0 {
+			return ComponentHealth{Status: HealthDown, Error: context.DeadlineExceeded.Error(), Details: details}
+		}
+		c, err := store.dial(redis.DialConnectTimeout(timeout), redis.DialReadTimeout(timeout), redis.DialWriteTimeout(timeout))
+		if err != nil {
+			return ComponentHealth{Status: HealthDown, Error: err.Error(), Details: details}
+		}
+		defer c.Close()
+
+		begin := time.Now()
+		_, err = c.Do("PING")
+		latency := float64(time.Since(begin)) / float64(time.Millisecond)
+		if err != nil {
+			return ComponentHealth{Status: HealthDown, Latency: latency, Error: err.Error(), Details: details}
+		}
+		return ComponentHealth{Status: HealthUp, Latency: latency, Details: details}
+	}
+}
+
+// EventBusCheck returns a check that reports the bus's subscribers and the
+// events it has published and dropped. The bus is in-process, so it is
+// always up.
+func EventBusCheck(bus *EventBus) HealthCheck {
+	return func(ctx context.Context) ComponentHealth {
+		return ComponentHealth{Status: HealthUp, Details: bus.Stats()}
+	}
+}
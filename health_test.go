@@ -0,0 +1,64 @@
+package iotmonitor
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+func component(status string) HealthCheck {
+	return func(ctx context.Context) ComponentHealth {
+		return ComponentHealth{Status: status}
+	}
+}
+
+func TestCheckHealth(t *testing.T) {
+	tests := []struct {
+		name   string
+		checks map[string]HealthCheck
+		want   string
+	}{
+		{"all up", map[string]HealthCheck{"store": component(HealthUp), "event_bus": component(HealthUp)}, HealthUp},
+		{"one down", map[string]HealthCheck{"store": component(HealthDown), "event_bus": component(HealthUp)}, HealthDown},
+		{"none", map[string]HealthCheck{}, HealthUp},
+	}
+	for _, tt := range tests {
+		report := CheckHealth(context.Background(), tt.checks)
+		if report.Status != tt.want || len(report.Components) != len(tt.checks) {
+			t.Errorf("%s: CheckHealth() = %+v, want status %s", tt.name, report, tt.want)
+		}
+	}
+}
+
+func TestHealthReportUp(t *testing.T) {
+	report := HealthReport{Components: map[string]ComponentHealth{
+		"store":     {Status: HealthDown},
+		"event_bus": {Status: HealthUp},
+	}}
+	tests := []struct {
+		components []string
+		want       bool
+	}{
+		{nil, false},
+		{[]string{"event_bus"}, true},
+		{[]string{"event_bus", "store"}, false},
+		{[]string{"workers"}, false},
+	}
+	for _, tt := range tests {
+		if got := report.Up(tt.components...); got != tt.want {
+			t.Errorf("Up(%v) = %v, want %v", tt.components, got, tt.want)
+		}
+	}
+}
+
+func TestEventBusCheck(t *testing.T) {
+	bus := NewEventBus()
+	_, cancel := bus.Subscribe(1)
+	defer cancel()
+	bus.Publish(Event{Type: EventStatusUpdated})
+	h := EventBusCheck(bus)(context.Background())
+	stats, ok := h.Details.(EventBusStats)
+	if h.Status != HealthUp || !ok || stats.Subscribers != 1 || stats.Published != 1 {
+		t.Errorf("EventBusCheck() = %+v", h)
+	}
+}
@@ -0,0 +1,127 @@
+package iotmonitor
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+	"github.com/go-kit/kit/metrics"
+	"golang.org/x/net/context"
+)
+
+const (
+	lastSeenKey = "devices:lastseen"
+	onlineKey   = "devices:online"
+)
+
+// markSeen records that a device reported at ts and reports whether the
+// device was offline until now.
+func markSeen(c redis.Conn, id uint64, ts int64) (bool, error) {
+	if _, err := c.Do("ZADD", lastSeenKey, ts, id); err != nil {
+		return false, err
+	}
+	return redis.Bool(c.Do("SADD", onlineKey, id))
+}
+
+func readConnectivity(c redis.Conn, id uint64) (online bool, lastSeen int64, err error) {
+	online, err = redis.Bool(c.Do("SISMEMBER", onlineKey, id))
+	if err != nil {
+		return
+	}
+	lastSeen, err = redis.Int64(c.Do("ZSCORE", lastSeenKey, id))
+	if err == redis.ErrNil {
+		err = nil
+	}
+	return
+}
+
+// HeartbeatMonitor marks devices offline once they have been silent for
+// longer than the timeout configured for their device type.
+type HeartbeatMonitor struct {
+	DefaultTimeout time.Duration
+	Timeouts       map[string]time.Duration // keyed by device type
+	Interval       time.Duration
+	Online         metrics.Gauge
+	Events         *EventBus
+	Store          StoreConfig
+}
+
+func (m *HeartbeatMonitor) timeout(deviceType string) time.Duration {
+	if t, ok := m.Timeouts[deviceType]; ok {
+		return t
+	}
+	return m.DefaultTimeout
+}
+
+// Run checks device heartbeats every interval until ctx is cancelled.
+func (m *HeartbeatMonitor) Run(ctx context.Context) error {
+	ticker := time.NewTicker(m.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := m.sweep(); err != nil {
+				fmt.Println("Failed to check device heartbeats")
+				fmt.Println(err)
+			}
+		}
+	}
+}
+
+func (m *HeartbeatMonitor) sweep() error {
+	c, err := m.Store.dial()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	ids, err := redis.Values(c.Do("SMEMBERS", onlineKey))
+	if err != nil {
+		return err
+	}
+	now := makeTimestamp()
+	for _, raw := range ids {
+		id, err := redis.Uint64(raw, nil)
+		if err != nil {
+			return err
+		}
+		device, err := readDevice(c, id)
+		if err == errDeviceNotFound {
+			if _, err := c.Do("SREM", onlineKey, id); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		_, lastSeen, err := readConnectivity(c, id)
+		if err != nil {
+			return err
+		}
+		if time.Duration(now-lastSeen)*time.Millisecond <= m.timeout(device.DeviceType) {
+			continue
+		}
+		removed, err := redis.Bool(c.Do("SREM", onlineKey, id))
+		if err != nil {
+			return err
+		}
+		if removed {
+			m.Events.Publish(Event{Type: EventDeviceOffline, DeviceID: id, Data: map[string]int64{"last_seen": lastSeen}})
+			if err := setReachable(c, m.Events, id, false, now); err != nil {
+				return err
+			}
+		}
+	}
+
+	online, err := redis.Int(c.Do("SCARD", onlineKey))
+	if err != nil {
+		return err
+	}
+	if m.Online != nil {
+		m.Online.Set(float64(online))
+	}
+	return nil
+}
@@ -0,0 +1,283 @@
+package iotmonitor
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/garyburd/redigo/redis"
+	"golang.org/x/net/context"
+)
+
+// Labels are stored per device in the hash labels:<id> and indexed by the
+// sets label:<key>=<value> and labelkey:<key>, each holding device IDs.
+
+var (
+	labelKeyPattern   = regexp.MustCompile(`^([a-zA-Z0-9]([-a-zA-Z0-9.]*[a-zA-Z0-9])?/)?[a-zA-Z0-9]([-a-zA-Z0-9_.]*[a-zA-Z0-9])?$`)
+	labelValuePattern = regexp.MustCompile(`^([a-zA-Z0-9]([-a-zA-Z0-9_.]*[a-zA-Z0-9])?)?$`)
+
+	errInvalidSelector = errors.New("invalid label selector")
+)
+
+func validateLabels(labels map[string]string) error {
+	for k, v := range labels {
+		if len(k) > 253 || !labelKeyPattern.MatchString(k) {
+			return fmt.Errorf("invalid label key %q", k)
+		}
+		if len(v) > 63 || !labelValuePattern.MatchString(v) {
+			return fmt.Errorf("invalid value %q for label %q", v, k)
+		}
+	}
+	return nil
+}
+
+func labelsKey(id uint64) string {
+	return fmt.Sprintf("labels:%d", id)
+}
+
+func labelIndexKey(k, v string) string {
+	return fmt.Sprintf("label:%s=%s", k, v)
+}
+
+func labelKeyIndexKey(k string) string {
+	return "labelkey:" + k
+}
+
+func readLabels(c redis.Conn, id uint64) (map[string]string, error) {
+	labels, err := redis.StringMap(c.Do("HGETALL", labelsKey(id)))
+	if err != nil || len(labels) == 0 {
+		return nil, err
+	}
+	return labels, nil
+}
+
+// writeLabels replaces a device's labels and keeps the indexes in step.
+func writeLabels(c redis.Conn, id uint64, labels map[string]string) error {
+	old, err := readLabels(c, id)
+	if err != nil {
+		return err
+	}
+	for k, v := range old {
+		if nv, ok := labels[k]; ok && nv == v {
+			continue
+		}
+		if _, err := c.Do("SREM", labelIndexKey(k, v), id); err != nil {
+			return err
+		}
+		if _, ok := labels[k]; !ok {
+			if _, err := c.Do("SREM", labelKeyIndexKey(k), id); err != nil {
+				return err
+			}
+		}
+	}
+	if _, err := c.Do("DEL", labelsKey(id)); err != nil {
+		return err
+	}
+	if len(labels) == 0 {
+		return nil
+	}
+	if _, err := c.Do("HMSET", redis.Args{}.Add(labelsKey(id)).AddFlat(labels)...); err != nil {
+		return err
+	}
+	for k, v := range labels {
+		if _, err := c.Do("SADD", labelIndexKey(k, v), id); err != nil {
+			return err
+		}
+		if _, err := c.Do("SADD", labelKeyIndexKey(k), id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetLabels replaces the labels on a device.
+func (s monitorService) SetLabels(ctx context.Context, id uint64, labels map[string]string) (bool, error) {
+	if err := validateLabels(labels); err != nil {
+		return false, err
+	}
+	c, err := s.store.dial()
+	if err != nil {
+		return false, err
+	}
+	defer c.Close()
+
+	if _, err := readDevice(c, id); err != nil {
+		return false, err
+	}
+	if err := writeLabels(c, id, labels); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Selector operators
+const (
+	opEquals       = "="
+	opNotEquals    = "!="
+	opIn           = "in"
+	opNotIn        = "notin"
+	opExists       = "exists"
+	opDoesNotExist = "!"
+)
+
+type requirement struct {
+	key    string
+	op     string
+	values []string
+}
+
+func (r requirement) matches(labels map[string]string) bool {
+	v, ok := labels[r.key]
+	switch r.op {
+	case opExists:
+		return ok
+	case opDoesNotExist:
+		return !ok
+	case opEquals, opIn:
+		return ok && containsString(r.values, v)
+	case opNotEquals, opNotIn:
+		return !ok || !containsString(r.values, v)
+	}
+	return false
+}
+
+func containsString(values []string, v string) bool {
+	for _, s := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// labelSelector is a parsed Kubernetes-style label selector. Requirements
+// are ANDed together.
+type labelSelector []requirement
+
+func (s labelSelector) matches(labels map[string]string) bool {
+	for _, r := range s {
+		if !r.matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseSelector parses selectors such as "site=plant-3,env!=test",
+// "tier in (edge,core)", "gpu" and "!retired".
+func parseSelector(selector string) (labelSelector, error) {
+	var s labelSelector
+	for _, term := range splitSelector(selector) {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+		r, err := parseRequirement(term)
+		if err != nil {
+			return nil, err
+		}
+		s = append(s, r)
+	}
+	return s, nil
+}
+
+// splitSelector splits on commas that aren't inside a value list.
+func splitSelector(selector string) []string {
+	var terms []string
+	depth, start := 0, 0
+	for i, ch := range selector {
+		switch ch {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				terms = append(terms, selector[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(terms, selector[start:])
+}
+
+func parseRequirement(term string) (requirement, error) {
+	invalid := fmt.Errorf("%v: %q", errInvalidSelector, term)
+
+	if strings.HasPrefix(term, "!") && !strings.Contains(term, "=") {
+		key := strings.TrimSpace(term[1:])
+		if !labelKeyPattern.MatchString(key) {
+			return requirement{}, invalid
+		}
+		return requirement{key: key, op: opDoesNotExist}, nil
+	}
+
+	for _, op := range []string{"!=", "==", "="} {
+		if i := strings.Index(term, op); i > 0 {
+			key, value := strings.TrimSpace(term[:i]), strings.TrimSpace(term[i+len(op):])
+			if !labelKeyPattern.MatchString(key) || !labelValuePattern.MatchString(value) {
+				return requirement{}, invalid
+			}
+			r := requirement{key: key, op: opEquals, values: []string{value}}
+			if op == "!=" {
+				r.op = opNotEquals
+			}
+			return r, nil
+		}
+	}
+
+	fields := strings.Fields(term)
+	if len(fields) == 1 {
+		if !labelKeyPattern.MatchString(fields[0]) {
+			return requirement{}, invalid
+		}
+		return requirement{key: fields[0], op: opExists}, nil
+	}
+	if len(fields) < 3 || (fields[1] != opIn && fields[1] != opNotIn) {
+		return requirement{}, invalid
+	}
+	list := strings.TrimSpace(strings.Join(fields[2:], " "))
+	if !strings.HasPrefix(list, "(") || !strings.HasSuffix(list, ")") {
+		return requirement{}, invalid
+	}
+	r := requirement{key: fields[0], op: fields[1]}
+	if strings.TrimSpace(list[1:len(list)-1]) == "" {
+		return requirement{}, invalid
+	}
+	for _, v := range strings.Split(list[1:len(list)-1], ",") {
+		v = strings.TrimSpace(v)
+		if !labelValuePattern.MatchString(v) {
+			return requirement{}, invalid
+		}
+		r.values = append(r.values, v)
+	}
+	if !labelKeyPattern.MatchString(r.key) || len(r.values) == 0 {
+		return requirement{}, invalid
+	}
+	return r, nil
+}
+
+// candidates uses the label indexes to narrow the devices that can match the
+// selector. It returns false when the selector has no indexable requirement.
+func (s labelSelector) candidates(c redis.Conn) ([]uint64, bool, error) {
+	var keys []string
+	for _, r := range s {
+		switch r.op {
+		case opEquals:
+			keys = append(keys, labelIndexKey(r.key, r.values[0]))
+		case opExists:
+			keys = append(keys, labelKeyIndexKey(r.key))
+		}
+	}
+	if len(keys) == 0 {
+		return nil, false, nil
+	}
+	ids, err := redis.Values(c.Do("SINTER", redis.Args{}.AddFlat(keys)...))
+	if err != nil {
+		return nil, true, err
+	}
+	var out []uint64
+	err = redis.ScanSlice(ids, &out)
+	return out, true, err
+}
@@ -0,0 +1,101 @@
+package iotmonitor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSelector(t *testing.T) {
+	tests := []struct {
+		selector string
+		want     labelSelector
+		wantErr  bool
+	}{
+		{"", nil, false},
+		{"site=plant-3", labelSelector{{key: "site", op: opEquals, values: []string{"plant-3"}}}, false},
+		{"site==plant-3", labelSelector{{key: "site", op: opEquals, values: []string{"plant-3"}}}, false},
+		{"env!=test", labelSelector{{key: "env", op: opNotEquals, values: []string{"test"}}}, false},
+		{"gpu", labelSelector{{key: "gpu", op: opExists}}, false},
+		{"!retired", labelSelector{{key: "retired", op: opDoesNotExist}}, false},
+		{"tier in (edge, core)", labelSelector{{key: "tier", op: opIn, values: []string{"edge", "core"}}}, false},
+		{"tier notin (edge)", labelSelector{{key: "tier", op: opNotIn, values: []string{"edge"}}}, false},
+		{
+			" site = plant-3 , tier in (edge,core), !retired ",
+			labelSelector{
+				{key: "site", op: opEquals, values: []string{"plant-3"}},
+				{key: "tier", op: opIn, values: []string{"edge", "core"}},
+				{key: "retired", op: opDoesNotExist},
+			},
+			false,
+		},
+		{"example.com/zone=a", labelSelector{{key: "example.com/zone", op: opEquals, values: []string{"a"}}}, false},
+		{"site=", labelSelector{{key: "site", op: opEquals, values: []string{""}}}, false},
+		{"=plant-3", nil, true},
+		{"site=plant 3", nil, true},
+		{"tier in edge", nil, true},
+		{"tier in ()", nil, true},
+		{"tier among (edge)", nil, true},
+		{"tier in (edge", nil, true},
+		{"-site", nil, true},
+		{"!", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := parseSelector(tt.selector)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseSelector(%q) error = %v, want error %v", tt.selector, err, tt.wantErr)
+			continue
+		}
+		if err == nil && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseSelector(%q) = %+v, want %+v", tt.selector, got, tt.want)
+		}
+	}
+}
+
+func TestSelectorMatches(t *testing.T) {
+	labels := map[string]string{"site": "plant-3", "tier": "edge", "gpu": ""}
+	tests := []struct {
+		selector string
+		want     bool
+	}{
+		{"", true},
+		{"site=plant-3", true},
+		{"site=plant-4", false},
+		{"site!=plant-4", true},
+		{"env!=test", true},
+		{"gpu", true},
+		{"env", false},
+		{"!env", true},
+		{"!gpu", false},
+		{"tier in (core,edge)", true},
+		{"tier notin (core,edge)", false},
+		{"env notin (test)", true},
+		{"env in (test)", false},
+		{"site=plant-3,tier=core", false},
+	}
+	for _, tt := range tests {
+		s, err := parseSelector(tt.selector)
+		if err != nil {
+			t.Fatalf("parseSelector(%q): %v", tt.selector, err)
+		}
+		if got := s.matches(labels); got != tt.want {
+			t.Errorf("%q matches %v = %v, want %v", tt.selector, labels, got, tt.want)
+		}
+	}
+}
+
+func TestValidateLabels(t *testing.T) {
+	tests := []struct {
+		labels  map[string]string
+		wantErr bool
+	}{
+		{map[string]string{"site": "plant-3", "example.com/tier": "edge", "empty": ""}, false},
+		{map[string]string{"-site": "a"}, true},
+		{map[string]string{"site": "plant 3"}, true},
+		{map[string]string{"site": "-a"}, true},
+	}
+	for _, tt := range tests {
+		if err := validateLabels(tt.labels); (err != nil) != tt.wantErr {
+			t.Errorf("validateLabels(%v) = %v, want error %v", tt.labels, err, tt.wantErr)
+		}
+	}
+}
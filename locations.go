@@ -0,0 +1,151 @@
+package iotmonitor
+
+import (
+	"errors"
+	"math"
+
+	"github.com/garyburd/redigo/redis"
+	"golang.org/x/net/context"
+)
+
+// locationsKey is a Redis geo set holding each device's last reported position.
+const locationsKey = "devices:locations"
+
+// DeviceLocation is a device with its last reported position. Distance is in
+// meters from the query point and is only set by NearbyDevices.
+type DeviceLocation struct {
+	Device    Device  `json:"device"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Distance  float64 `json:"distance,omitempty"`
+}
+
+var errInvalidRadius = errors.New("radius must be positive")
+
+func indexLocation(c redis.Conn, id uint64, lat, long float32) error {
+	_, err := c.Do("GEOADD", locationsKey, long, lat, id)
+	return err
+}
+
+// geoRadius returns the devices within radius meters of the point, nearest
+// first, with their positions and distances.
+func geoRadius(c redis.Conn, lat, long, radius float64) ([]DeviceLocation, error) {
+	v, err := redis.Values(c.Do("GEORADIUS", locationsKey, long, lat, radius, "m", "WITHDIST", "WITHCOORD", "ASC"))
+	if err != nil {
+		return nil, err
+	}
+	locations := make([]DeviceLocation, 0, len(v))
+	for _, raw := range v {
+		// Each entry is [member, distance, [long, lat]].
+		entry, err := redis.Values(raw, nil)
+		if err != nil || len(entry) != 3 {
+			return nil, errors.New("unexpected GEORADIUS reply")
+		}
+		id, err := redis.Uint64(entry[0], nil)
+		if err != nil {
+			return nil, err
+		}
+		dist, err := redis.Float64(entry[1], nil)
+		if err != nil {
+			return nil, err
+		}
+		coord, err := redis.Float64s(entry[2], nil)
+		if err != nil || len(coord) != 2 {
+			return nil, errors.New("unexpected GEORADIUS coordinates")
+		}
+		locations = append(locations, DeviceLocation{
+			Device:    Device{ID: id},
+			Latitude:  coord[1],
+			Longitude: coord[0],
+			Distance:  dist,
+		})
+	}
+	return locations, nil
+}
+
+// filterLocations fills in device details and drops devices the filter
+// rejects, as well as positions left behind by deleted devices.
+func filterLocations(c redis.Conn, locations []DeviceLocation, filter DeviceFilter) ([]DeviceLocation, error) {
+	m, err := filter.matcher(c)
+	if err != nil {
+		return nil, err
+	}
+	out := locations[:0]
+	for _, l := range locations {
+		d, err := loadDevice(c, l.Device.ID)
+		if err == errDeviceNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if m.matches(d) {
+			l.Device = d
+			out = append(out, l)
+		}
+	}
+	return out, nil
+}
+
+// NearbyDevices returns the devices last seen within radius meters of the
+// point, nearest first.
+func (s monitorService) NearbyDevices(ctx context.Context, lat, long, radius float64, filter DeviceFilter) ([]DeviceLocation, error) {
+	if radius <= 0 {
+		return nil, errInvalidRadius
+	}
+	c, err := s.store.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	return nearbyDevices(c, lat, long, radius, filter)
+}
+
+func nearbyDevices(c redis.Conn, lat, long, radius float64, filter DeviceFilter) ([]DeviceLocation, error) {
+	locations, err := geoRadius(c, lat, long, radius)
+	if err != nil {
+		return nil, err
+	}
+	return filterLocations(c, locations, filter)
+}
+
+// DevicesInBox returns the devices last seen inside the bounding box. Redis
+// has no box query before 6.2, so this searches the circle enclosing the box
+// and discards the corners.
+func (s monitorService) DevicesInBox(ctx context.Context, box BoundingBox, filter DeviceFilter) ([]DeviceLocation, error) {
+	if err := box.validate(); err != nil {
+		return nil, err
+	}
+	c, err := s.store.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	return devicesInBox(c, box, filter)
+}
+
+func devicesInBox(c redis.Conn, box BoundingBox, filter DeviceFilter) ([]DeviceLocation, error) {
+	lat, long := box.center()
+	radius := 1.0
+	for _, corner := range [][2]float64{
+		{box.MinLatitude, box.MinLongitude},
+		{box.MinLatitude, box.MaxLongitude},
+		{box.MaxLatitude, box.MinLongitude},
+		{box.MaxLatitude, box.MaxLongitude},
+	} {
+		radius = math.Max(radius, haversine(lat, long, corner[0], corner[1]))
+	}
+
+	candidates, err := geoRadius(c, lat, long, radius*1.01)
+	if err != nil {
+		return nil, err
+	}
+	inside := candidates[:0]
+	for _, l := range candidates {
+		if box.contains(l.Latitude, l.Longitude) {
+			l.Distance = 0
+			inside = append(inside, l)
+		}
+	}
+	return filterLocations(c, inside, filter)
+}
@@ -0,0 +1,108 @@
+package iotmonitor
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+// locatedDevices registers devices 1 to 4 at their last positions: 1 and 2
+// are drones near the origin, 3 is a sensor 0.01° north of it and 4 is
+// 1° away. Device 9 left its position behind when it was deleted.
+func locatedDevices(t *testing.T) redis.Conn {
+	c := newFakeRedis()
+	for _, d := range []struct {
+		device    deviceRecord
+		lat, long float32
+	}{
+		{deviceRecord{ID: 1, Name: "d1", DeviceType: "Drone"}, 0, 0.001},
+		{deviceRecord{ID: 2, Name: "d2", DeviceType: "Drone"}, 0.002, 0},
+		{deviceRecord{ID: 3, Name: "s3", DeviceType: "Sensor"}, 0.01, 0},
+		{deviceRecord{ID: 4, Name: "d4", DeviceType: "Drone"}, 1, 1},
+	} {
+		c.Do("HMSET", redisArgs(fmt.Sprintf("device:%d", d.device.ID), &d.device)...)
+		c.Do("SADD", "devices", d.device.ID)
+		if err := indexLocation(c, d.device.ID, d.lat, d.long); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := indexLocation(c, 9, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func locatedIDs(locations []DeviceLocation) []uint64 {
+	var ids []uint64
+	for _, l := range locations {
+		ids = append(ids, l.Device.ID)
+	}
+	return ids
+}
+
+func TestNearbyDevices(t *testing.T) {
+	c := locatedDevices(t)
+	tests := []struct {
+		name   string
+		radius float64
+		filter DeviceFilter
+		want   []uint64
+	}{
+		{"nearest first", 500, DeviceFilter{}, []uint64{1, 2}},
+		{"wider", 2000, DeviceFilter{}, []uint64{1, 2, 3}},
+		{"by type", 2000, DeviceFilter{DeviceType: "Sensor"}, []uint64{3}},
+		{"none", 50, DeviceFilter{}, nil},
+	}
+	for _, tt := range tests {
+		got, err := nearbyDevices(c, 0, 0, tt.radius, tt.filter)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if ids := locatedIDs(got); !reflect.DeepEqual(ids, tt.want) {
+			t.Errorf("%s: nearbyDevices() = %v, want %v", tt.name, ids, tt.want)
+		}
+	}
+
+	got, err := nearbyDevices(c, 0, 0, 500, DeviceFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := haversine(0, 0, 0, float64(float32(0.001)))
+	if l := got[0]; l.Device.Name != "d1" || l.Device.DeviceType != "Drone" || math.Abs(l.Distance-d) > 0.01 ||
+		math.Abs(l.Longitude-0.001) > 1e-6 || l.Latitude != 0 {
+		t.Errorf("nearest = %+v, want device 1 with its details, %g m away", l, d)
+	}
+}
+
+func TestDevicesInBox(t *testing.T) {
+	c := locatedDevices(t)
+	tests := []struct {
+		name   string
+		box    BoundingBox
+		filter DeviceFilter
+		want   []uint64
+	}{
+		{"around the origin", BoundingBox{-0.005, -0.005, 0.005, 0.005}, DeviceFilter{}, []uint64{1, 2}},
+		// The circle searched reaches devices 1 and 3, outside the box.
+		{"outside the box", BoundingBox{0.001, -0.004, 0.009, 0.004}, DeviceFilter{}, []uint64{2}},
+		{"tall", BoundingBox{-0.001, -0.001, 0.02, 0.001}, DeviceFilter{}, []uint64{3, 2, 1}},
+		{"by type", BoundingBox{-2, -2, 2, 2}, DeviceFilter{DeviceType: "Drone"}, []uint64{1, 2, 4}},
+	}
+	for _, tt := range tests {
+		got, err := devicesInBox(c, tt.box, tt.filter)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if ids := locatedIDs(got); !reflect.DeepEqual(ids, tt.want) {
+			t.Errorf("%s: devicesInBox() = %v, want %v", tt.name, ids, tt.want)
+		}
+		for _, l := range got {
+			if l.Distance != 0 {
+				t.Errorf("%s: device %d has distance %g, want none", tt.name, l.Device.ID, l.Distance)
+			}
+		}
+	}
+}
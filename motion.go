@@ -0,0 +1,211 @@
+package iotmonitor
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+	"golang.org/x/net/context"
+)
+
+// glitchSpeed is the fastest ground speed, in meters per second, we believe a
+// device can travel. Fixes implying anything faster are flagged as GPS
+// glitches and left out of the motion metrics.
+const glitchSpeed = 150.0
+
+// A run of glitchStreak implausible fixes, or one that arrives glitchExpiry
+// after the last plausible fix, suggests that fix was the bad one or that the
+// device has been moved: the new fix is accepted as the reference instead.
+const (
+	glitchStreak = 3
+	glitchExpiry = 10 * time.Minute
+)
+
+// motionRetries bounds how often applyMotion retries after a concurrent
+// update of the same device.
+const motionRetries = 3
+
+// MotionTotals accumulates motion over a device's lifetime.
+type MotionTotals struct {
+	Distance float64 `json:"distance"`  // meters
+	MaxSpeed float64 `json:"max_speed"` // meters per second
+	Glitches int64   `json:"glitches"`
+}
+
+// DeviceStatus is the last status a device reported, with the motion derived
+// from the fix before it.
+type DeviceStatus struct {
+	DeviceID  uint64       `json:"device_id"`
+	Latitude  float32      `json:"latitude"`
+	Longitude float32      `json:"longitude"`
+	Altitude  float32      `json:"altitude"`
+	Battery   uint32       `json:"battery"`
+	Timestamp int64        `json:"timestamp"`
+	Speed     float64      `json:"speed"`      // meters per second
+	Heading   float64      `json:"heading"`    // degrees clockwise from north
+	ClimbRate float64      `json:"climb_rate"` // meters per second
+	Distance  float64      `json:"distance"`   // meters since the previous fix
+	Glitch    bool         `json:"glitch"`
+	Totals    MotionTotals `json:"totals"`
+}
+
+var (
+	errNoStatus              = errors.New("device has not reported a status")
+	errMotionConcurrentWrite = errors.New("motion changed concurrently, retries exhausted")
+)
+
+// motionRecord holds the last plausible fix along with the device's totals.
+// Glitched fixes don't replace it, so a single bad reading can't skew the
+// metrics of the fix that follows; Streak counts the glitches since.
+type motionRecord struct {
+	Latitude  float64 `redis:"lat"`
+	Longitude float64 `redis:"long"`
+	Altitude  float64 `redis:"alt"`
+	Timestamp int64   `redis:"timestamp"`
+	Distance  float64 `redis:"distance"`
+	MaxSpeed  float64 `redis:"max_speed"`
+	Glitches  int64   `redis:"glitches"`
+	Streak    int64   `redis:"streak"`
+}
+
+func motionKey(id uint64) string {
+	return fmt.Sprintf("motion:%d", id)
+}
+
+func readMotion(c redis.Conn, id uint64) (motionRecord, error) {
+	var m motionRecord
+	v, err := redis.Values(c.Do("HGETALL", motionKey(id)))
+	if err != nil || len(v) == 0 {
+		return m, err
+	}
+	err = redis.ScanStruct(v, &m)
+	return m, err
+}
+
+// bearing returns the initial great-circle bearing in degrees from the first
+// point to the second.
+func bearing(lat1, long1, lat2, long2 float64) float64 {
+	dLong := radians(long2 - long1)
+	y := math.Sin(dLong) * math.Cos(radians(lat2))
+	x := math.Cos(radians(lat1))*math.Sin(radians(lat2)) -
+		math.Sin(radians(lat1))*math.Cos(radians(lat2))*math.Cos(dLong)
+	return math.Mod(math.Atan2(y, x)*180/math.Pi+360, 360)
+}
+
+// applyMotion fills in the motion fields of status from the device's last
+// plausible fix and updates its totals, under WATCH so that concurrent
+// updates of the same device don't lose each other's totals. It returns the
+// updated record.
+func applyMotion(c redis.Conn, id uint64, status *statusRecord) (motionRecord, error) {
+	for attempt := 0; attempt < motionRetries; attempt++ {
+		if _, err := c.Do("WATCH", motionKey(id)); err != nil {
+			return motionRecord{}, err
+		}
+		m, err := readMotion(c, id)
+		if err != nil {
+			c.Do("UNWATCH")
+			return motionRecord{}, err
+		}
+		next := *status
+		m.advance(&next)
+
+		c.Send("MULTI")
+		c.Send("HMSET", redis.Args{}.Add(motionKey(id)).AddFlat(&m)...)
+		reply, err := c.Do("EXEC")
+		if err != nil {
+			return motionRecord{}, err
+		}
+		if reply != nil {
+			*status = next
+			return m, nil
+		}
+	}
+	return motionRecord{}, errMotionConcurrentWrite
+}
+
+// advance moves the record on to the fix in status, filling in its motion
+// fields or flagging it as a glitch. A fix no newer than the reference, from
+// a late or concurrent update, is left without motion and doesn't move the
+// record.
+func (m *motionRecord) advance(status *statusRecord) {
+	lat, long, alt := float64(status.Latitude), float64(status.Longitude), float64(status.Altitude)
+
+	if m.Timestamp != 0 && status.Timestamp <= m.Timestamp {
+		return
+	}
+	if m.Timestamp != 0 {
+		dt := float64(status.Timestamp-m.Timestamp) / 1000
+		d := haversine(m.Latitude, m.Longitude, lat, long)
+		if d/dt > glitchSpeed {
+			if m.Streak+1 < glitchStreak && dt < glitchExpiry.Seconds() {
+				status.Glitch = true
+				m.Glitches++
+				m.Streak++
+				return
+			}
+			// Start again from this fix, with no motion to report.
+			m.Streak = 0
+			m.Latitude, m.Longitude, m.Altitude, m.Timestamp = lat, long, alt, status.Timestamp
+			return
+		}
+		status.Distance = d
+		status.Speed = d / dt
+		status.ClimbRate = (alt - m.Altitude) / dt
+		if d > 0 {
+			status.Heading = bearing(m.Latitude, m.Longitude, lat, long)
+		}
+		m.Distance += d
+		m.MaxSpeed = math.Max(m.MaxSpeed, status.Speed)
+	}
+
+	m.Streak = 0
+	m.Latitude, m.Longitude, m.Altitude, m.Timestamp = lat, long, alt, status.Timestamp
+}
+
+func (s monitorService) GetStatus(ctx context.Context, id uint64) (DeviceStatus, error) {
+	c, err := s.store.dial()
+	if err != nil {
+		return DeviceStatus{}, err
+	}
+	defer c.Close()
+
+	if _, err := readDevice(c, id); err != nil {
+		return DeviceStatus{}, err
+	}
+	return loadStatus(c, id)
+}
+
+func loadStatus(c redis.Conn, id uint64) (DeviceStatus, error) {
+	s, err := readStatus(c, id)
+	if err != nil {
+		return DeviceStatus{}, err
+	}
+	if s.Timestamp == 0 {
+		return DeviceStatus{}, errNoStatus
+	}
+	m, err := readMotion(c, id)
+	if err != nil {
+		return DeviceStatus{}, err
+	}
+	return s.status(id, m), nil
+}
+
+// status returns the record as the API's DeviceStatus, with the totals in m.
+func (s statusRecord) status(id uint64, m motionRecord) DeviceStatus {
+	return DeviceStatus{
+		DeviceID:  id,
+		Latitude:  s.Latitude,
+		Longitude: s.Longitude,
+		Altitude:  s.Altitude,
+		Battery:   s.Battery,
+		Timestamp: s.Timestamp,
+		Speed:     s.Speed,
+		Heading:   s.Heading,
+		ClimbRate: s.ClimbRate,
+		Distance:  s.Distance,
+		Glitch:    s.Glitch,
+		Totals:    MotionTotals{Distance: m.Distance, MaxSpeed: m.MaxSpeed, Glitches: m.Glitches},
+	}
+}
@@ -0,0 +1,173 @@
+package iotmonitor
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestBearing(t *testing.T) {
+	tests := []struct {
+		name                     string
+		lat1, long1, lat2, long2 float64
+		want                     float64
+	}{
+		{"north", 0, 0, 1, 0, 0},
+		{"east", 0, 0, 0, 1, 90},
+		{"south", 0, 0, -1, 0, 180},
+		{"west", 0, 0, 0, -1, 270},
+		{"north-east on the equator", 0, 0, 1, 1, 45},
+		{"London to Paris", 51.5074, -0.1278, 48.8566, 2.3522, 148.1},
+	}
+	for _, tt := range tests {
+		got := bearing(tt.lat1, tt.long1, tt.lat2, tt.long2)
+		if math.Abs(got-tt.want) > 0.1 {
+			t.Errorf("%s: bearing() = %.2f, want %.1f", tt.name, got, tt.want)
+		}
+	}
+}
+
+// fix returns a status at lat degrees north of the origin, ts seconds after
+// the first.
+func fix(lat float64, ts int64) statusRecord {
+	return statusRecord{Latitude: float32(lat), Timestamp: (1000 + ts) * 1000}
+}
+
+func TestMotionAdvance(t *testing.T) {
+	// 0.001° of latitude is about 111 m.
+	tests := []struct {
+		name   string
+		fixes  []statusRecord
+		glitch []bool
+		want   motionRecord // Latitude, Timestamp, Glitches and Streak are compared
+	}{
+		{
+			name:   "plausible track",
+			fixes:  []statusRecord{fix(0, 0), fix(0.001, 10), fix(0.002, 20)},
+			glitch: []bool{false, false, false},
+			want:   motionRecord{Latitude: 0.002, Timestamp: fix(0, 20).Timestamp},
+		},
+		{
+			name:   "single glitch keeps the reference",
+			fixes:  []statusRecord{fix(0, 0), fix(1, 10), fix(0.001, 20)},
+			glitch: []bool{false, true, false},
+			want:   motionRecord{Latitude: 0.001, Timestamp: fix(0, 20).Timestamp, Glitches: 1},
+		},
+		{
+			name:   "bad reference is replaced after a streak",
+			fixes:  []statusRecord{fix(0, 0), fix(1, 10), fix(1.001, 20), fix(1.002, 30), fix(1.003, 40)},
+			glitch: []bool{false, true, true, false, false},
+			want:   motionRecord{Latitude: 1.003, Timestamp: fix(0, 40).Timestamp, Glitches: 2},
+		},
+		{
+			name:   "stale reference is replaced",
+			fixes:  []statusRecord{fix(0, 0), fix(1, int64(glitchExpiry/time.Second))},
+			glitch: []bool{false, false},
+			want:   motionRecord{Latitude: 1, Timestamp: fix(0, int64(glitchExpiry/time.Second)).Timestamp},
+		},
+		{
+			name:   "late fix keeps the reference",
+			fixes:  []statusRecord{fix(0, 0), fix(0.001, 20), fix(0.002, 10), fix(0.002, 20), fix(0.003, 30)},
+			glitch: []bool{false, false, false, false, false},
+			want:   motionRecord{Latitude: 0.003, Timestamp: fix(0, 30).Timestamp},
+		},
+		{
+			name:   "streak is broken by a plausible fix",
+			fixes:  []statusRecord{fix(0, 0), fix(1, 10), fix(0.001, 20), fix(1, 30), fix(0.002, 40)},
+			glitch: []bool{false, true, false, true, false},
+			want:   motionRecord{Latitude: 0.002, Timestamp: fix(0, 40).Timestamp, Glitches: 2},
+		},
+	}
+	for _, tt := range tests {
+		var m motionRecord
+		for i, f := range tt.fixes {
+			m.advance(&f)
+			if f.Glitch != tt.glitch[i] {
+				t.Errorf("%s: fix %d glitch = %v, want %v", tt.name, i, f.Glitch, tt.glitch[i])
+			}
+		}
+		if math.Abs(m.Latitude-tt.want.Latitude) > 1e-6 || m.Timestamp != tt.want.Timestamp ||
+			m.Glitches != tt.want.Glitches || m.Streak != tt.want.Streak {
+			t.Errorf("%s: record = %+v, want %+v", tt.name, m, tt.want)
+		}
+	}
+}
+
+func TestMotionAdvanceMetrics(t *testing.T) {
+	m := motionRecord{Latitude: 0, Longitude: 0, Altitude: 100, Timestamp: 1000}
+	s := statusRecord{Latitude: 0, Longitude: 0.001, Altitude: 90, Timestamp: 11000}
+	m.advance(&s)
+
+	d := haversine(0, 0, 0, float64(float32(0.001)))
+	if math.Abs(s.Distance-d) > 1e-6 || math.Abs(s.Speed-d/10) > 1e-6 {
+		t.Errorf("distance, speed = %g, %g, want %g, %g", s.Distance, s.Speed, d, d/10)
+	}
+	if math.Abs(s.Heading-90) > 0.01 {
+		t.Errorf("heading = %g, want 90", s.Heading)
+	}
+	if s.ClimbRate != -1 {
+		t.Errorf("climb rate = %g, want -1", s.ClimbRate)
+	}
+	if math.Abs(m.Distance-d) > 1e-6 || m.MaxSpeed != s.Speed {
+		t.Errorf("totals = %g m, %g m/s, want %g m, %g m/s", m.Distance, m.MaxSpeed, d, s.Speed)
+	}
+}
+
+func TestApplyMotionRetriesConcurrentWrites(t *testing.T) {
+	c := newFakeRedis()
+	first := fix(0, 0)
+	if _, err := applyMotion(c, 1, &first); err != nil {
+		t.Fatal(err)
+	}
+
+	c.abortExec = motionRetries - 1
+	next := fix(0.001, 10)
+	applied, err := applyMotion(c, 1, &next)
+	if err != nil {
+		t.Fatalf("applyMotion() = %v after %d conflicts, want success", err, motionRetries-1)
+	}
+	if next.Speed == 0 {
+		t.Error("motion wasn't filled in")
+	}
+	m, err := readMotion(c, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Timestamp != next.Timestamp || math.Abs(m.Distance-next.Distance) > 1e-9 {
+		t.Errorf("stored %+v, want the second fix and its distance", m)
+	}
+	if applied != m {
+		t.Errorf("applyMotion() returned %+v, want the stored %+v", applied, m)
+	}
+
+	c.abortExec = motionRetries
+	last := fix(0.002, 20)
+	if _, err := applyMotion(c, 1, &last); err != errMotionConcurrentWrite {
+		t.Errorf("applyMotion() = %v, want %v", err, errMotionConcurrentWrite)
+	}
+	if last.Speed != 0 {
+		t.Error("status was changed although the update failed")
+	}
+}
+
+func TestStatusRecordStatus(t *testing.T) {
+	s := statusRecord{Latitude: 51.5, Battery: 80, Timestamp: 1000, Speed: 3, Glitch: true}
+	m := motionRecord{Distance: 120, MaxSpeed: 4, Glitches: 2, Streak: 1}
+	want := DeviceStatus{DeviceID: 7, Latitude: 51.5, Battery: 80, Timestamp: 1000, Speed: 3, Glitch: true, Totals: MotionTotals{Distance: 120, MaxSpeed: 4, Glitches: 2}}
+	if got := s.status(7, m); got != want {
+		t.Errorf("status() = %+v, want %+v", got, want)
+	}
+}
+
+func TestMotionAdvanceLateFix(t *testing.T) {
+	m := motionRecord{Latitude: 0.001, Timestamp: fix(0, 20).Timestamp, Distance: 111}
+	before := m
+	late := fix(0.005, 10)
+	m.advance(&late)
+	if m != before {
+		t.Errorf("record = %+v after a late fix, want %+v", m, before)
+	}
+	if late.Speed != 0 || late.Distance != 0 || late.Glitch {
+		t.Errorf("late fix = %+v, want no motion", late)
+	}
+}
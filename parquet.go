@@ -0,0 +1,295 @@
+package iotmonitor
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// A minimal Parquet writer: a flat schema of required columns written as a
+// single row group with one uncompressed, PLAIN-encoded data page per
+// column. That is all a registry export needs, and every Parquet reader
+// understands it.
+
+// Parquet physical types
+const (
+	parquetBoolean   = 0
+	parquetInt64     = 2
+	parquetDouble    = 5
+	parquetByteArray = 6
+)
+
+const (
+	parquetRequired     = 0
+	parquetUTF8         = 0 // converted type
+	parquetPlain        = 0
+	parquetRLE          = 3
+	parquetUncompressed = 0
+	parquetDataPage     = 0
+)
+
+var parquetMagic = []byte("PAR1")
+
+// parquetColumn is one column of a table being written. Values are appended
+// already PLAIN-encoded.
+type parquetColumn struct {
+	name string
+	typ  int32
+	data bytes.Buffer
+	bits []bool // boolean columns are bit-packed when the page is written
+	n    int
+}
+
+func (c *parquetColumn) appendString(s string) {
+	binary.Write(&c.data, binary.LittleEndian, uint32(len(s)))
+	c.data.WriteString(s)
+	c.n++
+}
+
+func (c *parquetColumn) appendInt64(v int64) {
+	binary.Write(&c.data, binary.LittleEndian, v)
+	c.n++
+}
+
+func (c *parquetColumn) appendDouble(v float64) {
+	binary.Write(&c.data, binary.LittleEndian, math.Float64bits(v))
+	c.n++
+}
+
+func (c *parquetColumn) appendBool(v bool) {
+	c.bits = append(c.bits, v)
+	c.n++
+}
+
+func (c *parquetColumn) page() []byte {
+	if c.typ != parquetBoolean {
+		return c.data.Bytes()
+	}
+	packed := make([]byte, (len(c.bits)+7)/8)
+	for i, v := range c.bits {
+		if v {
+			packed[i/8] |= 1 << uint(i%8)
+		}
+	}
+	return packed
+}
+
+// parquetTable is a set of equally long columns.
+type parquetTable struct {
+	columns []*parquetColumn
+}
+
+func (t *parquetTable) column(name string, typ int32) *parquetColumn {
+	c := &parquetColumn{name: name, typ: typ}
+	t.columns = append(t.columns, c)
+	return c
+}
+
+type columnChunk struct {
+	offset int64
+	size   int64
+}
+
+// writeTo writes the table as a complete Parquet file.
+func (t *parquetTable) writeTo(w io.Writer) error {
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+	cw.Write(parquetMagic)
+
+	rows := 0
+	chunks := make([]columnChunk, len(t.columns))
+	for i, c := range t.columns {
+		if i > 0 && c.n != rows {
+			return fmt.Errorf("parquet column %s has %d values, want %d", c.name, c.n, rows)
+		}
+		rows = c.n
+
+		data := c.page()
+		var header thriftWriter
+		header.i32(1, parquetDataPage)
+		header.i32(2, int32(len(data)))
+		header.i32(3, int32(len(data)))
+		header.beginStruct(5)
+		header.i32(1, int32(c.n))
+		header.i32(2, parquetPlain)
+		header.i32(3, parquetRLE)
+		header.i32(4, parquetRLE)
+		header.endStruct()
+		header.stop()
+
+		chunks[i].offset = cw.n
+		cw.Write(header.Bytes())
+		cw.Write(data)
+		chunks[i].size = cw.n - chunks[i].offset
+	}
+
+	var meta thriftWriter
+	meta.i32(1, 1)
+	meta.beginList(2, thriftStruct, len(t.columns)+1)
+	meta.str(4, "schema")
+	meta.i32(5, int32(len(t.columns)))
+	meta.endElement()
+	for _, c := range t.columns {
+		meta.i32(1, c.typ)
+		meta.i32(3, parquetRequired)
+		meta.str(4, c.name)
+		if c.typ == parquetByteArray {
+			meta.i32(6, parquetUTF8)
+		}
+		meta.endElement()
+	}
+	meta.endList()
+	meta.i64(3, int64(rows))
+	meta.beginList(4, thriftStruct, 1)
+	meta.beginList(1, thriftStruct, len(t.columns))
+	var total int64
+	for i, c := range t.columns {
+		meta.i64(2, chunks[i].offset)
+		meta.beginStruct(3)
+		meta.i32(1, c.typ)
+		meta.beginList(2, thriftI32, 2)
+		meta.zigzag(parquetPlain)
+		meta.zigzag(parquetRLE)
+		meta.endList()
+		meta.beginList(3, thriftBinary, 1)
+		meta.binary(c.name)
+		meta.endList()
+		meta.i32(4, parquetUncompressed)
+		meta.i64(5, int64(c.n))
+		meta.i64(6, chunks[i].size)
+		meta.i64(7, chunks[i].size)
+		meta.i64(9, chunks[i].offset)
+		meta.endStruct()
+		meta.endElement()
+		total += chunks[i].size
+	}
+	meta.endList()
+	meta.i64(2, total)
+	meta.i64(3, int64(rows))
+	meta.endElement()
+	meta.endList()
+	meta.str(6, "iotmonitor")
+	meta.stop()
+
+	cw.Write(meta.Bytes())
+	binary.Write(cw, binary.LittleEndian, uint32(meta.Len()))
+	cw.Write(parquetMagic)
+	if cw.err != nil {
+		return cw.err
+	}
+	return cw.w.(*bufio.Writer).Flush()
+}
+
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
+
+// Thrift compact protocol types
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter encodes the Thrift compact protocol that Parquet uses for
+// its page headers and footer. A list is written as beginList, its
+// elements, then endList; struct elements each end with endElement.
+type thriftWriter struct {
+	bytes.Buffer
+	last  int16
+	stack []int16
+}
+
+func (t *thriftWriter) varint(v int64) {
+	var b [binary.MaxVarintLen64]byte
+	t.Write(b[:binary.PutUvarint(b[:], uint64(v))])
+}
+
+func (t *thriftWriter) zigzag(v int64) {
+	t.varint((v << 1) ^ (v >> 63))
+}
+
+func (t *thriftWriter) field(id int16, typ byte) {
+	if delta := id - t.last; delta > 0 && delta <= 15 {
+		t.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		t.WriteByte(typ)
+		t.zigzag(int64(id))
+	}
+	t.last = id
+}
+
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.field(id, thriftI32)
+	t.zigzag(int64(v))
+}
+
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.field(id, thriftI64)
+	t.zigzag(v)
+}
+
+func (t *thriftWriter) binary(s string) {
+	t.varint(int64(len(s)))
+	t.WriteString(s)
+}
+
+func (t *thriftWriter) str(id int16, s string) {
+	t.field(id, thriftBinary)
+	t.binary(s)
+}
+
+func (t *thriftWriter) beginStruct(id int16) {
+	t.field(id, thriftStruct)
+	t.stack = append(t.stack, t.last)
+	t.last = 0
+}
+
+func (t *thriftWriter) endStruct() {
+	t.stop()
+	t.last = t.stack[len(t.stack)-1]
+	t.stack = t.stack[:len(t.stack)-1]
+}
+
+func (t *thriftWriter) beginList(id int16, elem byte, size int) {
+	t.field(id, thriftList)
+	if size < 15 {
+		t.WriteByte(byte(size)<<4 | elem)
+	} else {
+		t.WriteByte(0xf0 | elem)
+		t.varint(int64(size))
+	}
+	t.stack = append(t.stack, t.last)
+	t.last = 0
+}
+
+func (t *thriftWriter) endList() {
+	t.last = t.stack[len(t.stack)-1]
+	t.stack = t.stack[:len(t.stack)-1]
+}
+
+// endElement ends a struct list element.
+func (t *thriftWriter) endElement() {
+	t.stop()
+	t.last = 0
+}
+
+func (t *thriftWriter) stop() {
+	t.WriteByte(0)
+}
@@ -0,0 +1,194 @@
+package iotmonitor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// thriftReader decodes the Thrift compact protocol into maps of field IDs
+// to values, for checking what thriftWriter wrote.
+type thriftReader struct {
+	b []byte
+}
+
+func (r *thriftReader) byte() byte {
+	c := r.b[0]
+	r.b = r.b[1:]
+	return c
+}
+
+func (r *thriftReader) uvarint() int64 {
+	v, n := binary.Uvarint(r.b)
+	r.b = r.b[n:]
+	return int64(v)
+}
+
+func (r *thriftReader) zigzag() int64 {
+	v := uint64(r.uvarint())
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (r *thriftReader) value(typ byte) interface{} {
+	switch typ {
+	case thriftI32, thriftI64:
+		return r.zigzag()
+	case thriftBinary:
+		n := r.uvarint()
+		s := string(r.b[:n])
+		r.b = r.b[n:]
+		return s
+	case thriftList:
+		h := r.byte()
+		size, elem := int64(h>>4), h&0x0f
+		if size == 15 {
+			size = r.uvarint()
+		}
+		list := make([]interface{}, size)
+		for i := range list {
+			list[i] = r.value(elem)
+		}
+		return list
+	case thriftStruct:
+		return r.structure()
+	}
+	panic("unexpected thrift type")
+}
+
+func (r *thriftReader) structure() map[int16]interface{} {
+	s := make(map[int16]interface{})
+	var id int16
+	for {
+		h := r.byte()
+		if h == 0 {
+			return s
+		}
+		if delta := int16(h >> 4); delta != 0 {
+			id += delta
+		} else {
+			id = int16(r.zigzag())
+		}
+		s[id] = r.value(h & 0x0f)
+	}
+}
+
+func TestParquetTable(t *testing.T) {
+	var table parquetTable
+	ids := table.column("id", parquetInt64)
+	names := table.column("name", parquetByteArray)
+	levels := table.column("level", parquetDouble)
+	flags := table.column("online", parquetBoolean)
+	online := []bool{true, false, true, true, false, false, false, true, true}
+	for i, v := range online {
+		ids.appendInt64(int64(i + 1))
+		names.appendString(string(rune('a' + i)))
+		levels.appendDouble(float64(i) / 2)
+		flags.appendBool(v)
+	}
+
+	var buf bytes.Buffer
+	if err := table.writeTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	file := buf.Bytes()
+	if !bytes.HasPrefix(file, parquetMagic) || !bytes.HasSuffix(file, parquetMagic) {
+		t.Fatal("file isn't framed by the Parquet magic")
+	}
+	footerLen := int(binary.LittleEndian.Uint32(file[len(file)-8:]))
+	footer := &thriftReader{b: file[len(file)-8-footerLen : len(file)-8]}
+	meta := footer.structure()
+	if len(footer.b) != 0 {
+		t.Errorf("%d bytes left after the footer", len(footer.b))
+	}
+
+	if meta[3] != int64(len(online)) {
+		t.Errorf("num_rows = %v, want %d", meta[3], len(online))
+	}
+	schema := meta[2].([]interface{})
+	wantSchema := []map[int16]interface{}{
+		{4: "schema", 5: int64(4)},
+		{1: int64(parquetInt64), 3: int64(parquetRequired), 4: "id"},
+		{1: int64(parquetByteArray), 3: int64(parquetRequired), 4: "name", 6: int64(parquetUTF8)},
+		{1: int64(parquetDouble), 3: int64(parquetRequired), 4: "level"},
+		{1: int64(parquetBoolean), 3: int64(parquetRequired), 4: "online"},
+	}
+	for i, want := range wantSchema {
+		if !reflect.DeepEqual(schema[i], want) {
+			t.Errorf("schema element %d = %v, want %v", i, schema[i], want)
+		}
+	}
+
+	group := meta[4].([]interface{})[0].(map[int16]interface{})
+	chunks := group[1].([]interface{})
+	if len(chunks) != 4 || group[3] != int64(len(online)) {
+		t.Fatalf("row group has %d columns and %v rows", len(chunks), group[3])
+	}
+	var values [][]byte
+	for i, c := range chunks {
+		chunk := c.(map[int16]interface{})
+		cm := chunk[3].(map[int16]interface{})
+		offset := cm[9].(int64)
+		if chunk[2] != offset || cm[5] != int64(len(online)) {
+			t.Errorf("column %d: file offset %v, page offset %d, %v values", i, chunk[2], offset, cm[5])
+		}
+		page := &thriftReader{b: file[offset : offset+cm[6].(int64)]}
+		header := page.structure()
+		size := header[2].(int64)
+		if header[1] != int64(parquetDataPage) || header[3] != size || int64(len(page.b)) != size {
+			t.Errorf("column %d: page header %v with %d bytes of data", i, header, len(page.b))
+		}
+		values = append(values, page.b)
+	}
+
+	for i := range online {
+		if v := int64(binary.LittleEndian.Uint64(values[0][8*i:])); v != int64(i+1) {
+			t.Errorf("id %d = %d", i, v)
+		}
+		if n := binary.LittleEndian.Uint32(values[1][5*i:]); n != 1 || values[1][5*i+4] != byte('a'+i) {
+			t.Errorf("name %d = %q", i, values[1][5*i:5*i+5])
+		}
+		if v := math.Float64frombits(binary.LittleEndian.Uint64(values[2][8*i:])); v != float64(i)/2 {
+			t.Errorf("level %d = %g", i, v)
+		}
+		if v := values[3][i/8]&(1<<uint(i%8)) != 0; v != online[i] {
+			t.Errorf("online %d = %v", i, v)
+		}
+	}
+	if len(values[3]) != 2 {
+		t.Errorf("%d booleans packed into %d bytes", len(online), len(values[3]))
+	}
+}
+
+func TestParquetTableRaggedColumns(t *testing.T) {
+	var table parquetTable
+	table.column("id", parquetInt64).appendInt64(1)
+	table.column("name", parquetByteArray)
+	if err := table.writeTo(&bytes.Buffer{}); err == nil {
+		t.Error("writeTo() accepted columns of different lengths")
+	}
+}
+
+func TestThriftWriterLongFieldDeltaAndList(t *testing.T) {
+	var w thriftWriter
+	w.i32(1, -7)
+	w.i64(20, 1<<40)
+	w.beginList(21, thriftI32, 20)
+	for i := 0; i < 20; i++ {
+		w.zigzag(int64(i))
+	}
+	w.endList()
+	w.str(22, "x")
+	w.stop()
+
+	got := (&thriftReader{b: w.Bytes()}).structure()
+	list := make([]interface{}, 20)
+	for i := range list {
+		list[i] = int64(i)
+	}
+	want := map[int16]interface{}{1: int64(-7), 20: int64(1 << 40), 21: list, 22: "x"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("decoded %v, want %v", got, want)
+	}
+}
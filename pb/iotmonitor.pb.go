@@ -10,12 +10,159 @@ It is generated from these files:
 
 It has these top-level messages:
 	RegisterDeviceRequest
+	Label
 	RegisterDeviceReply
 	StatusUpdateRequest
 	StatusUpdateReply
 	TelemetrySubmitRequest
+	TelemetryValue
 	TelemetrySubmitReply
 	Location
+	Rule
+	Alert
+	CreateRuleRequest
+	CreateRuleReply
+	GetRuleRequest
+	GetRuleReply
+	UpdateRuleRequest
+	UpdateRuleReply
+	DeleteRuleRequest
+	DeleteRuleReply
+	ListRulesRequest
+	ListRulesReply
+	ActiveAlertsRequest
+	ActiveAlertsReply
+	Event
+	Webhook
+	DeadLetter
+	CreateWebhookRequest
+	CreateWebhookReply
+	ListWebhooksRequest
+	ListWebhooksReply
+	DeleteWebhookRequest
+	DeleteWebhookReply
+	DeadLettersRequest
+	DeadLettersReply
+	Device
+	GetDeviceRequest
+	GetDeviceReply
+	UpdateDeviceRequest
+	UpdateDeviceReply
+	DeleteDeviceRequest
+	DeleteDeviceReply
+	ListDevicesRequest
+	ListDevicesReply
+	Geofence
+	CreateGeofenceRequest
+	CreateGeofenceReply
+	UpdateGeofenceRequest
+	UpdateGeofenceReply
+	DeleteGeofenceRequest
+	DeleteGeofenceReply
+	ListGeofencesRequest
+	ListGeofencesReply
+	DeviceLocation
+	DeviceFilter
+	NearbyDevicesRequest
+	NearbyDevicesReply
+	DevicesInBoxRequest
+	DevicesInBoxReply
+	MotionTotals
+	DeviceStatus
+	GetStatusRequest
+	GetStatusReply
+	BatteryEstimate
+	BatterySample
+	BatteryHistoryRequest
+	BatteryHistoryReply
+	TelemetryPoint
+	TelemetrySeries
+	QueryTelemetryRequest
+	QueryTelemetryReply
+	AggregateGroup
+	AggregateTelemetryRequest
+	AggregateTelemetryReply
+	SetLabelsRequest
+	SetLabelsReply
+	ListStatusesRequest
+	ListStatusesReply
+	Group
+	CreateGroupRequest
+	CreateGroupReply
+	UpdateGroupRequest
+	UpdateGroupReply
+	DeleteGroupRequest
+	DeleteGroupReply
+	ListGroupsRequest
+	ListGroupsReply
+	AddGroupMembersRequest
+	AddGroupMembersReply
+	RemoveGroupMembersRequest
+	RemoveGroupMembersReply
+	RelayStatusRequest
+	RelayStatusReply
+	RelayTelemetryRequest
+	RelayTelemetryReply
+	TopologyNode
+	GetTopologyRequest
+	GetTopologyReply
+	Twin
+	DesiredState
+	GetTwinRequest
+	GetTwinReply
+	UpdateDesiredRequest
+	UpdateDesiredReply
+	ReportStateRequest
+	ReportStateReply
+	WatchDesiredRequest
+	Command
+	EnqueueCommandRequest
+	EnqueueCommandReply
+	AckCommandRequest
+	AckCommandReply
+	CommandHistoryRequest
+	CommandHistoryReply
+	WatchCommandsRequest
+	FirmwareReport
+	FirmwareOffer
+	FirmwareArtifact
+	CampaignStats
+	Campaign
+	UploadFirmwareRequest
+	UploadFirmwareReply
+	ListFirmwareRequest
+	ListFirmwareReply
+	DownloadFirmwareRequest
+	DownloadFirmwareReply
+	CreateCampaignRequest
+	CreateCampaignReply
+	GetCampaignRequest
+	GetCampaignReply
+	ListCampaignsRequest
+	ListCampaignsReply
+	ControlCampaignRequest
+	ControlCampaignReply
+	ReportFirmwareRequest
+	ReportFirmwareReply
+	SchemaViolation
+	MetricSpec
+	TelemetrySchema
+	SetTelemetrySchemaRequest
+	SetTelemetrySchemaReply
+	GetTelemetrySchemaRequest
+	GetTelemetrySchemaReply
+	ListTelemetrySchemasRequest
+	ListTelemetrySchemasReply
+	DeleteTelemetrySchemaRequest
+	DeleteTelemetrySchemaReply
+	DeviceImport
+	ImportResult
+	ImportDevicesRequest
+	ImportDevicesReply
+	DeviceExport
+	ExportDevicesRequest
+	ExportDevicesReply
+	WatchEventsRequest
 */
 package pb
 
@@ -60,11 +207,37 @@ func (x DeviceType) String() string {
 }
 func (DeviceType) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{0} }
 
+type RuleKind int32
+
+const (
+	RuleKind_THRESHOLD      RuleKind = 0
+	RuleKind_RATE_OF_CHANGE RuleKind = 1
+	RuleKind_ABSENCE        RuleKind = 2
+)
+
+var RuleKind_name = map[int32]string{
+	0: "THRESHOLD",
+	1: "RATE_OF_CHANGE",
+	2: "ABSENCE",
+}
+var RuleKind_value = map[string]int32{
+	"THRESHOLD":      0,
+	"RATE_OF_CHANGE": 1,
+	"ABSENCE":        2,
+}
+
+func (x RuleKind) String() string {
+	return proto.EnumName(RuleKind_name, int32(x))
+}
+func (RuleKind) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
 type RegisterDeviceRequest struct {
 	Name         string     `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
 	Serialnumber string     `protobuf:"bytes,2,opt,name=serialnumber" json:"serialnumber,omitempty"`
 	Owner        string     `protobuf:"bytes,3,opt,name=owner" json:"owner,omitempty"`
 	Devicetype   DeviceType `protobuf:"varint,4,opt,name=devicetype,enum=pb.DeviceType" json:"devicetype,omitempty"`
+	Labels       []*Label   `protobuf:"bytes,5,rep,name=labels" json:"labels,omitempty"`
+	Gatewayid    uint64     `protobuf:"varint,6,opt,name=gatewayid" json:"gatewayid,omitempty"`
 }
 
 func (m *RegisterDeviceRequest) Reset()                    { *m = RegisterDeviceRequest{} }
@@ -100,6 +273,44 @@ func (m *RegisterDeviceRequest) GetDevicetype() DeviceType {
 	return DeviceType_DRONE
 }
 
+func (m *RegisterDeviceRequest) GetLabels() []*Label {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+func (m *RegisterDeviceRequest) GetGatewayid() uint64 {
+	if m != nil {
+		return m.Gatewayid
+	}
+	return 0
+}
+
+type Label struct {
+	Key   string `protobuf:"bytes,1,opt,name=key" json:"key,omitempty"`
+	Value string `protobuf:"bytes,2,opt,name=value" json:"value,omitempty"`
+}
+
+func (m *Label) Reset()                    { *m = Label{} }
+func (m *Label) String() string            { return proto.CompactTextString(m) }
+func (*Label) ProtoMessage()               {}
+func (*Label) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+func (m *Label) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *Label) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
 type RegisterDeviceReply struct {
 	Registered bool   `protobuf:"varint,1,opt,name=registered" json:"registered,omitempty"`
 	Deviceid   uint64 `protobuf:"varint,2,opt,name=deviceid" json:"deviceid,omitempty"`
@@ -109,7 +320,7 @@ type RegisterDeviceReply struct {
 func (m *RegisterDeviceReply) Reset()                    { *m = RegisterDeviceReply{} }
 func (m *RegisterDeviceReply) String() string            { return proto.CompactTextString(m) }
 func (*RegisterDeviceReply) ProtoMessage()               {}
-func (*RegisterDeviceReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+func (*RegisterDeviceReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
 
 func (m *RegisterDeviceReply) GetRegistered() bool {
 	if m != nil {
@@ -133,15 +344,17 @@ func (m *RegisterDeviceReply) GetErr() string {
 }
 
 type StatusUpdateRequest struct {
-	Deviceid         uint64    `protobuf:"varint,1,opt,name=deviceid" json:"deviceid,omitempty"`
-	Location         *Location `protobuf:"bytes,2,opt,name=location" json:"location,omitempty"`
-	Batteryremaining uint32    `protobuf:"varint,3,opt,name=batteryremaining" json:"batteryremaining,omitempty"`
+	Deviceid         uint64          `protobuf:"varint,1,opt,name=deviceid" json:"deviceid,omitempty"`
+	Location         *Location       `protobuf:"bytes,2,opt,name=location" json:"location,omitempty"`
+	Batteryremaining uint32          `protobuf:"varint,3,opt,name=batteryremaining" json:"batteryremaining,omitempty"`
+	Firmware         *FirmwareReport `protobuf:"bytes,4,opt,name=firmware" json:"firmware,omitempty"`
+	Deliver          bool            `protobuf:"varint,5,opt,name=deliver" json:"deliver,omitempty"`
 }
 
 func (m *StatusUpdateRequest) Reset()                    { *m = StatusUpdateRequest{} }
 func (m *StatusUpdateRequest) String() string            { return proto.CompactTextString(m) }
 func (*StatusUpdateRequest) ProtoMessage()               {}
-func (*StatusUpdateRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+func (*StatusUpdateRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
 
 func (m *StatusUpdateRequest) GetDeviceid() uint64 {
 	if m != nil {
@@ -164,15 +377,32 @@ func (m *StatusUpdateRequest) GetBatteryremaining() uint32 {
 	return 0
 }
 
+func (m *StatusUpdateRequest) GetFirmware() *FirmwareReport {
+	if m != nil {
+		return m.Firmware
+	}
+	return nil
+}
+
+func (m *StatusUpdateRequest) GetDeliver() bool {
+	if m != nil {
+		return m.Deliver
+	}
+	return false
+}
+
 type StatusUpdateReply struct {
-	Acknowledged bool   `protobuf:"varint,1,opt,name=acknowledged" json:"acknowledged,omitempty"`
-	Err          string `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+	Acknowledged bool           `protobuf:"varint,1,opt,name=acknowledged" json:"acknowledged,omitempty"`
+	Err          string         `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+	Desired      *DesiredState  `protobuf:"bytes,3,opt,name=desired" json:"desired,omitempty"`
+	Commands     []*Command     `protobuf:"bytes,4,rep,name=commands" json:"commands,omitempty"`
+	Firmware     *FirmwareOffer `protobuf:"bytes,5,opt,name=firmware" json:"firmware,omitempty"`
 }
 
 func (m *StatusUpdateReply) Reset()                    { *m = StatusUpdateReply{} }
 func (m *StatusUpdateReply) String() string            { return proto.CompactTextString(m) }
 func (*StatusUpdateReply) ProtoMessage()               {}
-func (*StatusUpdateReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+func (*StatusUpdateReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
 
 func (m *StatusUpdateReply) GetAcknowledged() bool {
 	if m != nil {
@@ -188,15 +418,37 @@ func (m *StatusUpdateReply) GetErr() string {
 	return ""
 }
 
+func (m *StatusUpdateReply) GetDesired() *DesiredState {
+	if m != nil {
+		return m.Desired
+	}
+	return nil
+}
+
+func (m *StatusUpdateReply) GetCommands() []*Command {
+	if m != nil {
+		return m.Commands
+	}
+	return nil
+}
+
+func (m *StatusUpdateReply) GetFirmware() *FirmwareOffer {
+	if m != nil {
+		return m.Firmware
+	}
+	return nil
+}
+
 type TelemetrySubmitRequest struct {
-	Deviceid uint64             `protobuf:"varint,1,opt,name=deviceid" json:"deviceid,omitempty"`
-	Readings map[string]float32 `protobuf:"bytes,2,rep,name=readings" json:"readings,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed32,2,opt,name=value"`
+	Deviceid uint64                     `protobuf:"varint,1,opt,name=deviceid" json:"deviceid,omitempty"`
+	Readings map[string]float32         `protobuf:"bytes,2,rep,name=readings" json:"readings,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed32,2,opt,name=value"`
+	Values   map[string]*TelemetryValue `protobuf:"bytes,3,rep,name=values" json:"values,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 }
 
 func (m *TelemetrySubmitRequest) Reset()                    { *m = TelemetrySubmitRequest{} }
 func (m *TelemetrySubmitRequest) String() string            { return proto.CompactTextString(m) }
 func (*TelemetrySubmitRequest) ProtoMessage()               {}
-func (*TelemetrySubmitRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+func (*TelemetrySubmitRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
 
 func (m *TelemetrySubmitRequest) GetDeviceid() uint64 {
 	if m != nil {
@@ -212,15 +464,87 @@ func (m *TelemetrySubmitRequest) GetReadings() map[string]float32 {
 	return nil
 }
 
+func (m *TelemetrySubmitRequest) GetValues() map[string]*TelemetryValue {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+type TelemetryValue struct {
+	Type        string    `protobuf:"bytes,1,opt,name=type" json:"type,omitempty"`
+	Doublevalue float64   `protobuf:"fixed64,2,opt,name=doublevalue" json:"doublevalue,omitempty"`
+	Intvalue    int64     `protobuf:"varint,3,opt,name=intvalue" json:"intvalue,omitempty"`
+	Boolvalue   bool      `protobuf:"varint,4,opt,name=boolvalue" json:"boolvalue,omitempty"`
+	Stringvalue string    `protobuf:"bytes,5,opt,name=stringvalue" json:"stringvalue,omitempty"`
+	Vectorvalue []float64 `protobuf:"fixed64,6,rep,name=vectorvalue,packed" json:"vectorvalue,omitempty"`
+	Unit        string    `protobuf:"bytes,7,opt,name=unit" json:"unit,omitempty"`
+}
+
+func (m *TelemetryValue) Reset()                    { *m = TelemetryValue{} }
+func (m *TelemetryValue) String() string            { return proto.CompactTextString(m) }
+func (*TelemetryValue) ProtoMessage()               {}
+func (*TelemetryValue) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
+func (m *TelemetryValue) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *TelemetryValue) GetDoublevalue() float64 {
+	if m != nil {
+		return m.Doublevalue
+	}
+	return 0
+}
+
+func (m *TelemetryValue) GetIntvalue() int64 {
+	if m != nil {
+		return m.Intvalue
+	}
+	return 0
+}
+
+func (m *TelemetryValue) GetBoolvalue() bool {
+	if m != nil {
+		return m.Boolvalue
+	}
+	return false
+}
+
+func (m *TelemetryValue) GetStringvalue() string {
+	if m != nil {
+		return m.Stringvalue
+	}
+	return ""
+}
+
+func (m *TelemetryValue) GetVectorvalue() []float64 {
+	if m != nil {
+		return m.Vectorvalue
+	}
+	return nil
+}
+
+func (m *TelemetryValue) GetUnit() string {
+	if m != nil {
+		return m.Unit
+	}
+	return ""
+}
+
 type TelemetrySubmitReply struct {
-	Acknowledged bool   `protobuf:"varint,1,opt,name=acknowledged" json:"acknowledged,omitempty"`
-	Err          string `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+	Acknowledged bool               `protobuf:"varint,1,opt,name=acknowledged" json:"acknowledged,omitempty"`
+	Err          string             `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+	Violations   []*SchemaViolation `protobuf:"bytes,3,rep,name=violations" json:"violations,omitempty"`
 }
 
 func (m *TelemetrySubmitReply) Reset()                    { *m = TelemetrySubmitReply{} }
 func (m *TelemetrySubmitReply) String() string            { return proto.CompactTextString(m) }
 func (*TelemetrySubmitReply) ProtoMessage()               {}
-func (*TelemetrySubmitReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+func (*TelemetrySubmitReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
 
 func (m *TelemetrySubmitReply) GetAcknowledged() bool {
 	if m != nil {
@@ -236,6 +560,13 @@ func (m *TelemetrySubmitReply) GetErr() string {
 	return ""
 }
 
+func (m *TelemetrySubmitReply) GetViolations() []*SchemaViolation {
+	if m != nil {
+		return m.Violations
+	}
+	return nil
+}
+
 type Location struct {
 	Longitude float32 `protobuf:"fixed32,1,opt,name=longitude" json:"longitude,omitempty"`
 	Latitude  float32 `protobuf:"fixed32,2,opt,name=latitude" json:"latitude,omitempty"`
@@ -245,7 +576,7 @@ type Location struct {
 func (m *Location) Reset()                    { *m = Location{} }
 func (m *Location) String() string            { return proto.CompactTextString(m) }
 func (*Location) ProtoMessage()               {}
-func (*Location) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+func (*Location) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
 
 func (m *Location) GetLongitude() float32 {
 	if m != nil {
@@ -268,132 +599,6384 @@ func (m *Location) GetAltitude() float32 {
 	return 0
 }
 
-func init() {
-	proto.RegisterType((*RegisterDeviceRequest)(nil), "pb.RegisterDeviceRequest")
-	proto.RegisterType((*RegisterDeviceReply)(nil), "pb.RegisterDeviceReply")
-	proto.RegisterType((*StatusUpdateRequest)(nil), "pb.StatusUpdateRequest")
-	proto.RegisterType((*StatusUpdateReply)(nil), "pb.StatusUpdateReply")
-	proto.RegisterType((*TelemetrySubmitRequest)(nil), "pb.TelemetrySubmitRequest")
-	proto.RegisterType((*TelemetrySubmitReply)(nil), "pb.TelemetrySubmitReply")
-	proto.RegisterType((*Location)(nil), "pb.Location")
-	proto.RegisterEnum("pb.DeviceType", DeviceType_name, DeviceType_value)
+type Rule struct {
+	Ruleid     uint64   `protobuf:"varint,1,opt,name=ruleid" json:"ruleid,omitempty"`
+	Name       string   `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+	Kind       RuleKind `protobuf:"varint,3,opt,name=kind,enum=pb.RuleKind" json:"kind,omitempty"`
+	Metric     string   `protobuf:"bytes,4,opt,name=metric" json:"metric,omitempty"`
+	Operator   string   `protobuf:"bytes,5,opt,name=operator" json:"operator,omitempty"`
+	Threshold  float64  `protobuf:"fixed64,6,opt,name=threshold" json:"threshold,omitempty"`
+	Window     int64    `protobuf:"varint,7,opt,name=window" json:"window,omitempty"`
+	Deviceid   uint64   `protobuf:"varint,8,opt,name=deviceid" json:"deviceid,omitempty"`
+	Devicetype string   `protobuf:"bytes,9,opt,name=devicetype" json:"devicetype,omitempty"`
+	Owner      string   `protobuf:"bytes,10,opt,name=owner" json:"owner,omitempty"`
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
+func (m *Rule) Reset()                    { *m = Rule{} }
+func (m *Rule) String() string            { return proto.CompactTextString(m) }
+func (*Rule) ProtoMessage()               {}
+func (*Rule) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
+func (m *Rule) GetRuleid() uint64 {
+	if m != nil {
+		return m.Ruleid
+	}
+	return 0
+}
 
-// Client API for Monitor service
+func (m *Rule) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
 
-type MonitorClient interface {
-	RegisterDevice(ctx context.Context, in *RegisterDeviceRequest, opts ...grpc.CallOption) (*RegisterDeviceReply, error)
-	UpdateDeviceStatus(ctx context.Context, in *StatusUpdateRequest, opts ...grpc.CallOption) (*StatusUpdateReply, error)
-	SubmitTelemetry(ctx context.Context, in *TelemetrySubmitRequest, opts ...grpc.CallOption) (*TelemetrySubmitReply, error)
+func (m *Rule) GetKind() RuleKind {
+	if m != nil {
+		return m.Kind
+	}
+	return RuleKind_THRESHOLD
 }
 
-type monitorClient struct {
-	cc *grpc.ClientConn
+func (m *Rule) GetMetric() string {
+	if m != nil {
+		return m.Metric
+	}
+	return ""
 }
 
-func NewMonitorClient(cc *grpc.ClientConn) MonitorClient {
-	return &monitorClient{cc}
+func (m *Rule) GetOperator() string {
+	if m != nil {
+		return m.Operator
+	}
+	return ""
 }
 
-func (c *monitorClient) RegisterDevice(ctx context.Context, in *RegisterDeviceRequest, opts ...grpc.CallOption) (*RegisterDeviceReply, error) {
-	out := new(RegisterDeviceReply)
-	err := grpc.Invoke(ctx, "/pb.Monitor/RegisterDevice", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
+func (m *Rule) GetThreshold() float64 {
+	if m != nil {
+		return m.Threshold
 	}
-	return out, nil
+	return 0
 }
 
-func (c *monitorClient) UpdateDeviceStatus(ctx context.Context, in *StatusUpdateRequest, opts ...grpc.CallOption) (*StatusUpdateReply, error) {
-	out := new(StatusUpdateReply)
-	err := grpc.Invoke(ctx, "/pb.Monitor/UpdateDeviceStatus", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
+func (m *Rule) GetWindow() int64 {
+	if m != nil {
+		return m.Window
 	}
-	return out, nil
+	return 0
 }
 
-func (c *monitorClient) SubmitTelemetry(ctx context.Context, in *TelemetrySubmitRequest, opts ...grpc.CallOption) (*TelemetrySubmitReply, error) {
-	out := new(TelemetrySubmitReply)
-	err := grpc.Invoke(ctx, "/pb.Monitor/SubmitTelemetry", in, out, c.cc, opts...)
-	if err != nil {
-		return nil, err
+func (m *Rule) GetDeviceid() uint64 {
+	if m != nil {
+		return m.Deviceid
 	}
-	return out, nil
+	return 0
 }
 
-// Server API for Monitor service
+func (m *Rule) GetDevicetype() string {
+	if m != nil {
+		return m.Devicetype
+	}
+	return ""
+}
 
-type MonitorServer interface {
-	RegisterDevice(context.Context, *RegisterDeviceRequest) (*RegisterDeviceReply, error)
-	UpdateDeviceStatus(context.Context, *StatusUpdateRequest) (*StatusUpdateReply, error)
-	SubmitTelemetry(context.Context, *TelemetrySubmitRequest) (*TelemetrySubmitReply, error)
+func (m *Rule) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
 }
 
-func RegisterMonitorServer(s *grpc.Server, srv MonitorServer) {
-	s.RegisterService(&_Monitor_serviceDesc, srv)
+type Alert struct {
+	Ruleid     uint64  `protobuf:"varint,1,opt,name=ruleid" json:"ruleid,omitempty"`
+	Deviceid   uint64  `protobuf:"varint,2,opt,name=deviceid" json:"deviceid,omitempty"`
+	State      string  `protobuf:"bytes,3,opt,name=state" json:"state,omitempty"`
+	Value      float64 `protobuf:"fixed64,4,opt,name=value" json:"value,omitempty"`
+	Message    string  `protobuf:"bytes,5,opt,name=message" json:"message,omitempty"`
+	Firedat    int64   `protobuf:"varint,6,opt,name=firedat" json:"firedat,omitempty"`
+	Resolvedat int64   `protobuf:"varint,7,opt,name=resolvedat" json:"resolvedat,omitempty"`
+	Geofenceid uint64  `protobuf:"varint,8,opt,name=geofenceid" json:"geofenceid,omitempty"`
+	Source     string  `protobuf:"bytes,9,opt,name=source" json:"source,omitempty"`
 }
 
-func _Monitor_RegisterDevice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(RegisterDeviceRequest)
-	if err := dec(in); err != nil {
-		return nil, err
-	}
-	if interceptor == nil {
-		return srv.(MonitorServer).RegisterDevice(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/pb.Monitor/RegisterDevice",
-	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(MonitorServer).RegisterDevice(ctx, req.(*RegisterDeviceRequest))
+func (m *Alert) Reset()                    { *m = Alert{} }
+func (m *Alert) String() string            { return proto.CompactTextString(m) }
+func (*Alert) ProtoMessage()               {}
+func (*Alert) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+
+func (m *Alert) GetRuleid() uint64 {
+	if m != nil {
+		return m.Ruleid
 	}
-	return interceptor(ctx, in, info, handler)
+	return 0
 }
 
-func _Monitor_UpdateDeviceStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(StatusUpdateRequest)
-	if err := dec(in); err != nil {
-		return nil, err
-	}
-	if interceptor == nil {
-		return srv.(MonitorServer).UpdateDeviceStatus(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/pb.Monitor/UpdateDeviceStatus",
+func (m *Alert) GetDeviceid() uint64 {
+	if m != nil {
+		return m.Deviceid
 	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(MonitorServer).UpdateDeviceStatus(ctx, req.(*StatusUpdateRequest))
+	return 0
+}
+
+func (m *Alert) GetState() string {
+	if m != nil {
+		return m.State
 	}
-	return interceptor(ctx, in, info, handler)
+	return ""
 }
 
-func _Monitor_SubmitTelemetry_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(TelemetrySubmitRequest)
-	if err := dec(in); err != nil {
-		return nil, err
+func (m *Alert) GetValue() float64 {
+	if m != nil {
+		return m.Value
 	}
-	if interceptor == nil {
-		return srv.(MonitorServer).SubmitTelemetry(ctx, in)
+	return 0
+}
+
+func (m *Alert) GetMessage() string {
+	if m != nil {
+		return m.Message
 	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/pb.Monitor/SubmitTelemetry",
+	return ""
+}
+
+func (m *Alert) GetFiredat() int64 {
+	if m != nil {
+		return m.Firedat
 	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(MonitorServer).SubmitTelemetry(ctx, req.(*TelemetrySubmitRequest))
+	return 0
+}
+
+func (m *Alert) GetResolvedat() int64 {
+	if m != nil {
+		return m.Resolvedat
 	}
-	return interceptor(ctx, in, info, handler)
+	return 0
+}
+
+func (m *Alert) GetGeofenceid() uint64 {
+	if m != nil {
+		return m.Geofenceid
+	}
+	return 0
+}
+
+func (m *Alert) GetSource() string {
+	if m != nil {
+		return m.Source
+	}
+	return ""
+}
+
+type CreateRuleRequest struct {
+	Rule *Rule `protobuf:"bytes,1,opt,name=rule" json:"rule,omitempty"`
+}
+
+func (m *CreateRuleRequest) Reset()                    { *m = CreateRuleRequest{} }
+func (m *CreateRuleRequest) String() string            { return proto.CompactTextString(m) }
+func (*CreateRuleRequest) ProtoMessage()               {}
+func (*CreateRuleRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+
+func (m *CreateRuleRequest) GetRule() *Rule {
+	if m != nil {
+		return m.Rule
+	}
+	return nil
+}
+
+type CreateRuleReply struct {
+	Ruleid uint64 `protobuf:"varint,1,opt,name=ruleid" json:"ruleid,omitempty"`
+	Err    string `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *CreateRuleReply) Reset()                    { *m = CreateRuleReply{} }
+func (m *CreateRuleReply) String() string            { return proto.CompactTextString(m) }
+func (*CreateRuleReply) ProtoMessage()               {}
+func (*CreateRuleReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+
+func (m *CreateRuleReply) GetRuleid() uint64 {
+	if m != nil {
+		return m.Ruleid
+	}
+	return 0
+}
+
+func (m *CreateRuleReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type GetRuleRequest struct {
+	Ruleid uint64 `protobuf:"varint,1,opt,name=ruleid" json:"ruleid,omitempty"`
+}
+
+func (m *GetRuleRequest) Reset()                    { *m = GetRuleRequest{} }
+func (m *GetRuleRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetRuleRequest) ProtoMessage()               {}
+func (*GetRuleRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+
+func (m *GetRuleRequest) GetRuleid() uint64 {
+	if m != nil {
+		return m.Ruleid
+	}
+	return 0
+}
+
+type GetRuleReply struct {
+	Rule *Rule  `protobuf:"bytes,1,opt,name=rule" json:"rule,omitempty"`
+	Err  string `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *GetRuleReply) Reset()                    { *m = GetRuleReply{} }
+func (m *GetRuleReply) String() string            { return proto.CompactTextString(m) }
+func (*GetRuleReply) ProtoMessage()               {}
+func (*GetRuleReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+
+func (m *GetRuleReply) GetRule() *Rule {
+	if m != nil {
+		return m.Rule
+	}
+	return nil
+}
+
+func (m *GetRuleReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type UpdateRuleRequest struct {
+	Rule *Rule `protobuf:"bytes,1,opt,name=rule" json:"rule,omitempty"`
+}
+
+func (m *UpdateRuleRequest) Reset()                    { *m = UpdateRuleRequest{} }
+func (m *UpdateRuleRequest) String() string            { return proto.CompactTextString(m) }
+func (*UpdateRuleRequest) ProtoMessage()               {}
+func (*UpdateRuleRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+
+func (m *UpdateRuleRequest) GetRule() *Rule {
+	if m != nil {
+		return m.Rule
+	}
+	return nil
+}
+
+type UpdateRuleReply struct {
+	Acknowledged bool   `protobuf:"varint,1,opt,name=acknowledged" json:"acknowledged,omitempty"`
+	Err          string `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *UpdateRuleReply) Reset()                    { *m = UpdateRuleReply{} }
+func (m *UpdateRuleReply) String() string            { return proto.CompactTextString(m) }
+func (*UpdateRuleReply) ProtoMessage()               {}
+func (*UpdateRuleReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+
+func (m *UpdateRuleReply) GetAcknowledged() bool {
+	if m != nil {
+		return m.Acknowledged
+	}
+	return false
+}
+
+func (m *UpdateRuleReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type DeleteRuleRequest struct {
+	Ruleid uint64 `protobuf:"varint,1,opt,name=ruleid" json:"ruleid,omitempty"`
+}
+
+func (m *DeleteRuleRequest) Reset()                    { *m = DeleteRuleRequest{} }
+func (m *DeleteRuleRequest) String() string            { return proto.CompactTextString(m) }
+func (*DeleteRuleRequest) ProtoMessage()               {}
+func (*DeleteRuleRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
+
+func (m *DeleteRuleRequest) GetRuleid() uint64 {
+	if m != nil {
+		return m.Ruleid
+	}
+	return 0
+}
+
+type DeleteRuleReply struct {
+	Acknowledged bool   `protobuf:"varint,1,opt,name=acknowledged" json:"acknowledged,omitempty"`
+	Err          string `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *DeleteRuleReply) Reset()                    { *m = DeleteRuleReply{} }
+func (m *DeleteRuleReply) String() string            { return proto.CompactTextString(m) }
+func (*DeleteRuleReply) ProtoMessage()               {}
+func (*DeleteRuleReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
+
+func (m *DeleteRuleReply) GetAcknowledged() bool {
+	if m != nil {
+		return m.Acknowledged
+	}
+	return false
+}
+
+func (m *DeleteRuleReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type ListRulesRequest struct {
+}
+
+func (m *ListRulesRequest) Reset()                    { *m = ListRulesRequest{} }
+func (m *ListRulesRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListRulesRequest) ProtoMessage()               {}
+func (*ListRulesRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
+
+type ListRulesReply struct {
+	Rules []*Rule `protobuf:"bytes,1,rep,name=rules" json:"rules,omitempty"`
+	Err   string  `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *ListRulesReply) Reset()                    { *m = ListRulesReply{} }
+func (m *ListRulesReply) String() string            { return proto.CompactTextString(m) }
+func (*ListRulesReply) ProtoMessage()               {}
+func (*ListRulesReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
+
+func (m *ListRulesReply) GetRules() []*Rule {
+	if m != nil {
+		return m.Rules
+	}
+	return nil
+}
+
+func (m *ListRulesReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type ActiveAlertsRequest struct {
+	Deviceid uint64        `protobuf:"varint,1,opt,name=deviceid" json:"deviceid,omitempty"`
+	Filter   *DeviceFilter `protobuf:"bytes,2,opt,name=filter" json:"filter,omitempty"`
+}
+
+func (m *ActiveAlertsRequest) Reset()                    { *m = ActiveAlertsRequest{} }
+func (m *ActiveAlertsRequest) String() string            { return proto.CompactTextString(m) }
+func (*ActiveAlertsRequest) ProtoMessage()               {}
+func (*ActiveAlertsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{21} }
+
+func (m *ActiveAlertsRequest) GetDeviceid() uint64 {
+	if m != nil {
+		return m.Deviceid
+	}
+	return 0
+}
+
+func (m *ActiveAlertsRequest) GetFilter() *DeviceFilter {
+	if m != nil {
+		return m.Filter
+	}
+	return nil
+}
+
+type ActiveAlertsReply struct {
+	Alerts []*Alert `protobuf:"bytes,1,rep,name=alerts" json:"alerts,omitempty"`
+	Err    string   `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *ActiveAlertsReply) Reset()                    { *m = ActiveAlertsReply{} }
+func (m *ActiveAlertsReply) String() string            { return proto.CompactTextString(m) }
+func (*ActiveAlertsReply) ProtoMessage()               {}
+func (*ActiveAlertsReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22} }
+
+func (m *ActiveAlertsReply) GetAlerts() []*Alert {
+	if m != nil {
+		return m.Alerts
+	}
+	return nil
+}
+
+func (m *ActiveAlertsReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type Event struct {
+	Eventid   uint64 `protobuf:"varint,1,opt,name=eventid" json:"eventid,omitempty"`
+	Type      string `protobuf:"bytes,2,opt,name=type" json:"type,omitempty"`
+	Deviceid  uint64 `protobuf:"varint,3,opt,name=deviceid" json:"deviceid,omitempty"`
+	Timestamp int64  `protobuf:"varint,4,opt,name=timestamp" json:"timestamp,omitempty"`
+	Data      []byte `protobuf:"bytes,5,opt,name=data" json:"data,omitempty"`
+}
+
+func (m *Event) Reset()                    { *m = Event{} }
+func (m *Event) String() string            { return proto.CompactTextString(m) }
+func (*Event) ProtoMessage()               {}
+func (*Event) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23} }
+
+func (m *Event) GetEventid() uint64 {
+	if m != nil {
+		return m.Eventid
+	}
+	return 0
+}
+
+func (m *Event) GetType() string {
+	if m != nil {
+		return m.Type
+	}
+	return ""
+}
+
+func (m *Event) GetDeviceid() uint64 {
+	if m != nil {
+		return m.Deviceid
+	}
+	return 0
+}
+
+func (m *Event) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *Event) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+type Webhook struct {
+	Webhookid uint64   `protobuf:"varint,1,opt,name=webhookid" json:"webhookid,omitempty"`
+	Url       string   `protobuf:"bytes,2,opt,name=url" json:"url,omitempty"`
+	Secret    string   `protobuf:"bytes,3,opt,name=secret" json:"secret,omitempty"`
+	Events    []string `protobuf:"bytes,4,rep,name=events" json:"events,omitempty"`
+}
+
+func (m *Webhook) Reset()                    { *m = Webhook{} }
+func (m *Webhook) String() string            { return proto.CompactTextString(m) }
+func (*Webhook) ProtoMessage()               {}
+func (*Webhook) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24} }
+
+func (m *Webhook) GetWebhookid() uint64 {
+	if m != nil {
+		return m.Webhookid
+	}
+	return 0
+}
+
+func (m *Webhook) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+func (m *Webhook) GetSecret() string {
+	if m != nil {
+		return m.Secret
+	}
+	return ""
+}
+
+func (m *Webhook) GetEvents() []string {
+	if m != nil {
+		return m.Events
+	}
+	return nil
+}
+
+type DeadLetter struct {
+	Webhookid uint64 `protobuf:"varint,1,opt,name=webhookid" json:"webhookid,omitempty"`
+	Url       string `protobuf:"bytes,2,opt,name=url" json:"url,omitempty"`
+	Event     *Event `protobuf:"bytes,3,opt,name=event" json:"event,omitempty"`
+	Error     string `protobuf:"bytes,4,opt,name=error" json:"error,omitempty"`
+	Attempts  int32  `protobuf:"varint,5,opt,name=attempts" json:"attempts,omitempty"`
+	Failedat  int64  `protobuf:"varint,6,opt,name=failedat" json:"failedat,omitempty"`
+}
+
+func (m *DeadLetter) Reset()                    { *m = DeadLetter{} }
+func (m *DeadLetter) String() string            { return proto.CompactTextString(m) }
+func (*DeadLetter) ProtoMessage()               {}
+func (*DeadLetter) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{25} }
+
+func (m *DeadLetter) GetWebhookid() uint64 {
+	if m != nil {
+		return m.Webhookid
+	}
+	return 0
+}
+
+func (m *DeadLetter) GetUrl() string {
+	if m != nil {
+		return m.Url
+	}
+	return ""
+}
+
+func (m *DeadLetter) GetEvent() *Event {
+	if m != nil {
+		return m.Event
+	}
+	return nil
+}
+
+func (m *DeadLetter) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *DeadLetter) GetAttempts() int32 {
+	if m != nil {
+		return m.Attempts
+	}
+	return 0
+}
+
+func (m *DeadLetter) GetFailedat() int64 {
+	if m != nil {
+		return m.Failedat
+	}
+	return 0
+}
+
+type CreateWebhookRequest struct {
+	Webhook *Webhook `protobuf:"bytes,1,opt,name=webhook" json:"webhook,omitempty"`
+}
+
+func (m *CreateWebhookRequest) Reset()                    { *m = CreateWebhookRequest{} }
+func (m *CreateWebhookRequest) String() string            { return proto.CompactTextString(m) }
+func (*CreateWebhookRequest) ProtoMessage()               {}
+func (*CreateWebhookRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{26} }
+
+func (m *CreateWebhookRequest) GetWebhook() *Webhook {
+	if m != nil {
+		return m.Webhook
+	}
+	return nil
+}
+
+type CreateWebhookReply struct {
+	Webhookid uint64 `protobuf:"varint,1,opt,name=webhookid" json:"webhookid,omitempty"`
+	Err       string `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *CreateWebhookReply) Reset()                    { *m = CreateWebhookReply{} }
+func (m *CreateWebhookReply) String() string            { return proto.CompactTextString(m) }
+func (*CreateWebhookReply) ProtoMessage()               {}
+func (*CreateWebhookReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{27} }
+
+func (m *CreateWebhookReply) GetWebhookid() uint64 {
+	if m != nil {
+		return m.Webhookid
+	}
+	return 0
+}
+
+func (m *CreateWebhookReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type ListWebhooksRequest struct {
+}
+
+func (m *ListWebhooksRequest) Reset()                    { *m = ListWebhooksRequest{} }
+func (m *ListWebhooksRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListWebhooksRequest) ProtoMessage()               {}
+func (*ListWebhooksRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{28} }
+
+type ListWebhooksReply struct {
+	Webhooks []*Webhook `protobuf:"bytes,1,rep,name=webhooks" json:"webhooks,omitempty"`
+	Err      string     `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *ListWebhooksReply) Reset()                    { *m = ListWebhooksReply{} }
+func (m *ListWebhooksReply) String() string            { return proto.CompactTextString(m) }
+func (*ListWebhooksReply) ProtoMessage()               {}
+func (*ListWebhooksReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{29} }
+
+func (m *ListWebhooksReply) GetWebhooks() []*Webhook {
+	if m != nil {
+		return m.Webhooks
+	}
+	return nil
+}
+
+func (m *ListWebhooksReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type DeleteWebhookRequest struct {
+	Webhookid uint64 `protobuf:"varint,1,opt,name=webhookid" json:"webhookid,omitempty"`
+}
+
+func (m *DeleteWebhookRequest) Reset()                    { *m = DeleteWebhookRequest{} }
+func (m *DeleteWebhookRequest) String() string            { return proto.CompactTextString(m) }
+func (*DeleteWebhookRequest) ProtoMessage()               {}
+func (*DeleteWebhookRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{30} }
+
+func (m *DeleteWebhookRequest) GetWebhookid() uint64 {
+	if m != nil {
+		return m.Webhookid
+	}
+	return 0
+}
+
+type DeleteWebhookReply struct {
+	Acknowledged bool   `protobuf:"varint,1,opt,name=acknowledged" json:"acknowledged,omitempty"`
+	Err          string `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *DeleteWebhookReply) Reset()                    { *m = DeleteWebhookReply{} }
+func (m *DeleteWebhookReply) String() string            { return proto.CompactTextString(m) }
+func (*DeleteWebhookReply) ProtoMessage()               {}
+func (*DeleteWebhookReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{31} }
+
+func (m *DeleteWebhookReply) GetAcknowledged() bool {
+	if m != nil {
+		return m.Acknowledged
+	}
+	return false
+}
+
+func (m *DeleteWebhookReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type DeadLettersRequest struct {
+	Limit int32 `protobuf:"varint,1,opt,name=limit" json:"limit,omitempty"`
+}
+
+func (m *DeadLettersRequest) Reset()                    { *m = DeadLettersRequest{} }
+func (m *DeadLettersRequest) String() string            { return proto.CompactTextString(m) }
+func (*DeadLettersRequest) ProtoMessage()               {}
+func (*DeadLettersRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{32} }
+
+func (m *DeadLettersRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type DeadLettersReply struct {
+	Deadletters []*DeadLetter `protobuf:"bytes,1,rep,name=deadletters" json:"deadletters,omitempty"`
+	Err         string        `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *DeadLettersReply) Reset()                    { *m = DeadLettersReply{} }
+func (m *DeadLettersReply) String() string            { return proto.CompactTextString(m) }
+func (*DeadLettersReply) ProtoMessage()               {}
+func (*DeadLettersReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{33} }
+
+func (m *DeadLettersReply) GetDeadletters() []*DeadLetter {
+	if m != nil {
+		return m.Deadletters
+	}
+	return nil
+}
+
+func (m *DeadLettersReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type Device struct {
+	Deviceid    uint64           `protobuf:"varint,1,opt,name=deviceid" json:"deviceid,omitempty"`
+	Name        string           `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+	Owner       string           `protobuf:"bytes,3,opt,name=owner" json:"owner,omitempty"`
+	Devicetype  DeviceType       `protobuf:"varint,4,opt,name=devicetype,enum=pb.DeviceType" json:"devicetype,omitempty"`
+	Online      bool             `protobuf:"varint,5,opt,name=online" json:"online,omitempty"`
+	Lastseen    int64            `protobuf:"varint,6,opt,name=lastseen" json:"lastseen,omitempty"`
+	Battery     *BatteryEstimate `protobuf:"bytes,7,opt,name=battery" json:"battery,omitempty"`
+	Labels      []*Label         `protobuf:"bytes,8,rep,name=labels" json:"labels,omitempty"`
+	Gatewayid   uint64           `protobuf:"varint,9,opt,name=gatewayid" json:"gatewayid,omitempty"`
+	Unreachable bool             `protobuf:"varint,10,opt,name=unreachable" json:"unreachable,omitempty"`
+	Firmware    string           `protobuf:"bytes,11,opt,name=firmware" json:"firmware,omitempty"`
+}
+
+func (m *Device) Reset()                    { *m = Device{} }
+func (m *Device) String() string            { return proto.CompactTextString(m) }
+func (*Device) ProtoMessage()               {}
+func (*Device) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34} }
+
+func (m *Device) GetDeviceid() uint64 {
+	if m != nil {
+		return m.Deviceid
+	}
+	return 0
+}
+
+func (m *Device) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Device) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *Device) GetDevicetype() DeviceType {
+	if m != nil {
+		return m.Devicetype
+	}
+	return DeviceType_DRONE
+}
+
+func (m *Device) GetOnline() bool {
+	if m != nil {
+		return m.Online
+	}
+	return false
+}
+
+func (m *Device) GetLastseen() int64 {
+	if m != nil {
+		return m.Lastseen
+	}
+	return 0
+}
+
+func (m *Device) GetBattery() *BatteryEstimate {
+	if m != nil {
+		return m.Battery
+	}
+	return nil
+}
+
+func (m *Device) GetLabels() []*Label {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+func (m *Device) GetGatewayid() uint64 {
+	if m != nil {
+		return m.Gatewayid
+	}
+	return 0
+}
+
+func (m *Device) GetUnreachable() bool {
+	if m != nil {
+		return m.Unreachable
+	}
+	return false
+}
+
+func (m *Device) GetFirmware() string {
+	if m != nil {
+		return m.Firmware
+	}
+	return ""
+}
+
+type GetDeviceRequest struct {
+	Deviceid uint64 `protobuf:"varint,1,opt,name=deviceid" json:"deviceid,omitempty"`
+}
+
+func (m *GetDeviceRequest) Reset()                    { *m = GetDeviceRequest{} }
+func (m *GetDeviceRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetDeviceRequest) ProtoMessage()               {}
+func (*GetDeviceRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{35} }
+
+func (m *GetDeviceRequest) GetDeviceid() uint64 {
+	if m != nil {
+		return m.Deviceid
+	}
+	return 0
+}
+
+type GetDeviceReply struct {
+	Device *Device `protobuf:"bytes,1,opt,name=device" json:"device,omitempty"`
+	Err    string  `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *GetDeviceReply) Reset()                    { *m = GetDeviceReply{} }
+func (m *GetDeviceReply) String() string            { return proto.CompactTextString(m) }
+func (*GetDeviceReply) ProtoMessage()               {}
+func (*GetDeviceReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{36} }
+
+func (m *GetDeviceReply) GetDevice() *Device {
+	if m != nil {
+		return m.Device
+	}
+	return nil
+}
+
+func (m *GetDeviceReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type UpdateDeviceRequest struct {
+	Deviceid uint64 `protobuf:"varint,1,opt,name=deviceid" json:"deviceid,omitempty"`
+	Name     string `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+	Owner    string `protobuf:"bytes,3,opt,name=owner" json:"owner,omitempty"`
+}
+
+func (m *UpdateDeviceRequest) Reset()                    { *m = UpdateDeviceRequest{} }
+func (m *UpdateDeviceRequest) String() string            { return proto.CompactTextString(m) }
+func (*UpdateDeviceRequest) ProtoMessage()               {}
+func (*UpdateDeviceRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{37} }
+
+func (m *UpdateDeviceRequest) GetDeviceid() uint64 {
+	if m != nil {
+		return m.Deviceid
+	}
+	return 0
+}
+
+func (m *UpdateDeviceRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *UpdateDeviceRequest) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+type UpdateDeviceReply struct {
+	Acknowledged bool   `protobuf:"varint,1,opt,name=acknowledged" json:"acknowledged,omitempty"`
+	Err          string `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *UpdateDeviceReply) Reset()                    { *m = UpdateDeviceReply{} }
+func (m *UpdateDeviceReply) String() string            { return proto.CompactTextString(m) }
+func (*UpdateDeviceReply) ProtoMessage()               {}
+func (*UpdateDeviceReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{38} }
+
+func (m *UpdateDeviceReply) GetAcknowledged() bool {
+	if m != nil {
+		return m.Acknowledged
+	}
+	return false
+}
+
+func (m *UpdateDeviceReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type DeleteDeviceRequest struct {
+	Deviceid uint64 `protobuf:"varint,1,opt,name=deviceid" json:"deviceid,omitempty"`
+}
+
+func (m *DeleteDeviceRequest) Reset()                    { *m = DeleteDeviceRequest{} }
+func (m *DeleteDeviceRequest) String() string            { return proto.CompactTextString(m) }
+func (*DeleteDeviceRequest) ProtoMessage()               {}
+func (*DeleteDeviceRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{39} }
+
+func (m *DeleteDeviceRequest) GetDeviceid() uint64 {
+	if m != nil {
+		return m.Deviceid
+	}
+	return 0
+}
+
+type DeleteDeviceReply struct {
+	Acknowledged bool   `protobuf:"varint,1,opt,name=acknowledged" json:"acknowledged,omitempty"`
+	Err          string `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *DeleteDeviceReply) Reset()                    { *m = DeleteDeviceReply{} }
+func (m *DeleteDeviceReply) String() string            { return proto.CompactTextString(m) }
+func (*DeleteDeviceReply) ProtoMessage()               {}
+func (*DeleteDeviceReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{40} }
+
+func (m *DeleteDeviceReply) GetAcknowledged() bool {
+	if m != nil {
+		return m.Acknowledged
+	}
+	return false
+}
+
+func (m *DeleteDeviceReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type ListDevicesRequest struct {
+	Filter *DeviceFilter `protobuf:"bytes,1,opt,name=filter" json:"filter,omitempty"`
+}
+
+func (m *ListDevicesRequest) Reset()                    { *m = ListDevicesRequest{} }
+func (m *ListDevicesRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListDevicesRequest) ProtoMessage()               {}
+func (*ListDevicesRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{41} }
+
+func (m *ListDevicesRequest) GetFilter() *DeviceFilter {
+	if m != nil {
+		return m.Filter
+	}
+	return nil
+}
+
+type ListDevicesReply struct {
+	Devices []*Device `protobuf:"bytes,1,rep,name=devices" json:"devices,omitempty"`
+	Err     string    `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *ListDevicesReply) Reset()                    { *m = ListDevicesReply{} }
+func (m *ListDevicesReply) String() string            { return proto.CompactTextString(m) }
+func (*ListDevicesReply) ProtoMessage()               {}
+func (*ListDevicesReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{42} }
+
+func (m *ListDevicesReply) GetDevices() []*Device {
+	if m != nil {
+		return m.Devices
+	}
+	return nil
+}
+
+func (m *ListDevicesReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type Geofence struct {
+	Geofenceid uint64   `protobuf:"varint,1,opt,name=geofenceid" json:"geofenceid,omitempty"`
+	Name       string   `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+	Policy     string   `protobuf:"bytes,3,opt,name=policy" json:"policy,omitempty"`
+	Latitude   float64  `protobuf:"fixed64,4,opt,name=latitude" json:"latitude,omitempty"`
+	Longitude  float64  `protobuf:"fixed64,5,opt,name=longitude" json:"longitude,omitempty"`
+	Radius     float64  `protobuf:"fixed64,6,opt,name=radius" json:"radius,omitempty"`
+	Polygon    string   `protobuf:"bytes,7,opt,name=polygon" json:"polygon,omitempty"`
+	Devices    []uint64 `protobuf:"varint,8,rep,name=devices,packed" json:"devices,omitempty"`
+	Owners     []string `protobuf:"bytes,9,rep,name=owners" json:"owners,omitempty"`
+}
+
+func (m *Geofence) Reset()                    { *m = Geofence{} }
+func (m *Geofence) String() string            { return proto.CompactTextString(m) }
+func (*Geofence) ProtoMessage()               {}
+func (*Geofence) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{43} }
+
+func (m *Geofence) GetGeofenceid() uint64 {
+	if m != nil {
+		return m.Geofenceid
+	}
+	return 0
+}
+
+func (m *Geofence) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Geofence) GetPolicy() string {
+	if m != nil {
+		return m.Policy
+	}
+	return ""
+}
+
+func (m *Geofence) GetLatitude() float64 {
+	if m != nil {
+		return m.Latitude
+	}
+	return 0
+}
+
+func (m *Geofence) GetLongitude() float64 {
+	if m != nil {
+		return m.Longitude
+	}
+	return 0
+}
+
+func (m *Geofence) GetRadius() float64 {
+	if m != nil {
+		return m.Radius
+	}
+	return 0
+}
+
+func (m *Geofence) GetPolygon() string {
+	if m != nil {
+		return m.Polygon
+	}
+	return ""
+}
+
+func (m *Geofence) GetDevices() []uint64 {
+	if m != nil {
+		return m.Devices
+	}
+	return nil
+}
+
+func (m *Geofence) GetOwners() []string {
+	if m != nil {
+		return m.Owners
+	}
+	return nil
+}
+
+type CreateGeofenceRequest struct {
+	Geofence *Geofence `protobuf:"bytes,1,opt,name=geofence" json:"geofence,omitempty"`
+}
+
+func (m *CreateGeofenceRequest) Reset()                    { *m = CreateGeofenceRequest{} }
+func (m *CreateGeofenceRequest) String() string            { return proto.CompactTextString(m) }
+func (*CreateGeofenceRequest) ProtoMessage()               {}
+func (*CreateGeofenceRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{44} }
+
+func (m *CreateGeofenceRequest) GetGeofence() *Geofence {
+	if m != nil {
+		return m.Geofence
+	}
+	return nil
+}
+
+type CreateGeofenceReply struct {
+	Geofenceid uint64 `protobuf:"varint,1,opt,name=geofenceid" json:"geofenceid,omitempty"`
+	Err        string `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *CreateGeofenceReply) Reset()                    { *m = CreateGeofenceReply{} }
+func (m *CreateGeofenceReply) String() string            { return proto.CompactTextString(m) }
+func (*CreateGeofenceReply) ProtoMessage()               {}
+func (*CreateGeofenceReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{45} }
+
+func (m *CreateGeofenceReply) GetGeofenceid() uint64 {
+	if m != nil {
+		return m.Geofenceid
+	}
+	return 0
+}
+
+func (m *CreateGeofenceReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type UpdateGeofenceRequest struct {
+	Geofence *Geofence `protobuf:"bytes,1,opt,name=geofence" json:"geofence,omitempty"`
+}
+
+func (m *UpdateGeofenceRequest) Reset()                    { *m = UpdateGeofenceRequest{} }
+func (m *UpdateGeofenceRequest) String() string            { return proto.CompactTextString(m) }
+func (*UpdateGeofenceRequest) ProtoMessage()               {}
+func (*UpdateGeofenceRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{46} }
+
+func (m *UpdateGeofenceRequest) GetGeofence() *Geofence {
+	if m != nil {
+		return m.Geofence
+	}
+	return nil
+}
+
+type UpdateGeofenceReply struct {
+	Acknowledged bool   `protobuf:"varint,1,opt,name=acknowledged" json:"acknowledged,omitempty"`
+	Err          string `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *UpdateGeofenceReply) Reset()                    { *m = UpdateGeofenceReply{} }
+func (m *UpdateGeofenceReply) String() string            { return proto.CompactTextString(m) }
+func (*UpdateGeofenceReply) ProtoMessage()               {}
+func (*UpdateGeofenceReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{47} }
+
+func (m *UpdateGeofenceReply) GetAcknowledged() bool {
+	if m != nil {
+		return m.Acknowledged
+	}
+	return false
+}
+
+func (m *UpdateGeofenceReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type DeleteGeofenceRequest struct {
+	Geofenceid uint64 `protobuf:"varint,1,opt,name=geofenceid" json:"geofenceid,omitempty"`
+}
+
+func (m *DeleteGeofenceRequest) Reset()                    { *m = DeleteGeofenceRequest{} }
+func (m *DeleteGeofenceRequest) String() string            { return proto.CompactTextString(m) }
+func (*DeleteGeofenceRequest) ProtoMessage()               {}
+func (*DeleteGeofenceRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{48} }
+
+func (m *DeleteGeofenceRequest) GetGeofenceid() uint64 {
+	if m != nil {
+		return m.Geofenceid
+	}
+	return 0
+}
+
+type DeleteGeofenceReply struct {
+	Acknowledged bool   `protobuf:"varint,1,opt,name=acknowledged" json:"acknowledged,omitempty"`
+	Err          string `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *DeleteGeofenceReply) Reset()                    { *m = DeleteGeofenceReply{} }
+func (m *DeleteGeofenceReply) String() string            { return proto.CompactTextString(m) }
+func (*DeleteGeofenceReply) ProtoMessage()               {}
+func (*DeleteGeofenceReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{49} }
+
+func (m *DeleteGeofenceReply) GetAcknowledged() bool {
+	if m != nil {
+		return m.Acknowledged
+	}
+	return false
+}
+
+func (m *DeleteGeofenceReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type ListGeofencesRequest struct {
+}
+
+func (m *ListGeofencesRequest) Reset()                    { *m = ListGeofencesRequest{} }
+func (m *ListGeofencesRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListGeofencesRequest) ProtoMessage()               {}
+func (*ListGeofencesRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{50} }
+
+type ListGeofencesReply struct {
+	Geofences []*Geofence `protobuf:"bytes,1,rep,name=geofences" json:"geofences,omitempty"`
+	Err       string      `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *ListGeofencesReply) Reset()                    { *m = ListGeofencesReply{} }
+func (m *ListGeofencesReply) String() string            { return proto.CompactTextString(m) }
+func (*ListGeofencesReply) ProtoMessage()               {}
+func (*ListGeofencesReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{51} }
+
+func (m *ListGeofencesReply) GetGeofences() []*Geofence {
+	if m != nil {
+		return m.Geofences
+	}
+	return nil
+}
+
+func (m *ListGeofencesReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type DeviceLocation struct {
+	Device    *Device `protobuf:"bytes,1,opt,name=device" json:"device,omitempty"`
+	Latitude  float64 `protobuf:"fixed64,2,opt,name=latitude" json:"latitude,omitempty"`
+	Longitude float64 `protobuf:"fixed64,3,opt,name=longitude" json:"longitude,omitempty"`
+	Distance  float64 `protobuf:"fixed64,4,opt,name=distance" json:"distance,omitempty"`
+}
+
+func (m *DeviceLocation) Reset()                    { *m = DeviceLocation{} }
+func (m *DeviceLocation) String() string            { return proto.CompactTextString(m) }
+func (*DeviceLocation) ProtoMessage()               {}
+func (*DeviceLocation) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{52} }
+
+func (m *DeviceLocation) GetDevice() *Device {
+	if m != nil {
+		return m.Device
+	}
+	return nil
+}
+
+func (m *DeviceLocation) GetLatitude() float64 {
+	if m != nil {
+		return m.Latitude
+	}
+	return 0
+}
+
+func (m *DeviceLocation) GetLongitude() float64 {
+	if m != nil {
+		return m.Longitude
+	}
+	return 0
+}
+
+func (m *DeviceLocation) GetDistance() float64 {
+	if m != nil {
+		return m.Distance
+	}
+	return 0
+}
+
+type DeviceFilter struct {
+	Devicetype string   `protobuf:"bytes,1,opt,name=devicetype" json:"devicetype,omitempty"`
+	Owner      string   `protobuf:"bytes,2,opt,name=owner" json:"owner,omitempty"`
+	Deviceids  []uint64 `protobuf:"varint,3,rep,name=deviceids,packed" json:"deviceids,omitempty"`
+	Selector   string   `protobuf:"bytes,4,opt,name=selector" json:"selector,omitempty"`
+	Group      uint64   `protobuf:"varint,5,opt,name=group" json:"group,omitempty"`
+}
+
+func (m *DeviceFilter) Reset()                    { *m = DeviceFilter{} }
+func (m *DeviceFilter) String() string            { return proto.CompactTextString(m) }
+func (*DeviceFilter) ProtoMessage()               {}
+func (*DeviceFilter) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{53} }
+
+func (m *DeviceFilter) GetDevicetype() string {
+	if m != nil {
+		return m.Devicetype
+	}
+	return ""
+}
+
+func (m *DeviceFilter) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *DeviceFilter) GetDeviceids() []uint64 {
+	if m != nil {
+		return m.Deviceids
+	}
+	return nil
+}
+
+func (m *DeviceFilter) GetSelector() string {
+	if m != nil {
+		return m.Selector
+	}
+	return ""
+}
+
+func (m *DeviceFilter) GetGroup() uint64 {
+	if m != nil {
+		return m.Group
+	}
+	return 0
+}
+
+type NearbyDevicesRequest struct {
+	Latitude  float64       `protobuf:"fixed64,1,opt,name=latitude" json:"latitude,omitempty"`
+	Longitude float64       `protobuf:"fixed64,2,opt,name=longitude" json:"longitude,omitempty"`
+	Radius    float64       `protobuf:"fixed64,3,opt,name=radius" json:"radius,omitempty"`
+	Filter    *DeviceFilter `protobuf:"bytes,4,opt,name=filter" json:"filter,omitempty"`
+}
+
+func (m *NearbyDevicesRequest) Reset()                    { *m = NearbyDevicesRequest{} }
+func (m *NearbyDevicesRequest) String() string            { return proto.CompactTextString(m) }
+func (*NearbyDevicesRequest) ProtoMessage()               {}
+func (*NearbyDevicesRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{54} }
+
+func (m *NearbyDevicesRequest) GetLatitude() float64 {
+	if m != nil {
+		return m.Latitude
+	}
+	return 0
+}
+
+func (m *NearbyDevicesRequest) GetLongitude() float64 {
+	if m != nil {
+		return m.Longitude
+	}
+	return 0
+}
+
+func (m *NearbyDevicesRequest) GetRadius() float64 {
+	if m != nil {
+		return m.Radius
+	}
+	return 0
+}
+
+func (m *NearbyDevicesRequest) GetFilter() *DeviceFilter {
+	if m != nil {
+		return m.Filter
+	}
+	return nil
+}
+
+type NearbyDevicesReply struct {
+	Devices []*DeviceLocation `protobuf:"bytes,1,rep,name=devices" json:"devices,omitempty"`
+	Err     string            `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *NearbyDevicesReply) Reset()                    { *m = NearbyDevicesReply{} }
+func (m *NearbyDevicesReply) String() string            { return proto.CompactTextString(m) }
+func (*NearbyDevicesReply) ProtoMessage()               {}
+func (*NearbyDevicesReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{55} }
+
+func (m *NearbyDevicesReply) GetDevices() []*DeviceLocation {
+	if m != nil {
+		return m.Devices
+	}
+	return nil
+}
+
+func (m *NearbyDevicesReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type DevicesInBoxRequest struct {
+	Minlatitude  float64       `protobuf:"fixed64,1,opt,name=minlatitude" json:"minlatitude,omitempty"`
+	Minlongitude float64       `protobuf:"fixed64,2,opt,name=minlongitude" json:"minlongitude,omitempty"`
+	Maxlatitude  float64       `protobuf:"fixed64,3,opt,name=maxlatitude" json:"maxlatitude,omitempty"`
+	Maxlongitude float64       `protobuf:"fixed64,4,opt,name=maxlongitude" json:"maxlongitude,omitempty"`
+	Filter       *DeviceFilter `protobuf:"bytes,5,opt,name=filter" json:"filter,omitempty"`
+}
+
+func (m *DevicesInBoxRequest) Reset()                    { *m = DevicesInBoxRequest{} }
+func (m *DevicesInBoxRequest) String() string            { return proto.CompactTextString(m) }
+func (*DevicesInBoxRequest) ProtoMessage()               {}
+func (*DevicesInBoxRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{56} }
+
+func (m *DevicesInBoxRequest) GetMinlatitude() float64 {
+	if m != nil {
+		return m.Minlatitude
+	}
+	return 0
+}
+
+func (m *DevicesInBoxRequest) GetMinlongitude() float64 {
+	if m != nil {
+		return m.Minlongitude
+	}
+	return 0
+}
+
+func (m *DevicesInBoxRequest) GetMaxlatitude() float64 {
+	if m != nil {
+		return m.Maxlatitude
+	}
+	return 0
+}
+
+func (m *DevicesInBoxRequest) GetMaxlongitude() float64 {
+	if m != nil {
+		return m.Maxlongitude
+	}
+	return 0
+}
+
+func (m *DevicesInBoxRequest) GetFilter() *DeviceFilter {
+	if m != nil {
+		return m.Filter
+	}
+	return nil
+}
+
+type DevicesInBoxReply struct {
+	Devices []*DeviceLocation `protobuf:"bytes,1,rep,name=devices" json:"devices,omitempty"`
+	Err     string            `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *DevicesInBoxReply) Reset()                    { *m = DevicesInBoxReply{} }
+func (m *DevicesInBoxReply) String() string            { return proto.CompactTextString(m) }
+func (*DevicesInBoxReply) ProtoMessage()               {}
+func (*DevicesInBoxReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{57} }
+
+func (m *DevicesInBoxReply) GetDevices() []*DeviceLocation {
+	if m != nil {
+		return m.Devices
+	}
+	return nil
+}
+
+func (m *DevicesInBoxReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type MotionTotals struct {
+	Distance float64 `protobuf:"fixed64,1,opt,name=distance" json:"distance,omitempty"`
+	Maxspeed float64 `protobuf:"fixed64,2,opt,name=maxspeed" json:"maxspeed,omitempty"`
+	Glitches int64   `protobuf:"varint,3,opt,name=glitches" json:"glitches,omitempty"`
+}
+
+func (m *MotionTotals) Reset()                    { *m = MotionTotals{} }
+func (m *MotionTotals) String() string            { return proto.CompactTextString(m) }
+func (*MotionTotals) ProtoMessage()               {}
+func (*MotionTotals) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{58} }
+
+func (m *MotionTotals) GetDistance() float64 {
+	if m != nil {
+		return m.Distance
+	}
+	return 0
+}
+
+func (m *MotionTotals) GetMaxspeed() float64 {
+	if m != nil {
+		return m.Maxspeed
+	}
+	return 0
+}
+
+func (m *MotionTotals) GetGlitches() int64 {
+	if m != nil {
+		return m.Glitches
+	}
+	return 0
+}
+
+type DeviceStatus struct {
+	Deviceid  uint64        `protobuf:"varint,1,opt,name=deviceid" json:"deviceid,omitempty"`
+	Location  *Location     `protobuf:"bytes,2,opt,name=location" json:"location,omitempty"`
+	Battery   uint32        `protobuf:"varint,3,opt,name=battery" json:"battery,omitempty"`
+	Timestamp int64         `protobuf:"varint,4,opt,name=timestamp" json:"timestamp,omitempty"`
+	Speed     float64       `protobuf:"fixed64,5,opt,name=speed" json:"speed,omitempty"`
+	Heading   float64       `protobuf:"fixed64,6,opt,name=heading" json:"heading,omitempty"`
+	Climbrate float64       `protobuf:"fixed64,7,opt,name=climbrate" json:"climbrate,omitempty"`
+	Distance  float64       `protobuf:"fixed64,8,opt,name=distance" json:"distance,omitempty"`
+	Glitch    bool          `protobuf:"varint,9,opt,name=glitch" json:"glitch,omitempty"`
+	Totals    *MotionTotals `protobuf:"bytes,10,opt,name=totals" json:"totals,omitempty"`
+}
+
+func (m *DeviceStatus) Reset()                    { *m = DeviceStatus{} }
+func (m *DeviceStatus) String() string            { return proto.CompactTextString(m) }
+func (*DeviceStatus) ProtoMessage()               {}
+func (*DeviceStatus) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{59} }
+
+func (m *DeviceStatus) GetDeviceid() uint64 {
+	if m != nil {
+		return m.Deviceid
+	}
+	return 0
+}
+
+func (m *DeviceStatus) GetLocation() *Location {
+	if m != nil {
+		return m.Location
+	}
+	return nil
+}
+
+func (m *DeviceStatus) GetBattery() uint32 {
+	if m != nil {
+		return m.Battery
+	}
+	return 0
+}
+
+func (m *DeviceStatus) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *DeviceStatus) GetSpeed() float64 {
+	if m != nil {
+		return m.Speed
+	}
+	return 0
+}
+
+func (m *DeviceStatus) GetHeading() float64 {
+	if m != nil {
+		return m.Heading
+	}
+	return 0
+}
+
+func (m *DeviceStatus) GetClimbrate() float64 {
+	if m != nil {
+		return m.Climbrate
+	}
+	return 0
+}
+
+func (m *DeviceStatus) GetDistance() float64 {
+	if m != nil {
+		return m.Distance
+	}
+	return 0
+}
+
+func (m *DeviceStatus) GetGlitch() bool {
+	if m != nil {
+		return m.Glitch
+	}
+	return false
+}
+
+func (m *DeviceStatus) GetTotals() *MotionTotals {
+	if m != nil {
+		return m.Totals
+	}
+	return nil
+}
+
+type GetStatusRequest struct {
+	Deviceid uint64 `protobuf:"varint,1,opt,name=deviceid" json:"deviceid,omitempty"`
+}
+
+func (m *GetStatusRequest) Reset()                    { *m = GetStatusRequest{} }
+func (m *GetStatusRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetStatusRequest) ProtoMessage()               {}
+func (*GetStatusRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{60} }
+
+func (m *GetStatusRequest) GetDeviceid() uint64 {
+	if m != nil {
+		return m.Deviceid
+	}
+	return 0
+}
+
+type GetStatusReply struct {
+	Status *DeviceStatus `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
+	Err    string        `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *GetStatusReply) Reset()                    { *m = GetStatusReply{} }
+func (m *GetStatusReply) String() string            { return proto.CompactTextString(m) }
+func (*GetStatusReply) ProtoMessage()               {}
+func (*GetStatusReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{61} }
+
+func (m *GetStatusReply) GetStatus() *DeviceStatus {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+func (m *GetStatusReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type BatteryEstimate struct {
+	Level         uint32  `protobuf:"varint,1,opt,name=level" json:"level,omitempty"`
+	Charging      bool    `protobuf:"varint,2,opt,name=charging" json:"charging,omitempty"`
+	Dischargerate float64 `protobuf:"fixed64,3,opt,name=dischargerate" json:"dischargerate,omitempty"`
+	Timetoempty   int64   `protobuf:"varint,4,opt,name=timetoempty" json:"timetoempty,omitempty"`
+}
+
+func (m *BatteryEstimate) Reset()                    { *m = BatteryEstimate{} }
+func (m *BatteryEstimate) String() string            { return proto.CompactTextString(m) }
+func (*BatteryEstimate) ProtoMessage()               {}
+func (*BatteryEstimate) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{62} }
+
+func (m *BatteryEstimate) GetLevel() uint32 {
+	if m != nil {
+		return m.Level
+	}
+	return 0
+}
+
+func (m *BatteryEstimate) GetCharging() bool {
+	if m != nil {
+		return m.Charging
+	}
+	return false
+}
+
+func (m *BatteryEstimate) GetDischargerate() float64 {
+	if m != nil {
+		return m.Dischargerate
+	}
+	return 0
+}
+
+func (m *BatteryEstimate) GetTimetoempty() int64 {
+	if m != nil {
+		return m.Timetoempty
+	}
+	return 0
+}
+
+type BatterySample struct {
+	Level     uint32 `protobuf:"varint,1,opt,name=level" json:"level,omitempty"`
+	Timestamp int64  `protobuf:"varint,2,opt,name=timestamp" json:"timestamp,omitempty"`
+}
+
+func (m *BatterySample) Reset()                    { *m = BatterySample{} }
+func (m *BatterySample) String() string            { return proto.CompactTextString(m) }
+func (*BatterySample) ProtoMessage()               {}
+func (*BatterySample) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{63} }
+
+func (m *BatterySample) GetLevel() uint32 {
+	if m != nil {
+		return m.Level
+	}
+	return 0
+}
+
+func (m *BatterySample) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+type BatteryHistoryRequest struct {
+	Deviceid uint64 `protobuf:"varint,1,opt,name=deviceid" json:"deviceid,omitempty"`
+	Since    int64  `protobuf:"varint,2,opt,name=since" json:"since,omitempty"`
+}
+
+func (m *BatteryHistoryRequest) Reset()                    { *m = BatteryHistoryRequest{} }
+func (m *BatteryHistoryRequest) String() string            { return proto.CompactTextString(m) }
+func (*BatteryHistoryRequest) ProtoMessage()               {}
+func (*BatteryHistoryRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{64} }
+
+func (m *BatteryHistoryRequest) GetDeviceid() uint64 {
+	if m != nil {
+		return m.Deviceid
+	}
+	return 0
+}
+
+func (m *BatteryHistoryRequest) GetSince() int64 {
+	if m != nil {
+		return m.Since
+	}
+	return 0
+}
+
+type BatteryHistoryReply struct {
+	Samples []*BatterySample `protobuf:"bytes,1,rep,name=samples" json:"samples,omitempty"`
+	Err     string           `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *BatteryHistoryReply) Reset()                    { *m = BatteryHistoryReply{} }
+func (m *BatteryHistoryReply) String() string            { return proto.CompactTextString(m) }
+func (*BatteryHistoryReply) ProtoMessage()               {}
+func (*BatteryHistoryReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{65} }
+
+func (m *BatteryHistoryReply) GetSamples() []*BatterySample {
+	if m != nil {
+		return m.Samples
+	}
+	return nil
+}
+
+func (m *BatteryHistoryReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type TelemetryPoint struct {
+	Timestamp int64           `protobuf:"varint,1,opt,name=timestamp" json:"timestamp,omitempty"`
+	Min       float64         `protobuf:"fixed64,2,opt,name=min" json:"min,omitempty"`
+	Max       float64         `protobuf:"fixed64,3,opt,name=max" json:"max,omitempty"`
+	Avg       float64         `protobuf:"fixed64,4,opt,name=avg" json:"avg,omitempty"`
+	Last      float64         `protobuf:"fixed64,5,opt,name=last" json:"last,omitempty"`
+	Count     int64           `protobuf:"varint,6,opt,name=count" json:"count,omitempty"`
+	Value     *TelemetryValue `protobuf:"bytes,7,opt,name=value" json:"value,omitempty"`
+}
+
+func (m *TelemetryPoint) Reset()                    { *m = TelemetryPoint{} }
+func (m *TelemetryPoint) String() string            { return proto.CompactTextString(m) }
+func (*TelemetryPoint) ProtoMessage()               {}
+func (*TelemetryPoint) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{66} }
+
+func (m *TelemetryPoint) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *TelemetryPoint) GetMin() float64 {
+	if m != nil {
+		return m.Min
+	}
+	return 0
+}
+
+func (m *TelemetryPoint) GetMax() float64 {
+	if m != nil {
+		return m.Max
+	}
+	return 0
+}
+
+func (m *TelemetryPoint) GetAvg() float64 {
+	if m != nil {
+		return m.Avg
+	}
+	return 0
+}
+
+func (m *TelemetryPoint) GetLast() float64 {
+	if m != nil {
+		return m.Last
+	}
+	return 0
+}
+
+func (m *TelemetryPoint) GetCount() int64 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+func (m *TelemetryPoint) GetValue() *TelemetryValue {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+type TelemetrySeries struct {
+	Deviceid   uint64            `protobuf:"varint,1,opt,name=deviceid" json:"deviceid,omitempty"`
+	Metric     string            `protobuf:"bytes,2,opt,name=metric" json:"metric,omitempty"`
+	Resolution string            `protobuf:"bytes,3,opt,name=resolution" json:"resolution,omitempty"`
+	Points     []*TelemetryPoint `protobuf:"bytes,4,rep,name=points" json:"points,omitempty"`
+}
+
+func (m *TelemetrySeries) Reset()                    { *m = TelemetrySeries{} }
+func (m *TelemetrySeries) String() string            { return proto.CompactTextString(m) }
+func (*TelemetrySeries) ProtoMessage()               {}
+func (*TelemetrySeries) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{67} }
+
+func (m *TelemetrySeries) GetDeviceid() uint64 {
+	if m != nil {
+		return m.Deviceid
+	}
+	return 0
+}
+
+func (m *TelemetrySeries) GetMetric() string {
+	if m != nil {
+		return m.Metric
+	}
+	return ""
+}
+
+func (m *TelemetrySeries) GetResolution() string {
+	if m != nil {
+		return m.Resolution
+	}
+	return ""
+}
+
+func (m *TelemetrySeries) GetPoints() []*TelemetryPoint {
+	if m != nil {
+		return m.Points
+	}
+	return nil
+}
+
+type QueryTelemetryRequest struct {
+	Deviceid uint64 `protobuf:"varint,1,opt,name=deviceid" json:"deviceid,omitempty"`
+	Metric   string `protobuf:"bytes,2,opt,name=metric" json:"metric,omitempty"`
+	From     int64  `protobuf:"varint,3,opt,name=from" json:"from,omitempty"`
+	To       int64  `protobuf:"varint,4,opt,name=to" json:"to,omitempty"`
+}
+
+func (m *QueryTelemetryRequest) Reset()                    { *m = QueryTelemetryRequest{} }
+func (m *QueryTelemetryRequest) String() string            { return proto.CompactTextString(m) }
+func (*QueryTelemetryRequest) ProtoMessage()               {}
+func (*QueryTelemetryRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{68} }
+
+func (m *QueryTelemetryRequest) GetDeviceid() uint64 {
+	if m != nil {
+		return m.Deviceid
+	}
+	return 0
+}
+
+func (m *QueryTelemetryRequest) GetMetric() string {
+	if m != nil {
+		return m.Metric
+	}
+	return ""
+}
+
+func (m *QueryTelemetryRequest) GetFrom() int64 {
+	if m != nil {
+		return m.From
+	}
+	return 0
+}
+
+func (m *QueryTelemetryRequest) GetTo() int64 {
+	if m != nil {
+		return m.To
+	}
+	return 0
+}
+
+type QueryTelemetryReply struct {
+	Series *TelemetrySeries `protobuf:"bytes,1,opt,name=series" json:"series,omitempty"`
+	Err    string           `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *QueryTelemetryReply) Reset()                    { *m = QueryTelemetryReply{} }
+func (m *QueryTelemetryReply) String() string            { return proto.CompactTextString(m) }
+func (*QueryTelemetryReply) ProtoMessage()               {}
+func (*QueryTelemetryReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{69} }
+
+func (m *QueryTelemetryReply) GetSeries() *TelemetrySeries {
+	if m != nil {
+		return m.Series
+	}
+	return nil
+}
+
+func (m *QueryTelemetryReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type AggregateGroup struct {
+	Key     string  `protobuf:"bytes,1,opt,name=key" json:"key,omitempty"`
+	Devices int32   `protobuf:"varint,2,opt,name=devices" json:"devices,omitempty"`
+	Min     float64 `protobuf:"fixed64,3,opt,name=min" json:"min,omitempty"`
+	Max     float64 `protobuf:"fixed64,4,opt,name=max" json:"max,omitempty"`
+	Avg     float64 `protobuf:"fixed64,5,opt,name=avg" json:"avg,omitempty"`
+	Sum     float64 `protobuf:"fixed64,6,opt,name=sum" json:"sum,omitempty"`
+	Count   int64   `protobuf:"varint,7,opt,name=count" json:"count,omitempty"`
+}
+
+func (m *AggregateGroup) Reset()                    { *m = AggregateGroup{} }
+func (m *AggregateGroup) String() string            { return proto.CompactTextString(m) }
+func (*AggregateGroup) ProtoMessage()               {}
+func (*AggregateGroup) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{70} }
+
+func (m *AggregateGroup) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *AggregateGroup) GetDevices() int32 {
+	if m != nil {
+		return m.Devices
+	}
+	return 0
+}
+
+func (m *AggregateGroup) GetMin() float64 {
+	if m != nil {
+		return m.Min
+	}
+	return 0
+}
+
+func (m *AggregateGroup) GetMax() float64 {
+	if m != nil {
+		return m.Max
+	}
+	return 0
+}
+
+func (m *AggregateGroup) GetAvg() float64 {
+	if m != nil {
+		return m.Avg
+	}
+	return 0
+}
+
+func (m *AggregateGroup) GetSum() float64 {
+	if m != nil {
+		return m.Sum
+	}
+	return 0
+}
+
+func (m *AggregateGroup) GetCount() int64 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+type AggregateTelemetryRequest struct {
+	Filter  *DeviceFilter `protobuf:"bytes,1,opt,name=filter" json:"filter,omitempty"`
+	Metric  string        `protobuf:"bytes,2,opt,name=metric" json:"metric,omitempty"`
+	From    int64         `protobuf:"varint,3,opt,name=from" json:"from,omitempty"`
+	To      int64         `protobuf:"varint,4,opt,name=to" json:"to,omitempty"`
+	Groupby string        `protobuf:"bytes,5,opt,name=groupby" json:"groupby,omitempty"`
+}
+
+func (m *AggregateTelemetryRequest) Reset()                    { *m = AggregateTelemetryRequest{} }
+func (m *AggregateTelemetryRequest) String() string            { return proto.CompactTextString(m) }
+func (*AggregateTelemetryRequest) ProtoMessage()               {}
+func (*AggregateTelemetryRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{71} }
+
+func (m *AggregateTelemetryRequest) GetFilter() *DeviceFilter {
+	if m != nil {
+		return m.Filter
+	}
+	return nil
+}
+
+func (m *AggregateTelemetryRequest) GetMetric() string {
+	if m != nil {
+		return m.Metric
+	}
+	return ""
+}
+
+func (m *AggregateTelemetryRequest) GetFrom() int64 {
+	if m != nil {
+		return m.From
+	}
+	return 0
+}
+
+func (m *AggregateTelemetryRequest) GetTo() int64 {
+	if m != nil {
+		return m.To
+	}
+	return 0
+}
+
+func (m *AggregateTelemetryRequest) GetGroupby() string {
+	if m != nil {
+		return m.Groupby
+	}
+	return ""
+}
+
+type AggregateTelemetryReply struct {
+	Metric     string            `protobuf:"bytes,1,opt,name=metric" json:"metric,omitempty"`
+	From       int64             `protobuf:"varint,2,opt,name=from" json:"from,omitempty"`
+	To         int64             `protobuf:"varint,3,opt,name=to" json:"to,omitempty"`
+	Resolution string            `protobuf:"bytes,4,opt,name=resolution" json:"resolution,omitempty"`
+	Groups     []*AggregateGroup `protobuf:"bytes,5,rep,name=groups" json:"groups,omitempty"`
+	Err        string            `protobuf:"bytes,6,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *AggregateTelemetryReply) Reset()                    { *m = AggregateTelemetryReply{} }
+func (m *AggregateTelemetryReply) String() string            { return proto.CompactTextString(m) }
+func (*AggregateTelemetryReply) ProtoMessage()               {}
+func (*AggregateTelemetryReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{72} }
+
+func (m *AggregateTelemetryReply) GetMetric() string {
+	if m != nil {
+		return m.Metric
+	}
+	return ""
+}
+
+func (m *AggregateTelemetryReply) GetFrom() int64 {
+	if m != nil {
+		return m.From
+	}
+	return 0
+}
+
+func (m *AggregateTelemetryReply) GetTo() int64 {
+	if m != nil {
+		return m.To
+	}
+	return 0
+}
+
+func (m *AggregateTelemetryReply) GetResolution() string {
+	if m != nil {
+		return m.Resolution
+	}
+	return ""
+}
+
+func (m *AggregateTelemetryReply) GetGroups() []*AggregateGroup {
+	if m != nil {
+		return m.Groups
+	}
+	return nil
+}
+
+func (m *AggregateTelemetryReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type SetLabelsRequest struct {
+	Deviceid uint64   `protobuf:"varint,1,opt,name=deviceid" json:"deviceid,omitempty"`
+	Labels   []*Label `protobuf:"bytes,2,rep,name=labels" json:"labels,omitempty"`
+}
+
+func (m *SetLabelsRequest) Reset()                    { *m = SetLabelsRequest{} }
+func (m *SetLabelsRequest) String() string            { return proto.CompactTextString(m) }
+func (*SetLabelsRequest) ProtoMessage()               {}
+func (*SetLabelsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{73} }
+
+func (m *SetLabelsRequest) GetDeviceid() uint64 {
+	if m != nil {
+		return m.Deviceid
+	}
+	return 0
+}
+
+func (m *SetLabelsRequest) GetLabels() []*Label {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+type SetLabelsReply struct {
+	Acknowledged bool   `protobuf:"varint,1,opt,name=acknowledged" json:"acknowledged,omitempty"`
+	Err          string `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *SetLabelsReply) Reset()                    { *m = SetLabelsReply{} }
+func (m *SetLabelsReply) String() string            { return proto.CompactTextString(m) }
+func (*SetLabelsReply) ProtoMessage()               {}
+func (*SetLabelsReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{74} }
+
+func (m *SetLabelsReply) GetAcknowledged() bool {
+	if m != nil {
+		return m.Acknowledged
+	}
+	return false
+}
+
+func (m *SetLabelsReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type ListStatusesRequest struct {
+	Filter *DeviceFilter `protobuf:"bytes,1,opt,name=filter" json:"filter,omitempty"`
+}
+
+func (m *ListStatusesRequest) Reset()                    { *m = ListStatusesRequest{} }
+func (m *ListStatusesRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListStatusesRequest) ProtoMessage()               {}
+func (*ListStatusesRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{75} }
+
+func (m *ListStatusesRequest) GetFilter() *DeviceFilter {
+	if m != nil {
+		return m.Filter
+	}
+	return nil
+}
+
+type ListStatusesReply struct {
+	Statuses []*DeviceStatus `protobuf:"bytes,1,rep,name=statuses" json:"statuses,omitempty"`
+	Err      string          `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *ListStatusesReply) Reset()                    { *m = ListStatusesReply{} }
+func (m *ListStatusesReply) String() string            { return proto.CompactTextString(m) }
+func (*ListStatusesReply) ProtoMessage()               {}
+func (*ListStatusesReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{76} }
+
+func (m *ListStatusesReply) GetStatuses() []*DeviceStatus {
+	if m != nil {
+		return m.Statuses
+	}
+	return nil
+}
+
+func (m *ListStatusesReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type Group struct {
+	Groupid  uint64 `protobuf:"varint,1,opt,name=groupid" json:"groupid,omitempty"`
+	Name     string `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+	Parentid uint64 `protobuf:"varint,3,opt,name=parentid" json:"parentid,omitempty"`
+}
+
+func (m *Group) Reset()                    { *m = Group{} }
+func (m *Group) String() string            { return proto.CompactTextString(m) }
+func (*Group) ProtoMessage()               {}
+func (*Group) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{77} }
+
+func (m *Group) GetGroupid() uint64 {
+	if m != nil {
+		return m.Groupid
+	}
+	return 0
+}
+
+func (m *Group) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Group) GetParentid() uint64 {
+	if m != nil {
+		return m.Parentid
+	}
+	return 0
+}
+
+type CreateGroupRequest struct {
+	Group *Group `protobuf:"bytes,1,opt,name=group" json:"group,omitempty"`
+}
+
+func (m *CreateGroupRequest) Reset()                    { *m = CreateGroupRequest{} }
+func (m *CreateGroupRequest) String() string            { return proto.CompactTextString(m) }
+func (*CreateGroupRequest) ProtoMessage()               {}
+func (*CreateGroupRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{78} }
+
+func (m *CreateGroupRequest) GetGroup() *Group {
+	if m != nil {
+		return m.Group
+	}
+	return nil
+}
+
+type CreateGroupReply struct {
+	Groupid uint64 `protobuf:"varint,1,opt,name=groupid" json:"groupid,omitempty"`
+	Err     string `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *CreateGroupReply) Reset()                    { *m = CreateGroupReply{} }
+func (m *CreateGroupReply) String() string            { return proto.CompactTextString(m) }
+func (*CreateGroupReply) ProtoMessage()               {}
+func (*CreateGroupReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{79} }
+
+func (m *CreateGroupReply) GetGroupid() uint64 {
+	if m != nil {
+		return m.Groupid
+	}
+	return 0
+}
+
+func (m *CreateGroupReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type UpdateGroupRequest struct {
+	Group *Group `protobuf:"bytes,1,opt,name=group" json:"group,omitempty"`
+}
+
+func (m *UpdateGroupRequest) Reset()                    { *m = UpdateGroupRequest{} }
+func (m *UpdateGroupRequest) String() string            { return proto.CompactTextString(m) }
+func (*UpdateGroupRequest) ProtoMessage()               {}
+func (*UpdateGroupRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{80} }
+
+func (m *UpdateGroupRequest) GetGroup() *Group {
+	if m != nil {
+		return m.Group
+	}
+	return nil
+}
+
+type UpdateGroupReply struct {
+	Acknowledged bool   `protobuf:"varint,1,opt,name=acknowledged" json:"acknowledged,omitempty"`
+	Err          string `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *UpdateGroupReply) Reset()                    { *m = UpdateGroupReply{} }
+func (m *UpdateGroupReply) String() string            { return proto.CompactTextString(m) }
+func (*UpdateGroupReply) ProtoMessage()               {}
+func (*UpdateGroupReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{81} }
+
+func (m *UpdateGroupReply) GetAcknowledged() bool {
+	if m != nil {
+		return m.Acknowledged
+	}
+	return false
+}
+
+func (m *UpdateGroupReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type DeleteGroupRequest struct {
+	Groupid uint64 `protobuf:"varint,1,opt,name=groupid" json:"groupid,omitempty"`
+}
+
+func (m *DeleteGroupRequest) Reset()                    { *m = DeleteGroupRequest{} }
+func (m *DeleteGroupRequest) String() string            { return proto.CompactTextString(m) }
+func (*DeleteGroupRequest) ProtoMessage()               {}
+func (*DeleteGroupRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{82} }
+
+func (m *DeleteGroupRequest) GetGroupid() uint64 {
+	if m != nil {
+		return m.Groupid
+	}
+	return 0
+}
+
+type DeleteGroupReply struct {
+	Acknowledged bool   `protobuf:"varint,1,opt,name=acknowledged" json:"acknowledged,omitempty"`
+	Err          string `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *DeleteGroupReply) Reset()                    { *m = DeleteGroupReply{} }
+func (m *DeleteGroupReply) String() string            { return proto.CompactTextString(m) }
+func (*DeleteGroupReply) ProtoMessage()               {}
+func (*DeleteGroupReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{83} }
+
+func (m *DeleteGroupReply) GetAcknowledged() bool {
+	if m != nil {
+		return m.Acknowledged
+	}
+	return false
+}
+
+func (m *DeleteGroupReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type ListGroupsRequest struct {
+}
+
+func (m *ListGroupsRequest) Reset()                    { *m = ListGroupsRequest{} }
+func (m *ListGroupsRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListGroupsRequest) ProtoMessage()               {}
+func (*ListGroupsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{84} }
+
+type ListGroupsReply struct {
+	Groups []*Group `protobuf:"bytes,1,rep,name=groups" json:"groups,omitempty"`
+	Err    string   `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *ListGroupsReply) Reset()                    { *m = ListGroupsReply{} }
+func (m *ListGroupsReply) String() string            { return proto.CompactTextString(m) }
+func (*ListGroupsReply) ProtoMessage()               {}
+func (*ListGroupsReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{85} }
+
+func (m *ListGroupsReply) GetGroups() []*Group {
+	if m != nil {
+		return m.Groups
+	}
+	return nil
+}
+
+func (m *ListGroupsReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type AddGroupMembersRequest struct {
+	Groupid   uint64   `protobuf:"varint,1,opt,name=groupid" json:"groupid,omitempty"`
+	Deviceids []uint64 `protobuf:"varint,2,rep,name=deviceids,packed" json:"deviceids,omitempty"`
+}
+
+func (m *AddGroupMembersRequest) Reset()                    { *m = AddGroupMembersRequest{} }
+func (m *AddGroupMembersRequest) String() string            { return proto.CompactTextString(m) }
+func (*AddGroupMembersRequest) ProtoMessage()               {}
+func (*AddGroupMembersRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{86} }
+
+func (m *AddGroupMembersRequest) GetGroupid() uint64 {
+	if m != nil {
+		return m.Groupid
+	}
+	return 0
+}
+
+func (m *AddGroupMembersRequest) GetDeviceids() []uint64 {
+	if m != nil {
+		return m.Deviceids
+	}
+	return nil
+}
+
+type AddGroupMembersReply struct {
+	Acknowledged bool   `protobuf:"varint,1,opt,name=acknowledged" json:"acknowledged,omitempty"`
+	Err          string `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *AddGroupMembersReply) Reset()                    { *m = AddGroupMembersReply{} }
+func (m *AddGroupMembersReply) String() string            { return proto.CompactTextString(m) }
+func (*AddGroupMembersReply) ProtoMessage()               {}
+func (*AddGroupMembersReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{87} }
+
+func (m *AddGroupMembersReply) GetAcknowledged() bool {
+	if m != nil {
+		return m.Acknowledged
+	}
+	return false
+}
+
+func (m *AddGroupMembersReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type RemoveGroupMembersRequest struct {
+	Groupid   uint64   `protobuf:"varint,1,opt,name=groupid" json:"groupid,omitempty"`
+	Deviceids []uint64 `protobuf:"varint,2,rep,name=deviceids,packed" json:"deviceids,omitempty"`
+}
+
+func (m *RemoveGroupMembersRequest) Reset()                    { *m = RemoveGroupMembersRequest{} }
+func (m *RemoveGroupMembersRequest) String() string            { return proto.CompactTextString(m) }
+func (*RemoveGroupMembersRequest) ProtoMessage()               {}
+func (*RemoveGroupMembersRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{88} }
+
+func (m *RemoveGroupMembersRequest) GetGroupid() uint64 {
+	if m != nil {
+		return m.Groupid
+	}
+	return 0
+}
+
+func (m *RemoveGroupMembersRequest) GetDeviceids() []uint64 {
+	if m != nil {
+		return m.Deviceids
+	}
+	return nil
+}
+
+type RemoveGroupMembersReply struct {
+	Acknowledged bool   `protobuf:"varint,1,opt,name=acknowledged" json:"acknowledged,omitempty"`
+	Err          string `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *RemoveGroupMembersReply) Reset()                    { *m = RemoveGroupMembersReply{} }
+func (m *RemoveGroupMembersReply) String() string            { return proto.CompactTextString(m) }
+func (*RemoveGroupMembersReply) ProtoMessage()               {}
+func (*RemoveGroupMembersReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{89} }
+
+func (m *RemoveGroupMembersReply) GetAcknowledged() bool {
+	if m != nil {
+		return m.Acknowledged
+	}
+	return false
+}
+
+func (m *RemoveGroupMembersReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type RelayStatusRequest struct {
+	Gatewayid        uint64          `protobuf:"varint,1,opt,name=gatewayid" json:"gatewayid,omitempty"`
+	Deviceid         uint64          `protobuf:"varint,2,opt,name=deviceid" json:"deviceid,omitempty"`
+	Location         *Location       `protobuf:"bytes,3,opt,name=location" json:"location,omitempty"`
+	Batteryremaining uint32          `protobuf:"varint,4,opt,name=batteryremaining" json:"batteryremaining,omitempty"`
+	Firmware         *FirmwareReport `protobuf:"bytes,5,opt,name=firmware" json:"firmware,omitempty"`
+	Deliver          bool            `protobuf:"varint,6,opt,name=deliver" json:"deliver,omitempty"`
+}
+
+func (m *RelayStatusRequest) Reset()                    { *m = RelayStatusRequest{} }
+func (m *RelayStatusRequest) String() string            { return proto.CompactTextString(m) }
+func (*RelayStatusRequest) ProtoMessage()               {}
+func (*RelayStatusRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{90} }
+
+func (m *RelayStatusRequest) GetGatewayid() uint64 {
+	if m != nil {
+		return m.Gatewayid
+	}
+	return 0
+}
+
+func (m *RelayStatusRequest) GetDeviceid() uint64 {
+	if m != nil {
+		return m.Deviceid
+	}
+	return 0
+}
+
+func (m *RelayStatusRequest) GetLocation() *Location {
+	if m != nil {
+		return m.Location
+	}
+	return nil
+}
+
+func (m *RelayStatusRequest) GetBatteryremaining() uint32 {
+	if m != nil {
+		return m.Batteryremaining
+	}
+	return 0
+}
+
+func (m *RelayStatusRequest) GetFirmware() *FirmwareReport {
+	if m != nil {
+		return m.Firmware
+	}
+	return nil
+}
+
+func (m *RelayStatusRequest) GetDeliver() bool {
+	if m != nil {
+		return m.Deliver
+	}
+	return false
+}
+
+type RelayStatusReply struct {
+	Acknowledged bool           `protobuf:"varint,1,opt,name=acknowledged" json:"acknowledged,omitempty"`
+	Err          string         `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+	Desired      *DesiredState  `protobuf:"bytes,3,opt,name=desired" json:"desired,omitempty"`
+	Commands     []*Command     `protobuf:"bytes,4,rep,name=commands" json:"commands,omitempty"`
+	Firmware     *FirmwareOffer `protobuf:"bytes,5,opt,name=firmware" json:"firmware,omitempty"`
+}
+
+func (m *RelayStatusReply) Reset()                    { *m = RelayStatusReply{} }
+func (m *RelayStatusReply) String() string            { return proto.CompactTextString(m) }
+func (*RelayStatusReply) ProtoMessage()               {}
+func (*RelayStatusReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{91} }
+
+func (m *RelayStatusReply) GetAcknowledged() bool {
+	if m != nil {
+		return m.Acknowledged
+	}
+	return false
+}
+
+func (m *RelayStatusReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+func (m *RelayStatusReply) GetDesired() *DesiredState {
+	if m != nil {
+		return m.Desired
+	}
+	return nil
+}
+
+func (m *RelayStatusReply) GetCommands() []*Command {
+	if m != nil {
+		return m.Commands
+	}
+	return nil
+}
+
+func (m *RelayStatusReply) GetFirmware() *FirmwareOffer {
+	if m != nil {
+		return m.Firmware
+	}
+	return nil
+}
+
+type RelayTelemetryRequest struct {
+	Gatewayid uint64                     `protobuf:"varint,1,opt,name=gatewayid" json:"gatewayid,omitempty"`
+	Deviceid  uint64                     `protobuf:"varint,2,opt,name=deviceid" json:"deviceid,omitempty"`
+	Readings  map[string]float32         `protobuf:"bytes,3,rep,name=readings" json:"readings,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed32,2,opt,name=value"`
+	Values    map[string]*TelemetryValue `protobuf:"bytes,4,rep,name=values" json:"values,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+}
+
+func (m *RelayTelemetryRequest) Reset()                    { *m = RelayTelemetryRequest{} }
+func (m *RelayTelemetryRequest) String() string            { return proto.CompactTextString(m) }
+func (*RelayTelemetryRequest) ProtoMessage()               {}
+func (*RelayTelemetryRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{92} }
+
+func (m *RelayTelemetryRequest) GetGatewayid() uint64 {
+	if m != nil {
+		return m.Gatewayid
+	}
+	return 0
+}
+
+func (m *RelayTelemetryRequest) GetDeviceid() uint64 {
+	if m != nil {
+		return m.Deviceid
+	}
+	return 0
+}
+
+func (m *RelayTelemetryRequest) GetReadings() map[string]float32 {
+	if m != nil {
+		return m.Readings
+	}
+	return nil
+}
+
+func (m *RelayTelemetryRequest) GetValues() map[string]*TelemetryValue {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+type RelayTelemetryReply struct {
+	Acknowledged bool               `protobuf:"varint,1,opt,name=acknowledged" json:"acknowledged,omitempty"`
+	Err          string             `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+	Violations   []*SchemaViolation `protobuf:"bytes,3,rep,name=violations" json:"violations,omitempty"`
+}
+
+func (m *RelayTelemetryReply) Reset()                    { *m = RelayTelemetryReply{} }
+func (m *RelayTelemetryReply) String() string            { return proto.CompactTextString(m) }
+func (*RelayTelemetryReply) ProtoMessage()               {}
+func (*RelayTelemetryReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{93} }
+
+func (m *RelayTelemetryReply) GetAcknowledged() bool {
+	if m != nil {
+		return m.Acknowledged
+	}
+	return false
+}
+
+func (m *RelayTelemetryReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+func (m *RelayTelemetryReply) GetViolations() []*SchemaViolation {
+	if m != nil {
+		return m.Violations
+	}
+	return nil
+}
+
+type TopologyNode struct {
+	Device   *Device         `protobuf:"bytes,1,opt,name=device" json:"device,omitempty"`
+	Children []*TopologyNode `protobuf:"bytes,2,rep,name=children" json:"children,omitempty"`
+}
+
+func (m *TopologyNode) Reset()                    { *m = TopologyNode{} }
+func (m *TopologyNode) String() string            { return proto.CompactTextString(m) }
+func (*TopologyNode) ProtoMessage()               {}
+func (*TopologyNode) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{94} }
+
+func (m *TopologyNode) GetDevice() *Device {
+	if m != nil {
+		return m.Device
+	}
+	return nil
+}
+
+func (m *TopologyNode) GetChildren() []*TopologyNode {
+	if m != nil {
+		return m.Children
+	}
+	return nil
+}
+
+type GetTopologyRequest struct {
+	Deviceid uint64 `protobuf:"varint,1,opt,name=deviceid" json:"deviceid,omitempty"`
+}
+
+func (m *GetTopologyRequest) Reset()                    { *m = GetTopologyRequest{} }
+func (m *GetTopologyRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetTopologyRequest) ProtoMessage()               {}
+func (*GetTopologyRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{95} }
+
+func (m *GetTopologyRequest) GetDeviceid() uint64 {
+	if m != nil {
+		return m.Deviceid
+	}
+	return 0
+}
+
+type GetTopologyReply struct {
+	Topology *TopologyNode `protobuf:"bytes,1,opt,name=topology" json:"topology,omitempty"`
+	Err      string        `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *GetTopologyReply) Reset()                    { *m = GetTopologyReply{} }
+func (m *GetTopologyReply) String() string            { return proto.CompactTextString(m) }
+func (*GetTopologyReply) ProtoMessage()               {}
+func (*GetTopologyReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{96} }
+
+func (m *GetTopologyReply) GetTopology() *TopologyNode {
+	if m != nil {
+		return m.Topology
+	}
+	return nil
+}
+
+func (m *GetTopologyReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type Twin struct {
+	Deviceid         uint64 `protobuf:"varint,1,opt,name=deviceid" json:"deviceid,omitempty"`
+	Desired          string `protobuf:"bytes,2,opt,name=desired" json:"desired,omitempty"`
+	Desiredversion   int64  `protobuf:"varint,3,opt,name=desiredversion" json:"desiredversion,omitempty"`
+	Reported         string `protobuf:"bytes,4,opt,name=reported" json:"reported,omitempty"`
+	Reportedversion  int64  `protobuf:"varint,5,opt,name=reportedversion" json:"reportedversion,omitempty"`
+	Delta            string `protobuf:"bytes,6,opt,name=delta" json:"delta,omitempty"`
+	Deliveredversion int64  `protobuf:"varint,7,opt,name=deliveredversion" json:"deliveredversion,omitempty"`
+}
+
+func (m *Twin) Reset()                    { *m = Twin{} }
+func (m *Twin) String() string            { return proto.CompactTextString(m) }
+func (*Twin) ProtoMessage()               {}
+func (*Twin) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{97} }
+
+func (m *Twin) GetDeviceid() uint64 {
+	if m != nil {
+		return m.Deviceid
+	}
+	return 0
+}
+
+func (m *Twin) GetDesired() string {
+	if m != nil {
+		return m.Desired
+	}
+	return ""
+}
+
+func (m *Twin) GetDesiredversion() int64 {
+	if m != nil {
+		return m.Desiredversion
+	}
+	return 0
+}
+
+func (m *Twin) GetReported() string {
+	if m != nil {
+		return m.Reported
+	}
+	return ""
+}
+
+func (m *Twin) GetReportedversion() int64 {
+	if m != nil {
+		return m.Reportedversion
+	}
+	return 0
+}
+
+func (m *Twin) GetDelta() string {
+	if m != nil {
+		return m.Delta
+	}
+	return ""
+}
+
+func (m *Twin) GetDeliveredversion() int64 {
+	if m != nil {
+		return m.Deliveredversion
+	}
+	return 0
+}
+
+type DesiredState struct {
+	Version int64  `protobuf:"varint,1,opt,name=version" json:"version,omitempty"`
+	Desired string `protobuf:"bytes,2,opt,name=desired" json:"desired,omitempty"`
+	Delta   string `protobuf:"bytes,3,opt,name=delta" json:"delta,omitempty"`
+}
+
+func (m *DesiredState) Reset()                    { *m = DesiredState{} }
+func (m *DesiredState) String() string            { return proto.CompactTextString(m) }
+func (*DesiredState) ProtoMessage()               {}
+func (*DesiredState) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{98} }
+
+func (m *DesiredState) GetVersion() int64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *DesiredState) GetDesired() string {
+	if m != nil {
+		return m.Desired
+	}
+	return ""
+}
+
+func (m *DesiredState) GetDelta() string {
+	if m != nil {
+		return m.Delta
+	}
+	return ""
+}
+
+type GetTwinRequest struct {
+	Deviceid uint64 `protobuf:"varint,1,opt,name=deviceid" json:"deviceid,omitempty"`
+}
+
+func (m *GetTwinRequest) Reset()                    { *m = GetTwinRequest{} }
+func (m *GetTwinRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetTwinRequest) ProtoMessage()               {}
+func (*GetTwinRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{99} }
+
+func (m *GetTwinRequest) GetDeviceid() uint64 {
+	if m != nil {
+		return m.Deviceid
+	}
+	return 0
+}
+
+type GetTwinReply struct {
+	Twin *Twin  `protobuf:"bytes,1,opt,name=twin" json:"twin,omitempty"`
+	Err  string `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *GetTwinReply) Reset()                    { *m = GetTwinReply{} }
+func (m *GetTwinReply) String() string            { return proto.CompactTextString(m) }
+func (*GetTwinReply) ProtoMessage()               {}
+func (*GetTwinReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{100} }
+
+func (m *GetTwinReply) GetTwin() *Twin {
+	if m != nil {
+		return m.Twin
+	}
+	return nil
+}
+
+func (m *GetTwinReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type UpdateDesiredRequest struct {
+	Deviceid uint64 `protobuf:"varint,1,opt,name=deviceid" json:"deviceid,omitempty"`
+	Patch    string `protobuf:"bytes,2,opt,name=patch" json:"patch,omitempty"`
+	Version  int64  `protobuf:"varint,3,opt,name=version" json:"version,omitempty"`
+}
+
+func (m *UpdateDesiredRequest) Reset()                    { *m = UpdateDesiredRequest{} }
+func (m *UpdateDesiredRequest) String() string            { return proto.CompactTextString(m) }
+func (*UpdateDesiredRequest) ProtoMessage()               {}
+func (*UpdateDesiredRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{101} }
+
+func (m *UpdateDesiredRequest) GetDeviceid() uint64 {
+	if m != nil {
+		return m.Deviceid
+	}
+	return 0
+}
+
+func (m *UpdateDesiredRequest) GetPatch() string {
+	if m != nil {
+		return m.Patch
+	}
+	return ""
+}
+
+func (m *UpdateDesiredRequest) GetVersion() int64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+type UpdateDesiredReply struct {
+	Twin *Twin  `protobuf:"bytes,1,opt,name=twin" json:"twin,omitempty"`
+	Err  string `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *UpdateDesiredReply) Reset()                    { *m = UpdateDesiredReply{} }
+func (m *UpdateDesiredReply) String() string            { return proto.CompactTextString(m) }
+func (*UpdateDesiredReply) ProtoMessage()               {}
+func (*UpdateDesiredReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{102} }
+
+func (m *UpdateDesiredReply) GetTwin() *Twin {
+	if m != nil {
+		return m.Twin
+	}
+	return nil
+}
+
+func (m *UpdateDesiredReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type ReportStateRequest struct {
+	Deviceid uint64 `protobuf:"varint,1,opt,name=deviceid" json:"deviceid,omitempty"`
+	Patch    string `protobuf:"bytes,2,opt,name=patch" json:"patch,omitempty"`
+}
+
+func (m *ReportStateRequest) Reset()                    { *m = ReportStateRequest{} }
+func (m *ReportStateRequest) String() string            { return proto.CompactTextString(m) }
+func (*ReportStateRequest) ProtoMessage()               {}
+func (*ReportStateRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{103} }
+
+func (m *ReportStateRequest) GetDeviceid() uint64 {
+	if m != nil {
+		return m.Deviceid
+	}
+	return 0
+}
+
+func (m *ReportStateRequest) GetPatch() string {
+	if m != nil {
+		return m.Patch
+	}
+	return ""
+}
+
+type ReportStateReply struct {
+	Twin *Twin  `protobuf:"bytes,1,opt,name=twin" json:"twin,omitempty"`
+	Err  string `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *ReportStateReply) Reset()                    { *m = ReportStateReply{} }
+func (m *ReportStateReply) String() string            { return proto.CompactTextString(m) }
+func (*ReportStateReply) ProtoMessage()               {}
+func (*ReportStateReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{104} }
+
+func (m *ReportStateReply) GetTwin() *Twin {
+	if m != nil {
+		return m.Twin
+	}
+	return nil
+}
+
+func (m *ReportStateReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type WatchDesiredRequest struct {
+	Deviceid uint64 `protobuf:"varint,1,opt,name=deviceid" json:"deviceid,omitempty"`
+}
+
+func (m *WatchDesiredRequest) Reset()                    { *m = WatchDesiredRequest{} }
+func (m *WatchDesiredRequest) String() string            { return proto.CompactTextString(m) }
+func (*WatchDesiredRequest) ProtoMessage()               {}
+func (*WatchDesiredRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{105} }
+
+func (m *WatchDesiredRequest) GetDeviceid() uint64 {
+	if m != nil {
+		return m.Deviceid
+	}
+	return 0
+}
+
+type Command struct {
+	Commandid   uint64 `protobuf:"varint,1,opt,name=commandid" json:"commandid,omitempty"`
+	Deviceid    uint64 `protobuf:"varint,2,opt,name=deviceid" json:"deviceid,omitempty"`
+	Name        string `protobuf:"bytes,3,opt,name=name" json:"name,omitempty"`
+	Args        string `protobuf:"bytes,4,opt,name=args" json:"args,omitempty"`
+	Status      string `protobuf:"bytes,5,opt,name=status" json:"status,omitempty"`
+	Createdat   int64  `protobuf:"varint,6,opt,name=createdat" json:"createdat,omitempty"`
+	Expiresat   int64  `protobuf:"varint,7,opt,name=expiresat" json:"expiresat,omitempty"`
+	Deliveredat int64  `protobuf:"varint,8,opt,name=deliveredat" json:"deliveredat,omitempty"`
+	Completedat int64  `protobuf:"varint,9,opt,name=completedat" json:"completedat,omitempty"`
+	Result      string `protobuf:"bytes,10,opt,name=result" json:"result,omitempty"`
+}
+
+func (m *Command) Reset()                    { *m = Command{} }
+func (m *Command) String() string            { return proto.CompactTextString(m) }
+func (*Command) ProtoMessage()               {}
+func (*Command) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{106} }
+
+func (m *Command) GetCommandid() uint64 {
+	if m != nil {
+		return m.Commandid
+	}
+	return 0
+}
+
+func (m *Command) GetDeviceid() uint64 {
+	if m != nil {
+		return m.Deviceid
+	}
+	return 0
+}
+
+func (m *Command) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Command) GetArgs() string {
+	if m != nil {
+		return m.Args
+	}
+	return ""
+}
+
+func (m *Command) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *Command) GetCreatedat() int64 {
+	if m != nil {
+		return m.Createdat
+	}
+	return 0
+}
+
+func (m *Command) GetExpiresat() int64 {
+	if m != nil {
+		return m.Expiresat
+	}
+	return 0
+}
+
+func (m *Command) GetDeliveredat() int64 {
+	if m != nil {
+		return m.Deliveredat
+	}
+	return 0
+}
+
+func (m *Command) GetCompletedat() int64 {
+	if m != nil {
+		return m.Completedat
+	}
+	return 0
+}
+
+func (m *Command) GetResult() string {
+	if m != nil {
+		return m.Result
+	}
+	return ""
+}
+
+type EnqueueCommandRequest struct {
+	Deviceid uint64 `protobuf:"varint,1,opt,name=deviceid" json:"deviceid,omitempty"`
+	Name     string `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+	Args     string `protobuf:"bytes,3,opt,name=args" json:"args,omitempty"`
+	Ttl      int64  `protobuf:"varint,4,opt,name=ttl" json:"ttl,omitempty"`
+}
+
+func (m *EnqueueCommandRequest) Reset()                    { *m = EnqueueCommandRequest{} }
+func (m *EnqueueCommandRequest) String() string            { return proto.CompactTextString(m) }
+func (*EnqueueCommandRequest) ProtoMessage()               {}
+func (*EnqueueCommandRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{107} }
+
+func (m *EnqueueCommandRequest) GetDeviceid() uint64 {
+	if m != nil {
+		return m.Deviceid
+	}
+	return 0
+}
+
+func (m *EnqueueCommandRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *EnqueueCommandRequest) GetArgs() string {
+	if m != nil {
+		return m.Args
+	}
+	return ""
+}
+
+func (m *EnqueueCommandRequest) GetTtl() int64 {
+	if m != nil {
+		return m.Ttl
+	}
+	return 0
+}
+
+type EnqueueCommandReply struct {
+	Commandid uint64 `protobuf:"varint,1,opt,name=commandid" json:"commandid,omitempty"`
+	Err       string `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *EnqueueCommandReply) Reset()                    { *m = EnqueueCommandReply{} }
+func (m *EnqueueCommandReply) String() string            { return proto.CompactTextString(m) }
+func (*EnqueueCommandReply) ProtoMessage()               {}
+func (*EnqueueCommandReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{108} }
+
+func (m *EnqueueCommandReply) GetCommandid() uint64 {
+	if m != nil {
+		return m.Commandid
+	}
+	return 0
+}
+
+func (m *EnqueueCommandReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type AckCommandRequest struct {
+	Deviceid  uint64 `protobuf:"varint,1,opt,name=deviceid" json:"deviceid,omitempty"`
+	Commandid uint64 `protobuf:"varint,2,opt,name=commandid" json:"commandid,omitempty"`
+	Success   bool   `protobuf:"varint,3,opt,name=success" json:"success,omitempty"`
+	Result    string `protobuf:"bytes,4,opt,name=result" json:"result,omitempty"`
+}
+
+func (m *AckCommandRequest) Reset()                    { *m = AckCommandRequest{} }
+func (m *AckCommandRequest) String() string            { return proto.CompactTextString(m) }
+func (*AckCommandRequest) ProtoMessage()               {}
+func (*AckCommandRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{109} }
+
+func (m *AckCommandRequest) GetDeviceid() uint64 {
+	if m != nil {
+		return m.Deviceid
+	}
+	return 0
+}
+
+func (m *AckCommandRequest) GetCommandid() uint64 {
+	if m != nil {
+		return m.Commandid
+	}
+	return 0
+}
+
+func (m *AckCommandRequest) GetSuccess() bool {
+	if m != nil {
+		return m.Success
+	}
+	return false
+}
+
+func (m *AckCommandRequest) GetResult() string {
+	if m != nil {
+		return m.Result
+	}
+	return ""
+}
+
+type AckCommandReply struct {
+	Acknowledged bool   `protobuf:"varint,1,opt,name=acknowledged" json:"acknowledged,omitempty"`
+	Err          string `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *AckCommandReply) Reset()                    { *m = AckCommandReply{} }
+func (m *AckCommandReply) String() string            { return proto.CompactTextString(m) }
+func (*AckCommandReply) ProtoMessage()               {}
+func (*AckCommandReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{110} }
+
+func (m *AckCommandReply) GetAcknowledged() bool {
+	if m != nil {
+		return m.Acknowledged
+	}
+	return false
+}
+
+func (m *AckCommandReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type CommandHistoryRequest struct {
+	Deviceid uint64 `protobuf:"varint,1,opt,name=deviceid" json:"deviceid,omitempty"`
+	Limit    int32  `protobuf:"varint,2,opt,name=limit" json:"limit,omitempty"`
+}
+
+func (m *CommandHistoryRequest) Reset()                    { *m = CommandHistoryRequest{} }
+func (m *CommandHistoryRequest) String() string            { return proto.CompactTextString(m) }
+func (*CommandHistoryRequest) ProtoMessage()               {}
+func (*CommandHistoryRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{111} }
+
+func (m *CommandHistoryRequest) GetDeviceid() uint64 {
+	if m != nil {
+		return m.Deviceid
+	}
+	return 0
+}
+
+func (m *CommandHistoryRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type CommandHistoryReply struct {
+	Commands []*Command `protobuf:"bytes,1,rep,name=commands" json:"commands,omitempty"`
+	Err      string     `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *CommandHistoryReply) Reset()                    { *m = CommandHistoryReply{} }
+func (m *CommandHistoryReply) String() string            { return proto.CompactTextString(m) }
+func (*CommandHistoryReply) ProtoMessage()               {}
+func (*CommandHistoryReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{112} }
+
+func (m *CommandHistoryReply) GetCommands() []*Command {
+	if m != nil {
+		return m.Commands
+	}
+	return nil
+}
+
+func (m *CommandHistoryReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type WatchCommandsRequest struct {
+	Deviceid uint64 `protobuf:"varint,1,opt,name=deviceid" json:"deviceid,omitempty"`
+}
+
+func (m *WatchCommandsRequest) Reset()                    { *m = WatchCommandsRequest{} }
+func (m *WatchCommandsRequest) String() string            { return proto.CompactTextString(m) }
+func (*WatchCommandsRequest) ProtoMessage()               {}
+func (*WatchCommandsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{113} }
+
+func (m *WatchCommandsRequest) GetDeviceid() uint64 {
+	if m != nil {
+		return m.Deviceid
+	}
+	return 0
+}
+
+type FirmwareReport struct {
+	Version    string `protobuf:"bytes,1,opt,name=version" json:"version,omitempty"`
+	Campaignid uint64 `protobuf:"varint,2,opt,name=campaignid" json:"campaignid,omitempty"`
+	State      string `protobuf:"bytes,3,opt,name=state" json:"state,omitempty"`
+	Progress   int32  `protobuf:"varint,4,opt,name=progress" json:"progress,omitempty"`
+	Error      string `protobuf:"bytes,5,opt,name=error" json:"error,omitempty"`
+}
+
+func (m *FirmwareReport) Reset()                    { *m = FirmwareReport{} }
+func (m *FirmwareReport) String() string            { return proto.CompactTextString(m) }
+func (*FirmwareReport) ProtoMessage()               {}
+func (*FirmwareReport) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{114} }
+
+func (m *FirmwareReport) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+func (m *FirmwareReport) GetCampaignid() uint64 {
+	if m != nil {
+		return m.Campaignid
+	}
+	return 0
+}
+
+func (m *FirmwareReport) GetState() string {
+	if m != nil {
+		return m.State
+	}
+	return ""
+}
+
+func (m *FirmwareReport) GetProgress() int32 {
+	if m != nil {
+		return m.Progress
+	}
+	return 0
+}
+
+func (m *FirmwareReport) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+type FirmwareOffer struct {
+	Campaignid uint64 `protobuf:"varint,1,opt,name=campaignid" json:"campaignid,omitempty"`
+	Firmwareid uint64 `protobuf:"varint,2,opt,name=firmwareid" json:"firmwareid,omitempty"`
+	Version    string `protobuf:"bytes,3,opt,name=version" json:"version,omitempty"`
+	Checksum   string `protobuf:"bytes,4,opt,name=checksum" json:"checksum,omitempty"`
+	Size       int64  `protobuf:"varint,5,opt,name=size" json:"size,omitempty"`
+}
+
+func (m *FirmwareOffer) Reset()                    { *m = FirmwareOffer{} }
+func (m *FirmwareOffer) String() string            { return proto.CompactTextString(m) }
+func (*FirmwareOffer) ProtoMessage()               {}
+func (*FirmwareOffer) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{115} }
+
+func (m *FirmwareOffer) GetCampaignid() uint64 {
+	if m != nil {
+		return m.Campaignid
+	}
+	return 0
+}
+
+func (m *FirmwareOffer) GetFirmwareid() uint64 {
+	if m != nil {
+		return m.Firmwareid
+	}
+	return 0
+}
+
+func (m *FirmwareOffer) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+func (m *FirmwareOffer) GetChecksum() string {
+	if m != nil {
+		return m.Checksum
+	}
+	return ""
+}
+
+func (m *FirmwareOffer) GetSize() int64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+type FirmwareArtifact struct {
+	Firmwareid uint64 `protobuf:"varint,1,opt,name=firmwareid" json:"firmwareid,omitempty"`
+	Devicetype string `protobuf:"bytes,2,opt,name=devicetype" json:"devicetype,omitempty"`
+	Version    string `protobuf:"bytes,3,opt,name=version" json:"version,omitempty"`
+	Checksum   string `protobuf:"bytes,4,opt,name=checksum" json:"checksum,omitempty"`
+	Size       int64  `protobuf:"varint,5,opt,name=size" json:"size,omitempty"`
+	Createdat  int64  `protobuf:"varint,6,opt,name=createdat" json:"createdat,omitempty"`
+}
+
+func (m *FirmwareArtifact) Reset()                    { *m = FirmwareArtifact{} }
+func (m *FirmwareArtifact) String() string            { return proto.CompactTextString(m) }
+func (*FirmwareArtifact) ProtoMessage()               {}
+func (*FirmwareArtifact) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{116} }
+
+func (m *FirmwareArtifact) GetFirmwareid() uint64 {
+	if m != nil {
+		return m.Firmwareid
+	}
+	return 0
+}
+
+func (m *FirmwareArtifact) GetDevicetype() string {
+	if m != nil {
+		return m.Devicetype
+	}
+	return ""
+}
+
+func (m *FirmwareArtifact) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+func (m *FirmwareArtifact) GetChecksum() string {
+	if m != nil {
+		return m.Checksum
+	}
+	return ""
+}
+
+func (m *FirmwareArtifact) GetSize() int64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *FirmwareArtifact) GetCreatedat() int64 {
+	if m != nil {
+		return m.Createdat
+	}
+	return 0
+}
+
+type CampaignStats struct {
+	Targeted    int32   `protobuf:"varint,1,opt,name=targeted" json:"targeted,omitempty"`
+	Offered     int32   `protobuf:"varint,2,opt,name=offered" json:"offered,omitempty"`
+	Downloading int32   `protobuf:"varint,3,opt,name=downloading" json:"downloading,omitempty"`
+	Installing  int32   `protobuf:"varint,4,opt,name=installing" json:"installing,omitempty"`
+	Installed   int32   `protobuf:"varint,5,opt,name=installed" json:"installed,omitempty"`
+	Failed      int32   `protobuf:"varint,6,opt,name=failed" json:"failed,omitempty"`
+	Failurerate float64 `protobuf:"fixed64,7,opt,name=failurerate" json:"failurerate,omitempty"`
+}
+
+func (m *CampaignStats) Reset()                    { *m = CampaignStats{} }
+func (m *CampaignStats) String() string            { return proto.CompactTextString(m) }
+func (*CampaignStats) ProtoMessage()               {}
+func (*CampaignStats) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{117} }
+
+func (m *CampaignStats) GetTargeted() int32 {
+	if m != nil {
+		return m.Targeted
+	}
+	return 0
+}
+
+func (m *CampaignStats) GetOffered() int32 {
+	if m != nil {
+		return m.Offered
+	}
+	return 0
+}
+
+func (m *CampaignStats) GetDownloading() int32 {
+	if m != nil {
+		return m.Downloading
+	}
+	return 0
+}
+
+func (m *CampaignStats) GetInstalling() int32 {
+	if m != nil {
+		return m.Installing
+	}
+	return 0
+}
+
+func (m *CampaignStats) GetInstalled() int32 {
+	if m != nil {
+		return m.Installed
+	}
+	return 0
+}
+
+func (m *CampaignStats) GetFailed() int32 {
+	if m != nil {
+		return m.Failed
+	}
+	return 0
+}
+
+func (m *CampaignStats) GetFailurerate() float64 {
+	if m != nil {
+		return m.Failurerate
+	}
+	return 0
+}
+
+type Campaign struct {
+	Campaignid       uint64         `protobuf:"varint,1,opt,name=campaignid" json:"campaignid,omitempty"`
+	Name             string         `protobuf:"bytes,2,opt,name=name" json:"name,omitempty"`
+	Firmwareid       uint64         `protobuf:"varint,3,opt,name=firmwareid" json:"firmwareid,omitempty"`
+	Devicetype       string         `protobuf:"bytes,4,opt,name=devicetype" json:"devicetype,omitempty"`
+	Version          string         `protobuf:"bytes,5,opt,name=version" json:"version,omitempty"`
+	Selector         string         `protobuf:"bytes,6,opt,name=selector" json:"selector,omitempty"`
+	Stages           []int32        `protobuf:"varint,7,rep,name=stages,packed" json:"stages,omitempty"`
+	Stage            int32          `protobuf:"varint,8,opt,name=stage" json:"stage,omitempty"`
+	Failurethreshold float64        `protobuf:"fixed64,9,opt,name=failurethreshold" json:"failurethreshold,omitempty"`
+	Minreports       int32          `protobuf:"varint,10,opt,name=minreports" json:"minreports,omitempty"`
+	Status           string         `protobuf:"bytes,11,opt,name=status" json:"status,omitempty"`
+	Createdat        int64          `protobuf:"varint,12,opt,name=createdat" json:"createdat,omitempty"`
+	Stats            *CampaignStats `protobuf:"bytes,13,opt,name=stats" json:"stats,omitempty"`
+}
+
+func (m *Campaign) Reset()                    { *m = Campaign{} }
+func (m *Campaign) String() string            { return proto.CompactTextString(m) }
+func (*Campaign) ProtoMessage()               {}
+func (*Campaign) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{118} }
+
+func (m *Campaign) GetCampaignid() uint64 {
+	if m != nil {
+		return m.Campaignid
+	}
+	return 0
+}
+
+func (m *Campaign) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Campaign) GetFirmwareid() uint64 {
+	if m != nil {
+		return m.Firmwareid
+	}
+	return 0
+}
+
+func (m *Campaign) GetDevicetype() string {
+	if m != nil {
+		return m.Devicetype
+	}
+	return ""
+}
+
+func (m *Campaign) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+func (m *Campaign) GetSelector() string {
+	if m != nil {
+		return m.Selector
+	}
+	return ""
+}
+
+func (m *Campaign) GetStages() []int32 {
+	if m != nil {
+		return m.Stages
+	}
+	return nil
+}
+
+func (m *Campaign) GetStage() int32 {
+	if m != nil {
+		return m.Stage
+	}
+	return 0
+}
+
+func (m *Campaign) GetFailurethreshold() float64 {
+	if m != nil {
+		return m.Failurethreshold
+	}
+	return 0
+}
+
+func (m *Campaign) GetMinreports() int32 {
+	if m != nil {
+		return m.Minreports
+	}
+	return 0
+}
+
+func (m *Campaign) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *Campaign) GetCreatedat() int64 {
+	if m != nil {
+		return m.Createdat
+	}
+	return 0
+}
+
+func (m *Campaign) GetStats() *CampaignStats {
+	if m != nil {
+		return m.Stats
+	}
+	return nil
+}
+
+type UploadFirmwareRequest struct {
+	Devicetype string `protobuf:"bytes,1,opt,name=devicetype" json:"devicetype,omitempty"`
+	Version    string `protobuf:"bytes,2,opt,name=version" json:"version,omitempty"`
+	Data       []byte `protobuf:"bytes,3,opt,name=data" json:"data,omitempty"`
+}
+
+func (m *UploadFirmwareRequest) Reset()                    { *m = UploadFirmwareRequest{} }
+func (m *UploadFirmwareRequest) String() string            { return proto.CompactTextString(m) }
+func (*UploadFirmwareRequest) ProtoMessage()               {}
+func (*UploadFirmwareRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{119} }
+
+func (m *UploadFirmwareRequest) GetDevicetype() string {
+	if m != nil {
+		return m.Devicetype
+	}
+	return ""
+}
+
+func (m *UploadFirmwareRequest) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+func (m *UploadFirmwareRequest) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+type UploadFirmwareReply struct {
+	Artifact *FirmwareArtifact `protobuf:"bytes,1,opt,name=artifact" json:"artifact,omitempty"`
+	Err      string            `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *UploadFirmwareReply) Reset()                    { *m = UploadFirmwareReply{} }
+func (m *UploadFirmwareReply) String() string            { return proto.CompactTextString(m) }
+func (*UploadFirmwareReply) ProtoMessage()               {}
+func (*UploadFirmwareReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{120} }
+
+func (m *UploadFirmwareReply) GetArtifact() *FirmwareArtifact {
+	if m != nil {
+		return m.Artifact
+	}
+	return nil
+}
+
+func (m *UploadFirmwareReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type ListFirmwareRequest struct {
+}
+
+func (m *ListFirmwareRequest) Reset()                    { *m = ListFirmwareRequest{} }
+func (m *ListFirmwareRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListFirmwareRequest) ProtoMessage()               {}
+func (*ListFirmwareRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{121} }
+
+type ListFirmwareReply struct {
+	Artifacts []*FirmwareArtifact `protobuf:"bytes,1,rep,name=artifacts" json:"artifacts,omitempty"`
+	Err       string              `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *ListFirmwareReply) Reset()                    { *m = ListFirmwareReply{} }
+func (m *ListFirmwareReply) String() string            { return proto.CompactTextString(m) }
+func (*ListFirmwareReply) ProtoMessage()               {}
+func (*ListFirmwareReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{122} }
+
+func (m *ListFirmwareReply) GetArtifacts() []*FirmwareArtifact {
+	if m != nil {
+		return m.Artifacts
+	}
+	return nil
+}
+
+func (m *ListFirmwareReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type DownloadFirmwareRequest struct {
+	Firmwareid uint64 `protobuf:"varint,1,opt,name=firmwareid" json:"firmwareid,omitempty"`
+}
+
+func (m *DownloadFirmwareRequest) Reset()                    { *m = DownloadFirmwareRequest{} }
+func (m *DownloadFirmwareRequest) String() string            { return proto.CompactTextString(m) }
+func (*DownloadFirmwareRequest) ProtoMessage()               {}
+func (*DownloadFirmwareRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{123} }
+
+func (m *DownloadFirmwareRequest) GetFirmwareid() uint64 {
+	if m != nil {
+		return m.Firmwareid
+	}
+	return 0
+}
+
+type DownloadFirmwareReply struct {
+	Artifact *FirmwareArtifact `protobuf:"bytes,1,opt,name=artifact" json:"artifact,omitempty"`
+	Data     []byte            `protobuf:"bytes,2,opt,name=data" json:"data,omitempty"`
+	Err      string            `protobuf:"bytes,3,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *DownloadFirmwareReply) Reset()                    { *m = DownloadFirmwareReply{} }
+func (m *DownloadFirmwareReply) String() string            { return proto.CompactTextString(m) }
+func (*DownloadFirmwareReply) ProtoMessage()               {}
+func (*DownloadFirmwareReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{124} }
+
+func (m *DownloadFirmwareReply) GetArtifact() *FirmwareArtifact {
+	if m != nil {
+		return m.Artifact
+	}
+	return nil
+}
+
+func (m *DownloadFirmwareReply) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (m *DownloadFirmwareReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type CreateCampaignRequest struct {
+	Campaign *Campaign `protobuf:"bytes,1,opt,name=campaign" json:"campaign,omitempty"`
+}
+
+func (m *CreateCampaignRequest) Reset()                    { *m = CreateCampaignRequest{} }
+func (m *CreateCampaignRequest) String() string            { return proto.CompactTextString(m) }
+func (*CreateCampaignRequest) ProtoMessage()               {}
+func (*CreateCampaignRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{125} }
+
+func (m *CreateCampaignRequest) GetCampaign() *Campaign {
+	if m != nil {
+		return m.Campaign
+	}
+	return nil
+}
+
+type CreateCampaignReply struct {
+	Campaignid uint64 `protobuf:"varint,1,opt,name=campaignid" json:"campaignid,omitempty"`
+	Err        string `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *CreateCampaignReply) Reset()                    { *m = CreateCampaignReply{} }
+func (m *CreateCampaignReply) String() string            { return proto.CompactTextString(m) }
+func (*CreateCampaignReply) ProtoMessage()               {}
+func (*CreateCampaignReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{126} }
+
+func (m *CreateCampaignReply) GetCampaignid() uint64 {
+	if m != nil {
+		return m.Campaignid
+	}
+	return 0
+}
+
+func (m *CreateCampaignReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type GetCampaignRequest struct {
+	Campaignid uint64 `protobuf:"varint,1,opt,name=campaignid" json:"campaignid,omitempty"`
+}
+
+func (m *GetCampaignRequest) Reset()                    { *m = GetCampaignRequest{} }
+func (m *GetCampaignRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetCampaignRequest) ProtoMessage()               {}
+func (*GetCampaignRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{127} }
+
+func (m *GetCampaignRequest) GetCampaignid() uint64 {
+	if m != nil {
+		return m.Campaignid
+	}
+	return 0
+}
+
+type GetCampaignReply struct {
+	Campaign *Campaign `protobuf:"bytes,1,opt,name=campaign" json:"campaign,omitempty"`
+	Err      string    `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *GetCampaignReply) Reset()                    { *m = GetCampaignReply{} }
+func (m *GetCampaignReply) String() string            { return proto.CompactTextString(m) }
+func (*GetCampaignReply) ProtoMessage()               {}
+func (*GetCampaignReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{128} }
+
+func (m *GetCampaignReply) GetCampaign() *Campaign {
+	if m != nil {
+		return m.Campaign
+	}
+	return nil
+}
+
+func (m *GetCampaignReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type ListCampaignsRequest struct {
+}
+
+func (m *ListCampaignsRequest) Reset()                    { *m = ListCampaignsRequest{} }
+func (m *ListCampaignsRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListCampaignsRequest) ProtoMessage()               {}
+func (*ListCampaignsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{129} }
+
+type ListCampaignsReply struct {
+	Campaigns []*Campaign `protobuf:"bytes,1,rep,name=campaigns" json:"campaigns,omitempty"`
+	Err       string      `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *ListCampaignsReply) Reset()                    { *m = ListCampaignsReply{} }
+func (m *ListCampaignsReply) String() string            { return proto.CompactTextString(m) }
+func (*ListCampaignsReply) ProtoMessage()               {}
+func (*ListCampaignsReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{130} }
+
+func (m *ListCampaignsReply) GetCampaigns() []*Campaign {
+	if m != nil {
+		return m.Campaigns
+	}
+	return nil
+}
+
+func (m *ListCampaignsReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type ControlCampaignRequest struct {
+	Campaignid uint64 `protobuf:"varint,1,opt,name=campaignid" json:"campaignid,omitempty"`
+	Action     string `protobuf:"bytes,2,opt,name=action" json:"action,omitempty"`
+}
+
+func (m *ControlCampaignRequest) Reset()                    { *m = ControlCampaignRequest{} }
+func (m *ControlCampaignRequest) String() string            { return proto.CompactTextString(m) }
+func (*ControlCampaignRequest) ProtoMessage()               {}
+func (*ControlCampaignRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{131} }
+
+func (m *ControlCampaignRequest) GetCampaignid() uint64 {
+	if m != nil {
+		return m.Campaignid
+	}
+	return 0
+}
+
+func (m *ControlCampaignRequest) GetAction() string {
+	if m != nil {
+		return m.Action
+	}
+	return ""
+}
+
+type ControlCampaignReply struct {
+	Campaign *Campaign `protobuf:"bytes,1,opt,name=campaign" json:"campaign,omitempty"`
+	Err      string    `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *ControlCampaignReply) Reset()                    { *m = ControlCampaignReply{} }
+func (m *ControlCampaignReply) String() string            { return proto.CompactTextString(m) }
+func (*ControlCampaignReply) ProtoMessage()               {}
+func (*ControlCampaignReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{132} }
+
+func (m *ControlCampaignReply) GetCampaign() *Campaign {
+	if m != nil {
+		return m.Campaign
+	}
+	return nil
+}
+
+func (m *ControlCampaignReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type ReportFirmwareRequest struct {
+	Deviceid uint64          `protobuf:"varint,1,opt,name=deviceid" json:"deviceid,omitempty"`
+	Report   *FirmwareReport `protobuf:"bytes,2,opt,name=report" json:"report,omitempty"`
+}
+
+func (m *ReportFirmwareRequest) Reset()                    { *m = ReportFirmwareRequest{} }
+func (m *ReportFirmwareRequest) String() string            { return proto.CompactTextString(m) }
+func (*ReportFirmwareRequest) ProtoMessage()               {}
+func (*ReportFirmwareRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{133} }
+
+func (m *ReportFirmwareRequest) GetDeviceid() uint64 {
+	if m != nil {
+		return m.Deviceid
+	}
+	return 0
+}
+
+func (m *ReportFirmwareRequest) GetReport() *FirmwareReport {
+	if m != nil {
+		return m.Report
+	}
+	return nil
+}
+
+type ReportFirmwareReply struct {
+	Acknowledged bool   `protobuf:"varint,1,opt,name=acknowledged" json:"acknowledged,omitempty"`
+	Err          string `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *ReportFirmwareReply) Reset()                    { *m = ReportFirmwareReply{} }
+func (m *ReportFirmwareReply) String() string            { return proto.CompactTextString(m) }
+func (*ReportFirmwareReply) ProtoMessage()               {}
+func (*ReportFirmwareReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{134} }
+
+func (m *ReportFirmwareReply) GetAcknowledged() bool {
+	if m != nil {
+		return m.Acknowledged
+	}
+	return false
+}
+
+func (m *ReportFirmwareReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type SchemaViolation struct {
+	Metric string          `protobuf:"bytes,1,opt,name=metric" json:"metric,omitempty"`
+	Reason string          `protobuf:"bytes,2,opt,name=reason" json:"reason,omitempty"`
+	Value  *TelemetryValue `protobuf:"bytes,3,opt,name=value" json:"value,omitempty"`
+	Action string          `protobuf:"bytes,4,opt,name=action" json:"action,omitempty"`
+}
+
+func (m *SchemaViolation) Reset()                    { *m = SchemaViolation{} }
+func (m *SchemaViolation) String() string            { return proto.CompactTextString(m) }
+func (*SchemaViolation) ProtoMessage()               {}
+func (*SchemaViolation) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{135} }
+
+func (m *SchemaViolation) GetMetric() string {
+	if m != nil {
+		return m.Metric
+	}
+	return ""
+}
+
+func (m *SchemaViolation) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+func (m *SchemaViolation) GetValue() *TelemetryValue {
+	if m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (m *SchemaViolation) GetAction() string {
+	if m != nil {
+		return m.Action
+	}
+	return ""
+}
+
+type MetricSpec struct {
+	Unit   string  `protobuf:"bytes,1,opt,name=unit" json:"unit,omitempty"`
+	Hasmin bool    `protobuf:"varint,2,opt,name=hasmin" json:"hasmin,omitempty"`
+	Min    float64 `protobuf:"fixed64,3,opt,name=min" json:"min,omitempty"`
+	Hasmax bool    `protobuf:"varint,4,opt,name=hasmax" json:"hasmax,omitempty"`
+	Max    float64 `protobuf:"fixed64,5,opt,name=max" json:"max,omitempty"`
+}
+
+func (m *MetricSpec) Reset()                    { *m = MetricSpec{} }
+func (m *MetricSpec) String() string            { return proto.CompactTextString(m) }
+func (*MetricSpec) ProtoMessage()               {}
+func (*MetricSpec) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{136} }
+
+func (m *MetricSpec) GetUnit() string {
+	if m != nil {
+		return m.Unit
+	}
+	return ""
+}
+
+func (m *MetricSpec) GetHasmin() bool {
+	if m != nil {
+		return m.Hasmin
+	}
+	return false
+}
+
+func (m *MetricSpec) GetMin() float64 {
+	if m != nil {
+		return m.Min
+	}
+	return 0
+}
+
+func (m *MetricSpec) GetHasmax() bool {
+	if m != nil {
+		return m.Hasmax
+	}
+	return false
+}
+
+func (m *MetricSpec) GetMax() float64 {
+	if m != nil {
+		return m.Max
+	}
+	return 0
+}
+
+type TelemetrySchema struct {
+	Devicetype string                 `protobuf:"bytes,1,opt,name=devicetype" json:"devicetype,omitempty"`
+	Mode       string                 `protobuf:"bytes,2,opt,name=mode" json:"mode,omitempty"`
+	Metrics    map[string]*MetricSpec `protobuf:"bytes,3,rep,name=metrics" json:"metrics,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+}
+
+func (m *TelemetrySchema) Reset()                    { *m = TelemetrySchema{} }
+func (m *TelemetrySchema) String() string            { return proto.CompactTextString(m) }
+func (*TelemetrySchema) ProtoMessage()               {}
+func (*TelemetrySchema) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{137} }
+
+func (m *TelemetrySchema) GetDevicetype() string {
+	if m != nil {
+		return m.Devicetype
+	}
+	return ""
+}
+
+func (m *TelemetrySchema) GetMode() string {
+	if m != nil {
+		return m.Mode
+	}
+	return ""
+}
+
+func (m *TelemetrySchema) GetMetrics() map[string]*MetricSpec {
+	if m != nil {
+		return m.Metrics
+	}
+	return nil
+}
+
+type SetTelemetrySchemaRequest struct {
+	Schema *TelemetrySchema `protobuf:"bytes,1,opt,name=schema" json:"schema,omitempty"`
+}
+
+func (m *SetTelemetrySchemaRequest) Reset()                    { *m = SetTelemetrySchemaRequest{} }
+func (m *SetTelemetrySchemaRequest) String() string            { return proto.CompactTextString(m) }
+func (*SetTelemetrySchemaRequest) ProtoMessage()               {}
+func (*SetTelemetrySchemaRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{138} }
+
+func (m *SetTelemetrySchemaRequest) GetSchema() *TelemetrySchema {
+	if m != nil {
+		return m.Schema
+	}
+	return nil
+}
+
+type SetTelemetrySchemaReply struct {
+	Acknowledged bool   `protobuf:"varint,1,opt,name=acknowledged" json:"acknowledged,omitempty"`
+	Err          string `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *SetTelemetrySchemaReply) Reset()                    { *m = SetTelemetrySchemaReply{} }
+func (m *SetTelemetrySchemaReply) String() string            { return proto.CompactTextString(m) }
+func (*SetTelemetrySchemaReply) ProtoMessage()               {}
+func (*SetTelemetrySchemaReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{139} }
+
+func (m *SetTelemetrySchemaReply) GetAcknowledged() bool {
+	if m != nil {
+		return m.Acknowledged
+	}
+	return false
+}
+
+func (m *SetTelemetrySchemaReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type GetTelemetrySchemaRequest struct {
+	Devicetype string `protobuf:"bytes,1,opt,name=devicetype" json:"devicetype,omitempty"`
+}
+
+func (m *GetTelemetrySchemaRequest) Reset()                    { *m = GetTelemetrySchemaRequest{} }
+func (m *GetTelemetrySchemaRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetTelemetrySchemaRequest) ProtoMessage()               {}
+func (*GetTelemetrySchemaRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{140} }
+
+func (m *GetTelemetrySchemaRequest) GetDevicetype() string {
+	if m != nil {
+		return m.Devicetype
+	}
+	return ""
+}
+
+type GetTelemetrySchemaReply struct {
+	Schema *TelemetrySchema `protobuf:"bytes,1,opt,name=schema" json:"schema,omitempty"`
+	Err    string           `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *GetTelemetrySchemaReply) Reset()                    { *m = GetTelemetrySchemaReply{} }
+func (m *GetTelemetrySchemaReply) String() string            { return proto.CompactTextString(m) }
+func (*GetTelemetrySchemaReply) ProtoMessage()               {}
+func (*GetTelemetrySchemaReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{141} }
+
+func (m *GetTelemetrySchemaReply) GetSchema() *TelemetrySchema {
+	if m != nil {
+		return m.Schema
+	}
+	return nil
+}
+
+func (m *GetTelemetrySchemaReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type ListTelemetrySchemasRequest struct {
+}
+
+func (m *ListTelemetrySchemasRequest) Reset()                    { *m = ListTelemetrySchemasRequest{} }
+func (m *ListTelemetrySchemasRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListTelemetrySchemasRequest) ProtoMessage()               {}
+func (*ListTelemetrySchemasRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{142} }
+
+type ListTelemetrySchemasReply struct {
+	Schemas []*TelemetrySchema `protobuf:"bytes,1,rep,name=schemas" json:"schemas,omitempty"`
+	Err     string             `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *ListTelemetrySchemasReply) Reset()                    { *m = ListTelemetrySchemasReply{} }
+func (m *ListTelemetrySchemasReply) String() string            { return proto.CompactTextString(m) }
+func (*ListTelemetrySchemasReply) ProtoMessage()               {}
+func (*ListTelemetrySchemasReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{143} }
+
+func (m *ListTelemetrySchemasReply) GetSchemas() []*TelemetrySchema {
+	if m != nil {
+		return m.Schemas
+	}
+	return nil
+}
+
+func (m *ListTelemetrySchemasReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type DeleteTelemetrySchemaRequest struct {
+	Devicetype string `protobuf:"bytes,1,opt,name=devicetype" json:"devicetype,omitempty"`
+}
+
+func (m *DeleteTelemetrySchemaRequest) Reset()                    { *m = DeleteTelemetrySchemaRequest{} }
+func (m *DeleteTelemetrySchemaRequest) String() string            { return proto.CompactTextString(m) }
+func (*DeleteTelemetrySchemaRequest) ProtoMessage()               {}
+func (*DeleteTelemetrySchemaRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{144} }
+
+func (m *DeleteTelemetrySchemaRequest) GetDevicetype() string {
+	if m != nil {
+		return m.Devicetype
+	}
+	return ""
+}
+
+type DeleteTelemetrySchemaReply struct {
+	Acknowledged bool   `protobuf:"varint,1,opt,name=acknowledged" json:"acknowledged,omitempty"`
+	Err          string `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *DeleteTelemetrySchemaReply) Reset()                    { *m = DeleteTelemetrySchemaReply{} }
+func (m *DeleteTelemetrySchemaReply) String() string            { return proto.CompactTextString(m) }
+func (*DeleteTelemetrySchemaReply) ProtoMessage()               {}
+func (*DeleteTelemetrySchemaReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{145} }
+
+func (m *DeleteTelemetrySchemaReply) GetAcknowledged() bool {
+	if m != nil {
+		return m.Acknowledged
+	}
+	return false
+}
+
+func (m *DeleteTelemetrySchemaReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type DeviceImport struct {
+	Name       string   `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Owner      string   `protobuf:"bytes,2,opt,name=owner" json:"owner,omitempty"`
+	Devicetype string   `protobuf:"bytes,3,opt,name=devicetype" json:"devicetype,omitempty"`
+	Gatewayid  uint64   `protobuf:"varint,4,opt,name=gatewayid" json:"gatewayid,omitempty"`
+	Labels     []*Label `protobuf:"bytes,5,rep,name=labels" json:"labels,omitempty"`
+}
+
+func (m *DeviceImport) Reset()                    { *m = DeviceImport{} }
+func (m *DeviceImport) String() string            { return proto.CompactTextString(m) }
+func (*DeviceImport) ProtoMessage()               {}
+func (*DeviceImport) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{146} }
+
+func (m *DeviceImport) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *DeviceImport) GetOwner() string {
+	if m != nil {
+		return m.Owner
+	}
+	return ""
+}
+
+func (m *DeviceImport) GetDevicetype() string {
+	if m != nil {
+		return m.Devicetype
+	}
+	return ""
+}
+
+func (m *DeviceImport) GetGatewayid() uint64 {
+	if m != nil {
+		return m.Gatewayid
+	}
+	return 0
+}
+
+func (m *DeviceImport) GetLabels() []*Label {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+type ImportResult struct {
+	Row      int32  `protobuf:"varint,1,opt,name=row" json:"row,omitempty"`
+	Deviceid uint64 `protobuf:"varint,2,opt,name=deviceid" json:"deviceid,omitempty"`
+	Err      string `protobuf:"bytes,3,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *ImportResult) Reset()                    { *m = ImportResult{} }
+func (m *ImportResult) String() string            { return proto.CompactTextString(m) }
+func (*ImportResult) ProtoMessage()               {}
+func (*ImportResult) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{147} }
+
+func (m *ImportResult) GetRow() int32 {
+	if m != nil {
+		return m.Row
+	}
+	return 0
+}
+
+func (m *ImportResult) GetDeviceid() uint64 {
+	if m != nil {
+		return m.Deviceid
+	}
+	return 0
+}
+
+func (m *ImportResult) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type ImportDevicesRequest struct {
+	Devices []*DeviceImport `protobuf:"bytes,1,rep,name=devices" json:"devices,omitempty"`
+	Dryrun  bool            `protobuf:"varint,2,opt,name=dryrun" json:"dryrun,omitempty"`
+}
+
+func (m *ImportDevicesRequest) Reset()                    { *m = ImportDevicesRequest{} }
+func (m *ImportDevicesRequest) String() string            { return proto.CompactTextString(m) }
+func (*ImportDevicesRequest) ProtoMessage()               {}
+func (*ImportDevicesRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{148} }
+
+func (m *ImportDevicesRequest) GetDevices() []*DeviceImport {
+	if m != nil {
+		return m.Devices
+	}
+	return nil
+}
+
+func (m *ImportDevicesRequest) GetDryrun() bool {
+	if m != nil {
+		return m.Dryrun
+	}
+	return false
+}
+
+type ImportDevicesReply struct {
+	Results []*ImportResult `protobuf:"bytes,1,rep,name=results" json:"results,omitempty"`
+	Err     string          `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *ImportDevicesReply) Reset()                    { *m = ImportDevicesReply{} }
+func (m *ImportDevicesReply) String() string            { return proto.CompactTextString(m) }
+func (*ImportDevicesReply) ProtoMessage()               {}
+func (*ImportDevicesReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{149} }
+
+func (m *ImportDevicesReply) GetResults() []*ImportResult {
+	if m != nil {
+		return m.Results
+	}
+	return nil
+}
+
+func (m *ImportDevicesReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type DeviceExport struct {
+	Device *Device       `protobuf:"bytes,1,opt,name=device" json:"device,omitempty"`
+	Status *DeviceStatus `protobuf:"bytes,2,opt,name=status" json:"status,omitempty"`
+}
+
+func (m *DeviceExport) Reset()                    { *m = DeviceExport{} }
+func (m *DeviceExport) String() string            { return proto.CompactTextString(m) }
+func (*DeviceExport) ProtoMessage()               {}
+func (*DeviceExport) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{150} }
+
+func (m *DeviceExport) GetDevice() *Device {
+	if m != nil {
+		return m.Device
+	}
+	return nil
+}
+
+func (m *DeviceExport) GetStatus() *DeviceStatus {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+type ExportDevicesRequest struct {
+	Filter *DeviceFilter `protobuf:"bytes,1,opt,name=filter" json:"filter,omitempty"`
+}
+
+func (m *ExportDevicesRequest) Reset()                    { *m = ExportDevicesRequest{} }
+func (m *ExportDevicesRequest) String() string            { return proto.CompactTextString(m) }
+func (*ExportDevicesRequest) ProtoMessage()               {}
+func (*ExportDevicesRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{151} }
+
+func (m *ExportDevicesRequest) GetFilter() *DeviceFilter {
+	if m != nil {
+		return m.Filter
+	}
+	return nil
+}
+
+type ExportDevicesReply struct {
+	Devices []*DeviceExport `protobuf:"bytes,1,rep,name=devices" json:"devices,omitempty"`
+	Err     string          `protobuf:"bytes,2,opt,name=err" json:"err,omitempty"`
+}
+
+func (m *ExportDevicesReply) Reset()                    { *m = ExportDevicesReply{} }
+func (m *ExportDevicesReply) String() string            { return proto.CompactTextString(m) }
+func (*ExportDevicesReply) ProtoMessage()               {}
+func (*ExportDevicesReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{152} }
+
+func (m *ExportDevicesReply) GetDevices() []*DeviceExport {
+	if m != nil {
+		return m.Devices
+	}
+	return nil
+}
+
+func (m *ExportDevicesReply) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type WatchEventsRequest struct {
+	Types  []string      `protobuf:"bytes,1,rep,name=types" json:"types,omitempty"`
+	Filter *DeviceFilter `protobuf:"bytes,2,opt,name=filter" json:"filter,omitempty"`
+}
+
+func (m *WatchEventsRequest) Reset()                    { *m = WatchEventsRequest{} }
+func (m *WatchEventsRequest) String() string            { return proto.CompactTextString(m) }
+func (*WatchEventsRequest) ProtoMessage()               {}
+func (*WatchEventsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{153} }
+
+func (m *WatchEventsRequest) GetTypes() []string {
+	if m != nil {
+		return m.Types
+	}
+	return nil
+}
+
+func (m *WatchEventsRequest) GetFilter() *DeviceFilter {
+	if m != nil {
+		return m.Filter
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*RegisterDeviceRequest)(nil), "pb.RegisterDeviceRequest")
+	proto.RegisterType((*Label)(nil), "pb.Label")
+	proto.RegisterType((*RegisterDeviceReply)(nil), "pb.RegisterDeviceReply")
+	proto.RegisterType((*StatusUpdateRequest)(nil), "pb.StatusUpdateRequest")
+	proto.RegisterType((*StatusUpdateReply)(nil), "pb.StatusUpdateReply")
+	proto.RegisterType((*TelemetrySubmitRequest)(nil), "pb.TelemetrySubmitRequest")
+	proto.RegisterType((*TelemetryValue)(nil), "pb.TelemetryValue")
+	proto.RegisterType((*TelemetrySubmitReply)(nil), "pb.TelemetrySubmitReply")
+	proto.RegisterType((*Location)(nil), "pb.Location")
+	proto.RegisterType((*Rule)(nil), "pb.Rule")
+	proto.RegisterType((*Alert)(nil), "pb.Alert")
+	proto.RegisterType((*CreateRuleRequest)(nil), "pb.CreateRuleRequest")
+	proto.RegisterType((*CreateRuleReply)(nil), "pb.CreateRuleReply")
+	proto.RegisterType((*GetRuleRequest)(nil), "pb.GetRuleRequest")
+	proto.RegisterType((*GetRuleReply)(nil), "pb.GetRuleReply")
+	proto.RegisterType((*UpdateRuleRequest)(nil), "pb.UpdateRuleRequest")
+	proto.RegisterType((*UpdateRuleReply)(nil), "pb.UpdateRuleReply")
+	proto.RegisterType((*DeleteRuleRequest)(nil), "pb.DeleteRuleRequest")
+	proto.RegisterType((*DeleteRuleReply)(nil), "pb.DeleteRuleReply")
+	proto.RegisterType((*ListRulesRequest)(nil), "pb.ListRulesRequest")
+	proto.RegisterType((*ListRulesReply)(nil), "pb.ListRulesReply")
+	proto.RegisterType((*ActiveAlertsRequest)(nil), "pb.ActiveAlertsRequest")
+	proto.RegisterType((*ActiveAlertsReply)(nil), "pb.ActiveAlertsReply")
+	proto.RegisterType((*Event)(nil), "pb.Event")
+	proto.RegisterType((*Webhook)(nil), "pb.Webhook")
+	proto.RegisterType((*DeadLetter)(nil), "pb.DeadLetter")
+	proto.RegisterType((*CreateWebhookRequest)(nil), "pb.CreateWebhookRequest")
+	proto.RegisterType((*CreateWebhookReply)(nil), "pb.CreateWebhookReply")
+	proto.RegisterType((*ListWebhooksRequest)(nil), "pb.ListWebhooksRequest")
+	proto.RegisterType((*ListWebhooksReply)(nil), "pb.ListWebhooksReply")
+	proto.RegisterType((*DeleteWebhookRequest)(nil), "pb.DeleteWebhookRequest")
+	proto.RegisterType((*DeleteWebhookReply)(nil), "pb.DeleteWebhookReply")
+	proto.RegisterType((*DeadLettersRequest)(nil), "pb.DeadLettersRequest")
+	proto.RegisterType((*DeadLettersReply)(nil), "pb.DeadLettersReply")
+	proto.RegisterType((*Device)(nil), "pb.Device")
+	proto.RegisterType((*GetDeviceRequest)(nil), "pb.GetDeviceRequest")
+	proto.RegisterType((*GetDeviceReply)(nil), "pb.GetDeviceReply")
+	proto.RegisterType((*UpdateDeviceRequest)(nil), "pb.UpdateDeviceRequest")
+	proto.RegisterType((*UpdateDeviceReply)(nil), "pb.UpdateDeviceReply")
+	proto.RegisterType((*DeleteDeviceRequest)(nil), "pb.DeleteDeviceRequest")
+	proto.RegisterType((*DeleteDeviceReply)(nil), "pb.DeleteDeviceReply")
+	proto.RegisterType((*ListDevicesRequest)(nil), "pb.ListDevicesRequest")
+	proto.RegisterType((*ListDevicesReply)(nil), "pb.ListDevicesReply")
+	proto.RegisterType((*Geofence)(nil), "pb.Geofence")
+	proto.RegisterType((*CreateGeofenceRequest)(nil), "pb.CreateGeofenceRequest")
+	proto.RegisterType((*CreateGeofenceReply)(nil), "pb.CreateGeofenceReply")
+	proto.RegisterType((*UpdateGeofenceRequest)(nil), "pb.UpdateGeofenceRequest")
+	proto.RegisterType((*UpdateGeofenceReply)(nil), "pb.UpdateGeofenceReply")
+	proto.RegisterType((*DeleteGeofenceRequest)(nil), "pb.DeleteGeofenceRequest")
+	proto.RegisterType((*DeleteGeofenceReply)(nil), "pb.DeleteGeofenceReply")
+	proto.RegisterType((*ListGeofencesRequest)(nil), "pb.ListGeofencesRequest")
+	proto.RegisterType((*ListGeofencesReply)(nil), "pb.ListGeofencesReply")
+	proto.RegisterType((*DeviceLocation)(nil), "pb.DeviceLocation")
+	proto.RegisterType((*DeviceFilter)(nil), "pb.DeviceFilter")
+	proto.RegisterType((*NearbyDevicesRequest)(nil), "pb.NearbyDevicesRequest")
+	proto.RegisterType((*NearbyDevicesReply)(nil), "pb.NearbyDevicesReply")
+	proto.RegisterType((*DevicesInBoxRequest)(nil), "pb.DevicesInBoxRequest")
+	proto.RegisterType((*DevicesInBoxReply)(nil), "pb.DevicesInBoxReply")
+	proto.RegisterType((*MotionTotals)(nil), "pb.MotionTotals")
+	proto.RegisterType((*DeviceStatus)(nil), "pb.DeviceStatus")
+	proto.RegisterType((*GetStatusRequest)(nil), "pb.GetStatusRequest")
+	proto.RegisterType((*GetStatusReply)(nil), "pb.GetStatusReply")
+	proto.RegisterType((*BatteryEstimate)(nil), "pb.BatteryEstimate")
+	proto.RegisterType((*BatterySample)(nil), "pb.BatterySample")
+	proto.RegisterType((*BatteryHistoryRequest)(nil), "pb.BatteryHistoryRequest")
+	proto.RegisterType((*BatteryHistoryReply)(nil), "pb.BatteryHistoryReply")
+	proto.RegisterType((*TelemetryPoint)(nil), "pb.TelemetryPoint")
+	proto.RegisterType((*TelemetrySeries)(nil), "pb.TelemetrySeries")
+	proto.RegisterType((*QueryTelemetryRequest)(nil), "pb.QueryTelemetryRequest")
+	proto.RegisterType((*QueryTelemetryReply)(nil), "pb.QueryTelemetryReply")
+	proto.RegisterType((*AggregateGroup)(nil), "pb.AggregateGroup")
+	proto.RegisterType((*AggregateTelemetryRequest)(nil), "pb.AggregateTelemetryRequest")
+	proto.RegisterType((*AggregateTelemetryReply)(nil), "pb.AggregateTelemetryReply")
+	proto.RegisterType((*SetLabelsRequest)(nil), "pb.SetLabelsRequest")
+	proto.RegisterType((*SetLabelsReply)(nil), "pb.SetLabelsReply")
+	proto.RegisterType((*ListStatusesRequest)(nil), "pb.ListStatusesRequest")
+	proto.RegisterType((*ListStatusesReply)(nil), "pb.ListStatusesReply")
+	proto.RegisterType((*Group)(nil), "pb.Group")
+	proto.RegisterType((*CreateGroupRequest)(nil), "pb.CreateGroupRequest")
+	proto.RegisterType((*CreateGroupReply)(nil), "pb.CreateGroupReply")
+	proto.RegisterType((*UpdateGroupRequest)(nil), "pb.UpdateGroupRequest")
+	proto.RegisterType((*UpdateGroupReply)(nil), "pb.UpdateGroupReply")
+	proto.RegisterType((*DeleteGroupRequest)(nil), "pb.DeleteGroupRequest")
+	proto.RegisterType((*DeleteGroupReply)(nil), "pb.DeleteGroupReply")
+	proto.RegisterType((*ListGroupsRequest)(nil), "pb.ListGroupsRequest")
+	proto.RegisterType((*ListGroupsReply)(nil), "pb.ListGroupsReply")
+	proto.RegisterType((*AddGroupMembersRequest)(nil), "pb.AddGroupMembersRequest")
+	proto.RegisterType((*AddGroupMembersReply)(nil), "pb.AddGroupMembersReply")
+	proto.RegisterType((*RemoveGroupMembersRequest)(nil), "pb.RemoveGroupMembersRequest")
+	proto.RegisterType((*RemoveGroupMembersReply)(nil), "pb.RemoveGroupMembersReply")
+	proto.RegisterType((*RelayStatusRequest)(nil), "pb.RelayStatusRequest")
+	proto.RegisterType((*RelayStatusReply)(nil), "pb.RelayStatusReply")
+	proto.RegisterType((*RelayTelemetryRequest)(nil), "pb.RelayTelemetryRequest")
+	proto.RegisterType((*RelayTelemetryReply)(nil), "pb.RelayTelemetryReply")
+	proto.RegisterType((*TopologyNode)(nil), "pb.TopologyNode")
+	proto.RegisterType((*GetTopologyRequest)(nil), "pb.GetTopologyRequest")
+	proto.RegisterType((*GetTopologyReply)(nil), "pb.GetTopologyReply")
+	proto.RegisterType((*Twin)(nil), "pb.Twin")
+	proto.RegisterType((*DesiredState)(nil), "pb.DesiredState")
+	proto.RegisterType((*GetTwinRequest)(nil), "pb.GetTwinRequest")
+	proto.RegisterType((*GetTwinReply)(nil), "pb.GetTwinReply")
+	proto.RegisterType((*UpdateDesiredRequest)(nil), "pb.UpdateDesiredRequest")
+	proto.RegisterType((*UpdateDesiredReply)(nil), "pb.UpdateDesiredReply")
+	proto.RegisterType((*ReportStateRequest)(nil), "pb.ReportStateRequest")
+	proto.RegisterType((*ReportStateReply)(nil), "pb.ReportStateReply")
+	proto.RegisterType((*WatchDesiredRequest)(nil), "pb.WatchDesiredRequest")
+	proto.RegisterType((*Command)(nil), "pb.Command")
+	proto.RegisterType((*EnqueueCommandRequest)(nil), "pb.EnqueueCommandRequest")
+	proto.RegisterType((*EnqueueCommandReply)(nil), "pb.EnqueueCommandReply")
+	proto.RegisterType((*AckCommandRequest)(nil), "pb.AckCommandRequest")
+	proto.RegisterType((*AckCommandReply)(nil), "pb.AckCommandReply")
+	proto.RegisterType((*CommandHistoryRequest)(nil), "pb.CommandHistoryRequest")
+	proto.RegisterType((*CommandHistoryReply)(nil), "pb.CommandHistoryReply")
+	proto.RegisterType((*WatchCommandsRequest)(nil), "pb.WatchCommandsRequest")
+	proto.RegisterType((*FirmwareReport)(nil), "pb.FirmwareReport")
+	proto.RegisterType((*FirmwareOffer)(nil), "pb.FirmwareOffer")
+	proto.RegisterType((*FirmwareArtifact)(nil), "pb.FirmwareArtifact")
+	proto.RegisterType((*CampaignStats)(nil), "pb.CampaignStats")
+	proto.RegisterType((*Campaign)(nil), "pb.Campaign")
+	proto.RegisterType((*UploadFirmwareRequest)(nil), "pb.UploadFirmwareRequest")
+	proto.RegisterType((*UploadFirmwareReply)(nil), "pb.UploadFirmwareReply")
+	proto.RegisterType((*ListFirmwareRequest)(nil), "pb.ListFirmwareRequest")
+	proto.RegisterType((*ListFirmwareReply)(nil), "pb.ListFirmwareReply")
+	proto.RegisterType((*DownloadFirmwareRequest)(nil), "pb.DownloadFirmwareRequest")
+	proto.RegisterType((*DownloadFirmwareReply)(nil), "pb.DownloadFirmwareReply")
+	proto.RegisterType((*CreateCampaignRequest)(nil), "pb.CreateCampaignRequest")
+	proto.RegisterType((*CreateCampaignReply)(nil), "pb.CreateCampaignReply")
+	proto.RegisterType((*GetCampaignRequest)(nil), "pb.GetCampaignRequest")
+	proto.RegisterType((*GetCampaignReply)(nil), "pb.GetCampaignReply")
+	proto.RegisterType((*ListCampaignsRequest)(nil), "pb.ListCampaignsRequest")
+	proto.RegisterType((*ListCampaignsReply)(nil), "pb.ListCampaignsReply")
+	proto.RegisterType((*ControlCampaignRequest)(nil), "pb.ControlCampaignRequest")
+	proto.RegisterType((*ControlCampaignReply)(nil), "pb.ControlCampaignReply")
+	proto.RegisterType((*ReportFirmwareRequest)(nil), "pb.ReportFirmwareRequest")
+	proto.RegisterType((*ReportFirmwareReply)(nil), "pb.ReportFirmwareReply")
+	proto.RegisterType((*SchemaViolation)(nil), "pb.SchemaViolation")
+	proto.RegisterType((*MetricSpec)(nil), "pb.MetricSpec")
+	proto.RegisterType((*TelemetrySchema)(nil), "pb.TelemetrySchema")
+	proto.RegisterType((*SetTelemetrySchemaRequest)(nil), "pb.SetTelemetrySchemaRequest")
+	proto.RegisterType((*SetTelemetrySchemaReply)(nil), "pb.SetTelemetrySchemaReply")
+	proto.RegisterType((*GetTelemetrySchemaRequest)(nil), "pb.GetTelemetrySchemaRequest")
+	proto.RegisterType((*GetTelemetrySchemaReply)(nil), "pb.GetTelemetrySchemaReply")
+	proto.RegisterType((*ListTelemetrySchemasRequest)(nil), "pb.ListTelemetrySchemasRequest")
+	proto.RegisterType((*ListTelemetrySchemasReply)(nil), "pb.ListTelemetrySchemasReply")
+	proto.RegisterType((*DeleteTelemetrySchemaRequest)(nil), "pb.DeleteTelemetrySchemaRequest")
+	proto.RegisterType((*DeleteTelemetrySchemaReply)(nil), "pb.DeleteTelemetrySchemaReply")
+	proto.RegisterType((*DeviceImport)(nil), "pb.DeviceImport")
+	proto.RegisterType((*ImportResult)(nil), "pb.ImportResult")
+	proto.RegisterType((*ImportDevicesRequest)(nil), "pb.ImportDevicesRequest")
+	proto.RegisterType((*ImportDevicesReply)(nil), "pb.ImportDevicesReply")
+	proto.RegisterType((*DeviceExport)(nil), "pb.DeviceExport")
+	proto.RegisterType((*ExportDevicesRequest)(nil), "pb.ExportDevicesRequest")
+	proto.RegisterType((*ExportDevicesReply)(nil), "pb.ExportDevicesReply")
+	proto.RegisterType((*WatchEventsRequest)(nil), "pb.WatchEventsRequest")
+	proto.RegisterEnum("pb.DeviceType", DeviceType_name, DeviceType_value)
+	proto.RegisterEnum("pb.RuleKind", RuleKind_name, RuleKind_value)
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// Client API for Monitor service
+
+type MonitorClient interface {
+	RegisterDevice(ctx context.Context, in *RegisterDeviceRequest, opts ...grpc.CallOption) (*RegisterDeviceReply, error)
+	UpdateDeviceStatus(ctx context.Context, in *StatusUpdateRequest, opts ...grpc.CallOption) (*StatusUpdateReply, error)
+	SubmitTelemetry(ctx context.Context, in *TelemetrySubmitRequest, opts ...grpc.CallOption) (*TelemetrySubmitReply, error)
+	RelayStatus(ctx context.Context, in *RelayStatusRequest, opts ...grpc.CallOption) (*RelayStatusReply, error)
+	RelayTelemetry(ctx context.Context, in *RelayTelemetryRequest, opts ...grpc.CallOption) (*RelayTelemetryReply, error)
+	GetTopology(ctx context.Context, in *GetTopologyRequest, opts ...grpc.CallOption) (*GetTopologyReply, error)
+	GetTwin(ctx context.Context, in *GetTwinRequest, opts ...grpc.CallOption) (*GetTwinReply, error)
+	UpdateDesired(ctx context.Context, in *UpdateDesiredRequest, opts ...grpc.CallOption) (*UpdateDesiredReply, error)
+	ReportState(ctx context.Context, in *ReportStateRequest, opts ...grpc.CallOption) (*ReportStateReply, error)
+	WatchDesired(ctx context.Context, in *WatchDesiredRequest, opts ...grpc.CallOption) (Monitor_WatchDesiredClient, error)
+	EnqueueCommand(ctx context.Context, in *EnqueueCommandRequest, opts ...grpc.CallOption) (*EnqueueCommandReply, error)
+	AckCommand(ctx context.Context, in *AckCommandRequest, opts ...grpc.CallOption) (*AckCommandReply, error)
+	CommandHistory(ctx context.Context, in *CommandHistoryRequest, opts ...grpc.CallOption) (*CommandHistoryReply, error)
+	WatchCommands(ctx context.Context, in *WatchCommandsRequest, opts ...grpc.CallOption) (Monitor_WatchCommandsClient, error)
+	WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (Monitor_WatchEventsClient, error)
+	UploadFirmware(ctx context.Context, in *UploadFirmwareRequest, opts ...grpc.CallOption) (*UploadFirmwareReply, error)
+	ListFirmware(ctx context.Context, in *ListFirmwareRequest, opts ...grpc.CallOption) (*ListFirmwareReply, error)
+	DownloadFirmware(ctx context.Context, in *DownloadFirmwareRequest, opts ...grpc.CallOption) (*DownloadFirmwareReply, error)
+	CreateCampaign(ctx context.Context, in *CreateCampaignRequest, opts ...grpc.CallOption) (*CreateCampaignReply, error)
+	GetCampaign(ctx context.Context, in *GetCampaignRequest, opts ...grpc.CallOption) (*GetCampaignReply, error)
+	ListCampaigns(ctx context.Context, in *ListCampaignsRequest, opts ...grpc.CallOption) (*ListCampaignsReply, error)
+	ControlCampaign(ctx context.Context, in *ControlCampaignRequest, opts ...grpc.CallOption) (*ControlCampaignReply, error)
+	ReportFirmware(ctx context.Context, in *ReportFirmwareRequest, opts ...grpc.CallOption) (*ReportFirmwareReply, error)
+	SetTelemetrySchema(ctx context.Context, in *SetTelemetrySchemaRequest, opts ...grpc.CallOption) (*SetTelemetrySchemaReply, error)
+	GetTelemetrySchema(ctx context.Context, in *GetTelemetrySchemaRequest, opts ...grpc.CallOption) (*GetTelemetrySchemaReply, error)
+	ListTelemetrySchemas(ctx context.Context, in *ListTelemetrySchemasRequest, opts ...grpc.CallOption) (*ListTelemetrySchemasReply, error)
+	DeleteTelemetrySchema(ctx context.Context, in *DeleteTelemetrySchemaRequest, opts ...grpc.CallOption) (*DeleteTelemetrySchemaReply, error)
+	ImportDevices(ctx context.Context, in *ImportDevicesRequest, opts ...grpc.CallOption) (*ImportDevicesReply, error)
+	ExportDevices(ctx context.Context, in *ExportDevicesRequest, opts ...grpc.CallOption) (*ExportDevicesReply, error)
+	GetDevice(ctx context.Context, in *GetDeviceRequest, opts ...grpc.CallOption) (*GetDeviceReply, error)
+	UpdateDevice(ctx context.Context, in *UpdateDeviceRequest, opts ...grpc.CallOption) (*UpdateDeviceReply, error)
+	DeleteDevice(ctx context.Context, in *DeleteDeviceRequest, opts ...grpc.CallOption) (*DeleteDeviceReply, error)
+	ListDevices(ctx context.Context, in *ListDevicesRequest, opts ...grpc.CallOption) (*ListDevicesReply, error)
+	SetLabels(ctx context.Context, in *SetLabelsRequest, opts ...grpc.CallOption) (*SetLabelsReply, error)
+	ListStatuses(ctx context.Context, in *ListStatusesRequest, opts ...grpc.CallOption) (*ListStatusesReply, error)
+	GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*GetStatusReply, error)
+	BatteryHistory(ctx context.Context, in *BatteryHistoryRequest, opts ...grpc.CallOption) (*BatteryHistoryReply, error)
+	QueryTelemetry(ctx context.Context, in *QueryTelemetryRequest, opts ...grpc.CallOption) (*QueryTelemetryReply, error)
+	AggregateTelemetry(ctx context.Context, in *AggregateTelemetryRequest, opts ...grpc.CallOption) (*AggregateTelemetryReply, error)
+	CreateGroup(ctx context.Context, in *CreateGroupRequest, opts ...grpc.CallOption) (*CreateGroupReply, error)
+	UpdateGroup(ctx context.Context, in *UpdateGroupRequest, opts ...grpc.CallOption) (*UpdateGroupReply, error)
+	DeleteGroup(ctx context.Context, in *DeleteGroupRequest, opts ...grpc.CallOption) (*DeleteGroupReply, error)
+	ListGroups(ctx context.Context, in *ListGroupsRequest, opts ...grpc.CallOption) (*ListGroupsReply, error)
+	AddGroupMembers(ctx context.Context, in *AddGroupMembersRequest, opts ...grpc.CallOption) (*AddGroupMembersReply, error)
+	RemoveGroupMembers(ctx context.Context, in *RemoveGroupMembersRequest, opts ...grpc.CallOption) (*RemoveGroupMembersReply, error)
+	CreateRule(ctx context.Context, in *CreateRuleRequest, opts ...grpc.CallOption) (*CreateRuleReply, error)
+	GetRule(ctx context.Context, in *GetRuleRequest, opts ...grpc.CallOption) (*GetRuleReply, error)
+	UpdateRule(ctx context.Context, in *UpdateRuleRequest, opts ...grpc.CallOption) (*UpdateRuleReply, error)
+	DeleteRule(ctx context.Context, in *DeleteRuleRequest, opts ...grpc.CallOption) (*DeleteRuleReply, error)
+	ListRules(ctx context.Context, in *ListRulesRequest, opts ...grpc.CallOption) (*ListRulesReply, error)
+	ActiveAlerts(ctx context.Context, in *ActiveAlertsRequest, opts ...grpc.CallOption) (*ActiveAlertsReply, error)
+	CreateWebhook(ctx context.Context, in *CreateWebhookRequest, opts ...grpc.CallOption) (*CreateWebhookReply, error)
+	ListWebhooks(ctx context.Context, in *ListWebhooksRequest, opts ...grpc.CallOption) (*ListWebhooksReply, error)
+	DeleteWebhook(ctx context.Context, in *DeleteWebhookRequest, opts ...grpc.CallOption) (*DeleteWebhookReply, error)
+	DeadLetters(ctx context.Context, in *DeadLettersRequest, opts ...grpc.CallOption) (*DeadLettersReply, error)
+	CreateGeofence(ctx context.Context, in *CreateGeofenceRequest, opts ...grpc.CallOption) (*CreateGeofenceReply, error)
+	UpdateGeofence(ctx context.Context, in *UpdateGeofenceRequest, opts ...grpc.CallOption) (*UpdateGeofenceReply, error)
+	DeleteGeofence(ctx context.Context, in *DeleteGeofenceRequest, opts ...grpc.CallOption) (*DeleteGeofenceReply, error)
+	ListGeofences(ctx context.Context, in *ListGeofencesRequest, opts ...grpc.CallOption) (*ListGeofencesReply, error)
+	NearbyDevices(ctx context.Context, in *NearbyDevicesRequest, opts ...grpc.CallOption) (*NearbyDevicesReply, error)
+	DevicesInBox(ctx context.Context, in *DevicesInBoxRequest, opts ...grpc.CallOption) (*DevicesInBoxReply, error)
+}
+
+type monitorClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewMonitorClient(cc *grpc.ClientConn) MonitorClient {
+	return &monitorClient{cc}
+}
+
+func (c *monitorClient) RegisterDevice(ctx context.Context, in *RegisterDeviceRequest, opts ...grpc.CallOption) (*RegisterDeviceReply, error) {
+	out := new(RegisterDeviceReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/RegisterDevice", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) UpdateDeviceStatus(ctx context.Context, in *StatusUpdateRequest, opts ...grpc.CallOption) (*StatusUpdateReply, error) {
+	out := new(StatusUpdateReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/UpdateDeviceStatus", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) SubmitTelemetry(ctx context.Context, in *TelemetrySubmitRequest, opts ...grpc.CallOption) (*TelemetrySubmitReply, error) {
+	out := new(TelemetrySubmitReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/SubmitTelemetry", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) RelayStatus(ctx context.Context, in *RelayStatusRequest, opts ...grpc.CallOption) (*RelayStatusReply, error) {
+	out := new(RelayStatusReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/RelayStatus", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) RelayTelemetry(ctx context.Context, in *RelayTelemetryRequest, opts ...grpc.CallOption) (*RelayTelemetryReply, error) {
+	out := new(RelayTelemetryReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/RelayTelemetry", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) GetTopology(ctx context.Context, in *GetTopologyRequest, opts ...grpc.CallOption) (*GetTopologyReply, error) {
+	out := new(GetTopologyReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/GetTopology", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) GetTwin(ctx context.Context, in *GetTwinRequest, opts ...grpc.CallOption) (*GetTwinReply, error) {
+	out := new(GetTwinReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/GetTwin", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) UpdateDesired(ctx context.Context, in *UpdateDesiredRequest, opts ...grpc.CallOption) (*UpdateDesiredReply, error) {
+	out := new(UpdateDesiredReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/UpdateDesired", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) ReportState(ctx context.Context, in *ReportStateRequest, opts ...grpc.CallOption) (*ReportStateReply, error) {
+	out := new(ReportStateReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/ReportState", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) WatchDesired(ctx context.Context, in *WatchDesiredRequest, opts ...grpc.CallOption) (Monitor_WatchDesiredClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Monitor_serviceDesc.Streams[0], c.cc, "/pb.Monitor/WatchDesired", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &monitorWatchDesiredClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Monitor_WatchDesiredClient interface {
+	Recv() (*DesiredState, error)
+	grpc.ClientStream
+}
+
+type monitorWatchDesiredClient struct {
+	grpc.ClientStream
+}
+
+func (x *monitorWatchDesiredClient) Recv() (*DesiredState, error) {
+	m := new(DesiredState)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *monitorClient) EnqueueCommand(ctx context.Context, in *EnqueueCommandRequest, opts ...grpc.CallOption) (*EnqueueCommandReply, error) {
+	out := new(EnqueueCommandReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/EnqueueCommand", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) AckCommand(ctx context.Context, in *AckCommandRequest, opts ...grpc.CallOption) (*AckCommandReply, error) {
+	out := new(AckCommandReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/AckCommand", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) CommandHistory(ctx context.Context, in *CommandHistoryRequest, opts ...grpc.CallOption) (*CommandHistoryReply, error) {
+	out := new(CommandHistoryReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/CommandHistory", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) WatchCommands(ctx context.Context, in *WatchCommandsRequest, opts ...grpc.CallOption) (Monitor_WatchCommandsClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Monitor_serviceDesc.Streams[1], c.cc, "/pb.Monitor/WatchCommands", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &monitorWatchCommandsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Monitor_WatchCommandsClient interface {
+	Recv() (*Command, error)
+	grpc.ClientStream
+}
+
+type monitorWatchCommandsClient struct {
+	grpc.ClientStream
+}
+
+func (x *monitorWatchCommandsClient) Recv() (*Command, error) {
+	m := new(Command)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *monitorClient) WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (Monitor_WatchEventsClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_Monitor_serviceDesc.Streams[2], c.cc, "/pb.Monitor/WatchEvents", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &monitorWatchEventsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Monitor_WatchEventsClient interface {
+	Recv() (*Event, error)
+	grpc.ClientStream
+}
+
+type monitorWatchEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *monitorWatchEventsClient) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *monitorClient) UploadFirmware(ctx context.Context, in *UploadFirmwareRequest, opts ...grpc.CallOption) (*UploadFirmwareReply, error) {
+	out := new(UploadFirmwareReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/UploadFirmware", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) ListFirmware(ctx context.Context, in *ListFirmwareRequest, opts ...grpc.CallOption) (*ListFirmwareReply, error) {
+	out := new(ListFirmwareReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/ListFirmware", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) DownloadFirmware(ctx context.Context, in *DownloadFirmwareRequest, opts ...grpc.CallOption) (*DownloadFirmwareReply, error) {
+	out := new(DownloadFirmwareReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/DownloadFirmware", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) CreateCampaign(ctx context.Context, in *CreateCampaignRequest, opts ...grpc.CallOption) (*CreateCampaignReply, error) {
+	out := new(CreateCampaignReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/CreateCampaign", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) GetCampaign(ctx context.Context, in *GetCampaignRequest, opts ...grpc.CallOption) (*GetCampaignReply, error) {
+	out := new(GetCampaignReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/GetCampaign", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) ListCampaigns(ctx context.Context, in *ListCampaignsRequest, opts ...grpc.CallOption) (*ListCampaignsReply, error) {
+	out := new(ListCampaignsReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/ListCampaigns", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) ControlCampaign(ctx context.Context, in *ControlCampaignRequest, opts ...grpc.CallOption) (*ControlCampaignReply, error) {
+	out := new(ControlCampaignReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/ControlCampaign", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) ReportFirmware(ctx context.Context, in *ReportFirmwareRequest, opts ...grpc.CallOption) (*ReportFirmwareReply, error) {
+	out := new(ReportFirmwareReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/ReportFirmware", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) SetTelemetrySchema(ctx context.Context, in *SetTelemetrySchemaRequest, opts ...grpc.CallOption) (*SetTelemetrySchemaReply, error) {
+	out := new(SetTelemetrySchemaReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/SetTelemetrySchema", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) GetTelemetrySchema(ctx context.Context, in *GetTelemetrySchemaRequest, opts ...grpc.CallOption) (*GetTelemetrySchemaReply, error) {
+	out := new(GetTelemetrySchemaReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/GetTelemetrySchema", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) ListTelemetrySchemas(ctx context.Context, in *ListTelemetrySchemasRequest, opts ...grpc.CallOption) (*ListTelemetrySchemasReply, error) {
+	out := new(ListTelemetrySchemasReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/ListTelemetrySchemas", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) DeleteTelemetrySchema(ctx context.Context, in *DeleteTelemetrySchemaRequest, opts ...grpc.CallOption) (*DeleteTelemetrySchemaReply, error) {
+	out := new(DeleteTelemetrySchemaReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/DeleteTelemetrySchema", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) ImportDevices(ctx context.Context, in *ImportDevicesRequest, opts ...grpc.CallOption) (*ImportDevicesReply, error) {
+	out := new(ImportDevicesReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/ImportDevices", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) ExportDevices(ctx context.Context, in *ExportDevicesRequest, opts ...grpc.CallOption) (*ExportDevicesReply, error) {
+	out := new(ExportDevicesReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/ExportDevices", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) GetDevice(ctx context.Context, in *GetDeviceRequest, opts ...grpc.CallOption) (*GetDeviceReply, error) {
+	out := new(GetDeviceReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/GetDevice", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) UpdateDevice(ctx context.Context, in *UpdateDeviceRequest, opts ...grpc.CallOption) (*UpdateDeviceReply, error) {
+	out := new(UpdateDeviceReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/UpdateDevice", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) DeleteDevice(ctx context.Context, in *DeleteDeviceRequest, opts ...grpc.CallOption) (*DeleteDeviceReply, error) {
+	out := new(DeleteDeviceReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/DeleteDevice", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) ListDevices(ctx context.Context, in *ListDevicesRequest, opts ...grpc.CallOption) (*ListDevicesReply, error) {
+	out := new(ListDevicesReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/ListDevices", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) SetLabels(ctx context.Context, in *SetLabelsRequest, opts ...grpc.CallOption) (*SetLabelsReply, error) {
+	out := new(SetLabelsReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/SetLabels", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) ListStatuses(ctx context.Context, in *ListStatusesRequest, opts ...grpc.CallOption) (*ListStatusesReply, error) {
+	out := new(ListStatusesReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/ListStatuses", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*GetStatusReply, error) {
+	out := new(GetStatusReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/GetStatus", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) BatteryHistory(ctx context.Context, in *BatteryHistoryRequest, opts ...grpc.CallOption) (*BatteryHistoryReply, error) {
+	out := new(BatteryHistoryReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/BatteryHistory", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) QueryTelemetry(ctx context.Context, in *QueryTelemetryRequest, opts ...grpc.CallOption) (*QueryTelemetryReply, error) {
+	out := new(QueryTelemetryReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/QueryTelemetry", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) AggregateTelemetry(ctx context.Context, in *AggregateTelemetryRequest, opts ...grpc.CallOption) (*AggregateTelemetryReply, error) {
+	out := new(AggregateTelemetryReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/AggregateTelemetry", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) CreateGroup(ctx context.Context, in *CreateGroupRequest, opts ...grpc.CallOption) (*CreateGroupReply, error) {
+	out := new(CreateGroupReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/CreateGroup", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) UpdateGroup(ctx context.Context, in *UpdateGroupRequest, opts ...grpc.CallOption) (*UpdateGroupReply, error) {
+	out := new(UpdateGroupReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/UpdateGroup", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) DeleteGroup(ctx context.Context, in *DeleteGroupRequest, opts ...grpc.CallOption) (*DeleteGroupReply, error) {
+	out := new(DeleteGroupReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/DeleteGroup", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) ListGroups(ctx context.Context, in *ListGroupsRequest, opts ...grpc.CallOption) (*ListGroupsReply, error) {
+	out := new(ListGroupsReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/ListGroups", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) AddGroupMembers(ctx context.Context, in *AddGroupMembersRequest, opts ...grpc.CallOption) (*AddGroupMembersReply, error) {
+	out := new(AddGroupMembersReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/AddGroupMembers", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) RemoveGroupMembers(ctx context.Context, in *RemoveGroupMembersRequest, opts ...grpc.CallOption) (*RemoveGroupMembersReply, error) {
+	out := new(RemoveGroupMembersReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/RemoveGroupMembers", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) CreateRule(ctx context.Context, in *CreateRuleRequest, opts ...grpc.CallOption) (*CreateRuleReply, error) {
+	out := new(CreateRuleReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/CreateRule", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) GetRule(ctx context.Context, in *GetRuleRequest, opts ...grpc.CallOption) (*GetRuleReply, error) {
+	out := new(GetRuleReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/GetRule", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) UpdateRule(ctx context.Context, in *UpdateRuleRequest, opts ...grpc.CallOption) (*UpdateRuleReply, error) {
+	out := new(UpdateRuleReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/UpdateRule", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) DeleteRule(ctx context.Context, in *DeleteRuleRequest, opts ...grpc.CallOption) (*DeleteRuleReply, error) {
+	out := new(DeleteRuleReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/DeleteRule", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) ListRules(ctx context.Context, in *ListRulesRequest, opts ...grpc.CallOption) (*ListRulesReply, error) {
+	out := new(ListRulesReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/ListRules", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) ActiveAlerts(ctx context.Context, in *ActiveAlertsRequest, opts ...grpc.CallOption) (*ActiveAlertsReply, error) {
+	out := new(ActiveAlertsReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/ActiveAlerts", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) CreateWebhook(ctx context.Context, in *CreateWebhookRequest, opts ...grpc.CallOption) (*CreateWebhookReply, error) {
+	out := new(CreateWebhookReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/CreateWebhook", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) ListWebhooks(ctx context.Context, in *ListWebhooksRequest, opts ...grpc.CallOption) (*ListWebhooksReply, error) {
+	out := new(ListWebhooksReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/ListWebhooks", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) DeleteWebhook(ctx context.Context, in *DeleteWebhookRequest, opts ...grpc.CallOption) (*DeleteWebhookReply, error) {
+	out := new(DeleteWebhookReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/DeleteWebhook", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) DeadLetters(ctx context.Context, in *DeadLettersRequest, opts ...grpc.CallOption) (*DeadLettersReply, error) {
+	out := new(DeadLettersReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/DeadLetters", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) CreateGeofence(ctx context.Context, in *CreateGeofenceRequest, opts ...grpc.CallOption) (*CreateGeofenceReply, error) {
+	out := new(CreateGeofenceReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/CreateGeofence", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) UpdateGeofence(ctx context.Context, in *UpdateGeofenceRequest, opts ...grpc.CallOption) (*UpdateGeofenceReply, error) {
+	out := new(UpdateGeofenceReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/UpdateGeofence", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) DeleteGeofence(ctx context.Context, in *DeleteGeofenceRequest, opts ...grpc.CallOption) (*DeleteGeofenceReply, error) {
+	out := new(DeleteGeofenceReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/DeleteGeofence", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) ListGeofences(ctx context.Context, in *ListGeofencesRequest, opts ...grpc.CallOption) (*ListGeofencesReply, error) {
+	out := new(ListGeofencesReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/ListGeofences", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) NearbyDevices(ctx context.Context, in *NearbyDevicesRequest, opts ...grpc.CallOption) (*NearbyDevicesReply, error) {
+	out := new(NearbyDevicesReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/NearbyDevices", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *monitorClient) DevicesInBox(ctx context.Context, in *DevicesInBoxRequest, opts ...grpc.CallOption) (*DevicesInBoxReply, error) {
+	out := new(DevicesInBoxReply)
+	err := grpc.Invoke(ctx, "/pb.Monitor/DevicesInBox", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Server API for Monitor service
+
+type MonitorServer interface {
+	RegisterDevice(context.Context, *RegisterDeviceRequest) (*RegisterDeviceReply, error)
+	UpdateDeviceStatus(context.Context, *StatusUpdateRequest) (*StatusUpdateReply, error)
+	SubmitTelemetry(context.Context, *TelemetrySubmitRequest) (*TelemetrySubmitReply, error)
+	RelayStatus(context.Context, *RelayStatusRequest) (*RelayStatusReply, error)
+	RelayTelemetry(context.Context, *RelayTelemetryRequest) (*RelayTelemetryReply, error)
+	GetTopology(context.Context, *GetTopologyRequest) (*GetTopologyReply, error)
+	GetTwin(context.Context, *GetTwinRequest) (*GetTwinReply, error)
+	UpdateDesired(context.Context, *UpdateDesiredRequest) (*UpdateDesiredReply, error)
+	ReportState(context.Context, *ReportStateRequest) (*ReportStateReply, error)
+	WatchDesired(*WatchDesiredRequest, Monitor_WatchDesiredServer) error
+	EnqueueCommand(context.Context, *EnqueueCommandRequest) (*EnqueueCommandReply, error)
+	AckCommand(context.Context, *AckCommandRequest) (*AckCommandReply, error)
+	CommandHistory(context.Context, *CommandHistoryRequest) (*CommandHistoryReply, error)
+	WatchCommands(*WatchCommandsRequest, Monitor_WatchCommandsServer) error
+	WatchEvents(*WatchEventsRequest, Monitor_WatchEventsServer) error
+	UploadFirmware(context.Context, *UploadFirmwareRequest) (*UploadFirmwareReply, error)
+	ListFirmware(context.Context, *ListFirmwareRequest) (*ListFirmwareReply, error)
+	DownloadFirmware(context.Context, *DownloadFirmwareRequest) (*DownloadFirmwareReply, error)
+	CreateCampaign(context.Context, *CreateCampaignRequest) (*CreateCampaignReply, error)
+	GetCampaign(context.Context, *GetCampaignRequest) (*GetCampaignReply, error)
+	ListCampaigns(context.Context, *ListCampaignsRequest) (*ListCampaignsReply, error)
+	ControlCampaign(context.Context, *ControlCampaignRequest) (*ControlCampaignReply, error)
+	ReportFirmware(context.Context, *ReportFirmwareRequest) (*ReportFirmwareReply, error)
+	SetTelemetrySchema(context.Context, *SetTelemetrySchemaRequest) (*SetTelemetrySchemaReply, error)
+	GetTelemetrySchema(context.Context, *GetTelemetrySchemaRequest) (*GetTelemetrySchemaReply, error)
+	ListTelemetrySchemas(context.Context, *ListTelemetrySchemasRequest) (*ListTelemetrySchemasReply, error)
+	DeleteTelemetrySchema(context.Context, *DeleteTelemetrySchemaRequest) (*DeleteTelemetrySchemaReply, error)
+	ImportDevices(context.Context, *ImportDevicesRequest) (*ImportDevicesReply, error)
+	ExportDevices(context.Context, *ExportDevicesRequest) (*ExportDevicesReply, error)
+	GetDevice(context.Context, *GetDeviceRequest) (*GetDeviceReply, error)
+	UpdateDevice(context.Context, *UpdateDeviceRequest) (*UpdateDeviceReply, error)
+	DeleteDevice(context.Context, *DeleteDeviceRequest) (*DeleteDeviceReply, error)
+	ListDevices(context.Context, *ListDevicesRequest) (*ListDevicesReply, error)
+	SetLabels(context.Context, *SetLabelsRequest) (*SetLabelsReply, error)
+	ListStatuses(context.Context, *ListStatusesRequest) (*ListStatusesReply, error)
+	GetStatus(context.Context, *GetStatusRequest) (*GetStatusReply, error)
+	BatteryHistory(context.Context, *BatteryHistoryRequest) (*BatteryHistoryReply, error)
+	QueryTelemetry(context.Context, *QueryTelemetryRequest) (*QueryTelemetryReply, error)
+	AggregateTelemetry(context.Context, *AggregateTelemetryRequest) (*AggregateTelemetryReply, error)
+	CreateGroup(context.Context, *CreateGroupRequest) (*CreateGroupReply, error)
+	UpdateGroup(context.Context, *UpdateGroupRequest) (*UpdateGroupReply, error)
+	DeleteGroup(context.Context, *DeleteGroupRequest) (*DeleteGroupReply, error)
+	ListGroups(context.Context, *ListGroupsRequest) (*ListGroupsReply, error)
+	AddGroupMembers(context.Context, *AddGroupMembersRequest) (*AddGroupMembersReply, error)
+	RemoveGroupMembers(context.Context, *RemoveGroupMembersRequest) (*RemoveGroupMembersReply, error)
+	CreateRule(context.Context, *CreateRuleRequest) (*CreateRuleReply, error)
+	GetRule(context.Context, *GetRuleRequest) (*GetRuleReply, error)
+	UpdateRule(context.Context, *UpdateRuleRequest) (*UpdateRuleReply, error)
+	DeleteRule(context.Context, *DeleteRuleRequest) (*DeleteRuleReply, error)
+	ListRules(context.Context, *ListRulesRequest) (*ListRulesReply, error)
+	ActiveAlerts(context.Context, *ActiveAlertsRequest) (*ActiveAlertsReply, error)
+	CreateWebhook(context.Context, *CreateWebhookRequest) (*CreateWebhookReply, error)
+	ListWebhooks(context.Context, *ListWebhooksRequest) (*ListWebhooksReply, error)
+	DeleteWebhook(context.Context, *DeleteWebhookRequest) (*DeleteWebhookReply, error)
+	DeadLetters(context.Context, *DeadLettersRequest) (*DeadLettersReply, error)
+	CreateGeofence(context.Context, *CreateGeofenceRequest) (*CreateGeofenceReply, error)
+	UpdateGeofence(context.Context, *UpdateGeofenceRequest) (*UpdateGeofenceReply, error)
+	DeleteGeofence(context.Context, *DeleteGeofenceRequest) (*DeleteGeofenceReply, error)
+	ListGeofences(context.Context, *ListGeofencesRequest) (*ListGeofencesReply, error)
+	NearbyDevices(context.Context, *NearbyDevicesRequest) (*NearbyDevicesReply, error)
+	DevicesInBox(context.Context, *DevicesInBoxRequest) (*DevicesInBoxReply, error)
+}
+
+func RegisterMonitorServer(s *grpc.Server, srv MonitorServer) {
+	s.RegisterService(&_Monitor_serviceDesc, srv)
+}
+
+func _Monitor_RegisterDevice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterDeviceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).RegisterDevice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/RegisterDevice",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).RegisterDevice(ctx, req.(*RegisterDeviceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_UpdateDeviceStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatusUpdateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).UpdateDeviceStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/UpdateDeviceStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).UpdateDeviceStatus(ctx, req.(*StatusUpdateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_SubmitTelemetry_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TelemetrySubmitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).SubmitTelemetry(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/SubmitTelemetry",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).SubmitTelemetry(ctx, req.(*TelemetrySubmitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_RelayStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RelayStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).RelayStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/RelayStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).RelayStatus(ctx, req.(*RelayStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_RelayTelemetry_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RelayTelemetryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).RelayTelemetry(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/RelayTelemetry",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).RelayTelemetry(ctx, req.(*RelayTelemetryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_GetTopology_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTopologyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).GetTopology(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/GetTopology",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).GetTopology(ctx, req.(*GetTopologyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_GetTwin_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTwinRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).GetTwin(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/GetTwin",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).GetTwin(ctx, req.(*GetTwinRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_UpdateDesired_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateDesiredRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).UpdateDesired(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/UpdateDesired",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).UpdateDesired(ctx, req.(*UpdateDesiredRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_ReportState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReportStateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).ReportState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/ReportState",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).ReportState(ctx, req.(*ReportStateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_WatchDesired_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchDesiredRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MonitorServer).WatchDesired(m, &monitorWatchDesiredServer{stream})
+}
+
+type Monitor_WatchDesiredServer interface {
+	Send(*DesiredState) error
+	grpc.ServerStream
+}
+
+type monitorWatchDesiredServer struct {
+	grpc.ServerStream
+}
+
+func (x *monitorWatchDesiredServer) Send(m *DesiredState) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Monitor_EnqueueCommand_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EnqueueCommandRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).EnqueueCommand(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/EnqueueCommand",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).EnqueueCommand(ctx, req.(*EnqueueCommandRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_AckCommand_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AckCommandRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).AckCommand(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/AckCommand",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).AckCommand(ctx, req.(*AckCommandRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_CommandHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CommandHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).CommandHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/CommandHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).CommandHistory(ctx, req.(*CommandHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_WatchCommands_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchCommandsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MonitorServer).WatchCommands(m, &monitorWatchCommandsServer{stream})
+}
+
+type Monitor_WatchCommandsServer interface {
+	Send(*Command) error
+	grpc.ServerStream
+}
+
+type monitorWatchCommandsServer struct {
+	grpc.ServerStream
+}
+
+func (x *monitorWatchCommandsServer) Send(m *Command) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Monitor_WatchEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MonitorServer).WatchEvents(m, &monitorWatchEventsServer{stream})
+}
+
+type Monitor_WatchEventsServer interface {
+	Send(*Event) error
+	grpc.ServerStream
+}
+
+type monitorWatchEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *monitorWatchEventsServer) Send(m *Event) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Monitor_UploadFirmware_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UploadFirmwareRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).UploadFirmware(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/UploadFirmware",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).UploadFirmware(ctx, req.(*UploadFirmwareRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_ListFirmware_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFirmwareRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).ListFirmware(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/ListFirmware",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).ListFirmware(ctx, req.(*ListFirmwareRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_DownloadFirmware_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DownloadFirmwareRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).DownloadFirmware(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/DownloadFirmware",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).DownloadFirmware(ctx, req.(*DownloadFirmwareRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_CreateCampaign_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateCampaignRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).CreateCampaign(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/CreateCampaign",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).CreateCampaign(ctx, req.(*CreateCampaignRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_GetCampaign_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCampaignRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).GetCampaign(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/GetCampaign",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).GetCampaign(ctx, req.(*GetCampaignRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_ListCampaigns_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCampaignsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).ListCampaigns(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/ListCampaigns",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).ListCampaigns(ctx, req.(*ListCampaignsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_ControlCampaign_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ControlCampaignRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).ControlCampaign(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/ControlCampaign",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).ControlCampaign(ctx, req.(*ControlCampaignRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_ReportFirmware_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReportFirmwareRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).ReportFirmware(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/ReportFirmware",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).ReportFirmware(ctx, req.(*ReportFirmwareRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_SetTelemetrySchema_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetTelemetrySchemaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).SetTelemetrySchema(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/SetTelemetrySchema",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).SetTelemetrySchema(ctx, req.(*SetTelemetrySchemaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_GetTelemetrySchema_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTelemetrySchemaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).GetTelemetrySchema(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/GetTelemetrySchema",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).GetTelemetrySchema(ctx, req.(*GetTelemetrySchemaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_ListTelemetrySchemas_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTelemetrySchemasRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).ListTelemetrySchemas(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/ListTelemetrySchemas",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).ListTelemetrySchemas(ctx, req.(*ListTelemetrySchemasRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_DeleteTelemetrySchema_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteTelemetrySchemaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).DeleteTelemetrySchema(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/DeleteTelemetrySchema",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).DeleteTelemetrySchema(ctx, req.(*DeleteTelemetrySchemaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_ImportDevices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImportDevicesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).ImportDevices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/ImportDevices",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).ImportDevices(ctx, req.(*ImportDevicesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_ExportDevices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportDevicesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).ExportDevices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/ExportDevices",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).ExportDevices(ctx, req.(*ExportDevicesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_GetDevice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDeviceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).GetDevice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/GetDevice",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).GetDevice(ctx, req.(*GetDeviceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_UpdateDevice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateDeviceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).UpdateDevice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/UpdateDevice",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).UpdateDevice(ctx, req.(*UpdateDeviceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_DeleteDevice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteDeviceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).DeleteDevice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/DeleteDevice",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).DeleteDevice(ctx, req.(*DeleteDeviceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_ListDevices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDevicesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).ListDevices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/ListDevices",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).ListDevices(ctx, req.(*ListDevicesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_SetLabels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetLabelsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).SetLabels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/SetLabels",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).SetLabels(ctx, req.(*SetLabelsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_ListStatuses_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListStatusesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).ListStatuses(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/ListStatuses",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).ListStatuses(ctx, req.(*ListStatusesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).GetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/GetStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).GetStatus(ctx, req.(*GetStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_BatteryHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatteryHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).BatteryHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/BatteryHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).BatteryHistory(ctx, req.(*BatteryHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_QueryTelemetry_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryTelemetryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).QueryTelemetry(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/QueryTelemetry",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).QueryTelemetry(ctx, req.(*QueryTelemetryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_AggregateTelemetry_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AggregateTelemetryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).AggregateTelemetry(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/AggregateTelemetry",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).AggregateTelemetry(ctx, req.(*AggregateTelemetryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_CreateGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateGroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).CreateGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/CreateGroup",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).CreateGroup(ctx, req.(*CreateGroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_UpdateGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateGroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).UpdateGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/UpdateGroup",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).UpdateGroup(ctx, req.(*UpdateGroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_DeleteGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteGroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).DeleteGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/DeleteGroup",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).DeleteGroup(ctx, req.(*DeleteGroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_ListGroups_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListGroupsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).ListGroups(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/ListGroups",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).ListGroups(ctx, req.(*ListGroupsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_AddGroupMembers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddGroupMembersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).AddGroupMembers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/AddGroupMembers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).AddGroupMembers(ctx, req.(*AddGroupMembersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_RemoveGroupMembers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveGroupMembersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).RemoveGroupMembers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/RemoveGroupMembers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).RemoveGroupMembers(ctx, req.(*RemoveGroupMembersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_CreateRule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateRuleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).CreateRule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/CreateRule",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).CreateRule(ctx, req.(*CreateRuleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_GetRule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRuleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).GetRule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/GetRule",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).GetRule(ctx, req.(*GetRuleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_UpdateRule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateRuleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).UpdateRule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/UpdateRule",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).UpdateRule(ctx, req.(*UpdateRuleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_DeleteRule_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRuleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).DeleteRule(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/DeleteRule",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).DeleteRule(ctx, req.(*DeleteRuleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_ListRules_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRulesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).ListRules(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/ListRules",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).ListRules(ctx, req.(*ListRulesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_ActiveAlerts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ActiveAlertsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).ActiveAlerts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/ActiveAlerts",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).ActiveAlerts(ctx, req.(*ActiveAlertsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_CreateWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateWebhookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).CreateWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/CreateWebhook",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).CreateWebhook(ctx, req.(*CreateWebhookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_ListWebhooks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListWebhooksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).ListWebhooks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/ListWebhooks",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).ListWebhooks(ctx, req.(*ListWebhooksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_DeleteWebhook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteWebhookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).DeleteWebhook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/DeleteWebhook",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).DeleteWebhook(ctx, req.(*DeleteWebhookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_DeadLetters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeadLettersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).DeadLetters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/DeadLetters",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).DeadLetters(ctx, req.(*DeadLettersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_CreateGeofence_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateGeofenceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).CreateGeofence(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/CreateGeofence",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).CreateGeofence(ctx, req.(*CreateGeofenceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_UpdateGeofence_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateGeofenceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).UpdateGeofence(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/UpdateGeofence",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).UpdateGeofence(ctx, req.(*UpdateGeofenceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_DeleteGeofence_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteGeofenceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).DeleteGeofence(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/DeleteGeofence",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).DeleteGeofence(ctx, req.(*DeleteGeofenceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_ListGeofences_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListGeofencesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).ListGeofences(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/ListGeofences",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).ListGeofences(ctx, req.(*ListGeofencesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_NearbyDevices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NearbyDevicesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).NearbyDevices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/NearbyDevices",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).NearbyDevices(ctx, req.(*NearbyDevicesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Monitor_DevicesInBox_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DevicesInBoxRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MonitorServer).DevicesInBox(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.Monitor/DevicesInBox",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MonitorServer).DevicesInBox(ctx, req.(*DevicesInBoxRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
 var _Monitor_serviceDesc = grpc.ServiceDesc{
@@ -412,46 +6995,539 @@ var _Monitor_serviceDesc = grpc.ServiceDesc{
 			MethodName: "SubmitTelemetry",
 			Handler:    _Monitor_SubmitTelemetry_Handler,
 		},
+		{
+			MethodName: "RelayStatus",
+			Handler:    _Monitor_RelayStatus_Handler,
+		},
+		{
+			MethodName: "RelayTelemetry",
+			Handler:    _Monitor_RelayTelemetry_Handler,
+		},
+		{
+			MethodName: "GetTopology",
+			Handler:    _Monitor_GetTopology_Handler,
+		},
+		{
+			MethodName: "GetTwin",
+			Handler:    _Monitor_GetTwin_Handler,
+		},
+		{
+			MethodName: "UpdateDesired",
+			Handler:    _Monitor_UpdateDesired_Handler,
+		},
+		{
+			MethodName: "ReportState",
+			Handler:    _Monitor_ReportState_Handler,
+		},
+		{
+			MethodName: "EnqueueCommand",
+			Handler:    _Monitor_EnqueueCommand_Handler,
+		},
+		{
+			MethodName: "AckCommand",
+			Handler:    _Monitor_AckCommand_Handler,
+		},
+		{
+			MethodName: "CommandHistory",
+			Handler:    _Monitor_CommandHistory_Handler,
+		},
+		{
+			MethodName: "UploadFirmware",
+			Handler:    _Monitor_UploadFirmware_Handler,
+		},
+		{
+			MethodName: "ListFirmware",
+			Handler:    _Monitor_ListFirmware_Handler,
+		},
+		{
+			MethodName: "DownloadFirmware",
+			Handler:    _Monitor_DownloadFirmware_Handler,
+		},
+		{
+			MethodName: "CreateCampaign",
+			Handler:    _Monitor_CreateCampaign_Handler,
+		},
+		{
+			MethodName: "GetCampaign",
+			Handler:    _Monitor_GetCampaign_Handler,
+		},
+		{
+			MethodName: "ListCampaigns",
+			Handler:    _Monitor_ListCampaigns_Handler,
+		},
+		{
+			MethodName: "ControlCampaign",
+			Handler:    _Monitor_ControlCampaign_Handler,
+		},
+		{
+			MethodName: "ReportFirmware",
+			Handler:    _Monitor_ReportFirmware_Handler,
+		},
+		{
+			MethodName: "SetTelemetrySchema",
+			Handler:    _Monitor_SetTelemetrySchema_Handler,
+		},
+		{
+			MethodName: "GetTelemetrySchema",
+			Handler:    _Monitor_GetTelemetrySchema_Handler,
+		},
+		{
+			MethodName: "ListTelemetrySchemas",
+			Handler:    _Monitor_ListTelemetrySchemas_Handler,
+		},
+		{
+			MethodName: "DeleteTelemetrySchema",
+			Handler:    _Monitor_DeleteTelemetrySchema_Handler,
+		},
+		{
+			MethodName: "ImportDevices",
+			Handler:    _Monitor_ImportDevices_Handler,
+		},
+		{
+			MethodName: "ExportDevices",
+			Handler:    _Monitor_ExportDevices_Handler,
+		},
+		{
+			MethodName: "GetDevice",
+			Handler:    _Monitor_GetDevice_Handler,
+		},
+		{
+			MethodName: "UpdateDevice",
+			Handler:    _Monitor_UpdateDevice_Handler,
+		},
+		{
+			MethodName: "DeleteDevice",
+			Handler:    _Monitor_DeleteDevice_Handler,
+		},
+		{
+			MethodName: "ListDevices",
+			Handler:    _Monitor_ListDevices_Handler,
+		},
+		{
+			MethodName: "SetLabels",
+			Handler:    _Monitor_SetLabels_Handler,
+		},
+		{
+			MethodName: "ListStatuses",
+			Handler:    _Monitor_ListStatuses_Handler,
+		},
+		{
+			MethodName: "GetStatus",
+			Handler:    _Monitor_GetStatus_Handler,
+		},
+		{
+			MethodName: "BatteryHistory",
+			Handler:    _Monitor_BatteryHistory_Handler,
+		},
+		{
+			MethodName: "QueryTelemetry",
+			Handler:    _Monitor_QueryTelemetry_Handler,
+		},
+		{
+			MethodName: "AggregateTelemetry",
+			Handler:    _Monitor_AggregateTelemetry_Handler,
+		},
+		{
+			MethodName: "CreateGroup",
+			Handler:    _Monitor_CreateGroup_Handler,
+		},
+		{
+			MethodName: "UpdateGroup",
+			Handler:    _Monitor_UpdateGroup_Handler,
+		},
+		{
+			MethodName: "DeleteGroup",
+			Handler:    _Monitor_DeleteGroup_Handler,
+		},
+		{
+			MethodName: "ListGroups",
+			Handler:    _Monitor_ListGroups_Handler,
+		},
+		{
+			MethodName: "AddGroupMembers",
+			Handler:    _Monitor_AddGroupMembers_Handler,
+		},
+		{
+			MethodName: "RemoveGroupMembers",
+			Handler:    _Monitor_RemoveGroupMembers_Handler,
+		},
+		{
+			MethodName: "CreateRule",
+			Handler:    _Monitor_CreateRule_Handler,
+		},
+		{
+			MethodName: "GetRule",
+			Handler:    _Monitor_GetRule_Handler,
+		},
+		{
+			MethodName: "UpdateRule",
+			Handler:    _Monitor_UpdateRule_Handler,
+		},
+		{
+			MethodName: "DeleteRule",
+			Handler:    _Monitor_DeleteRule_Handler,
+		},
+		{
+			MethodName: "ListRules",
+			Handler:    _Monitor_ListRules_Handler,
+		},
+		{
+			MethodName: "ActiveAlerts",
+			Handler:    _Monitor_ActiveAlerts_Handler,
+		},
+		{
+			MethodName: "CreateWebhook",
+			Handler:    _Monitor_CreateWebhook_Handler,
+		},
+		{
+			MethodName: "ListWebhooks",
+			Handler:    _Monitor_ListWebhooks_Handler,
+		},
+		{
+			MethodName: "DeleteWebhook",
+			Handler:    _Monitor_DeleteWebhook_Handler,
+		},
+		{
+			MethodName: "DeadLetters",
+			Handler:    _Monitor_DeadLetters_Handler,
+		},
+		{
+			MethodName: "CreateGeofence",
+			Handler:    _Monitor_CreateGeofence_Handler,
+		},
+		{
+			MethodName: "UpdateGeofence",
+			Handler:    _Monitor_UpdateGeofence_Handler,
+		},
+		{
+			MethodName: "DeleteGeofence",
+			Handler:    _Monitor_DeleteGeofence_Handler,
+		},
+		{
+			MethodName: "ListGeofences",
+			Handler:    _Monitor_ListGeofences_Handler,
+		},
+		{
+			MethodName: "NearbyDevices",
+			Handler:    _Monitor_NearbyDevices_Handler,
+		},
+		{
+			MethodName: "DevicesInBox",
+			Handler:    _Monitor_DevicesInBox_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchDesired",
+			Handler:       _Monitor_WatchDesired_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchCommands",
+			Handler:       _Monitor_WatchCommands_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchEvents",
+			Handler:       _Monitor_WatchEvents_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "iotmonitor.proto",
 }
 
 func init() { proto.RegisterFile("iotmonitor.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 520 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x54, 0xd1, 0x6e, 0xd3, 0x30,
-	0x14, 0x25, 0x69, 0x37, 0xba, 0xbb, 0xae, 0x14, 0x6f, 0x83, 0x10, 0x21, 0x54, 0x85, 0x97, 0x6a,
-	0x0f, 0x7d, 0x28, 0x2f, 0x08, 0xde, 0x50, 0x2b, 0x84, 0x34, 0x36, 0xc9, 0x1d, 0x2f, 0x3c, 0xe1,
-	0x34, 0x57, 0x95, 0x35, 0xc7, 0x0e, 0xae, 0xb3, 0x29, 0x5f, 0xc0, 0x17, 0xf0, 0x2d, 0xfc, 0x0d,
-	0xdf, 0x82, 0x6c, 0xb7, 0x69, 0xbb, 0x06, 0x09, 0xf1, 0xe6, 0x7b, 0x8e, 0x7b, 0xef, 0x39, 0x37,
-	0xc7, 0x85, 0x3e, 0x57, 0x26, 0x57, 0x92, 0x1b, 0xa5, 0x47, 0x85, 0x56, 0x46, 0x91, 0xb0, 0x48,
-	0x93, 0x9f, 0x01, 0x9c, 0x53, 0x5c, 0xf0, 0xa5, 0x41, 0x3d, 0xc1, 0x3b, 0x3e, 0x47, 0x8a, 0xdf,
-	0x4b, 0x5c, 0x1a, 0x42, 0xa0, 0x2d, 0x59, 0x8e, 0x51, 0x30, 0x08, 0x86, 0x47, 0xd4, 0x9d, 0x49,
-	0x02, 0xdd, 0x25, 0x6a, 0xce, 0x84, 0x2c, 0xf3, 0x14, 0x75, 0x14, 0x3a, 0x6e, 0x07, 0x23, 0x67,
-	0x70, 0xa0, 0xee, 0x25, 0xea, 0xa8, 0xe5, 0x48, 0x5f, 0x90, 0x11, 0x40, 0xe6, 0xda, 0x9b, 0xaa,
-	0xc0, 0xa8, 0x3d, 0x08, 0x86, 0xbd, 0x71, 0x6f, 0x54, 0xa4, 0x23, 0x3f, 0xf4, 0xa6, 0x2a, 0x90,
-	0x6e, 0xdd, 0x48, 0xe6, 0x70, 0xfa, 0x50, 0x56, 0x21, 0x2a, 0xf2, 0x0a, 0x40, 0xaf, 0x60, 0xcc,
-	0x9c, 0xb4, 0x0e, 0xdd, 0x42, 0x48, 0x0c, 0x1d, 0xdf, 0x84, 0x67, 0x4e, 0x5c, 0x9b, 0xd6, 0x35,
-	0xe9, 0x43, 0x0b, 0xf5, 0x5a, 0x96, 0x3d, 0x26, 0x3f, 0x02, 0x38, 0x9d, 0x19, 0x66, 0xca, 0xe5,
-	0x97, 0x22, 0x63, 0xa6, 0xb6, 0xbe, 0xdd, 0x25, 0x78, 0xd0, 0x65, 0x08, 0x1d, 0xa1, 0xe6, 0xcc,
-	0x70, 0x25, 0xdd, 0x84, 0xe3, 0x71, 0xd7, 0xda, 0xb8, 0x5c, 0x61, 0xb4, 0x66, 0xc9, 0x05, 0xf4,
-	0x53, 0x66, 0x0c, 0xea, 0x4a, 0x63, 0xce, 0xb8, 0xe4, 0x72, 0xe1, 0x86, 0x9f, 0xd0, 0x3d, 0x3c,
-	0xf9, 0x04, 0x4f, 0x77, 0x85, 0x58, 0xb3, 0x09, 0x74, 0xd9, 0xfc, 0x56, 0xaa, 0x7b, 0x81, 0xd9,
-	0xa2, 0xb6, 0xbb, 0x83, 0xad, 0x4d, 0x85, 0x1b, 0x53, 0xbf, 0x02, 0x78, 0x76, 0x83, 0x02, 0x73,
-	0x34, 0xba, 0x9a, 0x95, 0x69, 0xce, 0xcd, 0xbf, 0xf8, 0x9a, 0x40, 0x47, 0x23, 0xcb, 0xb8, 0x5c,
-	0x2c, 0xa3, 0x70, 0xd0, 0x1a, 0x1e, 0x8f, 0x87, 0xd6, 0x57, 0x73, 0xa7, 0x11, 0x5d, 0x5d, 0x9d,
-	0x4a, 0xa3, 0x2b, 0x5a, 0xff, 0x32, 0x7e, 0x0f, 0x27, 0x3b, 0x94, 0xd5, 0x77, 0x8b, 0xd5, 0x2a,
-	0x44, 0xf6, 0x68, 0xf3, 0x71, 0xc7, 0x44, 0x89, 0x4e, 0x73, 0x48, 0x7d, 0xf1, 0x2e, 0x7c, 0x1b,
-	0x24, 0x97, 0x70, 0xb6, 0x37, 0xee, 0xff, 0xf7, 0xf0, 0x0d, 0x3a, 0xeb, 0x8f, 0x42, 0x5e, 0xc2,
-	0x91, 0x50, 0x72, 0xc1, 0x4d, 0x99, 0xf9, 0x40, 0x87, 0x74, 0x03, 0xd8, 0xb5, 0x08, 0x66, 0x3c,
-	0xe9, 0x45, 0xd5, 0xb5, 0xe5, 0x98, 0x58, 0x71, 0x2d, 0xcf, 0xad, 0xeb, 0x8b, 0xd7, 0x00, 0x9b,
-	0xf4, 0x92, 0x23, 0x38, 0x98, 0xd0, 0xeb, 0xab, 0x69, 0xff, 0x11, 0x01, 0x38, 0x9c, 0x4d, 0xaf,
-	0x66, 0xd7, 0xb4, 0x1f, 0x8c, 0x7f, 0x07, 0xf0, 0xf8, 0xb3, 0x7f, 0x76, 0x64, 0x02, 0xbd, 0xdd,
-	0x50, 0x93, 0x17, 0x76, 0xc7, 0x8d, 0xef, 0x2f, 0x7e, 0xde, 0x44, 0xd9, 0x75, 0x4c, 0x80, 0xf8,
-	0x94, 0x78, 0xd0, 0xe7, 0x86, 0xb8, 0xeb, 0x0d, 0x61, 0x8e, 0xcf, 0xf7, 0x09, 0xdb, 0xe5, 0x23,
-	0x3c, 0xf1, 0x3b, 0xae, 0x57, 0x4e, 0xe2, 0xbf, 0x7f, 0xf0, 0x38, 0x6a, 0xe4, 0x0a, 0x51, 0x7d,
-	0x68, 0x7f, 0x0d, 0x8b, 0x34, 0x3d, 0x74, 0x7f, 0x29, 0x6f, 0xfe, 0x04, 0x00, 0x00, 0xff, 0xff,
-	0x12, 0x23, 0x97, 0x79, 0x66, 0x04, 0x00, 0x00,
+	// 4579 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xcc, 0x7c, 0x5b, 0x6f, 0x1b, 0x49,
+	0x76, 0xf0, 0x36, 0xef, 0x3c, 0x94, 0x28, 0xb2, 0x49, 0x4a, 0x74, 0x7b, 0x3d, 0xa3, 0x69, 0xcf,
+	0x8e, 0xfd, 0xed, 0xe0, 0xf3, 0xcc, 0x7a, 0xc6, 0x97, 0xb9, 0x60, 0x1d, 0xd9, 0x92, 0x65, 0xcf,
+	0xfa, 0xb2, 0x91, 0x9c, 0x59, 0xec, 0x3e, 0x64, 0xd1, 0x22, 0xcb, 0x54, 0xc3, 0xcd, 0x6e, 0x4e,
+	0x77, 0x53, 0x12, 0xf3, 0x90, 0xe7, 0x24, 0x40, 0x80, 0x20, 0x41, 0x80, 0x3c, 0x6c, 0x1e, 0x92,
+	0x00, 0x79, 0xc8, 0x4f, 0xc8, 0x5b, 0x7e, 0x47, 0xfe, 0x48, 0x1e, 0x83, 0x53, 0x97, 0xee, 0xaa,
+	0xea, 0x6a, 0x89, 0x1e, 0x04, 0x48, 0xde, 0xc4, 0x53, 0x55, 0xe7, 0x56, 0xe7, 0x9c, 0x3a, 0xe7,
+	0x54, 0xb5, 0xa0, 0xe7, 0x47, 0xe9, 0x3c, 0x0a, 0xfd, 0x34, 0x8a, 0xef, 0x2c, 0xe2, 0x28, 0x8d,
+	0xec, 0xca, 0xe2, 0xc4, 0xfd, 0x83, 0x05, 0xa3, 0x23, 0x32, 0xf3, 0x93, 0x94, 0xc4, 0xfb, 0xe4,
+	0xcc, 0x9f, 0x90, 0x23, 0xf2, 0xc3, 0x92, 0x24, 0xa9, 0xbd, 0x01, 0xb5, 0xd0, 0x9b, 0x93, 0xb1,
+	0xb5, 0x6b, 0xdd, 0x6e, 0xdb, 0x43, 0xd8, 0x48, 0x48, 0xec, 0x7b, 0x41, 0xb8, 0x9c, 0x9f, 0x90,
+	0x78, 0x5c, 0xa1, 0xd0, 0x4d, 0xa8, 0x47, 0xe7, 0x21, 0x89, 0xc7, 0x55, 0xfa, 0xd3, 0x05, 0x98,
+	0x52, 0x1c, 0xe9, 0x6a, 0x41, 0xc6, 0xb5, 0x5d, 0xeb, 0x76, 0xf7, 0x6e, 0xf7, 0xce, 0xe2, 0xe4,
+	0x0e, 0xc3, 0xfc, 0x66, 0xb5, 0x20, 0xf6, 0x35, 0x68, 0x04, 0xde, 0x09, 0x09, 0x92, 0x71, 0x7d,
+	0xb7, 0x7a, 0xbb, 0x73, 0xb7, 0x8d, 0xe3, 0x2f, 0x10, 0x62, 0xf7, 0xa1, 0x3d, 0xf3, 0x52, 0x72,
+	0xee, 0xad, 0xfc, 0xe9, 0xb8, 0xb1, 0x6b, 0xdd, 0xae, 0xb9, 0x37, 0xa1, 0xce, 0xc6, 0x3a, 0x50,
+	0x7d, 0x47, 0x56, 0x9c, 0x99, 0x4d, 0xa8, 0x9f, 0x79, 0xc1, 0x92, 0x30, 0x2e, 0xdc, 0x67, 0x30,
+	0xd0, 0x45, 0x58, 0x04, 0x2b, 0xdb, 0x06, 0x88, 0x39, 0x98, 0x4c, 0xe9, 0xca, 0x96, 0xdd, 0x83,
+	0x16, 0xe3, 0xd0, 0x9f, 0xd2, 0xc5, 0x35, 0x44, 0x4c, 0x62, 0x2e, 0x80, 0xfb, 0x0f, 0x16, 0x0c,
+	0x8e, 0x53, 0x2f, 0x5d, 0x26, 0x7f, 0xb2, 0x98, 0x7a, 0x69, 0xa6, 0x0b, 0x79, 0x99, 0x45, 0x97,
+	0x7d, 0x00, 0xad, 0x20, 0x9a, 0x78, 0xa9, 0x1f, 0x85, 0x14, 0x51, 0xe7, 0xee, 0x06, 0x15, 0x84,
+	0xc3, 0xec, 0x31, 0xf4, 0x4e, 0xbc, 0x34, 0x25, 0xf1, 0x2a, 0x26, 0x73, 0xcf, 0x0f, 0xfd, 0x70,
+	0x46, 0x69, 0x6c, 0xda, 0x1f, 0x43, 0xeb, 0xad, 0x1f, 0xcf, 0xcf, 0xbd, 0x98, 0xa9, 0xa8, 0x73,
+	0xd7, 0xc6, 0x95, 0x4f, 0x39, 0xec, 0x88, 0x2c, 0xa2, 0x38, 0xb5, 0xb7, 0xa0, 0x39, 0x25, 0x81,
+	0x7f, 0x46, 0xe2, 0x71, 0x1d, 0x39, 0x77, 0xff, 0xd1, 0x82, 0xbe, 0xca, 0x1a, 0xca, 0x38, 0x84,
+	0x0d, 0x6f, 0xf2, 0x2e, 0x8c, 0xce, 0x03, 0x32, 0x9d, 0x65, 0x52, 0x72, 0x99, 0xd8, 0x1e, 0x7d,
+	0x84, 0x98, 0x12, 0x1f, 0x75, 0x50, 0xa5, 0xe4, 0x7a, 0x6c, 0x47, 0x28, 0x08, 0x31, 0x12, 0xfb,
+	0x06, 0xb4, 0x26, 0xd1, 0x7c, 0xee, 0x85, 0xd3, 0x64, 0x5c, 0xa3, 0xbb, 0xd2, 0xc1, 0x39, 0x4f,
+	0x18, 0xcc, 0xbe, 0x29, 0x71, 0x5c, 0xa7, 0x28, 0xfa, 0x32, 0xc7, 0xaf, 0xdf, 0xbe, 0x25, 0xb1,
+	0xfb, 0xb7, 0x15, 0xd8, 0x7e, 0x43, 0x02, 0x32, 0x27, 0x69, 0xbc, 0x3a, 0x5e, 0x9e, 0xcc, 0xfd,
+	0xb4, 0x5c, 0x7b, 0x5f, 0x43, 0x2b, 0x26, 0xde, 0xd4, 0x0f, 0x67, 0xc9, 0xb8, 0x42, 0x09, 0xde,
+	0x46, 0x8c, 0xe6, 0xf5, 0x77, 0x8e, 0xf8, 0xd4, 0x83, 0x30, 0x8d, 0x57, 0xf6, 0x7d, 0x68, 0xd0,
+	0xcd, 0x4f, 0xc6, 0x55, 0xba, 0xf2, 0x93, 0x4b, 0x56, 0x7e, 0x4f, 0x27, 0xd2, 0x75, 0xce, 0x67,
+	0xb0, 0xa9, 0x22, 0x2a, 0x37, 0xa9, 0xca, 0xd7, 0x95, 0x87, 0x96, 0xb3, 0x07, 0x1d, 0x69, 0xbd,
+	0x3a, 0xfd, 0x23, 0x79, 0x3a, 0xdf, 0xc1, 0x8c, 0x07, 0xba, 0x0a, 0x51, 0xb8, 0x7f, 0x63, 0x41,
+	0x57, 0x05, 0xa3, 0x5b, 0x51, 0xef, 0x60, 0x78, 0x06, 0xd0, 0x99, 0x46, 0xcb, 0x93, 0x80, 0xe4,
+	0xd8, 0x2c, 0xd4, 0x97, 0x1f, 0xa6, 0x0c, 0x82, 0x5b, 0x56, 0x45, 0xcf, 0x38, 0x89, 0xa2, 0x80,
+	0x81, 0x6a, 0x74, 0x8f, 0x07, 0xd0, 0x49, 0xd2, 0xd8, 0x0f, 0x67, 0x0c, 0x58, 0x17, 0xe8, 0xce,
+	0xc8, 0x24, 0x8d, 0x62, 0x06, 0x6c, 0xec, 0x56, 0x6f, 0x5b, 0x48, 0x71, 0x19, 0xfa, 0xe9, 0xb8,
+	0x49, 0x4d, 0xfc, 0x04, 0x86, 0x05, 0x65, 0xad, 0x69, 0x49, 0xb7, 0x00, 0xce, 0xfc, 0x28, 0xa0,
+	0x06, 0x2e, 0xb4, 0x3f, 0x40, 0xc9, 0x8f, 0x27, 0xa7, 0x64, 0xee, 0x7d, 0x2f, 0xc6, 0xdc, 0x3d,
+	0x68, 0x65, 0x8e, 0xd0, 0x87, 0x76, 0x10, 0x85, 0x33, 0x3f, 0x5d, 0x4e, 0x99, 0xd0, 0x15, 0x94,
+	0x0f, 0x27, 0x52, 0x48, 0x45, 0x40, 0xbc, 0x80, 0x43, 0x50, 0xe2, 0x8a, 0xfb, 0xef, 0x16, 0xd4,
+	0x8e, 0x96, 0x01, 0xb1, 0xbb, 0xd0, 0x88, 0x97, 0x41, 0x6e, 0x3a, 0x22, 0x2c, 0x31, 0x96, 0x1c,
+	0xa8, 0xbd, 0xf3, 0x43, 0x66, 0xd9, 0x5d, 0xe6, 0x82, 0xb8, 0xea, 0x57, 0x7e, 0x38, 0xc5, 0x95,
+	0x28, 0xa5, 0x3f, 0xa1, 0x1a, 0x6b, 0x23, 0x91, 0x68, 0x41, 0x62, 0x2f, 0x8d, 0x62, 0xae, 0xae,
+	0x3e, 0xb4, 0xd3, 0xd3, 0x98, 0x24, 0xa7, 0x51, 0xc0, 0x02, 0x8e, 0x85, 0x8b, 0xce, 0xfd, 0x70,
+	0x1a, 0x9d, 0x53, 0x75, 0x55, 0x15, 0xdb, 0x6d, 0x51, 0x06, 0x6c, 0x25, 0xc8, 0xb5, 0xd5, 0x38,
+	0x08, 0x54, 0xc7, 0xff, 0x64, 0x41, 0x7d, 0x2f, 0x20, 0x71, 0x5a, 0xe0, 0xbe, 0x18, 0x7f, 0x36,
+	0xa1, 0x9e, 0xa0, 0x13, 0x8e, 0xab, 0xaa, 0x1d, 0xd6, 0x28, 0x3b, 0x5b, 0xd0, 0x9c, 0x93, 0x24,
+	0xf1, 0x66, 0x62, 0x87, 0xb7, 0xa0, 0xf9, 0x16, 0x1d, 0xd7, 0x4b, 0x29, 0xc3, 0x55, 0x16, 0xe5,
+	0x92, 0x28, 0x38, 0xa3, 0xb0, 0xa6, 0x80, 0xcd, 0x48, 0xf4, 0x96, 0x84, 0x12, 0xdb, 0x5d, 0x68,
+	0x24, 0xd1, 0x32, 0x9e, 0x70, 0x96, 0xdd, 0x4f, 0xa1, 0xff, 0x24, 0x26, 0x18, 0x48, 0x96, 0x41,
+	0x16, 0xe7, 0xb6, 0xa1, 0x86, 0xec, 0x52, 0x66, 0x3b, 0x77, 0x5b, 0x42, 0x9d, 0xee, 0x1d, 0xd8,
+	0x92, 0x27, 0xa3, 0xbd, 0xe8, 0x92, 0xc9, 0x96, 0xe2, 0xee, 0x42, 0xf7, 0x90, 0xa4, 0x32, 0x66,
+	0x6d, 0xba, 0xfb, 0x05, 0x6c, 0x64, 0x33, 0x10, 0x5d, 0x09, 0x65, 0x15, 0xed, 0xa7, 0xd0, 0xe7,
+	0xc1, 0x6f, 0x0d, 0x9e, 0xbf, 0x84, 0x2d, 0x79, 0xf2, 0x7a, 0x36, 0xee, 0xde, 0x84, 0xfe, 0x3e,
+	0x09, 0x48, 0x4a, 0x2e, 0x63, 0xfe, 0x4b, 0xd8, 0x92, 0x27, 0xad, 0x89, 0xda, 0x86, 0xde, 0x0b,
+	0x3f, 0xa1, 0x32, 0x27, 0x1c, 0xb3, 0x7b, 0x1f, 0xba, 0x12, 0x0c, 0x11, 0xed, 0x40, 0x1d, 0x69,
+	0x25, 0x63, 0x6b, 0xb7, 0x2a, 0xcb, 0xa3, 0xe2, 0x7a, 0x0e, 0x83, 0xbd, 0x49, 0xea, 0x9f, 0x11,
+	0x6a, 0x66, 0x49, 0x79, 0xa4, 0xdd, 0x85, 0xc6, 0x5b, 0x3f, 0x48, 0xf9, 0x89, 0x9d, 0x05, 0x7f,
+	0x9c, 0xf3, 0x94, 0xc2, 0xdd, 0x6f, 0xa0, 0xaf, 0xa2, 0x42, 0x2e, 0xae, 0x41, 0xc3, 0xa3, 0x3f,
+	0xc7, 0x56, 0x7e, 0x4a, 0x33, 0x93, 0x56, 0xf8, 0xf8, 0x2d, 0xd4, 0x0f, 0xce, 0x48, 0x48, 0xcf,
+	0x2b, 0x82, 0x7f, 0xc8, 0x7e, 0x4a, 0x1d, 0xa4, 0x22, 0x7c, 0x2f, 0x63, 0xac, 0x4a, 0xc7, 0xd1,
+	0xf7, 0xfc, 0x39, 0x49, 0x52, 0x6f, 0xbe, 0xa0, 0xc6, 0x5e, 0xc5, 0x25, 0x53, 0x2f, 0xf5, 0xa8,
+	0xa5, 0x6f, 0xb8, 0xcf, 0xa1, 0xf9, 0x1b, 0x72, 0x72, 0x1a, 0x45, 0xef, 0x70, 0xee, 0x39, 0xfb,
+	0x53, 0x36, 0xb7, 0x65, 0x1c, 0x70, 0xec, 0x68, 0xdb, 0x64, 0x12, 0x93, 0x94, 0x3b, 0x51, 0x17,
+	0x1a, 0x94, 0x19, 0x76, 0x9a, 0xb5, 0xdd, 0x15, 0xc0, 0x3e, 0xf1, 0xa6, 0x2f, 0x48, 0x9a, 0x92,
+	0xf8, 0x4a, 0x6c, 0x63, 0xa8, 0xd3, 0xd5, 0xfc, 0xb8, 0xa4, 0xa2, 0x33, 0x21, 0x37, 0xa1, 0x4e,
+	0xe2, 0x38, 0x8a, 0xf3, 0x80, 0xe2, 0xa5, 0x29, 0x99, 0x2f, 0xd2, 0x84, 0xf2, 0x5c, 0x47, 0xc8,
+	0x5b, 0xcf, 0x0f, 0x72, 0xf7, 0x74, 0xbf, 0x84, 0x21, 0xf3, 0x1c, 0x2e, 0x8b, 0xd8, 0xa9, 0x9f,
+	0x42, 0x93, 0x33, 0xc1, 0x0d, 0x97, 0x9e, 0xb8, 0x7c, 0x92, 0xfb, 0x25, 0xd8, 0xda, 0x2a, 0xdc,
+	0x14, 0x33, 0xe3, 0xf9, 0x66, 0x8c, 0x60, 0x80, 0xc6, 0xc4, 0xd7, 0x64, 0x36, 0xf6, 0x08, 0xfa,
+	0x2a, 0x18, 0x71, 0xdd, 0x80, 0x16, 0xc7, 0x25, 0xb6, 0x58, 0x66, 0x40, 0xc5, 0xfb, 0xff, 0x60,
+	0xc8, 0xcc, 0x5d, 0x93, 0xa1, 0xc8, 0x8f, 0xfb, 0x00, 0x6c, 0x6d, 0xea, 0xda, 0x7e, 0x67, 0xe7,
+	0x5b, 0x94, 0xd9, 0xf3, 0x26, 0xd4, 0x03, 0x7f, 0xee, 0xa7, 0x74, 0x45, 0xdd, 0xdd, 0x87, 0x9e,
+	0x32, 0x09, 0x71, 0xdf, 0x84, 0xce, 0x94, 0x78, 0xd3, 0x80, 0xc1, 0xb8, 0x2c, 0x3c, 0xe9, 0xcc,
+	0xb6, 0x5c, 0x21, 0xf5, 0x5f, 0x16, 0x34, 0x98, 0x07, 0x18, 0xfc, 0x45, 0x3d, 0x5e, 0x7e, 0x44,
+	0x7e, 0xdb, 0x85, 0x46, 0x14, 0x06, 0x7e, 0xc8, 0x02, 0x76, 0x8b, 0x1d, 0x76, 0x49, 0x9a, 0x10,
+	0x12, 0xf2, 0x88, 0xfd, 0x31, 0x34, 0x79, 0x6a, 0x48, 0xc3, 0x35, 0x3f, 0x43, 0x1f, 0x33, 0xd0,
+	0x41, 0x92, 0xfa, 0x73, 0x2f, 0x95, 0xf3, 0xe4, 0xd6, 0xa5, 0x79, 0x72, 0x9b, 0xb2, 0x3d, 0x80,
+	0xce, 0x32, 0x8c, 0x89, 0x37, 0x39, 0xf5, 0x4e, 0x02, 0x32, 0x06, 0x41, 0x3a, 0xcb, 0xdb, 0x3a,
+	0x54, 0xf4, 0x8f, 0xa1, 0x77, 0x48, 0x52, 0x35, 0xcf, 0x2f, 0xe8, 0xc0, 0xfd, 0x0a, 0xba, 0xd2,
+	0x2c, 0x54, 0xb2, 0x03, 0x0d, 0x36, 0x87, 0x1b, 0x2b, 0xe4, 0x42, 0xab, 0xba, 0xdd, 0x87, 0x01,
+	0x0b, 0xba, 0x57, 0xd0, 0xb8, 0x54, 0xcf, 0xee, 0x7d, 0xe8, 0xab, 0x58, 0xd6, 0x34, 0xa2, 0x5b,
+	0x30, 0x60, 0xd6, 0x77, 0x95, 0x84, 0xf7, 0xa1, 0xaf, 0x4e, 0x5c, 0x93, 0xc0, 0x7d, 0xb0, 0xd1,
+	0x95, 0xd8, 0xaa, 0xcc, 0x4a, 0xf3, 0x18, 0x6b, 0x95, 0xc4, 0xd8, 0x6f, 0x59, 0xe8, 0xcf, 0xd6,
+	0x21, 0xb9, 0xeb, 0x98, 0x97, 0xd3, 0xdf, 0xdc, 0x68, 0x4b, 0x95, 0xfa, 0x2f, 0x16, 0xb4, 0x0e,
+	0xf9, 0x79, 0xae, 0x9d, 0xed, 0x26, 0x65, 0x76, 0xa1, 0xb1, 0x88, 0x02, 0x7f, 0xb2, 0xe2, 0x56,
+	0x2b, 0xa7, 0x5b, 0x2c, 0xab, 0x50, 0x72, 0xb2, 0xba, 0xc8, 0x7b, 0x62, 0x6f, 0xea, 0x2f, 0x13,
+	0x9e, 0x07, 0x6d, 0x41, 0x73, 0x11, 0x05, 0xab, 0x59, 0x14, 0xb2, 0xbc, 0x91, 0x15, 0x24, 0x8c,
+	0x5d, 0x34, 0x48, 0x9a, 0x50, 0xd0, 0x3d, 0x4b, 0xc6, 0x6d, 0x1a, 0x64, 0x1f, 0xc0, 0x88, 0xc5,
+	0x2c, 0xc1, 0xaa, 0x50, 0xcf, 0x07, 0xd0, 0x12, 0x1c, 0x73, 0x05, 0xd1, 0x3c, 0x4d, 0x4c, 0x73,
+	0xef, 0xc3, 0x40, 0x5f, 0xc8, 0xcb, 0xb7, 0x82, 0xa0, 0x8a, 0x5a, 0x1e, 0xc0, 0x88, 0x59, 0xc9,
+	0xfb, 0x12, 0x7c, 0x28, 0x8c, 0x54, 0x25, 0xb8, 0xc6, 0xfe, 0x7f, 0x0a, 0x23, 0x66, 0x37, 0x3a,
+	0x49, 0x03, 0xb3, 0x48, 0x46, 0x9f, 0xbc, 0x26, 0x99, 0x6d, 0x18, 0xa2, 0xb9, 0x88, 0x75, 0x59,
+	0x24, 0x7f, 0x0c, 0xb6, 0x06, 0x47, 0x84, 0x1f, 0x42, 0x5b, 0xd0, 0x16, 0xa6, 0xa4, 0xc8, 0xab,
+	0xe2, 0x9e, 0x40, 0x97, 0xd9, 0x58, 0x96, 0xa1, 0x5f, 0xe6, 0xdc, 0x7a, 0xaa, 0xae, 0xd9, 0x4e,
+	0x55, 0xd4, 0x2b, 0x53, 0x3f, 0x49, 0xbd, 0x70, 0xc2, 0x0d, 0xcc, 0x25, 0xb0, 0x21, 0xdb, 0xbf,
+	0x96, 0x33, 0x5b, 0xaa, 0xcf, 0x57, 0x44, 0x2e, 0x2e, 0x9c, 0x94, 0xd5, 0x16, 0x34, 0x59, 0x4e,
+	0x48, 0x40, 0xeb, 0x19, 0x7e, 0xe2, 0x6e, 0x42, 0x7d, 0x16, 0x47, 0xcb, 0x05, 0x35, 0xda, 0x9a,
+	0x3b, 0x87, 0xe1, 0x2b, 0xe2, 0xc5, 0x27, 0x2b, 0xcd, 0x21, 0x65, 0xae, 0xad, 0x22, 0xd7, 0x15,
+	0xcd, 0xe2, 0x99, 0x14, 0xb9, 0x17, 0xd7, 0x4a, 0xbc, 0xf8, 0x29, 0xd8, 0x1a, 0x39, 0x76, 0x00,
+	0x69, 0x7e, 0x6c, 0xe7, 0x0b, 0x33, 0x1d, 0x2b, 0x5b, 0xf0, 0xd7, 0x16, 0x0c, 0xd8, 0x78, 0xf2,
+	0x3c, 0x7c, 0x1c, 0x5d, 0x08, 0xb6, 0x07, 0xd0, 0x99, 0xfb, 0xa1, 0xc6, 0xf9, 0x10, 0x36, 0x10,
+	0xa8, 0x31, 0x8f, 0x53, 0xbd, 0x8b, 0x6c, 0x6a, 0x35, 0x9b, 0xea, 0x5d, 0xe4, 0x53, 0x6b, 0x9a,
+	0x5c, 0xf5, 0x12, 0xb9, 0x0e, 0xa0, 0xcf, 0x7e, 0x0b, 0x76, 0x7e, 0x9c, 0x58, 0xfb, 0xb0, 0xf1,
+	0x32, 0x42, 0xf0, 0x9b, 0x28, 0xf5, 0x82, 0x44, 0x31, 0x0b, 0x4b, 0x18, 0xca, 0xdc, 0xbb, 0x48,
+	0x16, 0x84, 0x4c, 0xf3, 0x52, 0x77, 0x16, 0xf8, 0xe9, 0xe4, 0x94, 0xb0, 0x6d, 0xa8, 0xba, 0xff,
+	0x69, 0x09, 0xdb, 0x61, 0xdd, 0x8e, 0x1f, 0xd1, 0x7b, 0xd9, 0xca, 0x0f, 0x58, 0xd6, 0x72, 0x31,
+	0xe4, 0x9a, 0x58, 0x76, 0x51, 0x3e, 0xea, 0x22, 0xdc, 0x9d, 0xb2, 0xe6, 0x00, 0x8f, 0x7f, 0x7d,
+	0x68, 0x4f, 0x02, 0x7f, 0x7e, 0x12, 0x7b, 0x29, 0x19, 0x37, 0x05, 0xaf, 0x99, 0x3c, 0x2d, 0x61,
+	0x42, 0x8c, 0x7b, 0x7a, 0x0a, 0xb7, 0x50, 0xd5, 0x29, 0x95, 0x7d, 0x0c, 0xb9, 0xaa, 0x65, 0x9d,
+	0xf0, 0x03, 0x98, 0x49, 0x56, 0x7e, 0x3c, 0x3d, 0x82, 0xae, 0x34, 0x0b, 0x77, 0x63, 0x17, 0x1a,
+	0x09, 0xfd, 0x59, 0x3c, 0x62, 0xb8, 0x9a, 0x94, 0xad, 0xf8, 0x53, 0xd8, 0xd2, 0xf3, 0x09, 0x4c,
+	0xa5, 0xc8, 0x19, 0x09, 0x28, 0x82, 0x4d, 0x24, 0x3a, 0x39, 0xf5, 0xe2, 0x19, 0x4a, 0x5c, 0xa1,
+	0xcc, 0x8f, 0x60, 0x73, 0xea, 0x27, 0x14, 0x48, 0x62, 0x51, 0x90, 0x52, 0x4b, 0x43, 0xdd, 0xa5,
+	0x11, 0xe6, 0xb9, 0x2b, 0xa6, 0x3d, 0xf7, 0x17, 0xb0, 0xc9, 0xf1, 0x1f, 0x7b, 0xf3, 0x45, 0x50,
+	0xc0, 0xae, 0x28, 0xbc, 0x42, 0x97, 0x3c, 0x84, 0x11, 0x5f, 0xf2, 0xcc, 0x4f, 0xd2, 0x28, 0x5e,
+	0x95, 0xe7, 0x06, 0xb8, 0x37, 0x3e, 0x6a, 0x99, 0xad, 0x7c, 0x0a, 0x03, 0x7d, 0x25, 0xaa, 0xc4,
+	0x85, 0x66, 0x42, 0x89, 0x0b, 0x03, 0xed, 0x4b, 0x69, 0x14, 0x67, 0x4b, 0x51, 0xca, 0x5f, 0xc9,
+	0xcd, 0x98, 0x5f, 0x47, 0x7e, 0x98, 0xaa, 0x7c, 0x5a, 0xd4, 0x30, 0x3a, 0x50, 0x9d, 0xfb, 0x21,
+	0x37, 0x4f, 0xfc, 0xe1, 0x5d, 0x70, 0x4d, 0x74, 0xa0, 0xea, 0x9d, 0xcd, 0xb8, 0x57, 0x6d, 0x40,
+	0x0d, 0xd3, 0x3a, 0x6e, 0x3e, 0x9b, 0x50, 0x9f, 0x44, 0xcb, 0x50, 0xd4, 0xe4, 0x59, 0x77, 0xa8,
+	0x59, 0xd6, 0x1d, 0x72, 0xdf, 0xc1, 0x56, 0x06, 0x39, 0x26, 0xb1, 0x4f, 0x4c, 0x86, 0x9e, 0x77,
+	0x30, 0x58, 0x8c, 0x14, 0xb5, 0xfe, 0x92, 0x9a, 0xbe, 0xc8, 0x49, 0x1b, 0x8b, 0xc8, 0x17, 0xb5,
+	0x8e, 0x4e, 0x8c, 0x8a, 0xe9, 0x1e, 0xc3, 0xe8, 0x8f, 0x97, 0x24, 0x5e, 0x65, 0xe0, 0x72, 0xdd,
+	0xeb, 0x24, 0x37, 0xa0, 0xf6, 0x36, 0x8e, 0xe6, 0xbc, 0x0f, 0x05, 0x50, 0x49, 0x23, 0x6e, 0x03,
+	0x87, 0x30, 0xd0, 0x91, 0xb2, 0xb8, 0xd1, 0x48, 0xa8, 0x3c, 0x63, 0x2b, 0x4f, 0x6e, 0x75, 0x51,
+	0x95, 0x7d, 0xf9, 0x01, 0xba, 0x7b, 0xb3, 0x59, 0x4c, 0x30, 0xa7, 0x3d, 0xc4, 0xe8, 0xae, 0xb6,
+	0xda, 0xa4, 0xc4, 0xa3, 0x42, 0x8b, 0x2c, 0xbe, 0x43, 0x55, 0x79, 0x87, 0x6a, 0xf2, 0x0e, 0xd5,
+	0xc5, 0x8f, 0x64, 0x39, 0x1f, 0x37, 0xd4, 0x0d, 0xa2, 0x0d, 0x12, 0xf7, 0x02, 0xae, 0x65, 0x24,
+	0x0b, 0x4a, 0xb9, 0x32, 0x9d, 0x5b, 0x5f, 0x49, 0xc8, 0x3c, 0x3d, 0xb0, 0x4e, 0x56, 0xac, 0x7f,
+	0xe3, 0xfe, 0x85, 0x05, 0x3b, 0x26, 0xd2, 0xbc, 0xa5, 0xc2, 0xd1, 0x5a, 0x0a, 0xda, 0x8a, 0x84,
+	0xb6, 0xaa, 0x34, 0x7d, 0x98, 0x21, 0xd4, 0x84, 0x21, 0x50, 0x52, 0xa2, 0xb1, 0x4e, 0x0d, 0xa1,
+	0xa8, 0x58, 0xd4, 0x7b, 0x83, 0xb2, 0xf2, 0x08, 0x7a, 0xc7, 0x24, 0xa5, 0x25, 0xc5, 0x25, 0x0d,
+	0x84, 0xbc, 0x0e, 0xa9, 0x68, 0x75, 0x88, 0xfb, 0x05, 0x74, 0x25, 0x04, 0x6b, 0xe6, 0x36, 0x0f,
+	0x58, 0x91, 0xca, 0xa2, 0xd6, 0xfb, 0xe4, 0xd0, 0xfb, 0xd0, 0x57, 0x17, 0xb2, 0x20, 0xd0, 0x4a,
+	0x38, 0x80, 0x47, 0x81, 0x2b, 0x22, 0xe3, 0x43, 0xa8, 0x33, 0x55, 0x88, 0x9d, 0x29, 0x49, 0xa2,
+	0x7b, 0xd0, 0x5a, 0x78, 0x31, 0x6b, 0x68, 0xd0, 0x86, 0x85, 0x7b, 0x47, 0xd4, 0xe4, 0x74, 0xbd,
+	0xe0, 0x7b, 0x2c, 0x32, 0x12, 0x2b, 0x6f, 0x16, 0xd0, 0x09, 0xee, 0xe7, 0xd0, 0x53, 0xe6, 0x23,
+	0xbb, 0x05, 0xa2, 0x0a, 0x6f, 0x77, 0xc0, 0xe6, 0x79, 0xe9, 0x7a, 0x14, 0xee, 0x41, 0x4f, 0x99,
+	0xbf, 0xe6, 0x0e, 0xfc, 0x4c, 0xd4, 0xe8, 0x0a, 0x19, 0x9d, 0x35, 0xc4, 0xae, 0x4c, 0x5b, 0x13,
+	0xfb, 0x80, 0x6d, 0x13, 0x5d, 0x94, 0x25, 0xae, 0x5f, 0xc1, 0x96, 0x0c, 0xe4, 0x1d, 0x26, 0x6e,
+	0xae, 0x52, 0x87, 0x49, 0xb1, 0x52, 0x86, 0xef, 0x5b, 0xd8, 0xde, 0x9b, 0x4e, 0xe9, 0xc0, 0x4b,
+	0x82, 0x57, 0x4f, 0x49, 0x19, 0xc7, 0x6a, 0x0a, 0x89, 0xd6, 0x8a, 0xa5, 0xec, 0xb0, 0xb0, 0x7a,
+	0x4d, 0x41, 0x1e, 0xc1, 0xb5, 0x23, 0x32, 0x8f, 0xce, 0xc8, 0x8f, 0xa5, 0xfd, 0x2d, 0xec, 0x98,
+	0x10, 0xac, 0x49, 0xfe, 0xdf, 0x2c, 0xb0, 0x8f, 0x48, 0xe0, 0xad, 0xd4, 0x64, 0x41, 0xa9, 0xfd,
+	0xcb, 0x7a, 0xca, 0x72, 0x82, 0x54, 0x5d, 0xf3, 0x72, 0xaa, 0x56, 0xb8, 0x9c, 0xaa, 0xaf, 0x73,
+	0x39, 0xd5, 0xa0, 0x97, 0x53, 0x7f, 0xb0, 0xa0, 0xa7, 0x30, 0xfb, 0x7f, 0xeb, 0x6e, 0xea, 0x9f,
+	0x2b, 0x78, 0xc9, 0x19, 0x78, 0xc5, 0x03, 0x70, 0x2d, 0x75, 0x7e, 0x25, 0xdd, 0x56, 0xb1, 0x5b,
+	0x8f, 0x5b, 0x48, 0xc3, 0x88, 0x51, 0xbb, 0xac, 0xba, 0x97, 0x5d, 0x56, 0x31, 0xde, 0x7f, 0x56,
+	0xbe, 0xf0, 0x7f, 0xfb, 0xae, 0xca, 0x83, 0x81, 0xce, 0xd8, 0xff, 0xf4, 0xbd, 0xd0, 0x2b, 0xd8,
+	0x78, 0x13, 0x2d, 0xa2, 0x20, 0x9a, 0xad, 0x5e, 0x45, 0x53, 0x72, 0x69, 0xe5, 0xe9, 0x62, 0x82,
+	0xea, 0x07, 0xd3, 0x98, 0x84, 0xfc, 0xe4, 0xa1, 0xb6, 0x21, 0xaf, 0x77, 0x3f, 0x01, 0xfb, 0x90,
+	0xa4, 0x02, 0x54, 0x9e, 0x4f, 0x3f, 0x81, 0x9e, 0x32, 0x8f, 0x9f, 0x1c, 0x29, 0x07, 0xc8, 0x47,
+	0x8e, 0xc2, 0x9f, 0xe2, 0x90, 0x7f, 0x6f, 0x41, 0xed, 0xcd, 0xb9, 0x1f, 0x16, 0xf1, 0x33, 0x7f,
+	0x60, 0x66, 0xcc, 0x34, 0xb2, 0x0d, 0x5d, 0x0e, 0x38, 0x23, 0x71, 0x22, 0xdc, 0x90, 0xde, 0x26,
+	0xc5, 0xd4, 0x85, 0xc8, 0x94, 0x9f, 0xda, 0x3b, 0xb0, 0x25, 0x20, 0x62, 0x6a, 0x5d, 0x14, 0x28,
+	0x53, 0x12, 0xa4, 0x1e, 0x3b, 0xac, 0xd1, 0x65, 0xb9, 0xcb, 0xe5, 0x13, 0x59, 0x2e, 0xf3, 0x08,
+	0xeb, 0x25, 0xc9, 0x61, 0xb6, 0xa0, 0x29, 0x26, 0x58, 0x22, 0x07, 0x51, 0xb9, 0xcb, 0x50, 0xb3,
+	0x6e, 0x9b, 0x4b, 0xab, 0x0d, 0x14, 0xad, 0x5c, 0x83, 0xec, 0xba, 0x86, 0xcd, 0xe1, 0xd7, 0x35,
+	0xe9, 0xb9, 0x1f, 0xca, 0x97, 0x2e, 0x54, 0x37, 0x8a, 0xc6, 0x9e, 0xc1, 0x50, 0xb4, 0xf1, 0x28,
+	0xf9, 0x4b, 0x33, 0xfe, 0x85, 0x87, 0x75, 0x54, 0x45, 0xa4, 0x7c, 0x8a, 0xde, 0xdc, 0xaf, 0xc0,
+	0xd6, 0x30, 0xad, 0xcd, 0xc4, 0x3d, 0x0c, 0xa3, 0xa8, 0x60, 0xaa, 0x9d, 0x75, 0x59, 0x70, 0x1f,
+	0x40, 0x4f, 0x59, 0xb6, 0x36, 0xbd, 0x5b, 0x30, 0xf8, 0x0d, 0xe2, 0xb9, 0x4a, 0x66, 0xbc, 0xe1,
+	0x6c, 0x8a, 0x28, 0x86, 0xd5, 0x26, 0xfb, 0xf3, 0x92, 0x30, 0x24, 0x12, 0x94, 0xaa, 0x48, 0x0e,
+	0xbd, 0x78, 0x96, 0x8c, 0x6b, 0xd9, 0x0d, 0x08, 0xab, 0x0f, 0xb3, 0x9b, 0xcd, 0x09, 0x4d, 0x3e,
+	0xf2, 0x8b, 0xc2, 0x3e, 0xb4, 0xc9, 0xc5, 0xc2, 0x8f, 0x49, 0x92, 0xdd, 0x13, 0xe2, 0xed, 0xb3,
+	0x30, 0x2a, 0x2f, 0x1d, 0xb7, 0x04, 0x70, 0x12, 0x61, 0xf5, 0xc4, 0x16, 0xb7, 0x29, 0x10, 0x9b,
+	0x25, 0x24, 0x59, 0x06, 0x29, 0xbf, 0xe1, 0x3c, 0x82, 0xd1, 0x41, 0xf8, 0xc3, 0x92, 0x2c, 0x09,
+	0x17, 0x61, 0xdd, 0x4e, 0xaf, 0x60, 0x9b, 0x09, 0xd1, 0x81, 0x6a, 0x9a, 0x06, 0xbc, 0xa0, 0xb8,
+	0x07, 0x03, 0x1d, 0x27, 0xbf, 0xf5, 0xd0, 0x75, 0xa3, 0x5d, 0x41, 0xf5, 0xf7, 0x26, 0xef, 0xae,
+	0x64, 0x43, 0x41, 0x53, 0x11, 0x6e, 0x9b, 0x2c, 0x27, 0x13, 0x92, 0x30, 0x76, 0x5a, 0x92, 0x94,
+	0x54, 0xab, 0x78, 0xcf, 0x27, 0xa3, 0x5e, 0xf3, 0xe4, 0x7e, 0x08, 0x23, 0xbe, 0x64, 0x9d, 0x4a,
+	0x97, 0xdd, 0x6f, 0xd0, 0xca, 0xc6, 0xdd, 0x83, 0x81, 0xbe, 0x92, 0xdf, 0xd5, 0x64, 0x47, 0xa0,
+	0x55, 0x3c, 0x02, 0x15, 0xe2, 0xb7, 0x61, 0x48, 0xcd, 0x8f, 0x0f, 0x5e, 0xd2, 0x64, 0x20, 0xd0,
+	0x2d, 0x1e, 0xeb, 0x72, 0xe4, 0xa0, 0xf5, 0xe6, 0xc4, 0x9b, 0x2f, 0x3c, 0x7f, 0x16, 0x96, 0xdd,
+	0x57, 0x63, 0x9e, 0x1c, 0x47, 0xb3, 0x18, 0x95, 0x58, 0xa3, 0xe5, 0x59, 0x76, 0x49, 0xc6, 0x0a,
+	0x9e, 0x53, 0xd8, 0x54, 0x0e, 0x63, 0x0d, 0xa9, 0x25, 0xee, 0xd4, 0xc5, 0x29, 0x2e, 0xef, 0x8e,
+	0x1c, 0x04, 0xda, 0xac, 0x65, 0x41, 0x26, 0xef, 0xb0, 0xaa, 0xab, 0x09, 0x63, 0x4a, 0xfc, 0x3f,
+	0x63, 0x07, 0x7f, 0xd5, 0xfd, 0x73, 0xe8, 0x09, 0x4a, 0x7b, 0x71, 0xea, 0xbf, 0xf5, 0x26, 0xa9,
+	0x86, 0xd8, 0x32, 0x5c, 0xe0, 0x1b, 0x23, 0xce, 0x95, 0xc4, 0x0c, 0x0e, 0xe6, 0xfe, 0x9d, 0x05,
+	0x9b, 0x4f, 0xb8, 0x64, 0x18, 0x35, 0x68, 0x45, 0x9f, 0x62, 0x3b, 0x25, 0xe5, 0x86, 0x52, 0x47,
+	0x3a, 0x11, 0x6a, 0x81, 0xc7, 0xe2, 0x3a, 0x7b, 0x00, 0x72, 0x1e, 0x06, 0x11, 0x6b, 0x3e, 0x55,
+	0x29, 0xd0, 0x06, 0xf0, 0xc3, 0x24, 0xf5, 0x82, 0x40, 0x64, 0x66, 0x75, 0x24, 0xc8, 0x61, 0xbc,
+	0x69, 0x55, 0x47, 0x73, 0x65, 0xb7, 0x8d, 0xe3, 0x86, 0xc0, 0x85, 0xbf, 0x97, 0x31, 0xc9, 0xbb,
+	0x56, 0xee, 0x5f, 0x56, 0xa0, 0x25, 0xb8, 0x32, 0xea, 0x5e, 0xf5, 0x4f, 0x55, 0x61, 0x55, 0x83,
+	0xc2, 0x6a, 0xba, 0xc2, 0xea, 0x42, 0x61, 0x59, 0xb3, 0xb6, 0x21, 0xc5, 0xa4, 0x19, 0x49, 0xc6,
+	0xcd, 0xdd, 0x2a, 0x33, 0x0c, 0xfa, 0x9b, 0xc6, 0x99, 0x3a, 0x9e, 0x68, 0x9c, 0xdb, 0xfc, 0x0d,
+	0x46, 0x9b, 0x16, 0xeb, 0x36, 0xc0, 0xdc, 0x0f, 0xd9, 0xb1, 0xc8, 0x5a, 0x69, 0x75, 0x29, 0xc0,
+	0x75, 0x8a, 0x01, 0x6e, 0x83, 0x6e, 0xc9, 0x2e, 0xb3, 0xcc, 0x64, 0xbc, 0x99, 0xe7, 0x81, 0xca,
+	0x7e, 0xb8, 0xdf, 0xe1, 0x8d, 0x01, 0xaa, 0x3a, 0x37, 0xfc, 0xac, 0x7d, 0x5f, 0xe8, 0x4f, 0x4b,
+	0x12, 0x66, 0xa1, 0x8b, 0x5e, 0x4f, 0x57, 0xe9, 0xf5, 0xf4, 0x77, 0x30, 0xd0, 0x71, 0xa1, 0xaf,
+	0x7e, 0x02, 0x2d, 0x8f, 0x1b, 0x1f, 0x3f, 0x27, 0x86, 0x72, 0x3e, 0x9a, 0x19, 0xa6, 0xe9, 0xe2,
+	0x56, 0xe3, 0xca, 0x7d, 0x0e, 0x7d, 0x15, 0x8c, 0x04, 0x6e, 0x41, 0x5b, 0x10, 0x10, 0xd1, 0x60,
+	0x0d, 0x0a, 0xff, 0x1f, 0x76, 0xf6, 0xb9, 0x99, 0x19, 0x64, 0xd7, 0x5d, 0xc4, 0xfd, 0x1d, 0x8c,
+	0x8a, 0xd3, 0xdf, 0x47, 0x3c, 0xa1, 0x2b, 0xa4, 0xbe, 0xa1, 0xbe, 0xb1, 0xcb, 0xee, 0x89, 0xc4,
+	0xde, 0x48, 0xd7, 0x36, 0xc2, 0x38, 0xe5, 0x6b, 0x1b, 0x31, 0x2d, 0xbf, 0x27, 0xca, 0x17, 0xf2,
+	0x7b, 0xa2, 0x82, 0x4d, 0x6b, 0x21, 0x11, 0xb3, 0x44, 0x9d, 0x9a, 0x61, 0x19, 0x76, 0x44, 0x94,
+	0x99, 0x88, 0xfe, 0x0a, 0xae, 0x8c, 0x17, 0x37, 0x62, 0x50, 0xbf, 0xb8, 0x91, 0xe0, 0xfc, 0xe2,
+	0x46, 0xa0, 0x56, 0x2e, 0x6e, 0xcc, 0xb8, 0xbf, 0x85, 0xed, 0x27, 0x51, 0x98, 0xc6, 0x51, 0xb0,
+	0x86, 0x28, 0xe8, 0x2f, 0xde, 0x24, 0xeb, 0x90, 0xb7, 0xdd, 0x27, 0x30, 0x2c, 0xac, 0x7e, 0x6f,
+	0xf1, 0x5e, 0x62, 0x19, 0x85, 0x5e, 0xa9, 0xdb, 0x50, 0xf1, 0x64, 0x73, 0xf1, 0x28, 0xc5, 0xa9,
+	0x72, 0xd5, 0xa1, 0x9e, 0x37, 0x78, 0x41, 0xa6, 0xa3, 0x5b, 0xf3, 0x88, 0x9d, 0xc2, 0x96, 0x56,
+	0x5b, 0x14, 0x9a, 0x67, 0xf4, 0x2c, 0xf7, 0x92, 0xcc, 0x7b, 0xb3, 0x2a, 0xa8, 0x5a, 0x56, 0x05,
+	0x49, 0x3a, 0x63, 0xc7, 0xff, 0x1b, 0x80, 0x97, 0x14, 0xe5, 0xf1, 0x82, 0x4c, 0xb2, 0x67, 0x74,
+	0x19, 0xfa, 0x53, 0x2f, 0x11, 0x9d, 0xe2, 0x96, 0xda, 0x94, 0xe4, 0x83, 0xbc, 0x2f, 0xd9, 0x12,
+	0x4d, 0x4a, 0xda, 0x97, 0x74, 0xff, 0xd5, 0x92, 0x5b, 0xbf, 0x54, 0x0a, 0x63, 0xfc, 0xd9, 0x80,
+	0xda, 0x3c, 0x9a, 0x8a, 0xb8, 0xfc, 0x0b, 0x7c, 0x08, 0x86, 0xbc, 0x88, 0x02, 0x6b, 0x57, 0xed,
+	0xab, 0x52, 0x3c, 0x77, 0x18, 0xbb, 0xbc, 0x88, 0xfc, 0x25, 0x6c, 0xc8, 0xbf, 0xd5, 0xa2, 0xf0,
+	0x86, 0x5a, 0x14, 0xd2, 0x57, 0x0c, 0xb9, 0xb0, 0xb4, 0x20, 0xfc, 0x23, 0xb8, 0x76, 0x4c, 0x52,
+	0x8d, 0x84, 0xd8, 0x71, 0x6c, 0xf3, 0x52, 0x80, 0xb9, 0xcd, 0x4b, 0x87, 0xb0, 0x03, 0x62, 0xc2,
+	0xb0, 0xe6, 0x26, 0x7f, 0x06, 0xd7, 0x0e, 0x4b, 0xe9, 0x1b, 0x34, 0xe6, 0xfe, 0x0a, 0x76, 0x0e,
+	0x4b, 0xc8, 0xad, 0xc3, 0xae, 0x4a, 0xfd, 0x06, 0x5c, 0x47, 0x97, 0xd5, 0xe6, 0x64, 0x1e, 0xfd,
+	0x0a, 0xae, 0x99, 0x87, 0x91, 0xda, 0xc7, 0xd0, 0x64, 0xd4, 0x84, 0x5b, 0x5f, 0x4d, 0xee, 0x2e,
+	0xfc, 0x94, 0x75, 0xdb, 0xde, 0x43, 0xde, 0x47, 0xe0, 0x94, 0xac, 0x59, 0x53, 0xc3, 0x73, 0x71,
+	0xd5, 0xf6, 0x7c, 0x4e, 0x13, 0x40, 0xf5, 0xc9, 0xb7, 0x76, 0x41, 0xab, 0x72, 0x50, 0x15, 0xa7,
+	0x70, 0xde, 0x3e, 0xa9, 0x69, 0xfd, 0x62, 0xfd, 0x7d, 0xb7, 0xfb, 0x35, 0x6c, 0x30, 0x42, 0x47,
+	0x34, 0xc9, 0x46, 0x5e, 0xe2, 0xe8, 0x9c, 0x67, 0x46, 0x57, 0xbc, 0xcc, 0x7e, 0x0e, 0x43, 0xb6,
+	0x56, 0xbb, 0xea, 0xfd, 0x48, 0xbf, 0xa6, 0x94, 0xfa, 0xbf, 0x5c, 0xaa, 0x2e, 0x34, 0xa6, 0xf1,
+	0x2a, 0x5e, 0x72, 0x57, 0x75, 0xf7, 0xc1, 0xd6, 0x50, 0xa1, 0xba, 0x3e, 0x82, 0x26, 0xcb, 0xfd,
+	0x15, 0x44, 0x3a, 0xbf, 0xb9, 0xee, 0x5e, 0x08, 0xdd, 0x1d, 0x5c, 0x50, 0x2a, 0x97, 0xf5, 0x32,
+	0xf2, 0xdb, 0xbb, 0x8a, 0xf9, 0xf6, 0xce, 0x7d, 0x08, 0x43, 0x86, 0xe7, 0xbd, 0x9f, 0x96, 0xec,
+	0x83, 0x7d, 0x70, 0x61, 0x92, 0xa6, 0x54, 0x2d, 0x9c, 0x61, 0x45, 0x9a, 0x03, 0xb0, 0x69, 0xd9,
+	0x40, 0xdf, 0xb9, 0xc9, 0xcf, 0xaf, 0x70, 0xb3, 0x19, 0x8e, 0xf6, 0xd5, 0x6f, 0x09, 0x7f, 0x7e,
+	0x13, 0x80, 0xfd, 0xa6, 0x4f, 0xa1, 0xda, 0x50, 0xdf, 0x3f, 0x7a, 0xfd, 0xea, 0xa0, 0xf7, 0x13,
+	0x1b, 0xa0, 0x71, 0x7c, 0xf0, 0xea, 0xf8, 0xf5, 0x51, 0xcf, 0xfa, 0xf9, 0xd7, 0xd0, 0xca, 0xde,
+	0xe8, 0x6e, 0x42, 0xfb, 0xcd, 0xb3, 0xa3, 0x83, 0xe3, 0x67, 0xaf, 0x5f, 0xec, 0xf7, 0x7e, 0x62,
+	0xdb, 0xd0, 0x3d, 0xda, 0x7b, 0x73, 0xf0, 0xfb, 0xd7, 0x4f, 0x7f, 0xff, 0xe4, 0xd9, 0xde, 0xab,
+	0xc3, 0x83, 0x1e, 0xde, 0xe3, 0x34, 0xf7, 0x1e, 0x1f, 0x1f, 0xbc, 0x7a, 0x72, 0xd0, 0xab, 0xdc,
+	0xfd, 0x8f, 0x0f, 0xa1, 0xf9, 0x92, 0x7d, 0xc4, 0x60, 0xef, 0x43, 0x57, 0x7d, 0xf6, 0x6f, 0x5f,
+	0x63, 0xdd, 0x35, 0xc3, 0xd7, 0x0c, 0xce, 0x8e, 0x69, 0x08, 0x35, 0xb5, 0x9f, 0xb7, 0x16, 0xf2,
+	0xfd, 0xb0, 0xe9, 0x74, 0xc3, 0x97, 0x00, 0xce, 0xa8, 0x38, 0x80, 0x58, 0x0e, 0x61, 0x8b, 0x3d,
+	0xa6, 0xce, 0x5c, 0xd1, 0x76, 0xca, 0xdf, 0xa5, 0x3b, 0x63, 0xe3, 0x18, 0x22, 0xfa, 0x06, 0x3a,
+	0x52, 0x23, 0xd5, 0xde, 0xce, 0xfa, 0x85, 0x4a, 0x1b, 0xd8, 0x19, 0x16, 0xe0, 0x4c, 0x96, 0xae,
+	0xda, 0xc2, 0x13, 0x1a, 0x31, 0xf4, 0x1b, 0x9d, 0x1d, 0xd3, 0x10, 0x67, 0x41, 0xea, 0x96, 0x31,
+	0x16, 0x8a, 0x6d, 0x36, 0x67, 0x58, 0x80, 0xe3, 0xe2, 0xcf, 0xa0, 0xc9, 0x1b, 0x45, 0xb6, 0x2d,
+	0x26, 0xe4, 0x9d, 0x25, 0xa7, 0xa7, 0xc0, 0x70, 0xc1, 0x1e, 0x6c, 0x2a, 0xad, 0x1d, 0x9b, 0xea,
+	0xc6, 0xd4, 0x37, 0x72, 0xb6, 0x0d, 0x23, 0x99, 0xce, 0xb2, 0x5e, 0x8d, 0xd0, 0x99, 0xde, 0xf3,
+	0x71, 0x86, 0x05, 0x38, 0x5b, 0xbc, 0x21, 0xf7, 0x6b, 0xd8, 0xce, 0x1b, 0x3a, 0x38, 0x4e, 0xa1,
+	0x35, 0xfd, 0xb9, 0x85, 0x0a, 0x57, 0x5b, 0x16, 0x4c, 0xe1, 0xc6, 0xd6, 0x88, 0xb3, 0x63, 0x1a,
+	0x42, 0x16, 0x1e, 0x02, 0xe4, 0x6d, 0x06, 0x9b, 0x5a, 0x58, 0xa1, 0xa3, 0xe1, 0x0c, 0x74, 0x30,
+	0xdf, 0x70, 0xb5, 0x61, 0xc0, 0xe8, 0x1b, 0xdb, 0x0f, 0xce, 0x8e, 0x69, 0x88, 0xd1, 0xdf, 0x54,
+	0x7a, 0x06, 0x6c, 0x0b, 0x4c, 0x6d, 0x04, 0x47, 0x6e, 0x3c, 0x7c, 0x6e, 0xd9, 0x77, 0xa1, 0x23,
+	0x85, 0x0d, 0xa6, 0xf9, 0x62, 0x1c, 0x71, 0xf2, 0x27, 0xb4, 0x4c, 0x67, 0x6a, 0xe1, 0xc4, 0x78,
+	0x36, 0x16, 0x66, 0xce, 0x8e, 0x69, 0x08, 0x79, 0xfe, 0x25, 0x6c, 0xc8, 0xb5, 0x11, 0xdb, 0x36,
+	0x43, 0x11, 0xe5, 0x8c, 0x8a, 0x03, 0xb8, 0xfe, 0x3b, 0xe8, 0xe9, 0x15, 0x8e, 0x7d, 0x9d, 0xee,
+	0xb0, 0xb9, 0x4c, 0x72, 0xae, 0x99, 0x07, 0xc5, 0x2e, 0x28, 0x85, 0x09, 0xdf, 0x05, 0x53, 0x95,
+	0xe3, 0xec, 0x98, 0x86, 0x72, 0xb7, 0xcb, 0x50, 0x08, 0xb7, 0xd3, 0xd7, 0x0f, 0x0b, 0x70, 0xee,
+	0x45, 0x4a, 0x81, 0xc1, 0xb6, 0xd0, 0x54, 0x8b, 0x38, 0xdb, 0x86, 0x11, 0x1e, 0xc2, 0xb4, 0x0a,
+	0x81, 0x85, 0x30, 0x73, 0xd1, 0xe1, 0x8c, 0x8d, 0x63, 0x59, 0x14, 0x92, 0xd3, 0x7a, 0x11, 0x85,
+	0x0c, 0x95, 0x83, 0xb3, 0x63, 0x1a, 0x42, 0x2c, 0xbf, 0x06, 0xbb, 0x98, 0x3b, 0xda, 0x37, 0x68,
+	0xf8, 0x2d, 0xcb, 0x0a, 0x9d, 0xeb, 0x65, 0xc3, 0x1c, 0xe3, 0x61, 0x09, 0xc6, 0xc3, 0xcb, 0x31,
+	0x96, 0x65, 0x95, 0xdf, 0xb3, 0x72, 0x4f, 0x1b, 0x4b, 0xec, 0x0f, 0x85, 0x8a, 0x4b, 0xb2, 0x47,
+	0xe7, 0x46, 0xf9, 0x04, 0xc4, 0xfb, 0x5b, 0xf1, 0xcc, 0x50, 0x67, 0x76, 0x97, 0xc5, 0xa0, 0xf2,
+	0x3c, 0xd1, 0xf9, 0xe0, 0x92, 0x19, 0xdc, 0x50, 0x94, 0xe4, 0x87, 0x19, 0x8a, 0x29, 0xb5, 0x72,
+	0xb6, 0x0d, 0x23, 0x1c, 0xc5, 0xc1, 0x45, 0x01, 0xc5, 0xc1, 0x45, 0x19, 0x0a, 0x43, 0x7a, 0x72,
+	0x0f, 0xda, 0xd9, 0x0b, 0x63, 0x5b, 0x58, 0xb4, 0x7a, 0x60, 0xdb, 0x1a, 0x94, 0x3b, 0xbd, 0x7c,
+	0x56, 0xdb, 0x3b, 0xf2, 0x81, 0x20, 0x2f, 0x1e, 0x15, 0x07, 0xf8, 0x7a, 0xf9, 0xd9, 0x2f, 0x5b,
+	0x6f, 0x78, 0x31, 0xec, 0x8c, 0x8a, 0x03, 0xdc, 0x45, 0xa5, 0x67, 0xbc, 0x76, 0xe6, 0x49, 0x9a,
+	0xd4, 0xc3, 0x02, 0x9c, 0xcb, 0x9c, 0xbd, 0x96, 0x60, 0x32, 0xeb, 0xaf, 0x2f, 0x1c, 0x5b, 0x83,
+	0x4a, 0x81, 0x4e, 0x3c, 0x7b, 0xc8, 0x03, 0x9d, 0xf6, 0x82, 0xc2, 0x19, 0x15, 0x07, 0x72, 0x55,
+	0x33, 0x58, 0xa6, 0x6a, 0x35, 0x99, 0xb0, 0x35, 0x28, 0x77, 0x62, 0xf5, 0xd1, 0x15, 0x73, 0x62,
+	0xe3, 0x13, 0x2e, 0x67, 0xc7, 0x34, 0xc4, 0xb1, 0xa8, 0x6f, 0x84, 0x18, 0x16, 0xe3, 0x63, 0x24,
+	0x67, 0xc7, 0x34, 0xc4, 0x1d, 0xb7, 0xf8, 0x64, 0x86, 0x39, 0x6e, 0xe9, 0x2b, 0x1e, 0xe7, 0x7a,
+	0xd9, 0x30, 0xdf, 0x48, 0xe9, 0x6d, 0x06, 0xdb, 0xc8, 0xe2, 0xe3, 0x0e, 0x67, 0x58, 0x80, 0xf3,
+	0xc5, 0xd2, 0xb3, 0x0b, 0x5b, 0xca, 0x4a, 0x8a, 0x8b, 0x0b, 0xef, 0x33, 0xbe, 0x81, 0x8e, 0xf4,
+	0xaa, 0x82, 0x2d, 0x2e, 0xbe, 0xc6, 0x70, 0x86, 0x05, 0x38, 0x4f, 0x14, 0xf2, 0x67, 0x14, 0x76,
+	0xb6, 0xe1, 0xca, 0x5b, 0x0b, 0x67, 0xa0, 0x83, 0x79, 0x70, 0xd7, 0xde, 0x41, 0xb0, 0xe0, 0x6e,
+	0x7e, 0x5a, 0xe1, 0x8c, 0x8d, 0x63, 0x7c, 0x2f, 0x8a, 0x8f, 0x1a, 0xd8, 0x5e, 0x94, 0xbe, 0x96,
+	0x70, 0xae, 0x97, 0x0d, 0x73, 0xa1, 0xf2, 0x6f, 0xcb, 0x98, 0x50, 0x85, 0x0f, 0xd3, 0x9c, 0x81,
+	0x0e, 0xce, 0x73, 0x4d, 0xba, 0x4c, 0x98, 0xb0, 0xbc, 0xa6, 0xa7, 0xc0, 0x38, 0xa9, 0xfc, 0x93,
+	0x30, 0x5b, 0x0a, 0x12, 0x05, 0x52, 0xfa, 0x97, 0x63, 0x0f, 0x01, 0xd8, 0x6e, 0xe4, 0x2b, 0x0b,
+	0x9f, 0x89, 0x39, 0x03, 0x1d, 0xcc, 0xfd, 0x2f, 0xfb, 0xc2, 0xcb, 0xce, 0x22, 0x83, 0xfc, 0x11,
+	0x98, 0x63, 0x6b, 0x50, 0xee, 0xf6, 0xf2, 0x57, 0x59, 0xcc, 0xed, 0x0d, 0x9f, 0x7c, 0x39, 0xa3,
+	0xe2, 0x00, 0x0f, 0xd2, 0xca, 0x17, 0x44, 0x2c, 0x48, 0x9b, 0x3e, 0x45, 0x72, 0xb6, 0x0d, 0x23,
+	0x52, 0xe4, 0xe1, 0x30, 0x29, 0xf2, 0x68, 0x1f, 0x18, 0x39, 0xa3, 0xe2, 0x00, 0x67, 0x41, 0xf9,
+	0x16, 0x88, 0xb1, 0x60, 0xfa, 0x92, 0xc8, 0xd9, 0x36, 0x8c, 0x64, 0xde, 0x92, 0x7d, 0xf0, 0x23,
+	0xbc, 0x45, 0xff, 0x4c, 0xc8, 0x19, 0x16, 0xe0, 0x4a, 0x5a, 0x96, 0x3d, 0x84, 0x97, 0xd2, 0x32,
+	0xed, 0x01, 0xbf, 0xb3, 0x63, 0x1a, 0xe2, 0x58, 0xd4, 0x8f, 0x05, 0x44, 0xba, 0x3a, 0x2d, 0xc3,
+	0x62, 0xfa, 0xb6, 0x60, 0x1f, 0xba, 0x4c, 0x3c, 0x15, 0x8b, 0xf1, 0x63, 0x02, 0x67, 0xc7, 0x34,
+	0x24, 0x65, 0x79, 0x02, 0x28, 0x65, 0x79, 0xfa, 0xa7, 0x02, 0xce, 0xb6, 0x61, 0x84, 0xa3, 0x50,
+	0xde, 0xb0, 0x33, 0x14, 0xa6, 0x57, 0xf4, 0xce, 0xb6, 0x61, 0x24, 0x3b, 0x45, 0xf3, 0xe7, 0xe2,
+	0xe2, 0x14, 0x2d, 0xbc, 0x67, 0x77, 0x46, 0xc5, 0x81, 0x45, 0xb0, 0x7a, 0x5c, 0xfb, 0x5d, 0x65,
+	0x71, 0x72, 0xd2, 0xa0, 0xff, 0x83, 0xe0, 0x8b, 0xff, 0x1e, 0x00, 0x28, 0x7e, 0xe1, 0xc5, 0x97,
+	0x40, 0x00, 0x00,
 }
+
+
+
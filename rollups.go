@@ -0,0 +1,380 @@
+package iotmonitor
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+	"golang.org/x/net/context"
+)
+
+// Telemetry resolutions
+const (
+	ResolutionRaw  = "raw"
+	ResolutionHour = "hour"
+	ResolutionDay  = "day"
+)
+
+// Raw samples and hourly rollups are compacted once they are older than
+// their retention; daily rollups are kept forever. Queries pick the finest
+// resolution that is still retained for the whole range and keeps the number
+// of points reasonable.
+const (
+	maxRawSpan    = 6 * time.Hour
+	maxHourlySpan = 14 * 24 * time.Hour
+)
+
+// Retention says how long telemetry is kept: raw samples for Raw and hourly
+// rollups for Hourly. Daily rollups are kept for as long as the device is.
+// A zero Retention keeps raw samples for 48 hours and hourly rollups for 90
+// days.
+type Retention struct {
+	Raw    time.Duration
+	Hourly time.Duration
+}
+
+func (r Retention) validate() error {
+	if r == (Retention{}) {
+		return nil
+	}
+	if r.Raw <= 0 {
+		return errors.New("raw retention must be positive")
+	}
+	if r.Hourly < r.Raw {
+		return errors.New("hourly retention must be at least the raw retention")
+	}
+	return nil
+}
+
+// durations returns the raw and hourly retention, or their defaults.
+func (r Retention) durations() (raw, hourly time.Duration) {
+	if r == (Retention{}) {
+		return 48 * time.Hour, 90 * 24 * time.Hour
+	}
+	return r.Raw, r.Hourly
+}
+
+var rollupResolutions = map[string]time.Duration{
+	ResolutionHour: time.Hour,
+	ResolutionDay:  24 * time.Hour,
+}
+
+// TelemetryPoint summarises a metric over one bucket. Raw points have a
+// Count of 1 and every statistic equal to the reported value, and carry the
+// value itself when it isn't a plain double. Strings and vectors have no
+// statistics, so their raw points have a Count of 0 and they are left out
+// of rollups.
+type TelemetryPoint struct {
+	Timestamp int64           `json:"timestamp"` // start of the bucket
+	Min       float64         `json:"min"`
+	Max       float64         `json:"max"`
+	Avg       float64         `json:"avg"`
+	Last      float64         `json:"last"`
+	Count     int64           `json:"count"`
+	Value     *TelemetryValue `json:"value,omitempty"`
+}
+
+// TelemetrySeries is a metric's history at a single resolution.
+type TelemetrySeries struct {
+	DeviceID   uint64           `json:"device_id"`
+	Metric     string           `json:"metric"`
+	Resolution string           `json:"resolution"`
+	Points     []TelemetryPoint `json:"points"`
+}
+
+var errInvalidRange = errors.New("invalid time range")
+
+func metricsKey(id uint64) string {
+	return fmt.Sprintf("telemetry:metrics:%d", id)
+}
+
+func rawKey(id uint64, metric string) string {
+	return fmt.Sprintf("telemetry:raw:%d:%s", id, metric)
+}
+
+// rollupIndexKey is a sorted set of the bucket start times that have a rollup.
+func rollupIndexKey(resolution string, id uint64, metric string) string {
+	return fmt.Sprintf("rollups:%s:%d:%s", resolution, id, metric)
+}
+
+func rollupKey(resolution string, id uint64, metric string, bucket int64) string {
+	return fmt.Sprintf("rollup:%s:%d:%s:%d", resolution, id, metric, bucket)
+}
+
+func bucketStart(ts int64, size time.Duration) int64 {
+	ms := int64(size / time.Millisecond)
+	return ts - ts%ms
+}
+
+// rollupScript folds a value into a bucket's aggregates atomically.
+// KEYS: rollup hash, bucket index. ARGV: value, timestamp, bucket start.
+var rollupScript = redis.NewScript(2, `
+local min = redis.call('HGET', KEYS[1], 'min')
+if not min or tonumber(ARGV[1]) < tonumber(min) then
+	redis.call('HSET', KEYS[1], 'min', ARGV[1])
+end
+local max = redis.call('HGET', KEYS[1], 'max')
+if not max or tonumber(ARGV[1]) > tonumber(max) then
+	redis.call('HSET', KEYS[1], 'max', ARGV[1])
+end
+redis.call('HINCRBYFLOAT', KEYS[1], 'sum', ARGV[1])
+redis.call('HINCRBY', KEYS[1], 'count', 1)
+local lastTs = redis.call('HGET', KEYS[1], 'last_ts')
+if not lastTs or tonumber(ARGV[2]) >= tonumber(lastTs) then
+	redis.call('HMSET', KEYS[1], 'last', ARGV[1], 'last_ts', ARGV[2])
+end
+redis.call('ZADD', KEYS[2], ARGV[3], ARGV[3])
+return 1
+`)
+
+// recordTelemetry stores the raw readings and folds them into the hourly and
+// daily rollups.
+func recordTelemetry(c redis.Conn, id uint64, ts int64, readings map[string]TelemetryValue) error {
+	for metric, v := range readings {
+		stored, err := v.encodeStored()
+		if err != nil {
+			return err
+		}
+		if _, err := c.Do("SADD", metricsKey(id), metric); err != nil {
+			return err
+		}
+		if _, err := c.Do("ZADD", rawKey(id, metric), ts, fmt.Sprintf("%d:%s", ts, stored)); err != nil {
+			return err
+		}
+		f, ok := v.Float()
+		if !ok {
+			continue
+		}
+		value := strconv.FormatFloat(f, 'g', -1, 64)
+		for resolution, size := range rollupResolutions {
+			bucket := bucketStart(ts, size)
+			key := rollupKey(resolution, id, metric, bucket)
+			index := rollupIndexKey(resolution, id, metric)
+			if _, err := rollupScript.Do(c, key, index, value, ts, bucket); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func chooseResolution(r Retention, from, to, now int64) string {
+	span := time.Duration(to-from) * time.Millisecond
+	age := time.Duration(now-from) * time.Millisecond
+	rawRetention, hourlyRetention := r.durations()
+	switch {
+	case span <= maxRawSpan && age <= rawRetention:
+		return ResolutionRaw
+	case span <= maxHourlySpan && age <= hourlyRetention:
+		return ResolutionHour
+	default:
+		return ResolutionDay
+	}
+}
+
+func readRaw(c redis.Conn, id uint64, metric string, from, to int64) ([]TelemetryPoint, error) {
+	members, err := redis.Strings(c.Do("ZRANGEBYSCORE", rawKey(id, metric), from, to))
+	if err != nil {
+		return nil, err
+	}
+	points := make([]TelemetryPoint, 0, len(members))
+	for _, m := range members {
+		parts := strings.SplitN(m, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		ts, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		v, err := decodeStored(parts[1])
+		if err != nil {
+			continue
+		}
+		p := TelemetryPoint{Timestamp: ts}
+		if f, ok := v.Float(); ok {
+			p.Min, p.Max, p.Avg, p.Last, p.Count = f, f, f, f, 1
+		}
+		if v.Type != TelemetryDouble || v.Unit != "" {
+			p.Value = &v
+		}
+		points = append(points, p)
+	}
+	return points, nil
+}
+
+type rollupRecord struct {
+	Min   float64 `redis:"min"`
+	Max   float64 `redis:"max"`
+	Sum   float64 `redis:"sum"`
+	Count int64   `redis:"count"`
+	Last  float64 `redis:"last"`
+}
+
+func readRollups(c redis.Conn, resolution string, id uint64, metric string, from, to int64) ([]TelemetryPoint, error) {
+	first := bucketStart(from, rollupResolutions[resolution])
+	buckets, err := redis.Int64s(c.Do("ZRANGEBYSCORE", rollupIndexKey(resolution, id, metric), first, to))
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range buckets {
+		if err := c.Send("HGETALL", rollupKey(resolution, id, metric, b)); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.Flush(); err != nil {
+		return nil, err
+	}
+
+	points := make([]TelemetryPoint, 0, len(buckets))
+	for _, b := range buckets {
+		v, err := redis.Values(c.Receive())
+		if err != nil {
+			return nil, err
+		}
+		if len(v) == 0 {
+			continue // compacted since the index was read
+		}
+		var r rollupRecord
+		if err := redis.ScanStruct(v, &r); err != nil {
+			return nil, err
+		}
+		p := TelemetryPoint{Timestamp: b, Min: r.Min, Max: r.Max, Last: r.Last, Count: r.Count}
+		if r.Count > 0 {
+			p.Avg = r.Sum / float64(r.Count)
+		}
+		points = append(points, p)
+	}
+	return points, nil
+}
+
+// QueryTelemetry returns a metric's history between from and to (Unix
+// milliseconds) at the resolution best suited to the range. A zero to means
+// now and a zero from means a day before to.
+func (s monitorService) QueryTelemetry(ctx context.Context, id uint64, metric string, from, to int64) (TelemetrySeries, error) {
+	now := makeTimestamp()
+	if to == 0 {
+		to = now
+	}
+	if from == 0 {
+		from = to - int64(24*time.Hour/time.Millisecond)
+	}
+	if from > to || metric == "" {
+		return TelemetrySeries{}, errInvalidRange
+	}
+
+	c, err := s.store.dial()
+	if err != nil {
+		return TelemetrySeries{}, err
+	}
+	defer c.Close()
+
+	if _, err := readDevice(c, id); err != nil {
+		return TelemetrySeries{}, err
+	}
+
+	series := TelemetrySeries{DeviceID: id, Metric: metric, Resolution: chooseResolution(s.retention, from, to, now)}
+	if series.Resolution == ResolutionRaw {
+		series.Points, err = readRaw(c, id, metric, from, to)
+	} else {
+		series.Points, err = readRollups(c, series.Resolution, id, metric, from, to)
+	}
+	return series, err
+}
+
+// RunRollupCompactor drops raw samples and hourly rollups that have aged out
+// of their retention every interval until ctx is cancelled.
+func RunRollupCompactor(ctx context.Context, store StoreConfig, r Retention, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := compactTelemetry(store, r, makeTimestamp()); err != nil {
+				fmt.Println("Failed to compact telemetry")
+				fmt.Println(err)
+			}
+		}
+	}
+}
+
+// deleteTelemetry drops a device's raw samples and rollups.
+func deleteTelemetry(c redis.Conn, id uint64) error {
+	metrics, err := redis.Strings(c.Do("SMEMBERS", metricsKey(id)))
+	if err != nil {
+		return err
+	}
+	for _, metric := range metrics {
+		for resolution := range rollupResolutions {
+			index := rollupIndexKey(resolution, id, metric)
+			buckets, err := redis.Int64s(c.Do("ZRANGE", index, 0, -1))
+			if err != nil {
+				return err
+			}
+			for _, b := range buckets {
+				if _, err := c.Do("DEL", rollupKey(resolution, id, metric, b)); err != nil {
+					return err
+				}
+			}
+			if _, err := c.Do("DEL", index); err != nil {
+				return err
+			}
+		}
+		if _, err := c.Do("DEL", rawKey(id, metric)); err != nil {
+			return err
+		}
+	}
+	_, err = c.Do("DEL", metricsKey(id))
+	return err
+}
+
+func compactTelemetry(store StoreConfig, r Retention, now int64) error {
+	c, err := store.dial()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	ids, err := redis.Values(c.Do("SMEMBERS", "devices"))
+	if err != nil {
+		return err
+	}
+	rawRetention, hourlyRetention := r.durations()
+	rawCutoff := now - int64(rawRetention/time.Millisecond)
+	hourlyCutoff := now - int64(hourlyRetention/time.Millisecond)
+	for _, raw := range ids {
+		id, err := redis.Uint64(raw, nil)
+		if err != nil {
+			return err
+		}
+		metrics, err := redis.Strings(c.Do("SMEMBERS", metricsKey(id)))
+		if err != nil {
+			return err
+		}
+		for _, metric := range metrics {
+			if _, err := c.Do("ZREMRANGEBYSCORE", rawKey(id, metric), "-inf", fmt.Sprintf("(%d", rawCutoff)); err != nil {
+				return err
+			}
+
+			index := rollupIndexKey(ResolutionHour, id, metric)
+			buckets, err := redis.Int64s(c.Do("ZRANGEBYSCORE", index, "-inf", fmt.Sprintf("(%d", hourlyCutoff)))
+			if err != nil {
+				return err
+			}
+			for _, b := range buckets {
+				if _, err := c.Do("DEL", rollupKey(ResolutionHour, id, metric, b)); err != nil {
+					return err
+				}
+				if _, err := c.Do("ZREM", index, b); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
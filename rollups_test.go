@@ -0,0 +1,72 @@
+package iotmonitor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestChooseResolution(t *testing.T) {
+	const now = 1500000000000
+	ago := func(d time.Duration) int64 { return now - int64(d/time.Millisecond) }
+	short := Retention{Raw: 12 * time.Hour, Hourly: 7 * 24 * time.Hour}
+	tests := []struct {
+		name      string
+		retention Retention
+		from, to  int64
+		want      string
+	}{
+		{"last hour", Retention{}, ago(time.Hour), now, ResolutionRaw},
+		{"longest raw span", Retention{}, ago(maxRawSpan), now, ResolutionRaw},
+		{"just over the raw span", Retention{}, ago(maxRawSpan + time.Minute), now, ResolutionHour},
+		{"short range past raw retention", Retention{}, ago(49 * time.Hour), ago(48 * time.Hour), ResolutionHour},
+		{"last week", Retention{}, ago(7 * 24 * time.Hour), now, ResolutionHour},
+		{"longest hourly span", Retention{}, ago(maxHourlySpan), now, ResolutionHour},
+		{"last month", Retention{}, ago(30 * 24 * time.Hour), now, ResolutionDay},
+		{"short range past hourly retention", Retention{}, ago(91 * 24 * time.Hour), ago(90 * 24 * time.Hour), ResolutionDay},
+		{"within configured raw retention", short, ago(12 * time.Hour), ago(11 * time.Hour), ResolutionRaw},
+		{"past configured raw retention", short, ago(13 * time.Hour), ago(12 * time.Hour), ResolutionHour},
+		{"past configured hourly retention", short, ago(8 * 24 * time.Hour), ago(7 * 24 * time.Hour), ResolutionDay},
+	}
+	for _, tt := range tests {
+		if got := chooseResolution(tt.retention, tt.from, tt.to, now); got != tt.want {
+			t.Errorf("%s: chooseResolution() = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRetentionValidate(t *testing.T) {
+	tests := []struct {
+		raw, hourly time.Duration
+		wantErr     bool
+	}{
+		{0, 0, false},
+		{time.Hour, 24 * time.Hour, false},
+		{time.Hour, time.Hour, false},
+		{0, time.Hour, true},
+		{2 * time.Hour, time.Hour, true},
+		{time.Hour, 0, true},
+	}
+	for _, tt := range tests {
+		if err := (Retention{Raw: tt.raw, Hourly: tt.hourly}).validate(); (err != nil) != tt.wantErr {
+			t.Errorf("validate() of %s, %s = %v, want error %v", tt.raw, tt.hourly, err, tt.wantErr)
+		}
+	}
+}
+
+func TestBucketStart(t *testing.T) {
+	tests := []struct {
+		ts   int64
+		size time.Duration
+		want int64
+	}{
+		{3600000, time.Hour, 3600000},
+		{3600001, time.Hour, 3600000},
+		{7199999, time.Hour, 3600000},
+		{86400000*3 + 5, 24 * time.Hour, 86400000 * 3},
+	}
+	for _, tt := range tests {
+		if got := bucketStart(tt.ts, tt.size); got != tt.want {
+			t.Errorf("bucketStart(%d, %s) = %d, want %d", tt.ts, tt.size, got, tt.want)
+		}
+	}
+}
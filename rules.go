@@ -0,0 +1,692 @@
+package iotmonitor
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+	"golang.org/x/net/context"
+)
+
+// Rule kinds
+const (
+	RuleThreshold    = "threshold"
+	RuleRateOfChange = "rate_of_change"
+	RuleAbsence      = "absence"
+)
+
+// Alert states
+const (
+	AlertFiring   = "firing"
+	AlertResolved = "resolved"
+)
+
+// Alert sources
+const (
+	AlertSourceRule     = "rule"
+	AlertSourceGeofence = "geofence"
+	AlertSourceBattery  = "battery" // predicted to run flat
+)
+
+// Rule describes a condition evaluated against the status and telemetry a
+// device reports. Metric is either a telemetry reading name or one of the
+// status metrics (battery, latitude, longitude, altitude, speed, heading,
+// climb_rate). DeviceID,
+// DeviceType and Owner scope the rule; zero values match every device.
+type Rule struct {
+	ID         uint64  `json:"id" redis:"id"`
+	Name       string  `json:"name" redis:"name"`
+	Kind       string  `json:"kind" redis:"kind"`
+	Metric     string  `json:"metric" redis:"metric"`
+	Operator   string  `json:"operator,omitempty" redis:"operator"`
+	Threshold  float64 `json:"threshold" redis:"threshold"`
+	Window     int64   `json:"window,omitempty" redis:"window"` // seconds without data before an absence rule fires
+	DeviceID   uint64  `json:"device_id,omitempty" redis:"device_id"`
+	DeviceType string  `json:"device_type,omitempty" redis:"device_type"`
+	Owner      string  `json:"owner,omitempty" redis:"owner"`
+}
+
+// Alert tracks the state of a single rule, geofence or battery prediction
+// for a single device. Source says which; a geofence alert with no
+// GeofenceID is for a device outside all of its permitted zones.
+type Alert struct {
+	Source     string  `json:"source,omitempty" redis:"source"`
+	RuleID     uint64  `json:"rule_id,omitempty" redis:"rule_id"`
+	GeofenceID uint64  `json:"geofence_id,omitempty" redis:"geofence_id"`
+	DeviceID   uint64  `json:"device_id" redis:"device_id"`
+	State      string  `json:"state" redis:"state"`
+	Value      float64 `json:"value" redis:"value"`
+	Message    string  `json:"message" redis:"message"`
+	FiredAt    int64   `json:"fired_at" redis:"fired_at"`
+	ResolvedAt int64   `json:"resolved_at,omitempty" redis:"resolved_at"`
+}
+
+var (
+	errRuleNotFound = errors.New("rule not found")
+	errRuleInvalid  = errors.New("invalid rule")
+)
+
+var statusMetrics = map[string]bool{
+	"battery":    true,
+	"latitude":   true,
+	"longitude":  true,
+	"altitude":   true,
+	"speed":      true,
+	"heading":    true,
+	"climb_rate": true,
+}
+
+// sample is one set of readings taken at a point in time (milliseconds).
+// Telemetry readings arrive piecemeal, so times records when each was last
+// taken where that differs from timestamp.
+type sample struct {
+	values    map[string]float64
+	timestamp int64
+	times     map[string]int64
+}
+
+// at returns when the metric was taken.
+func (s sample) at(metric string) int64 {
+	if t, ok := s.times[metric]; ok {
+		return t
+	}
+	return s.timestamp
+}
+
+func (s statusRecord) sample() sample {
+	if s.Timestamp == 0 {
+		return sample{}
+	}
+	return sample{
+		values: map[string]float64{
+			"battery":    float64(s.Battery),
+			"latitude":   float64(s.Latitude),
+			"longitude":  float64(s.Longitude),
+			"altitude":   float64(s.Altitude),
+			"speed":      s.Speed,
+			"heading":    s.Heading,
+			"climb_rate": s.ClimbRate,
+		},
+		timestamp: s.Timestamp,
+	}
+}
+
+func (r Rule) validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return fmt.Errorf("%v: name is required", errRuleInvalid)
+	}
+	switch r.Kind {
+	case RuleThreshold, RuleRateOfChange:
+		if r.Metric == "" {
+			return fmt.Errorf("%v: metric is required", errRuleInvalid)
+		}
+		if _, err := compare(r.Operator, 0, 0); err != nil {
+			return err
+		}
+	case RuleAbsence:
+		if r.Window <= 0 {
+			return fmt.Errorf("%v: window must be positive", errRuleInvalid)
+		}
+	default:
+		return fmt.Errorf("%v: unknown kind %q", errRuleInvalid, r.Kind)
+	}
+	return nil
+}
+
+func (r Rule) appliesTo(d deviceRecord) bool {
+	if r.DeviceID != 0 && r.DeviceID != d.ID {
+		return false
+	}
+	if r.DeviceType != "" && r.DeviceType != d.DeviceType {
+		return false
+	}
+	if r.Owner != "" && r.Owner != d.Owner {
+		return false
+	}
+	return true
+}
+
+func compare(op string, v, threshold float64) (bool, error) {
+	switch op {
+	case ">":
+		return v > threshold, nil
+	case ">=":
+		return v >= threshold, nil
+	case "<":
+		return v < threshold, nil
+	case "<=":
+		return v <= threshold, nil
+	case "==":
+		return v == threshold, nil
+	case "!=":
+		return v != threshold, nil
+	}
+	return false, fmt.Errorf("%v: unknown operator %q", errRuleInvalid, op)
+}
+
+func ruleKey(id uint64) string {
+	return fmt.Sprintf("rule:%d", id)
+}
+
+func alertMember(ruleID, deviceID uint64) string {
+	return fmt.Sprintf("%d:%d", ruleID, deviceID)
+}
+
+func readRule(c redis.Conn, id uint64) (Rule, error) {
+	var r Rule
+	v, err := redis.Values(c.Do("HGETALL", ruleKey(id)))
+	if err != nil {
+		return r, err
+	}
+	if len(v) == 0 {
+		return r, errRuleNotFound
+	}
+	err = redis.ScanStruct(v, &r)
+	return r, err
+}
+
+func readRules(c redis.Conn) ([]Rule, error) {
+	ids, err := redis.Values(c.Do("SMEMBERS", "rules"))
+	if err != nil {
+		return nil, err
+	}
+	var rules []Rule
+	for _, id := range ids {
+		n, err := redis.Uint64(id, nil)
+		if err != nil {
+			return nil, err
+		}
+		r, err := readRule(c, n)
+		if err == errRuleNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// evaluateRules checks every rule scoped to the device against a freshly
+// written sample and returns the alerts whose state changed as a result.
+func evaluateRules(c redis.Conn, deviceID uint64, prev, cur sample) ([]Alert, error) {
+	rules, err := readRules(c)
+	if err != nil || len(rules) == 0 {
+		return nil, err
+	}
+	device, err := readDevice(c, deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []Alert
+	for _, r := range rules {
+		if !r.appliesTo(device) {
+			continue
+		}
+		var firing bool
+		var value float64
+		switch r.Kind {
+		case RuleThreshold:
+			v, ok := cur.values[r.Metric]
+			if !ok {
+				continue
+			}
+			value = v
+			firing, _ = compare(r.Operator, v, r.Threshold)
+		case RuleRateOfChange:
+			v, ok := cur.values[r.Metric]
+			pv, pok := prev.values[r.Metric]
+			elapsed := float64(cur.at(r.Metric)-prev.at(r.Metric)) / 1000
+			if !ok || !pok || elapsed <= 0 {
+				continue
+			}
+			value = (v - pv) / elapsed
+			firing, _ = compare(r.Operator, value, r.Threshold)
+		case RuleAbsence:
+			// Fresh data for the watched metric clears an absence alert.
+			if _, ok := cur.values[r.Metric]; r.Metric != "" && !ok {
+				continue
+			}
+		}
+		a, err := setAlertState(c, r, deviceID, firing, value, cur.timestamp)
+		if err != nil {
+			return changed, err
+		}
+		if a != nil {
+			changed = append(changed, *a)
+		}
+	}
+	return changed, nil
+}
+
+// setAlertState records the outcome of a rule evaluation and returns the
+// alert if it transitioned between firing and resolved.
+func setAlertState(c redis.Conn, r Rule, deviceID uint64, firing bool, value float64, now int64) (*Alert, error) {
+	next := Alert{Source: AlertSourceRule, RuleID: r.ID, DeviceID: deviceID, Value: value, Message: alertMessage(r, value)}
+	return transitionAlert(c, alertMember(r.ID, deviceID), next, firing, now)
+}
+
+// transitionAlert moves the alert stored under member to the firing or
+// resolved state, returning it if the state changed. A resolved alert keeps
+// the message it fired with.
+func transitionAlert(c redis.Conn, member string, next Alert, firing bool, now int64) (*Alert, error) {
+	key := "alert:" + member
+	var a Alert
+	v, err := redis.Values(c.Do("HGETALL", key))
+	if err != nil {
+		return nil, err
+	}
+	if len(v) > 0 {
+		if err := redis.ScanStruct(v, &a); err != nil {
+			return nil, err
+		}
+	}
+
+	wasFiring := a.State == AlertFiring
+	if firing == wasFiring {
+		if firing {
+			_, err = c.Do("HSET", key, "value", next.Value)
+		}
+		return nil, err
+	}
+
+	a.Source = next.Source
+	a.RuleID = next.RuleID
+	a.GeofenceID = next.GeofenceID
+	a.DeviceID = next.DeviceID
+	a.Value = next.Value
+	if firing {
+		a.State = AlertFiring
+		a.Message = next.Message
+		a.FiredAt = now
+		a.ResolvedAt = 0
+	} else {
+		a.State = AlertResolved
+		a.ResolvedAt = now
+	}
+
+	if _, err := c.Do("HMSET", redis.Args{}.Add(key).AddFlat(&a)...); err != nil {
+		return nil, err
+	}
+	if firing {
+		_, err = c.Do("SADD", "alerts:active", member)
+	} else {
+		_, err = c.Do("SREM", "alerts:active", member)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func alertMessage(r Rule, value float64) string {
+	switch r.Kind {
+	case RuleAbsence:
+		metric := r.Metric
+		if metric == "" {
+			metric = "any data"
+		}
+		return fmt.Sprintf("%s: no %s for %ds", r.Name, metric, r.Window)
+	case RuleRateOfChange:
+		return fmt.Sprintf("%s: %s changing at %g/s (%s %g)", r.Name, r.Metric, value, r.Operator, r.Threshold)
+	}
+	return fmt.Sprintf("%s: %s is %g (%s %g)", r.Name, r.Metric, value, r.Operator, r.Threshold)
+}
+
+// lastReport returns when the device last reported metric, or either stream
+// when metric is empty. Telemetry written before readings were timed
+// separately falls back to the stream's timestamp.
+func lastReport(c redis.Conn, deviceID uint64, metric string) (int64, error) {
+	var status, telemetry int64
+	var err error
+	if metric == "" || statusMetrics[metric] {
+		status, err = redis.Int64(c.Do("HGET", fmt.Sprintf("status:%d", deviceID), "timestamp"))
+		if err != nil && err != redis.ErrNil {
+			return 0, err
+		}
+	}
+	if metric != "" && !statusMetrics[metric] {
+		telemetry, err = redis.Int64(c.Do("HGET", telemetryTimesKey(deviceID), metric))
+		if err != nil && err != redis.ErrNil {
+			return 0, err
+		}
+	}
+	if metric == "" || (!statusMetrics[metric] && telemetry == 0) {
+		telemetry, err = redis.Int64(c.Do("HGET", fmt.Sprintf("telemetry:%d", deviceID), "timestamp"))
+		if err != nil && err != redis.ErrNil {
+			return 0, err
+		}
+	}
+	if status > telemetry {
+		return status, nil
+	}
+	return telemetry, nil
+}
+
+// sweepAbsence evaluates absence-of-data rules, which by definition can't be
+// triggered from the write path. Devices that have never reported are skipped.
+func sweepAbsence(store StoreConfig) ([]Alert, error) {
+	c, err := store.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	rules, err := readRules(c)
+	if err != nil {
+		return nil, err
+	}
+	var absence []Rule
+	for _, r := range rules {
+		if r.Kind == RuleAbsence {
+			absence = append(absence, r)
+		}
+	}
+	if len(absence) == 0 {
+		return nil, nil
+	}
+
+	ids, err := redis.Values(c.Do("SMEMBERS", "devices"))
+	if err != nil {
+		return nil, err
+	}
+	now := makeTimestamp()
+	var changed []Alert
+	for _, raw := range ids {
+		id, err := redis.Uint64(raw, nil)
+		if err != nil {
+			return changed, err
+		}
+		device, err := readDevice(c, id)
+		if err == errDeviceNotFound {
+			continue
+		}
+		if err != nil {
+			return changed, err
+		}
+		for _, r := range absence {
+			if !r.appliesTo(device) {
+				continue
+			}
+			seen, err := lastReport(c, id, r.Metric)
+			if err != nil {
+				return changed, err
+			}
+			if seen == 0 {
+				continue
+			}
+			silent := float64(now-seen) / 1000
+			a, err := setAlertState(c, r, id, silent > float64(r.Window), silent, now)
+			if err != nil {
+				return changed, err
+			}
+			if a != nil {
+				changed = append(changed, *a)
+			}
+		}
+	}
+	return changed, nil
+}
+
+// RunAbsenceSweeper evaluates absence-of-data rules every interval until the
+// context is cancelled, publishing alert transitions to the bus.
+func RunAbsenceSweeper(ctx context.Context, store StoreConfig, bus *EventBus, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			alerts, err := sweepAbsence(store)
+			if err != nil {
+				fmt.Println("Failed to sweep absence rules")
+				fmt.Println(err)
+			}
+			for _, a := range alerts {
+				bus.Publish(alertEvent(a))
+			}
+		}
+	}
+}
+
+func (s monitorService) CreateRule(ctx context.Context, rule Rule) (uint64, error) {
+	if err := rule.validate(); err != nil {
+		return 0, err
+	}
+	c, err := s.store.dial()
+	if err != nil {
+		return 0, err
+	}
+	defer c.Close()
+
+	rule.ID, err = redis.Uint64(c.Do("INCR", "id:rules"))
+	if err != nil {
+		return 0, err
+	}
+	if _, err := c.Do("HMSET", redis.Args{}.Add(ruleKey(rule.ID)).AddFlat(&rule)...); err != nil {
+		return 0, err
+	}
+	if _, err := c.Do("SADD", "rules", rule.ID); err != nil {
+		return 0, err
+	}
+	return rule.ID, nil
+}
+
+func (s monitorService) GetRule(ctx context.Context, id uint64) (Rule, error) {
+	c, err := s.store.dial()
+	if err != nil {
+		return Rule{}, err
+	}
+	defer c.Close()
+	return readRule(c, id)
+}
+
+// UpdateRule replaces a rule, resolving its alerts for devices it no longer
+// applies to.
+func (s monitorService) UpdateRule(ctx context.Context, rule Rule) (bool, error) {
+	if err := rule.validate(); err != nil {
+		return false, err
+	}
+	c, err := s.store.dial()
+	if err != nil {
+		return false, err
+	}
+	defer c.Close()
+
+	exists, err := redis.Bool(c.Do("EXISTS", ruleKey(rule.ID)))
+	if err != nil {
+		return false, err
+	}
+	if !exists {
+		return false, errRuleNotFound
+	}
+	if _, err := c.Do("HMSET", redis.Args{}.Add(ruleKey(rule.ID)).AddFlat(&rule)...); err != nil {
+		return false, err
+	}
+
+	alerts, err := resolveOutOfScope(c, rule, makeTimestamp())
+	for _, a := range alerts {
+		s.events.Publish(alertEvent(a))
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// resolveOutOfScope resolves the rule's firing alerts for devices outside
+// its scope, returning those it resolved.
+func resolveOutOfScope(c redis.Conn, r Rule, now int64) ([]Alert, error) {
+	active, err := redis.Strings(c.Do("SMEMBERS", "alerts:active"))
+	if err != nil {
+		return nil, err
+	}
+	prefix := fmt.Sprintf("%d:", r.ID)
+	var resolved []Alert
+	for _, m := range active {
+		if !strings.HasPrefix(m, prefix) {
+			continue
+		}
+		deviceID, err := strconv.ParseUint(strings.TrimPrefix(m, prefix), 10, 64)
+		if err != nil {
+			continue
+		}
+		device, err := readDevice(c, deviceID)
+		if err == errDeviceNotFound {
+			continue
+		}
+		if err != nil {
+			return resolved, err
+		}
+		if r.appliesTo(device) {
+			continue
+		}
+		value, err := redis.Float64(c.Do("HGET", "alert:"+m, "value"))
+		if err != nil && err != redis.ErrNil {
+			return resolved, err
+		}
+		a, err := transitionAlert(c, m, Alert{Source: AlertSourceRule, RuleID: r.ID, DeviceID: deviceID, Value: value}, false, now)
+		if err != nil {
+			return resolved, err
+		}
+		if a != nil {
+			resolved = append(resolved, *a)
+		}
+	}
+	return resolved, nil
+}
+
+func (s monitorService) DeleteRule(ctx context.Context, id uint64) (bool, error) {
+	c, err := s.store.dial()
+	if err != nil {
+		return false, err
+	}
+	defer c.Close()
+
+	removed, err := redis.Int(c.Do("SREM", "rules", id))
+	if err != nil {
+		return false, err
+	}
+	if removed == 0 {
+		return false, errRuleNotFound
+	}
+	if _, err := c.Do("DEL", ruleKey(id)); err != nil {
+		return false, err
+	}
+
+	alerts, err := clearAlerts(c, fmt.Sprintf("%d:", id), makeTimestamp())
+	for _, a := range alerts {
+		s.events.Publish(alertEvent(a))
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// clearAlerts drops the active alerts whose member starts with prefix,
+// returning them resolved at now.
+func clearAlerts(c redis.Conn, prefix string, now int64) ([]Alert, error) {
+	return clearAlertsMatching(c, func(m string) bool { return strings.HasPrefix(m, prefix) }, now)
+}
+
+// clearDeviceAlerts drops a device's active rule and geofence alerts,
+// returning them resolved at now.
+func clearDeviceAlerts(c redis.Conn, deviceID uint64, now int64) ([]Alert, error) {
+	suffix := fmt.Sprintf(":%d", deviceID)
+	return clearAlertsMatching(c, func(m string) bool { return strings.HasSuffix(m, suffix) }, now)
+}
+
+// clearAlertsMatching drops the active alerts whose member match accepts.
+// Those dropped are returned resolved at now, for their resolution to be
+// published as it would be had they cleared on their own.
+func clearAlertsMatching(c redis.Conn, match func(member string) bool, now int64) ([]Alert, error) {
+	active, err := redis.Strings(c.Do("SMEMBERS", "alerts:active"))
+	if err != nil {
+		return nil, err
+	}
+	var resolved []Alert
+	for _, m := range active {
+		if !match(m) {
+			continue
+		}
+		var a Alert
+		v, err := redis.Values(c.Do("HGETALL", "alert:"+m))
+		if err != nil {
+			return resolved, err
+		}
+		if err := redis.ScanStruct(v, &a); err != nil {
+			return resolved, err
+		}
+		if _, err := c.Do("SREM", "alerts:active", m); err != nil {
+			return resolved, err
+		}
+		if _, err := c.Do("DEL", "alert:"+m); err != nil {
+			return resolved, err
+		}
+		a.State = AlertResolved
+		a.ResolvedAt = now
+		resolved = append(resolved, a)
+	}
+	return resolved, nil
+}
+
+func (s monitorService) ListRules(ctx context.Context) ([]Rule, error) {
+	c, err := s.store.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	return readRules(c)
+}
+
+// ActiveAlerts returns the firing alerts for the devices the filter selects.
+func (s monitorService) ActiveAlerts(ctx context.Context, filter DeviceFilter) ([]Alert, error) {
+	c, err := s.store.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	var selected map[uint64]bool
+	if !filter.empty() {
+		devices, err := selectDevices(c, filter)
+		if err != nil {
+			return nil, err
+		}
+		selected = make(map[uint64]bool, len(devices))
+		for _, d := range devices {
+			selected[d.ID] = true
+		}
+	}
+
+	active, err := redis.Strings(c.Do("SMEMBERS", "alerts:active"))
+	if err != nil {
+		return nil, err
+	}
+	var alerts []Alert
+	for _, m := range active {
+		v, err := redis.Values(c.Do("HGETALL", "alert:"+m))
+		if err != nil {
+			return nil, err
+		}
+		if len(v) == 0 {
+			continue
+		}
+		var a Alert
+		if err := redis.ScanStruct(v, &a); err != nil {
+			return nil, err
+		}
+		if selected != nil && !selected[a.DeviceID] {
+			continue
+		}
+		alerts = append(alerts, a)
+	}
+	return alerts, nil
+}
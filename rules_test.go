@@ -0,0 +1,224 @@
+package iotmonitor
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		op      string
+		v       float64
+		want    bool
+		wantErr bool
+	}{
+		{">", 11, true, false},
+		{">", 10, false, false},
+		{">=", 10, true, false},
+		{"<", 9, true, false},
+		{"<=", 11, false, false},
+		{"==", 10, true, false},
+		{"!=", 10, false, false},
+		{"=>", 10, false, true},
+		{"", 10, false, true},
+	}
+	for _, tt := range tests {
+		got, err := compare(tt.op, tt.v, 10)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("compare(%q, %g, 10) error = %v, want error %v", tt.op, tt.v, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("compare(%q, %g, 10) = %v, want %v", tt.op, tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestRuleValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    Rule
+		wantErr bool
+	}{
+		{"threshold", Rule{Name: "hot", Kind: RuleThreshold, Metric: "temp", Operator: ">"}, false},
+		{"rate of change", Rule{Name: "dive", Kind: RuleRateOfChange, Metric: "altitude", Operator: "<"}, false},
+		{"absence", Rule{Name: "quiet", Kind: RuleAbsence, Window: 60}, false},
+		{"no name", Rule{Name: " ", Kind: RuleThreshold, Metric: "temp", Operator: ">"}, true},
+		{"no metric", Rule{Name: "hot", Kind: RuleThreshold, Operator: ">"}, true},
+		{"bad operator", Rule{Name: "hot", Kind: RuleThreshold, Metric: "temp", Operator: "~"}, true},
+		{"no window", Rule{Name: "quiet", Kind: RuleAbsence}, true},
+		{"unknown kind", Rule{Name: "x", Kind: "sometimes"}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.rule.validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: validate() = %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestRuleAppliesTo(t *testing.T) {
+	d := deviceRecord{ID: 7, DeviceType: "Drone", Owner: "ops"}
+	tests := []struct {
+		name string
+		rule Rule
+		want bool
+	}{
+		{"unscoped", Rule{}, true},
+		{"device", Rule{DeviceID: 7}, true},
+		{"other device", Rule{DeviceID: 8}, false},
+		{"type", Rule{DeviceType: "Drone"}, true},
+		{"other type", Rule{DeviceType: "Sensor"}, false},
+		{"owner", Rule{Owner: "ops", DeviceType: "Drone"}, true},
+		{"other owner", Rule{Owner: "lab", DeviceType: "Drone"}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.rule.appliesTo(d); got != tt.want {
+			t.Errorf("%s: appliesTo() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSampleAt(t *testing.T) {
+	s := sample{timestamp: 5000, times: map[string]int64{"temp": 2000}}
+	tests := []struct {
+		metric string
+		want   int64
+	}{
+		{"temp", 2000},
+		{"humidity", 5000},
+	}
+	for _, tt := range tests {
+		if got := s.at(tt.metric); got != tt.want {
+			t.Errorf("at(%q) = %d, want %d", tt.metric, got, tt.want)
+		}
+	}
+}
+
+func TestStatusSampleCoversStatusMetrics(t *testing.T) {
+	s := statusRecord{Timestamp: 1}.sample()
+	for metric := range statusMetrics {
+		if _, ok := s.values[metric]; !ok {
+			t.Errorf("status sample lacks status metric %q", metric)
+		}
+	}
+	for metric := range s.values {
+		if !statusMetrics[metric] {
+			t.Errorf("status sample metric %q isn't a status metric", metric)
+		}
+	}
+}
+
+func TestAlertMessage(t *testing.T) {
+	tests := []struct {
+		rule  Rule
+		value float64
+		want  string
+	}{
+		{Rule{Name: "hot", Kind: RuleThreshold, Metric: "temp", Operator: ">", Threshold: 40}, 42, "hot: temp is 42 (> 40)"},
+		{Rule{Name: "dive", Kind: RuleRateOfChange, Metric: "altitude", Operator: "<", Threshold: -5}, -7.5, "dive: altitude changing at -7.5/s (< -5)"},
+		{Rule{Name: "quiet", Kind: RuleAbsence, Window: 60}, 90, "quiet: no any data for 60s"},
+		{Rule{Name: "quiet", Kind: RuleAbsence, Metric: "temp", Window: 60}, 90, "quiet: no temp for 60s"},
+	}
+	for _, tt := range tests {
+		if got := alertMessage(tt.rule, tt.value); got != tt.want {
+			t.Errorf("alertMessage(%s) = %q, want %q", tt.rule.Name, got, tt.want)
+		}
+	}
+}
+
+func TestResolveOutOfScope(t *testing.T) {
+	c := newFakeRedis()
+	for _, d := range []deviceRecord{
+		{ID: 1, DeviceType: "Drone"},
+		{ID: 2, DeviceType: "Sensor"},
+	} {
+		c.Do("HMSET", redisArgs(fmt.Sprintf("device:%d", d.ID), &d)...)
+	}
+	r := Rule{ID: 5, Name: "hot", Kind: RuleThreshold, Metric: "temp", Operator: ">", Threshold: 40}
+	for _, id := range []uint64{1, 2} {
+		if _, err := setAlertState(c, r, id, true, 45, 1000); err != nil {
+			t.Fatal(err)
+		}
+	}
+	other := Rule{ID: 6, Name: "cold", Kind: RuleThreshold, Metric: "temp", Operator: "<", Threshold: 0}
+	if _, err := setAlertState(c, other, 2, true, -5, 1000); err != nil {
+		t.Fatal(err)
+	}
+
+	r.DeviceType = "Drone"
+	resolved, err := resolveOutOfScope(c, r, 2000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resolved) != 1 || resolved[0].DeviceID != 2 || resolved[0].State != AlertResolved ||
+		resolved[0].ResolvedAt != 2000 || resolved[0].Value != 45 || resolved[0].Source != AlertSourceRule {
+		t.Fatalf("resolved %+v, want the alert for device 2 resolved at 2000 with its value", resolved)
+	}
+	active, _ := redis.Strings(c.Do("SMEMBERS", "alerts:active"))
+	want := []string{alertMember(5, 1), alertMember(6, 2)}
+	if !reflect.DeepEqual(active, want) {
+		t.Errorf("active alerts = %v, want %v", active, want)
+	}
+}
+
+func TestClearAlerts(t *testing.T) {
+	c := newFakeRedis()
+	hot := Rule{ID: 5, Name: "hot", Kind: RuleThreshold, Metric: "temp", Operator: ">", Threshold: 40}
+	cold := Rule{ID: 6, Name: "cold", Kind: RuleThreshold, Metric: "temp", Operator: "<", Threshold: 0}
+	for _, id := range []uint64{1, 2} {
+		if _, err := setAlertState(c, hot, id, true, 45, 1000); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := setAlertState(c, cold, 2, true, -5, 1000); err != nil {
+		t.Fatal(err)
+	}
+
+	resolved, err := clearAlerts(c, "5:", 2000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resolved) != 2 {
+		t.Fatalf("clearAlerts() resolved %+v, want both alerts of rule 5", resolved)
+	}
+	for _, a := range resolved {
+		if a.RuleID != 5 || a.State != AlertResolved || a.ResolvedAt != 2000 || a.FiredAt != 1000 || a.Value != 45 {
+			t.Errorf("clearAlerts() resolved %+v, want rule 5's alert resolved at 2000", a)
+		}
+		if n, _ := redis.Int(c.Do("EXISTS", "alert:"+alertMember(5, a.DeviceID))); n != 0 {
+			t.Errorf("alert for device %d still stored", a.DeviceID)
+		}
+	}
+
+	resolved, err = clearDeviceAlerts(c, 2, 3000)
+	if err != nil || len(resolved) != 1 || resolved[0].RuleID != 6 || resolved[0].ResolvedAt != 3000 {
+		t.Errorf("clearDeviceAlerts() = %+v, %v, want rule 6's alert for device 2", resolved, err)
+	}
+	if active, _ := redis.Strings(c.Do("SMEMBERS", "alerts:active")); len(active) != 0 {
+		t.Errorf("active alerts = %v, want none", active)
+	}
+}
+
+func TestEvaluateRulesRateOfChangeUsesMetricTimes(t *testing.T) {
+	c := newFakeRedis()
+	d := deviceRecord{ID: 1}
+	c.Do("HMSET", redisArgs("device:1", &d)...)
+	r := Rule{ID: 1, Name: "heating", Kind: RuleRateOfChange, Metric: "temp", Operator: ">", Threshold: 1}
+	c.Do("HMSET", redisArgs(ruleKey(1), &r)...)
+	c.Do("SADD", "rules", 1)
+
+	// temp was last read 60s before the other reading that moved the
+	// shared timestamp on; a rise of 30 over those 60s is 0.5/s.
+	prev := sample{values: map[string]float64{"temp": 20, "humidity": 50}, timestamp: 59000, times: map[string]int64{"temp": 0}}
+	cur := sample{values: map[string]float64{"temp": 50}, timestamp: 60000}
+	changed, err := evaluateRules(c, 1, prev, cur)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changed) != 0 {
+		t.Errorf("alerts %+v fired, want none for 0.5/s", changed)
+	}
+}
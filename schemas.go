@@ -0,0 +1,287 @@
+package iotmonitor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/garyburd/redigo/redis"
+	"golang.org/x/net/context"
+)
+
+// Schema modes decide what happens to telemetry that breaks a schema.
+const (
+	// SchemaStrict rejects the whole submission.
+	SchemaStrict = "strict"
+	// SchemaWarn stores the readings as submitted and reports the violations.
+	SchemaWarn = "warn"
+	// SchemaCoerce drops unknown metrics and clamps values into range.
+	SchemaCoerce = "coerce"
+)
+
+// Violation reasons
+const (
+	ViolationUnknownMetric = "unknown_metric"
+	ViolationBelowMin      = "below_min"
+	ViolationAboveMax      = "above_max"
+	ViolationUnitMismatch  = "unit_mismatch"
+)
+
+// What was done about a violation
+const (
+	ViolationRejected = "rejected"
+	ViolationWarned   = "warned"
+	ViolationDropped  = "dropped"
+	ViolationClamped  = "clamped"
+)
+
+const schemasKey = "telemetry:schemas"
+
+// MetricSpec declares one metric a device type may report. Readings that
+// carry a unit must carry this one. Min and Max bound numeric values and
+// every component of a vector when set.
+type MetricSpec struct {
+	Unit string   `json:"unit,omitempty"`
+	Min  *float64 `json:"min,omitempty"`
+	Max  *float64 `json:"max,omitempty"`
+}
+
+// TelemetrySchema lists the metrics devices of a type may report. Device
+// types without a schema accept any readings.
+type TelemetrySchema struct {
+	DeviceType string                `json:"device_type"`
+	Mode       string                `json:"mode"`
+	Metrics    map[string]MetricSpec `json:"metrics"`
+}
+
+// SchemaViolation describes a reading that broke its device type's schema
+// and what was done about it.
+type SchemaViolation struct {
+	Metric string         `json:"metric"`
+	Reason string         `json:"reason"`
+	Value  TelemetryValue `json:"value"`
+	Action string         `json:"action"`
+}
+
+var (
+	errSchemaNotFound   = errors.New("telemetry schema not found")
+	errSchemaViolations = errors.New("telemetry violates the device type's schema")
+)
+
+func (s *TelemetrySchema) validate() error {
+	if strings.TrimSpace(s.DeviceType) == "" {
+		return errors.New("schema device type is required")
+	}
+	switch s.Mode {
+	case "":
+		s.Mode = SchemaStrict
+	case SchemaStrict, SchemaWarn, SchemaCoerce:
+	default:
+		return fmt.Errorf("unknown schema mode %q", s.Mode)
+	}
+	if len(s.Metrics) == 0 {
+		return errors.New("schema must declare at least one metric")
+	}
+	for name, m := range s.Metrics {
+		if m.Min != nil && m.Max != nil && *m.Min > *m.Max {
+			return fmt.Errorf("metric %s: min is greater than max", name)
+		}
+	}
+	return nil
+}
+
+// check compares readings against the schema. It returns the readings to
+// store, which coerce mode may have changed, and the violations found.
+func (s TelemetrySchema) check(readings map[string]TelemetryValue) (map[string]TelemetryValue, []SchemaViolation) {
+	var violations []SchemaViolation
+	out := make(map[string]TelemetryValue, len(readings))
+	names := make([]string, 0, len(readings))
+	for name := range readings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		v := readings[name]
+		out[name] = v
+		m, ok := s.Metrics[name]
+		if !ok {
+			violations = append(violations, SchemaViolation{Metric: name, Reason: ViolationUnknownMetric, Value: v, Action: s.action(ViolationDropped)})
+			if s.Mode == SchemaCoerce {
+				delete(out, name)
+			}
+			continue
+		}
+		if m.Unit != "" && v.Unit != "" && v.Unit != m.Unit {
+			violations = append(violations, SchemaViolation{Metric: name, Reason: ViolationUnitMismatch, Value: v, Action: s.action(ViolationDropped)})
+			if s.Mode == SchemaCoerce {
+				delete(out, name)
+			}
+			continue
+		}
+		if reason, clamped := m.clamp(v); reason != "" {
+			violations = append(violations, SchemaViolation{Metric: name, Reason: reason, Value: v, Action: s.action(ViolationClamped)})
+			if s.Mode == SchemaCoerce {
+				out[name] = clamped
+			}
+		}
+	}
+	return out, violations
+}
+
+// clamp brings a value within the spec's range. It returns the reason the
+// value was out of range, or "" if it wasn't.
+func (m MetricSpec) clamp(v TelemetryValue) (string, TelemetryValue) {
+	bound := func(f float64) (string, float64) {
+		switch {
+		case m.Min != nil && f < *m.Min:
+			return ViolationBelowMin, *m.Min
+		case m.Max != nil && f > *m.Max:
+			return ViolationAboveMax, *m.Max
+		}
+		return "", f
+	}
+
+	var reason string
+	switch v.Type {
+	case TelemetryDouble:
+		reason, v.Double = bound(v.Double)
+	case TelemetryInt:
+		var f float64
+		if reason, f = bound(float64(v.Int)); reason != "" {
+			v.Int = int64(f)
+		}
+	case TelemetryVector:
+		components := make([]float64, len(v.Vector))
+		for i, f := range v.Vector {
+			r, c := bound(f)
+			if reason == "" {
+				reason = r
+			}
+			components[i] = c
+		}
+		v.Vector = components
+	}
+	return reason, v
+}
+
+// action is what the schema's mode does to a violation; coerced is the
+// action coerce mode takes.
+func (s TelemetrySchema) action(coerced string) string {
+	switch s.Mode {
+	case SchemaWarn:
+		return ViolationWarned
+	case SchemaCoerce:
+		return coerced
+	}
+	return ViolationRejected
+}
+
+func readSchema(c redis.Conn, deviceType string) (TelemetrySchema, error) {
+	var s TelemetrySchema
+	b, err := redis.Bytes(c.Do("HGET", schemasKey, deviceType))
+	if err == redis.ErrNil {
+		return s, errSchemaNotFound
+	}
+	if err != nil {
+		return s, err
+	}
+	err = json.Unmarshal(b, &s)
+	return s, err
+}
+
+// applySchema checks a device's readings against its type's schema. Devices
+// that aren't registered, or whose type has no schema, pass unchecked.
+func applySchema(c redis.Conn, id uint64, readings map[string]TelemetryValue) (map[string]TelemetryValue, []SchemaViolation, error) {
+	d, err := readDevice(c, id)
+	if err == errDeviceNotFound {
+		return readings, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	s, err := readSchema(c, d.DeviceType)
+	if err == errSchemaNotFound {
+		return readings, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	checked, violations := s.check(readings)
+	if len(violations) > 0 && s.Mode == SchemaStrict {
+		return nil, violations, errSchemaViolations
+	}
+	return checked, violations, nil
+}
+
+// SetTelemetrySchema creates or replaces the schema for a device type.
+func (s monitorService) SetTelemetrySchema(ctx context.Context, schema TelemetrySchema) (bool, error) {
+	if err := schema.validate(); err != nil {
+		return false, err
+	}
+	c, err := s.store.dial()
+	if err != nil {
+		return false, err
+	}
+	defer c.Close()
+
+	b, err := json.Marshal(schema)
+	if err != nil {
+		return false, err
+	}
+	if _, err := c.Do("HSET", schemasKey, schema.DeviceType, b); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s monitorService) GetTelemetrySchema(ctx context.Context, deviceType string) (TelemetrySchema, error) {
+	c, err := s.store.dial()
+	if err != nil {
+		return TelemetrySchema{}, err
+	}
+	defer c.Close()
+	return readSchema(c, deviceType)
+}
+
+func (s monitorService) ListTelemetrySchemas(ctx context.Context) ([]TelemetrySchema, error) {
+	c, err := s.store.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	raw, err := redis.ByteSlices(c.Do("HVALS", schemasKey))
+	if err != nil {
+		return nil, err
+	}
+	schemas := make([]TelemetrySchema, 0, len(raw))
+	for _, b := range raw {
+		var s TelemetrySchema
+		if err := json.Unmarshal(b, &s); err != nil {
+			return nil, err
+		}
+		schemas = append(schemas, s)
+	}
+	sort.Slice(schemas, func(i, j int) bool { return schemas[i].DeviceType < schemas[j].DeviceType })
+	return schemas, nil
+}
+
+func (s monitorService) DeleteTelemetrySchema(ctx context.Context, deviceType string) (bool, error) {
+	c, err := s.store.dial()
+	if err != nil {
+		return false, err
+	}
+	defer c.Close()
+
+	deleted, err := redis.Bool(c.Do("HDEL", schemasKey, deviceType))
+	if err != nil {
+		return false, err
+	}
+	if !deleted {
+		return false, errSchemaNotFound
+	}
+	return true, nil
+}
@@ -0,0 +1,118 @@
+package iotmonitor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func bound(f float64) *float64 { return &f }
+
+func TestMetricSpecClamp(t *testing.T) {
+	spec := MetricSpec{Min: bound(0), Max: bound(100)}
+	tests := []struct {
+		name   string
+		spec   MetricSpec
+		v      TelemetryValue
+		reason string
+		want   TelemetryValue
+	}{
+		{"in range", spec, DoubleValue(50, ""), "", DoubleValue(50, "")},
+		{"below", spec, DoubleValue(-5, "C"), ViolationBelowMin, DoubleValue(0, "C")},
+		{"above", spec, DoubleValue(120, ""), ViolationAboveMax, DoubleValue(100, "")},
+		{"int above", spec, TelemetryValue{Type: TelemetryInt, Int: 150}, ViolationAboveMax, TelemetryValue{Type: TelemetryInt, Int: 100}},
+		{"int in range", spec, TelemetryValue{Type: TelemetryInt, Int: 7}, "", TelemetryValue{Type: TelemetryInt, Int: 7}},
+		{
+			"vector",
+			spec,
+			TelemetryValue{Type: TelemetryVector, Vector: []float64{50, -1, 101}},
+			ViolationBelowMin,
+			TelemetryValue{Type: TelemetryVector, Vector: []float64{50, 0, 100}},
+		},
+		{"min only", MetricSpec{Min: bound(10)}, DoubleValue(1e9, ""), "", DoubleValue(1e9, "")},
+		{"unbounded", MetricSpec{}, DoubleValue(-1e9, ""), "", DoubleValue(-1e9, "")},
+		{"text", spec, TelemetryValue{Type: TelemetryString, Text: "x"}, "", TelemetryValue{Type: TelemetryString, Text: "x"}},
+	}
+	for _, tt := range tests {
+		reason, got := tt.spec.clamp(tt.v)
+		if reason != tt.reason || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: clamp() = %q, %+v, want %q, %+v", tt.name, reason, got, tt.reason, tt.want)
+		}
+	}
+}
+
+func TestTelemetrySchemaCheck(t *testing.T) {
+	metrics := map[string]MetricSpec{
+		"temp":     {Unit: "C", Min: bound(-40), Max: bound(85)},
+		"humidity": {Max: bound(100)},
+	}
+	readings := map[string]TelemetryValue{
+		"temp":     DoubleValue(90, "C"),
+		"humidity": DoubleValue(50, ""),
+		"pressure": DoubleValue(1013, "hPa"),
+	}
+	violations := func(action string) []SchemaViolation {
+		return []SchemaViolation{
+			{Metric: "pressure", Reason: ViolationUnknownMetric, Value: readings["pressure"], Action: action},
+			{Metric: "temp", Reason: ViolationAboveMax, Value: readings["temp"], Action: action},
+		}
+	}
+	tests := []struct {
+		mode       string
+		readings   map[string]TelemetryValue
+		want       map[string]TelemetryValue
+		violations []SchemaViolation
+	}{
+		{SchemaStrict, readings, readings, violations(ViolationRejected)},
+		{SchemaWarn, readings, readings, violations(ViolationWarned)},
+		{
+			SchemaCoerce,
+			readings,
+			map[string]TelemetryValue{"temp": DoubleValue(85, "C"), "humidity": DoubleValue(50, "")},
+			[]SchemaViolation{
+				{Metric: "pressure", Reason: ViolationUnknownMetric, Value: readings["pressure"], Action: ViolationDropped},
+				{Metric: "temp", Reason: ViolationAboveMax, Value: readings["temp"], Action: ViolationClamped},
+			},
+		},
+		{
+			SchemaCoerce,
+			map[string]TelemetryValue{"temp": DoubleValue(90, "F")},
+			map[string]TelemetryValue{},
+			[]SchemaViolation{{Metric: "temp", Reason: ViolationUnitMismatch, Value: DoubleValue(90, "F"), Action: ViolationDropped}},
+		},
+		{
+			SchemaStrict,
+			map[string]TelemetryValue{"temp": DoubleValue(20, ""), "humidity": DoubleValue(50, "%")},
+			map[string]TelemetryValue{"temp": DoubleValue(20, ""), "humidity": DoubleValue(50, "%")},
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		s := TelemetrySchema{DeviceType: "Sensor", Mode: tt.mode, Metrics: metrics}
+		got, violations := s.check(tt.readings)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: check() readings = %+v, want %+v", tt.mode, got, tt.want)
+		}
+		if !reflect.DeepEqual(violations, tt.violations) {
+			t.Errorf("%s: check() violations = %+v, want %+v", tt.mode, violations, tt.violations)
+		}
+	}
+}
+
+func TestTelemetrySchemaValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  TelemetrySchema
+		wantErr bool
+	}{
+		{"default mode", TelemetrySchema{DeviceType: "Sensor", Metrics: map[string]MetricSpec{"temp": {}}}, false},
+		{"no device type", TelemetrySchema{Metrics: map[string]MetricSpec{"temp": {}}}, true},
+		{"unknown mode", TelemetrySchema{DeviceType: "Sensor", Mode: "lenient", Metrics: map[string]MetricSpec{"temp": {}}}, true},
+		{"no metrics", TelemetrySchema{DeviceType: "Sensor"}, true},
+		{"min over max", TelemetrySchema{DeviceType: "Sensor", Metrics: map[string]MetricSpec{"temp": {Min: bound(10), Max: bound(0)}}}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.schema.validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: validate() = %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -1,13 +1,31 @@
 package iotmonitor
 
 import (
+	"errors"
+
 	"github.com/autodidaddict/iotmonitor/pb"
+	"github.com/go-kit/kit/endpoint"
 	grpctransport "github.com/go-kit/kit/transport/grpc"
 	"golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
-func NewGRPCServer(ctx context.Context, endpoints Endpoints) pb.MonitorServer {
+// errServerDraining ends watch streams when the server shuts down, telling
+// clients to reconnect elsewhere.
+var errServerDraining = status.Error(codes.Unavailable, "server is shutting down")
+
+// NewGRPCServer returns the gRPC transport. The events bus wakes the
+// WatchDesired and WatchCommands streams and feeds WatchEvents, which end
+// with Unavailable when ctx is done so that the server can drain.
+func NewGRPCServer(ctx context.Context, endpoints Endpoints, events *EventBus) pb.MonitorServer {
 	return &grpcServer{
+		ctx:             ctx,
+		deliverDesired:  endpoints.DeliverDesiredEndpoint,
+		deliverCommands: endpoints.DeliverCommandsEndpoint,
+		matchDevices:    endpoints.ListDevices,
+		events:          events,
+
 		register: grpctransport.NewServer(
 			endpoints.RegisterEndpoint,
 			DecodeGRPCRegisterRequest,
@@ -23,13 +41,368 @@ func NewGRPCServer(ctx context.Context, endpoints Endpoints) pb.MonitorServer {
 			DecodeGRPCTelemetryRequest,
 			EncodeGRPCTelemetryResponse,
 		),
+		createRule: grpctransport.NewServer(
+			endpoints.CreateRuleEndpoint,
+			DecodeGRPCCreateRuleRequest,
+			EncodeGRPCCreateRuleResponse,
+		),
+		getRule: grpctransport.NewServer(
+			endpoints.GetRuleEndpoint,
+			DecodeGRPCGetRuleRequest,
+			EncodeGRPCGetRuleResponse,
+		),
+		updateRule: grpctransport.NewServer(
+			endpoints.UpdateRuleEndpoint,
+			DecodeGRPCUpdateRuleRequest,
+			EncodeGRPCUpdateRuleResponse,
+		),
+		deleteRule: grpctransport.NewServer(
+			endpoints.DeleteRuleEndpoint,
+			DecodeGRPCDeleteRuleRequest,
+			EncodeGRPCDeleteRuleResponse,
+		),
+		listRules: grpctransport.NewServer(
+			endpoints.ListRulesEndpoint,
+			DecodeGRPCListRulesRequest,
+			EncodeGRPCListRulesResponse,
+		),
+		activeAlerts: grpctransport.NewServer(
+			endpoints.ActiveAlertsEndpoint,
+			DecodeGRPCActiveAlertsRequest,
+			EncodeGRPCActiveAlertsResponse,
+		),
+		createWebhook: grpctransport.NewServer(
+			endpoints.CreateWebhookEndpoint,
+			DecodeGRPCCreateWebhookRequest,
+			EncodeGRPCCreateWebhookResponse,
+		),
+		listWebhooks: grpctransport.NewServer(
+			endpoints.ListWebhooksEndpoint,
+			DecodeGRPCListWebhooksRequest,
+			EncodeGRPCListWebhooksResponse,
+		),
+		deleteWebhook: grpctransport.NewServer(
+			endpoints.DeleteWebhookEndpoint,
+			DecodeGRPCDeleteWebhookRequest,
+			EncodeGRPCDeleteWebhookResponse,
+		),
+		deadLetters: grpctransport.NewServer(
+			endpoints.DeadLettersEndpoint,
+			DecodeGRPCDeadLettersRequest,
+			EncodeGRPCDeadLettersResponse,
+		),
+		getDevice: grpctransport.NewServer(
+			endpoints.GetDeviceEndpoint,
+			DecodeGRPCGetDeviceRequest,
+			EncodeGRPCGetDeviceResponse,
+		),
+		listDevices: grpctransport.NewServer(
+			endpoints.ListDevicesEndpoint,
+			DecodeGRPCListDevicesRequest,
+			EncodeGRPCListDevicesResponse,
+		),
+		createGeofence: grpctransport.NewServer(
+			endpoints.CreateGeofenceEndpoint,
+			DecodeGRPCCreateGeofenceRequest,
+			EncodeGRPCCreateGeofenceResponse,
+		),
+		updateGeofence: grpctransport.NewServer(
+			endpoints.UpdateGeofenceEndpoint,
+			DecodeGRPCUpdateGeofenceRequest,
+			EncodeGRPCUpdateGeofenceResponse,
+		),
+		deleteGeofence: grpctransport.NewServer(
+			endpoints.DeleteGeofenceEndpoint,
+			DecodeGRPCDeleteGeofenceRequest,
+			EncodeGRPCDeleteGeofenceResponse,
+		),
+		listGeofences: grpctransport.NewServer(
+			endpoints.ListGeofencesEndpoint,
+			DecodeGRPCListGeofencesRequest,
+			EncodeGRPCListGeofencesResponse,
+		),
+		nearbyDevices: grpctransport.NewServer(
+			endpoints.NearbyDevicesEndpoint,
+			DecodeGRPCNearbyDevicesRequest,
+			EncodeGRPCNearbyDevicesResponse,
+		),
+		devicesInBox: grpctransport.NewServer(
+			endpoints.DevicesInBoxEndpoint,
+			DecodeGRPCDevicesInBoxRequest,
+			EncodeGRPCDevicesInBoxResponse,
+		),
+		getStatus: grpctransport.NewServer(
+			endpoints.GetStatusEndpoint,
+			DecodeGRPCGetStatusRequest,
+			EncodeGRPCGetStatusResponse,
+		),
+		batteryHistory: grpctransport.NewServer(
+			endpoints.BatteryHistoryEndpoint,
+			DecodeGRPCBatteryHistoryRequest,
+			EncodeGRPCBatteryHistoryResponse,
+		),
+		queryTelemetry: grpctransport.NewServer(
+			endpoints.QueryTelemetryEndpoint,
+			DecodeGRPCQueryTelemetryRequest,
+			EncodeGRPCQueryTelemetryResponse,
+		),
+		aggregateTelemetry: grpctransport.NewServer(
+			endpoints.AggregateTelemetryEndpoint,
+			DecodeGRPCAggregateTelemetryRequest,
+			EncodeGRPCAggregateTelemetryResponse,
+		),
+		setLabels: grpctransport.NewServer(
+			endpoints.SetLabelsEndpoint,
+			DecodeGRPCSetLabelsRequest,
+			EncodeGRPCSetLabelsResponse,
+		),
+		listStatuses: grpctransport.NewServer(
+			endpoints.ListStatusesEndpoint,
+			DecodeGRPCListStatusesRequest,
+			EncodeGRPCListStatusesResponse,
+		),
+		createGroup: grpctransport.NewServer(
+			endpoints.CreateGroupEndpoint,
+			DecodeGRPCCreateGroupRequest,
+			EncodeGRPCCreateGroupResponse,
+		),
+		updateGroup: grpctransport.NewServer(
+			endpoints.UpdateGroupEndpoint,
+			DecodeGRPCUpdateGroupRequest,
+			EncodeGRPCUpdateGroupResponse,
+		),
+		deleteGroup: grpctransport.NewServer(
+			endpoints.DeleteGroupEndpoint,
+			DecodeGRPCDeleteGroupRequest,
+			EncodeGRPCDeleteGroupResponse,
+		),
+		listGroups: grpctransport.NewServer(
+			endpoints.ListGroupsEndpoint,
+			DecodeGRPCListGroupsRequest,
+			EncodeGRPCListGroupsResponse,
+		),
+		addGroupMembers: grpctransport.NewServer(
+			endpoints.AddGroupMembersEndpoint,
+			DecodeGRPCAddGroupMembersRequest,
+			EncodeGRPCAddGroupMembersResponse,
+		),
+		removeGroupMembers: grpctransport.NewServer(
+			endpoints.RemoveGroupMembersEndpoint,
+			DecodeGRPCRemoveGroupMembersRequest,
+			EncodeGRPCRemoveGroupMembersResponse,
+		),
+		relayStatus: grpctransport.NewServer(
+			endpoints.RelayStatusEndpoint,
+			DecodeGRPCRelayStatusRequest,
+			EncodeGRPCRelayStatusResponse,
+		),
+		relayTelemetry: grpctransport.NewServer(
+			endpoints.RelayTelemetryEndpoint,
+			DecodeGRPCRelayTelemetryRequest,
+			EncodeGRPCRelayTelemetryResponse,
+		),
+		getTopology: grpctransport.NewServer(
+			endpoints.GetTopologyEndpoint,
+			DecodeGRPCGetTopologyRequest,
+			EncodeGRPCGetTopologyResponse,
+		),
+		getTwin: grpctransport.NewServer(
+			endpoints.GetTwinEndpoint,
+			DecodeGRPCGetTwinRequest,
+			EncodeGRPCGetTwinResponse,
+		),
+		updateDesired: grpctransport.NewServer(
+			endpoints.UpdateDesiredEndpoint,
+			DecodeGRPCUpdateDesiredRequest,
+			EncodeGRPCUpdateDesiredResponse,
+		),
+		reportState: grpctransport.NewServer(
+			endpoints.ReportStateEndpoint,
+			DecodeGRPCReportStateRequest,
+			EncodeGRPCReportStateResponse,
+		),
+		enqueueCommand: grpctransport.NewServer(
+			endpoints.EnqueueCommandEndpoint,
+			DecodeGRPCEnqueueCommandRequest,
+			EncodeGRPCEnqueueCommandResponse,
+		),
+		ackCommand: grpctransport.NewServer(
+			endpoints.AckCommandEndpoint,
+			DecodeGRPCAckCommandRequest,
+			EncodeGRPCAckCommandResponse,
+		),
+		commandHistory: grpctransport.NewServer(
+			endpoints.CommandHistoryEndpoint,
+			DecodeGRPCCommandHistoryRequest,
+			EncodeGRPCCommandHistoryResponse,
+		),
+		uploadFirmware: grpctransport.NewServer(
+			endpoints.UploadFirmwareEndpoint,
+			DecodeGRPCUploadFirmwareRequest,
+			EncodeGRPCUploadFirmwareResponse,
+		),
+		listFirmware: grpctransport.NewServer(
+			endpoints.ListFirmwareEndpoint,
+			DecodeGRPCListFirmwareRequest,
+			EncodeGRPCListFirmwareResponse,
+		),
+		downloadFirmware: grpctransport.NewServer(
+			endpoints.DownloadFirmwareEndpoint,
+			DecodeGRPCDownloadFirmwareRequest,
+			EncodeGRPCDownloadFirmwareResponse,
+		),
+		createCampaign: grpctransport.NewServer(
+			endpoints.CreateCampaignEndpoint,
+			DecodeGRPCCreateCampaignRequest,
+			EncodeGRPCCreateCampaignResponse,
+		),
+		getCampaign: grpctransport.NewServer(
+			endpoints.GetCampaignEndpoint,
+			DecodeGRPCGetCampaignRequest,
+			EncodeGRPCGetCampaignResponse,
+		),
+		listCampaigns: grpctransport.NewServer(
+			endpoints.ListCampaignsEndpoint,
+			DecodeGRPCListCampaignsRequest,
+			EncodeGRPCListCampaignsResponse,
+		),
+		controlCampaign: grpctransport.NewServer(
+			endpoints.ControlCampaignEndpoint,
+			DecodeGRPCControlCampaignRequest,
+			EncodeGRPCControlCampaignResponse,
+		),
+		reportFirmware: grpctransport.NewServer(
+			endpoints.ReportFirmwareEndpoint,
+			DecodeGRPCReportFirmwareRequest,
+			EncodeGRPCReportFirmwareResponse,
+		),
+		setTelemetrySchema: grpctransport.NewServer(
+			endpoints.SetTelemetrySchemaEndpoint,
+			DecodeGRPCSetTelemetrySchemaRequest,
+			EncodeGRPCSetTelemetrySchemaResponse,
+		),
+		getTelemetrySchema: grpctransport.NewServer(
+			endpoints.GetTelemetrySchemaEndpoint,
+			DecodeGRPCGetTelemetrySchemaRequest,
+			EncodeGRPCGetTelemetrySchemaResponse,
+		),
+		listTelemetrySchemas: grpctransport.NewServer(
+			endpoints.ListTelemetrySchemasEndpoint,
+			DecodeGRPCListTelemetrySchemasRequest,
+			EncodeGRPCListTelemetrySchemasResponse,
+		),
+		deleteTelemetrySchema: grpctransport.NewServer(
+			endpoints.DeleteTelemetrySchemaEndpoint,
+			DecodeGRPCDeleteTelemetrySchemaRequest,
+			EncodeGRPCDeleteTelemetrySchemaResponse,
+		),
+		importDevices: grpctransport.NewServer(
+			endpoints.ImportDevicesEndpoint,
+			DecodeGRPCImportDevicesRequest,
+			EncodeGRPCImportDevicesResponse,
+		),
+		exportDevices: grpctransport.NewServer(
+			endpoints.ExportDevicesEndpoint,
+			DecodeGRPCExportDevicesRequest,
+			EncodeGRPCExportDevicesResponse,
+		),
+		updateDevice: grpctransport.NewServer(
+			endpoints.UpdateDeviceEndpoint,
+			DecodeGRPCUpdateDeviceRequest,
+			EncodeGRPCUpdateDeviceResponse,
+		),
+		deleteDevice: grpctransport.NewServer(
+			endpoints.DeleteDeviceEndpoint,
+			DecodeGRPCDeleteDeviceRequest,
+			EncodeGRPCDeleteDeviceResponse,
+		),
 	}
 }
 
 type grpcServer struct {
+	ctx             context.Context
+	deliverDesired  endpoint.Endpoint
+	deliverCommands endpoint.Endpoint
+	matchDevices    func(context.Context, DeviceFilter) ([]Device, error)
+	events          *EventBus
+
 	register  grpctransport.Handler
 	update    grpctransport.Handler
 	telemetry grpctransport.Handler
+
+	createRule   grpctransport.Handler
+	getRule      grpctransport.Handler
+	updateRule   grpctransport.Handler
+	deleteRule   grpctransport.Handler
+	listRules    grpctransport.Handler
+	activeAlerts grpctransport.Handler
+
+	createWebhook grpctransport.Handler
+	listWebhooks  grpctransport.Handler
+	deleteWebhook grpctransport.Handler
+	deadLetters   grpctransport.Handler
+
+	getDevice   grpctransport.Handler
+	listDevices grpctransport.Handler
+
+	createGeofence grpctransport.Handler
+	updateGeofence grpctransport.Handler
+	deleteGeofence grpctransport.Handler
+	listGeofences  grpctransport.Handler
+
+	nearbyDevices grpctransport.Handler
+	devicesInBox  grpctransport.Handler
+
+	getStatus grpctransport.Handler
+
+	batteryHistory grpctransport.Handler
+
+	queryTelemetry grpctransport.Handler
+
+	aggregateTelemetry grpctransport.Handler
+
+	setLabels grpctransport.Handler
+
+	listStatuses       grpctransport.Handler
+	createGroup        grpctransport.Handler
+	updateGroup        grpctransport.Handler
+	deleteGroup        grpctransport.Handler
+	listGroups         grpctransport.Handler
+	addGroupMembers    grpctransport.Handler
+	removeGroupMembers grpctransport.Handler
+
+	relayStatus    grpctransport.Handler
+	relayTelemetry grpctransport.Handler
+	getTopology    grpctransport.Handler
+
+	getTwin       grpctransport.Handler
+	updateDesired grpctransport.Handler
+	reportState   grpctransport.Handler
+
+	enqueueCommand grpctransport.Handler
+	ackCommand     grpctransport.Handler
+	commandHistory grpctransport.Handler
+
+	uploadFirmware   grpctransport.Handler
+	listFirmware     grpctransport.Handler
+	downloadFirmware grpctransport.Handler
+	createCampaign   grpctransport.Handler
+	getCampaign      grpctransport.Handler
+	listCampaigns    grpctransport.Handler
+	controlCampaign  grpctransport.Handler
+
+	reportFirmware grpctransport.Handler
+
+	setTelemetrySchema    grpctransport.Handler
+	getTelemetrySchema    grpctransport.Handler
+	listTelemetrySchemas  grpctransport.Handler
+	deleteTelemetrySchema grpctransport.Handler
+
+	importDevices grpctransport.Handler
+	exportDevices grpctransport.Handler
+
+	updateDevice grpctransport.Handler
+	deleteDevice grpctransport.Handler
 }
 
 func (s *grpcServer) RegisterDevice(ctx context.Context, in *pb.RegisterDeviceRequest) (*pb.RegisterDeviceReply, error) {
@@ -55,3 +428,527 @@ func (s *grpcServer) SubmitTelemetry(ctx context.Context, in *pb.TelemetrySubmit
 	}
 	return resp.(*pb.TelemetrySubmitReply), nil
 }
+
+func (s *grpcServer) CreateRule(ctx context.Context, in *pb.CreateRuleRequest) (*pb.CreateRuleReply, error) {
+	_, resp, err := s.createRule.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.CreateRuleReply), nil
+}
+
+func (s *grpcServer) GetRule(ctx context.Context, in *pb.GetRuleRequest) (*pb.GetRuleReply, error) {
+	_, resp, err := s.getRule.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.GetRuleReply), nil
+}
+
+func (s *grpcServer) UpdateRule(ctx context.Context, in *pb.UpdateRuleRequest) (*pb.UpdateRuleReply, error) {
+	_, resp, err := s.updateRule.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.UpdateRuleReply), nil
+}
+
+func (s *grpcServer) DeleteRule(ctx context.Context, in *pb.DeleteRuleRequest) (*pb.DeleteRuleReply, error) {
+	_, resp, err := s.deleteRule.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.DeleteRuleReply), nil
+}
+
+func (s *grpcServer) ListRules(ctx context.Context, in *pb.ListRulesRequest) (*pb.ListRulesReply, error) {
+	_, resp, err := s.listRules.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.ListRulesReply), nil
+}
+
+func (s *grpcServer) ActiveAlerts(ctx context.Context, in *pb.ActiveAlertsRequest) (*pb.ActiveAlertsReply, error) {
+	_, resp, err := s.activeAlerts.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.ActiveAlertsReply), nil
+}
+
+func (s *grpcServer) CreateWebhook(ctx context.Context, in *pb.CreateWebhookRequest) (*pb.CreateWebhookReply, error) {
+	_, resp, err := s.createWebhook.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.CreateWebhookReply), nil
+}
+
+func (s *grpcServer) ListWebhooks(ctx context.Context, in *pb.ListWebhooksRequest) (*pb.ListWebhooksReply, error) {
+	_, resp, err := s.listWebhooks.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.ListWebhooksReply), nil
+}
+
+func (s *grpcServer) DeleteWebhook(ctx context.Context, in *pb.DeleteWebhookRequest) (*pb.DeleteWebhookReply, error) {
+	_, resp, err := s.deleteWebhook.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.DeleteWebhookReply), nil
+}
+
+func (s *grpcServer) DeadLetters(ctx context.Context, in *pb.DeadLettersRequest) (*pb.DeadLettersReply, error) {
+	_, resp, err := s.deadLetters.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.DeadLettersReply), nil
+}
+
+func (s *grpcServer) GetDevice(ctx context.Context, in *pb.GetDeviceRequest) (*pb.GetDeviceReply, error) {
+	_, resp, err := s.getDevice.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.GetDeviceReply), nil
+}
+
+func (s *grpcServer) ListDevices(ctx context.Context, in *pb.ListDevicesRequest) (*pb.ListDevicesReply, error) {
+	_, resp, err := s.listDevices.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.ListDevicesReply), nil
+}
+
+func (s *grpcServer) CreateGeofence(ctx context.Context, in *pb.CreateGeofenceRequest) (*pb.CreateGeofenceReply, error) {
+	_, resp, err := s.createGeofence.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.CreateGeofenceReply), nil
+}
+
+func (s *grpcServer) UpdateGeofence(ctx context.Context, in *pb.UpdateGeofenceRequest) (*pb.UpdateGeofenceReply, error) {
+	_, resp, err := s.updateGeofence.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.UpdateGeofenceReply), nil
+}
+
+func (s *grpcServer) DeleteGeofence(ctx context.Context, in *pb.DeleteGeofenceRequest) (*pb.DeleteGeofenceReply, error) {
+	_, resp, err := s.deleteGeofence.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.DeleteGeofenceReply), nil
+}
+
+func (s *grpcServer) ListGeofences(ctx context.Context, in *pb.ListGeofencesRequest) (*pb.ListGeofencesReply, error) {
+	_, resp, err := s.listGeofences.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.ListGeofencesReply), nil
+}
+
+func (s *grpcServer) NearbyDevices(ctx context.Context, in *pb.NearbyDevicesRequest) (*pb.NearbyDevicesReply, error) {
+	_, resp, err := s.nearbyDevices.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.NearbyDevicesReply), nil
+}
+
+func (s *grpcServer) DevicesInBox(ctx context.Context, in *pb.DevicesInBoxRequest) (*pb.DevicesInBoxReply, error) {
+	_, resp, err := s.devicesInBox.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.DevicesInBoxReply), nil
+}
+
+func (s *grpcServer) GetStatus(ctx context.Context, in *pb.GetStatusRequest) (*pb.GetStatusReply, error) {
+	_, resp, err := s.getStatus.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.GetStatusReply), nil
+}
+
+func (s *grpcServer) BatteryHistory(ctx context.Context, in *pb.BatteryHistoryRequest) (*pb.BatteryHistoryReply, error) {
+	_, resp, err := s.batteryHistory.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.BatteryHistoryReply), nil
+}
+
+func (s *grpcServer) QueryTelemetry(ctx context.Context, in *pb.QueryTelemetryRequest) (*pb.QueryTelemetryReply, error) {
+	_, resp, err := s.queryTelemetry.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.QueryTelemetryReply), nil
+}
+
+func (s *grpcServer) AggregateTelemetry(ctx context.Context, in *pb.AggregateTelemetryRequest) (*pb.AggregateTelemetryReply, error) {
+	_, resp, err := s.aggregateTelemetry.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.AggregateTelemetryReply), nil
+}
+
+func (s *grpcServer) SetLabels(ctx context.Context, in *pb.SetLabelsRequest) (*pb.SetLabelsReply, error) {
+	_, resp, err := s.setLabels.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.SetLabelsReply), nil
+}
+
+func (s *grpcServer) ListStatuses(ctx context.Context, in *pb.ListStatusesRequest) (*pb.ListStatusesReply, error) {
+	_, resp, err := s.listStatuses.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.ListStatusesReply), nil
+}
+
+func (s *grpcServer) CreateGroup(ctx context.Context, in *pb.CreateGroupRequest) (*pb.CreateGroupReply, error) {
+	_, resp, err := s.createGroup.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.CreateGroupReply), nil
+}
+
+func (s *grpcServer) UpdateGroup(ctx context.Context, in *pb.UpdateGroupRequest) (*pb.UpdateGroupReply, error) {
+	_, resp, err := s.updateGroup.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.UpdateGroupReply), nil
+}
+
+func (s *grpcServer) DeleteGroup(ctx context.Context, in *pb.DeleteGroupRequest) (*pb.DeleteGroupReply, error) {
+	_, resp, err := s.deleteGroup.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.DeleteGroupReply), nil
+}
+
+func (s *grpcServer) ListGroups(ctx context.Context, in *pb.ListGroupsRequest) (*pb.ListGroupsReply, error) {
+	_, resp, err := s.listGroups.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.ListGroupsReply), nil
+}
+
+func (s *grpcServer) AddGroupMembers(ctx context.Context, in *pb.AddGroupMembersRequest) (*pb.AddGroupMembersReply, error) {
+	_, resp, err := s.addGroupMembers.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.AddGroupMembersReply), nil
+}
+
+func (s *grpcServer) RemoveGroupMembers(ctx context.Context, in *pb.RemoveGroupMembersRequest) (*pb.RemoveGroupMembersReply, error) {
+	_, resp, err := s.removeGroupMembers.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.RemoveGroupMembersReply), nil
+}
+
+func (s *grpcServer) RelayStatus(ctx context.Context, in *pb.RelayStatusRequest) (*pb.RelayStatusReply, error) {
+	_, resp, err := s.relayStatus.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.RelayStatusReply), nil
+}
+
+func (s *grpcServer) RelayTelemetry(ctx context.Context, in *pb.RelayTelemetryRequest) (*pb.RelayTelemetryReply, error) {
+	_, resp, err := s.relayTelemetry.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.RelayTelemetryReply), nil
+}
+
+func (s *grpcServer) GetTopology(ctx context.Context, in *pb.GetTopologyRequest) (*pb.GetTopologyReply, error) {
+	_, resp, err := s.getTopology.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.GetTopologyReply), nil
+}
+
+func (s *grpcServer) GetTwin(ctx context.Context, in *pb.GetTwinRequest) (*pb.GetTwinReply, error) {
+	_, resp, err := s.getTwin.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.GetTwinReply), nil
+}
+
+func (s *grpcServer) UpdateDesired(ctx context.Context, in *pb.UpdateDesiredRequest) (*pb.UpdateDesiredReply, error) {
+	_, resp, err := s.updateDesired.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.UpdateDesiredReply), nil
+}
+
+func (s *grpcServer) ReportState(ctx context.Context, in *pb.ReportStateRequest) (*pb.ReportStateReply, error) {
+	_, resp, err := s.reportState.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.ReportStateReply), nil
+}
+
+// watch calls send once and again whenever an event of the given type is
+// published for the device, until the stream's or the server's context
+// ends.
+func (s *grpcServer) watch(ctx context.Context, deviceID uint64, eventType string, send func() error) error {
+	events, cancel := s.events.Subscribe(16)
+	defer cancel()
+
+	if err := send(); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.ctx.Done():
+			return errServerDraining
+		case e, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if e.Type != eventType || e.DeviceID != deviceID {
+				continue
+			}
+			if err := send(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// WatchDesired streams desired-state changes to a device. Any change not yet
+// delivered is sent as soon as the stream opens.
+func (s *grpcServer) WatchDesired(in *pb.WatchDesiredRequest, stream pb.Monitor_WatchDesiredServer) error {
+	ctx := stream.Context()
+	return s.watch(ctx, in.Deviceid, EventTwinDesiredUpdated, func() error {
+		resp, err := s.deliverDesired(ctx, deliverDesiredRequest{DeviceID: in.Deviceid})
+		if err != nil {
+			return err
+		}
+		res := resp.(deliverDesiredReply)
+		if res.Err != "" {
+			return errors.New(res.Err)
+		}
+		if res.Desired == nil {
+			return nil
+		}
+		return stream.Send(desiredStateToPB(res.Desired))
+	})
+}
+
+// WatchCommands streams queued commands to a device, starting with any that
+// are already pending.
+func (s *grpcServer) WatchCommands(in *pb.WatchCommandsRequest, stream pb.Monitor_WatchCommandsServer) error {
+	ctx := stream.Context()
+	return s.watch(ctx, in.Deviceid, EventCommandQueued, func() error {
+		resp, err := s.deliverCommands(ctx, deliverCommandsRequest{DeviceID: in.Deviceid})
+		if err != nil {
+			return err
+		}
+		res := resp.(deliverCommandsReply)
+		if res.Err != "" {
+			return errors.New(res.Err)
+		}
+		for _, cmd := range res.Commands {
+			if err := stream.Send(commandToPB(cmd)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// WatchEvents streams the bus events the request picks.
+func (s *grpcServer) WatchEvents(in *pb.WatchEventsRequest, stream pb.Monitor_WatchEventsServer) error {
+	filter := EventFilter{Types: in.Types, Devices: deviceFilterFromPB(in.Filter)}
+	err := streamEvents(stream.Context(), s.ctx, s.events, filter, s.matchDevices, func(e Event) error {
+		return stream.Send(eventToPB(e))
+	})
+	if err == errStreamDrained {
+		return errServerDraining
+	}
+	return err
+}
+
+func (s *grpcServer) EnqueueCommand(ctx context.Context, in *pb.EnqueueCommandRequest) (*pb.EnqueueCommandReply, error) {
+	_, resp, err := s.enqueueCommand.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.EnqueueCommandReply), nil
+}
+
+func (s *grpcServer) AckCommand(ctx context.Context, in *pb.AckCommandRequest) (*pb.AckCommandReply, error) {
+	_, resp, err := s.ackCommand.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.AckCommandReply), nil
+}
+
+func (s *grpcServer) CommandHistory(ctx context.Context, in *pb.CommandHistoryRequest) (*pb.CommandHistoryReply, error) {
+	_, resp, err := s.commandHistory.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.CommandHistoryReply), nil
+}
+
+func (s *grpcServer) UploadFirmware(ctx context.Context, in *pb.UploadFirmwareRequest) (*pb.UploadFirmwareReply, error) {
+	_, resp, err := s.uploadFirmware.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.UploadFirmwareReply), nil
+}
+
+func (s *grpcServer) ListFirmware(ctx context.Context, in *pb.ListFirmwareRequest) (*pb.ListFirmwareReply, error) {
+	_, resp, err := s.listFirmware.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.ListFirmwareReply), nil
+}
+
+func (s *grpcServer) DownloadFirmware(ctx context.Context, in *pb.DownloadFirmwareRequest) (*pb.DownloadFirmwareReply, error) {
+	_, resp, err := s.downloadFirmware.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.DownloadFirmwareReply), nil
+}
+
+func (s *grpcServer) CreateCampaign(ctx context.Context, in *pb.CreateCampaignRequest) (*pb.CreateCampaignReply, error) {
+	_, resp, err := s.createCampaign.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.CreateCampaignReply), nil
+}
+
+func (s *grpcServer) GetCampaign(ctx context.Context, in *pb.GetCampaignRequest) (*pb.GetCampaignReply, error) {
+	_, resp, err := s.getCampaign.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.GetCampaignReply), nil
+}
+
+func (s *grpcServer) ListCampaigns(ctx context.Context, in *pb.ListCampaignsRequest) (*pb.ListCampaignsReply, error) {
+	_, resp, err := s.listCampaigns.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.ListCampaignsReply), nil
+}
+
+func (s *grpcServer) ControlCampaign(ctx context.Context, in *pb.ControlCampaignRequest) (*pb.ControlCampaignReply, error) {
+	_, resp, err := s.controlCampaign.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.ControlCampaignReply), nil
+}
+
+func (s *grpcServer) ReportFirmware(ctx context.Context, in *pb.ReportFirmwareRequest) (*pb.ReportFirmwareReply, error) {
+	_, resp, err := s.reportFirmware.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.ReportFirmwareReply), nil
+}
+
+func (s *grpcServer) SetTelemetrySchema(ctx context.Context, in *pb.SetTelemetrySchemaRequest) (*pb.SetTelemetrySchemaReply, error) {
+	_, resp, err := s.setTelemetrySchema.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.SetTelemetrySchemaReply), nil
+}
+
+func (s *grpcServer) GetTelemetrySchema(ctx context.Context, in *pb.GetTelemetrySchemaRequest) (*pb.GetTelemetrySchemaReply, error) {
+	_, resp, err := s.getTelemetrySchema.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.GetTelemetrySchemaReply), nil
+}
+
+func (s *grpcServer) ListTelemetrySchemas(ctx context.Context, in *pb.ListTelemetrySchemasRequest) (*pb.ListTelemetrySchemasReply, error) {
+	_, resp, err := s.listTelemetrySchemas.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.ListTelemetrySchemasReply), nil
+}
+
+func (s *grpcServer) DeleteTelemetrySchema(ctx context.Context, in *pb.DeleteTelemetrySchemaRequest) (*pb.DeleteTelemetrySchemaReply, error) {
+	_, resp, err := s.deleteTelemetrySchema.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.DeleteTelemetrySchemaReply), nil
+}
+
+func (s *grpcServer) ImportDevices(ctx context.Context, in *pb.ImportDevicesRequest) (*pb.ImportDevicesReply, error) {
+	_, resp, err := s.importDevices.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.ImportDevicesReply), nil
+}
+
+func (s *grpcServer) ExportDevices(ctx context.Context, in *pb.ExportDevicesRequest) (*pb.ExportDevicesReply, error) {
+	_, resp, err := s.exportDevices.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.ExportDevicesReply), nil
+}
+
+func (s *grpcServer) UpdateDevice(ctx context.Context, in *pb.UpdateDeviceRequest) (*pb.UpdateDeviceReply, error) {
+	_, resp, err := s.updateDevice.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.UpdateDeviceReply), nil
+}
+
+func (s *grpcServer) DeleteDevice(ctx context.Context, in *pb.DeleteDeviceRequest) (*pb.DeleteDeviceReply, error) {
+	_, resp, err := s.deleteDevice.ServeGRPC(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.DeleteDeviceReply), nil
+}
@@ -1,6 +1,7 @@
 package iotmonitor
 
 import (
+	"encoding/json"
 	"net/http"
 
 	"github.com/gorilla/mux"
@@ -9,7 +10,10 @@ import (
 	"golang.org/x/net/context"
 )
 
-func NewHTTPServer(ctx context.Context, endpoints Endpoints) http.Handler {
+// NewHTTPServer returns the JSON/HTTP transport. The events bus feeds the
+// /v1/events stream, which ends when ctx is done so that the server can
+// drain.
+func NewHTTPServer(ctx context.Context, endpoints Endpoints, events *EventBus) http.Handler {
 	m := mux.NewRouter()
 
 	registerHandler := httptransport.NewServer(
@@ -30,8 +34,427 @@ func NewHTTPServer(ctx context.Context, endpoints Endpoints) http.Handler {
 		encodeResponse,
 	)
 
+	createRuleHandler := httptransport.NewServer(
+		endpoints.CreateRuleEndpoint,
+		decodeCreateRuleRequest,
+		encodeResponse,
+	)
+
+	getRuleHandler := httptransport.NewServer(
+		endpoints.GetRuleEndpoint,
+		decodeGetRuleRequest,
+		encodeResponse,
+	)
+
+	updateRuleHandler := httptransport.NewServer(
+		endpoints.UpdateRuleEndpoint,
+		decodeUpdateRuleRequest,
+		encodeResponse,
+	)
+
+	deleteRuleHandler := httptransport.NewServer(
+		endpoints.DeleteRuleEndpoint,
+		decodeDeleteRuleRequest,
+		encodeResponse,
+	)
+
+	listRulesHandler := httptransport.NewServer(
+		endpoints.ListRulesEndpoint,
+		decodeListRulesRequest,
+		encodeResponse,
+	)
+
+	activeAlertsHandler := httptransport.NewServer(
+		endpoints.ActiveAlertsEndpoint,
+		decodeActiveAlertsRequest,
+		encodeResponse,
+	)
+
+	createWebhookHandler := httptransport.NewServer(
+		endpoints.CreateWebhookEndpoint,
+		decodeCreateWebhookRequest,
+		encodeResponse,
+	)
+
+	listWebhooksHandler := httptransport.NewServer(
+		endpoints.ListWebhooksEndpoint,
+		decodeListWebhooksRequest,
+		encodeResponse,
+	)
+
+	deleteWebhookHandler := httptransport.NewServer(
+		endpoints.DeleteWebhookEndpoint,
+		decodeDeleteWebhookRequest,
+		encodeResponse,
+	)
+
+	deadLettersHandler := httptransport.NewServer(
+		endpoints.DeadLettersEndpoint,
+		decodeDeadLettersRequest,
+		encodeResponse,
+	)
+
+	getDeviceHandler := httptransport.NewServer(
+		endpoints.GetDeviceEndpoint,
+		decodeGetDeviceRequest,
+		encodeResponse,
+	)
+
+	listDevicesHandler := httptransport.NewServer(
+		endpoints.ListDevicesEndpoint,
+		decodeListDevicesRequest,
+		encodeResponse,
+	)
+
+	createGeofenceHandler := httptransport.NewServer(
+		endpoints.CreateGeofenceEndpoint,
+		decodeCreateGeofenceRequest,
+		encodeResponse,
+	)
+
+	updateGeofenceHandler := httptransport.NewServer(
+		endpoints.UpdateGeofenceEndpoint,
+		decodeUpdateGeofenceRequest,
+		encodeResponse,
+	)
+
+	deleteGeofenceHandler := httptransport.NewServer(
+		endpoints.DeleteGeofenceEndpoint,
+		decodeDeleteGeofenceRequest,
+		encodeResponse,
+	)
+
+	listGeofencesHandler := httptransport.NewServer(
+		endpoints.ListGeofencesEndpoint,
+		decodeListGeofencesRequest,
+		encodeResponse,
+	)
+
+	nearbyDevicesHandler := httptransport.NewServer(
+		endpoints.NearbyDevicesEndpoint,
+		decodeNearbyDevicesRequest,
+		encodeResponse,
+	)
+
+	devicesInBoxHandler := httptransport.NewServer(
+		endpoints.DevicesInBoxEndpoint,
+		decodeDevicesInBoxRequest,
+		encodeResponse,
+	)
+
+	getStatusHandler := httptransport.NewServer(
+		endpoints.GetStatusEndpoint,
+		decodeGetStatusRequest,
+		encodeResponse,
+	)
+
+	batteryHistoryHandler := httptransport.NewServer(
+		endpoints.BatteryHistoryEndpoint,
+		decodeBatteryHistoryRequest,
+		encodeResponse,
+	)
+
+	queryTelemetryHandler := httptransport.NewServer(
+		endpoints.QueryTelemetryEndpoint,
+		decodeQueryTelemetryRequest,
+		encodeResponse,
+	)
+
+	aggregateTelemetryHandler := httptransport.NewServer(
+		endpoints.AggregateTelemetryEndpoint,
+		decodeAggregateTelemetryRequest,
+		encodeResponse,
+	)
+
+	setLabelsHandler := httptransport.NewServer(
+		endpoints.SetLabelsEndpoint,
+		decodeSetLabelsRequest,
+		encodeResponse,
+	)
+
+	listStatusesHandler := httptransport.NewServer(
+		endpoints.ListStatusesEndpoint,
+		decodeListStatusesRequest,
+		encodeResponse,
+	)
+
+	createGroupHandler := httptransport.NewServer(
+		endpoints.CreateGroupEndpoint,
+		decodeCreateGroupRequest,
+		encodeResponse,
+	)
+
+	updateGroupHandler := httptransport.NewServer(
+		endpoints.UpdateGroupEndpoint,
+		decodeUpdateGroupRequest,
+		encodeResponse,
+	)
+
+	deleteGroupHandler := httptransport.NewServer(
+		endpoints.DeleteGroupEndpoint,
+		decodeDeleteGroupRequest,
+		encodeResponse,
+	)
+
+	listGroupsHandler := httptransport.NewServer(
+		endpoints.ListGroupsEndpoint,
+		decodeListGroupsRequest,
+		encodeResponse,
+	)
+
+	addGroupMembersHandler := httptransport.NewServer(
+		endpoints.AddGroupMembersEndpoint,
+		decodeAddGroupMembersRequest,
+		encodeResponse,
+	)
+
+	removeGroupMembersHandler := httptransport.NewServer(
+		endpoints.RemoveGroupMembersEndpoint,
+		decodeRemoveGroupMembersRequest,
+		encodeResponse,
+	)
+
+	relayStatusHandler := httptransport.NewServer(
+		endpoints.RelayStatusEndpoint,
+		decodeRelayStatusRequest,
+		encodeResponse,
+	)
+
+	relayTelemetryHandler := httptransport.NewServer(
+		endpoints.RelayTelemetryEndpoint,
+		decodeRelayTelemetryRequest,
+		encodeResponse,
+	)
+
+	getTopologyHandler := httptransport.NewServer(
+		endpoints.GetTopologyEndpoint,
+		decodeGetTopologyRequest,
+		encodeResponse,
+	)
+
+	getTwinHandler := httptransport.NewServer(
+		endpoints.GetTwinEndpoint,
+		decodeGetTwinRequest,
+		encodeResponse,
+	)
+
+	updateDesiredHandler := httptransport.NewServer(
+		endpoints.UpdateDesiredEndpoint,
+		decodeUpdateDesiredRequest,
+		encodeResponse,
+	)
+
+	reportStateHandler := httptransport.NewServer(
+		endpoints.ReportStateEndpoint,
+		decodeReportStateRequest,
+		encodeResponse,
+	)
+
+	enqueueCommandHandler := httptransport.NewServer(
+		endpoints.EnqueueCommandEndpoint,
+		decodeEnqueueCommandRequest,
+		encodeResponse,
+	)
+
+	ackCommandHandler := httptransport.NewServer(
+		endpoints.AckCommandEndpoint,
+		decodeAckCommandRequest,
+		encodeResponse,
+	)
+
+	commandHistoryHandler := httptransport.NewServer(
+		endpoints.CommandHistoryEndpoint,
+		decodeCommandHistoryRequest,
+		encodeResponse,
+	)
+
+	uploadFirmwareHandler := httptransport.NewServer(
+		endpoints.UploadFirmwareEndpoint,
+		decodeUploadFirmwareRequest,
+		encodeResponse,
+	)
+
+	listFirmwareHandler := httptransport.NewServer(
+		endpoints.ListFirmwareEndpoint,
+		decodeListFirmwareRequest,
+		encodeResponse,
+	)
+
+	downloadFirmwareHandler := httptransport.NewServer(
+		endpoints.DownloadFirmwareEndpoint,
+		decodeDownloadFirmwareRequest,
+		encodeFirmwareImage,
+	)
+
+	createCampaignHandler := httptransport.NewServer(
+		endpoints.CreateCampaignEndpoint,
+		decodeCreateCampaignRequest,
+		encodeResponse,
+	)
+
+	getCampaignHandler := httptransport.NewServer(
+		endpoints.GetCampaignEndpoint,
+		decodeGetCampaignRequest,
+		encodeResponse,
+	)
+
+	listCampaignsHandler := httptransport.NewServer(
+		endpoints.ListCampaignsEndpoint,
+		decodeListCampaignsRequest,
+		encodeResponse,
+	)
+
+	controlCampaignHandler := httptransport.NewServer(
+		endpoints.ControlCampaignEndpoint,
+		decodeControlCampaignRequest,
+		encodeResponse,
+	)
+
+	reportFirmwareHandler := httptransport.NewServer(
+		endpoints.ReportFirmwareEndpoint,
+		decodeReportFirmwareRequest,
+		encodeResponse,
+	)
+
+	setTelemetrySchemaHandler := httptransport.NewServer(
+		endpoints.SetTelemetrySchemaEndpoint,
+		decodeSetTelemetrySchemaRequest,
+		encodeResponse,
+	)
+
+	getTelemetrySchemaHandler := httptransport.NewServer(
+		endpoints.GetTelemetrySchemaEndpoint,
+		decodeGetTelemetrySchemaRequest,
+		encodeResponse,
+	)
+
+	listTelemetrySchemasHandler := httptransport.NewServer(
+		endpoints.ListTelemetrySchemasEndpoint,
+		decodeListTelemetrySchemasRequest,
+		encodeResponse,
+	)
+
+	deleteTelemetrySchemaHandler := httptransport.NewServer(
+		endpoints.DeleteTelemetrySchemaEndpoint,
+		decodeDeleteTelemetrySchemaRequest,
+		encodeResponse,
+	)
+
+	importDevicesHandler := httptransport.NewServer(
+		endpoints.ImportDevicesEndpoint,
+		decodeImportDevicesRequest,
+		encodeResponse,
+	)
+
+	exportDevicesHandler := httptransport.NewServer(
+		endpoints.ExportDevicesEndpoint,
+		decodeExportDevicesRequest,
+		encodeDeviceExport,
+	)
+
+	updateDeviceHandler := httptransport.NewServer(
+		endpoints.UpdateDeviceEndpoint,
+		decodeUpdateDeviceRequest,
+		encodeResponse,
+	)
+
+	deleteDeviceHandler := httptransport.NewServer(
+		endpoints.DeleteDeviceEndpoint,
+		decodeDeleteDeviceRequest,
+		encodeResponse,
+	)
+
 	m.Handle("/v1/devices", registerHandler).Methods("POST")
 	m.Handle("/v1/devices/{id}/status", statusUpdateHandler).Methods("PUT")
 	m.Handle("/v1/devices/{id}/telemetry", telemetryUpdateHandler).Methods("PUT")
+	m.Handle("/v1/rules", createRuleHandler).Methods("POST")
+	m.Handle("/v1/rules", listRulesHandler).Methods("GET")
+	m.Handle("/v1/rules/{id}", getRuleHandler).Methods("GET")
+	m.Handle("/v1/rules/{id}", updateRuleHandler).Methods("PUT")
+	m.Handle("/v1/rules/{id}", deleteRuleHandler).Methods("DELETE")
+	m.Handle("/v1/alerts", activeAlertsHandler).Methods("GET")
+	m.Handle("/v1/webhooks", createWebhookHandler).Methods("POST")
+	m.Handle("/v1/webhooks", listWebhooksHandler).Methods("GET")
+	m.Handle("/v1/webhooks/{id}", deleteWebhookHandler).Methods("DELETE")
+	m.Handle("/v1/webhooks/deadletters", deadLettersHandler).Methods("GET")
+	m.Handle("/v1/devices/{id}", getDeviceHandler).Methods("GET")
+	m.Handle("/v1/devices", listDevicesHandler).Methods("GET")
+	m.Handle("/v1/geofences", createGeofenceHandler).Methods("POST")
+	m.Handle("/v1/geofences/{id}", updateGeofenceHandler).Methods("PUT")
+	m.Handle("/v1/geofences/{id}", deleteGeofenceHandler).Methods("DELETE")
+	m.Handle("/v1/geofences", listGeofencesHandler).Methods("GET")
+	m.Handle("/v1/locations/nearby", nearbyDevicesHandler).Methods("GET")
+	m.Handle("/v1/locations/within", devicesInBoxHandler).Methods("GET")
+	m.Handle("/v1/devices/{id}/status", getStatusHandler).Methods("GET")
+	m.Handle("/v1/devices/{id}/battery", batteryHistoryHandler).Methods("GET")
+	m.Handle("/v1/devices/{id}/telemetry/{metric}", queryTelemetryHandler).Methods("GET")
+	m.Handle("/v1/telemetry/aggregate", aggregateTelemetryHandler).Methods("GET")
+	m.Handle("/v1/devices/{id}/labels", setLabelsHandler).Methods("PUT")
+	m.Handle("/v1/statuses", listStatusesHandler).Methods("GET")
+	m.Handle("/v1/groups", createGroupHandler).Methods("POST")
+	m.Handle("/v1/groups/{id}", updateGroupHandler).Methods("PUT")
+	m.Handle("/v1/groups/{id}", deleteGroupHandler).Methods("DELETE")
+	m.Handle("/v1/groups", listGroupsHandler).Methods("GET")
+	m.Handle("/v1/groups/{id}/devices", addGroupMembersHandler).Methods("POST")
+	m.Handle("/v1/groups/{id}/devices", removeGroupMembersHandler).Methods("DELETE")
+	m.Handle("/v1/gateways/{id}/devices/{device}/status", relayStatusHandler).Methods("PUT")
+	m.Handle("/v1/gateways/{id}/devices/{device}/telemetry", relayTelemetryHandler).Methods("PUT")
+	m.Handle("/v1/devices/{id}/topology", getTopologyHandler).Methods("GET")
+	m.Handle("/v1/devices/{id}/twin", getTwinHandler).Methods("GET")
+	m.Handle("/v1/devices/{id}/twin/desired", updateDesiredHandler).Methods("PATCH")
+	m.Handle("/v1/devices/{id}/twin/reported", reportStateHandler).Methods("PATCH")
+	m.Handle("/v1/devices/{id}/commands", enqueueCommandHandler).Methods("POST")
+	m.Handle("/v1/devices/{id}/commands/{command}/ack", ackCommandHandler).Methods("POST")
+	m.Handle("/v1/devices/{id}/commands", commandHistoryHandler).Methods("GET")
+	m.Handle("/v1/firmware", uploadFirmwareHandler).Methods("POST")
+	m.Handle("/v1/firmware", listFirmwareHandler).Methods("GET")
+	m.Handle("/v1/firmware/{id}/image", downloadFirmwareHandler).Methods("GET")
+	m.Handle("/v1/campaigns", createCampaignHandler).Methods("POST")
+	m.Handle("/v1/campaigns/{id}", getCampaignHandler).Methods("GET")
+	m.Handle("/v1/campaigns", listCampaignsHandler).Methods("GET")
+	m.Handle("/v1/campaigns/{id}/{action}", controlCampaignHandler).Methods("POST")
+	m.Handle("/v1/devices/{id}/firmware", reportFirmwareHandler).Methods("PUT")
+	m.Handle("/v1/schemas/{type}", setTelemetrySchemaHandler).Methods("PUT")
+	m.Handle("/v1/schemas/{type}", getTelemetrySchemaHandler).Methods("GET")
+	m.Handle("/v1/schemas", listTelemetrySchemasHandler).Methods("GET")
+	m.Handle("/v1/schemas/{type}", deleteTelemetrySchemaHandler).Methods("DELETE")
+	m.Handle("/v1/registry/import", importDevicesHandler).Methods("POST")
+	m.Handle("/v1/registry/export", exportDevicesHandler).Methods("GET")
+	m.Handle("/v1/devices/{id}", updateDeviceHandler).Methods("PATCH")
+	m.Handle("/v1/devices/{id}", deleteDeviceHandler).Methods("DELETE")
+	m.Handle("/v1/events", watchEventsHandler(ctx, endpoints, events)).Methods("GET")
 	return m
 }
+
+// watchEventsHandler streams the events a request picks as newline-delimited
+// JSON, flushing each one. A stream that fails after it has started ends
+// with a line carrying only the error.
+func watchEventsHandler(ctx context.Context, endpoints Endpoints, events *EventBus) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		filter, err := decodeEventFilter(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		enc := json.NewEncoder(w)
+		err = streamEvents(r.Context(), ctx, events, filter, endpoints.ListDevices, func(e Event) error {
+			if err := enc.Encode(e); err != nil {
+				return err
+			}
+			flusher.Flush()
+			return nil
+		})
+		if err != nil {
+			enc.Encode(watchEventsLine{Err: err.Error()})
+		}
+	})
+}
@@ -1,7 +1,9 @@
 package iotmonitor
 
 import (
+	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/garyburd/redigo/redis"
@@ -11,42 +13,276 @@ import (
 )
 
 type Service interface {
-	RegisterDevice(ctx context.Context, name, owner, deviceType string) (id uint64, err error)
+	RegisterDevice(ctx context.Context, name, owner, deviceType string, gatewayID uint64, labels map[string]string) (id uint64, err error)
 	UpdateStatus(ctx context.Context, id uint64, lat, long, alt float32, battery uint32) (bool, error)
-	SubmitTelemetry(ctx context.Context, id uint64, readings map[string]float32) (bool, error)
+	SubmitTelemetry(ctx context.Context, id uint64, readings map[string]TelemetryValue) (bool, []SchemaViolation, error)
+	RelayStatus(ctx context.Context, gatewayID, id uint64, lat, long, alt float32, battery uint32) (bool, error)
+	RelayTelemetry(ctx context.Context, gatewayID, id uint64, readings map[string]TelemetryValue) (bool, []SchemaViolation, error)
+	GetTopology(ctx context.Context, id uint64) (TopologyNode, error)
+
+	GetTwin(ctx context.Context, id uint64) (Twin, error)
+	UpdateDesired(ctx context.Context, id uint64, patch TwinDocument, version int64) (Twin, error)
+	ReportState(ctx context.Context, id uint64, patch TwinDocument) (Twin, error)
+	DeliverDesired(ctx context.Context, id uint64) (*DesiredState, error)
+
+	EnqueueCommand(ctx context.Context, id uint64, name string, args map[string]interface{}, ttl int64) (uint64, error)
+	DeliverCommands(ctx context.Context, id uint64) ([]Command, error)
+	AckCommand(ctx context.Context, id, commandID uint64, success bool, result string) (bool, error)
+	CommandHistory(ctx context.Context, id uint64, limit int) ([]Command, error)
+
+	UploadFirmware(ctx context.Context, deviceType, version string, data []byte) (FirmwareArtifact, error)
+	ListFirmware(ctx context.Context) ([]FirmwareArtifact, error)
+	DownloadFirmware(ctx context.Context, id uint64) (FirmwareImage, error)
+	CreateCampaign(ctx context.Context, campaign Campaign) (uint64, error)
+	GetCampaign(ctx context.Context, id uint64) (Campaign, error)
+	ListCampaigns(ctx context.Context) ([]Campaign, error)
+	ControlCampaign(ctx context.Context, id uint64, action string) (Campaign, error)
+	ReportFirmware(ctx context.Context, id uint64, report FirmwareReport) (bool, error)
+	OfferFirmware(ctx context.Context, id uint64) (*FirmwareOffer, error)
+
+	SetTelemetrySchema(ctx context.Context, schema TelemetrySchema) (bool, error)
+	GetTelemetrySchema(ctx context.Context, deviceType string) (TelemetrySchema, error)
+	ListTelemetrySchemas(ctx context.Context) ([]TelemetrySchema, error)
+	DeleteTelemetrySchema(ctx context.Context, deviceType string) (bool, error)
+
+	GetDevice(ctx context.Context, id uint64) (Device, error)
+	UpdateDevice(ctx context.Context, id uint64, name, owner string) (bool, error)
+	DeleteDevice(ctx context.Context, id uint64) (bool, error)
+	ListDevices(ctx context.Context, filter DeviceFilter) ([]Device, error)
+	ImportDevices(ctx context.Context, devices []DeviceImport, dryRun bool) ([]ImportResult, error)
+	ExportDevices(ctx context.Context, filter DeviceFilter) ([]DeviceExport, error)
+	SetLabels(ctx context.Context, id uint64, labels map[string]string) (bool, error)
+	ListStatuses(ctx context.Context, filter DeviceFilter) ([]DeviceStatus, error)
+	GetStatus(ctx context.Context, id uint64) (DeviceStatus, error)
+	BatteryHistory(ctx context.Context, id uint64, since int64) ([]BatterySample, error)
+	QueryTelemetry(ctx context.Context, id uint64, metric string, from, to int64) (TelemetrySeries, error)
+	AggregateTelemetry(ctx context.Context, q FleetQuery) (FleetAggregate, error)
+
+	CreateGroup(ctx context.Context, group Group) (uint64, error)
+	UpdateGroup(ctx context.Context, group Group) (bool, error)
+	DeleteGroup(ctx context.Context, id uint64) (bool, error)
+	ListGroups(ctx context.Context) ([]Group, error)
+	AddGroupMembers(ctx context.Context, groupID uint64, deviceIDs []uint64) (bool, error)
+	RemoveGroupMembers(ctx context.Context, groupID uint64, deviceIDs []uint64) (bool, error)
+
+	CreateRule(ctx context.Context, rule Rule) (uint64, error)
+	GetRule(ctx context.Context, id uint64) (Rule, error)
+	UpdateRule(ctx context.Context, rule Rule) (bool, error)
+	DeleteRule(ctx context.Context, id uint64) (bool, error)
+	ListRules(ctx context.Context) ([]Rule, error)
+	ActiveAlerts(ctx context.Context, filter DeviceFilter) ([]Alert, error)
+
+	CreateWebhook(ctx context.Context, hook Webhook) (uint64, error)
+	ListWebhooks(ctx context.Context) ([]Webhook, error)
+	DeleteWebhook(ctx context.Context, id uint64) (bool, error)
+	DeadLetters(ctx context.Context, limit int) ([]DeadLetter, error)
+
+	CreateGeofence(ctx context.Context, fence Geofence) (uint64, error)
+	UpdateGeofence(ctx context.Context, fence Geofence) (bool, error)
+	DeleteGeofence(ctx context.Context, id uint64) (bool, error)
+	ListGeofences(ctx context.Context) ([]Geofence, error)
+
+	NearbyDevices(ctx context.Context, lat, long, radius float64, filter DeviceFilter) ([]DeviceLocation, error)
+	DevicesInBox(ctx context.Context, box BoundingBox, filter DeviceFilter) ([]DeviceLocation, error)
 }
 
 type Middleware func(Service) Service
 
-func NewService() Service {
-	return &monitorService{}
+var errDeviceNotFound = errors.New("device not found")
+
+// Config configures a Service: where it keeps its state, how long it keeps
+// telemetry and where it keeps firmware images.
+type Config struct {
+	Store       StoreConfig
+	Retention   Retention
+	FirmwareDir string
+}
+
+// NewService returns a Service backed by Redis that publishes device and
+// alert events to the given bus. Background workers sharing its state are
+// given the same StoreConfig.
+func NewService(events *EventBus, cfg Config) (Service, error) {
+	if cfg.Store.Backend == "" {
+		cfg.Store.Backend = StoreBackendRedis
+	}
+	if err := cfg.Store.validate(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Retention.validate(); err != nil {
+		return nil, err
+	}
+	return &monitorService{
+		store:     cfg.Store,
+		retention: cfg.Retention,
+		events:    events,
+		fences:    newGeofenceIndex(),
+		firmware:  firmwareStore{dir: cfg.FirmwareDir},
+	}, nil
+}
+
+type monitorService struct {
+	store     StoreConfig
+	retention Retention
+	events    *EventBus
+	fences    *geofenceIndex
+	firmware  firmwareStore
+}
+
+// StoreBackendRedis is the only store backend, and the default.
+const StoreBackendRedis = "redis"
+
+// StoreConfig says where the monitor keeps its state.
+type StoreConfig struct {
+	Backend       string
+	RedisAddr     string
+	RedisPassword string
+	RedisDB       int
+	DialTimeout   time.Duration // 0 for no limit
+}
+
+func (cfg StoreConfig) validate() error {
+	if cfg.Backend != StoreBackendRedis {
+		return fmt.Errorf("unknown store backend %q, want %s", cfg.Backend, StoreBackendRedis)
+	}
+	if cfg.RedisAddr == "" {
+		return errors.New("redis address must be set")
+	}
+	if cfg.RedisDB < 0 {
+		return errors.New("redis database must not be negative")
+	}
+	return nil
+}
+
+func (cfg StoreConfig) dial(extra ...redis.DialOption) (redis.Conn, error) {
+	options := []redis.DialOption{redis.DialDatabase(cfg.RedisDB)}
+	if cfg.RedisPassword != "" {
+		options = append(options, redis.DialPassword(cfg.RedisPassword))
+	}
+	if cfg.DialTimeout > 0 {
+		options = append(options, redis.DialConnectTimeout(cfg.DialTimeout))
+	}
+	return redis.Dial("tcp", cfg.RedisAddr, append(options, extra...)...)
+}
+
+type deviceRecord struct {
+	Name       string `redis:"name"`
+	Owner      string `redis:"owner"`
+	DeviceType string `redis:"device_type"`
+	ID         uint64 `redis:"id"`
+	Gateway    uint64 `redis:"gateway"`
+
+	FirmwareVersion string `redis:"firmware_version"`
+}
+
+// device returns the record as the API's Device, with the given labels.
+func (r deviceRecord) device(labels map[string]string) Device {
+	return Device{ID: r.ID, Name: r.Name, Owner: r.Owner, DeviceType: r.DeviceType, GatewayID: r.Gateway, FirmwareVersion: r.FirmwareVersion, Labels: labels}
+}
+
+type statusRecord struct {
+	Latitude  float32 `redis:"lat"`
+	Longitude float32 `redis:"long"`
+	Altitude  float32 `redis:"alt"`
+	Battery   uint32  `redis:"battery"`
+	Timestamp int64   `redis:"timestamp"`
+	Speed     float64 `redis:"speed"`
+	Heading   float64 `redis:"heading"`
+	ClimbRate float64 `redis:"climb"`
+	Distance  float64 `redis:"distance"`
+	Glitch    bool    `redis:"glitch"`
+}
+
+func readDevice(c redis.Conn, id uint64) (deviceRecord, error) {
+	var d deviceRecord
+	v, err := redis.Values(c.Do("HGETALL", fmt.Sprintf("device:%d", id)))
+	if err != nil {
+		return d, err
+	}
+	if len(v) == 0 {
+		return d, errDeviceNotFound
+	}
+	err = redis.ScanStruct(v, &d)
+	return d, err
+}
+
+func readStatus(c redis.Conn, id uint64) (statusRecord, error) {
+	var s statusRecord
+	v, err := redis.Values(c.Do("HGETALL", fmt.Sprintf("status:%d", id)))
+	if err != nil || len(v) == 0 {
+		return s, err
+	}
+	err = redis.ScanStruct(v, &s)
+	return s, err
+}
+
+// telemetryTimesKey holds when each of a device's readings was last taken.
+func telemetryTimesKey(id uint64) string {
+	return fmt.Sprintf("telemetry:%d:times", id)
 }
 
-type monitorService struct{}
+func readTelemetry(c redis.Conn, id uint64) (sample, error) {
+	s := sample{values: make(map[string]float64), times: make(map[string]int64)}
+	m, err := redis.StringMap(c.Do("HGETALL", fmt.Sprintf("telemetry:%d", id)))
+	if err != nil {
+		return s, err
+	}
+	times, err := redis.StringMap(c.Do("HGETALL", telemetryTimesKey(id)))
+	if err != nil {
+		return s, err
+	}
+	for k, v := range times {
+		if t, err := strconv.ParseInt(v, 10, 64); err == nil {
+			s.times[k] = t
+		}
+	}
+	for k, v := range m {
+		if k == "timestamp" {
+			s.timestamp, _ = strconv.ParseInt(v, 10, 64)
+			continue
+		}
+		if tv, err := decodeStored(v); err == nil {
+			if f, ok := tv.Float(); ok {
+				s.values[k] = f
+			}
+		}
+	}
+	return s, nil
+}
 
-func (monitorService) RegisterDevice(ctx context.Context, name, owner, deviceType string) (id uint64, err error) {
+func (s monitorService) RegisterDevice(ctx context.Context, name, owner, deviceType string, gatewayID uint64, labels map[string]string) (id uint64, err error) {
 	fmt.Printf("Registering device name %s, type %s\n", name, deviceType)
 
-	c, err := redis.Dial("tcp", ":6379")
+	if err = validateLabels(labels); err != nil {
+		return
+	}
+
+	c, err := s.store.dial()
 	if err != nil {
 		// handle error
 		return
 	}
 	defer c.Close()
+
+	var gatewayOnline bool
+	if gatewayID != 0 {
+		if _, err = readDevice(c, gatewayID); err != nil {
+			return 0, fmt.Errorf("gateway %v", err)
+		}
+		if gatewayOnline, err = redis.Bool(c.Do("SISMEMBER", onlineKey, gatewayID)); err != nil {
+			return
+		}
+	}
+
 	id, err = redis.Uint64(c.Do("INCR", "id:devices"))
 	if err != nil {
 		return
 	}
-	var newDevice struct {
-		Name       string `redis:"name"`
-		Owner      string `redis:"owner"`
-		DeviceType string `redis:"device_type"`
-		ID         uint64 `redis:"id"`
-	}
+	var newDevice deviceRecord
 	newDevice.DeviceType = deviceType
 	newDevice.ID = id
 	newDevice.Name = name
 	newDevice.Owner = owner
+	newDevice.Gateway = gatewayID
 
 	deviceKey := fmt.Sprintf("device:%d", id)
 	if _, err := c.Do("HMSET", redis.Args{}.Add(deviceKey).AddFlat(&newDevice)...); err != nil {
@@ -55,21 +291,33 @@ func (monitorService) RegisterDevice(ctx context.Context, name, owner, deviceTyp
 		return 0, err
 	}
 
-	_, err = c.Do("SADD", "devices", id)
+	if _, err = c.Do("SADD", "devices", id); err != nil {
+		return 0, err
+	}
 
+	if err = writeLabels(c, id, labels); err != nil {
+		return 0, err
+	}
+
+	if gatewayID != 0 {
+		if _, err = c.Do("SADD", gatewayChildrenKey(gatewayID), id); err != nil {
+			return 0, err
+		}
+		if !gatewayOnline {
+			if _, err = c.Do("SADD", unreachableKey, id); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	s.events.Publish(Event{Type: EventDeviceRegistered, DeviceID: id, Data: newDevice.device(labels)})
 	return
 }
 
-func (monitorService) UpdateStatus(ctx context.Context, id uint64, lat, long, alt float32, battery uint32) (bool, error) {
+func (s monitorService) UpdateStatus(ctx context.Context, id uint64, lat, long, alt float32, battery uint32) (bool, error) {
 	fmt.Printf("Updating status for device %d, battery left %d .\n", id, battery)
 
-	var lastStatus struct {
-		Latitude  float32 `redis:"lat"`
-		Longitude float32 `redis:"long"`
-		Altitude  float32 `redis:"alt"`
-		Battery   uint32  `redis:"battery"`
-		Timestamp int64   `redis:"timestamp"`
-	}
+	var lastStatus statusRecord
 	lastStatus.Altitude = alt
 	lastStatus.Longitude = long
 	lastStatus.Latitude = lat
@@ -77,44 +325,183 @@ func (monitorService) UpdateStatus(ctx context.Context, id uint64, lat, long, al
 	lastStatus.Timestamp = makeTimestamp()
 
 	statusKey := fmt.Sprintf("status:%d", id)
-	c, err := redis.Dial("tcp", ":6379")
+	c, err := s.store.dial()
 	if err != nil {
 		// handle error
 		return false, err
 	}
 	defer c.Close()
 
+	prevStatus, err := readStatus(c, id)
+	if err != nil {
+		fmt.Println(err)
+		return false, err
+	}
+
+	motion, err := applyMotion(c, id, &lastStatus)
+	if err != nil {
+		fmt.Printf("Failed to compute motion for device %d\n", id)
+		fmt.Println(err)
+	}
+
 	if _, err := c.Do("HMSET", redis.Args{}.Add(statusKey).AddFlat(&lastStatus)...); err != nil {
 		fmt.Printf("Failed to HMSET status update %s\n", statusKey)
 		fmt.Println(err)
 		return false, err
 	}
 
+	if err := recordBattery(c, id, battery, lastStatus.Timestamp); err != nil {
+		fmt.Printf("Failed to record battery history for device %d\n", id)
+		fmt.Println(err)
+	}
+
+	if lastStatus.Glitch {
+		fmt.Printf("Device %d reported an implausible position, flagged as a GPS glitch\n", id)
+	} else if err := indexLocation(c, id, lat, long); err != nil {
+		fmt.Printf("Failed to index location of device %d\n", id)
+		fmt.Println(err)
+	}
+
+	s.seen(c, id, lastStatus.Timestamp)
+	s.events.Publish(Event{Type: EventStatusUpdated, DeviceID: id, Timestamp: lastStatus.Timestamp, Data: lastStatus.status(id, motion)})
+
+	alerts, err := evaluateRules(c, id, prevStatus.sample(), lastStatus.sample())
+	if err != nil {
+		fmt.Printf("Failed to evaluate rules for device %d\n", id)
+		fmt.Println(err)
+	}
+	for _, a := range alerts {
+		s.events.Publish(alertEvent(a))
+	}
+
+	if !lastStatus.Glitch {
+		s.checkGeofences(c, id, lastStatus)
+	}
+
 	return true, nil
 }
 
-func (monitorService) SubmitTelemetry(ctx context.Context, id uint64, readings map[string]float32) (bool, error) {
+// SubmitTelemetry records a device's readings after checking them against
+// its type's schema, if it has one. Violations are returned whether or not
+// the readings were accepted.
+func (s monitorService) SubmitTelemetry(ctx context.Context, id uint64, readings map[string]TelemetryValue) (bool, []SchemaViolation, error) {
 	fmt.Printf("Submitting telemetry for device %d,  %+v\n", id, readings)
 
+	for name, v := range readings {
+		if err := v.validate(); err != nil {
+			return false, nil, fmt.Errorf("%s: %v", name, err)
+		}
+	}
+
 	telemetryKey := fmt.Sprintf("telemetry:%d", id)
-	c, err := redis.Dial("tcp", ":6379")
+	c, err := s.store.dial()
 	if err != nil {
 		// handle error
-		return false, err
+		return false, nil, err
 	}
 	defer c.Close()
 
-	if _, err := c.Do("HMSET", redis.Args{}.Add(telemetryKey).AddFlat(readings)...); err != nil {
+	readings, violations, err := applySchema(c, id, readings)
+	if err != nil {
+		return false, violations, err
+	}
+	if len(readings) == 0 {
+		return true, violations, nil
+	}
+
+	prevTelemetry, err := readTelemetry(c, id)
+	if err != nil {
 		fmt.Println(err)
-		return false, err
+		return false, violations, err
 	}
+	timestamp := makeTimestamp()
 
-	if _, err := c.Do("HMSET", telemetryKey, "timestamp", makeTimestamp()); err != nil {
+	args := redis.Args{}.Add(telemetryKey)
+	times := redis.Args{}.Add(telemetryTimesKey(id))
+	for name, v := range readings {
+		stored, err := v.encodeStored()
+		if err != nil {
+			return false, violations, err
+		}
+		args = args.Add(name, stored)
+		times = times.Add(name, timestamp)
+	}
+	if _, err := c.Do("HMSET", args...); err != nil {
 		fmt.Println(err)
-		return false, err
+		return false, violations, err
+	}
+	if _, err := c.Do("HMSET", times...); err != nil {
+		fmt.Println(err)
+		return false, violations, err
 	}
 
-	return true, nil
+	if _, err := c.Do("HMSET", telemetryKey, "timestamp", timestamp); err != nil {
+		fmt.Println(err)
+		return false, violations, err
+	}
+
+	if err := recordTelemetry(c, id, timestamp, readings); err != nil {
+		fmt.Printf("Failed to record telemetry history for device %d\n", id)
+		fmt.Println(err)
+	}
+
+	current := sample{values: make(map[string]float64, len(readings)), timestamp: timestamp}
+	for k, v := range readings {
+		if f, ok := v.Float(); ok {
+			current.values[k] = f
+		}
+	}
+	s.seen(c, id, timestamp)
+	s.events.Publish(Event{Type: EventTelemetrySubmitted, DeviceID: id, Timestamp: timestamp, Data: readings})
+
+	alerts, err := evaluateRules(c, id, prevTelemetry, current)
+	if err != nil {
+		fmt.Printf("Failed to evaluate rules for device %d\n", id)
+		fmt.Println(err)
+	}
+	for _, a := range alerts {
+		s.events.Publish(alertEvent(a))
+	}
+
+	return true, violations, nil
+}
+
+// seen records a heartbeat for the device, announcing it if it was offline.
+func (s monitorService) seen(c redis.Conn, id uint64, ts int64) {
+	cameOnline, err := markSeen(c, id, ts)
+	if err != nil {
+		fmt.Printf("Failed to record heartbeat for device %d\n", id)
+		fmt.Println(err)
+		return
+	}
+	if cameOnline {
+		s.events.Publish(Event{Type: EventDeviceOnline, DeviceID: id, Timestamp: ts})
+		if err := setReachable(c, s.events, id, true, ts); err != nil {
+			fmt.Printf("Failed to mark the children of device %d reachable\n", id)
+			fmt.Println(err)
+		}
+	}
+}
+
+// checkGeofences publishes the enter/exit events and alerts caused by a
+// status update.
+func (s monitorService) checkGeofences(c redis.Conn, id uint64, status statusRecord) {
+	device, err := readDevice(c, id)
+	if err == nil {
+		var events []Event
+		var alerts []Alert
+		events, alerts, err = s.fences.evaluate(c, device, float64(status.Latitude), float64(status.Longitude), status.Timestamp)
+		for _, e := range events {
+			s.events.Publish(e)
+		}
+		for _, a := range alerts {
+			s.events.Publish(alertEvent(a))
+		}
+	}
+	if err != nil {
+		fmt.Printf("Failed to evaluate geofences for device %d\n", id)
+		fmt.Println(err)
+	}
 }
 
 func makeTimestamp() int64 {
@@ -125,32 +512,63 @@ type serviceInstrumentingMiddleware struct {
 	telemetryUpdates  metrics.Counter
 	devicesRegistered metrics.Counter
 	statusUpdates     metrics.Counter
-	next              Service
+	schemaViolations  metrics.Counter
+	Service
 }
 
-func ServiceInstrumentingMiddleware(telemetryUpdates, devicesRegistered, statusUpdates metrics.Counter) Middleware {
+// ServiceInstrumentingMiddleware counts registrations, updates and schema
+// violations. schemaViolations must take "reason" and "action" labels.
+func ServiceInstrumentingMiddleware(telemetryUpdates, devicesRegistered, statusUpdates, schemaViolations metrics.Counter) Middleware {
 	return func(next Service) Service {
 		return serviceInstrumentingMiddleware{
 			telemetryUpdates:  telemetryUpdates,
 			statusUpdates:     statusUpdates,
 			devicesRegistered: devicesRegistered,
-			next:              next,
+			schemaViolations:  schemaViolations,
+			Service:           next,
 		}
 	}
 }
 
-func (mw serviceInstrumentingMiddleware) RegisterDevice(ctx context.Context, name, owner, deviceType string) (id uint64, err error) {
-	v, err := mw.next.RegisterDevice(ctx, name, owner, deviceType)
+func (mw serviceInstrumentingMiddleware) countViolations(violations []SchemaViolation) {
+	for _, v := range violations {
+		mw.schemaViolations.With("reason", v.Reason, "action", v.Action).Add(1)
+	}
+}
+
+func (mw serviceInstrumentingMiddleware) RegisterDevice(ctx context.Context, name, owner, deviceType string, gatewayID uint64, labels map[string]string) (id uint64, err error) {
+	v, err := mw.Service.RegisterDevice(ctx, name, owner, deviceType, gatewayID, labels)
 	mw.devicesRegistered.Add(float64(1))
 	return v, err
 }
+func (mw serviceInstrumentingMiddleware) ImportDevices(ctx context.Context, devices []DeviceImport, dryRun bool) ([]ImportResult, error) {
+	v, err := mw.Service.ImportDevices(ctx, devices, dryRun)
+	for _, r := range v {
+		if r.DeviceID != 0 {
+			mw.devicesRegistered.Add(float64(1))
+		}
+	}
+	return v, err
+}
 func (mw serviceInstrumentingMiddleware) UpdateStatus(ctx context.Context, id uint64, lat, long, alt float32, battery uint32) (bool, error) {
-	v, err := mw.next.UpdateStatus(ctx, id, lat, long, alt, battery)
+	v, err := mw.Service.UpdateStatus(ctx, id, lat, long, alt, battery)
 	mw.statusUpdates.Add(float64(1))
 	return v, err
 }
-func (mw serviceInstrumentingMiddleware) SubmitTelemetry(ctx context.Context, id uint64, readings map[string]float32) (bool, error) {
-	v, err := mw.next.SubmitTelemetry(ctx, id, readings)
+func (mw serviceInstrumentingMiddleware) SubmitTelemetry(ctx context.Context, id uint64, readings map[string]TelemetryValue) (bool, []SchemaViolation, error) {
+	v, violations, err := mw.Service.SubmitTelemetry(ctx, id, readings)
 	mw.telemetryUpdates.Add(float64(1))
+	mw.countViolations(violations)
+	return v, violations, err
+}
+func (mw serviceInstrumentingMiddleware) RelayStatus(ctx context.Context, gatewayID, id uint64, lat, long, alt float32, battery uint32) (bool, error) {
+	v, err := mw.Service.RelayStatus(ctx, gatewayID, id, lat, long, alt, battery)
+	mw.statusUpdates.Add(float64(1))
 	return v, err
 }
+func (mw serviceInstrumentingMiddleware) RelayTelemetry(ctx context.Context, gatewayID, id uint64, readings map[string]TelemetryValue) (bool, []SchemaViolation, error) {
+	v, violations, err := mw.Service.RelayTelemetry(ctx, gatewayID, id, readings)
+	mw.telemetryUpdates.Add(float64(1))
+	mw.countViolations(violations)
+	return v, violations, err
+}
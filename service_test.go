@@ -0,0 +1,44 @@
+package iotmonitor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewService(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{"defaults", Config{Store: StoreConfig{RedisAddr: ":6379"}}, false},
+		{"configured", Config{Store: StoreConfig{Backend: StoreBackendRedis, RedisAddr: "redis:6379", RedisDB: 2}, Retention: Retention{Raw: time.Hour, Hourly: 24 * time.Hour}}, false},
+		{"unknown backend", Config{Store: StoreConfig{Backend: "etcd", RedisAddr: ":6379"}}, true},
+		{"no address", Config{}, true},
+		{"negative database", Config{Store: StoreConfig{RedisAddr: ":6379", RedisDB: -1}}, true},
+		{"bad retention", Config{Store: StoreConfig{RedisAddr: ":6379"}, Retention: Retention{Raw: time.Hour}}, true},
+	}
+	for _, tt := range tests {
+		if _, err := NewService(NewEventBus(), tt.cfg); (err != nil) != tt.wantErr {
+			t.Errorf("%s: NewService() = %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestNewServiceKeepsItsOwnConfig(t *testing.T) {
+	first, err := NewService(NewEventBus(), Config{Store: StoreConfig{RedisAddr: "a:6379"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := NewService(NewEventBus(), Config{Store: StoreConfig{RedisAddr: "b:6379", RedisDB: 1}, Retention: Retention{Raw: time.Hour, Hourly: time.Hour}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, b := first.(*monitorService), second.(*monitorService)
+	if a.store.RedisAddr != "a:6379" || a.store.Backend != StoreBackendRedis || a.retention != (Retention{}) {
+		t.Errorf("first service has %+v, %+v", a.store, a.retention)
+	}
+	if b.store.RedisAddr != "b:6379" || b.store.RedisDB != 1 || b.retention.Raw != time.Hour {
+		t.Errorf("second service has %+v, %+v", b.store, b.retention)
+	}
+}
@@ -0,0 +1,168 @@
+package iotmonitor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Telemetry value types
+const (
+	TelemetryDouble = "double"
+	TelemetryInt    = "int"
+	TelemetryBool   = "bool"
+	TelemetryString = "string"
+	TelemetryVector = "vector"
+)
+
+// TelemetryValue is a typed reading. Type says which of the value fields is
+// set. In JSON it is written {"type": "int", "value": 16777217, "unit": "count"};
+// the type may be left out, in which case it is taken from the value, and a
+// bare value such as 64.2 or "E42" is accepted too. Numbers without a type
+// are doubles, as every reading was before typed values, so an int64 must
+// say "type": "int".
+type TelemetryValue struct {
+	Type   string
+	Double float64
+	Int    int64
+	Bool   bool
+	Text   string
+	Vector []float64
+	Unit   string
+}
+
+// DoubleValue returns a double reading.
+func DoubleValue(v float64, unit string) TelemetryValue {
+	return TelemetryValue{Type: TelemetryDouble, Double: v, Unit: unit}
+}
+
+// legacyValue converts a float reading from an old client. It goes through
+// the float's shortest decimal form so 64.2 is stored as 64.2, not
+// 64.19999694824219.
+func legacyValue(v float32) TelemetryValue {
+	f, _ := strconv.ParseFloat(strconv.FormatFloat(float64(v), 'g', -1, 32), 64)
+	return DoubleValue(f, "")
+}
+
+// Float returns the value of a numeric reading. Booleans count as 0 or 1;
+// strings and vectors aren't numeric.
+func (v TelemetryValue) Float() (float64, bool) {
+	switch v.Type {
+	case TelemetryDouble:
+		return v.Double, true
+	case TelemetryInt:
+		return float64(v.Int), true
+	case TelemetryBool:
+		if v.Bool {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+func (v TelemetryValue) validate() error {
+	switch v.Type {
+	case TelemetryDouble, TelemetryInt, TelemetryBool, TelemetryString, TelemetryVector:
+		return nil
+	}
+	return fmt.Errorf("unknown telemetry value type %q", v.Type)
+}
+
+func (v TelemetryValue) value() interface{} {
+	switch v.Type {
+	case TelemetryInt:
+		return v.Int
+	case TelemetryBool:
+		return v.Bool
+	case TelemetryString:
+		return v.Text
+	case TelemetryVector:
+		return v.Vector
+	}
+	return v.Double
+}
+
+type telemetryValueJSON struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+	Unit  string          `json:"unit,omitempty"`
+}
+
+func (v TelemetryValue) MarshalJSON() ([]byte, error) {
+	raw, err := json.Marshal(v.value())
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(telemetryValueJSON{Type: v.Type, Value: raw, Unit: v.Unit})
+}
+
+func (v *TelemetryValue) UnmarshalJSON(b []byte) error {
+	var j telemetryValueJSON
+	b = bytes.TrimSpace(b)
+	if len(b) > 0 && b[0] == '{' {
+		if err := json.Unmarshal(b, &j); err != nil {
+			return err
+		}
+	} else {
+		j.Value = b
+	}
+	raw := bytes.TrimSpace(j.Value)
+	if len(raw) == 0 {
+		return fmt.Errorf("telemetry value is missing")
+	}
+
+	*v = TelemetryValue{Type: j.Type, Unit: j.Unit}
+	if v.Type == "" {
+		switch {
+		case raw[0] == '"':
+			v.Type = TelemetryString
+		case raw[0] == '[':
+			v.Type = TelemetryVector
+		case raw[0] == 't' || raw[0] == 'f':
+			v.Type = TelemetryBool
+		default:
+			v.Type = TelemetryDouble
+		}
+	}
+
+	var err error
+	switch v.Type {
+	case TelemetryDouble:
+		err = json.Unmarshal(raw, &v.Double)
+	case TelemetryInt:
+		err = json.Unmarshal(raw, &v.Int)
+	case TelemetryBool:
+		err = json.Unmarshal(raw, &v.Bool)
+	case TelemetryString:
+		err = json.Unmarshal(raw, &v.Text)
+	case TelemetryVector:
+		err = json.Unmarshal(raw, &v.Vector)
+	default:
+		err = v.validate()
+	}
+	return err
+}
+
+// encodeStored is how a reading is kept in Redis. Doubles are stored as
+// plain numbers, as every reading was before typed values; anything else is
+// stored as JSON so its type survives.
+func (v TelemetryValue) encodeStored() (string, error) {
+	if v.Type == TelemetryDouble && v.Unit == "" {
+		return strconv.FormatFloat(v.Double, 'g', -1, 64), nil
+	}
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+func decodeStored(s string) (TelemetryValue, error) {
+	if strings.HasPrefix(s, "{") {
+		var v TelemetryValue
+		err := json.Unmarshal([]byte(s), &v)
+		return v, err
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	return DoubleValue(f, ""), err
+}
@@ -0,0 +1,77 @@
+package iotmonitor
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestTelemetryValueUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    TelemetryValue
+		wantErr bool
+	}{
+		{`64.2`, DoubleValue(64.2, ""), false},
+		{`21`, DoubleValue(21, ""), false},
+		{`16777217`, DoubleValue(16777217, ""), false},
+		{`-3`, DoubleValue(-3, ""), false},
+		{`1e3`, DoubleValue(1000, ""), false},
+		{`true`, TelemetryValue{Type: TelemetryBool, Bool: true}, false},
+		{`"E42"`, TelemetryValue{Type: TelemetryString, Text: "E42"}, false},
+		{`[0.1, 0.2]`, TelemetryValue{Type: TelemetryVector, Vector: []float64{0.1, 0.2}}, false},
+		{`{"value": 21, "unit": "C"}`, DoubleValue(21, "C"), false},
+		{`{"type": "int", "value": 9007199254740993}`, TelemetryValue{Type: TelemetryInt, Int: 9007199254740993}, false},
+		{`{"type": "double", "value": 21}`, DoubleValue(21, ""), false},
+		{`{"type": "int", "value": 2.5}`, TelemetryValue{}, true},
+		{`{"type": "complex", "value": 1}`, TelemetryValue{}, true},
+		{`{"type": "int"}`, TelemetryValue{}, true},
+		{`{"value": "x", "unit": "code"}`, TelemetryValue{Type: TelemetryString, Text: "x", Unit: "code"}, false},
+	}
+	for _, tt := range tests {
+		var got TelemetryValue
+		err := json.Unmarshal([]byte(tt.in), &got)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Unmarshal(%s) error = %v, want error %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Unmarshal(%s) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTelemetryValueRoundTrip(t *testing.T) {
+	values := []TelemetryValue{
+		DoubleValue(64.2, ""),
+		DoubleValue(21, "C"),
+		{Type: TelemetryInt, Int: 9007199254740993, Unit: "count"},
+		{Type: TelemetryBool, Bool: false},
+		{Type: TelemetryString, Text: "E42"},
+		{Type: TelemetryVector, Vector: []float64{1, -2.5}},
+	}
+	for _, v := range values {
+		b, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got TelemetryValue
+		if err := json.Unmarshal(b, &got); err != nil || !reflect.DeepEqual(got, v) {
+			t.Errorf("JSON round trip of %+v via %s = %+v, %v", v, b, got, err)
+		}
+
+		s, err := v.encodeStored()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := decodeStored(s); err != nil || !reflect.DeepEqual(got, v) {
+			t.Errorf("stored round trip of %+v via %s = %+v, %v", v, s, got, err)
+		}
+	}
+}
+
+func TestLegacyValue(t *testing.T) {
+	if got := legacyValue(64.2); !reflect.DeepEqual(got, DoubleValue(64.2, "")) {
+		t.Errorf("legacyValue(64.2) = %+v", got)
+	}
+}
@@ -0,0 +1,237 @@
+package iotmonitor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/garyburd/redigo/redis"
+	"golang.org/x/net/context"
+)
+
+// TwinDocument is a JSON object describing device configuration, such as
+// {"report_interval": 30, "thresholds": {"temp": 80}, "firmware_channel": "beta"}.
+type TwinDocument map[string]interface{}
+
+// Twin pairs the configuration we want a device to run (Desired) with the
+// configuration the device says it runs (Reported). Each side carries a
+// version that increments on every change. Delta holds the desired settings
+// the device has not yet reported back.
+type Twin struct {
+	DeviceID         uint64       `json:"device_id"`
+	Desired          TwinDocument `json:"desired"`
+	DesiredVersion   int64        `json:"desired_version"`
+	Reported         TwinDocument `json:"reported"`
+	ReportedVersion  int64        `json:"reported_version"`
+	Delta            TwinDocument `json:"delta,omitempty"`
+	DeliveredVersion int64        `json:"delivered_version"`
+}
+
+// DesiredState is the desired document as delivered to a device.
+type DesiredState struct {
+	Version int64        `json:"version"`
+	Desired TwinDocument `json:"desired"`
+	Delta   TwinDocument `json:"delta,omitempty"`
+}
+
+var (
+	errTwinVersionConflict = errors.New("twin version conflict")
+	errTwinConcurrentWrite = errors.New("twin was modified concurrently, retry")
+)
+
+type twinRecord struct {
+	Desired          string `redis:"desired"`
+	DesiredVersion   int64  `redis:"desired_version"`
+	Reported         string `redis:"reported"`
+	ReportedVersion  int64  `redis:"reported_version"`
+	DeliveredVersion int64  `redis:"delivered_version"`
+}
+
+func twinKey(id uint64) string {
+	return fmt.Sprintf("twin:%d", id)
+}
+
+func readTwin(c redis.Conn, id uint64) (Twin, error) {
+	t := Twin{DeviceID: id, Desired: TwinDocument{}, Reported: TwinDocument{}}
+	v, err := redis.Values(c.Do("HGETALL", twinKey(id)))
+	if err != nil || len(v) == 0 {
+		return t, err
+	}
+	var rec twinRecord
+	if err := redis.ScanStruct(v, &rec); err != nil {
+		return t, err
+	}
+	if rec.Desired != "" {
+		if err := json.Unmarshal([]byte(rec.Desired), &t.Desired); err != nil {
+			return t, err
+		}
+	}
+	if rec.Reported != "" {
+		if err := json.Unmarshal([]byte(rec.Reported), &t.Reported); err != nil {
+			return t, err
+		}
+	}
+	t.DesiredVersion, t.ReportedVersion, t.DeliveredVersion = rec.DesiredVersion, rec.ReportedVersion, rec.DeliveredVersion
+	t.Delta = twinDelta(t.Desired, t.Reported)
+	return t, nil
+}
+
+// mergePatch applies an RFC 7386 JSON merge patch: nested objects merge and
+// null values remove keys.
+func mergePatch(doc, patch TwinDocument) TwinDocument {
+	if doc == nil {
+		doc = TwinDocument{}
+	}
+	for k, v := range patch {
+		if v == nil {
+			delete(doc, k)
+			continue
+		}
+		if p, ok := v.(map[string]interface{}); ok {
+			d, _ := doc[k].(map[string]interface{})
+			doc[k] = map[string]interface{}(mergePatch(d, p))
+			continue
+		}
+		doc[k] = v
+	}
+	return doc
+}
+
+// twinDelta returns the parts of desired that differ from reported.
+func twinDelta(desired, reported TwinDocument) TwinDocument {
+	delta := TwinDocument{}
+	for k, want := range desired {
+		have, ok := reported[k]
+		if w, isObject := want.(map[string]interface{}); isObject {
+			h, _ := have.(map[string]interface{})
+			if d := twinDelta(w, h); len(d) > 0 {
+				delta[k] = map[string]interface{}(d)
+			}
+			continue
+		}
+		if !ok || !reflect.DeepEqual(want, have) {
+			delta[k] = want
+		}
+	}
+	if len(delta) == 0 {
+		return nil
+	}
+	return delta
+}
+
+// updateTwin applies a patch to one side of a twin under WATCH, so
+// concurrent writers can't lose each other's changes. A non-zero version
+// must match the side's current version.
+func updateTwin(c redis.Conn, id uint64, desired bool, patch TwinDocument, version int64) (Twin, error) {
+	if _, err := c.Do("WATCH", twinKey(id)); err != nil {
+		return Twin{}, err
+	}
+	t, err := readTwin(c, id)
+	if err != nil {
+		c.Do("UNWATCH")
+		return t, err
+	}
+
+	field, current := "reported", &t.ReportedVersion
+	doc := &t.Reported
+	if desired {
+		field, current, doc = "desired", &t.DesiredVersion, &t.Desired
+	}
+	if version != 0 && version != *current {
+		c.Do("UNWATCH")
+		return t, fmt.Errorf("%v: have %d, want %d", errTwinVersionConflict, *current, version)
+	}
+	*doc = mergePatch(*doc, patch)
+	*current++
+	b, err := json.Marshal(*doc)
+	if err != nil {
+		c.Do("UNWATCH")
+		return t, err
+	}
+
+	c.Send("MULTI")
+	c.Send("HMSET", twinKey(id), field, b, field+"_version", *current)
+	reply, err := c.Do("EXEC")
+	if err != nil {
+		return t, err
+	}
+	if reply == nil {
+		return t, errTwinConcurrentWrite
+	}
+	t.Delta = twinDelta(t.Desired, t.Reported)
+	return t, nil
+}
+
+func (s monitorService) GetTwin(ctx context.Context, id uint64) (Twin, error) {
+	c, err := s.store.dial()
+	if err != nil {
+		return Twin{}, err
+	}
+	defer c.Close()
+
+	if _, err := readDevice(c, id); err != nil {
+		return Twin{}, err
+	}
+	return readTwin(c, id)
+}
+
+// UpdateDesired merges a patch into the desired document. The device picks
+// the change up on its next status update or over a WatchDesired stream.
+func (s monitorService) UpdateDesired(ctx context.Context, id uint64, patch TwinDocument, version int64) (Twin, error) {
+	c, err := s.store.dial()
+	if err != nil {
+		return Twin{}, err
+	}
+	defer c.Close()
+
+	if _, err := readDevice(c, id); err != nil {
+		return Twin{}, err
+	}
+	t, err := updateTwin(c, id, true, patch, version)
+	if err != nil {
+		return Twin{}, err
+	}
+	s.events.Publish(Event{Type: EventTwinDesiredUpdated, DeviceID: id, Data: DesiredState{Version: t.DesiredVersion, Desired: t.Desired, Delta: t.Delta}})
+	return t, nil
+}
+
+// ReportState merges the configuration a device reports into its reported
+// document.
+func (s monitorService) ReportState(ctx context.Context, id uint64, patch TwinDocument) (Twin, error) {
+	c, err := s.store.dial()
+	if err != nil {
+		return Twin{}, err
+	}
+	defer c.Close()
+
+	if _, err := readDevice(c, id); err != nil {
+		return Twin{}, err
+	}
+	t, err := updateTwin(c, id, false, patch, 0)
+	if err != nil {
+		return Twin{}, err
+	}
+	s.events.Publish(Event{Type: EventTwinReported, DeviceID: id, Data: t})
+	return t, nil
+}
+
+// DeliverDesired returns the desired state if it changed since it was last
+// delivered to the device, and records the delivery. It returns nil when the
+// device is up to date.
+func (s monitorService) DeliverDesired(ctx context.Context, id uint64) (*DesiredState, error) {
+	c, err := s.store.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	t, err := readTwin(c, id)
+	if err != nil || t.DesiredVersion <= t.DeliveredVersion {
+		return nil, err
+	}
+	if _, err := c.Do("HSET", twinKey(id), "delivered_version", t.DesiredVersion); err != nil {
+		return nil, err
+	}
+	return &DesiredState{Version: t.DesiredVersion, Desired: t.Desired, Delta: t.Delta}, nil
+}
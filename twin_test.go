@@ -0,0 +1,115 @@
+package iotmonitor
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   TwinDocument
+		patch TwinDocument
+		want  TwinDocument
+	}{
+		{"empty doc", nil, TwinDocument{"a": 1.0}, TwinDocument{"a": 1.0}},
+		{"replace", TwinDocument{"a": 1.0}, TwinDocument{"a": "x"}, TwinDocument{"a": "x"}},
+		{"delete", TwinDocument{"a": 1.0, "b": 2.0}, TwinDocument{"a": nil}, TwinDocument{"b": 2.0}},
+		{"delete missing", TwinDocument{"b": 2.0}, TwinDocument{"a": nil}, TwinDocument{"b": 2.0}},
+		{
+			"nested merge",
+			TwinDocument{"led": map[string]interface{}{"on": true, "color": "red"}},
+			TwinDocument{"led": map[string]interface{}{"color": "blue", "on": nil}},
+			TwinDocument{"led": map[string]interface{}{"color": "blue"}},
+		},
+		{
+			"object over scalar",
+			TwinDocument{"led": "off"},
+			TwinDocument{"led": map[string]interface{}{"on": true}},
+			TwinDocument{"led": map[string]interface{}{"on": true}},
+		},
+		{
+			"array replaces",
+			TwinDocument{"tags": []interface{}{"a", "b"}},
+			TwinDocument{"tags": []interface{}{"c"}},
+			TwinDocument{"tags": []interface{}{"c"}},
+		},
+	}
+	for _, tt := range tests {
+		if got := mergePatch(tt.doc, tt.patch); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: mergePatch() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTwinDelta(t *testing.T) {
+	tests := []struct {
+		name     string
+		desired  TwinDocument
+		reported TwinDocument
+		want     TwinDocument
+	}{
+		{"in sync", TwinDocument{"a": 1.0}, TwinDocument{"a": 1.0, "b": 2.0}, nil},
+		{"differs", TwinDocument{"a": 1.0}, TwinDocument{"a": 2.0}, TwinDocument{"a": 1.0}},
+		{"unreported", TwinDocument{"a": 1.0}, nil, TwinDocument{"a": 1.0}},
+		{
+			"nested",
+			TwinDocument{"led": map[string]interface{}{"on": true, "color": "red"}},
+			TwinDocument{"led": map[string]interface{}{"on": true, "color": "blue"}},
+			TwinDocument{"led": map[string]interface{}{"color": "red"}},
+		},
+		{
+			"nested in sync",
+			TwinDocument{"led": map[string]interface{}{"on": true}},
+			TwinDocument{"led": map[string]interface{}{"on": true, "color": "blue"}},
+			nil,
+		},
+		{
+			"nested unreported",
+			TwinDocument{"led": map[string]interface{}{"on": true}},
+			TwinDocument{"led": "broken"},
+			TwinDocument{"led": map[string]interface{}{"on": true}},
+		},
+	}
+	for _, tt := range tests {
+		if got := twinDelta(tt.desired, tt.reported); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: twinDelta() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestUpdateTwin(t *testing.T) {
+	c := newFakeRedis()
+	tw, err := updateTwin(c, 1, true, TwinDocument{"rate": 5.0}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tw.DesiredVersion != 1 || !reflect.DeepEqual(tw.Delta, TwinDocument{"rate": 5.0}) {
+		t.Errorf("after desired patch: version %d, delta %v", tw.DesiredVersion, tw.Delta)
+	}
+
+	if _, err := updateTwin(c, 1, true, TwinDocument{"rate": 6.0}, 3); err == nil || !strings.Contains(err.Error(), errTwinVersionConflict.Error()) {
+		t.Errorf("stale version: err = %v, want a version conflict", err)
+	}
+
+	c.abortExec = 1
+	if _, err := updateTwin(c, 1, false, TwinDocument{"rate": 5.0}, 0); err != errTwinConcurrentWrite {
+		t.Errorf("aborted EXEC: err = %v, want %v", err, errTwinConcurrentWrite)
+	}
+
+	tw, err = updateTwin(c, 1, false, TwinDocument{"rate": 5.0}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tw.ReportedVersion != 1 || tw.Delta != nil {
+		t.Errorf("after report: reported version %d, delta %v", tw.ReportedVersion, tw.Delta)
+	}
+	got, err := readTwin(c, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.DesiredVersion != 1 || got.ReportedVersion != 1 || got.Delta != nil {
+		t.Errorf("stored twin %+v, want both sides at version 1 and in sync", got)
+	}
+}
@@ -0,0 +1,107 @@
+package iotmonitor
+
+import (
+	"errors"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+const (
+	eventStreamBuffer  = 256
+	eventStreamRefresh = 10 * time.Second
+)
+
+// EventFilter picks the events a WatchEvents stream carries: those of
+// Types, or of every type if there are none, about the devices Devices
+// matches. A filter that matches every device also passes events that
+// aren't about a device.
+type EventFilter struct {
+	Types   []string
+	Devices DeviceFilter
+}
+
+func (f EventFilter) wants(eventType string) bool {
+	if len(f.Types) == 0 {
+		return true
+	}
+	for _, t := range f.Types {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	errStreamDrained = errors.New("server is shutting down")
+	errStreamEnded   = errors.New("event stream ended")
+)
+
+// streamEvents sends the bus events filter picks until ctx is done, or
+// returns errStreamDrained once drain is. The devices the filter matches
+// are listed when the stream opens and again whenever a device is
+// registered, updated or deleted, and every eventStreamRefresh to catch
+// label and group changes. Like WatchCommands, the stream holds a bounded
+// buffer: a client that falls that far behind misses events.
+func streamEvents(ctx, drain context.Context, bus *EventBus, filter EventFilter, list func(context.Context, DeviceFilter) ([]Device, error), send func(Event) error) error {
+	events, cancel := bus.Subscribe(eventStreamBuffer)
+	defer cancel()
+
+	var devices map[uint64]bool
+	match := func() error {
+		if filter.Devices.empty() {
+			return nil
+		}
+		matched, err := list(ctx, filter.Devices)
+		if err != nil {
+			return err
+		}
+		devices = make(map[uint64]bool, len(matched))
+		for _, d := range matched {
+			devices[d.ID] = true
+		}
+		return nil
+	}
+	if err := match(); err != nil {
+		return err
+	}
+	picks := func(e Event) bool {
+		return filter.wants(e.Type) && (devices == nil || devices[e.DeviceID])
+	}
+
+	refresh := time.NewTicker(eventStreamRefresh)
+	defer refresh.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-drain.Done():
+			return errStreamDrained
+		case <-refresh.C:
+			if err := match(); err != nil {
+				return err
+			}
+		case e, ok := <-events:
+			if !ok {
+				return nil
+			}
+			// Changes to a device are passed if it matched before or after
+			// them, so that a watch sees devices come and go.
+			wanted := picks(e)
+			switch e.Type {
+			case EventDeviceRegistered, EventDeviceUpdated, EventDeviceDeleted:
+				if err := match(); err != nil {
+					return err
+				}
+				wanted = wanted || picks(e)
+			}
+			if !wanted {
+				continue
+			}
+			if err := send(e); err != nil {
+				return err
+			}
+		}
+	}
+}
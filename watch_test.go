@@ -0,0 +1,184 @@
+package iotmonitor
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"runtime"
+	"sync"
+	"testing"
+
+	"golang.org/x/net/context"
+)
+
+// eventStream runs streamEvents over a new bus in the background, with
+// devices listed from matched.
+type eventStream struct {
+	bus     *EventBus
+	mu      sync.Mutex
+	matched []Device
+	events  chan Event
+	done    chan error
+}
+
+func startEventStream(ctx, drain context.Context, filter EventFilter, matched ...uint64) *eventStream {
+	s := &eventStream{bus: NewEventBus(), events: make(chan Event, 16), done: make(chan error, 1)}
+	s.match(matched...)
+	listed := make(chan struct{}, 1)
+	list := func(context.Context, DeviceFilter) ([]Device, error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		select {
+		case listed <- struct{}{}:
+		default:
+		}
+		return s.matched, nil
+	}
+	go func() {
+		s.done <- streamEvents(ctx, drain, s.bus, filter, list, func(e Event) error {
+			s.events <- e
+			return nil
+		})
+	}()
+	// The stream subscribes and then lists the devices it matches, unless
+	// it matches every device.
+	if filter.Devices.empty() {
+		for s.bus.Stats().Subscribers == 0 {
+			runtime.Gosched()
+		}
+	} else {
+		<-listed
+	}
+	return s
+}
+
+func (s *eventStream) match(ids ...uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.matched = nil
+	for _, id := range ids {
+		s.matched = append(s.matched, Device{ID: id})
+	}
+}
+
+// deviceEvent is the type and device of an event.
+type deviceEvent struct {
+	Type     string
+	DeviceID uint64
+}
+
+func (s *eventStream) publish(e deviceEvent) {
+	s.bus.Publish(Event{Type: e.Type, DeviceID: e.DeviceID})
+}
+
+// next returns the next event the stream sends.
+func (s *eventStream) next() deviceEvent {
+	e := <-s.events
+	return deviceEvent{e.Type, e.DeviceID}
+}
+
+func TestStreamEventsFilters(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	filter := EventFilter{
+		Types:   []string{EventStatusUpdated, EventDeviceUpdated},
+		Devices: DeviceFilter{Selector: "site=plant-3"},
+	}
+	s := startEventStream(ctx, context.Background(), filter, 1)
+
+	// Each step publishes events and then takes the one the stream should
+	// send, before the devices matched change again.
+	steps := []struct {
+		match   []uint64
+		publish []deviceEvent
+		want    deviceEvent
+	}{
+		{nil, []deviceEvent{{EventStatusUpdated, 1}}, deviceEvent{EventStatusUpdated, 1}},
+		{
+			[]uint64{1, 2},
+			[]deviceEvent{
+				{EventStatusUpdated, 2}, // not matched yet
+				{EventAlertFiring, 1},   // not wanted
+				{EventDeviceUpdated, 2}, // matched after
+			},
+			deviceEvent{EventDeviceUpdated, 2},
+		},
+		{nil, []deviceEvent{{EventStatusUpdated, 2}}, deviceEvent{EventStatusUpdated, 2}},
+		{[]uint64{1}, []deviceEvent{{EventDeviceUpdated, 2}}, deviceEvent{EventDeviceUpdated, 2}}, // matched before
+		{nil, []deviceEvent{{EventStatusUpdated, 2}, {EventStatusUpdated, 1}}, deviceEvent{EventStatusUpdated, 1}},
+	}
+	for i, step := range steps {
+		if step.match != nil {
+			s.match(step.match...)
+		}
+		for _, e := range step.publish {
+			s.publish(e)
+		}
+		if got := s.next(); got != step.want {
+			t.Fatalf("step %d: event = %v, want %v", i, got, step.want)
+		}
+	}
+	cancel()
+	if err := <-s.done; err != nil {
+		t.Errorf("streamEvents() = %v after ctx was done, want nil", err)
+	}
+}
+
+func TestStreamEventsEmptyFilter(t *testing.T) {
+	drain, stop := context.WithCancel(context.Background())
+	s := startEventStream(context.Background(), drain, EventFilter{})
+	s.publish(deviceEvent{EventCampaignPaused, 0})
+	if got := s.next(); got != (deviceEvent{EventCampaignPaused, 0}) {
+		t.Errorf("event = %v, want the campaign event", got)
+	}
+	stop()
+	if err := <-s.done; err != errStreamDrained {
+		t.Errorf("streamEvents() = %v after drain, want %v", err, errStreamDrained)
+	}
+}
+
+func TestEventFilterQuery(t *testing.T) {
+	filters := []EventFilter{
+		{},
+		{Types: []string{EventAlertFiring, EventAlertResolved}},
+		{Types: []string{EventStatusUpdated}, Devices: DeviceFilter{DeviceType: "Drone", Selector: "site=plant-3", DeviceIDs: []uint64{4, 2}}},
+	}
+	for _, f := range filters {
+		r, _ := http.NewRequest("GET", "http://monitor/v1/events?"+eventFilterQuery(f).Encode(), nil)
+		got, err := decodeEventFilter(r)
+		if err != nil || !reflect.DeepEqual(got, f) {
+			t.Errorf("decodeEventFilter(eventFilterQuery(%+v)) = %+v, %v", f, got, err)
+		}
+	}
+}
+
+func TestWatchEventsHTTP(t *testing.T) {
+	drain, stop := context.WithCancel(context.Background())
+	bus := NewEventBus()
+	server := httptest.NewServer(watchEventsHandler(drain, Endpoints{}, bus))
+	defer server.Close()
+
+	got := make(chan Event, 1)
+	done := make(chan error, 1)
+	go func() {
+		filter := EventFilter{Types: []string{EventAlertFiring}}
+		done <- WatchEventsHTTP(context.Background(), http.DefaultClient, server.URL, nil, filter, func(e Event) error {
+			got <- e
+			return nil
+		})
+	}()
+	for bus.Stats().Subscribers == 0 {
+		runtime.Gosched()
+	}
+	bus.Publish(Event{Type: EventStatusUpdated, DeviceID: 1})
+	bus.Publish(Event{Type: EventAlertFiring, DeviceID: 2, Data: Alert{DeviceID: 2, State: AlertFiring}})
+	e := <-got
+	if e.Type != EventAlertFiring || e.DeviceID != 2 || string(e.Data.(json.RawMessage)) != `{"device_id":2,"state":"firing","value":0,"message":"","fired_at":0}` {
+		t.Errorf("received %+v with data %s", e, e.Data)
+	}
+	stop()
+	if err := <-done; err == nil || err.Error() != errStreamDrained.Error() {
+		t.Errorf("WatchEventsHTTP() = %v after drain, want %v", err, errStreamDrained)
+	}
+}
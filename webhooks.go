@@ -0,0 +1,439 @@
+package iotmonitor
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+	"golang.org/x/net/context"
+)
+
+// Webhook is a subscription that receives events as signed HTTP POSTs.
+// An empty Events list subscribes to every event type.
+type Webhook struct {
+	ID     uint64   `json:"id"`
+	URL    string   `json:"url"`
+	Secret string   `json:"secret,omitempty"`
+	Events []string `json:"events,omitempty"`
+}
+
+// DeadLetter records a delivery that exhausted its retries, or that the
+// dispatcher stopped before it could finish.
+type DeadLetter struct {
+	WebhookID uint64 `json:"webhook_id"`
+	URL       string `json:"url"`
+	Event     Event  `json:"event"`
+	Error     string `json:"error"`
+	Attempts  int    `json:"attempts"`
+	FailedAt  int64  `json:"failed_at"`
+}
+
+var errWebhookNotFound = errors.New("webhook not found")
+
+const (
+	webhooksKey        = "webhooks"
+	webhooksVersionKey = "webhooks:version"
+	deadLetterKey      = "webhooks:deadletter"
+	deadLetterCap      = 1000
+	signatureHeader    = "X-Iotmonitor-Signature"
+)
+
+func (w Webhook) validate() error {
+	u, err := url.Parse(w.URL)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("invalid webhook url %q", w.URL)
+	}
+	return nil
+}
+
+func (w Webhook) wants(eventType string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, t := range w.Events {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// SignWebhook returns the hex encoded HMAC-SHA256 of body, as sent in the
+// X-Iotmonitor-Signature header ("sha256=<hex>").
+func SignWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func readWebhooks(c redis.Conn) ([]Webhook, error) {
+	raw, err := redis.ByteSlices(c.Do("HVALS", webhooksKey))
+	if err != nil {
+		return nil, err
+	}
+	hooks := make([]Webhook, 0, len(raw))
+	for _, b := range raw {
+		var w Webhook
+		if err := json.Unmarshal(b, &w); err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, w)
+	}
+	return hooks, nil
+}
+
+func (s monitorService) CreateWebhook(ctx context.Context, hook Webhook) (uint64, error) {
+	if err := hook.validate(); err != nil {
+		return 0, err
+	}
+	c, err := s.store.dial()
+	if err != nil {
+		return 0, err
+	}
+	defer c.Close()
+
+	hook.ID, err = redis.Uint64(c.Do("INCR", "id:webhooks"))
+	if err != nil {
+		return 0, err
+	}
+	b, err := json.Marshal(hook)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := c.Do("HSET", webhooksKey, hook.ID, b); err != nil {
+		return 0, err
+	}
+	if _, err := c.Do("INCR", webhooksVersionKey); err != nil {
+		return 0, err
+	}
+	return hook.ID, nil
+}
+
+// ListWebhooks returns the subscriptions with their secrets removed.
+func (s monitorService) ListWebhooks(ctx context.Context) ([]Webhook, error) {
+	c, err := s.store.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	hooks, err := readWebhooks(c)
+	if err != nil {
+		return nil, err
+	}
+	for i := range hooks {
+		hooks[i].Secret = ""
+	}
+	return hooks, nil
+}
+
+func (s monitorService) DeleteWebhook(ctx context.Context, id uint64) (bool, error) {
+	c, err := s.store.dial()
+	if err != nil {
+		return false, err
+	}
+	defer c.Close()
+
+	n, err := redis.Int(c.Do("HDEL", webhooksKey, id))
+	if err != nil {
+		return false, err
+	}
+	if n == 0 {
+		return false, errWebhookNotFound
+	}
+	if _, err := c.Do("INCR", webhooksVersionKey); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// DeadLetters returns up to limit failed deliveries, newest first.
+func (s monitorService) DeadLetters(ctx context.Context, limit int) ([]DeadLetter, error) {
+	if limit <= 0 || limit > deadLetterCap {
+		limit = deadLetterCap
+	}
+	c, err := s.store.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	raw, err := redis.ByteSlices(c.Do("LRANGE", deadLetterKey, 0, limit-1))
+	if err != nil {
+		return nil, err
+	}
+	letters := make([]DeadLetter, 0, len(raw))
+	for _, b := range raw {
+		var d DeadLetter
+		if err := json.Unmarshal(b, &d); err != nil {
+			return nil, err
+		}
+		letters = append(letters, d)
+	}
+	return letters, nil
+}
+
+// Defaults for the WebhookDispatcher settings left at zero.
+const (
+	defaultWebhookAttempts    = 5
+	defaultWebhookBackoff     = time.Second
+	defaultWebhookConcurrency = 16
+)
+
+// WebhookDispatcher delivers bus events to webhook subscribers, retrying
+// failed deliveries with exponential backoff before dead-lettering them.
+// MaxAttempts, Backoff and Concurrency take their defaults when zero.
+type WebhookDispatcher struct {
+	Store       StoreConfig // where the subscriptions and dead letters are kept
+	Client      *http.Client
+	MaxAttempts int
+	Backoff     time.Duration // delay before the first retry, doubled each time
+	Concurrency int
+	Refresh     time.Duration // how often the stored subscriptions are checked for changes
+
+	hooks webhookCache
+}
+
+func NewWebhookDispatcher(store StoreConfig) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		Store:       store,
+		Client:      &http.Client{Timeout: 10 * time.Second},
+		MaxAttempts: defaultWebhookAttempts,
+		Backoff:     defaultWebhookBackoff,
+		Concurrency: defaultWebhookConcurrency,
+		Refresh:     time.Second,
+	}
+}
+
+// webhookCache holds the stored subscriptions, reloading them when the
+// stored version changes. The version is checked at most once per refresh
+// interval, so a new or deleted webhook can take that long to take effect.
+// If the check fails, the subscriptions last loaded are returned with the
+// error.
+type webhookCache struct {
+	mu      sync.Mutex
+	version int64
+	loaded  bool
+	checked time.Time
+	hooks   []Webhook
+}
+
+func (x *webhookCache) get(store StoreConfig, now time.Time, refresh time.Duration) ([]Webhook, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if x.loaded && now.Sub(x.checked) < refresh {
+		return x.hooks, nil
+	}
+
+	c, err := store.dial()
+	if err != nil {
+		return x.hooks, err
+	}
+	defer c.Close()
+
+	version, err := redis.Int64(c.Do("GET", webhooksVersionKey))
+	if err != nil && err != redis.ErrNil {
+		return x.hooks, err
+	}
+	if !x.loaded || x.version != version {
+		hooks, err := readWebhooks(c)
+		if err != nil {
+			return x.hooks, err
+		}
+		x.hooks = hooks
+		x.version = version
+		x.loaded = true
+	}
+	x.checked = now
+	return x.hooks, nil
+}
+
+var errDispatcherStopped = errors.New("webhook dispatcher stopped before delivery")
+
+// Run subscribes to the bus and delivers events until ctx is cancelled. It
+// then stops taking events, dead-letters those still waiting to be
+// dispatched, and returns once the deliveries under way have finished.
+// Those keep retrying until deliveries is done, and are dead-lettered if
+// they haven't succeeded by then, so that nothing is lost.
+func (d *WebhookDispatcher) Run(ctx, deliveries context.Context, bus *EventBus) error {
+	events, cancel := bus.SubscribeUnbounded()
+	defer cancel()
+
+	concurrency := d.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultWebhookConcurrency
+	}
+	var inFlight sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for {
+		select {
+		case <-ctx.Done():
+			cancel()
+			for e := range events {
+				d.abandon(e)
+			}
+			inFlight.Wait()
+			return ctx.Err()
+		case e := <-events:
+			hooks, err := d.subscribers(e.Type)
+			if err != nil {
+				fmt.Printf("Failed to load webhooks: %v\n", err)
+			}
+		dispatch:
+			for i, w := range hooks {
+				select {
+				case sem <- struct{}{}:
+				case <-ctx.Done():
+					for _, w := range hooks[i:] {
+						d.abandonTo(w, e)
+					}
+					break dispatch
+				}
+				inFlight.Add(1)
+				go func(w Webhook, e Event) {
+					defer inFlight.Done()
+					defer func() { <-sem }()
+					d.deliver(deliveries, w, e)
+				}(w, e)
+			}
+		}
+	}
+}
+
+// subscribers returns the webhooks that want eventType. If they can't be
+// reloaded, it falls back to those last loaded, returning the error too.
+func (d *WebhookDispatcher) subscribers(eventType string) ([]Webhook, error) {
+	hooks, err := d.hooks.get(d.Store, time.Now(), d.Refresh)
+	var matched []Webhook
+	for _, w := range hooks {
+		if w.wants(eventType) {
+			matched = append(matched, w)
+		}
+	}
+	return matched, err
+}
+
+func (d *WebhookDispatcher) deliver(ctx context.Context, w Webhook, e Event) {
+	body, err := json.Marshal(e)
+	if err != nil {
+		fmt.Printf("Failed to encode event %d for webhook %d: %v\n", e.ID, w.ID, err)
+		return
+	}
+
+	maxAttempts, delay := d.MaxAttempts, d.Backoff
+	if maxAttempts <= 0 {
+		maxAttempts = defaultWebhookAttempts
+	}
+	if delay <= 0 {
+		delay = defaultWebhookBackoff
+	}
+	attempts := 0
+retry:
+	for attempts < maxAttempts {
+		attempts++
+		if err = d.post(ctx, w, e, body); err == nil {
+			return
+		}
+		if attempts == maxAttempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			break retry
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+
+	fmt.Printf("Webhook %d delivery of event %d failed after %d attempts: %v\n", w.ID, e.ID, attempts, err)
+	if err := deadLetter(d.Store, DeadLetter{
+		WebhookID: w.ID,
+		URL:       w.URL,
+		Event:     e,
+		Error:     err.Error(),
+		Attempts:  attempts,
+		FailedAt:  makeTimestamp(),
+	}); err != nil {
+		fmt.Printf("Failed to dead-letter event %d for webhook %d: %v\n", e.ID, w.ID, err)
+	}
+}
+
+// abandon dead-letters an event for each of its subscribers without trying
+// to deliver it.
+func (d *WebhookDispatcher) abandon(e Event) {
+	hooks, err := d.subscribers(e.Type)
+	if err != nil {
+		fmt.Printf("Failed to load webhooks: %v\n", err)
+	}
+	for _, w := range hooks {
+		d.abandonTo(w, e)
+	}
+}
+
+// abandonTo dead-letters an event for w without trying to deliver it.
+func (d *WebhookDispatcher) abandonTo(w Webhook, e Event) {
+	if err := deadLetter(d.Store, DeadLetter{
+		WebhookID: w.ID,
+		URL:       w.URL,
+		Event:     e,
+		Error:     errDispatcherStopped.Error(),
+		FailedAt:  makeTimestamp(),
+	}); err != nil {
+		fmt.Printf("Failed to dead-letter event %d for webhook %d: %v\n", e.ID, w.ID, err)
+	}
+}
+
+func (d *WebhookDispatcher) post(ctx context.Context, w Webhook, e Event, body []byte) error {
+	req, err := http.NewRequest("POST", w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Iotmonitor-Event", e.Type)
+	req.Header.Set("X-Iotmonitor-Delivery", strconv.FormatUint(e.ID, 10))
+	if w.Secret != "" {
+		req.Header.Set(signatureHeader, "sha256="+SignWebhook(w.Secret, body))
+	}
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook %s returned %s", w.URL, resp.Status)
+	}
+	return nil
+}
+
+func deadLetter(store StoreConfig, d DeadLetter) error {
+	c, err := store.dial()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	b, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	if _, err := c.Do("LPUSH", deadLetterKey, b); err != nil {
+		return err
+	}
+	_, err = c.Do("LTRIM", deadLetterKey, 0, deadLetterCap-1)
+	return err
+}
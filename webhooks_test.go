@@ -0,0 +1,122 @@
+package iotmonitor
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+func TestSignWebhook(t *testing.T) {
+	// RFC 4231 test case 2.
+	got := SignWebhook("Jefe", []byte("what do ya want for nothing?"))
+	want := "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"
+	if got != want {
+		t.Errorf("SignWebhook() = %s, want %s", got, want)
+	}
+}
+
+func TestWebhookValidate(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr bool
+	}{
+		{"https://example.com/hook", false},
+		{"http://10.0.0.1:8080/", false},
+		{"ftp://example.com/hook", true},
+		{"https:///hook", true},
+		{"example.com/hook", true},
+		{"://bad", true},
+	}
+	for _, tt := range tests {
+		if err := (Webhook{URL: tt.url}).validate(); (err != nil) != tt.wantErr {
+			t.Errorf("validate(%q) = %v, want error %v", tt.url, err, tt.wantErr)
+		}
+	}
+}
+
+func TestWebhookWants(t *testing.T) {
+	tests := []struct {
+		events []string
+		event  string
+		want   bool
+	}{
+		{nil, EventAlertFiring, true},
+		{[]string{EventAlertFiring, EventAlertResolved}, EventAlertResolved, true},
+		{[]string{EventAlertFiring}, EventDeviceOnline, false},
+	}
+	for _, tt := range tests {
+		if got := (Webhook{Events: tt.events}).wants(tt.event); got != tt.want {
+			t.Errorf("wants(%q) with %v = %v, want %v", tt.event, tt.events, got, tt.want)
+		}
+	}
+}
+
+// TestDispatcherFinishesDeliveriesAfterStop checks that deliveries under
+// way when the dispatcher is stopped are finished rather than cut off.
+func TestDispatcherFinishesDeliveriesAfterStop(t *testing.T) {
+	arrived := make(chan string, 1)
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		arrived <- r.Header.Get("X-Iotmonitor-Event")
+		<-release
+	}))
+	defer server.Close()
+
+	d := NewWebhookDispatcher(StoreConfig{})
+	d.Refresh = time.Hour
+	d.hooks = webhookCache{loaded: true, checked: time.Now(), hooks: []Webhook{{ID: 1, URL: server.URL}}}
+	bus := NewEventBus()
+	ctx, stop := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- d.Run(ctx, context.Background(), bus) }()
+	for bus.Stats().Subscribers == 0 {
+		runtime.Gosched()
+	}
+
+	bus.Publish(Event{Type: EventDeviceRegistered, DeviceID: 1})
+	if got := <-arrived; got != EventDeviceRegistered {
+		t.Errorf("delivered %q", got)
+	}
+	stop()
+	select {
+	case err := <-done:
+		t.Fatalf("Run() = %v with a delivery under way", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Run() = %v, want %v", err, context.Canceled)
+	}
+}
+
+// TestDeliverZeroSettings checks that a dispatcher built without
+// NewWebhookDispatcher still makes its attempts.
+func TestDeliverZeroSettings(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Header.Get("X-Iotmonitor-Delivery") == "2" {
+			// Cut the retries short, leaving the failure to be dead-lettered.
+			cancel()
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	d := &WebhookDispatcher{Client: http.DefaultClient}
+	w := Webhook{ID: 1, URL: server.URL}
+	d.deliver(ctx, w, Event{ID: 1, Type: EventDeviceRegistered})
+	if calls != 1 {
+		t.Fatalf("delivered %d times, want once", calls)
+	}
+	d.deliver(ctx, w, Event{ID: 2, Type: EventDeviceRegistered})
+	if calls != 2 {
+		t.Errorf("delivered %d times, want a failed attempt", calls)
+	}
+}